@@ -0,0 +1,45 @@
+package benchmark
+
+import (
+	"io"
+	"testing"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+
+	logx "github.com/zalgonoise/logx"
+)
+
+// TestAllocs guards the pooled hot path against allocation regressions.
+//
+// Attributes whose values do not fit in an interface word (like strings or
+// large integers) still allocate when boxed by their attr.Attr's Value method
+func TestAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates on its own")
+	}
+
+	localLogger := logx.New(logx.WithHandler(jsonh.New(io.Discard)), logx.WithRecordPool(true))
+
+	for _, test := range []struct {
+		name  string
+		attrs []attr.Attr
+	}{
+		{name: "NoAttrs"},
+		{name: "Primitives", attrs: []attr.Attr{
+			attr.New("ok", true),
+			attr.Int("attempt", int64(3)),
+			attr.Uint("code", uint64(200)),
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(100, func() {
+				localLogger.Info(longMsg, test.attrs...)
+			})
+
+			if allocs > 0 {
+				t.Errorf("unexpected allocations: wanted 0 ; got %v", allocs)
+			}
+		})
+	}
+}
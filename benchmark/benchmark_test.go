@@ -2,37 +2,49 @@ package benchmark
 
 import (
 	"bytes"
+	"context"
+	"io"
+	"log/slog"
 	"testing"
 
+	"github.com/rs/zerolog"
 	"github.com/zalgonoise/attr"
 	"github.com/zalgonoise/logx/handlers/jsonh"
 	"github.com/zalgonoise/logx/handlers/texth"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	logx "github.com/zalgonoise/logx"
 )
 
+const (
+	msg     = "benchmark test log event"
+	longMsg = "this is a long message describing a benchmark test log event"
+)
+
+var (
+	newMeta = []attr.Attr{
+		attr.New("complex", true),
+		attr.New("id", 1234567890),
+		attr.New("content", []attr.Attr{attr.New("data", true)}),
+		attr.New("affected", []string{"none", "nothing", "nada"}),
+	}
+	primitiveMeta = []attr.Attr{
+		attr.String("user", "gopher"),
+		attr.Int("id", int64(1234567890)),
+		attr.Uint("attempt", uint64(3)),
+		attr.Float("ratio", 0.75),
+		attr.New("ok", true),
+	}
+)
+
 func BenchmarkLogger(b *testing.B) {
-	const (
-		prefix  = "benchmark"
-		sub     = "test"
-		msg     = "benchmark test log event"
-		longMsg = "this is a long message describing a benchmark test log event"
-	)
-
-	var (
-		newMeta = []attr.Attr{
-			attr.New("complex", true),
-			attr.New("id", 1234567890),
-			attr.New("content", []attr.Attr{attr.New("data", true)}),
-			attr.New("affected", []string{"none", "nothing", "nada"}),
-		}
-		buf = new(bytes.Buffer)
-	)
+	buf := new(bytes.Buffer)
 
 	b.Run("Writing", func(b *testing.B) {
 		b.Run("SimpleText", func(b *testing.B) {
 			b.Run("LogX", func(b *testing.B) {
-				localLogger := logx.New(texth.New(buf))
+				localLogger := logx.New(logx.WithHandler(texth.New(buf)))
 
 				b.ResetTimer()
 				for n := 0; n < b.N; n++ {
@@ -43,7 +55,7 @@ func BenchmarkLogger(b *testing.B) {
 		})
 		b.Run("SimpleJSON", func(b *testing.B) {
 			b.Run("LogX", func(b *testing.B) {
-				localLogger := logx.New(jsonh.New(buf))
+				localLogger := logx.New(logx.WithHandler(jsonh.New(buf)))
 
 				b.ResetTimer()
 				for n := 0; n < b.N; n++ {
@@ -55,7 +67,7 @@ func BenchmarkLogger(b *testing.B) {
 
 		b.Run("ComplexText", func(b *testing.B) {
 			b.Run("LogX", func(b *testing.B) {
-				localLogger := logx.New(texth.New(buf))
+				localLogger := logx.New(logx.WithHandler(texth.New(buf)))
 
 				b.ResetTimer()
 				for n := 0; n < b.N; n++ {
@@ -66,7 +78,7 @@ func BenchmarkLogger(b *testing.B) {
 		})
 		b.Run("ComplexJSON", func(b *testing.B) {
 			b.Run("LogX", func(b *testing.B) {
-				localLogger := logx.New(jsonh.New(buf))
+				localLogger := logx.New(logx.WithHandler(jsonh.New(buf)))
 
 				b.ResetTimer()
 				for n := 0; n < b.N; n++ {
@@ -77,3 +89,129 @@ func BenchmarkLogger(b *testing.B) {
 		})
 	})
 }
+
+// BenchmarkCompare logs the same events with logx and other structured
+// loggers, writing JSON to io.Discard
+func BenchmarkCompare(b *testing.B) {
+	b.Run("Simple", func(b *testing.B) {
+		b.Run("LogX", func(b *testing.B) {
+			localLogger := logx.New(logx.WithHandler(jsonh.New(io.Discard)))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				localLogger.Info(msg)
+			}
+		})
+		b.Run("LogXPooled", func(b *testing.B) {
+			localLogger := logx.New(logx.WithHandler(jsonh.New(io.Discard)), logx.WithRecordPool(true))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				localLogger.Info(msg)
+			}
+		})
+		b.Run("Slog", func(b *testing.B) {
+			localLogger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				localLogger.Info(msg)
+			}
+		})
+		b.Run("Zap", func(b *testing.B) {
+			localLogger := newZap()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				localLogger.Info(msg)
+			}
+		})
+		b.Run("Zerolog", func(b *testing.B) {
+			localLogger := zerolog.New(io.Discard).With().Timestamp().Logger()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				localLogger.Info().Msg(msg)
+			}
+		})
+	})
+
+	b.Run("Primitives", func(b *testing.B) {
+		b.Run("LogX", func(b *testing.B) {
+			localLogger := logx.New(logx.WithHandler(jsonh.New(io.Discard)))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				localLogger.Info(longMsg, primitiveMeta...)
+			}
+		})
+		b.Run("LogXPooled", func(b *testing.B) {
+			localLogger := logx.New(logx.WithHandler(jsonh.New(io.Discard)), logx.WithRecordPool(true))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				localLogger.Info(longMsg, primitiveMeta...)
+			}
+		})
+		b.Run("Slog", func(b *testing.B) {
+			localLogger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				localLogger.LogAttrs(context.Background(), slog.LevelInfo, longMsg,
+					slog.String("user", "gopher"),
+					slog.Int64("id", 1234567890),
+					slog.Uint64("attempt", 3),
+					slog.Float64("ratio", 0.75),
+					slog.Bool("ok", true),
+				)
+			}
+		})
+		b.Run("Zap", func(b *testing.B) {
+			localLogger := newZap()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				localLogger.Info(longMsg,
+					zap.String("user", "gopher"),
+					zap.Int64("id", 1234567890),
+					zap.Uint64("attempt", 3),
+					zap.Float64("ratio", 0.75),
+					zap.Bool("ok", true),
+				)
+			}
+		})
+		b.Run("Zerolog", func(b *testing.B) {
+			localLogger := zerolog.New(io.Discard).With().Timestamp().Logger()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				localLogger.Info().
+					Str("user", "gopher").
+					Int64("id", 1234567890).
+					Uint64("attempt", 3).
+					Float64("ratio", 0.75).
+					Bool("ok", true).
+					Msg(longMsg)
+			}
+		})
+	})
+}
+
+func newZap() *zap.Logger {
+	return zap.New(zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		zapcore.AddSync(io.Discard),
+		zapcore.InfoLevel,
+	))
+}
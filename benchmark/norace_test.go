@@ -0,0 +1,6 @@
+//go:build !race
+
+package benchmark
+
+// raceEnabled is set when the race detector is on, which allocates on its own
+const raceEnabled = false
@@ -25,7 +25,7 @@ func main() {
 	defer pprof.StopCPUProfile()
 
 	buf := &bytes.Buffer{}
-	l := log.New(jsonh.New(buf))
+	l := log.New(log.WithHandler(jsonh.New(buf)))
 	a := []attr.Attr{
 		attr.New("complex", true),
 		attr.New("id", 1234567890),
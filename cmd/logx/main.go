@@ -0,0 +1,175 @@
+// Command logx renders the NDJSON output of logx handlers as colorized,
+// aligned console lines, to tail production logs locally:
+//
+//	kubectl logs -f deploy/api | logx -level warn -keys request_id,status
+//
+// Lines that are not logx records are printed as-is.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+type printer struct {
+	w       *bufio.Writer
+	min     level.Level
+	keys    map[string]struct{}
+	theme   handlers.Theme
+	timeFmt string
+	width   int
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "logx:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, r io.Reader, w io.Writer) error {
+	fs := flag.NewFlagSet("logx", flag.ContinueOnError)
+	var (
+		minLevel = fs.String("level", "", "minimum level of the records to print (e.g. info, warn)")
+		keys     = fs.String("keys", "", "comma-separated attribute keys to print; all if empty")
+		color    = fs.String("color", "auto", "colorize the output: auto, always or never")
+		theme    = fs.String("theme", "dark", "colors of the output: dark, light or monochrome")
+		timeFmt  = fs.String("time", "15:04:05.000", "layout of the records' timestamps")
+		width    = fs.Int("width", 40, "width of the message column")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p := &printer{
+		w:       bufio.NewWriter(w),
+		timeFmt: *timeFmt,
+		width:   *width,
+	}
+
+	if *minLevel != "" {
+		lv, err := level.Parse(*minLevel)
+		if err != nil {
+			return fmt.Errorf("invalid level %q: %w", *minLevel, err)
+		}
+		p.min = lv
+	}
+
+	if *keys != "" {
+		p.keys = map[string]struct{}{}
+		for _, key := range strings.Split(*keys, ",") {
+			p.keys[strings.TrimSpace(key)] = struct{}{}
+		}
+	}
+
+	t, err := handlers.ParseTheme(*theme)
+	if err != nil {
+		return err
+	}
+
+	switch *color {
+	case "always":
+		if handlers.EnableVirtualTerminal(w) {
+			p.theme = t
+		}
+	case "never":
+	case "auto":
+		if handlers.IsTerminal(w) && handlers.EnableVirtualTerminal(w) {
+			p.theme = t
+		}
+	default:
+		return fmt.Errorf("invalid color mode %q", *color)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for scanner.Scan() {
+		p.line(scanner.Text())
+		// flush line by line, for tailing
+		if err := p.w.Flush(); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (p *printer) line(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+
+	r, err := records.NewDecoder(strings.NewReader(line)).Decode()
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			p.w.WriteString(line)
+			p.w.WriteByte('\n')
+		}
+		return
+	}
+
+	if p.min != nil && r.Level().Int() < p.min.Int() {
+		return
+	}
+
+	p.theme.Paint(p.w, p.theme.Time, r.Time().Format(p.timeFmt))
+	p.w.WriteByte(' ')
+	p.theme.Paint(p.w, p.theme.Level(r.Level()), fmt.Sprintf("%-6s", strings.ToUpper(r.Level().String())))
+	p.w.WriteByte(' ')
+
+	var fields []string
+	p.flatten(&fields, "", r.Attrs())
+
+	if len(fields) == 0 {
+		p.theme.Paint(p.w, p.theme.Message, r.Message())
+		p.w.WriteByte('\n')
+		return
+	}
+
+	p.theme.Paint(p.w, p.theme.Message, fmt.Sprintf("%-*s", p.width, r.Message()))
+	for i := 0; i < len(fields); i += 2 {
+		p.w.WriteByte(' ')
+		p.theme.Paint(p.w, p.theme.Key, fields[i]+"=")
+		p.theme.Paint(p.w, p.theme.Value, fields[i+1])
+	}
+	p.w.WriteByte('\n')
+}
+
+// flatten appends the selected attributes as key and value pairs to
+// `fields`, with nested groups joined with dots (e.g. `http.status`)
+func (p *printer) flatten(fields *[]string, prefix string, attrs []attr.Attr) {
+	for _, a := range attrs {
+		key := prefix + a.Key()
+
+		if group, ok := a.Value().([]attr.Attr); ok {
+			if p.selected(key) {
+				// selecting a group prints all of its attributes
+				(&printer{}).flatten(fields, key+".", group)
+				continue
+			}
+			p.flatten(fields, key+".", group)
+			continue
+		}
+
+		if p.selected(key) {
+			*fields = append(*fields, key, handlers.FormatValue(a.Value()))
+		}
+	}
+}
+
+func (p *printer) selected(key string) bool {
+	if p.keys == nil {
+		return true
+	}
+	_, ok := p.keys[key]
+	return ok
+}
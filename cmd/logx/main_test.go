@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testInput = `{"timestamp":"2024-05-06T07:08:09.123Z","message":"request served","level":"info","data":{"http":{"method":"GET","status":200},"path":"/items","request_id":"abc"}}
+{"timestamp":"2024-05-06T07:08:10.456Z","message":"cache miss","level":"warn","data":{"key":"users 42"}}
+plain text line
+{"timestamp":"2024-05-06T07:08:11.789Z","message":"debugging","level":"debug"}
+`
+
+func TestRun(t *testing.T) {
+	for _, testcase := range []struct {
+		name  string
+		args  []string
+		wants string
+	}{
+		{
+			name: "Default",
+			args: []string{"-color", "never", "-width", "16"},
+			wants: "07:08:09.123 INFO   request served   http.method=GET http.status=200 path=/items request_id=abc\n" +
+				"07:08:10.456 WARN   cache miss       key=\"users 42\"\n" +
+				"plain text line\n" +
+				"07:08:11.789 DEBUG  debugging\n",
+		},
+		{
+			name: "Level",
+			args: []string{"-color", "never", "-level", "warn", "-width", "0"},
+			wants: "07:08:10.456 WARN   cache miss key=\"users 42\"\n" +
+				"plain text line\n",
+		},
+		{
+			name: "Keys",
+			args: []string{"-color", "never", "-keys", "request_id,http", "-width", "0"},
+			wants: "07:08:09.123 INFO   request served http.method=GET http.status=200 request_id=abc\n" +
+				"07:08:10.456 WARN   cache miss\n" +
+				"plain text line\n" +
+				"07:08:11.789 DEBUG  debugging\n",
+		},
+		{
+			name:  "Color",
+			args:  []string{"-color", "always", "-level", "error"},
+			wants: "plain text line\n",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := run(testcase.args, strings.NewReader(testInput), b); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if b.String() != testcase.wants {
+				t.Errorf("output mismatch error: wanted\n%s\n; got\n%s", testcase.wants, b.String())
+			}
+		})
+	}
+
+	t.Run("Colorized", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		if err := run([]string{"-color", "always", "-level", "warn"}, strings.NewReader(testInput), b); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if !strings.Contains(b.String(), "\x1b[33mWARN  \x1b[0m") {
+			t.Errorf("output mismatch error: wanted a yellow warn level ; got %q", b.String())
+		}
+	})
+	t.Run("Theme", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		if err := run([]string{"-color", "always", "-theme", "light", "-level", "warn"}, strings.NewReader(testInput), b); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if !strings.Contains(b.String(), "\x1b[1;33mWARN  \x1b[0m") {
+			t.Errorf("output mismatch error: wanted a bold yellow warn level ; got %q", b.String())
+		}
+	})
+	t.Run("InvalidFlags", func(t *testing.T) {
+		for _, args := range [][]string{{"-level", "loud"}, {"-color", "sometimes"}, {"-theme", "neon"}} {
+			if err := run(args, strings.NewReader(""), &bytes.Buffer{}); err == nil {
+				t.Errorf("unexpected error: wanted an error for %v ; got %v", args, err)
+			}
+		}
+	})
+}
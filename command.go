@@ -0,0 +1,208 @@
+//go:build !logx_tiny
+
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+)
+
+// maxCommandLine is the size from which a line with no line break is logged as
+// a record, to bound the memory held for it
+const maxCommandLine = 64 << 10
+
+// CommandOption describes a setting applied to a CommandWriter
+type CommandOption func(*CommandWriter)
+
+// WithCommandLevel sets the level that the lines of a CommandWriter are logged
+// with, which defaults to level.Info (or to the level of JSON lines, if set)
+func WithCommandLevel(lv level.Level) CommandOption {
+	return func(w *CommandWriter) {
+		if lv != nil {
+			w.lv = lv
+		}
+	}
+}
+
+// WithJSONLines sets whether a CommandWriter detects the lines holding JSON
+// objects, as written by structured loggers, logging their fields as
+// attributes instead of the raw line. The record's message and level are read
+// from the object's `msg` or `message`, and `level` or `severity` fields
+func WithJSONLines(enabled bool) CommandOption {
+	return func(w *CommandWriter) {
+		w.json = enabled
+	}
+}
+
+// CommandWriter is an io.Writer that logs each line written to it as a record,
+// tagged with the name of a command and the stream it comes from, turning the
+// output of a subprocess into structured records (see CaptureCommand).
+//
+// Lines are split on line breaks; a trailing line with no line break is only
+// logged when the CommandWriter is flushed (with Close)
+type CommandWriter struct {
+	l    Logger
+	lv   level.Level
+	json bool
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewCommandWriter creates a CommandWriter logging the lines of the stream
+// `stream` (like `stdout`) of the command `name` with the Logger `logger` (or
+// the standard Logger, if nil), with `command` and `stream` attributes
+func NewCommandWriter(logger Logger, name, stream string, opts ...CommandOption) *CommandWriter {
+	if logger == nil {
+		logger = std
+	}
+
+	w := &CommandWriter{
+		l:  logger.With(attr.String("command", name), attr.String("stream", stream)),
+		lv: level.Info,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(w)
+		}
+	}
+	return w
+}
+
+// Write implements io.Writer, logging the complete lines in `p`
+func (w *CommandWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		w.line(w.buf[:idx])
+		w.buf = w.buf[idx+1:]
+	}
+
+	if len(w.buf) >= maxCommandLine {
+		w.line(w.buf)
+		w.buf = w.buf[:0]
+	}
+	// release the consumed part of the buffer
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+// Close implements io.Closer, logging the trailing line with no line break, if
+// any
+func (w *CommandWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.line(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+func (w *CommandWriter) line(b []byte) {
+	b = bytes.TrimSuffix(b, []byte("\r"))
+	if len(bytes.TrimSpace(b)) == 0 {
+		return
+	}
+
+	if w.json && b[0] == '{' {
+		var fields map[string]any
+		if err := json.Unmarshal(b, &fields); err == nil {
+			lv, msg, attrs := w.jsonRecord(fields)
+			w.l.Log(lv, msg, attrs...)
+			return
+		}
+	}
+
+	w.l.Log(w.lv, string(b))
+}
+
+// jsonRecord returns the level, message and attributes of a JSON line, from
+// its fields `fields`
+func (w *CommandWriter) jsonRecord(fields map[string]any) (level.Level, string, []attr.Attr) {
+	lv := w.lv
+	for _, key := range [...]string{"level", "severity"} {
+		if s, ok := fields[key].(string); ok {
+			if parsed, err := level.Parse(s); err == nil {
+				lv = parsed
+				delete(fields, key)
+				break
+			}
+		}
+	}
+
+	var msg string
+	for _, key := range [...]string{"msg", "message"} {
+		if s, ok := fields[key].(string); ok {
+			msg = s
+			delete(fields, key)
+			break
+		}
+	}
+	if msg == "" {
+		msg = "command output"
+	}
+
+	return lv, msg, jsonAttrs(fields)
+}
+
+// jsonAttrs converts the decoded JSON object `fields` into attributes, sorted
+// by key. Nested objects become attribute groups
+func jsonAttrs(fields map[string]any) []attr.Attr {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]attr.Attr, 0, len(keys))
+	for _, key := range keys {
+		if obj, ok := fields[key].(map[string]any); ok {
+			attrs = append(attrs, attr.New(key, jsonAttrs(obj)))
+			continue
+		}
+		attrs = append(attrs, attr.New(key, fields[key]))
+	}
+	return attrs
+}
+
+// CaptureCommand sets the standard output and error of the command `cmd` to
+// CommandWriters logging with the Logger `logger`, named after the command's
+// executable. Standard error lines are logged with level.Warn, unless set
+// otherwise with the options `opts`.
+//
+// The returned function logs the trailing lines with no line break, and is
+// meant to be called once the command is done:
+//
+//	flush := logx.CaptureCommand(logger, cmd, logx.WithJSONLines(true))
+//	err := cmd.Run()
+//	flush()
+func CaptureCommand(logger Logger, cmd *exec.Cmd, opts ...CommandOption) (flush func()) {
+	name := filepath.Base(cmd.Path)
+
+	stdout := NewCommandWriter(logger, name, "stdout", opts...)
+	stderr := NewCommandWriter(logger, name, "stderr", append([]CommandOption{WithCommandLevel(level.Warn)}, opts...)...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	return func() {
+		_ = stdout.Close()
+		_ = stderr.Close()
+	}
+}
@@ -0,0 +1,104 @@
+//go:build !logx_tiny
+
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"testing"
+
+	"github.com/zalgonoise/logx/handlers/jsonh"
+)
+
+type commandEntry struct {
+	Message string         `json:"message"`
+	Level   string         `json:"level"`
+	Data    map[string]any `json:"data"`
+}
+
+func decodeCommandEntries(t *testing.T, b *bytes.Buffer) []commandEntry {
+	var entries []commandEntry
+	for dec := json.NewDecoder(b); dec.More(); {
+		var e commandEntry
+		if err := dec.Decode(&e); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return nil
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestCommandWriter(t *testing.T) {
+	t.Run("Lines", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		w := NewCommandWriter(New(WithHandler(jsonh.New(b))), "migrate", "stdout")
+
+		_, _ = w.Write([]byte("applying 001\r\napply"))
+		_, _ = w.Write([]byte("ing 002\n\ndone"))
+		_ = w.Close()
+
+		wants := []string{"applying 001", "applying 002", "done"}
+		entries := decodeCommandEntries(t, b)
+		if len(entries) != len(wants) {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", len(wants), len(entries))
+			return
+		}
+		for i := range wants {
+			if entries[i].Message != wants[i] || entries[i].Level != "info" {
+				t.Errorf("output mismatch error: wanted %v ; got %v", wants[i], entries[i].Message)
+			}
+			if entries[i].Data["command"] != "migrate" || entries[i].Data["stream"] != "stdout" {
+				t.Errorf("output mismatch error: wanted the command attributes ; got %v", entries[i].Data)
+			}
+		}
+	})
+
+	t.Run("JSONLines", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		w := NewCommandWriter(New(WithHandler(jsonh.New(b))), "worker", "stderr", WithJSONLines(true))
+
+		_, _ = w.Write([]byte(`{"level":"ERROR","msg":"job failed","job":{"id":7}}` + "\n{not json}\n"))
+
+		entries := decodeCommandEntries(t, b)
+		if len(entries) != 2 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 2, len(entries))
+			return
+		}
+		if e := entries[0]; e.Message != "job failed" || e.Level != "error" {
+			t.Errorf("output mismatch error: wanted %s at error level ; got %s at %s level", "job failed", e.Message, e.Level)
+		}
+		if job, _ := entries[0].Data["job"].(map[string]any); job["id"] != float64(7) {
+			t.Errorf("output mismatch error: wanted the job group ; got %v", entries[0].Data)
+		}
+		if entries[1].Message != "{not json}" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "{not json}", entries[1].Message)
+		}
+	})
+}
+
+func TestCaptureCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell available")
+	}
+
+	b := &bytes.Buffer{}
+	cmd := exec.Command("sh", "-c", "echo out; echo err >&2; printf partial")
+	flush := CaptureCommand(New(WithHandler(jsonh.New(b))), cmd)
+	if err := cmd.Run(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	flush()
+
+	levels := map[string]string{}
+	for _, e := range decodeCommandEntries(t, b) {
+		levels[e.Message] = e.Level
+	}
+	for msg, wants := range map[string]string{"out": "info", "err": "warn", "partial": "info"} {
+		if levels[msg] != wants {
+			t.Errorf("output mismatch error for %s: wanted %v ; got %v", msg, wants, levels[msg])
+		}
+	}
+}
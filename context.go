@@ -18,7 +18,7 @@ const StandardCtxKey CtxLoggerKey = "logger"
 func InContext(ctx context.Context, logger Logger) context.Context {
 	if ctx == nil || logger == nil {
 		// context logger exists but doesn't do anything
-		return context.WithValue(ctx, StandardCtxKey, New(handlers.Unimpl()))
+		return context.WithValue(ctx, StandardCtxKey, New(WithHandler(handlers.Unimpl())))
 	}
 	return context.WithValue(ctx, StandardCtxKey, logger)
 }
@@ -32,5 +32,5 @@ func From(ctx context.Context) Logger {
 	}
 
 	// return logger from context but doesn't do anything
-	return New(handlers.Unimpl())
+	return New(WithHandler(handlers.Unimpl()))
 }
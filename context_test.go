@@ -12,7 +12,7 @@ import (
 func TestInContext(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		b := &bytes.Buffer{}
-		wants := New(jsonh.New(b))
+		wants := New(WithHandler(jsonh.New(b)))
 		input := InContext(context.Background(), wants)
 
 		v := input.Value(StandardCtxKey)
@@ -33,7 +33,7 @@ func TestInContext(t *testing.T) {
 	})
 	t.Run("FailNoContext", func(t *testing.T) {
 		b := &bytes.Buffer{}
-		l := New(jsonh.New(b))
+		l := New(WithHandler(jsonh.New(b)))
 		out := InContext(nil, l)
 
 		if out != nil {
@@ -59,7 +59,7 @@ func TestInContext(t *testing.T) {
 func TestFrom(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		b := &bytes.Buffer{}
-		wants := New(jsonh.New(b))
+		wants := New(WithHandler(jsonh.New(b)))
 		input := context.WithValue(context.Background(), StandardCtxKey, wants)
 
 		out := From(input)
@@ -0,0 +1,231 @@
+package logx
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zalgonoise/attr"
+)
+
+const (
+	diffBeforeKey    = "before"
+	diffAfterKey     = "after"
+	diffTruncatedKey = "_truncated"
+
+	// diffMaxDepth is how deep Diff walks into nested values; the values below
+	// it are compared (and logged) as a whole
+	diffMaxDepth = 8
+	// diffMaxChanges is the number of changed fields listed by Diff
+	diffMaxChanges = 64
+	// diffMaxElems is the number of slice elements and map entries compared by
+	// Diff, for each slice or map
+	diffMaxElems = 1024
+)
+
+// Diff creates an attribute with key `key` listing the fields changed from
+// `before` to `after` (like two versions of an updated entity), each one as a
+// group with its `before` and `after` values, for audit-style change logs:
+//
+//	logger.Info("user updated", logx.Diff("user", old, updated))
+//
+// Struct fields (named after their JSON tags, if set), map entries and slice
+// elements are compared recursively and listed by their dotted path, like
+// `address.city` or `roles.1`; unexported struct fields are ignored. Values
+// nested deeper than 8 levels are compared as a whole, and only the first 64
+// changes are listed, with a `_truncated` attribute marking the rest.
+//
+// Returns nil if there are no changes
+func Diff(key string, before, after any) attr.Attr {
+	d := &differ{}
+	d.diff("", reflect.ValueOf(before), reflect.ValueOf(after), 0)
+
+	// the values themselves changed, rather than their fields
+	if d.root != nil {
+		return attr.New(key, d.root)
+	}
+
+	if len(d.changes) == 0 {
+		return nil
+	}
+
+	if d.truncated {
+		d.changes = append(d.changes, attr.New(diffTruncatedKey, true))
+	}
+
+	return attr.New(key, d.changes)
+}
+
+// differ accumulates the changes found by Diff
+type differ struct {
+	root      []attr.Attr
+	changes   []attr.Attr
+	truncated bool
+	full      bool
+}
+
+// diff compares the values `a` and `b` at the path `path`
+func (d *differ) diff(path string, a, b reflect.Value, depth int) {
+	if d.full {
+		return
+	}
+
+	a, b = diffIndirect(a), diffIndirect(b)
+
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return
+	case !a.IsValid() || !b.IsValid(), a.Type() != b.Type(), depth >= diffMaxDepth:
+		d.compare(path, a, b)
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Struct:
+		if a.Type() == timeType {
+			if !a.Interface().(time.Time).Equal(b.Interface().(time.Time)) {
+				d.add(path, a, b)
+			}
+			return
+		}
+		d.diffStruct(path, a, b, depth)
+	case reflect.Map:
+		d.diffMap(path, a, b, depth)
+	case reflect.Slice, reflect.Array:
+		if a.Kind() == reflect.Slice && a.Type().Elem().Kind() == reflect.Uint8 {
+			d.compare(path, a, b)
+			return
+		}
+		d.diffSlice(path, a, b, depth)
+	default:
+		d.compare(path, a, b)
+	}
+}
+
+func (d *differ) diffStruct(path string, a, b reflect.Value, depth int) {
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tag, _, _ = strings.Cut(tag, ",")
+			switch tag {
+			case "-":
+				continue
+			case "":
+			default:
+				name = tag
+			}
+		}
+
+		d.diff(diffPath(path, name), a.Field(i), b.Field(i), depth+1)
+	}
+}
+
+func (d *differ) diffMap(path string, a, b reflect.Value, depth int) {
+	keys := make(map[string]reflect.Value, a.Len()+b.Len())
+	for _, m := range []reflect.Value{a, b} {
+		iter := m.MapRange()
+		for n := 0; iter.Next(); n++ {
+			if n == diffMaxElems {
+				d.truncated = true
+				break
+			}
+			keys[fmt.Sprint(iter.Key().Interface())] = iter.Key()
+		}
+	}
+
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		d.diff(diffPath(path, name), a.MapIndex(keys[name]), b.MapIndex(keys[name]), depth+1)
+	}
+}
+
+func (d *differ) diffSlice(path string, a, b reflect.Value, depth int) {
+	n := a.Len()
+	if b.Len() > n {
+		n = b.Len()
+	}
+	if n > diffMaxElems {
+		n = diffMaxElems
+		d.truncated = true
+	}
+
+	for i := 0; i < n; i++ {
+		var ea, eb reflect.Value
+		if i < a.Len() {
+			ea = a.Index(i)
+		}
+		if i < b.Len() {
+			eb = b.Index(i)
+		}
+		d.diff(diffPath(path, strconv.Itoa(i)), ea, eb, depth+1)
+	}
+}
+
+// compare adds a change at the path `path` if the values `a` and `b` differ
+func (d *differ) compare(path string, a, b reflect.Value) {
+	if a.IsValid() && b.IsValid() && a.Type() == b.Type() && reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return
+	}
+	d.add(path, a, b)
+}
+
+// add lists the change from `a` to `b` at the path `path`, with the invalid
+// values (absent, or nil) as nil
+func (d *differ) add(path string, a, b reflect.Value) {
+	change := []attr.Attr{
+		attr.New(diffBeforeKey, diffValue(a)),
+		attr.New(diffAfterKey, diffValue(b)),
+	}
+
+	if path == "" {
+		d.root = change
+		return
+	}
+
+	if len(d.changes) == diffMaxChanges {
+		d.truncated, d.full = true, true
+		return
+	}
+
+	d.changes = append(d.changes, attr.New(path, change))
+}
+
+// diffIndirect dereferences the pointers and interfaces in `v`, returning an
+// invalid reflect.Value for nil ones
+func diffIndirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func diffValue(v reflect.Value) any {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func diffPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,138 @@
+package logx
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+)
+
+type testAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type testUser struct {
+	Name     string `json:"name"`
+	Email    string
+	Password string       `json:"-"`
+	Address  *testAddress `json:"address"`
+	Roles    []string     `json:"roles"`
+	Labels   map[string]int
+	Updated  time.Time
+	internal int
+}
+
+func change(path string, before, after any) attr.Attr {
+	return attr.New(path, []attr.Attr{attr.New("before", before), attr.New("after", after)})
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+	base := testUser{
+		Name:     "gopher",
+		Email:    "gopher@example.com",
+		Password: "secret",
+		Address:  &testAddress{City: "Lisbon", Zip: "1000"},
+		Roles:    []string{"user"},
+		Labels:   map[string]int{"a": 1},
+		Updated:  now,
+	}
+
+	for _, testcase := range []struct {
+		name   string
+		before any
+		after  any
+		wants  attr.Attr
+	}{
+		{
+			name:   "NoChanges",
+			before: base,
+			after: func() testUser {
+				u := base
+				u.Address = &testAddress{City: "Lisbon", Zip: "1000"}
+				u.Updated = now.UTC()
+				u.internal = 7
+				return u
+			}(),
+		},
+		{
+			name:   "Fields",
+			before: base,
+			after: func() testUser {
+				u := base
+				u.Email = "new@example.com"
+				u.Password = "changed"
+				u.Address = &testAddress{City: "Porto", Zip: "1000"}
+				u.Roles = []string{"user", "admin"}
+				u.Labels = map[string]int{"b": 2}
+				return u
+			}(),
+			wants: attr.New("user", []attr.Attr{
+				change("Email", "gopher@example.com", "new@example.com"),
+				change("address.city", "Lisbon", "Porto"),
+				change("roles.1", nil, "admin"),
+				change("Labels.a", 1, nil),
+				change("Labels.b", nil, 2),
+			}),
+		},
+		{
+			name:   "NilPointer",
+			before: &testAddress{City: "Lisbon"},
+			after:  (*testAddress)(nil),
+			wants:  change("user", testAddress{City: "Lisbon"}, nil),
+		},
+		{
+			name:   "Scalars",
+			before: 1,
+			after:  2,
+			wants:  change("user", 1, 2),
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			got := Diff("user", testcase.before, testcase.after)
+			if !reflect.DeepEqual(got, testcase.wants) {
+				t.Errorf("output mismatch error: wanted %v ; got %v", testcase.wants, got)
+			}
+		})
+	}
+
+	t.Run("MaxChanges", func(t *testing.T) {
+		before := make([]int, 100)
+		after := make([]int, 100)
+		for i := range after {
+			after[i] = i + 1
+		}
+
+		group, _ := Diff("values", before, after).Value().([]attr.Attr)
+		if len(group) != diffMaxChanges+1 {
+			t.Errorf("output mismatch error: wanted %v attributes ; got %v", diffMaxChanges+1, len(group))
+			return
+		}
+		if last := group[len(group)-1]; last.Key() != diffTruncatedKey {
+			t.Errorf("output mismatch error: wanted %v ; got %v", diffTruncatedKey, last.Key())
+		}
+	})
+
+	t.Run("MaxDepth", func(t *testing.T) {
+		type node struct {
+			Next *node
+			N    int
+		}
+		build := func(n int) *node {
+			root := &node{N: n}
+			cur := root
+			for i := 0; i < diffMaxDepth+2; i++ {
+				cur.Next = &node{N: n}
+				cur = cur.Next
+			}
+			return root
+		}
+
+		group, _ := Diff("list", build(1), build(2)).Value().([]attr.Attr)
+		if len(group) != diffMaxDepth+1 {
+			t.Errorf("output mismatch error: wanted %v attributes ; got %v", diffMaxDepth+1, len(group))
+		}
+	})
+}
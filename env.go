@@ -0,0 +1,109 @@
+package logx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+)
+
+const (
+	// EnvLevel is the environment variable holding the logger's level
+	EnvLevel = "LOGX_LEVEL"
+	// EnvFormat is the environment variable holding the logger's output
+	// format: `json`, `text`, `console`, `gcp` or `auto`
+	EnvFormat = "LOGX_FORMAT"
+	// EnvOutput is the environment variable holding the logger's output:
+	// `stdout`, `stderr` or a file path
+	EnvOutput = "LOGX_OUTPUT"
+	// EnvSource is the environment variable holding a boolean on whether
+	// to add source information to records
+	EnvSource = "LOGX_SOURCE"
+)
+
+// ErrInvalidFormat is raised when the format set in the environment is not
+// supported
+var ErrInvalidFormat error = errors.New("invalid format")
+
+// FromEnv creates a Logger configured from the environment variables
+// LOGX_LEVEL, LOGX_FORMAT, LOGX_OUTPUT and LOGX_SOURCE.
+//
+// Unset variables default to JSON records written to standard error, with no
+// level filter and no source information. The `console` format is rendered
+// with the text handler, and the `gcp` format with the handlers.GCP preset;
+// the `auto` format picks the latter when running in Google Cloud (see
+// handlers.OnGCP), or handlers.Auto otherwise: colorized console lines on a
+// terminal, and JSON elsewhere.
+//
+// An error is returned if any of the variables hold an invalid value, or if
+// the output file cannot be opened. The output file is registered with
+// RegisterShutdown, to be closed by Shutdown
+func FromEnv() (Logger, error) {
+	w, err := envOutput(os.Getenv(EnvOutput))
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := envLogger(w)
+	if f, ok := w.(*os.File); ok && f != os.Stdout && f != os.Stderr {
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		RegisterShutdown(f)
+	}
+	return l, err
+}
+
+// envLogger creates a Logger writing to `w`, configured from the environment
+// variables LOGX_LEVEL, LOGX_FORMAT and LOGX_SOURCE
+func envLogger(w io.Writer) (Logger, error) {
+
+	var h handlers.Handler
+	switch format := strings.ToLower(os.Getenv(EnvFormat)); {
+	case format == "gcp", format == "auto" && handlers.OnGCP():
+		h = handlers.GCP(w, "")
+	case format == "auto":
+		h = handlers.Auto(w)
+	case format == "", format == "json":
+		h = newHandler(FormatJSON, w)
+	case format == "text", format == "console":
+		h = newHandler(FormatText, w)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidFormat, os.Getenv(EnvFormat))
+	}
+
+	if v := os.Getenv(EnvLevel); v != "" {
+		lv, err := level.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvLevel, err)
+		}
+		h = h.WithLevel(lv)
+	}
+
+	if v := os.Getenv(EnvSource); v != "" {
+		addSource, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvSource, err)
+		}
+		h = h.WithSource(addSource)
+	}
+
+	return New(WithHandler(h)), nil
+}
+
+func envOutput(output string) (io.Writer, error) {
+	switch strings.ToLower(output) {
+	case "", "stderr":
+		return os.Stderr, nil
+	case "stdout":
+		return os.Stdout, nil
+	default:
+		return os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	}
+}
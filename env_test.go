@@ -0,0 +1,99 @@
+package logx
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		l, err := FromEnv()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if l == nil {
+			t.Errorf("expected output logger not to be nil")
+		}
+	})
+	t.Run("TextFile", func(t *testing.T) {
+		skipTiny(t)
+
+		path := filepath.Join(t.TempDir(), "app.log")
+		t.Setenv(EnvFormat, "console")
+		t.Setenv(EnvOutput, path)
+		t.Setenv(EnvLevel, "WARN")
+
+		l, err := FromEnv()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if l.Enabled(level.Info) {
+			t.Errorf("expected logger not to be enabled for info")
+		}
+
+		l.Warn("test message")
+		out, _ := os.ReadFile(path)
+		if !strings.Contains(string(out), "[warn] test message") {
+			t.Errorf("unexpected output: %s", string(out))
+		}
+	})
+	t.Run("AutoGCP", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		t.Setenv(EnvFormat, "auto")
+		t.Setenv(EnvOutput, path)
+		t.Setenv("K_SERVICE", "api")
+
+		l, err := FromEnv()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		l.Warn("test message")
+		out, _ := os.ReadFile(path)
+		if !strings.Contains(string(out), `"severity":"WARNING"`) {
+			t.Errorf("unexpected output: %s", string(out))
+		}
+
+		// the output file is registered to be closed by Shutdown
+		shutdownTargets.mu.Lock()
+		n := len(shutdownTargets.targets)
+		f, _ := shutdownTargets.targets[n-1].(*os.File)
+		shutdownTargets.targets = shutdownTargets.targets[:n-1]
+		shutdownTargets.mu.Unlock()
+
+		if f == nil || f.Name() != path {
+			t.Errorf("output mismatch error: wanted %v registered ; got %v", path, f)
+			return
+		}
+		_ = f.Close()
+	})
+	t.Run("InvalidFormat", func(t *testing.T) {
+		t.Setenv(EnvFormat, "xml")
+
+		if _, err := FromEnv(); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrInvalidFormat, err)
+		}
+	})
+	t.Run("InvalidLevel", func(t *testing.T) {
+		t.Setenv(EnvLevel, "verbose")
+
+		if _, err := FromEnv(); !errors.Is(err, level.ErrInvalidLevel) {
+			t.Errorf("unexpected error: wanted %v ; got %v", level.ErrInvalidLevel, err)
+		}
+	})
+	t.Run("InvalidSource", func(t *testing.T) {
+		t.Setenv(EnvSource, "maybe")
+
+		if _, err := FromEnv(); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package logx
+
+import (
+	"sync/atomic"
+
+	"github.com/zalgonoise/logx/internal/stats"
+	"github.com/zalgonoise/logx/records"
+)
+
+// ErrorHandler is a function called when a Logger's Handler fails to handle
+// the Record `r`, with the raised error `err`
+//
+// The Record is only valid for the duration of the call
+type ErrorHandler func(err error, r records.Record)
+
+var errorHandler atomic.Pointer[ErrorHandler]
+
+// SetErrorHandler sets the ErrorHandler `fn` called when a Handler fails, for
+// all Loggers without their own ErrorHandler (see the WithErrorHandler option).
+//
+// Setting a nil ErrorHandler discards these errors, which is the default
+func SetErrorHandler(fn ErrorHandler) {
+	if fn == nil {
+		errorHandler.Store(nil)
+		return
+	}
+	errorHandler.Store(&fn)
+}
+
+func (l *logger) handleError(err error, r records.Record) {
+	stats.Errors.Add(1)
+
+	if l.onError != nil {
+		l.onError(err, r)
+		return
+	}
+	if fn := errorHandler.Load(); fn != nil {
+		(*fn)(err, r)
+	}
+}
@@ -0,0 +1,59 @@
+package logx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/records"
+)
+
+var errTestWrite = errors.New("write failed")
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errTestWrite }
+
+func TestErrorHandler(t *testing.T) {
+	t.Run("Global", func(t *testing.T) {
+		var (
+			gotErr error
+			gotMsg string
+		)
+		SetErrorHandler(func(err error, r records.Record) {
+			gotErr = err
+			gotMsg = r.Message()
+		})
+		defer SetErrorHandler(nil)
+
+		New(WithHandler(jsonh.New(errWriter{}))).Info("test message")
+
+		if !errors.Is(gotErr, errTestWrite) {
+			t.Errorf("unexpected error: wanted %v ; got %v", errTestWrite, gotErr)
+		}
+		if gotMsg != "test message" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "test message", gotMsg)
+		}
+	})
+	t.Run("PerLogger", func(t *testing.T) {
+		var globalCalls, localCalls int
+		SetErrorHandler(func(error, records.Record) { globalCalls++ })
+		defer SetErrorHandler(nil)
+
+		l := New(
+			WithHandler(jsonh.New(errWriter{})),
+			WithRecordPool(true),
+			WithErrorHandler(func(error, records.Record) { localCalls++ }),
+		)
+		l.Info("test message")
+		l.With().Warn("test message")
+
+		if globalCalls != 0 || localCalls != 2 {
+			t.Errorf("output mismatch error: wanted 0 global and 2 local calls ; got %d and %d", globalCalls, localCalls)
+		}
+	})
+	t.Run("Unset", func(t *testing.T) {
+		// must not panic
+		New(WithHandler(jsonh.New(errWriter{}))).Info("test message")
+	})
+}
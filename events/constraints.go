@@ -0,0 +1,142 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+)
+
+// ErrInvalidValue is raised when an attribute is created with a value that does
+// not conform to its key's constraints
+var ErrInvalidValue error = errors.New("invalid attribute value")
+
+// number is the set of types that a NumberKey's values may have
+type number interface {
+	attr.IntRestriction | attr.UintRestriction | attr.FloatRestriction
+}
+
+// NumberKey is an attribute key whose values are numbers of type N within a
+// range, like a percentage or a port number
+type NumberKey[N number] struct {
+	key      string
+	min, max N
+	kind     reflect.Kind
+}
+
+// Number creates a NumberKey with key `key`, accepting the values from `min` to
+// `max`, inclusive:
+//
+//	var Progress = events.Number[float64]("progress", 0, 100)
+//
+//	a, err := Progress.Attr(pct)
+func Number[N number](key string, min, max N) NumberKey[N] {
+	var zero N
+	return NumberKey[N]{
+		key:  key,
+		min:  min,
+		max:  max,
+		kind: reflect.TypeOf(zero).Kind(),
+	}
+}
+
+// Attr creates an attribute with this NumberKey and the value `value`, or
+// returns an error wrapping ErrInvalidValue if it is out of range (or NaN).
+// The value is stored as an int64, uint64 or float64, like with attr.Int,
+// attr.Uint and attr.Float
+func (k NumberKey[N]) Attr(value N) (attr.Attr, error) {
+	if !(value >= k.min && value <= k.max) {
+		return nil, fmt.Errorf("%w: %q: %v is out of range [%v, %v]", ErrInvalidValue, k.key, value, k.min, k.max)
+	}
+
+	switch k.kind {
+	case reflect.Float32, reflect.Float64:
+		return attr.New(k.key, float64(value)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return attr.New(k.key, uint64(value)), nil
+	default:
+		return attr.New(k.key, int64(value)), nil
+	}
+}
+
+// MustAttr is like Attr, but panics if the value `value` is out of range
+func (k NumberKey[N]) MustAttr(value N) attr.Attr {
+	a, err := k.Attr(value)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Required returns a required Field for this NumberKey
+func (k NumberKey[N]) Required() handlers.Field {
+	return handlers.Field{Key: k.key, Kind: k.fieldKind(), Required: true}
+}
+
+// Optional returns an optional Field for this NumberKey
+func (k NumberKey[N]) Optional() handlers.Field {
+	return handlers.Field{Key: k.key, Kind: k.fieldKind()}
+}
+
+func (k NumberKey[N]) fieldKind() handlers.Kind {
+	if k.kind == reflect.Float32 || k.kind == reflect.Float64 {
+		return handlers.KindFloat
+	}
+	return handlers.KindInt
+}
+
+// EnumKey is an attribute key whose values are one of a set of strings of
+// type T, like a state or an outcome
+type EnumKey[T ~string] struct {
+	key     string
+	allowed []T
+}
+
+// Enum creates an EnumKey with key `key`, accepting only the values `allowed`:
+//
+//	type Outcome string
+//
+//	var Result = events.Enum[Outcome]("result", "success", "failure")
+func Enum[T ~string](key string, allowed ...T) EnumKey[T] {
+	return EnumKey[T]{
+		key:     key,
+		allowed: allowed,
+	}
+}
+
+// Attr creates an attribute with this EnumKey and the value `value`, or returns
+// an error wrapping ErrInvalidValue if it is not one of the allowed values
+func (k EnumKey[T]) Attr(value T) (attr.Attr, error) {
+	for _, allowed := range k.allowed {
+		if value == allowed {
+			return attr.String(k.key, string(value)), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q: %q is not one of %q", ErrInvalidValue, k.key, value, k.allowed)
+}
+
+// MustAttr is like Attr, but panics if the value `value` is not allowed
+func (k EnumKey[T]) MustAttr(value T) attr.Attr {
+	a, err := k.Attr(value)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Values returns the values allowed by this EnumKey
+func (k EnumKey[T]) Values() []T {
+	return append([]T(nil), k.allowed...)
+}
+
+// Required returns a required Field for this EnumKey
+func (k EnumKey[T]) Required() handlers.Field {
+	return handlers.Field{Key: k.key, Kind: handlers.KindString, Required: true}
+}
+
+// Optional returns an optional Field for this EnumKey
+func (k EnumKey[T]) Optional() handlers.Field {
+	return handlers.Field{Key: k.key, Kind: handlers.KindString}
+}
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/zalgonoise/logx/handlers"
+)
+
+type outcome string
+
+func TestNumber(t *testing.T) {
+	type port uint16
+
+	var (
+		progress = Number[float64]("progress", 0, 100)
+		ports    = Number[port]("port", 1, 65535)
+		retries  = Number[int]("retries", 0, 5)
+	)
+
+	for _, test := range []struct {
+		name  string
+		attr  func() (any, error)
+		wants any
+		err   error
+	}{
+		{"Float", func() (any, error) { a, err := progress.Attr(42.5); return value(a), err }, 42.5, nil},
+		{"Uint", func() (any, error) { a, err := ports.Attr(8080); return value(a), err }, uint64(8080), nil},
+		{"Int", func() (any, error) { a, err := retries.Attr(5); return value(a), err }, int64(5), nil},
+		{"Below", func() (any, error) { a, err := retries.Attr(-1); return value(a), err }, nil, ErrInvalidValue},
+		{"Above", func() (any, error) { a, err := progress.Attr(100.5); return value(a), err }, nil, ErrInvalidValue},
+		{"NaN", func() (any, error) { a, err := progress.Attr(math.NaN()); return value(a), err }, nil, ErrInvalidValue},
+		{"Zero", func() (any, error) { a, err := ports.Attr(0); return value(a), err }, nil, ErrInvalidValue},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := test.attr()
+			if !errors.Is(err, test.err) {
+				t.Errorf("unexpected error: wanted %v ; got %v", test.err, err)
+				return
+			}
+			if v != test.wants {
+				t.Errorf("output mismatch error: wanted %v (%T) ; got %v (%T)", test.wants, test.wants, v, v)
+			}
+		})
+	}
+
+	t.Run("Fields", func(t *testing.T) {
+		if f := progress.Required(); f != (handlers.Field{Key: "progress", Kind: handlers.KindFloat, Required: true}) {
+			t.Errorf("output mismatch error: got %v", f)
+		}
+		if f := ports.Optional(); f != (handlers.Field{Key: "port", Kind: handlers.KindInt}) {
+			t.Errorf("output mismatch error: got %v", f)
+		}
+	})
+
+	t.Run("MustAttr", func(t *testing.T) {
+		defer func() {
+			if r, ok := recover().(error); !ok || !errors.Is(r, ErrInvalidValue) {
+				t.Errorf("expected MustAttr to panic with %v ; got %v", ErrInvalidValue, r)
+			}
+		}()
+		retries.MustAttr(6)
+	})
+}
+
+func TestEnum(t *testing.T) {
+	result := Enum[outcome]("result", "success", "failure")
+
+	t.Run("Allowed", func(t *testing.T) {
+		a, err := result.Attr("failure")
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if a.Key() != "result" || a.Value() != "failure" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "failure", a)
+		}
+	})
+	t.Run("NotAllowed", func(t *testing.T) {
+		if _, err := result.Attr("maybe"); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrInvalidValue, err)
+		}
+	})
+	t.Run("Event", func(t *testing.T) {
+		ev := New("job done", nil, result.Required())
+		if err := ev.Validate(result.MustAttr("success")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func value(a interface{ Value() any }) any {
+	if a == nil {
+		return nil
+	}
+	return a.Value()
+}
@@ -0,0 +1,145 @@
+// Package events allows declaring the event types that a service logs, with
+// their level and attributes, so that they are logged consistently across
+// call sites (and services):
+//
+//	var (
+//		UserID = events.Key[string]("user_id")
+//
+//		UserLogin = events.New("user logged in", level.Info, UserID.Required())
+//	)
+//
+//	events.Wrap(logger).Event(UserLogin, UserID.Attr(id))
+//
+// Keys are typed, so that the attributes built with them carry a value of the
+// declared type, while the presence of the required attributes is validated
+// when the event is logged. Number and Enum keys further restrict the values
+// of their attributes, to a range or a set of allowed values, failing fast
+// when an attribute is created with an invalid value
+package events
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// ErrInvalidEvent is raised when an event is logged with attributes that do
+// not conform to its definition
+var ErrInvalidEvent error = errors.New("invalid event")
+
+// Key is an attribute key whose values are of type T
+type Key[T any] string
+
+// Attr creates an attribute with this Key and the value `value`
+func (k Key[T]) Attr(value T) attr.Attr {
+	return attr.New(string(k), value)
+}
+
+// Required returns a required Field for this Key, with the Kind matching T
+func (k Key[T]) Required() handlers.Field {
+	return handlers.Field{Key: string(k), Kind: kindOf[T](), Required: true}
+}
+
+// Optional returns an optional Field for this Key, with the Kind matching T
+func (k Key[T]) Optional() handlers.Field {
+	return handlers.Field{Key: string(k), Kind: kindOf[T]()}
+}
+
+func kindOf[T any]() handlers.Kind {
+	var zero T
+
+	switch any(zero).(type) {
+	case time.Duration:
+		return handlers.KindDuration
+	case string:
+		return handlers.KindString
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return handlers.KindInt
+	case float32, float64:
+		return handlers.KindFloat
+	case bool:
+		return handlers.KindBool
+	case time.Time:
+		return handlers.KindTime
+	case []attr.Attr, attr.Attrs:
+		return handlers.KindGroup
+	default:
+		return handlers.KindAny
+	}
+}
+
+// Event describes a type of log event, logged with the message Name
+type Event struct {
+	// Name is the event's log message
+	Name string
+	// Level is the event's log level; Info if nil
+	Level level.Level
+	// Fields are the attributes that the event carries
+	Fields []handlers.Field
+}
+
+// New creates an Event with name `name`, level `lv` and fields `fields`
+func New(name string, lv level.Level, fields ...handlers.Field) Event {
+	return Event{
+		Name:   name,
+		Level:  lv,
+		Fields: fields,
+	}
+}
+
+// Validate checks the attributes `attrs` against the Event's fields, returning
+// an error wrapping ErrInvalidEvent with the violations found, if any
+func (e Event) Validate(attrs ...attr.Attr) error {
+	violations := handlers.Schema{Fields: e.Fields}.Validate(records.New(time.Time{}, e.level(), e.Name, attrs...))
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %q: %v", ErrInvalidEvent, e.Name, violations)
+}
+
+func (e Event) level() level.Level {
+	if e.Level == nil {
+		return level.Info
+	}
+	return e.Level
+}
+
+// Schema returns a handlers.Schema declaring the fields of the Events `evs`,
+// so that handlers can enforce them on the records logged without this
+// package (see handlers.Enforce)
+func Schema(evs ...Event) handlers.Schema {
+	s := handlers.Schema{Events: make(map[string][]handlers.Field, len(evs))}
+	for _, ev := range evs {
+		s.Events[ev.Name] = append(s.Events[ev.Name], ev.Fields...)
+	}
+	return s
+}
+
+// Logger is a logx.Logger that is also able to log Events
+type Logger struct {
+	logx.Logger
+}
+
+// Wrap creates a Logger from the logx.Logger `logger`, or from the standard
+// Logger if nil
+func Wrap(logger logx.Logger) Logger {
+	if logger == nil {
+		logger = logx.Default()
+	}
+	return Logger{logger}
+}
+
+// Event logs the Event `ev` with the attributes `attrs`. The event is logged
+// even if its attributes are not valid, in which case an error wrapping
+// ErrInvalidEvent is returned
+func (l Logger) Event(ev Event, attrs ...attr.Attr) error {
+	err := ev.Validate(attrs...)
+	l.Logger.Log(ev.level(), ev.Name, attrs...)
+	return err
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+var (
+	userID   = Key[string]("user_id")
+	attempts = Key[int]("attempts")
+	elapsed  = Key[time.Duration]("elapsed")
+
+	userLogin = New("user logged in", level.Warn, userID.Required(), attempts.Optional(), elapsed.Optional())
+)
+
+func TestKey(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		field handlers.Field
+		wants handlers.Field
+	}{
+		{"String", userID.Required(), handlers.Field{Key: "user_id", Kind: handlers.KindString, Required: true}},
+		{"Int", attempts.Optional(), handlers.Field{Key: "attempts", Kind: handlers.KindInt}},
+		{"Duration", elapsed.Optional(), handlers.Field{Key: "elapsed", Kind: handlers.KindDuration}},
+		{"Any", Key[[]string]("tags").Optional(), handlers.Field{Key: "tags", Kind: handlers.KindAny}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if test.field != test.wants {
+				t.Errorf("output mismatch error: wanted %v ; got %v", test.wants, test.field)
+			}
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		logger := Wrap(logx.New(logx.WithHandler(jsonh.New(b))))
+
+		if err := logger.Event(userLogin, userID.Attr("gopher"), attempts.Attr(2)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		var e struct {
+			Message string         `json:"message"`
+			Level   string         `json:"level"`
+			Data    map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal(b.Bytes(), &e); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if e.Message != userLogin.Name || e.Level != "warn" || e.Data["user_id"] != "gopher" {
+			t.Errorf("output mismatch error: wanted the event's record ; got %v", e)
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		logger := Wrap(logx.New(logx.WithHandler(jsonh.New(b))))
+
+		err := logger.Event(userLogin, attempts.Attr(2))
+		if !errors.Is(err, ErrInvalidEvent) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrInvalidEvent, err)
+		}
+		if b.Len() == 0 {
+			t.Errorf("output mismatch error: wanted the invalid event to be logged")
+		}
+	})
+}
+
+func TestSchema(t *testing.T) {
+	s := Schema(userLogin)
+	r := records.New(time.Now(), level.Warn, userLogin.Name, attempts.Attr(1))
+
+	if v := s.Validate(r); len(v) != 1 || v[0].Key != "user_id" {
+		t.Errorf("output mismatch error: wanted a missing user_id violation ; got %v", v)
+	}
+}
@@ -0,0 +1,63 @@
+package logx
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+)
+
+// Extractor is a function that returns the attributes to add to a record from
+// the Context `ctx`, such as request IDs, tenant IDs or auth subjects stored
+// as its values. It is called by the Logger's context-aware methods (see
+// ContextPrinter), and should return nil when `ctx` holds none
+type Extractor func(ctx context.Context) []attr.Attr
+
+var extractors atomic.Pointer[[]Extractor]
+
+// SetExtractors sets the Extractors `fns` run by the context-aware methods of
+// all Loggers without their own Extractors (see the WithExtractors option),
+// replacing the previous ones.
+//
+// Calling SetExtractors with no Extractors removes them, which is the default
+func SetExtractors(fns ...Extractor) {
+	if len(fns) == 0 {
+		extractors.Store(nil)
+		return
+	}
+	fns = append([]Extractor(nil), fns...)
+	extractors.Store(&fns)
+}
+
+// ValueExtractor returns an Extractor that adds the value stored in a Context
+// under `key` as an attribute with key `attrKey`, if present
+func ValueExtractor(key any, attrKey string) Extractor {
+	return func(ctx context.Context) []attr.Attr {
+		if v := ctx.Value(key); v != nil {
+			return []attr.Attr{attr.New(attrKey, v)}
+		}
+		return nil
+	}
+}
+
+// extract returns `attrs` followed by the attributes extracted from `ctx`,
+// without modifying the caller's slice
+func (l *logger) extract(ctx context.Context, attrs []attr.Attr) []attr.Attr {
+	fns := l.extractors
+	if fns == nil {
+		if ref := extractors.Load(); ref != nil {
+			fns = *ref
+		}
+	}
+
+	for _, fn := range fns {
+		if fn == nil {
+			continue
+		}
+		if extra := fn(ctx); len(extra) > 0 {
+			attrs = append(attrs[:len(attrs):len(attrs)], handlers.Traced("Extractor", extra...)...)
+		}
+	}
+	return attrs
+}
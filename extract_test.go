@@ -0,0 +1,93 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/level"
+)
+
+type tenantKey struct{}
+
+func TestExtractors(t *testing.T) {
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	tenant := ValueExtractor(tenantKey{}, "tenant")
+
+	t.Run("PerLogger", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := New(WithHandler(jsonh.New(b)), WithExtractors(tenant))
+
+		l.InfoContext(ctx, "with context", attr.Int("id", 1))
+		if !strings.Contains(b.String(), `"data":{"id":1,"tenant":"acme"}`) {
+			t.Errorf("output mismatch error: wanted the tenant attribute ; got %s", b.String())
+		}
+
+		b.Reset()
+		l.Info("without context")
+		if strings.Contains(b.String(), "tenant") {
+			t.Errorf("output mismatch error: wanted no tenant attribute ; got %s", b.String())
+		}
+	})
+	t.Run("Global", func(t *testing.T) {
+		SetExtractors(tenant)
+		defer SetExtractors()
+
+		b := &bytes.Buffer{}
+		New(WithHandler(jsonh.New(b))).LogContext(ctx, level.Warn, "with context")
+		if !strings.Contains(b.String(), `"tenant":"acme"`) || !strings.Contains(b.String(), `"level":"warn"`) {
+			t.Errorf("output mismatch error: wanted the tenant attribute ; got %s", b.String())
+		}
+	})
+	t.Run("MissingValue", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		New(WithHandler(jsonh.New(b)), WithExtractors(tenant)).ErrorContext(context.Background(), "missing value")
+		if strings.Contains(b.String(), "tenant") {
+			t.Errorf("output mismatch error: wanted no tenant attribute ; got %s", b.String())
+		}
+	})
+	t.Run("KeepsCallerAttrs", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := New(WithHandler(jsonh.New(b)), WithExtractors(tenant))
+
+		attrs := make([]attr.Attr, 1, 4)
+		attrs[0] = attr.Int("id", 1)
+		l.InfoContext(ctx, "with context", attrs...)
+
+		if extra := attrs[:2][1]; extra != nil {
+			t.Errorf("output mismatch error: wanted the caller's slice untouched ; got %v", extra)
+		}
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		var calls int
+		l := New(
+			WithHandler(jsonh.New(&bytes.Buffer{})),
+			WithLevel(level.Warn),
+			WithExtractors(func(context.Context) []attr.Attr {
+				calls++
+				return nil
+			}),
+		)
+		l.DebugContext(ctx, "filtered")
+
+		if calls != 0 {
+			t.Errorf("output mismatch error: wanted %v calls ; got %v", 0, calls)
+		}
+	})
+	t.Run("Canceled", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := New(WithHandler(handlers.Canceled(jsonh.New(b), level.Debug)))
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		l.ErrorContext(ctx, "write failed")
+
+		if !strings.Contains(b.String(), `"level":"debug"`) || !strings.Contains(b.String(), `"context_canceled":"context canceled"`) {
+			t.Errorf("output mismatch error: wanted a downgraded record ; got %s", b.String())
+		}
+	})
+}
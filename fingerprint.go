@@ -0,0 +1,83 @@
+package logx
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zalgonoise/attr"
+)
+
+// FingerprintKey is the key of the attribute created by Fingerprint
+const FingerprintKey = "error_fingerprint"
+
+var (
+	uuidPattern   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	idPattern     = regexp.MustCompile(`\b(0x)?[0-9a-fA-F]{8,}\b`)
+	numberPattern = regexp.MustCompile(`[0-9]+`)
+)
+
+// Fingerprint creates an attribute with a stable hash of the error `err`, so
+// that the occurrences of the same error can be grouped (and their alerts
+// deduplicated) across requests and hosts, even when their messages differ.
+//
+// The hash covers the types of the errors in the chain of `err` (see
+// errors.Unwrap) and its message, normalized with the UUIDs, hexadecimal IDs
+// and numbers stripped. Returns nil if `err` is nil
+func Fingerprint(err error) attr.Attr {
+	if err == nil {
+		return nil
+	}
+	return attr.String(FingerprintKey, ErrorFingerprint(err))
+}
+
+// ErrorFingerprint returns the hash of the error `err` used by Fingerprint, as
+// 16 hexadecimal digits, or an empty string if `err` is nil
+func ErrorFingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	h := fnv.New64a()
+	writeErrorTypes(h, err)
+	_, _ = h.Write([]byte(normalizeMessage(err.Error())))
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// writeErrorTypes writes the type of the error `err` and of the errors it
+// wraps, depth-first, into the writer `w`
+func writeErrorTypes(w io.Writer, err error) {
+	for err != nil {
+		_, _ = fmt.Fprintf(w, "%T;", err)
+
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			_, _ = w.Write([]byte(strconv.Itoa(len(multi.Unwrap())) + "("))
+			for _, e := range multi.Unwrap() {
+				writeErrorTypes(w, e)
+			}
+			_, _ = w.Write([]byte(")"))
+			return
+		}
+		err = errors.Unwrap(err)
+	}
+}
+
+// normalizeMessage strips the UUIDs, hexadecimal IDs (of 8 digits or more) and
+// numbers from the error message `msg`, which vary across occurrences of the
+// same error
+func normalizeMessage(msg string) string {
+	msg = uuidPattern.ReplaceAllString(msg, "<uuid>")
+	msg = idPattern.ReplaceAllStringFunc(msg, func(id string) string {
+		// long words made of hexadecimal letters only are kept
+		if strings.ContainsAny(id, "0123456789") {
+			return "<id>"
+		}
+		return id
+	})
+	return numberPattern.ReplaceAllString(msg, "<n>")
+}
@@ -0,0 +1,84 @@
+package logx
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+)
+
+type testQueryError struct {
+	id int
+}
+
+func (e testQueryError) Error() string {
+	return fmt.Sprintf("query %d failed", e.id)
+}
+
+func TestFingerprint(t *testing.T) {
+	errTimeout := errors.New("timeout")
+
+	for _, test := range []struct {
+		name  string
+		a, b  error
+		equal bool
+	}{
+		{
+			name:  "Numbers",
+			a:     fmt.Errorf("request 1234 to shard 7: %w", errTimeout),
+			b:     fmt.Errorf("request 98 to shard 12: %w", errTimeout),
+			equal: true,
+		},
+		{
+			name:  "IDs",
+			a:     fmt.Errorf("user 3f2a9c1d-07b4-4a5e-9c1e-2b7f1a6d8e90 session 0xdeadbeef01: %w", errTimeout),
+			b:     fmt.Errorf("user 0c8e2f6a-1d3b-4c7e-8a9f-5e4d3c2b1a00 session 0x7f00aa1122: %w", errTimeout),
+			equal: true,
+		},
+		{
+			name:  "Types",
+			a:     fmt.Errorf("load: %w", testQueryError{id: 1}),
+			b:     fmt.Errorf("load: %w", testQueryError{id: 2}),
+			equal: true,
+		},
+		{
+			name:  "DifferentTypes",
+			a:     fmt.Errorf("open: %w", &fs.PathError{Op: "open", Path: "a", Err: fs.ErrNotExist}),
+			b:     fmt.Errorf("open: %w", errors.New("open a: file does not exist")),
+			equal: false,
+		},
+		{
+			name:  "DifferentMessages",
+			a:     errors.New("connection refused"),
+			b:     errors.New("connection reset"),
+			equal: false,
+		},
+		{
+			name:  "Joined",
+			a:     errors.Join(errTimeout, testQueryError{id: 3}),
+			b:     errors.Join(errTimeout, testQueryError{id: 4}),
+			equal: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := ErrorFingerprint(test.a), ErrorFingerprint(test.b)
+			if len(a) != 16 {
+				t.Errorf("output mismatch error: wanted 16 hex digits ; got %q", a)
+			}
+			if (a == b) != test.equal {
+				t.Errorf("output mismatch error: wanted equal fingerprints to be %v ; got %q and %q", test.equal, a, b)
+			}
+		})
+	}
+
+	t.Run("Attr", func(t *testing.T) {
+		err := errors.New("boom")
+		a := Fingerprint(err)
+		if a == nil || a.Key() != FingerprintKey || a.Value() != ErrorFingerprint(err) {
+			t.Errorf("output mismatch error: wanted the fingerprint attribute ; got %v", a)
+		}
+		if Fingerprint(nil) != nil {
+			t.Errorf("output mismatch error: wanted nil for a nil error")
+		}
+	})
+}
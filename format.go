@@ -0,0 +1,22 @@
+//go:build !logx_tiny
+
+package logx
+
+import (
+	"io"
+
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/handlers/texth"
+)
+
+// newHandler creates the Handler encoding records in the Format `format`,
+// writing them to the io.Writer `w`
+func newHandler(format Format, w io.Writer) handlers.Handler {
+	switch format {
+	case FormatText:
+		return texth.New(w)
+	default:
+		return jsonh.New(w)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !logx_tiny
+
+package logx
+
+import "testing"
+
+// skipTiny skips the tests on the encoding of the default handlers in the
+// logx_tiny build profile
+func skipTiny(t *testing.T) {}
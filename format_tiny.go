@@ -0,0 +1,17 @@
+//go:build logx_tiny
+
+package logx
+
+import (
+	"io"
+
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/tinyh"
+)
+
+// newHandler creates the Handler encoding records in the Format `format`,
+// writing them to the io.Writer `w`. In the logx_tiny build profile, all
+// formats are encoded with the tinyh Handler
+func newHandler(_ Format, w io.Writer) handlers.Handler {
+	return tinyh.New(w)
+}
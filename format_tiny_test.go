@@ -0,0 +1,29 @@
+//go:build logx_tiny
+
+package logx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/zalgonoise/attr"
+)
+
+// skipTiny skips the tests on the encoding of the default handlers in the
+// logx_tiny build profile
+func skipTiny(t *testing.T) {
+	t.Skip("default handlers are replaced in the logx_tiny build profile")
+}
+
+func TestTinyFormat(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatText} {
+		b := &bytes.Buffer{}
+		l := New(WithWriter(b), WithFormat(format), TestMode())
+		l.Info("connected", attr.Int("retries", 3))
+
+		wants := "2024-01-01T00:00:00Z info connected retries=3\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const accessTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogFormat is a classic HTTP server access log format
+type AccessLogFormat int
+
+const (
+	// CommonLogFormat is the NCSA Common Log Format:
+	//
+	//	host ident user [time] "request" status bytes
+	CommonLogFormat AccessLogFormat = iota
+	// CombinedLogFormat is the Apache Combined Log Format, adding the referer
+	// and user agent to the CommonLogFormat:
+	//
+	//	host ident user [time] "request" status bytes "referer" "user-agent"
+	CombinedLogFormat
+)
+
+var accessEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+
+type accessHandler struct {
+	w        io.Writer
+	format   AccessLogFormat
+	levelRef level.Leveler
+	attrs    []attr.Attr
+}
+
+// AccessLog creates a handler that writes the HTTP request records (as logged
+// by the logxhttp, logxgin, logxecho and logxfiber middlewares) to the
+// io.Writer `w` as access log lines in the AccessLogFormat `format`.
+//
+// The lines are built from the records' `remote_addr` (or `client_ip`),
+// `user`, `method`, `path`, `query`, `proto`, `status`, `bytes`, `referer`
+// and `user_agent` attributes, with missing fields written as `-`. Records
+// without a `status` attribute are not access records, and are skipped
+func AccessLog(w io.Writer, format AccessLogFormat) Handler {
+	if w == nil {
+		return nil
+	}
+	return accessHandler{
+		w:      w,
+		format: format,
+	}
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h accessHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	status := h.value("status", r)
+	if status == "" {
+		return nil
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	host := h.value("remote_addr", r)
+	if host == "" {
+		host = h.value("client_ip", r)
+	}
+	if split, _, err := net.SplitHostPort(host); err == nil {
+		host = split
+	}
+
+	b.WriteString(orDash(host))
+	b.WriteString(" - ")
+	b.WriteString(orDash(h.value("user", r)))
+	b.WriteString(" [")
+	*b = r.Time().AppendFormat(*b, accessTimeFormat)
+	b.WriteString(`] "`)
+
+	method, path := h.value("method", r), h.value("path", r)
+	if method != "" || path != "" {
+		proto := h.value("proto", r)
+		if proto == "" {
+			proto = "HTTP/1.1"
+		}
+		if query := h.value("query", r); query != "" {
+			path += "?" + query
+		}
+		b.WriteString(accessEscaper.Replace(method + " " + path + " " + proto))
+	} else {
+		b.WriteByte('-')
+	}
+	b.WriteString(`" `)
+	b.WriteString(status)
+	b.WriteByte(' ')
+
+	if size := h.value("bytes", r); size != "" && size != "0" {
+		b.WriteString(size)
+	} else {
+		b.WriteByte('-')
+	}
+
+	if h.format == CombinedLogFormat {
+		b.WriteString(` "`)
+		b.WriteString(accessEscaper.Replace(orDash(h.value("referer", r))))
+		b.WriteString(`" "`)
+		b.WriteString(accessEscaper.Replace(orDash(h.value("user_agent", r))))
+		b.WriteByte('"')
+	}
+	b.WriteByte('\n')
+
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+// value returns the value of the attribute with key `key` in the Record `r` or
+// in the handler's bound attributes as a string, or an empty string if absent
+func (h accessHandler) value(key string, r records.Record) string {
+	a := lookup(key, r.Attrs(), h.attrs)
+	if a == nil {
+		return ""
+	}
+
+	switch v := a.Value().(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// Ping implements Pinger, checking the health of the handler's io.Writer
+func (h accessHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, h.w)
+}
+
+// Shutdown implements Shutdowner, shutting down the handler's io.Writer
+func (h accessHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, h.w)
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h accessHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h accessHandler) With(attrs ...attr.Attr) Handler {
+	return accessHandler{
+		w:        h.w,
+		format:   h.format,
+		levelRef: h.levelRef,
+		attrs:    attrs,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h accessHandler) WithSource(bool) Handler {
+	return h
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h accessHandler) WithLevel(level level.Leveler) Handler {
+	return accessHandler{
+		w:        h.w,
+		format:   h.format,
+		levelRef: level,
+		attrs:    h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`. Access log lines have a fixed set of fields, so the
+// replace function is not used
+func (h accessHandler) WithReplaceFn(func(a attr.Attr) attr.Attr) Handler {
+	return h
+}
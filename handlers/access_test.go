@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestAccessLog(t *testing.T) {
+	ts := time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600))
+	request := []attr.Attr{
+		attr.String("method", "GET"),
+		attr.String("path", "/apache_pb.gif"),
+		attr.String("query", "a=1"),
+		attr.Int("status", 200),
+		attr.Int("bytes", 2326),
+		attr.String("remote_addr", "127.0.0.1:54321"),
+		attr.String("referer", "http://www.example.com/start.html"),
+		attr.String("user_agent", `Mozilla/4.08 "beta"`),
+	}
+
+	for _, test := range []struct {
+		name   string
+		format AccessLogFormat
+		attrs  []attr.Attr
+		wants  string
+	}{
+		{
+			name:   "Common",
+			format: CommonLogFormat,
+			attrs:  request,
+			wants:  `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?a=1 HTTP/1.1" 200 2326` + "\n",
+		},
+		{
+			name:   "Combined",
+			format: CombinedLogFormat,
+			attrs:  request,
+			wants: `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?a=1 HTTP/1.1" 200 2326` +
+				` "http://www.example.com/start.html" "Mozilla/4.08 \"beta\""` + "\n",
+		},
+		{
+			name:   "Missing",
+			format: CombinedLogFormat,
+			attrs:  []attr.Attr{attr.Int("status", 204), attr.Int("bytes", 0), attr.String("client_ip", "10.0.0.1")},
+			wants:  `10.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "-" 204 - "-" "-"` + "\n",
+		},
+		{
+			name:   "NotARequest",
+			format: CommonLogFormat,
+			attrs:  []attr.Attr{attr.String("user", "gopher")},
+			wants:  "",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+
+			if err := AccessLog(b, test.format).Handle(records.New(ts, level.Info, "http request", test.attrs...)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if b.String() != test.wants {
+				t.Errorf("output mismatch error: wanted %q ; got %q", test.wants, b.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package amqph
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+type testPublisher struct {
+	mu     sync.Mutex
+	msgs   []Message
+	fail   error
+	closed bool
+}
+
+func (p *testPublisher) Publish(_ context.Context, msg Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.fail != nil {
+		return p.fail
+	}
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+
+func (p *testPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	return nil
+}
+
+func TestHandle(t *testing.T) {
+	pub := &testPublisher{}
+	h, err := New(Config{
+		Dial:       func(context.Context) (Publisher, error) { return pub, nil },
+		Exchange:   "logs",
+		RoutingKey: "{service}.{level}.{missing}",
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	h = h.With(attr.String("service", "api")).WithLevel(level.Info)
+
+	_ = h.Handle(records.New(testTime, level.Debug, "filtered"))
+	if err := h.Handle(records.New(testTime, level.Warn, "slow request", attr.Int("ms", 300))); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	if len(pub.msgs) != 1 {
+		t.Errorf("output mismatch error: wanted 1 message ; got %d", len(pub.msgs))
+		return
+	}
+	msg := pub.msgs[0]
+	if msg.Exchange != "logs" || msg.RoutingKey != "api.warn.{missing}" || !msg.Persistent ||
+		msg.ContentType != "application/json" || !msg.Timestamp.Equal(testTime) {
+		t.Errorf("output mismatch error: got %+v", msg)
+	}
+	if body := string(msg.Body); !strings.HasPrefix(body, "{") || !strings.HasSuffix(body, "}") ||
+		!strings.Contains(body, `"ms":300`) || !strings.Contains(body, `"service":"api"`) {
+		t.Errorf("output mismatch error: got %s", body)
+	}
+
+	if err := handlers.Shutdown(context.Background(), h); err != nil || !pub.closed {
+		t.Errorf("expected the publisher to be closed ; got %v", err)
+	}
+	if err := h.Handle(records.New(testTime, level.Warn, "late")); err == nil {
+		t.Errorf("expected an error after shutdown")
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	errBroker := errors.New("connection reset")
+
+	var dials int
+	pubs := []*testPublisher{{fail: errBroker}, {}}
+	h, _ := New(Config{
+		Dial: func(context.Context) (Publisher, error) {
+			dials++
+			return pubs[dials-1], nil
+		},
+		ReconnectInterval: time.Minute,
+	})
+
+	now := testTime
+	h.(amqpHandler).c.now = func() time.Time { return now }
+	r := records.New(testTime, level.Info, "event")
+
+	if err := h.Handle(r); !errors.Is(err, errBroker) {
+		t.Errorf("unexpected error: wanted %v ; got %v", errBroker, err)
+	}
+	if !pubs[0].closed {
+		t.Errorf("expected the failed publisher to be closed")
+	}
+	if err := h.Handle(r); !errors.Is(err, ErrDisconnected) || !errors.Is(err, errBroker) {
+		t.Errorf("unexpected error: wanted %v ; got %v", ErrDisconnected, err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := h.Handle(r); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if dials != 2 || len(pubs[1].msgs) != 1 {
+		t.Errorf("output mismatch error: wanted a reconnection ; got %d dials", dials)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(Config{}); !errors.Is(err, ErrNoDialer) {
+		t.Errorf("unexpected error: wanted %v ; got %v", ErrNoDialer, err)
+	}
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/stats"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// DropPolicy defines how an Async handler behaves when its queue is full
+type DropPolicy int
+
+const (
+	// DropNewest discards the incoming record when the queue is full
+	DropNewest DropPolicy = iota
+	// DropOldest discards the oldest queued record to make room for the
+	// incoming one
+	DropOldest
+	// Block waits for room in the queue, up to a timeout, discarding the
+	// incoming record if it expires
+	Block
+)
+
+// Async is a Handler that queues records in a bounded buffer, handling them
+// in a background goroutine, so that logging never stalls the caller due to a
+// slow Handler.
+//
+// Handlers derived from an Async (with its With* methods) share its queue and
+// counters. Errors raised by the wrapped Handler are discarded
+type Async struct {
+	asyncHandler
+}
+
+type asyncHandler struct {
+	h Handler
+	q *asyncQueue
+}
+
+type asyncJob struct {
+	h Handler
+	r records.Record
+}
+
+type asyncQueue struct {
+	mu      sync.RWMutex
+	closed  bool
+	jobs    chan asyncJob
+	policy  DropPolicy
+	timeout time.Duration
+	dropped atomic.Uint64
+	done    chan struct{}
+}
+
+// NewAsync creates an Async handler that queues up to `size` records for the
+// Handler `h`, applying the DropPolicy `policy` when the queue is full.
+//
+// The `timeout` is only used with the Block policy; if it is not greater than
+// zero, callers block until there is room in the queue
+func NewAsync(h Handler, size int, policy DropPolicy, timeout time.Duration) *Async {
+	if h == nil {
+		h = Unimpl()
+	}
+	if size <= 0 {
+		size = 1
+	}
+
+	q := &asyncQueue{
+		jobs:    make(chan asyncJob, size),
+		policy:  policy,
+		timeout: timeout,
+		done:    make(chan struct{}),
+	}
+	go q.run()
+
+	return &Async{
+		asyncHandler: asyncHandler{
+			h: h,
+			q: q,
+		},
+	}
+}
+
+// Dropped returns the number of records discarded so far, due to a full queue
+func (a *Async) Dropped() uint64 {
+	return a.q.dropped.Load()
+}
+
+// Pressure implements Pressurer, returning the fill ratio of the queue
+func (a asyncHandler) Pressure() float64 {
+	return ratio(int64(len(a.q.jobs)), int64(cap(a.q.jobs)))
+}
+
+// Close stops accepting records, and blocks until the queued records are
+// handled. Records handled after Close return os.ErrClosed
+func (a *Async) Close() error {
+	a.q.close()
+	<-a.q.done
+	return nil
+}
+
+func (q *asyncQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	for job := range q.jobs {
+		stats.Queued.Add(-1)
+		_ = job.h.Handle(job.r)
+	}
+}
+
+func (q *asyncQueue) push(job asyncJob) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return os.ErrClosed
+	}
+
+	select {
+	case q.jobs <- job:
+		stats.Queued.Add(1)
+		return nil
+	default:
+	}
+
+	switch q.policy {
+	case DropOldest:
+		for {
+			select {
+			case <-q.jobs:
+				stats.Queued.Add(-1)
+				q.drop()
+			default:
+			}
+			select {
+			case q.jobs <- job:
+				stats.Queued.Add(1)
+				return nil
+			default:
+			}
+		}
+	case Block:
+		if q.timeout <= 0 {
+			q.jobs <- job
+			stats.Queued.Add(1)
+			return nil
+		}
+
+		timer := time.NewTimer(q.timeout)
+		defer timer.Stop()
+
+		select {
+		case q.jobs <- job:
+			stats.Queued.Add(1)
+			return nil
+		case <-timer.C:
+		}
+	}
+
+	q.drop()
+	return nil
+}
+
+func (q *asyncQueue) drop() {
+	q.dropped.Add(1)
+	stats.Dropped.Add(1)
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (a asyncHandler) Enabled(level level.Level) bool {
+	return a.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (a asyncHandler) Handle(r records.Record) error {
+	if !a.h.Enabled(r.Level()) {
+		return nil
+	}
+	// records may be reused by the caller once Handle returns
+	return a.q.push(asyncJob{h: a.h, r: r.Clone()})
+}
+
+// Ping implements Pinger, returning os.ErrClosed once the Async handler is
+// closed, or checking the health of the wrapped Handler otherwise
+func (a asyncHandler) Ping(ctx context.Context) error {
+	a.q.mu.RLock()
+	closed := a.q.closed
+	a.q.mu.RUnlock()
+
+	if closed {
+		return os.ErrClosed
+	}
+	return Ping(ctx, a.h)
+}
+
+// Capabilities implements Capable, returning the capabilities of the wrapped
+// Handler along with CapFlush, as records are queued
+func (a asyncHandler) Capabilities() Capability {
+	return decoratedCapabilities(a.h) | CapFlush
+}
+
+// Shutdown implements Shutdowner, closing the Async handler's queue and waiting
+// for the queued records to be handled, before shutting down the wrapped
+// Handler
+func (a asyncHandler) Shutdown(ctx context.Context) error {
+	a.q.close()
+
+	select {
+	case <-a.q.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return Shutdown(ctx, a.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (a asyncHandler) With(attrs ...attr.Attr) Handler {
+	return asyncHandler{
+		h: a.h.With(attrs...),
+		q: a.q,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (a asyncHandler) WithSource(addSource bool) Handler {
+	return asyncHandler{
+		h: a.h.WithSource(addSource),
+		q: a.q,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (a asyncHandler) WithLevel(level level.Leveler) Handler {
+	return asyncHandler{
+		h: a.h.WithLevel(level),
+		q: a.q,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (a asyncHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return asyncHandler{
+		h: a.h.WithReplaceFn(fn),
+		q: a.q,
+	}
+}
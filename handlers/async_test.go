@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// gatedHandler blocks every Handle call until its gate is closed
+type gatedHandler struct {
+	testHandler
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func newGatedHandler() gatedHandler {
+	return gatedHandler{
+		testHandler: newTestHandler(),
+		started:     make(chan struct{}, 16),
+		gate:        make(chan struct{}),
+	}
+}
+
+func (h gatedHandler) Handle(r records.Record) error {
+	h.started <- struct{}{}
+	<-h.gate
+	return h.testHandler.Handle(r)
+}
+
+func messages(rs []records.Record) []string {
+	out := make([]string, 0, len(rs))
+	for _, r := range rs {
+		out = append(out, r.Message())
+	}
+	return out
+}
+
+func TestAsync(t *testing.T) {
+	t.Run("Delivers", func(t *testing.T) {
+		th := newTestHandler()
+		a := NewAsync(th, 8, DropNewest, 0)
+
+		for _, msg := range []string{"first", "second", "third"} {
+			if err := a.Handle(records.New(time.Now(), level.Info, msg)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+		_ = a.Close()
+
+		if out := messages(th.Records()); len(out) != 3 || out[2] != "third" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", []string{"first", "second", "third"}, out)
+		}
+		if err := a.Handle(records.New(time.Now(), level.Info, "late")); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("unexpected error: wanted %v ; got %v", os.ErrClosed, err)
+		}
+	})
+
+	for _, test := range []struct {
+		name   string
+		policy DropPolicy
+		wants  []string
+	}{
+		{name: "DropNewest", policy: DropNewest, wants: []string{"first", "second"}},
+		{name: "DropOldest", policy: DropOldest, wants: []string{"first", "third"}},
+		{name: "BlockTimeout", policy: Block, wants: []string{"first", "second"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			gh := newGatedHandler()
+			a := NewAsync(gh, 1, test.policy, 10*time.Millisecond)
+
+			_ = a.Handle(records.New(time.Now(), level.Info, "first"))
+			<-gh.started // the worker holds the first record; the queue is empty
+			_ = a.Handle(records.New(time.Now(), level.Info, "second"))
+			_ = a.Handle(records.New(time.Now(), level.Info, "third"))
+
+			close(gh.gate)
+			_ = a.Close()
+
+			if a.Dropped() != 1 {
+				t.Errorf("output mismatch error: wanted %v ; got %v", 1, a.Dropped())
+			}
+			out := messages(gh.Records())
+			if len(out) != len(test.wants) || out[0] != test.wants[0] || out[1] != test.wants[1] {
+				t.Errorf("output mismatch error: wanted %v ; got %v", test.wants, out)
+			}
+		})
+	}
+
+	t.Run("DerivedSharesQueue", func(t *testing.T) {
+		th := newTestHandler()
+		a := NewAsync(th, 8, DropNewest, 0)
+
+		_ = a.WithLevel(level.Warn).Handle(records.New(time.Now(), level.Info, "filtered"))
+		_ = a.WithLevel(level.Warn).Handle(records.New(time.Now(), level.Error, "kept"))
+		_ = a.Close()
+
+		if out := messages(th.Records()); len(out) != 1 || out[0] != "kept" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", []string{"kept"}, out)
+		}
+	})
+}
+
+func TestAsyncShutdown(t *testing.T) {
+	t.Run("Drains", func(t *testing.T) {
+		th := newTestHandler()
+		a := NewAsync(th, 8, DropNewest, 0)
+		_ = a.Handle(records.New(time.Now(), level.Info, "test message"))
+
+		if err := Shutdown(context.Background(), a); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(th.Records()) != 1 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 1, len(th.Records()))
+		}
+	})
+	t.Run("Deadline", func(t *testing.T) {
+		gh := newGatedHandler()
+		defer close(gh.gate)
+
+		a := NewAsync(gh, 8, DropNewest, 0)
+		_ = a.Handle(records.New(time.Now(), level.Info, "test message"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if err := Shutdown(ctx, a); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error: wanted %v ; got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
@@ -0,0 +1,211 @@
+// Package audit provides a tamper-evident Handler, which hash-chains the
+// records it writes so that any modification, removal or reordering of them
+// is detected by the Verify function
+//
+// Each record is encoded as a JSON line (see the jsonh package), carrying
+// an `audit_seq` sequence number and a `prev_hash` attribute with the
+// hex-encoded SHA-256 hash of the previous line. The first record in a chain
+// refers to the Genesis hash
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	seqKey        = "audit_seq"
+	prevHashKey   = "prev_hash"
+	anchorMessage = "audit anchor"
+)
+
+// Genesis is the hash referred to by the first record of a chain
+var Genesis = hex.EncodeToString(make([]byte, sha256.Size))
+
+// Anchor is a checkpoint of a chain: the sequence number and hash of one of its
+// records. Storing anchors outside of the log (e.g. in a separate system)
+// allows Verify to also detect the truncation of the log, and the rewriting
+// of the chain from an earlier record
+type Anchor struct {
+	Seq  uint64
+	Hash string
+	Time time.Time
+}
+
+// Option configures an audit Handler
+type Option func(*chain)
+
+// WithAnchors makes the Handler write an anchor record after every `every`
+// records, calling `fn` with the resulting Anchor so it can be stored
+// elsewhere. `fn` is called while holding the chain's lock, and must not log
+// to the same Handler
+func WithAnchors(every int, fn func(Anchor)) Option {
+	return func(c *chain) {
+		c.anchorEvery = every
+		c.onAnchor = fn
+	}
+}
+
+// WithChain continues the chain from the Anchor `from`, such as the one
+// returned by Verify for the existing log, instead of starting from Genesis
+func WithChain(from Anchor) Option {
+	return func(c *chain) {
+		c.seq = from.Seq
+		if from.Hash != "" {
+			c.prev = from.Hash
+		}
+	}
+}
+
+// chain holds the state shared by an audit Handler and the ones derived from
+// it, so that all of their records form a single chain
+type chain struct {
+	mu   sync.Mutex
+	w    io.Writer
+	seq  uint64
+	prev string
+
+	anchorEvery int
+	onAnchor    func(Anchor)
+	pending     int
+	base        handlers.Handler
+}
+
+// Write hashes and writes the encoded record `p` as a line, advancing the
+// chain if successful. It is only called by the chain's JSON handlers, with
+// the chain's lock held
+func (c *chain) Write(p []byte) (int, error) {
+	sum := sha256.Sum256(p)
+
+	line := make([]byte, 0, len(p)+1)
+	line = append(append(line, p...), '\n')
+	if _, err := c.w.Write(line); err != nil {
+		return 0, err
+	}
+
+	c.seq++
+	c.prev = hex.EncodeToString(sum[:])
+	return len(p), nil
+}
+
+func (c *chain) link(r records.Record) records.Record {
+	return r.AddAttr(
+		attr.Uint(seqKey, c.seq+1),
+		attr.String(prevHashKey, c.prev),
+	)
+}
+
+func (c *chain) anchor() error {
+	if c.anchorEvery <= 0 {
+		return nil
+	}
+	if c.pending++; c.pending < c.anchorEvery {
+		return nil
+	}
+	c.pending = 0
+
+	if err := c.base.Handle(c.link(records.New(time.Now(), level.Info, anchorMessage))); err != nil {
+		return err
+	}
+	if c.onAnchor != nil {
+		c.onAnchor(Anchor{Seq: c.seq, Hash: c.prev, Time: time.Now()})
+	}
+	return nil
+}
+
+type auditHandler struct {
+	c   *chain
+	enc handlers.Handler
+}
+
+// New creates an audit Handler writing hash-chained JSON lines to the
+// io.Writer `w`, configured with the input Options `opts`
+func New(w io.Writer, opts ...Option) handlers.Handler {
+	if w == nil {
+		return nil
+	}
+
+	c := &chain{
+		w:    w,
+		prev: Genesis,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	c.base = jsonh.New(c)
+
+	return auditHandler{
+		c:   c,
+		enc: c.base,
+	}
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h auditHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	h.c.mu.Lock()
+	defer h.c.mu.Unlock()
+
+	if err := h.enc.Handle(h.c.link(r)); err != nil {
+		return err
+	}
+	return h.c.anchor()
+}
+
+// Ping implements handlers.Pinger, checking the health of the handler's
+// io.Writer
+func (h auditHandler) Ping(ctx context.Context) error {
+	return handlers.Ping(ctx, h.c.w)
+}
+
+// Shutdown implements handlers.Shutdowner, shutting down the handler's
+// io.Writer
+func (h auditHandler) Shutdown(ctx context.Context) error {
+	return handlers.Shutdown(ctx, h.c.w)
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h auditHandler) Enabled(level level.Level) bool {
+	return h.enc.Enabled(level)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`. Its records are added to the same chain
+func (h auditHandler) With(attrs ...attr.Attr) handlers.Handler {
+	return auditHandler{c: h.c, enc: h.enc.With(attrs...)}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h auditHandler) WithSource(addSource bool) handlers.Handler {
+	return auditHandler{c: h.c, enc: h.enc.WithSource(addSource)}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h auditHandler) WithLevel(level level.Leveler) handlers.Handler {
+	return auditHandler{c: h.c, enc: h.enc.WithLevel(level)}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`. The `audit_seq` and `prev_hash` attributes must
+// be kept as-is, or the chain will fail verification
+func (h auditHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) handlers.Handler {
+	return auditHandler{c: h.c, enc: h.enc.WithReplaceFn(fn)}
+}
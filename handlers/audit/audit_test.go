@@ -0,0 +1,127 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func writeRecords(t *testing.T, b *bytes.Buffer, n int, opts ...Option) {
+	h := New(b, opts...)
+	derived := h.With(attr.String("actor", "admin"))
+
+	for i := 0; i < n; i++ {
+		target := h
+		if i%2 == 1 {
+			target = derived
+		}
+		if err := target.Handle(records.New(time.Now(), level.Info, "user updated", attr.Int("id", i))); err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+	}
+}
+
+func TestAudit(t *testing.T) {
+	t.Run("Verify", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		writeRecords(t, b, 5)
+
+		head, err := Verify(bytes.NewReader(b.Bytes()))
+		if err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+		if head.Seq != 5 || head.Hash == Genesis {
+			t.Errorf("output mismatch error: wanted the 5th record as head ; got %v", head)
+		}
+	})
+	t.Run("Tampered", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		writeRecords(t, b, 5)
+
+		tampered := strings.Replace(b.String(), `"id":2`, `"id":7`, 1)
+		if _, err := Verify(strings.NewReader(tampered)); !errors.Is(err, ErrBrokenChain) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrBrokenChain, err)
+		}
+	})
+	t.Run("Removed", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		writeRecords(t, b, 5)
+
+		lines := strings.SplitAfter(b.String(), "\n")
+		removed := strings.Join(append(lines[:2:2], lines[3:]...), "")
+		if _, err := Verify(strings.NewReader(removed)); !errors.Is(err, ErrBrokenChain) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrBrokenChain, err)
+		}
+	})
+	t.Run("Anchors", func(t *testing.T) {
+		var (
+			b       = &bytes.Buffer{}
+			anchors []Anchor
+		)
+		writeRecords(t, b, 6, WithAnchors(3, func(a Anchor) {
+			anchors = append(anchors, a)
+		}))
+
+		// 6 records and 2 anchor records
+		if len(anchors) != 2 || anchors[1].Seq != 8 {
+			t.Errorf("output mismatch error: wanted 2 anchors up to record 8 ; got %v", anchors)
+			return
+		}
+		if strings.Count(b.String(), anchorMessage) != 2 {
+			t.Errorf("output mismatch error: wanted 2 anchor records ; got %s", b.String())
+		}
+		if _, err := Verify(bytes.NewReader(b.Bytes()), anchors...); err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+		}
+
+		lines := strings.SplitAfter(b.String(), "\n")
+		truncated := strings.Join(lines[:6], "")
+		if _, err := Verify(strings.NewReader(truncated), anchors...); !errors.Is(err, ErrTruncated) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrTruncated, err)
+		}
+
+		anchors[0].Hash = Genesis
+		if _, err := Verify(bytes.NewReader(b.Bytes()), anchors...); !errors.Is(err, ErrAnchorMismatch) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrAnchorMismatch, err)
+		}
+	})
+	t.Run("Continue", func(t *testing.T) {
+		first := &bytes.Buffer{}
+		writeRecords(t, first, 3)
+
+		head, err := Verify(bytes.NewReader(first.Bytes()))
+		if err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+
+		second := &bytes.Buffer{}
+		writeRecords(t, second, 3, WithChain(head))
+
+		if _, err := Verify(bytes.NewReader(second.Bytes())); !errors.Is(err, ErrBrokenChain) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrBrokenChain, err)
+		}
+		if next, err := VerifyFrom(bytes.NewReader(second.Bytes()), head); err != nil || next.Seq != 6 {
+			t.Errorf("unexpected error: wanted record 6 and %v ; got %d and %v", nil, next.Seq, err)
+		}
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := New(b).WithLevel(level.Warn)
+
+		if err := h.Handle(records.New(time.Now(), level.Info, "filtered")); err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+		}
+		if b.Len() != 0 {
+			t.Errorf("output mismatch error: wanted no output ; got %s", b.String())
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// maxLineSize is the size of the longest line that Verify accepts
+const maxLineSize = 16 << 20
+
+var (
+	// ErrBrokenChain is raised when a record does not refer to the hash or
+	// sequence number of the record before it
+	ErrBrokenChain error = errors.New("audit chain is broken")
+	// ErrAnchorMismatch is raised when a record does not match the Anchor
+	// for its sequence number
+	ErrAnchorMismatch error = errors.New("audit record does not match its anchor")
+	// ErrTruncated is raised when the chain ends before the last Anchor
+	ErrTruncated error = errors.New("audit chain is truncated")
+)
+
+type entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Data      struct {
+		Seq      uint64 `json:"audit_seq"`
+		PrevHash string `json:"prev_hash"`
+	} `json:"data"`
+}
+
+// Verify reads the chain written by an audit Handler from the io.Reader `r`,
+// checking that each record refers to the one before it, starting from
+// Genesis, and that the records match the Anchors `anchors`.
+//
+// It returns the Anchor for the last record in the chain, which can be used
+// to continue it (see WithChain), or to verify its continuation (see
+// VerifyFrom)
+func Verify(r io.Reader, anchors ...Anchor) (Anchor, error) {
+	return VerifyFrom(r, Anchor{}, anchors...)
+}
+
+// VerifyFrom is like Verify, for a chain continuing from the Anchor `from`,
+// such as a log file following a rotation. A zero Anchor is the start of a
+// chain (Genesis). Anchors for records before `from` are ignored
+func VerifyFrom(r io.Reader, from Anchor, anchors ...Anchor) (Anchor, error) {
+	head := from
+	if head.Hash == "" {
+		head.Hash = Genesis
+	}
+
+	expected := make(map[uint64]string, len(anchors))
+	var last uint64
+	for _, a := range anchors {
+		if a.Seq <= from.Seq {
+			continue
+		}
+		expected[a.Seq] = a.Hash
+		last = max(last, a.Seq)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		b := scanner.Bytes()
+		if len(b) == 0 {
+			continue
+		}
+
+		var e entry
+		if err := json.Unmarshal(b, &e); err != nil {
+			return head, fmt.Errorf("%w: line %d: %v", ErrBrokenChain, line, err)
+		}
+		if e.Data.Seq != head.Seq+1 || e.Data.PrevHash != head.Hash {
+			return head, fmt.Errorf("%w: line %d: record %d does not follow record %d", ErrBrokenChain, line, e.Data.Seq, head.Seq)
+		}
+
+		sum := sha256.Sum256(b)
+		head = Anchor{
+			Seq:  e.Data.Seq,
+			Hash: hex.EncodeToString(sum[:]),
+			Time: e.Timestamp,
+		}
+
+		if hash, ok := expected[head.Seq]; ok && hash != head.Hash {
+			return head, fmt.Errorf("%w: line %d: record %d", ErrAnchorMismatch, line, head.Seq)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return head, err
+	}
+
+	if last > head.Seq {
+		return head, fmt.Errorf("%w: last record is %d, anchored up to %d", ErrTruncated, head.Seq, last)
+	}
+	return head, nil
+}
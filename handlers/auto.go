@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	// EnvForceFormat is the environment variable that overrides the output
+	// format picked by Auto: `console` or `json`
+	EnvForceFormat = "LOGX_FORCE_FORMAT"
+	// EnvNoColor is the environment variable that, when set to any value,
+	// disables the colors of the console output picked by Auto
+	// (see https://no-color.org)
+	EnvNoColor = "NO_COLOR"
+)
+
+// IsTerminal returns true if the io.Writer `w` is an *os.File connected to a
+// terminal
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Auto creates a handler that writes records to the io.Writer `w` in the
+// format that suits where they are read: as colorized Console lines (with the
+// DarkTheme) if `w` is a terminal, for developers running the application
+// locally; or as JSON lines otherwise, for log collectors reading a
+// container's output or a file.
+//
+// The LOGX_FORCE_FORMAT environment variable overrides the detection, with
+// the `console` or `json` formats. The console output is not colorized if the
+// NO_COLOR environment variable is set.
+//
+// The JSON lines have the same fields as the ones written by the jsonh
+// handler, which cannot be imported from this package
+func Auto(w io.Writer) Handler {
+	if w == nil {
+		return nil
+	}
+
+	console := IsTerminal(w)
+	switch strings.ToLower(os.Getenv(EnvForceFormat)) {
+	case "console":
+		console = true
+	case "json":
+		console = false
+	}
+
+	if !console {
+		return jsonLinesHandler{w: w}
+	}
+
+	if _, ok := os.LookupEnv(EnvNoColor); ok {
+		return Console(w, Theme{})
+	}
+	return Console(w, DarkTheme)
+}
+
+// jsonLinesHandler writes records as JSON lines with the `timestamp`,
+// `message` and `level` fields, and the attributes in a `data` object
+type jsonLinesHandler struct {
+	w        io.Writer
+	levelRef level.Leveler
+	replFn   func(a attr.Attr) attr.Attr
+	attrs    []attr.Attr
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h jsonLinesHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	b.WriteString(`{"timestamp":`)
+	b.WriteJSON(r.Time().UTC().Format(time.RFC3339Nano))
+	b.WriteString(`,"message":`)
+	b.WriteJSON(r.Message())
+	if lv := r.Level(); lv != nil {
+		b.WriteString(`,"level":`)
+		b.WriteJSON(lv.String())
+	}
+
+	if r.AttrLen() > 0 || len(h.attrs) > 0 {
+		start := b.Len()
+		b.WriteString(`,"data":{`)
+		writeJSONFields(b, false, h.replFn, nil, h.attrs)
+		writeJSONFields(b, b.Len() > start+len(`,"data":{`), h.replFn, nil, r.Attrs())
+		if b.Len() == start+len(`,"data":{`) {
+			*b = (*b)[:start]
+		} else {
+			b.WriteByte('}')
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+// Ping implements Pinger, checking the health of the handler's io.Writer
+func (h jsonLinesHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, h.w)
+}
+
+// Shutdown implements Shutdowner, shutting down the handler's io.Writer
+func (h jsonLinesHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, h.w)
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h jsonLinesHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h jsonLinesHandler) With(attrs ...attr.Attr) Handler {
+	return jsonLinesHandler{
+		w:        h.w,
+		levelRef: h.levelRef,
+		replFn:   h.replFn,
+		attrs:    attrs,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h jsonLinesHandler) WithSource(bool) Handler {
+	return h
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h jsonLinesHandler) WithLevel(level level.Leveler) Handler {
+	return jsonLinesHandler{
+		w:        h.w,
+		levelRef: level,
+		replFn:   h.replFn,
+		attrs:    h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h jsonLinesHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return jsonLinesHandler{
+		w:        h.w,
+		levelRef: h.levelRef,
+		replFn:   fn,
+		attrs:    h.attrs,
+	}
+}
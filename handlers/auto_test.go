@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestAuto(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Setenv(EnvForceFormat, "")
+		b := &bytes.Buffer{}
+		h := Auto(b).With(attr.String("service", "api"))
+
+		err := h.Handle(records.New(ts, level.Warn, "slow request",
+			attr.New("http", []attr.Attr{attr.Int("status", 504)}),
+		))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		wants := `{"timestamp":"2024-01-02T03:04:05Z","message":"slow request","level":"warn",` +
+			`"data":{"service":"api","http":{"status":504}}}` + "\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+
+		r, err := records.NewDecoder(b).Decode()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if r.Message() != "slow request" || r.AttrLen() != 2 {
+			t.Errorf("output mismatch error: wanted the decoded record ; got %v", r)
+		}
+	})
+	t.Run("NoAttrs", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := Auto(b).WithReplaceFn(func(attr.Attr) attr.Attr { return nil })
+
+		_ = h.Handle(records.New(ts, level.Info, "message", attr.Int("n", 1)))
+
+		wants := `{"timestamp":"2024-01-02T03:04:05Z","message":"message","level":"info"}` + "\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("ForceConsole", func(t *testing.T) {
+		if _, ok := os.LookupEnv(EnvNoColor); ok {
+			t.Skip("colors are disabled in the environment")
+		}
+		t.Setenv(EnvForceFormat, "console")
+		b := &bytes.Buffer{}
+
+		_ = Auto(b).Handle(records.New(ts, level.Info, "message"))
+
+		if !strings.Contains(b.String(), "\x1b[36mINFO  \x1b[0m message") {
+			t.Errorf("output mismatch error: wanted colorized console output ; got %q", b.String())
+		}
+	})
+	t.Run("ForceConsoleNoColor", func(t *testing.T) {
+		t.Setenv(EnvForceFormat, "console")
+		t.Setenv(EnvNoColor, "1")
+		b := &bytes.Buffer{}
+
+		_ = Auto(b).Handle(records.New(ts, level.Info, "message"))
+
+		wants := "03:04:05.000 INFO   message\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("File", func(t *testing.T) {
+		f, err := os.Create(filepath.Join(t.TempDir(), "app.log"))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		defer f.Close()
+
+		if IsTerminal(f) {
+			t.Errorf("expected a regular file not to be a terminal")
+		}
+		if _, ok := Auto(f).(jsonLinesHandler); !ok {
+			t.Errorf("output mismatch error: wanted a JSON handler ; got %T", Auto(f))
+		}
+	})
+}
@@ -0,0 +1,634 @@
+//go:build !logx_tiny
+
+// Package azureh provides a batching handler that sends records to Azure
+// Monitor Logs (a Log Analytics workspace), through either of its ingestion
+// APIs. The Logs Ingestion API takes a data collection rule (DCR), and a
+// Microsoft Entra ID application (or a Token function, like one backed by a
+// managed identity):
+//
+//	h, err := azureh.New(azureh.Config{
+//		Endpoint:     "https://my-dce.westeurope-1.ingest.monitor.azure.com",
+//		RuleID:       "dcr-00000000000000000000000000000000",
+//		Stream:       "Custom-AppLogs_CL",
+//		TenantID:     tenantID,
+//		ClientID:     clientID,
+//		ClientSecret: clientSecret,
+//	})
+//
+// While the legacy HTTP Data Collector API takes the workspace ID and its
+// shared key:
+//
+//	h, err := azureh.New(azureh.Config{
+//		WorkspaceID: workspaceID,
+//		SharedKey:   sharedKey,
+//		LogType:     "AppLogs",
+//	})
+//
+// Records are sent in batches, as JSON arrays of objects with the fields:
+//   - `TimeGenerated`: the record's timestamp, in RFC 3339 format
+//   - `Level`: the record's level name
+//   - `Message`: the record's message
+//   - `Source`: the record's source reference, if enabled with WithSource
+//   - `Attributes`: the record's attributes, as a JSON object
+//   - one field per entry in Config.Fields, with the value of the attribute
+//     mapped to it
+//
+// Throttled requests (429 and 503 responses) are retried after the interval
+// in their Retry-After header
+package azureh
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	defaultBatchInterval = time.Second
+	defaultBatchSize     = 1 << 19 // 512 KiB, within the 1 MB limit of the Logs Ingestion API
+	defaultMaxRetries    = 3
+	defaultAuthorityHost = "https://login.microsoftonline.com"
+
+	ingestionAPIVersion = "2023-01-01"
+	collectorAPIVersion = "2016-04-01"
+	monitorScope        = "https://monitor.azure.com//.default"
+
+	timeField     = "TimeGenerated"
+	maxRetryWait  = 30 * time.Second
+	minRetryWait  = time.Second
+	tokenLeeway   = time.Minute
+	maxReplyBytes = 64 << 10
+)
+
+var (
+	// ErrNoDestination is raised when creating a handler without either a
+	// data collection rule (Endpoint, RuleID and Stream) or a workspace
+	// (WorkspaceID, SharedKey and LogType)
+	ErrNoDestination error = errors.New("no data collection rule or workspace provided")
+	// ErrNoCredentials is raised when creating a handler for the Logs Ingestion
+	// API without a Token function or an Entra ID application
+	ErrNoCredentials error = errors.New("no credentials provided")
+	// ErrThrottled is raised when the requests are still throttled after the
+	// configured retries
+	ErrThrottled error = errors.New("throttled by Azure Monitor")
+)
+
+// Config describes the destination of the records, how to authenticate with
+// it, and how the records' attributes are mapped to fields
+type Config struct {
+	// Endpoint is the data collection endpoint (or the DCR's logs ingestion
+	// endpoint) of the Logs Ingestion API. With a WorkspaceID, it overrides the
+	// Data Collector API endpoint, `https://<WorkspaceID>.ods.opinsights.azure.com`
+	Endpoint string
+	// RuleID is the immutable ID of the data collection rule, like
+	// `dcr-0123...`
+	RuleID string
+	// Stream is the name of the DCR's input stream, like `Custom-AppLogs_CL`
+	Stream string
+	// TenantID, ClientID and ClientSecret identify the Entra ID application
+	// that authenticates with the Logs Ingestion API, with the client
+	// credentials flow
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	// AuthorityHost is the Entra ID authority of the client credentials flow,
+	// `https://login.microsoftonline.com` by default
+	AuthorityHost string
+	// Token, if set, returns the bearer tokens for the Logs Ingestion API
+	// instead of the client credentials flow, like a managed identity's. It is
+	// called for every request, so it should cache its tokens
+	Token func(ctx context.Context) (string, error)
+
+	// WorkspaceID and SharedKey identify and authenticate with the Log
+	// Analytics workspace, for the Data Collector API
+	WorkspaceID string
+	SharedKey   string
+	// LogType is the name of the custom log table of the Data Collector API,
+	// without its `_CL` suffix
+	LogType string
+
+	// Fields maps the names of the records' fields to the keys of the
+	// attributes that fill them (including the ones bound to the handler,
+	// with With), like table columns. Keys within groups are joined with
+	// dots, like `http.status`
+	Fields map[string]string
+	// HTTP configures the requests, like their proxy and timeout
+	HTTP handlers.HTTPConfig
+	// BatchInterval is how often batches are sent, one second by default
+	BatchInterval time.Duration
+	// BatchSize is the size (in bytes) from which a batch is sent before its
+	// interval, 512 KiB by default
+	BatchSize int
+	// MaxRetries is the number of times a throttled request is retried (within
+	// the deadline of the batch's flush) before failing with ErrThrottled,
+	// three by default. Failed batches are retried on the following flushes
+	MaxRetries int
+}
+
+// field is a record field filled with the value of an attribute
+type field struct {
+	name string
+	path []string
+}
+
+type azureHandler struct {
+	w         io.Writer
+	fields    []field
+	addSource bool
+	levelRef  level.Leveler
+	replFn    func(a attr.Attr) attr.Attr
+	attrs     []attr.Attr
+}
+
+// New creates a handler that sends records to Azure Monitor Logs as described
+// by the Config `conf`, in batches. Failed batches are retried on the following
+// intervals (see handlers.AckedBatch), and any buffered records are sent on
+// Shutdown
+func New(conf Config) (handlers.Handler, error) {
+	w, err := newWriter(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := conf.BatchInterval
+	if interval <= 0 {
+		interval = defaultBatchInterval
+	}
+	size := conf.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	fields := make([]field, 0, len(conf.Fields))
+	for name, key := range conf.Fields {
+		fields = append(fields, field{name: name, path: strings.Split(key, ".")})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].name < fields[j].name
+	})
+
+	return azureHandler{
+		w:      handlers.AckedBatch(w, interval, size),
+		fields: fields,
+	}, nil
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h azureHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	lv := r.Level()
+	if lv == nil {
+		lv = level.Info
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	b.WriteString(`{"` + timeField + `":"`)
+	b.WriteString(r.Time().UTC().Format(time.RFC3339Nano))
+	b.WriteString(`","Level":`)
+	b.WriteJSON(lv.String())
+	b.WriteString(`,"Message":`)
+	b.WriteJSON(r.Message())
+
+	if h.addSource {
+		if f, ok := handlers.Source(r); ok {
+			b.WriteString(`,"Source":`)
+			b.WriteJSON(f.File + ":" + strconv.Itoa(f.Line))
+		}
+	}
+
+	b.WriteString(`,"Attributes":`)
+	b.WriteString(handlers.AttrsJSON(h.replFn, r.Attrs(), h.attrs))
+
+	for _, f := range h.fields {
+		a := h.find(f.path, r.Attrs())
+		if a == nil {
+			continue
+		}
+		b.WriteByte(',')
+		b.WriteJSON(f.name)
+		b.WriteByte(':')
+		writeValue(b, h.replFn, a.Value())
+	}
+	b.WriteString("}\n")
+
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+// find returns the attribute in the path `path` (a key, or the keys of nested
+// groups) in the record's attributes `attrs`, or in the handler's bound
+// attributes
+func (h azureHandler) find(path []string, attrs []attr.Attr) attr.Attr {
+	for _, list := range [2][]attr.Attr{attrs, h.attrs} {
+		if a := findPath(h.replFn, path, list); a != nil {
+			return a
+		}
+	}
+	return nil
+}
+
+func findPath(replFn func(attr.Attr) attr.Attr, path []string, attrs []attr.Attr) attr.Attr {
+	for i := len(attrs) - 1; i >= 0; i-- {
+		a := attrs[i]
+		if a == nil || a.Key() != path[0] {
+			continue
+		}
+		if replFn != nil {
+			if a = replFn(a); a == nil {
+				return nil
+			}
+		}
+		if len(path) == 1 {
+			return a
+		}
+		group, ok := a.Value().([]attr.Attr)
+		if !ok {
+			return nil
+		}
+		return findPath(replFn, path[1:], group)
+	}
+	return nil
+}
+
+// writeValue writes the attribute value `v` as a JSON value for its field
+func writeValue(b *buffer.Buffer, replFn func(attr.Attr) attr.Attr, v any) {
+	switch v := v.(type) {
+	case []attr.Attr:
+		b.WriteString(handlers.AttrsJSON(replFn, v))
+	case error:
+		b.WriteJSON(v.Error())
+	case time.Time:
+		b.WriteJSON(v.UTC().Format(time.RFC3339Nano))
+	case time.Duration:
+		b.WriteJSON(v.String())
+	default:
+		b.WriteJSON(v)
+	}
+}
+
+// writer sends the batches of JSON lines to either ingestion API, as JSON
+// arrays
+type writer struct {
+	url        string
+	client     *http.Client
+	header     http.Header
+	maxRetries int
+	// sign sets the authorization header of the request with body `body`
+	sign func(ctx context.Context, req *http.Request, body []byte) error
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func newWriter(conf Config) (*writer, error) {
+	client, err := conf.HTTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &writer{
+		client:     client,
+		header:     conf.HTTP.Header,
+		maxRetries: conf.MaxRetries,
+		now:        time.Now,
+		sleep:      sleep,
+	}
+	if w.maxRetries <= 0 {
+		w.maxRetries = defaultMaxRetries
+	}
+
+	switch {
+	case conf.WorkspaceID != "" && conf.SharedKey != "" && conf.LogType != "":
+		key, err := base64.StdEncoding.DecodeString(conf.SharedKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shared key: %w", err)
+		}
+
+		endpoint := conf.Endpoint
+		if endpoint == "" {
+			endpoint = "https://" + conf.WorkspaceID + ".ods.opinsights.azure.com"
+		}
+		w.url = strings.TrimRight(endpoint, "/") + "/api/logs?api-version=" + collectorAPIVersion
+		w.sign = w.sharedKey(conf.WorkspaceID, conf.LogType, key)
+
+	case conf.Endpoint != "" && conf.RuleID != "" && conf.Stream != "":
+		token := conf.Token
+		if token == nil {
+			if conf.TenantID == "" || conf.ClientID == "" || conf.ClientSecret == "" {
+				return nil, ErrNoCredentials
+			}
+			token = (&tokenSource{conf: conf, client: client, now: time.Now}).Token
+		}
+
+		w.url = strings.TrimRight(conf.Endpoint, "/") + "/dataCollectionRules/" + url.PathEscape(conf.RuleID) +
+			"/streams/" + url.PathEscape(conf.Stream) + "?api-version=" + ingestionAPIVersion
+		w.sign = func(ctx context.Context, req *http.Request, _ []byte) error {
+			t, err := token(ctx)
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Authorization", "Bearer "+t)
+			return nil
+		}
+
+	default:
+		return nil, ErrNoDestination
+	}
+
+	if _, err := url.ParseRequestURI(w.url); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// sharedKey returns the function signing the Data Collector API requests with
+// the workspace's shared key `key`
+func (w *writer) sharedKey(workspaceID, logType string, key []byte) func(context.Context, *http.Request, []byte) error {
+	return func(_ context.Context, req *http.Request, body []byte) error {
+		date := w.now().UTC().Format(http.TimeFormat)
+
+		mac := hmac.New(sha256.New, key)
+		_, _ = mac.Write([]byte("POST\n" + strconv.Itoa(len(body)) + "\napplication/json\nx-ms-date:" + date + "\n/api/logs"))
+
+		req.Header.Set("Log-Type", logType)
+		req.Header.Set("x-ms-date", date)
+		req.Header.Set("time-generated-field", timeField)
+		req.Header.Set("Authorization", "SharedKey "+workspaceID+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+		return nil
+	}
+}
+
+// Write implements io.Writer, sending the JSON lines in `p` in a request
+func (w *writer) Write(p []byte) (int, error) {
+	return w.WriteContext(context.Background(), p)
+}
+
+// WriteContext implements handlers.ContextWriter, sending the JSON lines in `p`
+// as a JSON array, in a request bound to the context `ctx`. Throttled requests
+// are retried after their Retry-After interval, unless it exceeds the
+// context's deadline
+func (w *writer) WriteContext(ctx context.Context, p []byte) (int, error) {
+	body := toArray(p)
+
+	for retries := 0; ; retries++ {
+		res, err := w.send(ctx, body)
+		if err != nil {
+			return 0, err
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxReplyBytes))
+		_ = res.Body.Close()
+
+		switch {
+		case res.StatusCode >= 200 && res.StatusCode <= 299:
+			return len(p), nil
+		case res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable:
+			return 0, fmt.Errorf("%w: %s", handlers.ErrHTTPStatus, strings.TrimSpace(res.Status))
+		case retries >= w.maxRetries:
+			return 0, fmt.Errorf("%w: %s", ErrThrottled, strings.TrimSpace(res.Status))
+		}
+
+		wait := w.retryAfter(res.Header.Get("Retry-After"), retries)
+		if deadline, ok := ctx.Deadline(); ok && w.now().Add(wait).After(deadline) {
+			return 0, fmt.Errorf("%w: retry after %s", ErrThrottled, wait)
+		}
+		if err := w.sleep(ctx, wait); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (w *writer) send(ctx context.Context, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range w.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := w.sign(ctx, req, body); err != nil {
+		return nil, err
+	}
+	return w.client.Do(req)
+}
+
+// retryAfter returns the interval to wait for before retrying a throttled
+// request, from its Retry-After header `value` (in seconds, or as an HTTP
+// date), or doubling from one second on each retry if absent
+func (w *writer) retryAfter(value string, retries int) time.Duration {
+	wait := minRetryWait << retries
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		wait = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		wait = t.Sub(w.now())
+	}
+
+	switch {
+	case wait < 0:
+		return 0
+	case wait > maxRetryWait:
+		return maxRetryWait
+	default:
+		return wait
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// toArray returns the JSON lines in `p` as a JSON array
+func toArray(p []byte) []byte {
+	body := make([]byte, 0, len(p)+2)
+	body = append(body, '[')
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+		if len(body) > 1 {
+			body = append(body, ',')
+		}
+		body = append(body, line...)
+	}
+	return append(body, ']')
+}
+
+// Shutdown implements handlers.Shutdowner, closing the idle connections
+func (w *writer) Shutdown(context.Context) error {
+	w.client.CloseIdleConnections()
+	return nil
+}
+
+// tokenSource fetches and caches the bearer tokens of an Entra ID application,
+// with the client credentials flow
+type tokenSource struct {
+	conf   Config
+	client *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+	now    func() time.Time
+}
+
+// Token returns the cached token, or fetches a new one if it is about to
+// expire
+func (s *tokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Before(s.expiry) {
+		return s.token, nil
+	}
+
+	authority := s.conf.AuthorityHost
+	if authority == "" {
+		authority = defaultAuthorityHost
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {s.conf.ClientID},
+		"client_secret": {s.conf.ClientSecret},
+		"scope":         {monitorScope},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimRight(authority, "/")+"/"+url.PathEscape(s.conf.TenantID)+"/oauth2/v2.0/token",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return "", fmt.Errorf("token request: %w: %s", handlers.ErrHTTPStatus, strings.TrimSpace(res.Status))
+	}
+
+	var reply struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxReplyBytes)).Decode(&reply); err != nil {
+		return "", fmt.Errorf("token request: %w", err)
+	}
+
+	s.token = reply.AccessToken
+	s.expiry = s.now().Add(time.Duration(reply.ExpiresIn)*time.Second - tokenLeeway)
+	return s.token, nil
+}
+
+// Capabilities implements handlers.Capable, returning CapGroups and CapBinary,
+// along with CapFlush and CapRemote as records are sent in batches
+func (h azureHandler) Capabilities() handlers.Capability {
+	return handlers.CapGroups | handlers.CapBinary | handlers.CapFlush | handlers.CapRemote
+}
+
+// Ping implements handlers.Pinger, checking the health of the batch writer
+func (h azureHandler) Ping(ctx context.Context) error {
+	return handlers.Ping(ctx, h.w)
+}
+
+// Shutdown implements handlers.Shutdowner, sending any buffered records
+func (h azureHandler) Shutdown(ctx context.Context) error {
+	return handlers.Shutdown(ctx, h.w)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h azureHandler) With(attrs ...attr.Attr) handlers.Handler {
+	return azureHandler{
+		w:         h.w,
+		fields:    h.fields,
+		addSource: h.addSource,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     attrs,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h azureHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h azureHandler) WithSource(addSource bool) handlers.Handler {
+	return azureHandler{
+		w:         h.w,
+		fields:    h.fields,
+		addSource: addSource,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h azureHandler) WithLevel(level level.Leveler) handlers.Handler {
+	return azureHandler{
+		w:         h.w,
+		fields:    h.fields,
+		addSource: h.addSource,
+		levelRef:  level,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h azureHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) handlers.Handler {
+	return azureHandler{
+		w:         h.w,
+		fields:    h.fields,
+		addSource: h.addSource,
+		levelRef:  h.levelRef,
+		replFn:    fn,
+		attrs:     h.attrs,
+	}
+}
@@ -0,0 +1,234 @@
+//go:build !logx_tiny
+
+package azureh
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// intake is a fake Azure Monitor endpoint recording the requests it receives
+type intake struct {
+	mu       sync.Mutex
+	reqs     []*http.Request
+	bodies   [][]byte
+	tokens   int
+	statuses []int
+}
+
+func (i *intake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if r.URL.Path == "/tenant/oauth2/v2.0/token" {
+		i.tokens++
+		form, _ := url.ParseQuery(string(body))
+		if form.Get("client_secret") != "secret" || form.Get("scope") != monitorScope {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"token_type":"Bearer","expires_in":3599,"access_token":"token-` + strconv.Itoa(i.tokens) + `"}`))
+		return
+	}
+
+	i.reqs = append(i.reqs, r)
+	i.bodies = append(i.bodies, body)
+	if len(i.statuses) > 0 {
+		status := i.statuses[0]
+		i.statuses = i.statuses[1:]
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (i *intake) requests() ([]*http.Request, [][]byte) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return append([]*http.Request(nil), i.reqs...), append([][]byte(nil), i.bodies...)
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("LogsIngestion", func(t *testing.T) {
+		in := &intake{}
+		srv := httptest.NewServer(in)
+		defer srv.Close()
+
+		h, err := New(Config{
+			Endpoint:      srv.URL,
+			RuleID:        "dcr-0123",
+			Stream:        "Custom-AppLogs_CL",
+			TenantID:      "tenant",
+			ClientID:      "app",
+			ClientSecret:  "secret",
+			AuthorityHost: srv.URL,
+			Fields:        map[string]string{"Service": "service", "Status": "http.status"},
+			BatchInterval: time.Hour,
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		h = h.With(attr.String("service", "api"))
+
+		for _, msg := range []string{"started", "served"} {
+			r := records.New(time.Unix(0, 0).UTC(), level.Warn, msg,
+				attr.New("http", []attr.Attr{attr.Int("status", 503)}))
+			if err := h.Handle(r); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+		}
+		if err := handlers.Shutdown(context.Background(), h); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		reqs, bodies := in.requests()
+		if len(reqs) != 1 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 1, len(reqs))
+			return
+		}
+		if path := reqs[0].URL.Path; path != "/dataCollectionRules/dcr-0123/streams/Custom-AppLogs_CL" {
+			t.Errorf("output mismatch error: got path %q", path)
+		}
+		if auth := reqs[0].Header.Get("Authorization"); auth != "Bearer token-1" {
+			t.Errorf("output mismatch error: got authorization %q", auth)
+		}
+
+		var rows []map[string]any
+		if err := json.Unmarshal(bodies[0], &rows); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(rows) != 2 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 2, len(rows))
+			return
+		}
+		row := rows[1]
+		for key, wants := range map[string]any{
+			"TimeGenerated": "1970-01-01T00:00:00Z",
+			"Level":         "warn",
+			"Message":       "served",
+			"Service":       "api",
+			"Status":        float64(503),
+		} {
+			if row[key] != wants {
+				t.Errorf("output mismatch error: %s: wanted %v ; got %v", key, wants, row[key])
+			}
+		}
+		if attrs, ok := row["Attributes"].(map[string]any); !ok || attrs["service"] != "api" {
+			t.Errorf("output mismatch error: got attributes %v", row["Attributes"])
+		}
+	})
+	t.Run("DataCollector", func(t *testing.T) {
+		in := &intake{}
+		srv := httptest.NewServer(in)
+		defer srv.Close()
+
+		key := base64.StdEncoding.EncodeToString([]byte("workspace-key"))
+		w, err := newWriter(Config{Endpoint: srv.URL, WorkspaceID: "ws", SharedKey: key, LogType: "AppLogs"})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if _, err := w.Write([]byte("{\"Message\":\"a\"}\n{\"Message\":\"b\"}\n")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		reqs, bodies := in.requests()
+		if string(bodies[0]) != `[{"Message":"a"},{"Message":"b"}]` {
+			t.Errorf("output mismatch error: got body %s", bodies[0])
+		}
+
+		req := reqs[0]
+		if req.URL.Path != "/api/logs" || req.Header.Get("Log-Type") != "AppLogs" ||
+			req.Header.Get("time-generated-field") != "TimeGenerated" {
+			t.Errorf("output mismatch error: got request %s with headers %v", req.URL, req.Header)
+		}
+
+		mac := hmac.New(sha256.New, []byte("workspace-key"))
+		_, _ = mac.Write([]byte("POST\n" + strconv.Itoa(len(bodies[0])) + "\napplication/json\nx-ms-date:" +
+			req.Header.Get("x-ms-date") + "\n/api/logs"))
+		if wants := "SharedKey ws:" + base64.StdEncoding.EncodeToString(mac.Sum(nil)); req.Header.Get("Authorization") != wants {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, req.Header.Get("Authorization"))
+		}
+	})
+	t.Run("Throttled", func(t *testing.T) {
+		in := &intake{statuses: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}}
+		srv := httptest.NewServer(in)
+		defer srv.Close()
+
+		w, _ := newWriter(Config{Endpoint: srv.URL, RuleID: "dcr", Stream: "logs", Token: func(context.Context) (string, error) {
+			return "token", nil
+		}})
+		var waits []time.Duration
+		w.sleep = func(_ context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		}
+
+		if _, err := w.Write([]byte("{}\n")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if reqs, _ := in.requests(); len(reqs) != 3 || len(waits) != 2 {
+			t.Errorf("output mismatch error: got %d requests and %d waits", len(reqs), len(waits))
+		}
+
+		in.mu.Lock()
+		in.statuses = []int{429, 429, 429, 429}
+		in.mu.Unlock()
+		if _, err := w.Write([]byte("{}\n")); !errors.Is(err, ErrThrottled) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrThrottled, err)
+		}
+	})
+	t.Run("RetryAfter", func(t *testing.T) {
+		w := &writer{now: func() time.Time { return time.Unix(0, 0) }}
+
+		for _, testcase := range []struct {
+			value   string
+			retries int
+			wants   time.Duration
+		}{
+			{"5", 0, 5 * time.Second},
+			{"", 2, 4 * time.Second},
+			{"3600", 0, maxRetryWait},
+			{time.Unix(10, 0).UTC().Format(http.TimeFormat), 0, 10 * time.Second},
+		} {
+			if got := w.retryAfter(testcase.value, testcase.retries); got != testcase.wants {
+				t.Errorf("output mismatch error: %q: wanted %v ; got %v", testcase.value, testcase.wants, got)
+			}
+		}
+	})
+	t.Run("Config", func(t *testing.T) {
+		if _, err := New(Config{}); !errors.Is(err, ErrNoDestination) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrNoDestination, err)
+		}
+		if _, err := New(Config{Endpoint: "https://dce", RuleID: "dcr", Stream: "logs"}); !errors.Is(err, ErrNoCredentials) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrNoCredentials, err)
+		}
+	})
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultBatchSize is the buffer size used by Batch when none is provided
+const defaultBatchSize = 64 << 10 // 64 KiB
+
+// WriteSyncCloser is a WriteSyncer that also needs to be closed, to release
+// its resources
+type WriteSyncCloser interface {
+	WriteSyncer
+	io.Closer
+}
+
+type batchWriter struct {
+	mu       sync.Mutex
+	ws       WriteSyncer
+	buf      []byte
+	size     int
+	interval time.Duration
+	err      error
+
+	// in acknowledged mode, a batch is kept in `pending` (with its idempotency
+	// key) until the sink accepts it
+	acked      bool
+	pending    []byte
+	pendingKey string
+
+	// ctx is the parent of the background flushes' contexts, cancelled when
+	// the context of a Shutdown call is done
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	done   chan struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// Batch creates a WriteSyncCloser that coalesces writes to `w` in a buffer,
+// writing it out once it grows past `size` bytes, or every `interval`. This
+// trades a small delay in the output for far fewer write calls to `w`.
+//
+// If `size` is not greater than zero, a 64 KiB buffer is used. If `interval`
+// is not greater than zero, the buffer is only written out when full, on Sync
+// and on Close.
+//
+// Closing the writer flushes any buffered data, but does not close `w`. Errors
+// raised when flushing in the background are returned on the next call to
+// Write, Sync or Close.
+//
+// Each batch is written with WriteContext, so that remote sinks (implementing
+// ContextWriter, or network connections) do not block indefinitely: flushes in
+// the background are bound to `interval`, and the final flush on Shutdown to
+// its context, which also interrupts any flush in progress once it is done
+func Batch(w io.Writer, interval time.Duration, size int) WriteSyncCloser {
+	return newBatch(w, interval, size, false)
+}
+
+// AckedBatch is similar to Batch, but with at-least-once delivery: a batch is
+// only dropped once the sink acknowledges it, by returning no error from its
+// Write (e.g. the HTTPWriter, on a 2xx response). Failed batches are retried
+// as-is on the following flushes, while new writes are buffered behind them;
+// once the buffer is full as well, Write returns the error of the sink. The
+// errors of the background flushes are not reported, as their batches are
+// kept; Sync and Close retry the kept batches before returning an error.
+//
+// Each batch is written with a random idempotency key in its context (see
+// IdempotencyKey), the same on every retry, for the sink to deduplicate
+// batches that it received but failed to acknowledge. The HTTPWriter sends it
+// in the Idempotency-Key header
+func AckedBatch(w io.Writer, interval time.Duration, size int) WriteSyncCloser {
+	return newBatch(w, interval, size, true)
+}
+
+func newBatch(w io.Writer, interval time.Duration, size int, acked bool) WriteSyncCloser {
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &batchWriter{
+		ws:       AddSync(w),
+		buf:      make([]byte, 0, size),
+		size:     size,
+		interval: interval,
+		acked:    acked,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	if interval > 0 {
+		b.wg.Add(1)
+		go b.run(interval)
+	}
+	return b
+}
+
+// Write implements io.Writer, buffering `p` until the next flush
+func (b *batchWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, os.ErrClosed
+	}
+	if err := b.takeErr(); err != nil {
+		return 0, err
+	}
+
+	if len(b.buf)+len(p) > b.size {
+		if err := b.flush(context.Background()); err != nil {
+			// in acknowledged mode, the buffer may have become the pending
+			// batch, leaving room for `p`
+			if !b.acked || len(b.buf)+len(p) > b.size {
+				return 0, err
+			}
+		}
+	}
+	// writes larger than the buffer skip it entirely
+	if len(p) > b.size {
+		return b.ws.Write(p)
+	}
+
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+// Sync implements WriteSyncer, flushing the buffer and syncing the underlying
+// writer
+func (b *batchWriter) Sync() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.takeErr(); err != nil {
+		return err
+	}
+	if err := b.flush(context.Background()); err != nil {
+		return err
+	}
+	return b.ws.Sync()
+}
+
+// Close implements io.Closer, stopping the background flush and writing out
+// any buffered data
+func (b *batchWriter) Close() error {
+	return b.close(context.Background())
+}
+
+func (b *batchWriter) close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.done)
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	b.cancel()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.takeErr(); err != nil {
+		return err
+	}
+	return b.flush(ctx)
+}
+
+// Ping implements Pinger, returning os.ErrClosed once the writer is closed or
+// any pending flush error, and checking the health of the underlying writer
+// otherwise
+func (b *batchWriter) Ping(ctx context.Context) error {
+	b.mu.Lock()
+	closed, err := b.closed, b.err
+	b.mu.Unlock()
+
+	switch {
+	case closed:
+		return os.ErrClosed
+	case err != nil:
+		return err
+	}
+	return Ping(ctx, b.ws)
+}
+
+// Pressure implements Pressurer, returning the fill ratio of the buffer
+// (including a batch pending acknowledgment)
+func (b *batchWriter) Pressure() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return ratio(int64(len(b.buf)+len(b.pending)), int64(b.size))
+}
+
+// Capabilities implements Capable, returning CapFlush (as writes are buffered)
+// along with the capabilities of the underlying writer
+func (b *batchWriter) Capabilities() Capability {
+	return EncoderCapabilities(CapFlush, b.ws)
+}
+
+// Shutdown implements Shutdowner, closing the writer and shutting down the
+// underlying writer. Once the context `ctx` is done, any flush in progress is
+// interrupted and the buffered data is discarded
+func (b *batchWriter) Shutdown(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, b.cancel)
+	defer stop()
+
+	if err := b.close(ctx); err != nil {
+		return err
+	}
+	return Shutdown(ctx, b.ws)
+}
+
+func (b *batchWriter) run(interval time.Duration) {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(b.ctx, b.interval)
+			b.mu.Lock()
+			// in acknowledged mode, failed batches are kept and retried
+			if err := b.flush(ctx); err != nil && b.err == nil && !b.acked {
+				b.err = err
+			}
+			b.mu.Unlock()
+			cancel()
+		}
+	}
+}
+
+func (b *batchWriter) flush(ctx context.Context) error {
+	if b.acked {
+		return b.flushAcked(ctx)
+	}
+	if len(b.buf) == 0 {
+		return nil
+	}
+
+	_, err := WriteContext(ctx, b.ws, b.buf)
+	b.buf = b.buf[:0]
+	return err
+}
+
+// flushAcked writes out the pending batch, if any, and then the buffer as a
+// new batch. A batch is only dropped once written without errors
+func (b *batchWriter) flushAcked(ctx context.Context) error {
+	for len(b.pending) > 0 || len(b.buf) > 0 {
+		if len(b.pending) == 0 {
+			b.pending, b.buf = b.buf, b.pending[:0]
+			b.pendingKey = newIdempotencyKey()
+		}
+
+		if _, err := WriteContext(WithIdempotencyKey(ctx, b.pendingKey), b.ws, b.pending); err != nil {
+			return err
+		}
+		b.pending, b.pendingKey = b.pending[:0], ""
+	}
+	return nil
+}
+
+func (b *batchWriter) takeErr() error {
+	err := b.err
+	b.err = nil
+	return err
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingWriter counts the Write calls it receives
+type countingWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes int
+	err    error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.writes++
+	return w.buf.Write(p)
+}
+
+func (w *countingWriter) state() (string, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.String(), w.writes
+}
+
+// stalledWriter is a ContextWriter for a sink that never responds
+type stalledWriter struct{}
+
+func (stalledWriter) Write(p []byte) (int, error) {
+	select {}
+}
+
+func (stalledWriter) WriteContext(ctx context.Context, p []byte) (int, error) {
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
+
+func TestBatch(t *testing.T) {
+	t.Run("FlushOnSize", func(t *testing.T) {
+		w := &countingWriter{}
+		b := Batch(w, 0, 10)
+		defer b.Close()
+
+		for _, line := range []string{"abcd\n", "efgh\n", "ijkl\n"} {
+			if _, err := b.Write([]byte(line)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+		}
+
+		out, writes := w.state()
+		if out != "abcd\nefgh\n" || writes != 1 {
+			t.Errorf("output mismatch error: wanted %q in 1 write ; got %q in %d writes", "abcd\nefgh\n", out, writes)
+		}
+	})
+	t.Run("FlushOnClose", func(t *testing.T) {
+		w := &countingWriter{}
+		b := Batch(w, 0, 0)
+
+		_, _ = b.Write([]byte("first\n"))
+		_, _ = b.Write([]byte("second\n"))
+		if out, _ := w.state(); out != "" {
+			t.Errorf("output mismatch error: wanted empty string ; got %q", out)
+		}
+
+		if err := b.Close(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		out, writes := w.state()
+		if out != "first\nsecond\n" || writes != 1 {
+			t.Errorf("output mismatch error: wanted %q in 1 write ; got %q in %d writes", "first\nsecond\n", out, writes)
+		}
+
+		if _, err := b.Write([]byte("third\n")); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("unexpected error: wanted %v ; got %v", os.ErrClosed, err)
+		}
+	})
+	t.Run("FlushOnInterval", func(t *testing.T) {
+		w := &countingWriter{}
+		b := Batch(w, 10*time.Millisecond, 0)
+		defer b.Close()
+
+		_, _ = b.Write([]byte("line\n"))
+
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			if out, _ := w.state(); out == "line\n" {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Errorf("expected the buffer to be flushed within the interval")
+	})
+	t.Run("LargeWrite", func(t *testing.T) {
+		w := &countingWriter{}
+		b := Batch(w, 0, 4)
+		defer b.Close()
+
+		_, _ = b.Write([]byte("ab"))
+		_, _ = b.Write([]byte("larger than the buffer"))
+
+		out, writes := w.state()
+		if out != "ablarger than the buffer" || writes != 2 {
+			t.Errorf("output mismatch error: wanted %q in 2 writes ; got %q in %d writes", "ablarger than the buffer", out, writes)
+		}
+	})
+	t.Run("FlushError", func(t *testing.T) {
+		errWrite := errors.New("write failed")
+		w := &countingWriter{err: errWrite}
+		b := Batch(w, 0, 0)
+		defer b.Close()
+
+		_, _ = b.Write([]byte("line\n"))
+		if err := b.Sync(); !errors.Is(err, errWrite) {
+			t.Errorf("unexpected error: wanted %v ; got %v", errWrite, err)
+		}
+	})
+	t.Run("ShutdownDeadline", func(t *testing.T) {
+		b := Batch(stalledWriter{}, 0, 0)
+		_, _ = b.Write([]byte("line\n"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		done := make(chan error)
+		go func() { done <- Shutdown(ctx, b) }()
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("unexpected error: wanted %v ; got %v", context.DeadlineExceeded, err)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("expected Shutdown to return once its context is done")
+		}
+	})
+}
+
+func TestAckedBatchRecovery(t *testing.T) {
+	errDown := errors.New("sink is down")
+
+	t.Run("WriteBehindPending", func(t *testing.T) {
+		w := &countingWriter{err: errDown}
+		b := AckedBatch(w, 0, 4)
+		defer b.Close()
+
+		if _, err := b.Write([]byte("aa\n")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		// the buffer becomes the pending batch, leaving room for the write
+		if _, err := b.Write([]byte("bb\n")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		// both the pending batch and the buffer are full
+		if _, err := b.Write([]byte("cc\n")); !errors.Is(err, errDown) {
+			t.Errorf("unexpected error: wanted %v ; got %v", errDown, err)
+		}
+
+		w.mu.Lock()
+		w.err = nil
+		w.mu.Unlock()
+
+		if err := b.Sync(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if out, _ := w.state(); out != "aa\nbb\n" {
+			t.Errorf("output mismatch error: wanted %q ; got %q", "aa\nbb\n", out)
+		}
+	})
+	t.Run("BackgroundRetry", func(t *testing.T) {
+		w := &countingWriter{err: errDown}
+		b := AckedBatch(w, time.Millisecond, 0)
+
+		_, _ = b.Write([]byte("kept\n"))
+		// let the background flushes fail
+		time.Sleep(20 * time.Millisecond)
+
+		w.mu.Lock()
+		w.err = nil
+		w.mu.Unlock()
+
+		deadline := time.Now().Add(time.Second)
+		for out, _ := w.state(); out != "kept\n"; out, _ = w.state() {
+			if time.Now().After(deadline) {
+				t.Errorf("output mismatch error: wanted %q ; got %q", "kept\n", out)
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		if err := b.Sync(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := b.Close(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/stats"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// errHandlerPanic is reported to the circuit when the wrapped Handler panics
+var errHandlerPanic = errors.New("handler panicked")
+
+// BreakerState is the state of a Breaker's circuit
+type BreakerState int
+
+const (
+	// BreakerClosed sends records to the wrapped Handler
+	BreakerClosed BreakerState = iota
+	// BreakerOpen short-circuits records to the fallback Handler, or drops them
+	BreakerOpen
+	// BreakerHalfOpen lets a single record through to the wrapped Handler, as a
+	// probe on whether it has recovered
+	BreakerHalfOpen
+)
+
+// String implements fmt.Stringer
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig configures when a Breaker trips, and what it does while open
+type BreakerConfig struct {
+	// Threshold is the number of consecutive Handle errors that trip the
+	// circuit, defaulting to 5
+	Threshold int
+	// Cooldown is the time the circuit stays open before a record is let
+	// through as a probe, defaulting to 30 seconds
+	Cooldown time.Duration
+	// Fallback is the Handler that records are sent to while the circuit is
+	// open, if set. Otherwise, those records are dropped
+	Fallback Handler
+}
+
+// Breaker is a Handler that wraps a remote (or otherwise unreliable) Handler
+// with a circuit breaker: after a number of consecutive Handle errors the
+// circuit opens, and records are short-circuited to a fallback Handler (or
+// dropped) without calling the wrapped Handler, so that a dead sink does not
+// add its timeouts to every logging call.
+//
+// Once the cooldown elapses, the next record is sent to the wrapped Handler as
+// a probe; if it succeeds the circuit closes, otherwise it opens for another
+// cooldown.
+//
+// Handlers derived from a Breaker (with its With* methods) share its circuit
+// and counters
+type Breaker struct {
+	breakerHandler
+}
+
+type breakerHandler struct {
+	h        Handler
+	fallback Handler
+	c        *circuit
+}
+
+type circuit struct {
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	threshold int
+	cooldown  time.Duration
+	dropped   atomic.Uint64
+}
+
+// NewBreaker creates a Breaker for the Handler `h`, as configured by the
+// BreakerConfig `conf`
+func NewBreaker(h Handler, conf BreakerConfig) *Breaker {
+	if h == nil {
+		h = Unimpl()
+	}
+	if conf.Threshold <= 0 {
+		conf.Threshold = defaultBreakerThreshold
+	}
+	if conf.Cooldown <= 0 {
+		conf.Cooldown = defaultBreakerCooldown
+	}
+
+	return &Breaker{
+		breakerHandler: breakerHandler{
+			h:        h,
+			fallback: conf.Fallback,
+			c: &circuit{
+				threshold: conf.Threshold,
+				cooldown:  conf.Cooldown,
+			},
+		},
+	}
+}
+
+// State returns the current state of the Breaker's circuit
+func (b *Breaker) State() BreakerState {
+	b.c.mu.Lock()
+	defer b.c.mu.Unlock()
+
+	return b.c.state
+}
+
+// Dropped returns the number of records discarded so far while the circuit was
+// open, when no fallback Handler is set
+func (b *Breaker) Dropped() uint64 {
+	return b.c.dropped.Load()
+}
+
+// allow returns whether a record may be sent to the wrapped Handler, and
+// whether it is a probe of a half-open circuit
+func (c *circuit) allow() (ok, probe bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case BreakerClosed:
+		return true, false
+	case BreakerOpen:
+		if time.Since(c.openedAt) < c.cooldown {
+			return false, false
+		}
+		c.state = BreakerHalfOpen
+		return true, true
+	default:
+		// a probe is already in flight
+		return false, false
+	}
+}
+
+// report updates the circuit with the outcome of a Handle call
+func (c *circuit) report(err error, probe bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err == nil {
+		// late successes from before the circuit opened do not close it
+		if probe || c.state == BreakerClosed {
+			c.state = BreakerClosed
+			c.failures = 0
+		}
+		return
+	}
+
+	c.failures++
+	if probe || (c.state == BreakerClosed && c.failures >= c.threshold) {
+		c.state = BreakerOpen
+		c.openedAt = time.Now()
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (b breakerHandler) Enabled(level level.Level) bool {
+	return b.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (b breakerHandler) Handle(r records.Record) error {
+	if !b.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	ok, probe := b.c.allow()
+	if !ok {
+		if b.fallback != nil {
+			return b.fallback.Handle(r)
+		}
+		b.c.dropped.Add(1)
+		stats.Dropped.Add(1)
+		return nil
+	}
+
+	// a panicking Handler counts as a failure, so that a panicking probe does
+	// not leave the circuit half-open
+	var done bool
+	defer func() {
+		if !done {
+			b.c.report(errHandlerPanic, probe)
+		}
+	}()
+
+	err := b.h.Handle(r)
+	done = true
+	b.c.report(err, probe)
+	return err
+}
+
+// Ping implements Pinger, checking the health of the wrapped Handler
+func (b breakerHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, b.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the wrapped Handler and the
+// fallback Handler, if set
+func (b breakerHandler) Shutdown(ctx context.Context) error {
+	err := Shutdown(ctx, b.h)
+	if b.fallback != nil {
+		err = errors.Join(err, Shutdown(ctx, b.fallback))
+	}
+	return err
+}
+
+// derive returns a copy of this Handler, applying `fn` to the wrapped and the
+// fallback Handlers
+func (b breakerHandler) derive(fn func(Handler) Handler) Handler {
+	fallback := b.fallback
+	if fallback != nil {
+		fallback = fn(fallback)
+	}
+	return breakerHandler{
+		h:        fn(b.h),
+		fallback: fallback,
+		c:        b.c,
+	}
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (b breakerHandler) With(attrs ...attr.Attr) Handler {
+	return b.derive(func(h Handler) Handler { return h.With(attrs...) })
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (b breakerHandler) WithSource(addSource bool) Handler {
+	return b.derive(func(h Handler) Handler { return h.WithSource(addSource) })
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (b breakerHandler) WithLevel(level level.Leveler) Handler {
+	return b.derive(func(h Handler) Handler { return h.WithLevel(level) })
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (b breakerHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return b.derive(func(h Handler) Handler { return h.WithReplaceFn(fn) })
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// flakyHandler is a testHandler that fails while `fail` is set
+type flakyHandler struct {
+	testHandler
+	fail  *atomic.Bool
+	calls *atomic.Int64
+}
+
+func (h flakyHandler) Handle(r records.Record) error {
+	h.calls.Add(1)
+	if h.fail.Load() {
+		return errors.New("sink is down")
+	}
+	return h.testHandler.Handle(r)
+}
+
+// panicHandler is a flakyHandler that panics while `panics` is set
+type panicHandler struct {
+	flakyHandler
+	panics *atomic.Bool
+}
+
+func (h panicHandler) Handle(r records.Record) error {
+	if h.panics.Load() {
+		panic("sink is down")
+	}
+	return h.flakyHandler.Handle(r)
+}
+
+func TestBreaker(t *testing.T) {
+	newFlaky := func() flakyHandler {
+		h := flakyHandler{testHandler: newTestHandler(), fail: &atomic.Bool{}, calls: &atomic.Int64{}}
+		h.fail.Store(true)
+		return h
+	}
+	rec := records.New(time.Now(), level.Info, "message")
+
+	t.Run("TripAndDrop", func(t *testing.T) {
+		h := newFlaky()
+		b := NewBreaker(h, BreakerConfig{Threshold: 3, Cooldown: time.Hour})
+
+		for i := 0; i < 3; i++ {
+			if err := b.Handle(rec); err == nil {
+				t.Errorf("expected an error from the wrapped handler")
+			}
+		}
+		if b.State() != BreakerOpen {
+			t.Errorf("output mismatch error: wanted %v ; got %v", BreakerOpen, b.State())
+		}
+
+		for i := 0; i < 5; i++ {
+			if err := b.Handle(rec); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+		if calls := h.calls.Load(); calls != 3 {
+			t.Errorf("output mismatch error: wanted %v calls ; got %v", 3, calls)
+		}
+		if dropped := b.Dropped(); dropped != 5 {
+			t.Errorf("output mismatch error: wanted %v dropped ; got %v", 5, dropped)
+		}
+	})
+	t.Run("Fallback", func(t *testing.T) {
+		fallback := newTestHandler()
+		b := NewBreaker(newFlaky(), BreakerConfig{Threshold: 1, Cooldown: time.Hour, Fallback: fallback})
+
+		_ = b.Handle(rec)
+		if err := b.With().Handle(rec); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if n := len(fallback.Records()); n != 1 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 1, n)
+		}
+		if b.Dropped() != 0 {
+			t.Errorf("expected no records to be dropped with a fallback")
+		}
+	})
+	t.Run("Probe", func(t *testing.T) {
+		h := newFlaky()
+		b := NewBreaker(h, BreakerConfig{Threshold: 1, Cooldown: 10 * time.Millisecond})
+
+		_ = b.Handle(rec)
+		time.Sleep(20 * time.Millisecond)
+
+		// the probe fails, opening the circuit for another cooldown
+		if err := b.Handle(rec); err == nil {
+			t.Errorf("expected an error from the probe")
+		}
+		if b.State() != BreakerOpen {
+			t.Errorf("output mismatch error: wanted %v ; got %v", BreakerOpen, b.State())
+		}
+
+		h.fail.Store(false)
+		time.Sleep(20 * time.Millisecond)
+
+		if err := b.Handle(rec); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if b.State() != BreakerClosed {
+			t.Errorf("output mismatch error: wanted %v ; got %v", BreakerClosed, b.State())
+		}
+		if n := len(h.Records()); n != 1 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 1, n)
+		}
+	})
+	t.Run("PanickingProbe", func(t *testing.T) {
+		h := panicHandler{flakyHandler: newFlaky(), panics: &atomic.Bool{}}
+		b := NewBreaker(h, BreakerConfig{Threshold: 1, Cooldown: 10 * time.Millisecond})
+
+		_ = b.Handle(rec)
+		time.Sleep(20 * time.Millisecond)
+
+		// the probe panics, opening the circuit for another cooldown
+		h.panics.Store(true)
+		func() {
+			defer func() {
+				if v := recover(); v == nil {
+					t.Errorf("expected the probe to panic")
+				}
+			}()
+			_ = b.Handle(rec)
+		}()
+		if b.State() != BreakerOpen {
+			t.Errorf("output mismatch error: wanted %v ; got %v", BreakerOpen, b.State())
+		}
+
+		h.panics.Store(false)
+		h.fail.Store(false)
+		time.Sleep(20 * time.Millisecond)
+
+		if err := b.Handle(rec); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if b.State() != BreakerClosed {
+			t.Errorf("output mismatch error: wanted %v ; got %v", BreakerClosed, b.State())
+		}
+	})
+	t.Run("ResetOnSuccess", func(t *testing.T) {
+		h := newFlaky()
+		b := NewBreaker(h, BreakerConfig{Threshold: 2, Cooldown: time.Hour})
+
+		_ = b.Handle(rec)
+		h.fail.Store(false)
+		_ = b.Handle(rec)
+		h.fail.Store(true)
+		_ = b.Handle(rec)
+
+		if b.State() != BreakerClosed {
+			t.Errorf("output mismatch error: wanted %v ; got %v", BreakerClosed, b.State())
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"runtime/debug"
+
+	"github.com/zalgonoise/attr"
+)
+
+// WithBuildInfo decorates the Handler `h` so that every Record it handles
+// carries a `build` attribute group with the main module's version, and the
+// VCS revision and dirty flag that the binary was built from, as read from its
+// build info (see debug.ReadBuildInfo):
+//
+//	"build": {"version": "v1.4.0", "revision": "2f7c3a1...", "dirty": false}
+//
+// The version is `(devel)` for binaries built from a working tree, and the
+// VCS fields are only present when the binary was built with VCS stamping
+// (the default with `go build` in a repository). Fields that are not known are
+// omitted, as is the group if none are
+func WithBuildInfo(h Handler) Handler {
+	return Enrich(h, BuildInfo())
+}
+
+// BuildInfo returns a static Enricher with the `build` attribute group added
+// by WithBuildInfo, or with no attributes if the build info is unavailable
+func BuildInfo() Enricher {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return staticEnricher{}
+	}
+
+	group := buildAttrs(info)
+	if len(group) == 0 {
+		return staticEnricher{}
+	}
+	return staticEnricher{attrs: []attr.Attr{attr.New("build", group)}}
+}
+
+// buildAttrs returns the version, VCS revision and dirty flag in the build
+// info `info`, as attributes
+func buildAttrs(info *debug.BuildInfo) []attr.Attr {
+	attrs := make([]attr.Attr, 0, 3)
+	if info.Main.Version != "" {
+		attrs = append(attrs, attr.String("version", info.Main.Version))
+	}
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			attrs = append(attrs, attr.String("revision", setting.Value))
+		case "vcs.modified":
+			attrs = append(attrs, attr.New("dirty", setting.Value == "true"))
+		}
+	}
+
+	return attrs
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"reflect"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestWithBuildInfo(t *testing.T) {
+	t.Run("Attrs", func(t *testing.T) {
+		info := &debug.BuildInfo{
+			Main: debug.Module{Path: "example.com/app", Version: "v1.4.0"},
+			Settings: []debug.BuildSetting{
+				{Key: "-trimpath", Value: "true"},
+				{Key: "vcs.revision", Value: "2f7c3a1"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		}
+
+		wants := map[string]any{"version": "v1.4.0", "revision": "2f7c3a1", "dirty": true}
+		if got := attr.Map(buildAttrs(info)...); !reflect.DeepEqual(wants, got) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, got)
+		}
+	})
+	t.Run("Handler", func(t *testing.T) {
+		th := newTestHandler()
+		h := WithBuildInfo(th)
+
+		if err := h.Handle(records.New(time.Now(), level.Info, "test message")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		// test binaries carry no version nor VCS stamps, in which case the
+		// Handler is returned as-is
+		if _, ok := h.(testHandler); ok {
+			return
+		}
+		if _, ok := attr.Map(th.Records()[0].Attrs()...)["build"]; !ok {
+			t.Errorf("output mismatch error: wanted a build group ; got %v", th.Records()[0].Attrs())
+		}
+	})
+	t.Run("Nil", func(t *testing.T) {
+		if WithBuildInfo(nil) != nil {
+			t.Errorf("expected output to be nil")
+		}
+	})
+}
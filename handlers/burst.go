@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	defaultBurstWindow     = 10 * time.Second
+	defaultBurstFactor     = 10
+	defaultBurstMinRecords = 100
+
+	// burstSmoothing is the weight of the last window in a key's baseline
+	burstSmoothing = 0.3
+	// burstIdleWindows is the number of windows without records after which a
+	// key is forgotten
+	burstIdleWindows = 10
+
+	burstMessage = "log_burst_detected"
+)
+
+// BurstConfig configures the detection of record bursts by a Burst Handler
+type BurstConfig struct {
+	// Key is the key of the attribute whose value the record rates are kept
+	// by, like an event's name. Attributes bound to the Handler (with With) are
+	// also looked up. If empty, or absent from a record, its message is used
+	Key string
+	// Window is the period record rates are measured over, 10 seconds by
+	// default
+	Window time.Duration
+	// Factor is how many times a key's rate in a window must exceed its
+	// baseline (the moving average of its previous windows) to be a burst, 10
+	// by default
+	Factor float64
+	// MinRecords is the minimum number of records of a key in a window for it
+	// to be a burst, 100 by default. It keeps the rare records from being
+	// flagged, since any record is a burst over an empty baseline
+	MinRecords int
+	// Level is the level of the `log_burst_detected` records, level.Warn by
+	// default
+	Level level.Level
+}
+
+type burstHandler struct {
+	h     Handler
+	conf  BurstConfig
+	bound []attr.Attr
+	b     *bursts
+}
+
+type burstRate struct {
+	start    time.Time
+	count    int
+	baseline float64
+	flagged  bool
+}
+
+type bursts struct {
+	mu    sync.Mutex
+	sweep time.Time
+	rates map[string]*burstRate
+	now   func() time.Time
+}
+
+// Burst decorates the Handler `h` so that the rate of the records it handles
+// is tracked per key, as configured by the BurstConfig `conf`, to catch the
+// runaway loops and the spikes in volume early. When a key's rate exceeds its
+// baseline by the configured factor, a single `log_burst_detected` record is
+// handled (per window) before the record that crossed it, with the key, its
+// rate and baseline (in records per second) and the window as attributes.
+//
+// Records are never dropped; see Quota and Sample for that. Handlers derived
+// from a Burst Handler (with its With* methods) share its rates
+func Burst(h Handler, conf BurstConfig) Handler {
+	if h == nil {
+		return nil
+	}
+	if conf.Window <= 0 {
+		conf.Window = defaultBurstWindow
+	}
+	if conf.Factor <= 0 {
+		conf.Factor = defaultBurstFactor
+	}
+	if conf.MinRecords <= 0 {
+		conf.MinRecords = defaultBurstMinRecords
+	}
+	if conf.Level == nil {
+		conf.Level = level.Warn
+	}
+
+	return burstHandler{
+		h:    h,
+		conf: conf,
+		b: &bursts{
+			rates: make(map[string]*burstRate),
+			now:   time.Now,
+		},
+	}
+}
+
+// take accounts for a record of the key `key`, returning its rate in the
+// current window if it just turned into a burst
+func (b *bursts) take(key string, conf BurstConfig) (*burstRate, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if idle := burstIdleWindows * conf.Window; now.Sub(b.sweep) >= idle {
+		b.sweep = now
+		for k, rate := range b.rates {
+			if now.Sub(rate.start) >= idle {
+				delete(b.rates, k)
+			}
+		}
+	}
+
+	rate, ok := b.rates[key]
+	if !ok {
+		rate = &burstRate{start: now}
+		b.rates[key] = rate
+	}
+
+	if elapsed := now.Sub(rate.start); elapsed >= conf.Window {
+		windows := int(elapsed / conf.Window)
+		// the windows in between had no records
+		rate.baseline = (1-burstSmoothing)*rate.baseline + burstSmoothing*float64(rate.count)
+		rate.baseline *= math.Pow(1-burstSmoothing, float64(windows-1))
+		rate.start = rate.start.Add(time.Duration(windows) * conf.Window)
+		rate.count = 0
+		rate.flagged = false
+	}
+
+	rate.count++
+	if rate.flagged || rate.count < conf.MinRecords || float64(rate.count) <= conf.Factor*rate.baseline {
+		return nil, false
+	}
+
+	rate.flagged = true
+	snapshot := *rate
+	return &snapshot, true
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (b burstHandler) Enabled(level level.Level) bool {
+	return b.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (b burstHandler) Handle(r records.Record) error {
+	if !b.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	key := r.Message()
+	if b.conf.Key != "" {
+		if a := lookup(b.conf.Key, r.Attrs(), b.bound); a != nil {
+			key = fmt.Sprint(a.Value())
+		}
+	}
+
+	if rate, ok := b.b.take(key, b.conf); ok && b.h.Enabled(b.conf.Level) {
+		seconds := b.conf.Window.Seconds()
+		_ = b.h.Handle(records.New(r.Time(), b.conf.Level, burstMessage,
+			attr.String("burst_key", key),
+			attr.Int("burst_count", rate.count),
+			attr.Float("burst_rate", float64(rate.count)/seconds),
+			attr.Float("burst_baseline", rate.baseline/seconds),
+			attr.New("burst_window", b.conf.Window),
+		))
+	}
+
+	return b.h.Handle(r)
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (b burstHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, b.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (b burstHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, b.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (b burstHandler) With(attrs ...attr.Attr) Handler {
+	bound := make([]attr.Attr, 0, len(b.bound)+len(attrs))
+	return burstHandler{
+		h:     b.h.With(attrs...),
+		conf:  b.conf,
+		bound: append(append(bound, b.bound...), attrs...),
+		b:     b.b,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (b burstHandler) WithSource(addSource bool) Handler {
+	return burstHandler{
+		h:     b.h.WithSource(addSource),
+		conf:  b.conf,
+		bound: b.bound,
+		b:     b.b,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (b burstHandler) WithLevel(level level.Leveler) Handler {
+	return burstHandler{
+		h:     b.h.WithLevel(level),
+		conf:  b.conf,
+		bound: b.bound,
+		b:     b.b,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (b burstHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return burstHandler{
+		h:     b.h.WithReplaceFn(fn),
+		conf:  b.conf,
+		bound: b.bound,
+		b:     b.b,
+	}
+}
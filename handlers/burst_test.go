@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestBurst(t *testing.T) {
+	handle := func(h Handler, times int, msg string, attrs ...attr.Attr) {
+		for i := 0; i < times; i++ {
+			_ = h.Handle(records.New(time.Now(), level.Info, msg, attrs...))
+		}
+	}
+	markers := func(rs []records.Record) []records.Record {
+		var out []records.Record
+		for _, r := range rs {
+			if r.Message() == burstMessage {
+				out = append(out, r)
+			}
+		}
+		return out
+	}
+
+	t.Run("Spike", func(t *testing.T) {
+		th := newTestHandler()
+		h := Burst(th, BurstConfig{Window: time.Second, Factor: 5, MinRecords: 10})
+		b := h.(burstHandler)
+
+		now := time.Unix(1700000000, 0)
+		b.b.now = func() time.Time { return now }
+
+		// steady baseline of 4 records per window
+		for i := 0; i < 10; i++ {
+			handle(h, 4, "polling")
+			now = now.Add(time.Second)
+		}
+		if n := len(markers(th.Records())); n != 0 {
+			t.Errorf("output mismatch error: wanted %v markers ; got %v", 0, n)
+			return
+		}
+
+		handle(h, 100, "polling")
+
+		got := markers(th.Records())
+		if len(got) != 1 {
+			t.Errorf("output mismatch error: wanted %v markers ; got %v", 1, len(got))
+			return
+		}
+		if got[0].Level() != level.Warn {
+			t.Errorf("output mismatch error: wanted %v ; got %v", level.Warn, got[0].Level())
+		}
+		wants := map[string]any{"burst_key": "polling", "burst_window": time.Second}
+		for _, a := range got[0].Attrs() {
+			if w, ok := wants[a.Key()]; ok && a.Value() != w {
+				t.Errorf("output mismatch error for %s: wanted %v ; got %v", a.Key(), w, a.Value())
+			}
+		}
+		if n := len(th.Records()); n != 141 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 141, n)
+		}
+	})
+	t.Run("MinRecords", func(t *testing.T) {
+		th := newTestHandler()
+		h := Burst(th, BurstConfig{})
+
+		handle(h, 99, "rare")
+		if n := len(markers(th.Records())); n != 0 {
+			t.Errorf("output mismatch error: wanted %v markers ; got %v", 0, n)
+		}
+
+		handle(h, 1, "rare")
+		if n := len(markers(th.Records())); n != 1 {
+			t.Errorf("output mismatch error: wanted %v markers ; got %v", 1, n)
+		}
+	})
+	t.Run("ByKey", func(t *testing.T) {
+		th := newTestHandler()
+		h := Burst(th, BurstConfig{Key: "event", MinRecords: 5})
+
+		handle(h.With(attr.String("event", "retry")), 3, "a")
+		handle(h, 3, "b", attr.String("event", "retry"))
+
+		got := markers(th.Records())
+		if len(got) != 1 || got[0].Attrs()[0].Value() != "retry" {
+			t.Errorf("output mismatch error: wanted a marker for %v ; got %v", "retry", got)
+		}
+	})
+	t.Run("Idle", func(t *testing.T) {
+		th := newTestHandler()
+		h := Burst(th, BurstConfig{Window: time.Second, MinRecords: 1})
+		b := h.(burstHandler)
+
+		now := time.Unix(1700000000, 0)
+		b.b.now = func() time.Time { return now }
+
+		handle(h, 1, "once")
+		now = now.Add(time.Minute)
+		handle(h, 1, "other")
+
+		if _, ok := b.b.rates["once"]; ok {
+			t.Errorf("output mismatch error: wanted the idle key to be released")
+		}
+	})
+	t.Run("NilHandler", func(t *testing.T) {
+		if h := Burst(nil, BurstConfig{}); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+}
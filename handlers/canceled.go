@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// CanceledKey is the key of the attribute noting that a record was logged with
+// a Context that was already done, holding its error (like `context canceled`),
+// as added by the Loggers' context-aware methods
+const CanceledKey = "context_canceled"
+
+type canceledHandler struct {
+	h  Handler
+	to level.Level
+}
+
+// Canceled decorates the Handler `h` so that the records logged with a Context
+// that was already canceled (or past its deadline), like the ones of requests
+// whose client went away, are downgraded to the level `to`. Records already at
+// or below it are kept as-is, and the Handler's verbosity filter applies to the
+// downgraded level. If `to` is nil, the records are dropped instead.
+//
+// Records are identified by their CanceledKey attribute, which is kept
+func Canceled(h Handler, to level.Level) Handler {
+	if h == nil {
+		return nil
+	}
+	return canceledHandler{h: h, to: to}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (c canceledHandler) Enabled(level level.Level) bool {
+	return c.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (c canceledHandler) Handle(r records.Record) error {
+	if lookup(CanceledKey, r.Attrs(), nil) == nil {
+		return c.h.Handle(r)
+	}
+
+	switch {
+	case c.to == nil:
+		return nil
+	case r.Level() != nil && r.Level().Int() > c.to.Int():
+		return c.h.Handle(records.WithPC(records.New(r.Time(), c.to, r.Message(), r.Attrs()...), records.PC(r)))
+	default:
+		return c.h.Handle(r)
+	}
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (c canceledHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, c.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (c canceledHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, c.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (c canceledHandler) With(attrs ...attr.Attr) Handler {
+	return canceledHandler{
+		h:  c.h.With(attrs...),
+		to: c.to,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (c canceledHandler) WithSource(addSource bool) Handler {
+	return canceledHandler{
+		h:  c.h.WithSource(addSource),
+		to: c.to,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (c canceledHandler) WithLevel(level level.Leveler) Handler {
+	return canceledHandler{
+		h:  c.h.WithLevel(level),
+		to: c.to,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (c canceledHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return canceledHandler{
+		h:  c.h.WithReplaceFn(fn),
+		to: c.to,
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestCanceled(t *testing.T) {
+	canceled := attr.String(CanceledKey, "context canceled")
+
+	for _, test := range []struct {
+		name  string
+		to    level.Level
+		r     records.Record
+		wants level.Level
+	}{
+		{"Downgrade", level.Debug, records.New(time.Now(), level.Error, "write failed", canceled), level.Debug},
+		{"BelowTarget", level.Debug, records.New(time.Now(), level.Trace, "write failed", canceled), level.Trace},
+		{"NotCanceled", level.Debug, records.New(time.Now(), level.Error, "write failed"), level.Error},
+		{"Drop", nil, records.New(time.Now(), level.Error, "write failed", canceled), nil},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			th := newTestHandler()
+			if err := Canceled(th, test.to).Handle(test.r); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			out := th.Records()
+			if test.wants == nil {
+				if len(out) != 0 {
+					t.Errorf("output mismatch error: wanted no records ; got %d", len(out))
+				}
+				return
+			}
+			if len(out) != 1 || out[0].Level() != test.wants || out[0].AttrLen() != test.r.AttrLen() {
+				t.Errorf("output mismatch error: wanted a %v record ; got %v", test.wants, out)
+			}
+		})
+	}
+
+	t.Run("NilHandler", func(t *testing.T) {
+		if h := Canceled(nil, level.Debug); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// Capability is a set of features of a Handler's output, as reported by its
+// Capabilities method (see Capable)
+type Capability uint8
+
+const (
+	// CapGroups is set by the Handlers that encode the groups of attributes
+	// (with []attr.Attr values) as nested structures, or as dotted keys
+	CapGroups Capability = 1 << iota
+	// CapBinary is set by the Handlers that encode the []byte values without
+	// loss, like as base64 strings
+	CapBinary
+	// CapFlush is set by the Handlers and writers that buffer or queue the
+	// records, which must be shut down (see Shutdowner) to flush them
+	CapFlush
+	// CapRemote is set by the Handlers and writers that send the records to a
+	// remote sink, over the network
+	CapRemote
+)
+
+var capabilityNames = [...]string{"groups", "binary", "flush", "remote"}
+
+// Has returns a boolean on whether all capabilities in `c` are set
+func (caps Capability) Has(c Capability) bool {
+	return caps&c == c
+}
+
+// String implements fmt.Stringer, listing the capabilities' names separated by
+// a pipe, like `groups|binary`
+func (caps Capability) String() string {
+	names := make([]string, 0, len(capabilityNames))
+	for i, name := range capabilityNames {
+		if caps&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Capable is implemented by Handlers and writers that report the features of
+// their output, so that the unsupported ones are adapted (see Adapt) instead
+// of degrading the output silently. Writers only report CapFlush and
+// CapRemote, which the Handlers that wrap them add to their own
+type Capable interface {
+	// Capabilities returns the features supported by the output
+	Capabilities() Capability
+}
+
+// Capabilities returns the capabilities of the Handler or writer `v`, and a
+// boolean on whether it reports them (implementing Capable)
+func Capabilities(v any) (Capability, bool) {
+	if c, ok := v.(Capable); ok {
+		return c.Capabilities(), true
+	}
+	return 0, false
+}
+
+// EncoderCapabilities returns the capabilities `caps` of a Handler encoding
+// the records to the io.Writer `w`, merged with the ones the writer reports
+// (CapFlush and CapRemote)
+func EncoderCapabilities(caps Capability, w any) Capability {
+	writer, _ := Capabilities(w)
+	return caps | writer&(CapFlush|CapRemote)
+}
+
+// decoratedCapabilities returns the capabilities of the Handler `h` decorated
+// by another, assuming CapGroups and CapBinary if it does not report them, so
+// that the decorators of such Handlers are not adapted needlessly
+func decoratedCapabilities(h Handler) Capability {
+	if caps, ok := Capabilities(h); ok {
+		return caps
+	}
+	return CapGroups | CapBinary
+}
+
+type adaptHandler struct {
+	h    Handler
+	caps Capability
+}
+
+// Adapt decorates the Handler `h` so that the attributes it does not support,
+// as reported by its Capabilities method, are converted into the ones it
+// does: without CapGroups, groups are flattened into attributes with dotted
+// keys (like `http.status`), and without CapBinary, []byte values are encoded
+// as base64 strings.
+//
+// The Handler `h` is returned as-is if it supports both, or if it does not
+// report its capabilities
+func Adapt(h Handler) Handler {
+	if h == nil {
+		return nil
+	}
+
+	caps, ok := Capabilities(h)
+	if !ok || caps.Has(CapGroups|CapBinary) {
+		return h
+	}
+	return adaptHandler{
+		h:    h,
+		caps: caps,
+	}
+}
+
+// adapt returns the attributes `attrs` converted into the ones supported by
+// the Handler, or `attrs` itself if none needs to be
+func (a adaptHandler) adapt(attrs []attr.Attr) []attr.Attr {
+	if !a.needed(attrs) {
+		return attrs
+	}
+	return a.appendAdapted(make([]attr.Attr, 0, len(attrs)), "", attrs)
+}
+
+func (a adaptHandler) needed(attrs []attr.Attr) bool {
+	for _, at := range attrs {
+		if at == nil {
+			continue
+		}
+		switch v := at.Value().(type) {
+		case []attr.Attr:
+			if !a.caps.Has(CapGroups) || a.needed(v) {
+				return true
+			}
+		case []byte:
+			if !a.caps.Has(CapBinary) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a adaptHandler) appendAdapted(dst []attr.Attr, prefix string, attrs []attr.Attr) []attr.Attr {
+	for _, at := range attrs {
+		if at == nil {
+			continue
+		}
+		switch v := at.Value().(type) {
+		case []attr.Attr:
+			if !a.caps.Has(CapGroups) {
+				dst = a.appendAdapted(dst, prefix+at.Key()+".", v)
+				continue
+			}
+			dst = append(dst, attr.New(prefix+at.Key(), a.appendAdapted(make([]attr.Attr, 0, len(v)), "", v)))
+		case []byte:
+			if !a.caps.Has(CapBinary) {
+				dst = append(dst, attr.String(prefix+at.Key(), base64.StdEncoding.EncodeToString(v)))
+				continue
+			}
+			dst = append(dst, renamed(at, prefix))
+		default:
+			dst = append(dst, renamed(at, prefix))
+		}
+	}
+	return dst
+}
+
+// renamed returns the attribute `a` with its key prefixed with `prefix`
+func renamed(a attr.Attr, prefix string) attr.Attr {
+	if prefix == "" {
+		return a
+	}
+	return attr.New(prefix+a.Key(), a.Value())
+}
+
+// Capabilities implements Capable, returning the capabilities of the decorated
+// Handler, along with the ones that are adapted
+func (a adaptHandler) Capabilities() Capability {
+	return a.caps | CapGroups | CapBinary
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (a adaptHandler) Enabled(level level.Level) bool {
+	return a.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (a adaptHandler) Handle(r records.Record) error {
+	if !a.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	attrs := r.Attrs()
+	if !a.needed(attrs) {
+		return a.h.Handle(r)
+	}
+	adapted := records.New(r.Time(), r.Level(), r.Message(),
+		a.appendAdapted(make([]attr.Attr, 0, len(attrs)), "", attrs)...,
+	)
+	return a.h.Handle(records.WithPC(adapted, records.PC(r)))
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (a adaptHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, a.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (a adaptHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, a.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (a adaptHandler) With(attrs ...attr.Attr) Handler {
+	return adaptHandler{
+		h:    a.h.With(a.adapt(attrs)...),
+		caps: a.caps,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (a adaptHandler) WithSource(addSource bool) Handler {
+	return adaptHandler{
+		h:    a.h.WithSource(addSource),
+		caps: a.caps,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (a adaptHandler) WithLevel(level level.Leveler) Handler {
+	return adaptHandler{
+		h:    a.h.WithLevel(level),
+		caps: a.caps,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (a adaptHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return adaptHandler{
+		h:    a.h.WithReplaceFn(fn),
+		caps: a.caps,
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+type capsHandler struct {
+	testHandler
+	caps Capability
+}
+
+func (h capsHandler) Capabilities() Capability { return h.caps }
+
+func TestCapabilities(t *testing.T) {
+	t.Run("String", func(t *testing.T) {
+		caps := CapGroups | CapRemote
+		if caps.String() != "groups|remote" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "groups|remote", caps.String())
+		}
+		if !caps.Has(CapGroups) || caps.Has(CapGroups|CapBinary) {
+			t.Errorf("output mismatch error: wanted only %v ; got %v", caps, caps)
+		}
+	})
+	t.Run("Unreported", func(t *testing.T) {
+		if _, ok := Capabilities(newTestHandler()); ok {
+			t.Errorf("output mismatch error: wanted %v ; got %v", false, ok)
+		}
+		if _, ok := Adapt(newTestHandler()).(adaptHandler); ok {
+			t.Errorf("expected the Handler to be returned as-is")
+		}
+	})
+	t.Run("Encoders", func(t *testing.T) {
+		batch := Batch(io.Discard, time.Second, 0)
+		defer batch.Close()
+
+		caps, _ := Capabilities(NewAsync(CSV(batch, false, "user"), 1, DropNewest, 0))
+		if caps != CapGroups|CapFlush {
+			t.Errorf("output mismatch error: wanted %v ; got %v", CapGroups|CapFlush, caps)
+		}
+	})
+	t.Run("Adapt", func(t *testing.T) {
+		th := newTestHandler()
+		h := Adapt(capsHandler{testHandler: th})
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "event",
+			attr.New("http", []attr.Attr{
+				attr.Int("status", 200),
+				attr.New("tls", []attr.Attr{attr.String("version", "1.3")}),
+			}),
+			attr.New("payload", []byte("hi")),
+		))
+
+		rs := th.Records()
+		if len(rs) != 1 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 1, len(rs))
+			return
+		}
+		wants := map[string]any{"http.status": int64(200), "http.tls.version": "1.3", "payload": "aGk="}
+		got := rs[0].Attrs()
+		if len(got) != len(wants) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, got)
+			return
+		}
+		for _, a := range got {
+			if a.Value() != wants[a.Key()] {
+				t.Errorf("output mismatch error for %s: wanted %v ; got %v", a.Key(), wants[a.Key()], a.Value())
+			}
+		}
+	})
+	t.Run("AdaptSupported", func(t *testing.T) {
+		h := capsHandler{testHandler: newTestHandler(), caps: CapGroups | CapBinary}
+		if _, ok := Adapt(h).(adaptHandler); ok {
+			t.Errorf("expected the Handler to be returned as-is")
+		}
+	})
+	t.Run("Multi", func(t *testing.T) {
+		flat, full := newTestHandler(), newTestHandler()
+		h := Multi(
+			capsHandler{testHandler: flat, caps: CapBinary | CapRemote},
+			capsHandler{testHandler: full, caps: CapGroups | CapBinary},
+		)
+
+		caps, _ := Capabilities(h)
+		if caps != CapGroups|CapBinary|CapRemote {
+			t.Errorf("output mismatch error: wanted %v ; got %v", CapGroups|CapBinary|CapRemote, caps)
+		}
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "event",
+			attr.New("http", []attr.Attr{attr.Int("status", 200)}),
+		))
+
+		if a := flat.Records()[0].Attrs()[0]; a.Key() != "http.status" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "http.status", a.Key())
+		}
+		if a := full.Records()[0].Attrs()[0]; a.Key() != "http" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "http", a.Key())
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+)
+
+// Catalog localizes the messages of the records written by the text-family
+// handlers (texth and Console), for user-facing output like CLIs and desktop
+// apps. It returns the message for the key `key`, which is a record's message,
+// given the record's attributes `attrs`; or an empty string to keep the key.
+//
+// Catalogs only change how records are rendered, so structured sinks (like
+// JSON) keep logging the canonical keys
+type Catalog func(key string, attrs []attr.Attr) string
+
+// Messages creates a Catalog from the localized messages `messages`, keyed by
+// the records' messages. The localized messages may reference the records'
+// attributes with `{key}` placeholders, replaced with their values:
+//
+//	catalog := handlers.Messages(map[string]string{
+//		"file downloaded": "Datei {name} heruntergeladen",
+//	})
+func Messages(messages map[string]string) Catalog {
+	return func(key string, attrs []attr.Attr) string {
+		msg, ok := messages[key]
+		if !ok {
+			return ""
+		}
+		return Expand(msg, attrs, nil)
+	}
+}
+
+// Message returns the localized message for the key `key`, or the key itself
+// if the Catalog is nil or has no message for it
+func (c Catalog) Message(key string, attrs []attr.Attr) string {
+	if c == nil {
+		return key
+	}
+	if msg := c(key, attrs); msg != "" {
+		return msg
+	}
+	return key
+}
+
+// Expand replaces the `{key}` placeholders in the template `tmpl` with the
+// value of the matching attribute in `attrs`, or in the bound attributes
+// `bound`, like in the messages of a Catalog or the routing keys and topics of
+// the message broker handlers. Placeholders without a matching attribute are
+// kept as-is
+func Expand(tmpl string, attrs, bound []attr.Attr) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	for {
+		start := strings.IndexByte(tmpl, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(tmpl[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		b.WriteString(tmpl[:start])
+		if a := lookup(tmpl[start+1:end], attrs, bound); a != nil {
+			b.WriteValue(a.Value())
+		} else {
+			b.WriteString(tmpl[start : end+1])
+		}
+		tmpl = tmpl[end+1:]
+	}
+	b.WriteString(tmpl)
+	return b.String()
+}
+
+// WithCatalog creates a copy of the Console Handler `h`, rendering the records'
+// messages as localized by the Catalog `catalog`. Returns nil if the Handler is
+// not a Console handler
+func WithCatalog(h Handler, catalog Catalog) Handler {
+	consoleH, ok := (h).(consoleHandler)
+	if !ok {
+		return nil
+	}
+
+	consoleH.catalog = catalog
+	return consoleH
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestCatalog(t *testing.T) {
+	catalog := Messages(map[string]string{
+		"file downloaded": "Datei {name} heruntergeladen ({size} Bytes)",
+		"no placeholders": "Keine Platzhalter",
+	})
+
+	for _, test := range []struct {
+		name    string
+		catalog Catalog
+		key     string
+		attrs   []attr.Attr
+		wants   string
+	}{
+		{"Placeholders", catalog, "file downloaded", []attr.Attr{attr.String("name", "a.txt")}, "Datei a.txt heruntergeladen ({size} Bytes)"},
+		{"Plain", catalog, "no placeholders", nil, "Keine Platzhalter"},
+		{"Missing", catalog, "file deleted", nil, "file deleted"},
+		{"Nil", nil, "file deleted", nil, "file deleted"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.catalog.Message(test.key, test.attrs); got != test.wants {
+				t.Errorf("output mismatch error: wanted %q ; got %q", test.wants, got)
+			}
+		})
+	}
+
+	t.Run("Console", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithCatalog(Console(b, Theme{}), catalog).With(attr.String("name", "b.txt"))
+
+		_ = h.Handle(records.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), level.Info, "file downloaded", attr.Int("size", 10)))
+
+		wants := "00:00:00.000 INFO   Datei b.txt heruntergeladen (10 Bytes) size=10 name=b.txt\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("NotConsole", func(t *testing.T) {
+		if h := WithCatalog(newTestHandler(), catalog); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+}
+
+func TestExpand(t *testing.T) {
+	attrs := []attr.Attr{attr.String("device", "sensor-1"), attr.Int("zone", 3)}
+	bound := []attr.Attr{attr.String("fleet", "eu"), attr.String("device", "bound")}
+
+	for _, test := range []struct {
+		tmpl  string
+		wants string
+	}{
+		{"logs/{fleet}/{device}", "logs/eu/sensor-1"},
+		{"zone.{zone}.{missing}", "zone.3.{missing}"},
+		{"unclosed.{device", "unclosed.{device"},
+		{"plain", "plain"},
+	} {
+		if got := Expand(test.tmpl, attrs, bound); got != test.wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", test.wants, got)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+//go:build !logx_tiny
+
+// Package clickhouseh provides a batching handler that inserts records into a
+// ClickHouse table, for high-volume analytical log storage.
+//
+// Records are sent in batches to the ClickHouse HTTP interface, as rows in the
+// JSONEachRow format, with the fields:
+//   - `timestamp`: the record's timestamp, as `2006-01-02 15:04:05.000000000`
+//     in UTC (for a DateTime64(9) column)
+//   - `level`: the record's level name
+//   - `message`: the record's message
+//   - `source`: the record's source reference, if enabled with WithSource
+//   - `attrs`: the record's attributes, as a JSON object in a string
+//   - one field per column in Config.Columns, with the value of the attribute
+//     mapped to it
+//
+// Fields without a matching column in the table are skipped, so a minimal
+// table only needs the first three:
+//
+//	CREATE TABLE logs (
+//		timestamp DateTime64(9),
+//		level     LowCardinality(String),
+//		message   String,
+//		attrs     String,
+//		user_id   UInt64
+//	) ENGINE = MergeTree ORDER BY timestamp
+//
+// To use the native protocol instead, Config.Writer takes the batches of rows
+// in place of the HTTP interface, to be inserted with a native client
+package clickhouseh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	timeFormat           = "2006-01-02 15:04:05.000000000"
+	defaultBatchInterval = time.Second
+	defaultBatchSize     = 1 << 20 // 1 MiB
+)
+
+// ErrNoTable is raised when creating a handler without a table name
+var ErrNoTable error = errors.New("no table provided")
+
+// Config describes the ClickHouse server and table that records are inserted
+// into, and how their attributes are mapped to the table's columns
+type Config struct {
+	// Endpoint is the URL of the ClickHouse HTTP interface, like
+	// `http://localhost:8123`
+	Endpoint string
+	// Table is the name of the table, optionally qualified with its database,
+	// like `observability.logs`
+	Table string
+	// Columns maps the names of the table's columns to the keys of the
+	// attributes that fill them (including the ones bound to the handler,
+	// with With). Keys within groups are joined with dots, like `http.status`
+	Columns map[string]string
+	// User and Password authenticate the requests to the server
+	User     string
+	Password string
+	// HTTP configures the requests to the server, like its TLS settings and
+	// compression
+	HTTP handlers.HTTPConfig
+	// BatchInterval is how often batches are sent, one second by default
+	BatchInterval time.Duration
+	// BatchSize is the size (in bytes) from which a batch is sent before its
+	// interval, 1 MiB by default
+	BatchSize int
+	// Writer, if set, receives the batches of JSONEachRow rows instead of the
+	// HTTP interface, like an adapter over a native protocol client. The
+	// Endpoint, User, Password and HTTP settings are then ignored
+	Writer io.Writer
+}
+
+// column is a table column filled with the value of an attribute
+type column struct {
+	name string
+	path []string
+}
+
+type clickhouseHandler struct {
+	w         io.Writer
+	columns   []column
+	addSource bool
+	levelRef  level.Leveler
+	replFn    func(a attr.Attr) attr.Attr
+	attrs     []attr.Attr
+}
+
+// New creates a handler that inserts records into the ClickHouse table
+// described by the Config `conf`, in batches. Failed batches are retried on the
+// following intervals (see handlers.AckedBatch), and any buffered records are
+// sent on Shutdown
+func New(conf Config) (handlers.Handler, error) {
+	if conf.Table == "" {
+		return nil, ErrNoTable
+	}
+
+	w := conf.Writer
+	if w == nil {
+		var err error
+		if w, err = httpWriter(conf); err != nil {
+			return nil, err
+		}
+	}
+
+	interval := conf.BatchInterval
+	if interval <= 0 {
+		interval = defaultBatchInterval
+	}
+	size := conf.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	columns := make([]column, 0, len(conf.Columns))
+	for name, key := range conf.Columns {
+		columns = append(columns, column{name: name, path: strings.Split(key, ".")})
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].name < columns[j].name
+	})
+
+	return clickhouseHandler{
+		w:       handlers.AckedBatch(w, interval, size),
+		columns: columns,
+	}, nil
+}
+
+// httpWriter creates the handlers.HTTPWriter sending the batches to the
+// server's HTTP interface, in INSERT queries
+func httpWriter(conf Config) (io.Writer, error) {
+	endpoint, err := url.Parse(conf.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	query := endpoint.Query()
+	query.Set("query", "INSERT INTO "+conf.Table+" FORMAT JSONEachRow")
+	query.Set("input_format_skip_unknown_fields", "1")
+	endpoint.RawQuery = query.Encode()
+
+	httpConf := conf.HTTP
+	if conf.User != "" {
+		httpConf.Header = httpConf.Header.Clone()
+		if httpConf.Header == nil {
+			httpConf.Header = http.Header{}
+		}
+		httpConf.Header.Set("X-ClickHouse-User", conf.User)
+		httpConf.Header.Set("X-ClickHouse-Key", conf.Password)
+	}
+
+	return handlers.HTTPWriter(endpoint.String(), httpConf)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h clickhouseHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	lv := r.Level()
+	if lv == nil {
+		lv = level.Info
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	b.WriteString(`{"timestamp":"`)
+	b.WriteString(r.Time().UTC().Format(timeFormat))
+	b.WriteString(`","level":`)
+	b.WriteJSON(lv.String())
+	b.WriteString(`,"message":`)
+	b.WriteJSON(r.Message())
+
+	if h.addSource {
+		if f, ok := handlers.Source(r); ok {
+			b.WriteString(`,"source":`)
+			b.WriteJSON(f.File + ":" + strconv.Itoa(f.Line))
+		}
+	}
+
+	b.WriteString(`,"attrs":`)
+	b.WriteJSON(handlers.AttrsJSON(h.replFn, r.Attrs(), h.attrs))
+
+	for _, col := range h.columns {
+		a := h.find(col.path, r.Attrs())
+		if a == nil {
+			continue
+		}
+		b.WriteByte(',')
+		b.WriteJSON(col.name)
+		b.WriteByte(':')
+		writeValue(b, h.replFn, a.Value())
+	}
+	b.WriteString("}\n")
+
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+// find returns the attribute in the path `path` (a key, or the keys of nested
+// groups) in the record's attributes `attrs`, or in the handler's bound
+// attributes
+func (h clickhouseHandler) find(path []string, attrs []attr.Attr) attr.Attr {
+	for _, list := range [2][]attr.Attr{attrs, h.attrs} {
+		if a := findPath(h.replFn, path, list); a != nil {
+			return a
+		}
+	}
+	return nil
+}
+
+func findPath(replFn func(attr.Attr) attr.Attr, path []string, attrs []attr.Attr) attr.Attr {
+	for i := len(attrs) - 1; i >= 0; i-- {
+		a := attrs[i]
+		if a == nil || a.Key() != path[0] {
+			continue
+		}
+		if replFn != nil {
+			if a = replFn(a); a == nil {
+				return nil
+			}
+		}
+		if len(path) == 1 {
+			return a
+		}
+		group, ok := a.Value().([]attr.Attr)
+		if !ok {
+			return nil
+		}
+		return findPath(replFn, path[1:], group)
+	}
+	return nil
+}
+
+// writeValue writes the attribute value `v` as a JSON value for its column
+func writeValue(b *buffer.Buffer, replFn func(attr.Attr) attr.Attr, v any) {
+	switch v := v.(type) {
+	case []attr.Attr:
+		b.WriteJSON(handlers.AttrsJSON(replFn, v))
+	case error:
+		b.WriteJSON(v.Error())
+	case time.Time:
+		b.WriteJSON(v.UTC().Format(timeFormat))
+	case time.Duration:
+		b.WriteString(strconv.FormatInt(int64(v), 10))
+	default:
+		b.WriteJSON(v)
+	}
+}
+
+// Capabilities implements handlers.Capable, returning CapGroups and CapBinary,
+// along with CapFlush and CapRemote as records are sent in batches
+func (h clickhouseHandler) Capabilities() handlers.Capability {
+	return handlers.CapGroups | handlers.CapBinary | handlers.CapFlush | handlers.CapRemote
+}
+
+// Ping implements handlers.Pinger, checking the health of the batch writer
+func (h clickhouseHandler) Ping(ctx context.Context) error {
+	return handlers.Ping(ctx, h.w)
+}
+
+// Shutdown implements handlers.Shutdowner, sending any buffered records
+func (h clickhouseHandler) Shutdown(ctx context.Context) error {
+	return handlers.Shutdown(ctx, h.w)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h clickhouseHandler) With(attrs ...attr.Attr) handlers.Handler {
+	return clickhouseHandler{
+		w:         h.w,
+		columns:   h.columns,
+		addSource: h.addSource,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     attrs,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h clickhouseHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h clickhouseHandler) WithSource(addSource bool) handlers.Handler {
+	return clickhouseHandler{
+		w:         h.w,
+		columns:   h.columns,
+		addSource: addSource,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h clickhouseHandler) WithLevel(level level.Leveler) handlers.Handler {
+	return clickhouseHandler{
+		w:         h.w,
+		columns:   h.columns,
+		addSource: h.addSource,
+		levelRef:  level,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h clickhouseHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) handlers.Handler {
+	return clickhouseHandler{
+		w:         h.w,
+		columns:   h.columns,
+		addSource: h.addSource,
+		levelRef:  h.levelRef,
+		replFn:    fn,
+		attrs:     h.attrs,
+	}
+}
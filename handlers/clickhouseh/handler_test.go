@@ -0,0 +1,115 @@
+//go:build !logx_tiny
+
+package clickhouseh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 600000000, time.UTC)
+
+func TestHandle(t *testing.T) {
+	b := &bytes.Buffer{}
+	h, err := New(Config{
+		Table:   "logs",
+		Columns: map[string]string{"user_id": "user_id", "status": "http.status", "missing": "missing"},
+		Writer:  b,
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	h = h.With(attr.Int("user_id", 7)).WithLevel(level.Info)
+
+	_ = h.Handle(records.New(testTime, level.Debug, "filtered"))
+	_ = h.Handle(records.New(testTime, level.Warn, "slow request",
+		attr.New("http", []attr.Attr{attr.Int("status", 504)}),
+		attr.New("latency", 1500*time.Millisecond),
+	))
+	if err := handlers.Shutdown(context.Background(), h); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	wants := `{"timestamp":"2024-01-02 03:04:05.600000000","level":"warn","message":"slow request",` +
+		`"attrs":"{\"http\":{\"status\":504},\"latency\":1500000000,\"user_id\":7}","status":504,"user_id":7}` + "\n"
+	if b.String() != wants {
+		t.Errorf("output mismatch error: wanted %s ; got %s", wants, b.String())
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		queries []string
+		bodies  []string
+		users   []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		defer mu.Unlock()
+		queries = append(queries, r.URL.Query().Get("query"))
+		bodies = append(bodies, string(body))
+		users = append(users, r.Header.Get("X-ClickHouse-User"))
+	}))
+	defer srv.Close()
+
+	h, err := New(Config{
+		Endpoint:      srv.URL + "/?database=observability",
+		Table:         "logs",
+		User:          "writer",
+		Password:      "secret",
+		BatchInterval: time.Hour,
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	for _, msg := range []string{"first", "second"} {
+		if err := h.Handle(records.New(testTime, level.Info, msg)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+	}
+	if err := handlers.Shutdown(context.Background(), h); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(bodies) != 1 {
+		t.Errorf("output mismatch error: wanted a single batch ; got %d", len(bodies))
+		return
+	}
+	if queries[0] != "INSERT INTO logs FORMAT JSONEachRow" || users[0] != "writer" {
+		t.Errorf("output mismatch error: got query %q with user %q", queries[0], users[0])
+	}
+	if strings.Count(bodies[0], "\n") != 2 || !strings.Contains(bodies[0], `"message":"second"`) {
+		t.Errorf("output mismatch error: wanted both rows in the batch ; got %q", bodies[0])
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(Config{Endpoint: "http://localhost:8123"}); !errors.Is(err, ErrNoTable) {
+		t.Errorf("unexpected error: wanted %v ; got %v", ErrNoTable, err)
+	}
+}
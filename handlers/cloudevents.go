@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	ceSpecVersion = "1.0"
+	ceContentType = "application/json"
+
+	defaultCloudEventsSource = "logx"
+	defaultCloudEventsType   = "com.github.zalgonoise.logx.record"
+)
+
+// CloudEventsConfig sets how the context attributes of the CloudEvents written
+// by a CloudEvents handler are filled in. The attribute keys are looked up in
+// the records' attributes and in the ones bound to the handler (with With);
+// the attributes that are found are left out of the events' data
+type CloudEventsConfig struct {
+	// Source is the events' `source`, a URI-reference identifying the service
+	// (like `/services/checkout`), `logx` by default
+	Source string
+	// SourceKey is the key of the attribute whose value overrides Source
+	SourceKey string
+	// Type is the events' `type`, `com.github.zalgonoise.logx.record` by
+	// default
+	Type string
+	// TypeKey is the key of the attribute whose value overrides Type, like an
+	// event's name (e.g. `com.example.order.created`)
+	TypeKey string
+	// SubjectKey is the key of the attribute whose value is the events'
+	// `subject`, like an order ID. If empty, or absent from a record, its event
+	// has no subject
+	SubjectKey string
+	// IDKey is the key of the attribute whose value is the events' `id`, for
+	// the consumers to deduplicate them. If empty, or absent from a record, its
+	// event is given a random ID
+	IDKey string
+}
+
+type cloudEventsHandler struct {
+	w         io.Writer
+	conf      CloudEventsConfig
+	addSource bool
+	source    SourceConfig
+	levelRef  level.Leveler
+	replFn    func(a attr.Attr) attr.Attr
+	attrs     []attr.Attr
+}
+
+// CloudEvents creates a handler that writes records to the io.Writer `w` as
+// CloudEvents (version 1.0), in the JSON structured content mode, one per line,
+// so that they can be published onto the event buses that take them (like
+// Knative Eventing or Amazon EventBridge). The events' context attributes are
+// set as configured in the CloudEventsConfig `conf`, with the record's
+// timestamp as their `time`; and their data holds the record like the jsonh
+// handler encodes it, with its `message`, `level` and `data` fields:
+//
+//	{"specversion":"1.0","id":"...","source":"/services/checkout",
+//	"type":"com.github.zalgonoise.logx.record","time":"...",
+//	"datacontenttype":"application/json","data":{"message":"order placed",
+//	"level":"info","data":{"items":3}}}
+//
+// The `trace_id` and `span_id` attributes (as added by logx.TraceParent, for
+// instance) are also written as the `traceparent` extension attribute, from the
+// Distributed Tracing extension. With WithSource, the caller's file, line and
+// function are added to the data, as a `source` object
+func CloudEvents(w io.Writer, conf CloudEventsConfig) Handler {
+	if w == nil {
+		return nil
+	}
+	if conf.Source == "" {
+		conf.Source = defaultCloudEventsSource
+	}
+	if conf.Type == "" {
+		conf.Type = defaultCloudEventsType
+	}
+
+	return cloudEventsHandler{
+		w:    w,
+		conf: conf,
+	}
+}
+
+// value returns the value of the attribute with key `key` as a string, or
+// `fallback` if there is none
+func (h cloudEventsHandler) value(key string, attrs []attr.Attr, fallback string) string {
+	if key == "" {
+		return fallback
+	}
+	if a := lookup(key, attrs, h.attrs); a != nil {
+		return fmt.Sprint(a.Value())
+	}
+	return fallback
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h cloudEventsHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	attrs := r.Attrs()
+
+	id := h.value(h.conf.IDKey, attrs, "")
+	if id == "" {
+		id = newIdempotencyKey()
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	b.WriteString(`{"specversion":"` + ceSpecVersion + `","id":`)
+	b.WriteJSON(id)
+	b.WriteString(`,"source":`)
+	b.WriteJSON(h.value(h.conf.SourceKey, attrs, h.conf.Source))
+	b.WriteString(`,"type":`)
+	b.WriteJSON(h.value(h.conf.TypeKey, attrs, h.conf.Type))
+	if subject := h.value(h.conf.SubjectKey, attrs, ""); subject != "" {
+		b.WriteString(`,"subject":`)
+		b.WriteJSON(subject)
+	}
+	b.WriteString(`,"time":`)
+	b.WriteJSON(r.Time().UTC().Format(time.RFC3339Nano))
+	b.WriteString(`,"datacontenttype":"` + ceContentType + `"`)
+
+	if traceID := lookup(traceIDKey, attrs, h.attrs); traceID != nil {
+		if spanID := lookup(spanIDKey, attrs, h.attrs); spanID != nil {
+			flags := "00"
+			if a := lookup(sampledKey, attrs, h.attrs); a != nil && a.Value() == true {
+				flags = "01"
+			}
+			b.WriteString(`,"traceparent":`)
+			b.WriteJSON(fmt.Sprintf("00-%v-%v-%s", traceID.Value(), spanID.Value(), flags))
+		}
+	}
+
+	b.WriteString(`,"data":{"message":`)
+	b.WriteJSON(r.Message())
+	b.WriteString(`,"level":`)
+	b.WriteJSON(r.Level().String())
+
+	if h.addSource {
+		if f, ok := Source(r); ok {
+			b.WriteString(`,"source":{"file":`)
+			b.WriteJSON(h.source.file(f, keepPath))
+			b.WriteString(`,"line":`)
+			b.WriteString(strconv.Itoa(f.Line))
+			b.WriteString(`,"function":`)
+			b.WriteJSON(f.Function)
+			b.WriteByte('}')
+		}
+	}
+
+	skip := []string{h.conf.SourceKey, h.conf.TypeKey, h.conf.SubjectKey, h.conf.IDKey}
+	if len(attrs) > 0 || len(h.attrs) > 0 {
+		b.WriteString(`,"data":{`)
+		start := b.Len()
+		writeJSONFields(b, false, h.replFn, skip, attrs)
+		writeJSONFields(b, b.Len() > start, h.replFn, skip, h.attrs)
+		b.WriteByte('}')
+	}
+	b.WriteString("}}\n")
+
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+// Capabilities implements Capable, returning CapGroups and CapBinary along with
+// the capabilities of the handler's io.Writer
+func (h cloudEventsHandler) Capabilities() Capability {
+	return EncoderCapabilities(CapGroups|CapBinary, h.w)
+}
+
+// Ping implements Pinger, checking the health of the handler's io.Writer
+func (h cloudEventsHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, h.w)
+}
+
+// Shutdown implements Shutdowner, shutting down the handler's io.Writer
+func (h cloudEventsHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, h.w)
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h cloudEventsHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h cloudEventsHandler) With(attrs ...attr.Attr) Handler {
+	return cloudEventsHandler{
+		w:         h.w,
+		conf:      h.conf,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     attrs,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h cloudEventsHandler) WithSource(addSource bool) Handler {
+	return cloudEventsHandler{
+		w:         h.w,
+		conf:      h.conf,
+		addSource: addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// withSourceConfig implements sourceConfigurer
+func (h cloudEventsHandler) withSourceConfig(conf SourceConfig) Handler {
+	h.source = conf
+	return h
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h cloudEventsHandler) WithLevel(level level.Leveler) Handler {
+	return cloudEventsHandler{
+		w:         h.w,
+		conf:      h.conf,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  level,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h cloudEventsHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return cloudEventsHandler{
+		w:         h.w,
+		conf:      h.conf,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    fn,
+		attrs:     h.attrs,
+	}
+}
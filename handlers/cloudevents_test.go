@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestCloudEvents(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := CloudEvents(b, CloudEventsConfig{
+			Source:     "/services/checkout",
+			TypeKey:    "event",
+			SubjectKey: "order_id",
+			IDKey:      "event_id",
+		}).With(attr.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"))
+
+		err := h.Handle(records.New(ts, level.Info, "order placed",
+			attr.String("event", "com.example.order.created"),
+			attr.String("event_id", "evt-1"),
+			attr.String("order_id", "o-42"),
+			attr.String("span_id", "00f067aa0ba902b7"),
+			attr.New("sampled", true),
+			attr.Int("items", 3),
+		))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		wants := `{"specversion":"1.0","id":"evt-1","source":"/services/checkout",` +
+			`"type":"com.example.order.created","subject":"o-42","time":"2024-01-02T03:04:05Z",` +
+			`"datacontenttype":"application/json",` +
+			`"traceparent":"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",` +
+			`"data":{"message":"order placed","level":"info","data":{"span_id":"00f067aa0ba902b7",` +
+			`"sampled":true,"items":3,"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"}}}` + "\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, b.String())
+		}
+	})
+	t.Run("Defaults", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		_ = CloudEvents(b, CloudEventsConfig{}).Handle(records.New(ts, level.Warn, "disk full"))
+
+		var event map[string]any
+		if err := json.Unmarshal(b.Bytes(), &event); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		for key, wants := range map[string]any{
+			"specversion": "1.0",
+			"source":      defaultCloudEventsSource,
+			"type":        defaultCloudEventsType,
+			"data":        map[string]any{"message": "disk full", "level": "warn"},
+		} {
+			if got, _ := json.Marshal(event[key]); string(got) != mustMarshal(wants) {
+				t.Errorf("output mismatch error for %s: wanted %v ; got %v", key, wants, event[key])
+			}
+		}
+		if id, _ := event["id"].(string); len(id) != 32 {
+			t.Errorf("output mismatch error: wanted a random ID ; got %v", event["id"])
+		}
+		if _, ok := event["subject"]; ok {
+			t.Errorf("unexpected subject in event: %v", event)
+		}
+	})
+}
+
+func mustMarshal(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
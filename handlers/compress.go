@@ -0,0 +1,97 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// defaultCompressionThreshold is the payload size from which payloads are
+// compressed, when no threshold is set
+const defaultCompressionThreshold = 1 << 10 // 1 KiB
+
+// Compression is a payload compression algorithm, used by the writers that
+// ship batches of records to remote sinks
+type Compression int
+
+const (
+	// CompressNone sends payloads uncompressed
+	CompressNone Compression = iota
+	// CompressGzip compresses payloads with gzip
+	CompressGzip
+	// CompressZstd compresses payloads with Zstandard, which is faster and
+	// compresses further than gzip, but is not supported by every sink
+	CompressZstd
+)
+
+var (
+	gzipPool = sync.Pool{
+		New: func() any {
+			return gzip.NewWriter(nil)
+		},
+	}
+
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+)
+
+// String returns the name of the Compression, as used in the Content-Encoding
+// HTTP header
+func (c Compression) String() string {
+	switch c {
+	case CompressGzip:
+		return "gzip"
+	case CompressZstd:
+		return "zstd"
+	default:
+		return ""
+	}
+}
+
+// compress returns the payload `p` compressed with the Compression `c`, if it
+// is at least `threshold` bytes long (or 1 KiB, if not greater than zero).
+// Otherwise, `p` is returned as-is, with a false boolean. Payloads compressed
+// with CompressZstd use the encoder `dict` if set, as configured with a
+// dictionary
+func (c Compression) compress(p []byte, threshold int, dict *zstd.Encoder) ([]byte, bool, error) {
+	if threshold <= 0 {
+		threshold = defaultCompressionThreshold
+	}
+	if len(p) < threshold {
+		return p, false, nil
+	}
+
+	switch c {
+	case CompressGzip:
+		buf := &bytes.Buffer{}
+		buf.Grow(len(p) / 2)
+
+		gz := gzipPool.Get().(*gzip.Writer)
+		defer gzipPool.Put(gz)
+		gz.Reset(buf)
+
+		if _, err := gz.Write(p); err != nil {
+			return nil, false, err
+		}
+		if err := gz.Close(); err != nil {
+			return nil, false, err
+		}
+		return buf.Bytes(), true, nil
+	case CompressZstd:
+		if dict != nil {
+			return dict.EncodeAll(p, make([]byte, 0, len(p)/2)), true, nil
+		}
+		zstdOnce.Do(func() {
+			// with a nil writer, the encoder is only used with EncodeAll, which is
+			// safe for concurrent use; it cannot fail with the default options
+			zstdEncoder, _ = zstd.NewWriter(nil)
+		})
+		return zstdEncoder.EncodeAll(p, make([]byte, 0, len(p)/2)), true, nil
+	default:
+		return p, false, nil
+	}
+}
@@ -0,0 +1,182 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	defaultDictSize    = 64 << 10 // 64 KiB
+	defaultDictSamples = 1000
+
+	// minDictSamples is the number of samples below which a dictionary is not
+	// trained, as it would only fit those records
+	minDictSamples = 8
+)
+
+// ErrNotEnoughSamples is returned when training a Zstandard dictionary from
+// too few samples
+var ErrNotEnoughSamples error = errors.New("not enough samples to train a dictionary")
+
+// ZstdDictConfig configures the training of a Zstandard dictionary
+type ZstdDictConfig struct {
+	// Size is the maximum size of the dictionary, in bytes, defaulting to
+	// 64 KiB
+	Size int
+	// ID is the dictionary's ID, which is written in the frames compressed with
+	// it, for the decoders to pick the right dictionary. If zero, a random ID
+	// is set
+	ID uint32
+}
+
+// TrainZstdDict trains a Zstandard dictionary from the encoded records
+// `samples` (like the lines written by a jsonh handler), as configured in the
+// ZstdDictConfig `conf`. The dictionary holds the byte sequences that repeat
+// across the samples, like the attributes' keys and the recurring messages, so
+// that small payloads of similar records compress several times further with
+// it (see HTTPConfig.ZstdDict).
+//
+// The samples should be representative of the records that will be
+// compressed, and number at least a few hundred; ErrNotEnoughSamples is
+// returned if there are less than eight
+func TrainZstdDict(samples [][]byte, conf ZstdDictConfig) ([]byte, error) {
+	if len(samples) < minDictSamples {
+		return nil, ErrNotEnoughSamples
+	}
+	if conf.Size <= 0 {
+		conf.Size = defaultDictSize
+	}
+
+	return dict.BuildZstdDict(samples, dict.Options{
+		MaxDictSize: conf.Size,
+		HashBytes:   6,
+		ZstdDictID:  conf.ID,
+	})
+}
+
+// newZstdDictEncoder returns a Zstandard encoder using the dictionary `d`, or
+// nil if `d` is empty
+func newZstdDictEncoder(d []byte) (*zstd.Encoder, error) {
+	if len(d) == 0 {
+		return nil, nil
+	}
+	// with a nil writer, the encoder is only used with EncodeAll
+	return zstd.NewWriter(nil, zstd.WithEncoderDict(d))
+}
+
+// DictSampler is an io.Writer that samples the writes to another, as the
+// records to train a Zstandard dictionary from (see TrainZstdDict). It keeps a
+// uniform sample of all writes (with reservoir sampling), so it can be left in
+// place for a while to capture the variety of the records:
+//
+//	sampler := handlers.NewDictSampler(os.Stdout, 0)
+//	logger := logx.New(jsonh.New(sampler))
+//	// (...)
+//	zdict, err := sampler.Train(handlers.ZstdDictConfig{})
+//
+// Each write is expected to hold a single record, as written by the encoding
+// handlers; so the sampler must be placed before any batching writer
+type DictSampler struct {
+	mu      sync.Mutex
+	w       io.Writer
+	size    int
+	seen    int
+	samples [][]byte
+	rand    *rand.Rand
+}
+
+// NewDictSampler creates a DictSampler writing to the io.Writer `w`, keeping up
+// to `size` samples (or 1000, if not greater than zero). If `w` is nil, the
+// writes are only sampled
+func NewDictSampler(w io.Writer, size int) *DictSampler {
+	if w == nil {
+		w = io.Discard
+	}
+	if size <= 0 {
+		size = defaultDictSamples
+	}
+
+	return &DictSampler{
+		w:       w,
+		size:    size,
+		samples: make([][]byte, 0, size),
+		rand:    rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// sample keeps a copy of `p` as a sample, replacing a random one once the
+// sampler is full
+func (s *DictSampler) sample(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seen++
+	if len(s.samples) < s.size {
+		s.samples = append(s.samples, append([]byte(nil), p...))
+		return
+	}
+	if i := s.rand.Intn(s.seen); i < s.size {
+		s.samples[i] = append(s.samples[i][:0], p...)
+	}
+}
+
+// Write implements io.Writer, sampling `p` before writing it to the
+// underlying writer
+func (s *DictSampler) Write(p []byte) (int, error) {
+	s.sample(p)
+	return s.w.Write(p)
+}
+
+// WriteContext implements ContextWriter, sampling `p` before writing it to the
+// underlying writer with WriteContext
+func (s *DictSampler) WriteContext(ctx context.Context, p []byte) (int, error) {
+	s.sample(p)
+	return WriteContext(ctx, s.w, p)
+}
+
+// Samples returns a copy of the samples taken so far
+func (s *DictSampler) Samples() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	samples := make([][]byte, len(s.samples))
+	for i := range s.samples {
+		samples[i] = append([]byte(nil), s.samples[i]...)
+	}
+	return samples
+}
+
+// Train trains a Zstandard dictionary from the samples taken so far, as
+// configured in the ZstdDictConfig `conf` (see TrainZstdDict)
+func (s *DictSampler) Train(conf ZstdDictConfig) ([]byte, error) {
+	return TrainZstdDict(s.Samples(), conf)
+}
+
+// Ping implements Pinger, checking the health of the underlying writer
+func (s *DictSampler) Ping(ctx context.Context) error {
+	return Ping(ctx, s.w)
+}
+
+// Capabilities implements Capable, returning the capabilities of the
+// underlying writer
+func (s *DictSampler) Capabilities() Capability {
+	caps, _ := Capabilities(s.w)
+	return caps
+}
+
+// Shutdown implements Shutdowner, shutting down the underlying writer
+func (s *DictSampler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, s.w)
+}
@@ -0,0 +1,88 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestTrainZstdDict(t *testing.T) {
+	var out bytes.Buffer
+	sampler := NewDictSampler(&out, 200)
+	for i := 0; i < 1000; i++ {
+		_, _ = fmt.Fprintf(sampler,
+			`{"timestamp":"2024-05-06T07:00:%02d.%03dZ","message":"request served","level":"info","data":{"method":"GET","path":"/api/v1/orders/%d","status":200,"duration_ms":%d}}`+"\n",
+			i%60, i%1000, i, i%97,
+		)
+	}
+
+	t.Run("Sampler", func(t *testing.T) {
+		if n := len(sampler.Samples()); n != 200 {
+			t.Errorf("output mismatch error: wanted %v samples ; got %v", 200, n)
+		}
+		if n := bytes.Count(out.Bytes(), []byte("\n")); n != 1000 {
+			t.Errorf("output mismatch error: wanted %v lines written ; got %v", 1000, n)
+		}
+	})
+
+	t.Run("NotEnoughSamples", func(t *testing.T) {
+		if _, err := TrainZstdDict([][]byte{[]byte("{}")}, ZstdDictConfig{}); !errors.Is(err, ErrNotEnoughSamples) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", ErrNotEnoughSamples, err)
+		}
+	})
+
+	zdict, err := sampler.Train(ZstdDictConfig{Size: 4 << 10, ID: 42})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	t.Run("HTTPWriter", func(t *testing.T) {
+		var body []byte
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+		}))
+		defer srv.Close()
+
+		payload := []byte(`{"timestamp":"2024-05-06T08:00:00.000Z","message":"request served","level":"info","data":{"method":"GET","path":"/api/v1/orders/5000","status":200,"duration_ms":12}}` + "\n")
+		plain, _, _ := CompressZstd.compress(payload, 1, nil)
+
+		w, err := HTTPWriter(srv.URL, HTTPConfig{Compression: CompressZstd, CompressionThreshold: 1, ZstdDict: zdict})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if _, err := w.Write(payload); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if len(body) >= len(plain) {
+			t.Errorf("expected the dictionary to improve compression: %d bytes, %d without it", len(body), len(plain))
+		}
+
+		dec, err := zstd.NewReader(nil, zstd.WithDecoderDicts(zdict))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		defer dec.Close()
+
+		decoded, err := dec.DecodeAll(body, nil)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if !bytes.Equal(decoded, payload) {
+			t.Errorf("output mismatch error: wanted %q ; got %q", payload, decoded)
+		}
+	})
+}
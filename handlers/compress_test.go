@@ -0,0 +1,82 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestCompression(t *testing.T) {
+	payload := []byte(strings.Repeat(`{"message":"request served","level":"info"}`+"\n", 100))
+
+	decode := map[string]func(t *testing.T, p []byte) []byte{
+		"gzip": func(t *testing.T, p []byte) []byte {
+			r, err := gzip.NewReader(bytes.NewReader(p))
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return nil
+			}
+			out, _ := io.ReadAll(r)
+			return out
+		},
+		"zstd": func(t *testing.T, p []byte) []byte {
+			r, err := zstd.NewReader(bytes.NewReader(p))
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return nil
+			}
+			defer r.Close()
+			out, _ := io.ReadAll(r)
+			return out
+		},
+	}
+
+	for _, c := range []Compression{CompressGzip, CompressZstd} {
+		t.Run(c.String(), func(t *testing.T) {
+			var (
+				encoding string
+				body     []byte
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encoding = r.Header.Get("Content-Encoding")
+				body, _ = io.ReadAll(r.Body)
+			}))
+			defer srv.Close()
+
+			w, _ := HTTPWriter(srv.URL, HTTPConfig{Compression: c})
+			if _, err := w.Write(payload); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if encoding != c.String() {
+				t.Errorf("output mismatch error: wanted %q ; got %q", c.String(), encoding)
+			}
+			if len(body) >= len(payload) {
+				t.Errorf("expected the body to be compressed: %d bytes from %d", len(body), len(payload))
+			}
+			if out := decode[c.String()](t, body); !bytes.Equal(out, payload) {
+				t.Errorf("output mismatch error: wanted the original payload ; got %q", out)
+			}
+		})
+	}
+
+	t.Run("BelowThreshold", func(t *testing.T) {
+		out, compressed, err := CompressGzip.compress(payload[:100], 0, nil)
+		if err != nil || compressed || !bytes.Equal(out, payload[:100]) {
+			t.Errorf("output mismatch error: wanted the payload as-is ; got %q (%v)", out, err)
+		}
+
+		if _, compressed, _ = CompressGzip.compress(payload[:100], 50, nil); !compressed {
+			t.Errorf("expected the payload to be compressed above a custom threshold")
+		}
+	})
+}
@@ -0,0 +1,506 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	consoleTimeFormat = "15:04:05.000"
+	ansiReset         = "\x1b[0m"
+)
+
+// ErrUnknownTheme is raised when parsing the name of a Theme that is not
+// built-in
+var ErrUnknownTheme error = errors.New("unknown theme")
+
+// Theme describes the styling of console output, as the ANSI escape sequences
+// written before each of the elements of a line. Empty styles leave the
+// element unstyled, and styled elements are followed by a reset sequence.
+//
+// Any sequence is accepted, so custom themes can use 256-color or true-color
+// escapes as well:
+//
+//	theme := handlers.DarkTheme
+//	theme.Info = handlers.SGR(38, 5, 39) // 256-color blue
+type Theme struct {
+	// Trace, Debug, Info, Warn, Error and Fatal style the level of the records
+	// with (at least) the corresponding level
+	Trace string
+	Debug string
+	Info  string
+	Warn  string
+	Error string
+	Fatal string
+
+	// Time styles the record's timestamp
+	Time string
+	// Message styles the record's message
+	Message string
+	// Key styles the attributes' keys, including the `=` separator
+	Key string
+	// Value styles the attributes' values
+	Value string
+	// Source styles the caller's file and line, if added
+	Source string
+}
+
+var (
+	// DarkTheme is the default Theme, for terminals with a dark background
+	DarkTheme = Theme{
+		Trace:  "\x1b[90m",
+		Debug:  "\x1b[90m",
+		Info:   "\x1b[36m",
+		Warn:   "\x1b[33m",
+		Error:  "\x1b[31m",
+		Fatal:  "\x1b[1;35m",
+		Time:   "\x1b[2m",
+		Key:    "\x1b[2m",
+		Source: "\x1b[2m",
+	}
+
+	// LightTheme is a Theme for terminals with a light background
+	LightTheme = Theme{
+		Trace:  "\x1b[37m",
+		Debug:  "\x1b[34m",
+		Info:   "\x1b[32m",
+		Warn:   "\x1b[1;33m",
+		Error:  "\x1b[1;31m",
+		Fatal:  "\x1b[1;37;41m",
+		Time:   "\x1b[90m",
+		Key:    "\x1b[35m",
+		Source: "\x1b[90m",
+	}
+
+	// MonochromeTheme is a Theme that only uses the intensity, underline and
+	// reverse video attributes, for terminals without colors
+	MonochromeTheme = Theme{
+		Trace:  "\x1b[2m",
+		Debug:  "\x1b[2m",
+		Warn:   "\x1b[1m",
+		Error:  "\x1b[1;4m",
+		Fatal:  "\x1b[7m",
+		Time:   "\x1b[2m",
+		Key:    "\x1b[2m",
+		Source: "\x1b[2m",
+	}
+)
+
+// SGR returns the ANSI Select Graphic Rendition escape sequence with the
+// parameters `params`, for use in a Theme. For example, SGR(1, 31) is bold red
+func SGR(params ...int) string {
+	sb := &strings.Builder{}
+	sb.WriteString("\x1b[")
+	for i, p := range params {
+		if i > 0 {
+			sb.WriteByte(';')
+		}
+		sb.WriteString(strconv.Itoa(p))
+	}
+	sb.WriteByte('m')
+	return sb.String()
+}
+
+// ParseTheme returns the built-in Theme named `name`: `dark`, `light`,
+// `monochrome`, or `none` for no styling
+func ParseTheme(name string) (Theme, error) {
+	switch strings.ToLower(name) {
+	case "dark":
+		return DarkTheme, nil
+	case "light":
+		return LightTheme, nil
+	case "monochrome", "mono":
+		return MonochromeTheme, nil
+	case "none":
+		return Theme{}, nil
+	default:
+		return Theme{}, fmt.Errorf("%w: %q", ErrUnknownTheme, name)
+	}
+}
+
+// Level returns the style for the level `lv`
+func (t Theme) Level(lv level.Level) string {
+	if lv == nil {
+		return ""
+	}
+
+	switch n := lv.Int(); {
+	case n >= level.Fatal.Int():
+		return t.Fatal
+	case n >= level.Error.Int():
+		return t.Error
+	case n >= level.Warn.Int():
+		return t.Warn
+	case n >= level.Info.Int():
+		return t.Info
+	case n >= level.Debug.Int():
+		return t.Debug
+	default:
+		return t.Trace
+	}
+}
+
+// Paint writes the string `s` to `w` with the style `style`, followed by a
+// reset sequence if styled
+func (t Theme) Paint(w io.StringWriter, style, s string) {
+	if style == "" {
+		w.WriteString(s)
+		return
+	}
+	w.WriteString(style)
+	w.WriteString(s)
+	w.WriteString(ansiReset)
+}
+
+// LevelFormat is the way a Console handler renders records' levels
+type LevelFormat int
+
+const (
+	// LevelName renders levels with their full name in uppercase, padded to a
+	// fixed width (e.g. `INFO  `)
+	LevelName LevelFormat = iota
+	// LevelAbbrev renders levels as three-letter abbreviations (e.g. `INF`,
+	// `WRN`)
+	LevelAbbrev
+	// LevelIcon renders levels as emoji (e.g. 🔵 for info, 🔴 for error), all
+	// of them two columns wide in most terminals
+	LevelIcon
+)
+
+var (
+	levelAbbrevs = [...]string{"TRC", "DBG", "INF", "WRN", "ERR", "FTL"}
+	levelIcons   = [...]string{"🔎", "🐛", "🔵", "🟡", "🔴", "💀"}
+)
+
+// label returns the text for the level `lv` in this LevelFormat
+func (f LevelFormat) label(lv level.Level) string {
+	if lv == nil {
+		switch f {
+		case LevelAbbrev:
+			return "   "
+		case LevelIcon:
+			return "  "
+		default:
+			return "      "
+		}
+	}
+
+	var idx int
+	switch n := lv.Int(); {
+	case n >= level.Fatal.Int():
+		idx = 5
+	case n >= level.Error.Int():
+		idx = 4
+	case n >= level.Warn.Int():
+		idx = 3
+	case n >= level.Info.Int():
+		idx = 2
+	case n >= level.Debug.Int():
+		idx = 1
+	}
+
+	switch f {
+	case LevelAbbrev:
+		return levelAbbrevs[idx]
+	case LevelIcon:
+		return levelIcons[idx]
+	default:
+		return fmt.Sprintf("%-6s", strings.ToUpper(lv.String()))
+	}
+}
+
+type consoleHandler struct {
+	w         io.Writer
+	theme     Theme
+	levelFmt  LevelFormat
+	addSource bool
+	source    SourceConfig
+	levelRef  level.Leveler
+	replFn    func(a attr.Attr) attr.Attr
+	attrs     []attr.Attr
+	catalog   Catalog
+	multiline MultilineMode
+}
+
+// Console creates a handler that writes records to the io.Writer `w` as
+// human-readable lines styled with the Theme `theme`, with the record's time,
+// level and message followed by its attributes, as `key=value` pairs (with
+// the keys of nested groups joined with dots):
+//
+//	15:04:05.000 INFO   request served http.method=GET path=/items
+//
+// A zero Theme writes the lines without any escape sequences. On Windows, the
+// virtual terminal processing of the console is enabled for the escape
+// sequences to be rendered; on older consoles that do not support it, the
+// lines are written without styling
+func Console(w io.Writer, theme Theme) Handler {
+	if w == nil {
+		return nil
+	}
+	if theme != (Theme{}) && !EnableVirtualTerminal(w) {
+		theme = Theme{}
+	}
+	return consoleHandler{
+		w:     w,
+		theme: theme,
+	}
+}
+
+// WithLevelFormat creates a copy of the Console Handler `h`, rendering the
+// records' levels in the LevelFormat `format`. Returns nil if the Handler is
+// not a Console handler
+func WithLevelFormat(h Handler, format LevelFormat) Handler {
+	consoleH, ok := (h).(consoleHandler)
+	if !ok {
+		return nil
+	}
+
+	consoleH.levelFmt = format
+	return consoleH
+}
+
+// WithMultiline creates a copy of the Console Handler `h`, rendering the
+// multi-line messages and values (like stack traces) in the MultilineMode
+// `mode`. As the Console handler quotes the values with line breaks, only the
+// messages are affected by MultilineEscape. Returns nil if the Handler is not
+// a Console handler
+func WithMultiline(h Handler, mode MultilineMode) Handler {
+	consoleH, ok := (h).(consoleHandler)
+	if !ok {
+		return nil
+	}
+
+	consoleH.multiline = mode
+	return consoleH
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h consoleHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	h.theme.Paint(b, h.theme.Time, r.Time().Format(consoleTimeFormat))
+	b.WriteByte(' ')
+
+	h.theme.Paint(b, h.theme.Level(r.Level()), h.levelFmt.label(r.Level()))
+	b.WriteByte(' ')
+
+	if h.addSource {
+		if f, ok := Source(r); ok {
+			src := h.source.file(f, filepath.Base) + ":" + strconv.Itoa(f.Line)
+			if h.source.Function {
+				src += " " + f.Function
+			}
+			h.theme.Paint(b, h.theme.Source, src)
+			b.WriteByte(' ')
+		}
+	}
+
+	msg := r.Message()
+	if h.catalog != nil {
+		attrs := r.Attrs()
+		msg = h.catalog.Message(msg, append(attrs[:len(attrs):len(attrs)], h.attrs...))
+	}
+
+	var folded *buffer.Buffer
+	switch h.multiline {
+	case MultilineEscape:
+		msg = EscapeLines(msg)
+	case MultilineFold:
+		folded = buffer.Get()
+		defer folded.Free()
+
+		var rest string
+		if msg, rest = CutFirstLine(msg); rest != "" {
+			*folded = AppendFolded(*folded, "", rest)
+		}
+	}
+
+	h.theme.Paint(b, h.theme.Message, msg)
+	h.writeAttrs(b, "", r.Attrs(), folded)
+	h.writeAttrs(b, "", h.attrs, folded)
+	b.WriteByte('\n')
+
+	if folded != nil {
+		_, _ = b.Write(folded.Bytes())
+	}
+
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+// writeAttrs writes the attributes `attrs` as `key=value` pairs, with their
+// keys prefixed with `prefix`. With MultilineFold, the multi-line values are
+// written to the Buffer `folded` instead
+func (h consoleHandler) writeAttrs(b *buffer.Buffer, prefix string, attrs []attr.Attr, folded *buffer.Buffer) {
+	for _, a := range attrs {
+		if h.replFn != nil && a != nil {
+			a = h.replFn(a)
+		}
+		if a == nil || a.Key() == "" {
+			continue
+		}
+
+		key := prefix + a.Key()
+		if group, ok := a.Value().([]attr.Attr); ok {
+			h.writeAttrs(b, key+".", group, folded)
+			continue
+		}
+		if folded != nil {
+			if s, ok := Multiline(a.Value()); ok {
+				*folded = AppendFolded(*folded, key, s)
+				continue
+			}
+		}
+
+		b.WriteByte(' ')
+		h.theme.Paint(b, h.theme.Key, key+"=")
+
+		if h.theme.Value == "" {
+			writeConsoleValue(b, a.Value())
+			continue
+		}
+		b.WriteString(h.theme.Value)
+		writeConsoleValue(b, a.Value())
+		b.WriteString(ansiReset)
+	}
+}
+
+// FormatValue formats the attribute value `v` as console output, quoting
+// strings that are empty or contain spaces, quotes or `=` signs
+func FormatValue(v any) string {
+	b := buffer.Get()
+	defer b.Free()
+
+	writeConsoleValue(b, v)
+	return b.String()
+}
+
+func writeConsoleValue(b *buffer.Buffer, v any) {
+	switch v := v.(type) {
+	case string:
+		if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+			*b = strconv.AppendQuote(*b, v)
+			return
+		}
+		b.WriteString(v)
+	case error:
+		writeConsoleValue(b, v.Error())
+	default:
+		b.WriteValue(v)
+	}
+}
+
+// Capabilities implements Capable, returning CapGroups along with the
+// capabilities of the handler's io.Writer
+func (h consoleHandler) Capabilities() Capability {
+	return EncoderCapabilities(CapGroups, h.w)
+}
+
+// Ping implements Pinger, checking the health of the handler's io.Writer
+func (h consoleHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, h.w)
+}
+
+// Shutdown implements Shutdowner, shutting down the handler's io.Writer
+func (h consoleHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, h.w)
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h consoleHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h consoleHandler) With(attrs ...attr.Attr) Handler {
+	return consoleHandler{
+		w:         h.w,
+		theme:     h.theme,
+		levelFmt:  h.levelFmt,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     attrs,
+		catalog:   h.catalog,
+		multiline: h.multiline,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h consoleHandler) WithSource(addSource bool) Handler {
+	return consoleHandler{
+		w:         h.w,
+		theme:     h.theme,
+		levelFmt:  h.levelFmt,
+		addSource: addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+		catalog:   h.catalog,
+		multiline: h.multiline,
+	}
+}
+
+// withSourceConfig implements sourceConfigurer
+func (h consoleHandler) withSourceConfig(conf SourceConfig) Handler {
+	h.source = conf
+	return h
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h consoleHandler) WithLevel(level level.Leveler) Handler {
+	return consoleHandler{
+		w:         h.w,
+		theme:     h.theme,
+		levelFmt:  h.levelFmt,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  level,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+		catalog:   h.catalog,
+		multiline: h.multiline,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h consoleHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return consoleHandler{
+		w:         h.w,
+		theme:     h.theme,
+		levelFmt:  h.levelFmt,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    fn,
+		attrs:     h.attrs,
+		catalog:   h.catalog,
+		multiline: h.multiline,
+	}
+}
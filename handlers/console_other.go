@@ -0,0 +1,12 @@
+//go:build !windows
+
+package handlers
+
+import "io"
+
+// EnableVirtualTerminal reports whether the io.Writer `w` can be written ANSI
+// escape sequences, enabling their processing in Windows consoles. Outside of
+// Windows, terminals render them already, so it always returns true
+func EnableVirtualTerminal(io.Writer) bool {
+	return true
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestConsole(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6_000_000, time.UTC)
+
+	t.Run("NoTheme", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := Console(b, Theme{}).With(attr.String("service", "api"))
+
+		err := h.Handle(records.New(ts, level.Warn, "slow request",
+			attr.New("http", []attr.Attr{attr.Int("status", 504)}),
+			attr.String("path", "/items list"),
+		))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		wants := "03:04:05.006 WARN   slow request http.status=504 path=\"/items list\" service=api\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("DarkTheme", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := Console(b, DarkTheme)
+
+		_ = h.Handle(records.New(ts, level.Error, "failed", attr.String("err", "boom")))
+
+		wants := "\x1b[2m03:04:05.006\x1b[0m \x1b[31mERROR \x1b[0m failed \x1b[2merr=\x1b[0mboom\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("CustomTheme", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		theme := MonochromeTheme
+		theme.Info = SGR(38, 5, 39)
+		theme.Value = SGR(1)
+		h := Console(b, theme)
+
+		_ = h.Handle(records.New(ts, level.Info, "ok", attr.Int("n", 1)))
+
+		for _, wants := range []string{"\x1b[38;5;39mINFO  \x1b[0m", "\x1b[1m1\x1b[0m"} {
+			if !strings.Contains(b.String(), wants) {
+				t.Errorf("output mismatch error: wanted %q in the output ; got %q", wants, b.String())
+			}
+		}
+	})
+	t.Run("WithSource", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := Console(b, Theme{}).WithSource(true)
+
+		_ = h.Handle(records.New(ts, level.Info, "message"))
+
+		if !strings.Contains(b.String(), " console_test.go:") {
+			t.Errorf("output mismatch error: wanted the caller's source ; got %q", b.String())
+		}
+	})
+	t.Run("WithSourceFunction", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithSourceConfig(Console(b, Theme{}).WithSource(true), SourceConfig{Function: true})
+
+		_ = h.Handle(records.New(ts, level.Info, "message"))
+
+		if !strings.Contains(b.String(), " github.com/zalgonoise/logx/handlers.TestConsole.func") {
+			t.Errorf("output mismatch error: wanted the caller's function ; got %q", b.String())
+		}
+	})
+	t.Run("LevelFormat", func(t *testing.T) {
+		for _, testcase := range []struct {
+			name   string
+			format LevelFormat
+			wants  string
+		}{
+			{name: "Name", format: LevelName, wants: "03:04:05.006 DEBUG  message\n03:04:05.006 ERROR  message\n"},
+			{name: "Abbrev", format: LevelAbbrev, wants: "03:04:05.006 DBG message\n03:04:05.006 ERR message\n"},
+			{name: "Icon", format: LevelIcon, wants: "03:04:05.006 🐛 message\n03:04:05.006 🔴 message\n"},
+		} {
+			t.Run(testcase.name, func(t *testing.T) {
+				b := &bytes.Buffer{}
+				h := WithLevelFormat(Console(b, Theme{}), testcase.format).With(attr.String("k", "v"))
+				h = h.WithReplaceFn(func(attr.Attr) attr.Attr { return nil })
+
+				_ = h.Handle(records.New(ts, level.Debug, "message"))
+				_ = h.Handle(records.New(ts, level.Error, "message"))
+
+				if b.String() != testcase.wants {
+					t.Errorf("output mismatch error: wanted %q ; got %q", testcase.wants, b.String())
+				}
+			})
+		}
+
+		if h := WithLevelFormat(CSV(&bytes.Buffer{}, false), LevelIcon); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+	t.Run("WithLevel", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := Console(b, Theme{}).WithLevel(level.Warn)
+
+		_ = h.Handle(records.New(ts, level.Info, "message"))
+
+		if b.Len() != 0 {
+			t.Errorf("output mismatch error: wanted no output ; got %q", b.String())
+		}
+	})
+	t.Run("Multiline", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithMultiline(Console(b, Theme{}), MultilineFold)
+
+		_ = h.Handle(records.New(ts, level.Error, "panic recovered",
+			attr.String("path", "/items"),
+			attr.New("error", errors.New("boom\ngoroutine 1 [running]:")),
+		))
+
+		wants := "03:04:05.006 ERROR  panic recovered path=/items\n" +
+			"  error:\n" +
+			"    boom\n" +
+			"    goroutine 1 [running]:\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+
+		if h := WithMultiline(Unimpl(), MultilineFold); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+}
+
+func TestParseTheme(t *testing.T) {
+	for _, testcase := range []struct {
+		name  string
+		wants Theme
+	}{
+		{name: "dark", wants: DarkTheme},
+		{name: "Light", wants: LightTheme},
+		{name: "monochrome", wants: MonochromeTheme},
+		{name: "none", wants: Theme{}},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			theme, err := ParseTheme(testcase.name)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if theme != testcase.wants {
+				t.Errorf("output mismatch error: wanted %v ; got %v", testcase.wants, theme)
+			}
+		})
+	}
+
+	t.Run("Unknown", func(t *testing.T) {
+		if _, err := ParseTheme("neon"); !errors.Is(err, ErrUnknownTheme) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", ErrUnknownTheme, err)
+		}
+	})
+}
@@ -0,0 +1,35 @@
+//go:build windows
+
+package handlers
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// EnableVirtualTerminal turns on the virtual terminal processing of the
+// console that the io.Writer `w` writes to, if any, so that it renders ANSI
+// escape sequences instead of printing them. It returns false if `w` is a
+// console that does not support it (before Windows 10), where the escape
+// sequences should not be written
+func EnableVirtualTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return true
+	}
+
+	handle := windows.Handle(f.Fd())
+
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		// not a console: a pipe, a file, or a terminal emulator like mintty
+		return true
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+
+	return windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
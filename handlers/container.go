@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zalgonoise/attr"
+)
+
+// EnvContainerImage is the environment variable that WithContainerInfo reads
+// the container's image from, as no runtime exposes it otherwise (except for
+// Podman). It is usually set in the image itself, or by the deployment
+const EnvContainerImage = "CONTAINER_IMAGE"
+
+// containerID matches the 64-character hexadecimal container IDs
+var containerID = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// WithContainerInfo decorates the Handler `h` so that every Record it handles
+// carries a `container` attribute group, when running in a container:
+//
+//	"container": {"runtime": "docker", "id": "3f4e1c...", "image": "app:1.4.0"}
+//
+// The runtime and container ID are detected from the process' cgroups and
+// mounts (under /proc/self), and from the marker files left by Docker
+// (`/.dockerenv`) and Podman (`/run/.containerenv`). The image is read from
+// the EnvContainerImage environment variable, or from Podman's marker file.
+//
+// Fields that are not found are omitted, and if no container is detected, the
+// Handler `h` is returned as-is
+func WithContainerInfo(h Handler) Handler {
+	return Enrich(h, ContainerInfo())
+}
+
+// ContainerInfo returns a static Enricher with the `container` attribute group
+// added by WithContainerInfo, or with no attributes outside of a container
+func ContainerInfo() Enricher {
+	group := containerAttrs(os.Getenv, "/")
+	if len(group) == 0 {
+		return staticEnricher{}
+	}
+	return staticEnricher{attrs: []attr.Attr{attr.New("container", group)}}
+}
+
+// containerAttrs returns the container's runtime, ID and image as attributes,
+// reading the environment with `getenv` and the files under `root`
+func containerAttrs(getenv func(string) string, root string) []attr.Attr {
+	var runtime, id, image string
+
+	if _, err := os.Stat(filepath.Join(root, ".dockerenv")); err == nil {
+		runtime = "docker"
+	}
+	if env, err := readContainerEnv(filepath.Join(root, "run", ".containerenv")); err == nil {
+		runtime, id, image = "podman", env["id"], env["image"]
+	}
+
+	if id == "" {
+		var detected string
+		if detected, id = scanContainerID(filepath.Join(root, "proc", "self", "cgroup")); id == "" {
+			detected, id = scanContainerID(filepath.Join(root, "proc", "self", "mountinfo"))
+		}
+		if runtime == "" {
+			runtime = detected
+		}
+	}
+
+	if v := getenv(EnvContainerImage); v != "" {
+		image = v
+	}
+
+	if runtime == "" && id == "" {
+		return nil
+	}
+
+	attrs := make([]attr.Attr, 0, 3)
+	for _, field := range []struct {
+		key   string
+		value string
+	}{
+		{"runtime", runtime},
+		{"id", id},
+		{"image", image},
+	} {
+		if field.value != "" {
+			attrs = append(attrs, attr.String(field.key, field.value))
+		}
+	}
+	return attrs
+}
+
+// scanContainerID looks for a container ID in the cgroup or mountinfo file at
+// `path`, returning it with the runtime inferred from the line it is found in
+func scanContainerID(path string) (runtime, id string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.Contains(line, "/docker") && !strings.Contains(line, "containers/") &&
+			!strings.Contains(line, "kubepods") && !strings.Contains(line, "containerd") &&
+			!strings.Contains(line, "crio") && !strings.Contains(line, "libpod") {
+			continue
+		}
+
+		id := containerID.FindString(line)
+		if id == "" {
+			continue
+		}
+
+		switch {
+		case strings.Contains(line, "libpod"), strings.Contains(line, "overlay-containers"):
+			return "podman", id
+		case strings.Contains(line, "crio"):
+			return "cri-o", id
+		case strings.Contains(line, "containerd"):
+			return "containerd", id
+		case strings.Contains(line, "docker"):
+			return "docker", id
+		default:
+			return "kubernetes", id
+		}
+	}
+
+	return "", ""
+}
+
+// readContainerEnv parses Podman's `.containerenv` file at `path`, which holds
+// a `key="value"` pair per line
+func readContainerEnv(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		env[key] = value
+	}
+	return env, nil
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/attr"
+)
+
+func TestContainerInfo(t *testing.T) {
+	id := strings.Repeat("3f4e1c0a", 8)
+
+	// newRoot creates a root directory with the files in `files`, relative to it
+	newRoot := func(t *testing.T, files map[string]string) string {
+		root := t.TempDir()
+		for path, data := range files {
+			path = filepath.Join(root, path)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		return root
+	}
+	env := func(key string) string {
+		if key == EnvContainerImage {
+			return "app:1.4.0"
+		}
+		return ""
+	}
+
+	for _, test := range []struct {
+		name   string
+		files  map[string]string
+		getenv func(string) string
+		wants  map[string]any
+	}{
+		{
+			name: "DockerCgroupV1",
+			files: map[string]string{
+				".dockerenv":       "",
+				"proc/self/cgroup": "12:memory:/docker/" + id + "\n11:cpu:/docker/" + id + "\n",
+			},
+			getenv: env,
+			wants:  map[string]any{"runtime": "docker", "id": id, "image": "app:1.4.0"},
+		},
+		{
+			name: "DockerCgroupV2",
+			files: map[string]string{
+				"proc/self/cgroup":    "0::/\n",
+				"proc/self/mountinfo": "1 2 0:3 /var/lib/docker/containers/" + id + "/hostname /etc/hostname rw - ext4 /dev/sda1 rw\n",
+			},
+			getenv: os.Getenv,
+			wants:  map[string]any{"runtime": "docker", "id": id},
+		},
+		{
+			name: "Kubernetes",
+			files: map[string]string{
+				"proc/self/cgroup": "0::/kubepods.slice/kubepods-burstable.slice/cri-containerd-" + id + ".scope\n",
+			},
+			getenv: os.Getenv,
+			wants:  map[string]any{"runtime": "containerd", "id": id},
+		},
+		{
+			name: "Podman",
+			files: map[string]string{
+				"run/.containerenv": "engine=\"podman-4.9.3\"\nname=\"app\"\nid=\"" + id + "\"\nimage=\"quay.io/org/app:latest\"\n",
+			},
+			getenv: os.Getenv,
+			wants:  map[string]any{"runtime": "podman", "id": id, "image": "quay.io/org/app:latest"},
+		},
+		{
+			name: "Host",
+			files: map[string]string{
+				"proc/self/cgroup": "0::/user.slice/user-1000.slice/session-2.scope\n",
+			},
+			getenv: env,
+			wants:  map[string]any{},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := attr.Map(containerAttrs(test.getenv, newRoot(t, test.files))...)
+			if !reflect.DeepEqual(test.wants, got) {
+				t.Errorf("output mismatch error: wanted %v ; got %v", test.wants, got)
+			}
+		})
+	}
+
+	t.Run("Nil", func(t *testing.T) {
+		if WithContainerInfo(nil) != nil {
+			t.Errorf("expected output to be nil")
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+type csvHandler struct {
+	w        io.Writer
+	keys     []string
+	header   *sync.Once
+	levelRef level.Leveler
+	replFn   func(a attr.Attr) attr.Attr
+	attrs    []attr.Attr
+}
+
+// CSV creates a handler that writes records to the io.Writer `w` as CSV rows,
+// with the `timestamp`, `level` and `message` columns followed by a column for
+// each of the attribute keys `keys`. Keys of attributes within groups are
+// referenced with dots (e.g. `http.status`), and missing attributes are
+// written as empty values.
+//
+// If `header` is true, a header row with the column names is written before
+// the first record. Handlers derived from this one (with its With* methods)
+// share its header
+func CSV(w io.Writer, header bool, keys ...string) Handler {
+	if w == nil {
+		return nil
+	}
+
+	h := csvHandler{
+		w:      w,
+		keys:   keys,
+		header: &sync.Once{},
+	}
+	if !header {
+		h.header.Do(func() {})
+	}
+	return h
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h csvHandler) Handle(r records.Record) (err error) {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	cw := csv.NewWriter(b)
+
+	h.header.Do(func() {
+		err = cw.Write(append([]string{"timestamp", "level", "message"}, h.keys...))
+	})
+	if err != nil {
+		return err
+	}
+
+	row := make([]string, 3, 3+len(h.keys))
+	row[0] = r.Time().Format(time.RFC3339Nano)
+	if lv := r.Level(); lv != nil {
+		row[1] = lv.String()
+	}
+	row[2] = r.Message()
+
+	for _, key := range h.keys {
+		row = append(row, h.value(key, r))
+	}
+
+	if err = cw.Write(row); err != nil {
+		return err
+	}
+	cw.Flush()
+	if err = cw.Error(); err != nil {
+		return err
+	}
+
+	_, err = h.w.Write(b.Bytes())
+	return err
+}
+
+// value returns the value of the attribute with the (dot-separated) key `key`
+// in the Record `r` or in the handler's bound attributes, formatted as text
+func (h csvHandler) value(key string, r records.Record) string {
+	for _, attrs := range [2][]attr.Attr{r.Attrs(), h.attrs} {
+		if a := h.find(key, attrs); a != nil {
+			switch v := a.Value().(type) {
+			case string:
+				return v
+			case time.Time:
+				return v.Format(time.RFC3339Nano)
+			case error:
+				return v.Error()
+			default:
+				return fmt.Sprint(v)
+			}
+		}
+	}
+	return ""
+}
+
+func (h csvHandler) find(key string, attrs []attr.Attr) attr.Attr {
+	for i := len(attrs) - 1; i >= 0; i-- {
+		a := attrs[i]
+		if h.replFn != nil && a != nil {
+			a = h.replFn(a)
+		}
+		if a == nil {
+			continue
+		}
+
+		if a.Key() == key {
+			return a
+		}
+		if group, ok := a.Value().([]attr.Attr); ok && strings.HasPrefix(key, a.Key()+".") {
+			if found := h.find(key[len(a.Key())+1:], group); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// Capabilities implements Capable, returning CapGroups (as the columns match
+// the attributes within groups by their dotted keys) along with the
+// capabilities of the handler's io.Writer
+func (h csvHandler) Capabilities() Capability {
+	return EncoderCapabilities(CapGroups, h.w)
+}
+
+// Ping implements Pinger, checking the health of the handler's io.Writer
+func (h csvHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, h.w)
+}
+
+// Shutdown implements Shutdowner, shutting down the handler's io.Writer
+func (h csvHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, h.w)
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h csvHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h csvHandler) With(attrs ...attr.Attr) Handler {
+	return csvHandler{
+		w:        h.w,
+		keys:     h.keys,
+		header:   h.header,
+		levelRef: h.levelRef,
+		replFn:   h.replFn,
+		attrs:    attrs,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h csvHandler) WithSource(bool) Handler {
+	return h
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h csvHandler) WithLevel(level level.Leveler) Handler {
+	return csvHandler{
+		w:        h.w,
+		keys:     h.keys,
+		header:   h.header,
+		levelRef: level,
+		replFn:   h.replFn,
+		attrs:    h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h csvHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return csvHandler{
+		w:        h.w,
+		keys:     h.keys,
+		header:   h.header,
+		levelRef: h.levelRef,
+		replFn:   fn,
+		attrs:    h.attrs,
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestCSV(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Header", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := CSV(b, true, "user", "http.status", "missing").With(attr.String("user", "gopher"))
+
+		_ = h.Handle(records.New(ts, level.Info, "request, served",
+			attr.New("http", []attr.Attr{attr.Int("status", 200)}),
+		))
+		_ = h.Handle(records.New(ts, level.Warn, `said "hi"`, attr.String("user", "admin")))
+
+		wants := "timestamp,level,message,user,http.status,missing\n" +
+			"2024-01-02T03:04:05Z,info,\"request, served\",gopher,200,\n" +
+			"2024-01-02T03:04:05Z,warn,\"said \"\"hi\"\"\",admin,,\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("NoHeader", func(t *testing.T) {
+		b := &bytes.Buffer{}
+
+		_ = CSV(b, false, "id").Handle(records.New(ts, level.Error, "failed", attr.Int("id", 7)))
+
+		wants := "2024-01-02T03:04:05Z,error,failed,7\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("SharedHeader", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := CSV(b, true)
+
+		_ = h.Handle(records.New(ts, level.Info, "one"))
+		_ = h.With(attr.Int("a", 1)).WithLevel(level.Info).Handle(records.New(ts, level.Info, "two"))
+
+		if n := bytes.Count(b.Bytes(), []byte("timestamp,level,message")); n != 1 {
+			t.Errorf("output mismatch error: wanted %v header rows ; got %v", 1, n)
+		}
+	})
+}
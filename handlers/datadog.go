@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	ddServiceKey = "service"
+	ddEnvKey     = "env"
+	ddVersionKey = "version"
+)
+
+// DatadogConfig holds the unified service tags that a Datadog handler writes
+// with every record. Empty fields are read from the DD_SERVICE, DD_ENV and
+// DD_VERSION environment variables
+type DatadogConfig struct {
+	Service string
+	Env     string
+	Version string
+}
+
+type datadogHandler struct {
+	w         io.Writer
+	conf      DatadogConfig
+	addSource bool
+	source    SourceConfig
+	levelRef  level.Leveler
+	replFn    func(a attr.Attr) attr.Attr
+	attrs     []attr.Attr
+}
+
+// Datadog creates a handler that writes records to the io.Writer `w` as the
+// JSON lines that the Datadog Agent parses without remapping, with the
+// `timestamp`, `status` and `message` fields, as well as the `service`, `env`
+// and `version` tags from the DatadogConfig `conf`.
+//
+// The `trace_id` and `span_id` attributes (as added by logx.TraceParent, for
+// instance) are written as the `dd.trace_id` and `dd.span_id` fields, in the
+// decimal 64-bit form that Datadog correlates with its traces. Attributes
+// with a reserved key (`service`, `env` or `version`) are only written if
+// the corresponding tag is not set. With WithSource, the caller's file, line
+// and function are added as the `logger.*` fields
+func Datadog(w io.Writer, conf DatadogConfig) Handler {
+	if w == nil {
+		return nil
+	}
+	if conf.Service == "" {
+		conf.Service = os.Getenv("DD_SERVICE")
+	}
+	if conf.Env == "" {
+		conf.Env = os.Getenv("DD_ENV")
+	}
+	if conf.Version == "" {
+		conf.Version = os.Getenv("DD_VERSION")
+	}
+
+	return datadogHandler{
+		w:    w,
+		conf: conf,
+	}
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h datadogHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	b.WriteString(`{"timestamp":`)
+	b.WriteJSON(r.Time().UTC().Format(time.RFC3339Nano))
+	b.WriteString(`,"status":`)
+	b.WriteJSON(datadogStatus(r.Level()))
+	b.WriteString(`,"message":`)
+	b.WriteJSON(r.Message())
+
+	skip := []string{traceIDKey, spanIDKey}
+	for _, tag := range [...]struct{ key, value string }{
+		{ddServiceKey, h.conf.Service},
+		{ddEnvKey, h.conf.Env},
+		{ddVersionKey, h.conf.Version},
+	} {
+		if tag.value == "" {
+			continue
+		}
+		skip = append(skip, tag.key)
+		b.WriteString(`,"` + tag.key + `":`)
+		b.WriteJSON(tag.value)
+	}
+
+	if a := lookup(traceIDKey, r.Attrs(), h.attrs); a != nil {
+		b.WriteString(`,"dd.trace_id":`)
+		b.WriteJSON(datadogID(a.Value()))
+	}
+	if a := lookup(spanIDKey, r.Attrs(), h.attrs); a != nil {
+		b.WriteString(`,"dd.span_id":`)
+		b.WriteJSON(datadogID(a.Value()))
+	}
+
+	if h.addSource {
+		if f, ok := Source(r); ok {
+			b.WriteString(`,"logger.file_name":`)
+			b.WriteJSON(h.source.file(f, keepPath))
+			b.WriteString(`,"logger.line":`)
+			b.WriteString(strconv.Itoa(f.Line))
+			b.WriteString(`,"logger.method_name":`)
+			b.WriteJSON(f.Function)
+		}
+	}
+
+	writeJSONFields(b, true, h.replFn, skip, r.Attrs())
+	writeJSONFields(b, true, h.replFn, skip, h.attrs)
+	b.WriteString("}\n")
+
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+// datadogStatus maps the level `lv` to a Datadog log status
+func datadogStatus(lv level.Level) string {
+	if lv == nil {
+		return "info"
+	}
+
+	switch n := lv.Int(); {
+	case n >= level.Fatal.Int():
+		return "critical"
+	case n >= level.Error.Int():
+		return "error"
+	case n >= level.Warn.Int():
+		return "warning"
+	case n >= level.Info.Int():
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// datadogID converts the hex-encoded W3C trace or span ID `id` into the
+// decimal form of its lower 64 bits, as used by Datadog. Other values are
+// returned in their default format
+func datadogID(id any) string {
+	s := fmt.Sprint(id)
+	if len(s) != 16 && len(s) != 32 {
+		return s
+	}
+
+	n, err := strconv.ParseUint(s[len(s)-16:], 16, 64)
+	if err != nil {
+		return s
+	}
+	return strconv.FormatUint(n, 10)
+}
+
+// Ping implements Pinger, checking the health of the handler's io.Writer
+func (h datadogHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, h.w)
+}
+
+// Shutdown implements Shutdowner, shutting down the handler's io.Writer
+func (h datadogHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, h.w)
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h datadogHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h datadogHandler) With(attrs ...attr.Attr) Handler {
+	return datadogHandler{
+		w:         h.w,
+		conf:      h.conf,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     attrs,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h datadogHandler) WithSource(addSource bool) Handler {
+	return datadogHandler{
+		w:         h.w,
+		conf:      h.conf,
+		addSource: addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// withSourceConfig implements sourceConfigurer
+func (h datadogHandler) withSourceConfig(conf SourceConfig) Handler {
+	h.source = conf
+	return h
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h datadogHandler) WithLevel(level level.Leveler) Handler {
+	return datadogHandler{
+		w:         h.w,
+		conf:      h.conf,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  level,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h datadogHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return datadogHandler{
+		w:         h.w,
+		conf:      h.conf,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    fn,
+		attrs:     h.attrs,
+	}
+}
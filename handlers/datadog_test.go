@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestDatadog(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := Datadog(b, DatadogConfig{Service: "api", Env: "prod", Version: "1.2.3"}).
+			With(attr.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"))
+
+		err := h.Handle(records.New(ts, level.Warn, "slow request",
+			attr.String("span_id", "00f067aa0ba902b7"),
+			attr.String("service", "ignored"),
+			attr.Int("status", 200),
+		))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		wants := `{"timestamp":"2024-01-02T03:04:05Z","status":"warning","message":"slow request",` +
+			`"service":"api","env":"prod","version":"1.2.3",` +
+			`"dd.trace_id":"11803532876627986230","dd.span_id":"67667974448284343","status":200}` + "\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, b.String())
+		}
+	})
+	t.Run("FromEnv", func(t *testing.T) {
+		t.Setenv("DD_SERVICE", "worker")
+		t.Setenv("DD_ENV", "")
+		t.Setenv("DD_VERSION", "")
+		b := &bytes.Buffer{}
+
+		_ = Datadog(b, DatadogConfig{}).Handle(records.New(ts, level.Fatal, "crashed", attr.String("env", "staging")))
+
+		wants := `{"timestamp":"2024-01-02T03:04:05Z","status":"critical","message":"crashed",` +
+			`"service":"worker","env":"staging"}` + "\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, b.String())
+		}
+	})
+	t.Run("ID", func(t *testing.T) {
+		for _, test := range []struct {
+			id    any
+			wants string
+		}{
+			{"00000000000000000000000000000001", "1"},
+			{"00000000000000ff", "255"},
+			{"not-an-id", "not-an-id"},
+			{uint64(42), "42"},
+		} {
+			if got := datadogID(test.id); got != test.wants {
+				t.Errorf("output mismatch error for %v: wanted %v ; got %v", test.id, test.wants, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/internal/stats"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	defaultDeadLetterPath     = "deadletter.ndjson"
+	defaultDeadLetterErrorKey = "delivery_error"
+)
+
+// DeadLetterConfig configures where a DeadLetter writes the records that could
+// not be delivered
+type DeadLetterConfig struct {
+	// Sink is the Handler that the undeliverable records are written to, if
+	// set. Otherwise, they are appended to the file in Path
+	Sink Handler
+	// Path is the file that the undeliverable records are appended to when no
+	// Sink is set, as JSON lines (like the ones written by jsonh), defaulting
+	// to `deadletter.ndjson`. Each record is synced to disk before its Handle
+	// call returns
+	Path string
+	// ErrorKey is the key of the attribute holding the delivery's error
+	// message, added to the undeliverable records, defaulting to
+	// `delivery_error`
+	ErrorKey string
+}
+
+func (c DeadLetterConfig) withDefaults() DeadLetterConfig {
+	if c.Path == "" {
+		c.Path = defaultDeadLetterPath
+	}
+	if c.ErrorKey == "" {
+		c.ErrorKey = defaultDeadLetterErrorKey
+	}
+	return c
+}
+
+// DeadLetter is a Handler that captures the records its wrapped Handler fails
+// to deliver, writing them to a dead-letter sink along with the delivery's
+// error, so that no record is lost silently and they can be replayed once the
+// issue is solved (see DeadLetterConfig.Replay).
+//
+// It is meant to wrap the whole delivery chain, so that only the records that
+// exhausted its retries and failovers are captured:
+//
+//	dl, err := handlers.NewDeadLetter(handlers.NewBreaker(remote, handlers.BreakerConfig{
+//		Fallback: local,
+//	}), handlers.DeadLetterConfig{Path: "/var/log/app/deadletter.ndjson"})
+//
+// A captured record is not reported as an error by the DeadLetter's Handle
+// method; only the records that the dead-letter sink also fails to write are,
+// and counted as dropped. Note that a Breaker without a Fallback Handler drops
+// the records silently while open, so they cannot be captured.
+//
+// Handlers derived from a DeadLetter (with its With* methods) share its sink
+// and counters
+type DeadLetter struct {
+	deadLetterHandler
+}
+
+type deadLetterHandler struct {
+	h        Handler
+	sink     Handler
+	file     io.WriteCloser
+	errorKey string
+	bound    []attr.Attr
+	captured *atomic.Uint64
+}
+
+// NewDeadLetter creates a DeadLetter for the Handler `h`, as configured by the
+// DeadLetterConfig `conf`. An error is returned if the dead-letter file cannot
+// be opened
+func NewDeadLetter(h Handler, conf DeadLetterConfig) (*DeadLetter, error) {
+	if h == nil {
+		h = Unimpl()
+	}
+	conf = conf.withDefaults()
+
+	d := &DeadLetter{
+		deadLetterHandler: deadLetterHandler{
+			h:        h,
+			sink:     conf.Sink,
+			errorKey: conf.ErrorKey,
+			captured: &atomic.Uint64{},
+		},
+	}
+
+	if conf.Sink == nil {
+		f, err := NDJSONFile(conf.Path, SyncPolicy{Records: 1})
+		if err != nil {
+			return nil, err
+		}
+		d.file = f
+	}
+
+	return d, nil
+}
+
+// Captured returns the number of records written to the dead-letter sink so
+// far
+func (d *DeadLetter) Captured() uint64 {
+	return d.captured.Load()
+}
+
+// Replay sends the records in the dead-letter file in Path to the Handler `h`,
+// in order, without the attribute holding their delivery's error; returning
+// the number of records replayed. It stops at the first error returned by `h`.
+//
+// The file is left as-is, to be removed (or rotated) once the records are
+// replayed. It should not be replayed while a DeadLetter is appending to it
+func (c DeadLetterConfig) Replay(h Handler) (int, error) {
+	if h == nil {
+		return 0, nil
+	}
+	c = c.withDefaults()
+
+	f, err := os.Open(c.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var (
+		n   int
+		dec = records.NewDecoder(f)
+	)
+	for {
+		r, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+
+		attrs := make([]attr.Attr, 0, r.AttrLen())
+		for _, a := range r.Attrs() {
+			if a != nil && a.Key() != c.ErrorKey {
+				attrs = append(attrs, a)
+			}
+		}
+
+		if err := h.Handle(records.WithPC(records.New(r.Time(), r.Level(), r.Message(), attrs...), records.PC(r))); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// capture writes the Record `r`, which failed to be delivered with the error
+// `err`, to the dead-letter sink
+func (d deadLetterHandler) capture(r records.Record, err error) error {
+	attrs := make([]attr.Attr, 0, len(d.bound)+1)
+	attrs = append(append(attrs, d.bound...), attr.String(d.errorKey, err.Error()))
+	r = r.AddAttr(attrs...)
+
+	if d.sink != nil {
+		return d.sink.Handle(r)
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	b.WriteString(`{"timestamp":`)
+	b.WriteJSON(r.Time().Format(time.RFC3339Nano))
+	b.WriteString(`,"message":`)
+	b.WriteJSON(r.Message())
+	b.WriteString(`,"level":`)
+	b.WriteJSON(r.Level().String())
+	b.WriteString(`,"data":{`)
+	writeJSONFields(b, false, nil, nil, r.Attrs())
+	b.WriteString("}}\n")
+
+	_, werr := d.file.Write(b.Bytes())
+	return werr
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (d deadLetterHandler) Enabled(level level.Level) bool {
+	return d.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (d deadLetterHandler) Handle(r records.Record) error {
+	err := d.h.Handle(r)
+	if err == nil {
+		return nil
+	}
+
+	if sinkErr := d.capture(r, err); sinkErr != nil {
+		stats.Dropped.Add(1)
+		return errors.Join(err, sinkErr)
+	}
+	d.captured.Add(1)
+	return nil
+}
+
+// Capabilities implements Capable, returning the capabilities of the decorated
+// Handler
+func (d deadLetterHandler) Capabilities() Capability {
+	return decoratedCapabilities(d.h)
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (d deadLetterHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, d.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler and then
+// the dead-letter sink, so that the records that fail to be flushed are
+// captured
+func (d deadLetterHandler) Shutdown(ctx context.Context) error {
+	err := Shutdown(ctx, d.h)
+	if d.sink != nil {
+		return errors.Join(err, Shutdown(ctx, d.sink))
+	}
+	return errors.Join(err, d.file.Close())
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (d deadLetterHandler) With(attrs ...attr.Attr) Handler {
+	d.h = d.h.With(attrs...)
+	d.bound = attrs
+	return d
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (d deadLetterHandler) WithSource(addSource bool) Handler {
+	d.h = d.h.WithSource(addSource)
+	return d
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (d deadLetterHandler) WithLevel(level level.Leveler) Handler {
+	d.h = d.h.WithLevel(level)
+	return d
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (d deadLetterHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	d.h = d.h.WithReplaceFn(fn)
+	return d
+}
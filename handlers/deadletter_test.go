@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestDeadLetter(t *testing.T) {
+	errDelivery := errors.New("sink unavailable")
+
+	t.Run("FileAndReplay", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "deadletter.ndjson")
+		failing := newTestHandler()
+		failing.err = errDelivery
+
+		dl, err := NewDeadLetter(failing, DeadLetterConfig{Path: path})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		h := dl.With(attr.String("service", "api"))
+		ts := time.Date(2024, 5, 6, 7, 0, 0, 0, time.UTC)
+		if err := h.Handle(records.New(ts, level.Warn, "order placed", attr.Int("items", 3))); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if dl.Captured() != 1 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 1, dl.Captured())
+		}
+
+		data, _ := os.ReadFile(path)
+		if !strings.Contains(string(data), `"delivery_error":"sink unavailable"`) ||
+			!strings.Contains(string(data), `"service":"api"`) {
+			t.Errorf("output mismatch error: wanted the record with its error ; got %s", data)
+		}
+
+		if err := Shutdown(context.Background(), dl); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		th := newTestHandler()
+		n, err := DeadLetterConfig{Path: path}.Replay(th)
+		if err != nil || n != 1 {
+			t.Errorf("output mismatch error: wanted %v records replayed ; got %v (%v)", 1, n, err)
+			return
+		}
+
+		r := th.Records()[0]
+		if !r.Time().Equal(ts) || r.Message() != "order placed" || r.AttrLen() != 2 {
+			t.Errorf("output mismatch error: wanted the original record ; got %v", r)
+		}
+		for _, a := range r.Attrs() {
+			if a.Key() == defaultDeadLetterErrorKey {
+				t.Errorf("unexpected error attribute in the replayed record: %v", a)
+			}
+		}
+	})
+	t.Run("SinkFailure", func(t *testing.T) {
+		failing, sink := newTestHandler(), newTestHandler()
+		failing.err = errDelivery
+		sink.err = errors.New("disk full")
+
+		dl, _ := NewDeadLetter(failing, DeadLetterConfig{Sink: sink})
+		err := dl.Handle(records.New(time.Now(), level.Info, "event"))
+		if !errors.Is(err, errDelivery) || !errors.Is(err, sink.err) {
+			t.Errorf("output mismatch error: wanted both errors ; got %v", err)
+		}
+		if dl.Captured() != 0 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 0, dl.Captured())
+		}
+	})
+	t.Run("Delivered", func(t *testing.T) {
+		h, sink := newTestHandler(), newTestHandler()
+
+		dl, _ := NewDeadLetter(h, DeadLetterConfig{Sink: sink})
+		_ = dl.Handle(records.New(time.Now(), level.Info, "event"))
+		if len(h.Records()) != 1 || len(sink.Records()) != 0 {
+			t.Errorf("output mismatch error: wanted the record delivered only")
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// ErrDuplicateKey is raised when a Handler with the DuplicateError policy
+// handles a record with repeated attribute keys
+var ErrDuplicateKey error = errors.New("duplicate attribute key")
+
+// DuplicatePolicy defines what a Handler does with the attributes of a record
+// that share the same key
+type DuplicatePolicy int
+
+const (
+	// DuplicateKeepFirst keeps the first attribute with a given key
+	DuplicateKeepFirst DuplicatePolicy = iota
+	// DuplicateKeepLast keeps the last attribute with a given key
+	DuplicateKeepLast
+	// DuplicateSuffix keeps all attributes, suffixing the repeated keys with
+	// their index, like `id`, `id_1`, `id_2`
+	DuplicateSuffix
+	// DuplicateError discards the record, returning an error wrapping
+	// ErrDuplicateKey
+	DuplicateError
+)
+
+type dedupeHandler struct {
+	h      Handler
+	policy DuplicatePolicy
+	bound  []attr.Attr
+}
+
+// Dedupe decorates the Handler `h` so that the records it handles carry unique
+// (top-level) attribute keys, resolving the duplicates with the
+// DuplicatePolicy `policy`.
+//
+// Attributes bound with the Handler's With method are kept by the decorator
+// and placed before the record's own attributes, in the order they were
+// bound; so with DuplicateKeepFirst the oldest binding wins, and with
+// DuplicateKeepLast the call site wins
+func Dedupe(h Handler, policy DuplicatePolicy) Handler {
+	if h == nil {
+		return nil
+	}
+
+	return dedupeHandler{
+		h:      h,
+		policy: policy,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (d dedupeHandler) Enabled(level level.Level) bool {
+	return d.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (d dedupeHandler) Handle(r records.Record) error {
+	if !d.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	attrs := r.Attrs()
+	if len(d.bound) > 0 {
+		attrs = append(append(make([]attr.Attr, 0, len(d.bound)+len(attrs)), d.bound...), attrs...)
+	}
+
+	deduped, err := d.dedupe(attrs)
+	if err != nil {
+		return fmt.Errorf("%w: %q", err, r.Message())
+	}
+	if len(d.bound) == 0 && len(deduped) == len(attrs) && d.policy != DuplicateSuffix {
+		return d.h.Handle(r)
+	}
+	return d.h.Handle(records.WithPC(records.New(r.Time(), r.Level(), r.Message(), deduped...), records.PC(r)))
+}
+
+func (d dedupeHandler) dedupe(attrs []attr.Attr) ([]attr.Attr, error) {
+	seen := make(map[string]int, len(attrs))
+	out := make([]attr.Attr, 0, len(attrs))
+
+	for _, a := range attrs {
+		if a == nil {
+			continue
+		}
+
+		idx, ok := seen[a.Key()]
+		if !ok {
+			seen[a.Key()] = len(out)
+			out = append(out, a)
+			continue
+		}
+
+		switch d.policy {
+		case DuplicateKeepLast:
+			out[idx] = a
+		case DuplicateSuffix:
+			key := a.Key()
+			for n := 1; ; n++ {
+				key = a.Key() + "_" + strconv.Itoa(n)
+				if _, ok := seen[key]; !ok {
+					break
+				}
+			}
+			seen[key] = len(out)
+			out = append(out, attr.New(key, a.Value()))
+		case DuplicateError:
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateKey, a.Key())
+		}
+	}
+
+	return out, nil
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (d dedupeHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, d.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (d dedupeHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, d.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (d dedupeHandler) With(attrs ...attr.Attr) Handler {
+	bound := make([]attr.Attr, 0, len(d.bound)+len(attrs))
+	return dedupeHandler{
+		h:      d.h,
+		policy: d.policy,
+		bound:  append(append(bound, d.bound...), attrs...),
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (d dedupeHandler) WithSource(addSource bool) Handler {
+	return dedupeHandler{
+		h:      d.h.WithSource(addSource),
+		policy: d.policy,
+		bound:  d.bound,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (d dedupeHandler) WithLevel(level level.Leveler) Handler {
+	return dedupeHandler{
+		h:      d.h.WithLevel(level),
+		policy: d.policy,
+		bound:  d.bound,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (d dedupeHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return dedupeHandler{
+		h:      d.h.WithReplaceFn(fn),
+		policy: d.policy,
+		bound:  d.bound,
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestDedupe(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		policy DuplicatePolicy
+		wants  map[string]any
+	}{
+		{
+			name:   "KeepFirst",
+			policy: DuplicateKeepFirst,
+			wants:  map[string]any{"id": "bound", "user": "gopher"},
+		},
+		{
+			name:   "KeepLast",
+			policy: DuplicateKeepLast,
+			wants:  map[string]any{"id": "last", "user": "gopher"},
+		},
+		{
+			name:   "Suffix",
+			policy: DuplicateSuffix,
+			wants:  map[string]any{"id": "bound", "id_1": "call", "id_2": "last", "user": "gopher"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			th := newTestHandler()
+			h := Dedupe(th, test.policy).With(attr.String("id", "bound"))
+
+			err := h.Handle(records.New(time.Now(), level.Info, "message",
+				attr.String("id", "call"),
+				attr.String("user", "gopher"),
+				attr.String("id", "last"),
+			))
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			recs := th.Records()
+			if len(recs) != 1 {
+				t.Errorf("output mismatch error: wanted %v records ; got %v", 1, len(recs))
+				return
+			}
+			got := recs[0].Attrs()
+			if len(got) != len(test.wants) {
+				t.Errorf("output mismatch error: wanted %v ; got %v", test.wants, got)
+				return
+			}
+			for _, a := range got {
+				if test.wants[a.Key()] != a.Value() {
+					t.Errorf("output mismatch error for %s: wanted %v ; got %v", a.Key(), test.wants[a.Key()], a.Value())
+				}
+			}
+		})
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		th := newTestHandler()
+		h := Dedupe(th, DuplicateError)
+
+		err := h.Handle(records.New(time.Now(), level.Info, "message", attr.Int("n", 1), attr.Int("n", 2)))
+		if !errors.Is(err, ErrDuplicateKey) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrDuplicateKey, err)
+		}
+		if len(th.Records()) != 0 {
+			t.Errorf("output mismatch error: wanted no records ; got %v", th.Records())
+		}
+	})
+	t.Run("Unique", func(t *testing.T) {
+		th := newTestHandler()
+		r := records.New(time.Now(), level.Info, "message", attr.Int("a", 1), attr.Int("b", 2))
+
+		if err := Dedupe(th, DuplicateError).Handle(r); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if recs := th.Records(); len(recs) != 1 || recs[0].AttrLen() != 2 {
+			t.Errorf("output mismatch error: wanted the record as-is ; got %v", recs)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+type sortHandler struct {
+	h     Handler
+	bound []attr.Attr
+}
+
+// SortAttrs decorates the Handler `h` so that the attributes of the records it
+// handles are sorted by key, including the ones in groups, for the encodings
+// that keep their order (like texth's and Console's). The attributes bound to
+// the decorator (with With) are merged into each record, so they are sorted
+// along with the record's own attributes
+func SortAttrs(h Handler) Handler {
+	if h == nil {
+		return nil
+	}
+	return sortHandler{h: h}
+}
+
+// Deterministic returns a copy of the Handler `h` whose output is reproducible
+// byte-for-byte, for golden tests and examples: with sorted attributes (see
+// SortAttrs), no source references, and no colors (for Console handlers).
+// Timestamps are left to the records' clock (see logx.TestMode)
+func Deterministic(h Handler) Handler {
+	if h == nil {
+		return nil
+	}
+	if c, ok := h.(consoleHandler); ok {
+		c.theme = Theme{}
+		h = c
+	}
+	return SortAttrs(h.WithSource(false))
+}
+
+// sortAttrs returns a sorted copy of the attributes `attrs`, recursively
+func sortAttrs(attrs []attr.Attr) []attr.Attr {
+	sorted := make([]attr.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if a == nil {
+			continue
+		}
+		if group, ok := a.Value().([]attr.Attr); ok {
+			a = attr.New(a.Key(), sortAttrs(group))
+		}
+		sorted = append(sorted, a)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Key() < sorted[j].Key()
+	})
+	return sorted
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (s sortHandler) Enabled(level level.Level) bool {
+	return s.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (s sortHandler) Handle(r records.Record) error {
+	if !s.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	attrs := make([]attr.Attr, 0, r.AttrLen()+len(s.bound))
+	attrs = append(append(attrs, r.Attrs()...), s.bound...)
+	return s.h.Handle(records.WithPC(records.New(r.Time(), r.Level(), r.Message(), sortAttrs(attrs)...), records.PC(r)))
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (s sortHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, s.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (s sortHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, s.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (s sortHandler) With(attrs ...attr.Attr) Handler {
+	bound := make([]attr.Attr, 0, len(s.bound)+len(attrs))
+	return sortHandler{
+		h:     s.h,
+		bound: append(append(bound, s.bound...), attrs...),
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (s sortHandler) WithSource(addSource bool) Handler {
+	return sortHandler{
+		h:     s.h.WithSource(addSource),
+		bound: s.bound,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (s sortHandler) WithLevel(level level.Leveler) Handler {
+	return sortHandler{
+		h:     s.h.WithLevel(level),
+		bound: s.bound,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (s sortHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return sortHandler{
+		h:     s.h.WithReplaceFn(fn),
+		bound: s.bound,
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestDeterministic(t *testing.T) {
+	b := &bytes.Buffer{}
+	h := Deterministic(Console(b, DarkTheme).WithSource(true)).With(attr.String("service", "api"))
+
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := h.Handle(records.New(ts, level.Info, "request served",
+		attr.Int("status", 200),
+		attr.New("http", []attr.Attr{attr.String("path", "/"), attr.String("method", "GET")}),
+	))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	wants := "00:00:00.000 INFO   request served http.method=GET http.path=/ service=api status=200\n"
+	if b.String() != wants {
+		t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// ECSVersion is the version of the Elastic Common Schema that the ECS handler
+// complies with
+const ECSVersion = "8.11.0"
+
+type ecsHandler struct {
+	w         io.Writer
+	addSource bool
+	source    SourceConfig
+	levelRef  level.Leveler
+	replFn    func(a attr.Attr) attr.Attr
+	attrs     []attr.Attr
+}
+
+// ECS creates a handler that writes records to the io.Writer `w` as
+// newline-delimited JSON objects compliant with the Elastic Common Schema,
+// which Filebeat and Elastic Agent ingest as-is:
+//
+//	{"@timestamp":"...","log.level":"info","message":"...","ecs.version":"8.11.0",...}
+//
+// The records' attributes are added as top-level fields, with groups as nested
+// objects. With WithSource, the caller's file, line and function are added as
+// the `log.origin.*` fields
+func ECS(w io.Writer) Handler {
+	if w == nil {
+		return nil
+	}
+	return ecsHandler{
+		w: w,
+	}
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h ecsHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	b.WriteString(`{"@timestamp":`)
+	b.WriteJSON(r.Time().UTC().Format(time.RFC3339Nano))
+	b.WriteString(`,"log.level":`)
+	if lv := r.Level(); lv != nil {
+		b.WriteJSON(lv.String())
+	} else {
+		b.WriteJSON(level.Info.String())
+	}
+	b.WriteString(`,"message":`)
+	b.WriteJSON(r.Message())
+	b.WriteString(`,"ecs.version":"` + ECSVersion + `"`)
+
+	if h.addSource {
+		if f, ok := Source(r); ok {
+			b.WriteString(`,"log.origin.file.name":`)
+			b.WriteJSON(h.source.file(f, filepath.Base))
+			b.WriteString(`,"log.origin.file.line":`)
+			b.WriteString(strconv.Itoa(f.Line))
+			b.WriteString(`,"log.origin.function":`)
+			b.WriteJSON(f.Function)
+		}
+	}
+
+	writeJSONFields(b, true, h.replFn, nil, r.Attrs())
+	writeJSONFields(b, true, h.replFn, nil, h.attrs)
+	b.WriteString("}\n")
+
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+// Ping implements Pinger, checking the health of the handler's io.Writer
+func (h ecsHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, h.w)
+}
+
+// Shutdown implements Shutdowner, shutting down the handler's io.Writer
+func (h ecsHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, h.w)
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h ecsHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h ecsHandler) With(attrs ...attr.Attr) Handler {
+	return ecsHandler{
+		w:         h.w,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     attrs,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h ecsHandler) WithSource(addSource bool) Handler {
+	return ecsHandler{
+		w:         h.w,
+		addSource: addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// withSourceConfig implements sourceConfigurer
+func (h ecsHandler) withSourceConfig(conf SourceConfig) Handler {
+	h.source = conf
+	return h
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h ecsHandler) WithLevel(level level.Leveler) Handler {
+	return ecsHandler{
+		w:         h.w,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  level,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h ecsHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return ecsHandler{
+		w:         h.w,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    fn,
+		attrs:     h.attrs,
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestECS(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+
+	t.Run("Success", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := ECS(b).With(attr.String("service.name", "api"))
+
+		err := h.Handle(records.New(ts, level.Warn, "slow \"query\"",
+			attr.Int("rows", 42),
+			attr.New("error", errors.New("timeout")),
+			attr.New("http", []attr.Attr{attr.String("method", "GET")}),
+		))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		wants := `{"@timestamp":"2024-01-02T02:04:05Z","log.level":"warn","message":"slow \"query\"","ecs.version":"8.11.0",` +
+			`"rows":42,"error":"timeout","http":{"method":"GET"},"service.name":"api"}` + "\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, b.String())
+		}
+	})
+	t.Run("WithSource", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := ECS(b).WithSource(true)
+
+		_ = h.Handle(records.New(ts, level.Info, "message"))
+
+		var e map[string]any
+		if err := json.Unmarshal(b.Bytes(), &e); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if e["log.origin.file.name"] != "ecs_test.go" || e["log.origin.function"] == "" || e["log.origin.file.line"] == nil {
+			t.Errorf("output mismatch error: wanted the caller's origin ; got %v", e)
+		}
+	})
+	t.Run("WithLevel", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		_ = ECS(b).WithLevel(level.Error).Handle(records.New(ts, level.Info, "skipped"))
+
+		if b.Len() != 0 {
+			t.Errorf("output mismatch error: wanted no output ; got %s", b.String())
+		}
+	})
+	t.Run("NilWriter", func(t *testing.T) {
+		if h := ECS(nil); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+}
@@ -0,0 +1,234 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+// maxFrameSize is the size of the largest encrypted record that is accepted
+// when decrypting, guarding against allocating memory for corrupted lengths
+const maxFrameSize = 16 << 20
+
+var (
+	// ErrInvalidKeyID is raised when creating an encrypting writer with a key ID
+	// longer than 255 bytes
+	ErrInvalidKeyID error = errors.New("key ID must be at most 255 bytes long")
+	// ErrMalformedFrame is raised when decrypting data that is not a valid
+	// sequence of encrypted records
+	ErrMalformedFrame error = errors.New("malformed encrypted record")
+)
+
+// KeyFunc is a function that returns the AES key (of 16, 24 or 32 bytes) for
+// the key ID `keyID`, e.g. by unwrapping a data key with a KMS
+type KeyFunc func(keyID string) ([]byte, error)
+
+// StaticKey returns a KeyFunc that returns `key` for any key ID
+func StaticKey(key []byte) KeyFunc {
+	return func(string) ([]byte, error) {
+		return key, nil
+	}
+}
+
+type encryptWriter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	keyID string
+	aead  cipher.AEAD
+}
+
+// Encrypt creates an io.WriteCloser that encrypts each write with AES-GCM,
+// with the key returned by `keyFn` for `keyID`, before writing it to `w`.
+//
+// As Handlers write each record with a single call, each record is sealed in
+// its own frame: its length, the key ID (also used as additional
+// authenticated data), a random nonce and the ciphertext. Frames are read back
+// with Decrypt. Closing the returned writer closes `w`, if it is an io.Closer
+func Encrypt(w io.Writer, keyID string, keyFn KeyFunc) (io.WriteCloser, error) {
+	if len(keyID) > math.MaxUint8 {
+		return nil, ErrInvalidKeyID
+	}
+
+	aead, err := newAEAD(keyID, keyFn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{
+		w:     w,
+		keyID: keyID,
+		aead:  aead,
+	}, nil
+}
+
+// RotateEncrypted creates an io.WriteCloser that encrypts each record like
+// Encrypt, writing them to a file rotated like with Rotate
+func RotateEncrypted(path string, maxSize int64, maxBackups int, keyID string, keyFn KeyFunc) (io.WriteCloser, error) {
+	f, err := Rotate(path, maxSize, maxBackups)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := Encrypt(f, keyID, keyFn)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func newAEAD(keyID string, keyFn KeyFunc) (cipher.AEAD, error) {
+	key, err := keyFn(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Write implements io.Writer, writing `p` as a single encrypted frame
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var (
+		nonceSize = e.aead.NonceSize()
+		header    = 4 + 1 + len(e.keyID)
+		size      = header + nonceSize + len(p) + e.aead.Overhead()
+		frame     = make([]byte, header+nonceSize, size)
+	)
+
+	binary.BigEndian.PutUint32(frame, uint32(size-4))
+	frame[4] = byte(len(e.keyID))
+	copy(frame[5:], e.keyID)
+
+	nonce := frame[header : header+nonceSize]
+	if _, err := rand.Read(nonce); err != nil {
+		return 0, err
+	}
+	frame = e.aead.Seal(frame, nonce, p, []byte(e.keyID))
+
+	if _, err := e.w.Write(frame); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync implements WriteSyncer, syncing the underlying writer
+func (e *encryptWriter) Sync() error {
+	return AddSync(e.w).Sync()
+}
+
+// Close implements io.Closer, closing the underlying writer if it is an
+// io.Closer
+func (e *encryptWriter) Close() error {
+	if c, ok := e.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Ping implements Pinger, checking the health of the underlying writer
+func (e *encryptWriter) Ping(ctx context.Context) error {
+	return Ping(ctx, e.w)
+}
+
+// Shutdown implements Shutdowner, shutting down the underlying writer
+func (e *encryptWriter) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, e.w)
+}
+
+type decryptReader struct {
+	r     io.Reader
+	keyFn KeyFunc
+	keys  map[string]cipher.AEAD
+	buf   []byte
+	plain []byte
+}
+
+// Decrypt creates an io.Reader that reads the records encrypted by Encrypt
+// from `r`, returning their plaintext. The keys for the frames' key IDs are
+// retrieved with `keyFn`, once per key ID
+func Decrypt(r io.Reader, keyFn KeyFunc) io.Reader {
+	return &decryptReader{
+		r:     r,
+		keyFn: keyFn,
+		keys:  map[string]cipher.AEAD{},
+	}
+}
+
+// Read implements io.Reader
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+func (d *decryptReader) next() error {
+	var length [4]byte
+	if _, err := io.ReadFull(d.r, length[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("%w: truncated length", ErrMalformedFrame)
+		}
+		return err
+	}
+
+	size := binary.BigEndian.Uint32(length[:])
+	if size == 0 || size > maxFrameSize {
+		return fmt.Errorf("%w: invalid length %d", ErrMalformedFrame, size)
+	}
+
+	if cap(d.buf) < int(size) {
+		d.buf = make([]byte, size)
+	}
+	frame := d.buf[:size]
+	if _, err := io.ReadFull(d.r, frame); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedFrame, err)
+	}
+
+	idLen := int(frame[0])
+	if len(frame) < 1+idLen {
+		return fmt.Errorf("%w: truncated key ID", ErrMalformedFrame)
+	}
+	keyID := string(frame[1 : 1+idLen])
+
+	aead, ok := d.keys[keyID]
+	if !ok {
+		var err error
+		if aead, err = newAEAD(keyID, d.keyFn); err != nil {
+			return err
+		}
+		d.keys[keyID] = aead
+	}
+
+	rest := frame[1+idLen:]
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return fmt.Errorf("%w: truncated ciphertext", ErrMalformedFrame)
+	}
+
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedFrame, err)
+	}
+	d.plain = plain
+	return nil
+}
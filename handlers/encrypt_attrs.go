@@ -0,0 +1,349 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// encryptedPrefix leads the values of the attributes encrypted by
+// EncryptAttrs
+const encryptedPrefix = "enc:"
+
+// ErrMalformedValue is raised when decrypting an attribute value that was not
+// encrypted by EncryptAttrs, or was tampered with
+var ErrMalformedValue error = errors.New("malformed encrypted value")
+
+// Keyring holds the AES keys that attribute values are encrypted with by
+// EncryptAttrs: the current one, used for new values, and the previous ones,
+// retrieved with its KeyFunc to decrypt older values. Keys are rotated with
+// Rotate, without rebuilding the Handlers
+type Keyring struct {
+	keyFn KeyFunc
+
+	mu      sync.RWMutex
+	current string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring creates a Keyring encrypting with the key returned by `keyFn`
+// for the key ID `keyID`, returning an error if it cannot be retrieved or is
+// not a valid AES key
+func NewKeyring(keyID string, keyFn KeyFunc) (*Keyring, error) {
+	k := &Keyring{
+		keyFn: keyFn,
+		aeads: map[string]cipher.AEAD{},
+	}
+	if err := k.Rotate(keyID); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Rotate sets the key ID `keyID` as the current one, for the values encrypted
+// from then on. The values encrypted with the previous keys are still
+// decrypted, as long as the KeyFunc returns them
+func (k *Keyring) Rotate(keyID string) error {
+	aead, err := k.aead(keyID)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.current = keyID
+	k.aeads[keyID] = aead
+	return nil
+}
+
+// aead returns the AEAD for the key ID `keyID`, retrieving its key once
+func (k *Keyring) aead(keyID string) (cipher.AEAD, error) {
+	k.mu.RLock()
+	aead, ok := k.aeads[keyID]
+	k.mu.RUnlock()
+	if ok {
+		return aead, nil
+	}
+
+	aead, err := newAEAD(keyID, k.keyFn)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	k.aeads[keyID] = aead
+	k.mu.Unlock()
+	return aead, nil
+}
+
+// seal encrypts the plaintext `plain` of the attribute with key `key`, with
+// the current key, as `enc:<key ID>:<nonce and ciphertext in base64>`
+func (k *Keyring) seal(key string, plain []byte) (string, error) {
+	k.mu.RLock()
+	keyID := k.current
+	aead := k.aeads[keyID]
+	k.mu.RUnlock()
+
+	nonceSize := aead.NonceSize()
+	data := make([]byte, nonceSize, nonceSize+len(plain)+aead.Overhead())
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	data = aead.Seal(data, data[:nonceSize], plain, additionalData(key, keyID))
+
+	return encryptedPrefix + keyID + ":" + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Decrypt returns the plaintext of the value `value` of the attribute with
+// key `key`, as encrypted by EncryptAttrs: the JSON encoding of the original
+// value. An error wrapping ErrMalformedValue is returned if `value` is not an
+// encrypted value, or if it was not encrypted for this key
+func (k *Keyring) Decrypt(key, value string) ([]byte, error) {
+	rest, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %q prefix", ErrMalformedValue, encryptedPrefix)
+	}
+	// the base64 ciphertext holds no colons, unlike the key ID
+	i := strings.LastIndexByte(rest, ':')
+	if i < 0 {
+		return nil, fmt.Errorf("%w: missing key ID", ErrMalformedValue)
+	}
+	keyID, encoded := rest[:i], rest[i+1:]
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedValue, err)
+	}
+
+	aead, err := k.aead(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("%w: truncated ciphertext", ErrMalformedValue)
+	}
+
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additionalData(key, keyID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedValue, err)
+	}
+	return plain, nil
+}
+
+// additionalData binds an encrypted value to its attribute's key and its key
+// ID, so that it cannot be moved to another attribute
+func additionalData(key, keyID string) []byte {
+	return []byte(key + "\x00" + keyID)
+}
+
+type encryptAttrsHandler struct {
+	h    Handler
+	ring *Keyring
+	keys map[string]struct{}
+}
+
+// EncryptAttrs decorates the Handler `h` so that the values of the attributes
+// with the keys `keys` (at any depth, in groups) are encrypted with AES-GCM by
+// the Keyring `ring`, leaving the rest of the record in plaintext. It protects
+// sensitive fields in sinks shared with readers who may not see them:
+//
+//	ring, err := handlers.NewKeyring("2024-01", keyFn)
+//	// (...)
+//	h := handlers.EncryptAttrs(jsonh.New(os.Stdout), ring, "email", "ssn")
+//
+// Each value is replaced with a string holding the key ID and the ciphertext
+// of its JSON encoding, which is read back with Keyring.Decrypt. The
+// attributes bound with its With method are encrypted as well
+func EncryptAttrs(h Handler, ring *Keyring, keys ...string) Handler {
+	if h == nil {
+		return nil
+	}
+	if ring == nil || len(keys) == 0 {
+		return h
+	}
+
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+
+	return encryptAttrsHandler{
+		h:    h,
+		ring: ring,
+		keys: set,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (e encryptAttrsHandler) Enabled(level level.Level) bool {
+	return e.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (e encryptAttrsHandler) Handle(r records.Record) error {
+	if !e.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	attrs, changed, err := e.encryptAttrs(r.Attrs())
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return e.h.Handle(r)
+	}
+	return e.h.Handle(records.WithPC(records.New(r.Time(), r.Level(), r.Message(), attrs...), records.PC(r)))
+}
+
+// encryptAttrs returns the copy of `attrs` with the values of the configured
+// keys encrypted, or `attrs` itself if none were found
+func (e encryptAttrsHandler) encryptAttrs(attrs []attr.Attr) ([]attr.Attr, bool, error) {
+	var out []attr.Attr
+	for i, a := range attrs {
+		encrypted, changed, err := e.encryptAttr(a)
+		if err != nil {
+			return nil, false, err
+		}
+		if !changed {
+			if out != nil {
+				out = append(out, a)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]attr.Attr, i, len(attrs))
+			copy(out, attrs[:i])
+		}
+		out = append(out, encrypted)
+	}
+
+	if out == nil {
+		return attrs, false, nil
+	}
+	return out, true, nil
+}
+
+func (e encryptAttrsHandler) encryptAttr(a attr.Attr) (attr.Attr, bool, error) {
+	if a == nil {
+		return a, false, nil
+	}
+
+	if _, ok := e.keys[a.Key()]; ok {
+		value, err := e.ring.seal(a.Key(), plaintext(a.Value()))
+		if err != nil {
+			return nil, false, err
+		}
+		return attr.String(a.Key(), value), true, nil
+	}
+
+	var group []attr.Attr
+	switch v := a.Value().(type) {
+	case []attr.Attr:
+		group = v
+	case attr.Attrs:
+		group = v
+	default:
+		return a, false, nil
+	}
+
+	encrypted, changed, err := e.encryptAttrs(group)
+	if err != nil || !changed {
+		return a, false, err
+	}
+	return attr.New(a.Key(), encrypted), true, nil
+}
+
+// plaintext returns the JSON encoding of the attribute value `v`
+func plaintext(v any) []byte {
+	switch value := v.(type) {
+	case []attr.Attr:
+		return []byte(AttrsJSON(nil, value))
+	case attr.Attrs:
+		return []byte(AttrsJSON(nil, value))
+	case error:
+		v = value.Error()
+	case time.Duration:
+		v = value.String()
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return data
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (e encryptAttrsHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, e.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (e encryptAttrsHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, e.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`, encrypted. Attributes that fail to be encrypted are dropped
+func (e encryptAttrsHandler) With(attrs ...attr.Attr) Handler {
+	encrypted, _, err := e.encryptAttrs(attrs)
+	if err != nil {
+		encrypted = make([]attr.Attr, 0, len(attrs))
+		for _, a := range attrs {
+			if a, _, err := e.encryptAttr(a); err == nil {
+				encrypted = append(encrypted, a)
+			}
+		}
+	}
+
+	return encryptAttrsHandler{
+		h:    e.h.With(encrypted...),
+		ring: e.ring,
+		keys: e.keys,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (e encryptAttrsHandler) WithSource(addSource bool) Handler {
+	return encryptAttrsHandler{
+		h:    e.h.WithSource(addSource),
+		ring: e.ring,
+		keys: e.keys,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (e encryptAttrsHandler) WithLevel(level level.Leveler) Handler {
+	return encryptAttrsHandler{
+		h:    e.h.WithLevel(level),
+		ring: e.ring,
+		keys: e.keys,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (e encryptAttrsHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return encryptAttrsHandler{
+		h:    e.h.WithReplaceFn(fn),
+		ring: e.ring,
+		keys: e.keys,
+	}
+}
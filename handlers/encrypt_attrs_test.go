@@ -0,0 +1,130 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestEncryptAttrs(t *testing.T) {
+	keys := map[string][]byte{
+		"k1": testKey,
+		"k2": bytes.Repeat([]byte{0x07}, 32),
+	}
+	keyFn := func(keyID string) ([]byte, error) {
+		return keys[keyID], nil
+	}
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		ring, err := NewKeyring("k1", keyFn)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		th := newTestHandler()
+		h := EncryptAttrs(th, ring, "email", "card")
+
+		attrs := []attr.Attr{
+			attr.String("email", "gopher@example.com"),
+			attr.Int("age", 12),
+			attr.New("payment", []attr.Attr{attr.Int("card", 4111)}),
+		}
+		_ = h.Handle(records.New(time.Now(), level.Info, "signup", attrs...))
+
+		got := th.Records()[0].Attrs()
+		email, _ := got[0].Value().(string)
+		if !strings.HasPrefix(email, "enc:k1:") || strings.Contains(email, "gopher") {
+			t.Errorf("output mismatch error: wanted an encrypted value ; got %q", email)
+			return
+		}
+		if got[1] != attrs[1] {
+			t.Errorf("output mismatch error: wanted %v ; got %v", attrs[1], got[1])
+		}
+
+		plain, err := ring.Decrypt("email", email)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if wants := `"gopher@example.com"`; string(plain) != wants {
+			t.Errorf("output mismatch error: wanted %v ; got %s", wants, plain)
+		}
+
+		card, _ := got[2].Value().([]attr.Attr)[0].Value().(string)
+		if plain, err = ring.Decrypt("card", card); err != nil || string(plain) != "4111" {
+			t.Errorf("output mismatch error: wanted %v ; got %s (%v)", 4111, plain, err)
+		}
+	})
+	t.Run("Rotate", func(t *testing.T) {
+		ring, _ := NewKeyring("k1", keyFn)
+		th := newTestHandler()
+		h := EncryptAttrs(th, ring, "token")
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "before", attr.String("token", "a")))
+		if err := ring.Rotate("k2"); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		_ = h.Handle(records.New(time.Now(), level.Info, "after", attr.String("token", "b")))
+
+		for i, wants := range []string{"a", "b"} {
+			value := th.Records()[i].Attrs()[0].Value().(string)
+			if i == 1 && !strings.HasPrefix(value, "enc:k2:") {
+				t.Errorf("output mismatch error: wanted the rotated key ; got %q", value)
+			}
+			plain, err := ring.Decrypt("token", value)
+			if err != nil || string(plain) != `"`+wants+`"` {
+				t.Errorf("output mismatch error: wanted %q ; got %s (%v)", wants, plain, err)
+			}
+		}
+	})
+	t.Run("WrongKey", func(t *testing.T) {
+		ring, _ := NewKeyring("k1", keyFn)
+		th := newTestHandler()
+		_ = EncryptAttrs(th, ring, "email").Handle(records.New(time.Now(), level.Info, "msg",
+			attr.String("email", "gopher@example.com")))
+
+		value := th.Records()[0].Attrs()[0].Value().(string)
+		if _, err := ring.Decrypt("user", value); !errors.Is(err, ErrMalformedValue) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", ErrMalformedValue, err)
+		}
+		if _, err := ring.Decrypt("email", "plain"); !errors.Is(err, ErrMalformedValue) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", ErrMalformedValue, err)
+		}
+	})
+	t.Run("Untouched", func(t *testing.T) {
+		ring, _ := NewKeyring("k1", keyFn)
+		th := newTestHandler()
+		attrs := []attr.Attr{attr.String("user", "gopher")}
+
+		_ = EncryptAttrs(th, ring, "email").Handle(records.New(time.Now(), level.Info, "msg", attrs...))
+
+		if got := th.Records()[0]; got.Attrs()[0] != attrs[0] {
+			t.Errorf("output mismatch error: wanted the record untouched ; got %v", got)
+		}
+	})
+	t.Run("With", func(t *testing.T) {
+		ring, _ := NewKeyring("k1", keyFn)
+		bound := &[]attr.Attr{}
+		h := EncryptAttrs(withHandler{testHandler: newTestHandler(), bound: bound}, ring, "email")
+
+		_ = h.With(attr.String("email", "gopher@example.com"))
+
+		if value, _ := (*bound)[0].Value().(string); !strings.HasPrefix(value, "enc:k1:") {
+			t.Errorf("output mismatch error: wanted the bound attribute encrypted ; got %v", *bound)
+		}
+	})
+	t.Run("InvalidKey", func(t *testing.T) {
+		if _, err := NewKeyring("k3", keyFn); err == nil {
+			t.Errorf("output mismatch error: wanted an error ; got %v", err)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testKey = bytes.Repeat([]byte{0x2a}, 32)
+
+func TestEncrypt(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		w, err := Encrypt(b, "key-1", StaticKey(testKey))
+		if err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+
+		for _, rec := range []string{`{"message":"first"}`, `{"message":"second"}`} {
+			if _, err := w.Write([]byte(rec)); err != nil {
+				t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+				return
+			}
+		}
+		if bytes.Contains(b.Bytes(), []byte("first")) {
+			t.Errorf("output mismatch error: wanted ciphertext ; got %q", b.String())
+		}
+
+		out, err := io.ReadAll(Decrypt(b, StaticKey(testKey)))
+		if err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+		if wants := `{"message":"first"}{"message":"second"}`; string(out) != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, out)
+		}
+	})
+	t.Run("KeyRotation", func(t *testing.T) {
+		var (
+			b    = &bytes.Buffer{}
+			keys = map[string][]byte{
+				"old": testKey,
+				"new": bytes.Repeat([]byte{0x07}, 16),
+			}
+			calls  int
+			keyFn  = func(id string) ([]byte, error) { calls++; return keys[id], nil }
+			old, _ = Encrypt(b, "old", keyFn)
+			cur, _ = Encrypt(b, "new", keyFn)
+		)
+		_, _ = old.Write([]byte("a"))
+		_, _ = cur.Write([]byte("b"))
+		_, _ = old.Write([]byte("c"))
+
+		calls = 0
+		out, err := io.ReadAll(Decrypt(b, keyFn))
+		if err != nil || string(out) != "abc" {
+			t.Errorf("output mismatch error: wanted %q and %v ; got %q and %v", "abc", nil, out, err)
+		}
+		if calls != 2 {
+			t.Errorf("output mismatch error: wanted %v key lookups ; got %v", 2, calls)
+		}
+	})
+	t.Run("WrongKey", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		w, _ := Encrypt(b, "key-1", StaticKey(testKey))
+		_, _ = w.Write([]byte("secret"))
+
+		_, err := io.ReadAll(Decrypt(b, StaticKey(bytes.Repeat([]byte{0x01}, 32))))
+		if !errors.Is(err, ErrMalformedFrame) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrMalformedFrame, err)
+		}
+	})
+	t.Run("Truncated", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		w, _ := Encrypt(b, "key-1", StaticKey(testKey))
+		_, _ = w.Write([]byte("secret"))
+
+		_, err := io.ReadAll(Decrypt(bytes.NewReader(b.Bytes()[:b.Len()-3]), StaticKey(testKey)))
+		if !errors.Is(err, ErrMalformedFrame) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrMalformedFrame, err)
+		}
+	})
+	t.Run("InvalidKey", func(t *testing.T) {
+		if _, err := Encrypt(&bytes.Buffer{}, "key-1", StaticKey([]byte("short"))); err == nil {
+			t.Errorf("unexpected error: wanted an error ; got %v", err)
+		}
+	})
+	t.Run("RotateEncrypted", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log.enc")
+		w, err := RotateEncrypted(path, 1024, 1, "key-1", StaticKey(testKey))
+		if err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+		_, _ = w.Write([]byte("record"))
+		if err := w.Close(); err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+		defer f.Close()
+
+		out, err := io.ReadAll(Decrypt(f, StaticKey(testKey)))
+		if err != nil || string(out) != "record" {
+			t.Errorf("output mismatch error: wanted %q and %v ; got %q and %v", "record", nil, out, err)
+		}
+	})
+}
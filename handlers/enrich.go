@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// Enricher is implemented by providers of attributes to add to every record,
+// like the process, build, container or Kubernetes metadata, or custom ones
+// (a deployment's region, a feature flag's state). Enrichers are combined
+// into a Handler decorator with Enrich.
+//
+// An Enricher is called for every record by default; wrap it with Static to
+// resolve its attributes once, or with Cached to refresh them periodically.
+// The metadata Enrichers (ProcessInfo, BuildInfo, ContainerInfo and
+// KubernetesInfo) are static: their attributes are resolved once, when they
+// are created (like by the WithProcessInfo decorator), and reused on every
+// record
+type Enricher interface {
+	// Attrs returns the attributes to add to a record, or nil if there are
+	// none
+	Attrs(ctx context.Context) []attr.Attr
+}
+
+// EnricherFunc is a function that implements Enricher
+type EnricherFunc func(ctx context.Context) []attr.Attr
+
+// Attrs implements Enricher, calling the EnricherFunc
+func (fn EnricherFunc) Attrs(ctx context.Context) []attr.Attr {
+	return fn(ctx)
+}
+
+type staticEnricher struct {
+	attrs []attr.Attr
+}
+
+// Static returns an Enricher with the attributes of the Enricher `e`, resolved
+// once when Static is called. Its attributes are added to records with no
+// overhead beyond the Handler's own
+func Static(e Enricher) Enricher {
+	if e == nil {
+		return staticEnricher{}
+	}
+	if s, ok := e.(staticEnricher); ok {
+		return s
+	}
+	return staticEnricher{attrs: e.Attrs(context.Background())}
+}
+
+// Attrs implements Enricher, returning the resolved attributes
+func (s staticEnricher) Attrs(context.Context) []attr.Attr {
+	return s.attrs
+}
+
+type cachedEnricher struct {
+	e   Enricher
+	ttl time.Duration
+
+	mu      sync.Mutex
+	attrs   []attr.Attr
+	expires time.Time
+}
+
+// Cached returns an Enricher that calls the Enricher `e` at most once every
+// `ttl`, reusing its attributes in the meantime. This suits metadata that
+// changes rarely but is costly to read, like a file's contents
+func Cached(e Enricher, ttl time.Duration) Enricher {
+	if e == nil {
+		return staticEnricher{}
+	}
+	return &cachedEnricher{
+		e:   e,
+		ttl: ttl,
+	}
+}
+
+// Attrs implements Enricher, returning the cached attributes, or refreshing
+// them once expired
+func (c *cachedEnricher) Attrs(ctx context.Context) []attr.Attr {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now := time.Now(); now.After(c.expires) {
+		c.attrs = c.e.Attrs(ctx)
+		c.expires = now.Add(c.ttl)
+	}
+	return c.attrs
+}
+
+type enrichHandler struct {
+	h         Handler
+	attrs     []attr.Attr
+	enrichers []Enricher
+	origin    string
+}
+
+// Enrich decorates the Handler `h` so that every Record it handles carries the
+// attributes of the Enrichers `enrichers`.
+//
+// Static Enrichers are resolved into a fixed set of attributes when the
+// decorator is created, which precede the attributes of the others (in the
+// order they are set). They are added to each Record rather than bound with
+// the With method of `h`, which would replace the attributes already bound to
+// it. The others are called on every Record (with an
+// empty context, as records do not carry one; use a logx.Extractor for
+// attributes held in a request's context). If there are no attributes to add,
+// the Handler `h` is returned as-is.
+//
+// With provenance tracking enabled (see TrackProvenance), the attributes are
+// marked as attached by Enrich, at its call site
+func Enrich(h Handler, enrichers ...Enricher) Handler {
+	if h == nil {
+		return nil
+	}
+
+	var (
+		attrs   []attr.Attr
+		dynamic []Enricher
+	)
+	for _, e := range enrichers {
+		switch v := e.(type) {
+		case nil:
+		case staticEnricher:
+			attrs = append(attrs, v.attrs...)
+		default:
+			dynamic = append(dynamic, e)
+		}
+	}
+
+	if len(attrs) == 0 && len(dynamic) == 0 {
+		return h
+	}
+
+	origin := callerOrigin("Enrich", 1)
+	return enrichHandler{
+		h:         h,
+		attrs:     Traced(origin, attrs...),
+		enrichers: dynamic,
+		origin:    origin,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (e enrichHandler) Enabled(level level.Level) bool {
+	return e.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (e enrichHandler) Handle(r records.Record) error {
+	if len(e.enrichers) == 0 {
+		return e.h.Handle(r.AddAttr(e.attrs...))
+	}
+	if !e.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	attrs := e.attrs[:len(e.attrs):len(e.attrs)]
+	for _, enricher := range e.enrichers {
+		attrs = append(attrs, Traced(e.origin, enricher.Attrs(context.Background())...)...)
+	}
+	return e.h.Handle(r.AddAttr(attrs...))
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (e enrichHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, e.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (e enrichHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, e.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (e enrichHandler) With(attrs ...attr.Attr) Handler {
+	return enrichHandler{
+		h:         e.h.With(attrs...),
+		attrs:     e.attrs,
+		enrichers: e.enrichers,
+		origin:    e.origin,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (e enrichHandler) WithSource(addSource bool) Handler {
+	return enrichHandler{
+		h:         e.h.WithSource(addSource),
+		attrs:     e.attrs,
+		enrichers: e.enrichers,
+		origin:    e.origin,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (e enrichHandler) WithLevel(level level.Leveler) Handler {
+	return enrichHandler{
+		h:         e.h.WithLevel(level),
+		attrs:     e.attrs,
+		enrichers: e.enrichers,
+		origin:    e.origin,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (e enrichHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return enrichHandler{
+		h:         e.h.WithReplaceFn(fn),
+		attrs:     e.attrs,
+		enrichers: e.enrichers,
+		origin:    e.origin,
+	}
+}
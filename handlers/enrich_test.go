@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestEnrich(t *testing.T) {
+	// counter returns an Enricher adding the number of times it was called
+	counter := func() Enricher {
+		n := &atomic.Int64{}
+		return EnricherFunc(func(context.Context) []attr.Attr {
+			return []attr.Attr{attr.Int("calls", n.Add(1))}
+		})
+	}
+	handle := func(t *testing.T, h Handler, times int) []records.Record {
+		for i := 0; i < times; i++ {
+			if err := h.Handle(records.New(time.Now(), level.Info, "test message")); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+		return h.(enrichHandler).h.(testHandler).Records()
+	}
+
+	t.Run("Modes", func(t *testing.T) {
+		th := newTestHandler()
+		h := Enrich(th,
+			Static(counter()),
+			counter(),
+			Cached(counter(), time.Hour),
+			staticEnricher{attrs: []attr.Attr{attr.String("region", "eu")}},
+		)
+
+		recs := handle(t, h, 3)
+		wants := []attr.Attr{
+			attr.Int("calls", int64(1)),
+			attr.String("region", "eu"),
+			attr.Int("calls", int64(3)),
+			attr.Int("calls", int64(1)),
+		}
+		if got := recs[2].Attrs(); !reflect.DeepEqual(wants, got) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, got)
+		}
+	})
+	t.Run("BoundHandler", func(t *testing.T) {
+		h := Enrich(newTestHandler().With(attr.String("app", "x")),
+			staticEnricher{attrs: []attr.Attr{attr.String("region", "eu")}},
+		)
+
+		recs := handle(t, h, 1)
+		if got := attr.Map(recs[0].Attrs()...); got["app"] != "x" || got["region"] != "eu" {
+			t.Errorf("output mismatch error: wanted the bound and static attributes ; got %v", got)
+		}
+	})
+	t.Run("CachedRefresh", func(t *testing.T) {
+		e := Cached(counter(), time.Millisecond)
+
+		_ = e.Attrs(context.Background())
+		time.Sleep(5 * time.Millisecond)
+		if got := attr.Map(e.Attrs(context.Background())...)["calls"]; got != int64(2) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 2, got)
+		}
+	})
+	t.Run("Empty", func(t *testing.T) {
+		th := newTestHandler()
+		if h := Enrich(th, nil, Static(EnricherFunc(func(context.Context) []attr.Attr { return nil }))); !reflect.DeepEqual(h, Handler(th)) {
+			t.Errorf("expected the Handler to be returned as-is")
+		}
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		th := newTestHandler()
+		e := counter()
+		h := Enrich(th, e).WithLevel(level.Warn)
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "test message"))
+		if got := attr.Map(e.Attrs(context.Background())...)["calls"]; got != int64(1) {
+			t.Errorf("expected the Enricher not to be called for disabled records")
+		}
+	})
+	t.Run("Nil", func(t *testing.T) {
+		if Enrich(nil, counter()) != nil {
+			t.Errorf("expected output to be nil")
+		}
+	})
+}
@@ -0,0 +1,397 @@
+//go:build !logx_tiny
+
+// Package fluenth provides a batching handler that sends records to Fluentd or
+// Fluent Bit with the Forward protocol, over TCP or a Unix socket, so they feed
+// an existing fluent aggregation pipeline without scraping stdout:
+//
+//	h, err := fluenth.New(fluenth.Config{
+//		Addr: "localhost:24224",
+//		Tag:  "app.api",
+//		Ack:  true,
+//	})
+//
+// Records are encoded as MessagePack maps with the `message`, `level` and
+// `source` (if enabled with WithSource) keys besides their attributes, with
+// their timestamp as an EventTime. Batches are sent in the PackedForward mode.
+//
+// With Config.Ack, each batch is sent with a chunk ID and is only acknowledged
+// once the server replies with it (the `require_ack_response` option). The
+// security handshake (with a shared key) is not supported
+package fluenth
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	defaultAddr          = "localhost:24224"
+	defaultTimeout       = 5 * time.Second
+	defaultBatchInterval = time.Second
+	defaultBatchSize     = 1 << 20 // 1 MiB
+)
+
+var (
+	// ErrNoTag is raised when creating a handler without a tag
+	ErrNoTag error = errors.New("no tag provided")
+	// ErrAck is raised when the server replies with an unexpected
+	// acknowledgment
+	ErrAck error = errors.New("unexpected acknowledgment")
+)
+
+// Config describes the Forward server and the tag of the records' events
+type Config struct {
+	// Network is the network of the server's address, `tcp` (the default) or
+	// `unix`
+	Network string
+	// Addr is the address of the server, `localhost:24224` by default, or the
+	// path to its socket with the `unix` network
+	Addr string
+	// TLS configures the connection with TLS, if set
+	TLS *handlers.TLSConfig
+	// Timeout bounds connecting to the server, and sending each batch
+	// (including its acknowledgment), five seconds by default
+	Timeout time.Duration
+
+	// Tag is the events' tag, routing them in the server's configuration
+	Tag string
+	// Ack requests an acknowledgment for every batch, which is sent again on
+	// the following interval if it is not acknowledged in time
+	Ack bool
+
+	// BatchInterval is how often batches are sent, one second by default
+	BatchInterval time.Duration
+	// BatchSize is the size (in bytes) from which a batch is sent before its
+	// interval, 1 MiB by default
+	BatchSize int
+}
+
+type fluentHandler struct {
+	w         io.Writer
+	addSource bool
+	levelRef  level.Leveler
+	replFn    func(a attr.Attr) attr.Attr
+	attrs     []attr.Attr
+}
+
+// New creates a handler that sends records to the Forward server described by
+// the Config `conf`, in batches. Failed batches are retried on the following
+// intervals (see handlers.AckedBatch), and any buffered records are sent on
+// Shutdown
+func New(conf Config) (handlers.Handler, error) {
+	w, err := newWriter(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := conf.BatchInterval
+	if interval <= 0 {
+		interval = defaultBatchInterval
+	}
+	size := conf.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	return fluentHandler{
+		w: handlers.AckedBatch(w, interval, size),
+	}, nil
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h fluentHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	lv := r.Level()
+	if lv == nil {
+		lv = level.Info
+	}
+
+	fields := 2
+	var source string
+	if h.addSource {
+		if f, ok := handlers.Source(r); ok {
+			source = f.File + ":" + strconv.Itoa(f.Line)
+			fields++
+		}
+	}
+	attrs, n := appendAttrs(nil, h.replFn, r.Attrs(), h.attrs)
+
+	// each entry is a [time, record] array
+	b := appendArrayHeader(make([]byte, 0, 64+len(attrs)), 2)
+	b = appendEventTime(b, r.Time())
+	b = appendMapHeader(b, fields+n)
+	b = appendString(appendString(b, "message"), r.Message())
+	b = appendString(appendString(b, "level"), lv.String())
+	if source != "" {
+		b = appendString(appendString(b, "source"), source)
+	}
+	b = append(b, attrs...)
+
+	_, err := h.w.Write(b)
+	return err
+}
+
+// writer sends the batches of entries to the server, as PackedForward
+// messages
+type writer struct {
+	mu     sync.Mutex
+	conf   Config
+	conn   net.Conn
+	r      *bufio.Reader
+	closed bool
+}
+
+func newWriter(conf Config) (*writer, error) {
+	if conf.Tag == "" {
+		return nil, ErrNoTag
+	}
+	if conf.Network == "" {
+		conf.Network = "tcp"
+	}
+	if conf.Addr == "" {
+		conf.Addr = defaultAddr
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = defaultTimeout
+	}
+	return &writer{conf: conf}, nil
+}
+
+// Write implements io.Writer, sending the entries in `p` in a message
+func (w *writer) Write(p []byte) (int, error) {
+	return w.WriteContext(context.Background(), p)
+}
+
+// WriteContext implements handlers.ContextWriter, sending the entries in `p`
+// in a message within the deadline of the context `ctx`. With Ack, the batch's
+// idempotency key (see handlers.IdempotencyKey) is its chunk ID, if set
+func (w *writer) WriteContext(ctx context.Context, p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	deadline, ok := ctx.Deadline()
+	if limit := time.Now().Add(w.conf.Timeout); !ok || limit.Before(deadline) {
+		deadline = limit
+	}
+
+	if w.conn == nil {
+		if err := w.connect(ctx, deadline); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := w.send(ctx, deadline, p); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync implements handlers.WriteSyncer, as a no-op since batches are sent as
+// they are written
+func (w *writer) Sync() error {
+	return nil
+}
+
+func (w *writer) connect(ctx context.Context, deadline time.Time) error {
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if w.conf.TLS != nil {
+		conn, err = handlers.DialTLS(ctx, w.conf.Network, w.conf.Addr, *w.conf.TLS)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, w.conf.Network, w.conf.Addr)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.conn = conn
+	w.r = bufio.NewReader(conn)
+	return nil
+}
+
+// send writes the PackedForward message with the entries in `p`, and reads
+// its acknowledgment with Ack
+func (w *writer) send(ctx context.Context, deadline time.Time, p []byte) error {
+	if err := w.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	fields := 2
+	var chunk string
+	if w.conf.Ack {
+		var err error
+		if chunk, err = chunkID(ctx); err != nil {
+			return err
+		}
+		fields++
+	}
+
+	b := appendArrayHeader(make([]byte, 0, len(p)+len(w.conf.Tag)+64), fields)
+	b = appendString(b, w.conf.Tag)
+	b = appendBin(b, p)
+	if w.conf.Ack {
+		b = appendMapHeader(b, 1)
+		b = appendString(appendString(b, "chunk"), chunk)
+	}
+
+	if _, err := w.conn.Write(b); err != nil {
+		return err
+	}
+	if !w.conf.Ack {
+		return nil
+	}
+
+	reply, err := readValue(w.r)
+	if err != nil {
+		return err
+	}
+	if m, ok := reply.(map[string]any); !ok || m["ack"] != chunk {
+		return fmt.Errorf("%w: %v", ErrAck, reply)
+	}
+	return nil
+}
+
+// chunkID returns the chunk ID of a batch, as its idempotency key in the
+// context `ctx` or a random one
+func chunkID(ctx context.Context) (string, error) {
+	if key, ok := handlers.IdempotencyKey(ctx); ok {
+		return key, nil
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(id), nil
+}
+
+// Ping implements handlers.Pinger, connecting to the server if needed
+func (w *writer) Ping(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch {
+	case w.closed:
+		return os.ErrClosed
+	case w.conn != nil:
+		return nil
+	}
+	return w.connect(ctx, time.Now().Add(w.conf.Timeout))
+}
+
+// Shutdown implements handlers.Shutdowner, closing the connection
+func (w *writer) Shutdown(context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// Capabilities implements handlers.Capable, returning CapGroups and CapBinary,
+// along with CapFlush and CapRemote as records are sent in batches
+func (h fluentHandler) Capabilities() handlers.Capability {
+	return handlers.CapGroups | handlers.CapBinary | handlers.CapFlush | handlers.CapRemote
+}
+
+// Ping implements handlers.Pinger, checking the health of the batch writer
+func (h fluentHandler) Ping(ctx context.Context) error {
+	return handlers.Ping(ctx, h.w)
+}
+
+// Shutdown implements handlers.Shutdowner, sending any buffered records
+func (h fluentHandler) Shutdown(ctx context.Context) error {
+	return handlers.Shutdown(ctx, h.w)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h fluentHandler) With(attrs ...attr.Attr) handlers.Handler {
+	return fluentHandler{
+		w:         h.w,
+		addSource: h.addSource,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     attrs,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h fluentHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h fluentHandler) WithSource(addSource bool) handlers.Handler {
+	return fluentHandler{
+		w:         h.w,
+		addSource: addSource,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h fluentHandler) WithLevel(level level.Leveler) handlers.Handler {
+	return fluentHandler{
+		w:         h.w,
+		addSource: h.addSource,
+		levelRef:  level,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h fluentHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) handlers.Handler {
+	return fluentHandler{
+		w:         h.w,
+		addSource: h.addSource,
+		levelRef:  h.levelRef,
+		replFn:    fn,
+		attrs:     h.attrs,
+	}
+}
@@ -0,0 +1,206 @@
+//go:build !logx_tiny
+
+package fluenth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// event is an entry of a PackedForward message received by the fake server
+type event struct {
+	tag    string
+	time   time.Time
+	record map[string]any
+}
+
+// server is a fake Forward server recording the events it receives
+type server struct {
+	ln net.Listener
+	// ack is the reply to acknowledgment requests, or the chunk ID if empty
+	ack string
+
+	mu     sync.Mutex
+	events []event
+}
+
+func newServer(t *testing.T, network, addr string) *server {
+	ln, err := net.Listen(network, addr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := &server{ln: ln}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *server) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for {
+		msg, err := readValue(r)
+		if err != nil {
+			return
+		}
+		fields := msg.([]any)
+		tag := fields[0].(string)
+
+		entries := bufio.NewReader(bytes.NewReader(fields[1].([]byte)))
+		for {
+			entry, err := readValue(entries)
+			if err != nil {
+				break
+			}
+			values := entry.([]any)
+			s.mu.Lock()
+			s.events = append(s.events, event{tag: tag, time: values[0].(time.Time), record: values[1].(map[string]any)})
+			s.mu.Unlock()
+		}
+
+		if len(fields) == 3 {
+			chunk := fields[2].(map[string]any)["chunk"].(string)
+			if s.ack != "" {
+				chunk = s.ack
+			}
+			_, _ = conn.Write(appendString(appendString(appendMapHeader(nil, 1), "ack"), chunk))
+		}
+	}
+}
+
+func (s *server) received() []event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]event(nil), s.events...)
+}
+
+// wait waits for the server to receive `n` events, as they are not
+// acknowledged without Ack
+func (s *server) wait(n int) []event {
+	deadline := time.Now().Add(time.Second)
+	for len(s.received()) < n && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return s.received()
+}
+
+func TestHandler(t *testing.T) {
+	for _, testcase := range []struct {
+		name    string
+		network string
+		ack     bool
+	}{
+		{"TCP", "tcp", false},
+		{"TCPAck", "tcp", true},
+		{"Unix", "unix", false},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			addr := "127.0.0.1:0"
+			if testcase.network == "unix" {
+				addr = filepath.Join(t.TempDir(), "fluent.sock")
+			}
+			srv := newServer(t, testcase.network, addr)
+
+			h, err := New(Config{
+				Network:       testcase.network,
+				Addr:          srv.ln.Addr().String(),
+				Tag:           "app.api",
+				Ack:           testcase.ack,
+				BatchInterval: time.Hour,
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			h = h.With(attr.String("service", "api"))
+
+			ts := time.Unix(1700000000, 123456789)
+			for _, msg := range []string{"started", "served"} {
+				r := records.New(ts, level.Warn, msg,
+					attr.Int("status", 200), attr.New("req", []attr.Attr{attr.String("path", "/")}))
+				if err := h.Handle(r); err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+			}
+			if err := handlers.Shutdown(context.Background(), h); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			events := srv.wait(2)
+			if len(events) != 2 {
+				t.Errorf("output mismatch error: wanted %v ; got %v", 2, len(events))
+				return
+			}
+			e := events[1]
+			if e.tag != "app.api" || !e.time.Equal(ts) {
+				t.Errorf("output mismatch error: got tag %q and time %v", e.tag, e.time)
+			}
+			for key, wants := range map[string]any{
+				"message": "served",
+				"level":   "warn",
+				"status":  int64(200),
+				"service": "api",
+			} {
+				if e.record[key] != wants {
+					t.Errorf("output mismatch error: %s: wanted %v ; got %v", key, wants, e.record[key])
+				}
+			}
+			if req, ok := e.record["req"].(map[string]any); !ok || req["path"] != "/" {
+				t.Errorf("output mismatch error: got group %v", e.record["req"])
+			}
+		})
+	}
+
+	t.Run("WrongAck", func(t *testing.T) {
+		srv := newServer(t, "tcp", "127.0.0.1:0")
+		srv.ack = "other"
+		w, _ := newWriter(Config{Addr: srv.ln.Addr().String(), Tag: "app", Ack: true})
+		defer w.Shutdown(context.Background())
+
+		entry := appendMapHeader(appendEventTime(appendArrayHeader(nil, 2), time.Now()), 0)
+		if _, err := w.Write(entry); !errors.Is(err, ErrAck) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrAck, err)
+		}
+	})
+	t.Run("Ping", func(t *testing.T) {
+		srv := newServer(t, "tcp", "127.0.0.1:0")
+		w, _ := newWriter(Config{Addr: srv.ln.Addr().String(), Tag: "app"})
+
+		if err := handlers.Ping(context.Background(), w); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		_ = w.Shutdown(context.Background())
+		if err := handlers.Ping(context.Background(), w); err == nil {
+			t.Errorf("expected an error after shutdown")
+		}
+	})
+	t.Run("NoTag", func(t *testing.T) {
+		if _, err := New(Config{}); !errors.Is(err, ErrNoTag) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrNoTag, err)
+		}
+	})
+}
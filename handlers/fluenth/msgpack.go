@@ -0,0 +1,345 @@
+//go:build !logx_tiny
+
+package fluenth
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/zalgonoise/attr"
+)
+
+// extEventTime is the MessagePack extension type of the Forward protocol's
+// EventTime
+const extEventTime = 0
+
+// errMsgpack is raised when decoding an invalid or unsupported MessagePack
+// value
+var errMsgpack = errors.New("invalid MessagePack value")
+
+func appendNil(b []byte) []byte {
+	return append(b, 0xc0)
+}
+
+func appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xc3)
+	}
+	return append(b, 0xc2)
+}
+
+func appendInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+	}
+}
+
+func appendUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+	}
+}
+
+func appendFloat(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v))
+}
+
+func appendString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n <= 31:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func appendBin(b []byte, p []byte) []byte {
+	switch n := len(p); {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, p...)
+}
+
+func appendArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+	}
+}
+
+func appendMapHeader(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+	}
+}
+
+// appendEventTime appends the time `t` as an EventTime, a fixext8 with the
+// seconds and nanoseconds since the Unix epoch
+func appendEventTime(b []byte, t time.Time) []byte {
+	b = append(b, 0xd7, extEventTime)
+	b = binary.BigEndian.AppendUint32(b, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+}
+
+// appendAttrs appends the attributes in `lists` as map entries, passed through
+// the replace function `replFn` if set, returning the number of entries
+func appendAttrs(b []byte, replFn func(attr.Attr) attr.Attr, lists ...[]attr.Attr) ([]byte, int) {
+	var n int
+	for _, attrs := range lists {
+		for _, a := range attrs {
+			if replFn != nil && a != nil {
+				a = replFn(a)
+			}
+			if a == nil {
+				continue
+			}
+
+			b = appendString(b, a.Key())
+			b = appendValue(b, replFn, a.Value())
+			n++
+		}
+	}
+	return b, n
+}
+
+// appendValue appends the attribute value `v`, with groups as nested maps
+func appendValue(b []byte, replFn func(attr.Attr) attr.Attr, v any) []byte {
+	switch v := v.(type) {
+	case nil:
+		return appendNil(b)
+	case []attr.Attr:
+		group, n := appendAttrs(nil, replFn, v)
+		return append(appendMapHeader(b, n), group...)
+	case string:
+		return appendString(b, v)
+	case bool:
+		return appendBool(b, v)
+	case int:
+		return appendInt(b, int64(v))
+	case int8:
+		return appendInt(b, int64(v))
+	case int16:
+		return appendInt(b, int64(v))
+	case int32:
+		return appendInt(b, int64(v))
+	case int64:
+		return appendInt(b, v)
+	case uint:
+		return appendUint(b, uint64(v))
+	case uint8:
+		return appendUint(b, uint64(v))
+	case uint16:
+		return appendUint(b, uint64(v))
+	case uint32:
+		return appendUint(b, uint64(v))
+	case uint64:
+		return appendUint(b, v)
+	case uintptr:
+		return appendUint(b, uint64(v))
+	case float32:
+		return appendFloat(b, float64(v))
+	case float64:
+		return appendFloat(b, v)
+	case []byte:
+		return appendBin(b, v)
+	case time.Time:
+		return appendString(b, v.Format(time.RFC3339Nano))
+	case time.Duration:
+		return appendString(b, v.String())
+	case error:
+		return appendString(b, v.Error())
+	case fmt.Stringer:
+		return appendString(b, v.String())
+	default:
+		return appendString(b, fmt.Sprint(v))
+	}
+}
+
+// readValue reads a MessagePack value from `r`, as nil, a bool, an int64 (or a
+// uint64 beyond its range), a float64, a string, a []byte, a []any, a
+// map[string]any, or a time.Time for an EventTime
+func readValue(r *bufio.Reader) (any, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return readMap(r, int(c&0x0f))
+	case c&0xf0 == 0x90:
+		return readArray(r, int(c&0x0f))
+	case c&0xe0 == 0xa0:
+		data, err := readN(r, int(c&0x1f))
+		return string(data), err
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readLen(r, 1<<(c-0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return readN(r, n)
+	case 0xcb:
+		data, err := readN(r, 8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		data, err := readN(r, 1<<(c-0xcc))
+		if err != nil {
+			return nil, err
+		}
+		v := readUint(data)
+		if v > math.MaxInt64 {
+			return v, nil
+		}
+		return int64(v), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		data, err := readN(r, 1<<(c-0xd0))
+		if err != nil {
+			return nil, err
+		}
+		v := readUint(data)
+		shift := 64 - 8*len(data)
+		return int64(v<<shift) >> shift, nil
+	case 0xd7:
+		data, err := readN(r, 9)
+		if err != nil {
+			return nil, err
+		}
+		if data[0] != extEventTime {
+			return nil, fmt.Errorf("%w: extension type %d", errMsgpack, data[0])
+		}
+		return time.Unix(int64(binary.BigEndian.Uint32(data[1:])), int64(binary.BigEndian.Uint32(data[5:]))), nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := readLen(r, 1<<(c-0xd9))
+		if err != nil {
+			return nil, err
+		}
+		data, err := readN(r, n)
+		return string(data), err
+	case 0xdc, 0xdd:
+		n, err := readLen(r, 2<<(c-0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return readArray(r, n)
+	case 0xde, 0xdf:
+		n, err := readLen(r, 2<<(c-0xde))
+		if err != nil {
+			return nil, err
+		}
+		return readMap(r, n)
+	default:
+		return nil, fmt.Errorf("%w: type 0x%02x", errMsgpack, c)
+	}
+}
+
+func readArray(r *bufio.Reader, n int) ([]any, error) {
+	values := make([]any, n)
+	for i := range values {
+		var err error
+		if values[i], err = readValue(r); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func readMap(r *bufio.Reader, n int) (map[string]any, error) {
+	values := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		key, err := readValue(r)
+		if err != nil {
+			return nil, err
+		}
+		k, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: non-string map key", errMsgpack)
+		}
+		if values[k], err = readValue(r); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// readLen reads a big-endian length of `size` bytes
+func readLen(r *bufio.Reader, size int) (int, error) {
+	data, err := readN(r, size)
+	if err != nil {
+		return 0, err
+	}
+	return int(readUint(data)), nil
+}
+
+func readN(r *bufio.Reader, n int) ([]byte, error) {
+	data := make([]byte, n)
+	_, err := io.ReadFull(r, data)
+	return data, err
+}
+
+func readUint(data []byte) uint64 {
+	var v uint64
+	for _, c := range data {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
@@ -0,0 +1,54 @@
+//go:build !logx_tiny
+
+package fluenth
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMsgpack(t *testing.T) {
+	for _, testcase := range []struct {
+		name  string
+		enc   func([]byte) []byte
+		wants any
+	}{
+		{"Nil", appendNil, nil},
+		{"Bool", func(b []byte) []byte { return appendBool(b, true) }, true},
+		{"FixInt", func(b []byte) []byte { return appendInt(b, 7) }, int64(7)},
+		{"NegFixInt", func(b []byte) []byte { return appendInt(b, -3) }, int64(-3)},
+		{"Int16", func(b []byte) []byte { return appendInt(b, -1000) }, int64(-1000)},
+		{"Int64", func(b []byte) []byte { return appendInt(b, math.MinInt64) }, int64(math.MinInt64)},
+		{"Uint32", func(b []byte) []byte { return appendUint(b, 70000) }, int64(70000)},
+		{"Uint64", func(b []byte) []byte { return appendUint(b, math.MaxUint64) }, uint64(math.MaxUint64)},
+		{"Float", func(b []byte) []byte { return appendFloat(b, 1.5) }, 1.5},
+		{"FixStr", func(b []byte) []byte { return appendString(b, "abc") }, "abc"},
+		{"Str16", func(b []byte) []byte { return appendString(b, strings.Repeat("x", 300)) }, strings.Repeat("x", 300)},
+		{"Bin", func(b []byte) []byte { return appendBin(b, []byte{1, 2}) }, []byte{1, 2}},
+		{"Array", func(b []byte) []byte {
+			return appendString(appendInt(appendArrayHeader(b, 2), 1), "a")
+		}, []any{int64(1), "a"}},
+		{"Map", func(b []byte) []byte {
+			return appendBool(appendString(appendMapHeader(b, 1), "ok"), false)
+		}, map[string]any{"ok": false}},
+		{"EventTime", func(b []byte) []byte {
+			return appendEventTime(b, time.Unix(10, 5))
+		}, time.Unix(10, 5)},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			v, err := readValue(bufio.NewReader(bytes.NewReader(testcase.enc(nil))))
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if !reflect.DeepEqual(v, testcase.wants) {
+				t.Errorf("output mismatch error: wanted %v ; got %v", testcase.wants, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const gcpPrefix = "logging.googleapis.com/"
+
+// gcpEnv lists the environment variables set by the Google Cloud runtimes:
+// Cloud Run, Cloud Functions, App Engine, and the project set by gcloud or
+// GKE workload configurations
+var gcpEnv = []string{"K_SERVICE", "FUNCTION_TARGET", "GAE_SERVICE", "GOOGLE_CLOUD_PROJECT"}
+
+type gcpHandler struct {
+	w         io.Writer
+	projectID string
+	addSource bool
+	source    SourceConfig
+	levelRef  level.Leveler
+	replFn    func(a attr.Attr) attr.Attr
+	attrs     []attr.Attr
+}
+
+// OnGCP returns true if the process is running in a Google Cloud runtime, as
+// detected from its environment variables
+func OnGCP() bool {
+	for _, key := range gcpEnv {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// GCP creates a handler that writes records to the io.Writer `w` as the JSON
+// lines that Cloud Logging parses from the standard output of Cloud Run, GKE
+// and Cloud Functions workloads, with the `severity`, `message` and `time`
+// fields.
+//
+// The `trace_id`, `span_id` and `sampled` attributes (as added by
+// logx.TraceParent, for instance) are written as the entry's trace fields,
+// with the trace qualified by the Google Cloud project `projectID`. If empty,
+// the project is read from the GOOGLE_CLOUD_PROJECT environment variable. With
+// WithSource, the caller's file, line and function are added as the entry's
+// source location
+func GCP(w io.Writer, projectID string) Handler {
+	if w == nil {
+		return nil
+	}
+	if projectID == "" {
+		projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+
+	return gcpHandler{
+		w:         w,
+		projectID: projectID,
+	}
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h gcpHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	b.WriteString(`{"severity":`)
+	b.WriteJSON(level.GCPSeverity(r.Level()))
+	b.WriteString(`,"message":`)
+	b.WriteJSON(r.Message())
+	b.WriteString(`,"time":`)
+	b.WriteJSON(r.Time().UTC().Format(time.RFC3339Nano))
+
+	if h.addSource {
+		if f, ok := Source(r); ok {
+			b.WriteString(`,"` + gcpPrefix + `sourceLocation":{"file":`)
+			b.WriteJSON(h.source.file(f, keepPath))
+			b.WriteString(`,"line":`)
+			b.WriteJSON(strconv.Itoa(f.Line))
+			b.WriteString(`,"function":`)
+			b.WriteJSON(f.Function)
+			b.WriteByte('}')
+		}
+	}
+
+	var skip []string
+	if h.projectID != "" {
+		skip = []string{traceIDKey, spanIDKey, sampledKey}
+
+		if a := lookup(traceIDKey, r.Attrs(), h.attrs); a != nil {
+			b.WriteString(`,"` + gcpPrefix + `trace":`)
+			b.WriteJSON(fmt.Sprintf("projects/%s/traces/%v", h.projectID, a.Value()))
+		}
+		if a := lookup(spanIDKey, r.Attrs(), h.attrs); a != nil {
+			b.WriteString(`,"` + gcpPrefix + `spanId":`)
+			b.WriteJSON(fmt.Sprint(a.Value()))
+		}
+		if a := lookup(sampledKey, r.Attrs(), h.attrs); a != nil {
+			if sampled, ok := a.Value().(bool); ok {
+				b.WriteString(`,"` + gcpPrefix + `trace_sampled":`)
+				b.WriteJSON(sampled)
+			}
+		}
+	}
+
+	writeJSONFields(b, true, h.replFn, skip, r.Attrs())
+	writeJSONFields(b, true, h.replFn, skip, h.attrs)
+	b.WriteString("}\n")
+
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+// Ping implements Pinger, checking the health of the handler's io.Writer
+func (h gcpHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, h.w)
+}
+
+// Shutdown implements Shutdowner, shutting down the handler's io.Writer
+func (h gcpHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, h.w)
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h gcpHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h gcpHandler) With(attrs ...attr.Attr) Handler {
+	return gcpHandler{
+		w:         h.w,
+		projectID: h.projectID,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     attrs,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h gcpHandler) WithSource(addSource bool) Handler {
+	return gcpHandler{
+		w:         h.w,
+		projectID: h.projectID,
+		addSource: addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// withSourceConfig implements sourceConfigurer
+func (h gcpHandler) withSourceConfig(conf SourceConfig) Handler {
+	h.source = conf
+	return h
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h gcpHandler) WithLevel(level level.Leveler) Handler {
+	return gcpHandler{
+		w:         h.w,
+		projectID: h.projectID,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  level,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h gcpHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return gcpHandler{
+		w:         h.w,
+		projectID: h.projectID,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    fn,
+		attrs:     h.attrs,
+	}
+}
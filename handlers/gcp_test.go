@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestGCP(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := GCP(b, "my-project").With(attr.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"))
+
+		err := h.Handle(records.New(ts, level.Error, "request failed",
+			attr.String("span_id", "00f067aa0ba902b7"),
+			attr.New("sampled", true),
+			attr.Int("status", 500),
+		))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		wants := `{"severity":"ERROR","message":"request failed","time":"2024-01-02T03:04:05Z",` +
+			`"logging.googleapis.com/trace":"projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736",` +
+			`"logging.googleapis.com/spanId":"00f067aa0ba902b7","logging.googleapis.com/trace_sampled":true,` +
+			`"status":500}` + "\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, b.String())
+		}
+	})
+	t.Run("NoProject", func(t *testing.T) {
+		t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+		b := &bytes.Buffer{}
+
+		_ = GCP(b, "").Handle(records.New(ts, level.Info, "message", attr.String("trace_id", "abc")))
+
+		wants := `{"severity":"INFO","message":"message","time":"2024-01-02T03:04:05Z","trace_id":"abc"}` + "\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, b.String())
+		}
+	})
+	t.Run("WithSource", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		_ = GCP(b, "p").WithSource(true).Handle(records.New(ts, level.Debug, "message"))
+
+		var e struct {
+			Severity string `json:"severity"`
+			Source   struct {
+				File string `json:"file"`
+				Line string `json:"line"`
+			} `json:"logging.googleapis.com/sourceLocation"`
+		}
+		if err := json.Unmarshal(b.Bytes(), &e); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if e.Severity != "DEBUG" || e.Source.File == "" || e.Source.Line == "" {
+			t.Errorf("output mismatch error: wanted a debug entry with its source location ; got %+v", e)
+		}
+	})
+	t.Run("Severity", func(t *testing.T) {
+		for _, test := range []struct {
+			lv    level.Level
+			wants string
+		}{
+			{nil, "DEFAULT"},
+			{level.Trace, "DEBUG"},
+			{level.Info, "INFO"},
+			{level.Info.Offset(2), "INFO"},
+			{level.Warn, "WARNING"},
+			{level.Fatal, "CRITICAL"},
+		} {
+			if got := level.GCPSeverity(test.lv); got != test.wants {
+				t.Errorf("output mismatch error for %v: wanted %v ; got %v", test.lv, test.wants, got)
+			}
+		}
+	})
+}
+
+func TestOnGCP(t *testing.T) {
+	for _, key := range gcpEnv {
+		t.Setenv(key, "")
+	}
+	if OnGCP() {
+		t.Errorf("output mismatch error: wanted false ; got true")
+	}
+
+	t.Setenv("K_SERVICE", "api")
+	if !OnGCP() {
+		t.Errorf("output mismatch error: wanted true ; got false")
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strconv"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// goroutineKey is the key of the attribute added by WithGoroutineID
+const goroutineKey = "goroutine"
+
+type goroutineHandler struct {
+	h Handler
+}
+
+// WithGoroutineID decorates the Handler `h` so that every Record it handles
+// carries the ID of the goroutine that logged it, as the `goroutine`
+// attribute. This helps to tell apart the interleaved records of concurrent
+// code when debugging.
+//
+// Goroutine IDs are deliberately hidden by the runtime: they are read from a
+// stack trace (which costs about a microsecond per record), are reused once a
+// goroutine exits, and must not be relied upon for anything but debugging.
+//
+// The ID is read when the record is handled, so the decorator must be placed
+// outside of any Handler that handles records in another goroutine, like Async
+func WithGoroutineID(h Handler) Handler {
+	if h == nil {
+		return nil
+	}
+
+	return goroutineHandler{
+		h: h,
+	}
+}
+
+// goroutineID returns the ID of the current goroutine, parsed from the header
+// of its stack trace (`goroutine 42 [running]:`)
+func goroutineID() (int, bool) {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if idx := bytes.IndexByte(b, ' '); idx > 0 {
+		b = b[:idx]
+	}
+
+	id, err := strconv.Atoi(string(b))
+	return id, err == nil
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (g goroutineHandler) Enabled(level level.Level) bool {
+	return g.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (g goroutineHandler) Handle(r records.Record) error {
+	if !g.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	if id, ok := goroutineID(); ok {
+		r = r.AddAttr(attr.Int(goroutineKey, id))
+	}
+	return g.h.Handle(r)
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (g goroutineHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, g.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (g goroutineHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, g.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (g goroutineHandler) With(attrs ...attr.Attr) Handler {
+	return goroutineHandler{
+		h: g.h.With(attrs...),
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (g goroutineHandler) WithSource(addSource bool) Handler {
+	return goroutineHandler{
+		h: g.h.WithSource(addSource),
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (g goroutineHandler) WithLevel(level level.Leveler) Handler {
+	return goroutineHandler{
+		h: g.h.WithLevel(level),
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (g goroutineHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return goroutineHandler{
+		h: g.h.WithReplaceFn(fn),
+	}
+}
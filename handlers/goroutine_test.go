@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestWithGoroutineID(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		th := newTestHandler()
+		h := WithGoroutineID(th)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = h.Handle(records.New(time.Now(), level.Info, "test message"))
+			}()
+		}
+		wg.Wait()
+
+		seen := map[any]struct{}{}
+		for _, r := range th.Records() {
+			id, ok := attr.Map(r.Attrs()...)[goroutineKey]
+			if !ok || id.(int64) <= 0 {
+				t.Errorf("output mismatch error: wanted a goroutine ID ; got %v", r.Attrs())
+			}
+			seen[id] = struct{}{}
+		}
+		if len(seen) != 4 {
+			t.Errorf("output mismatch error: wanted %v distinct IDs ; got %v", 4, len(seen))
+		}
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		th := newTestHandler()
+		h := WithGoroutineID(th).WithLevel(level.Warn)
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "test message"))
+		if len(th.Records()) != 0 {
+			t.Errorf("expected the record to be filtered out")
+		}
+	})
+	t.Run("Nil", func(t *testing.T) {
+		if WithGoroutineID(nil) != nil {
+			t.Errorf("expected output to be nil")
+		}
+	})
+}
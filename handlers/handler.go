@@ -25,9 +25,9 @@ type Handler interface {
 	// to add a source file+line reference to `addSource` boolean
 	WithSource(addSource bool) Handler
 
-	// WithLevel will spawn a copy of this Handler with the input level `level`
-	// as a verbosity filter
-	WithLevel(level level.Level) Handler
+	// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+	// as a verbosity filter. The Leveler is consulted on every Handle call
+	WithLevel(level level.Leveler) Handler
 
 	// WithReplaceFn will spawn a copy of this Handler with the input attribute
 	// replace function `fn`
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"sync"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// testHandler is a Handler that keeps the records it handles in memory
+type testHandler struct {
+	mu       *sync.Mutex
+	records  *[]records.Record
+	levelRef level.Leveler
+	bound    *[]attr.Attr
+	err      error
+}
+
+func newTestHandler() testHandler {
+	return testHandler{
+		mu:      &sync.Mutex{},
+		records: &[]records.Record{},
+	}
+}
+
+func (h testHandler) Records() []records.Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]records.Record{}, (*h.records)...)
+}
+
+func (h testHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+func (h testHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+	if h.bound != nil {
+		r = r.AddAttr(*h.bound...)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.records = append(*h.records, r)
+	return h.err
+}
+
+func (h testHandler) With(attrs ...attr.Attr) Handler {
+	h.bound = &attrs
+	return h
+}
+
+func (h testHandler) WithSource(addSource bool) Handler                    { return h }
+func (h testHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler { return h }
+func (h testHandler) WithLevel(level level.Leveler) Handler {
+	h.levelRef = level
+	return h
+}
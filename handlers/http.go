@@ -0,0 +1,211 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	defaultHTTPTimeout     = 10 * time.Second
+	defaultHTTPContentType = "application/x-ndjson"
+
+	// ProxyDirect is the HTTPConfig.Proxy value that disables proxies,
+	// regardless of the environment
+	ProxyDirect = "direct"
+)
+
+// ErrHTTPStatus is raised when an HTTP sink responds with a status code other
+// than 2xx
+var ErrHTTPStatus error = errors.New("unexpected HTTP status")
+
+// HTTPConfig describes how an HTTP-based sink (like a webhook or a log intake
+// endpoint) is reached, and is shared by the handlers and writers that send
+// records over HTTP
+type HTTPConfig struct {
+	// Proxy is the URL of the proxy that requests are sent through, overriding
+	// the environment. If empty, the proxy is read from the HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY environment variables (see
+	// http.ProxyFromEnvironment); ProxyDirect disables proxies altogether
+	Proxy string
+	// TLS configures the connections to HTTPS endpoints (and proxies), if set
+	TLS *TLSConfig
+	// Timeout bounds each request, defaulting to 10 seconds
+	Timeout time.Duration
+	// Header holds the headers added to every request, like an API key
+	Header http.Header
+	// ContentType is the request's content type, defaulting to
+	// `application/x-ndjson`
+	ContentType string
+	// Compression is the algorithm that request bodies are compressed with,
+	// as announced in their Content-Encoding header
+	Compression Compression
+	// CompressionThreshold is the body size (in bytes) from which requests
+	// are compressed, defaulting to 1 KiB; smaller bodies are sent as-is, as
+	// compressing them saves little
+	CompressionThreshold int
+	// ZstdDict is the Zstandard dictionary that request bodies are compressed
+	// with, when Compression is CompressZstd (see TrainZstdDict). It improves
+	// the compression of small bodies considerably, so the threshold can be
+	// lowered along with it; but the sink must decode the bodies with the same
+	// dictionary
+	ZstdDict []byte
+}
+
+// Client creates an *http.Client from the HTTPConfig, with its own transport.
+// An error is returned if the proxy URL is invalid or the TLS settings cannot
+// be loaded
+func (c HTTPConfig) Client() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	switch c.Proxy {
+	case "":
+		transport.Proxy = http.ProxyFromEnvironment
+	case ProxyDirect:
+		transport.Proxy = nil
+	default:
+		proxy, err := url.Parse(c.Proxy)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", c.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if c.TLS != nil {
+		tlsConf, err := c.TLS.Build()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConf
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}, nil
+}
+
+type httpWriter struct {
+	url         string
+	client      *http.Client
+	header      http.Header
+	contentType string
+	compression Compression
+	threshold   int
+	dict        *zstd.Encoder
+}
+
+// HTTPWriter creates an io.Writer that sends each write as the body of a POST
+// request to the URL `endpoint`, as configured by the HTTPConfig `conf`.
+// Responses with a status code other than 2xx are returned as ErrHTTPStatus
+// errors. An error is also returned if the Zstandard dictionary in `conf` is
+// invalid.
+//
+// Each write is a request, so the writer is usually wrapped with Batch to send
+// records in bulk:
+//
+//	w, err := handlers.HTTPWriter("https://logs.example.com/ingest", handlers.HTTPConfig{
+//		Header: http.Header{"Authorization": {"Bearer " + token}},
+//	})
+//	// (...)
+//	h := jsonh.New(handlers.Batch(w, time.Second, 0))
+//
+// The writer implements ContextWriter, so the requests of batches are bound
+// to their contexts
+func HTTPWriter(endpoint string, conf HTTPConfig) (io.Writer, error) {
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, err
+	}
+
+	client, err := conf.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := conf.ContentType
+	if contentType == "" {
+		contentType = defaultHTTPContentType
+	}
+
+	dict, err := newZstdDictEncoder(conf.ZstdDict)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpWriter{
+		url:         endpoint,
+		client:      client,
+		header:      conf.Header,
+		contentType: contentType,
+		compression: conf.Compression,
+		threshold:   conf.CompressionThreshold,
+		dict:        dict,
+	}, nil
+}
+
+// Write implements io.Writer, sending `p` in a request
+func (w *httpWriter) Write(p []byte) (int, error) {
+	return w.WriteContext(context.Background(), p)
+}
+
+// WriteContext implements ContextWriter, sending `p` in a request bound to the
+// context `ctx`
+func (w *httpWriter) WriteContext(ctx context.Context, p []byte) (int, error) {
+	body, compressed, err := w.compression.compress(p, w.threshold, w.dict)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for key, values := range w.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", w.contentType)
+	if compressed {
+		req.Header.Set("Content-Encoding", w.compression.String())
+	}
+	if key, ok := IdempotencyKey(ctx); ok {
+		req.Header.Set("Idempotency-Key", key)
+	}
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return 0, fmt.Errorf("%w: %s", ErrHTTPStatus, strings.TrimSpace(res.Status))
+	}
+	return len(p), nil
+}
+
+// Capabilities implements Capable, returning CapRemote
+func (w *httpWriter) Capabilities() Capability {
+	return CapRemote
+}
+
+// Shutdown implements Shutdowner, closing the idle connections to the sink
+func (w *httpWriter) Shutdown(context.Context) error {
+	w.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,180 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingServer is an HTTP handler keeping the requests it receives
+type recordingServer struct {
+	mu     sync.Mutex
+	status int
+	urls   []string
+	bodies []string
+	header []http.Header
+}
+
+func (s *recordingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	s.urls = append(s.urls, r.URL.String())
+	s.bodies = append(s.bodies, string(body))
+	s.header = append(s.header, r.Header)
+	status := s.status
+	s.mu.Unlock()
+
+	if status == 0 {
+		status = http.StatusAccepted
+	}
+	w.WriteHeader(status)
+}
+
+func TestHTTPWriter(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		rs := &recordingServer{}
+		srv := httptest.NewServer(rs)
+		defer srv.Close()
+
+		w, err := HTTPWriter(srv.URL+"/ingest", HTTPConfig{
+			Header: http.Header{"Authorization": {"Bearer token"}},
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if n, err := w.Write([]byte("{\"message\":\"hi\"}\n")); err != nil || n != 17 {
+			t.Errorf("unexpected error: %v (%d bytes written)", err, n)
+		}
+
+		if len(rs.bodies) != 1 || rs.bodies[0] != "{\"message\":\"hi\"}\n" {
+			t.Errorf("output mismatch error: wanted a single request ; got %v", rs.bodies)
+			return
+		}
+		if got := rs.header[0].Get("Authorization"); got != "Bearer token" {
+			t.Errorf("output mismatch error: wanted %q ; got %q", "Bearer token", got)
+		}
+		if got := rs.header[0].Get("Content-Type"); got != defaultHTTPContentType {
+			t.Errorf("output mismatch error: wanted %q ; got %q", defaultHTTPContentType, got)
+		}
+	})
+	t.Run("Status", func(t *testing.T) {
+		srv := httptest.NewServer(&recordingServer{status: http.StatusServiceUnavailable})
+		defer srv.Close()
+
+		w, _ := HTTPWriter(srv.URL, HTTPConfig{})
+		if _, err := w.Write([]byte("line\n")); !errors.Is(err, ErrHTTPStatus) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrHTTPStatus, err)
+		}
+	})
+	t.Run("Proxy", func(t *testing.T) {
+		proxy := &recordingServer{}
+		proxySrv := httptest.NewServer(proxy)
+		defer proxySrv.Close()
+
+		w, err := HTTPWriter("http://logs.example.com/ingest", HTTPConfig{Proxy: proxySrv.URL})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if _, err := w.Write([]byte("line\n")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		if len(proxy.urls) != 1 || proxy.urls[0] != "http://logs.example.com/ingest" {
+			t.Errorf("output mismatch error: wanted the request to go through the proxy ; got %v", proxy.urls)
+		}
+	})
+	t.Run("Direct", func(t *testing.T) {
+		client, err := HTTPConfig{Proxy: ProxyDirect}.Client()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if client.Transport.(*http.Transport).Proxy != nil {
+			t.Errorf("expected no proxy to be set")
+		}
+	})
+	t.Run("InvalidProxy", func(t *testing.T) {
+		if _, err := HTTPWriter("http://logs.example.com", HTTPConfig{Proxy: "::"}); err == nil {
+			t.Errorf("expected an error parsing the proxy URL")
+		}
+	})
+	t.Run("Batched", func(t *testing.T) {
+		unblock := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-unblock
+		}))
+		defer srv.Close()
+		defer close(unblock)
+
+		w, _ := HTTPWriter(srv.URL, HTTPConfig{Timeout: time.Minute})
+		b := Batch(w, 0, 0)
+		_, _ = b.Write([]byte("line\n"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if err := Shutdown(ctx, b); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error: wanted %v ; got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
+
+func TestAckedBatch(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		failures = 1
+		keys     []string
+		bodies   []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		bodies = append(bodies, string(body))
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	w, _ := HTTPWriter(srv.URL, HTTPConfig{})
+	b := AckedBatch(w, 0, 0)
+	defer b.Close()
+
+	_, _ = b.Write([]byte("a\n"))
+	if err := b.Sync(); !errors.Is(err, ErrHTTPStatus) {
+		t.Errorf("unexpected error: wanted %v ; got %v", ErrHTTPStatus, err)
+	}
+
+	// written behind the failed batch, which is retried first
+	_, _ = b.Write([]byte("b\n"))
+	if err := b.Sync(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(bodies) != 3 || bodies[0] != "a\n" || bodies[1] != "a\n" || bodies[2] != "b\n" {
+		t.Errorf("output mismatch error: wanted the first batch to be retried ; got %q", bodies)
+		return
+	}
+	if keys[0] == "" || keys[0] != keys[1] || keys[1] == keys[2] {
+		t.Errorf("output mismatch error: wanted the same key on retries only ; got %q", keys)
+	}
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a copy of the context `ctx` holding the
+// idempotency key `key`, which identifies a write to a sink across retries
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKey returns the idempotency key in the context `ctx`, as set by
+// AckedBatch for each of its batches. Sinks that implement ContextWriter use
+// it for the downstream service to deduplicate retried writes
+func IdempotencyKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	return key, ok && key != ""
+}
+
+func newIdempotencyKey() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// Hooks holds the functions called around the Handle calls of an instrumented
+// Handler (see Instrument), to build latency tracking, tracing or debugging of
+// the logging pipeline itself. Either may be nil.
+//
+// Hooks are called synchronously, on the logging goroutine, so they should
+// be fast. They must not log with a Logger using the instrumented Handler
+type Hooks struct {
+	// Before is called with the Record `r` before it is handled
+	Before func(r records.Record)
+	// After is called with the Record `r` once it is handled, with the
+	// duration `d` of the Handle call and the error `err` it returned
+	After func(r records.Record, d time.Duration, err error)
+}
+
+type instrumentHandler struct {
+	h     Handler
+	hooks Hooks
+}
+
+// Instrument decorates the Handler `h` so that the Hooks `hooks` are called
+// around each of its Handle calls. Records that `h` is not accepting (as per
+// its Enabled method) are skipped without calling the Hooks.
+//
+// If no hooks are set, the Handler `h` is returned as-is
+func Instrument(h Handler, hooks Hooks) Handler {
+	if h == nil {
+		return nil
+	}
+	if hooks.Before == nil && hooks.After == nil {
+		return h
+	}
+
+	return instrumentHandler{
+		h:     h,
+		hooks: hooks,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (i instrumentHandler) Enabled(level level.Level) bool {
+	return i.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (i instrumentHandler) Handle(r records.Record) error {
+	if !i.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	if i.hooks.Before != nil {
+		i.hooks.Before(r)
+	}
+	if i.hooks.After == nil {
+		return i.h.Handle(r)
+	}
+
+	start := time.Now()
+	err := i.h.Handle(r)
+	i.hooks.After(r, time.Since(start), err)
+	return err
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (i instrumentHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, i.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (i instrumentHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, i.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (i instrumentHandler) With(attrs ...attr.Attr) Handler {
+	return instrumentHandler{
+		h:     i.h.With(attrs...),
+		hooks: i.hooks,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (i instrumentHandler) WithSource(addSource bool) Handler {
+	return instrumentHandler{
+		h:     i.h.WithSource(addSource),
+		hooks: i.hooks,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (i instrumentHandler) WithLevel(level level.Leveler) Handler {
+	return instrumentHandler{
+		h:     i.h.WithLevel(level),
+		hooks: i.hooks,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (i instrumentHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return instrumentHandler{
+		h:     i.h.WithReplaceFn(fn),
+		hooks: i.hooks,
+	}
+}
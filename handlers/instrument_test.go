@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestInstrument(t *testing.T) {
+	t.Run("Hooks", func(t *testing.T) {
+		var (
+			calls []string
+			errs  []error
+		)
+		th := newTestHandler()
+		th.err = errors.New("sink failed")
+
+		h := Instrument(th.WithLevel(level.Info), Hooks{
+			Before: func(r records.Record) {
+				calls = append(calls, "before:"+r.Message())
+			},
+			After: func(r records.Record, d time.Duration, err error) {
+				calls = append(calls, "after:"+r.Message())
+				if d < 0 {
+					t.Errorf("unexpected negative duration: %v", d)
+				}
+				errs = append(errs, err)
+			},
+		})
+
+		_ = h.Handle(records.New(time.Now(), level.Debug, "skipped"))
+		if err := h.Handle(records.New(time.Now(), level.Info, "handled")); !errors.Is(err, th.err) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", th.err, err)
+		}
+
+		wants := []string{"before:handled", "after:handled"}
+		if len(calls) != len(wants) || calls[0] != wants[0] || calls[1] != wants[1] {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, calls)
+		}
+		if len(errs) != 1 || !errors.Is(errs[0], th.err) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", th.err, errs)
+		}
+	})
+
+	t.Run("NoHooks", func(t *testing.T) {
+		if _, ok := Instrument(newTestHandler(), Hooks{}).(testHandler); !ok {
+			t.Errorf("expected the Handler to be returned as-is")
+		}
+	})
+}
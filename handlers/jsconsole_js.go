@@ -0,0 +1,168 @@
+//go:build js && wasm
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"syscall/js"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// ErrNoConsole is raised when pinging a JSConsole handler in a runtime without
+// a JavaScript console
+var ErrNoConsole error = errors.New("JavaScript console is not available")
+
+type jsConsoleHandler struct {
+	console   js.Value
+	addSource bool
+	levelRef  level.Leveler
+	replFn    func(a attr.Attr) attr.Attr
+	attrs     []attr.Attr
+}
+
+// JSConsole returns a Handler that writes records to the JavaScript console
+// (`globalThis.console`), for WASM frontends and Workers-style runtimes. Each
+// record is written as its message followed by a structured object with its
+// timestamp, level and attributes (with groups as nested objects), so that
+// the browser's developer tools can inspect and filter them:
+//
+//	console.info("user signed in", {time: "...", level: "info", user: {id: 42}})
+//
+// Records are written with `console.debug` below Info level, `console.info`,
+// `console.warn` and `console.error` from Error level upwards.
+//
+// This Handler is only available with GOOS=js
+func JSConsole() Handler {
+	return jsConsoleHandler{
+		console: js.Global().Get("console"),
+	}
+}
+
+// method returns the name of the console method for the level `lv`
+func (h jsConsoleHandler) method(lv level.Level) string {
+	switch n := lv.Int(); {
+	case n >= level.Error.Int():
+		return "error"
+	case n >= level.Warn.Int():
+		return "warn"
+	case n >= level.Info.Int():
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h jsConsoleHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	obj := map[string]any{
+		"time":  r.Time().Format(time.RFC3339Nano),
+		"level": r.Level().String(),
+	}
+	if h.addSource {
+		if f, ok := Source(r); ok {
+			obj["source"] = f.File + ":" + strconv.Itoa(f.Line)
+		}
+	}
+	h.setAttrs(obj, r.Attrs())
+	h.setAttrs(obj, h.attrs)
+
+	h.console.Call(h.method(r.Level()), r.Message(), js.ValueOf(obj))
+	return nil
+}
+
+// setAttrs sets the attributes `attrs` as the fields of the object `obj`
+func (h jsConsoleHandler) setAttrs(obj map[string]any, attrs []attr.Attr) {
+	for _, a := range attrs {
+		if h.replFn != nil && a != nil {
+			a = h.replFn(a)
+		}
+		if a == nil || a.Key() == "" {
+			continue
+		}
+
+		if group, ok := a.Value().([]attr.Attr); ok {
+			nested := make(map[string]any, len(group))
+			h.setAttrs(nested, group)
+			obj[a.Key()] = nested
+			continue
+		}
+		obj[a.Key()] = jsValue(a.Value())
+	}
+}
+
+// jsValue converts the attribute value `v` into a value supported by
+// js.ValueOf
+func jsValue(v any) any {
+	switch v := v.(type) {
+	case nil, bool, string, float64, float32,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Ping implements Pinger, checking that the JavaScript console is available
+func (h jsConsoleHandler) Ping(context.Context) error {
+	if h.console.IsUndefined() || h.console.IsNull() {
+		return ErrNoConsole
+	}
+	return nil
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h jsConsoleHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h jsConsoleHandler) With(attrs ...attr.Attr) Handler {
+	h.attrs = attrs
+	return h
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h jsConsoleHandler) WithSource(addSource bool) Handler {
+	h.addSource = addSource
+	return h
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h jsConsoleHandler) WithLevel(level level.Leveler) Handler {
+	h.levelRef = level
+	return h
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h jsConsoleHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	h.replFn = fn
+	return h
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+)
+
+// keys of the trace context attributes, as added by logx.TraceParent, which
+// the vendor presets write in their own fields
+const (
+	traceIDKey = "trace_id"
+	spanIDKey  = "span_id"
+	sampledKey = "sampled"
+)
+
+// writeJSONFields writes the attributes `attrs` as JSON object fields, each one
+// led by a comma except for the first one if `comma` is false. Attributes are
+// passed through the replace function `replFn` if set, and the ones with a key
+// listed in `skip` are left out
+func writeJSONFields(b *buffer.Buffer, comma bool, replFn func(attr.Attr) attr.Attr, skip []string, attrs []attr.Attr) {
+	for _, a := range attrs {
+		if replFn != nil && a != nil {
+			a = replFn(a)
+		}
+		if a == nil || contains(skip, a.Key()) {
+			continue
+		}
+
+		if comma {
+			b.WriteByte(',')
+		}
+		comma = true
+
+		b.WriteJSON(a.Key())
+		b.WriteByte(':')
+
+		switch v := a.Value().(type) {
+		case []attr.Attr:
+			b.WriteByte('{')
+			writeJSONFields(b, false, replFn, nil, v)
+			b.WriteByte('}')
+		case error:
+			b.WriteJSON(v.Error())
+		default:
+			b.WriteJSON(v)
+		}
+	}
+}
+
+func contains(keys []string, key string) bool {
+	for i := range keys {
+		if keys[i] == key {
+			return true
+		}
+	}
+	return false
+}
+
+// AttrsJSON returns the attributes in `lists` encoded as a single JSON object,
+// passed through the replace function `replFn` if set. It is meant for the
+// Handlers that store the attributes in a structured field, like a database
+// column
+func AttrsJSON(replFn func(attr.Attr) attr.Attr, lists ...[]attr.Attr) string {
+	b := buffer.Get()
+	defer b.Free()
+
+	b.WriteByte('{')
+	var comma bool
+	for _, attrs := range lists {
+		n := len(*b)
+		writeJSONFields(b, comma, replFn, nil, attrs)
+		comma = comma || len(*b) > n
+	}
+	b.WriteByte('}')
+	return b.String()
+}
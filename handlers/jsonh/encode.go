@@ -0,0 +1,324 @@
+package jsonh
+
+import (
+	"math"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	json "github.com/goccy/go-json"
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/records"
+)
+
+const hex = "0123456789abcdef"
+
+// field is a key-value pair in a JSON object, after resolving duplicate keys
+type field struct {
+	key   string
+	value any
+}
+
+// encodeState holds the scratch space used when sorting an object's fields
+type encodeState struct {
+	fields []field
+}
+
+var statePool = sync.Pool{
+	New: func() any {
+		return &encodeState{
+			fields: make([]field, 0, 16),
+		}
+	},
+}
+
+func (h jsonHandler) appendRecord(e *encodeState, b []byte, r records.Record) ([]byte, error) {
+	if h.boundErr != nil {
+		return b, h.boundErr
+	}
+
+	var err error
+
+	b = append(b, `{"timestamp":`...)
+	if h.timeConf.Epoch {
+		b = h.timeConf.AppendTime(b, r.Time())
+	} else {
+		b = append(b, '"')
+		b = h.timeConf.AppendTime(b, r.Time())
+		b = append(b, '"')
+	}
+	b = append(b, `,"message":`...)
+	b = appendString(b, r.Message())
+	b = append(b, `,"level":`...)
+	b = appendString(b, r.Level().String())
+
+	if h.addSource {
+		if f, ok := handlers.Source(r); ok {
+			b = h.appendSource(b, f)
+		}
+	}
+
+	if r.AttrLen() > 0 || len(h.bound) > 0 {
+		start := len(b)
+		b = append(b, `,"data":{`...)
+		b = append(b, h.bound...)
+		if b, err = h.appendFields(e, b, len(h.bound) > 0, h.boundKeys, r.Attrs()); err != nil {
+			return b, err
+		}
+		if len(b) == start+len(`,"data":{`) {
+			b = b[:start]
+		} else {
+			b = append(b, '}')
+		}
+	}
+
+	return append(b, '}'), nil
+}
+
+// appendSource appends the source reference of the frame `f`, as a `source`
+// object with its file, line and (optionally) function
+func (h jsonHandler) appendSource(b []byte, f runtime.Frame) []byte {
+	b = append(b, `,"source":{"file":`...)
+	b = appendString(b, h.source.Path(f))
+	b = append(b, `,"line":`...)
+	b = strconv.AppendInt(b, int64(f.Line), 10)
+	if h.source.Function {
+		b = append(b, `,"function":`...)
+		b = appendString(b, f.Function)
+	}
+	return append(b, '}')
+}
+
+// encodeBound pre-encodes the handler's attributes, to be spliced into the
+// data object of every record it handles
+func (h jsonHandler) encodeBound() jsonHandler {
+	h.bound, h.boundKeys, h.boundErr = nil, nil, nil
+	if len(h.attrs) == 0 {
+		return h
+	}
+
+	e := statePool.Get().(*encodeState)
+	defer statePool.Put(e)
+
+	h.bound, h.boundErr = h.appendFields(e, nil, false, nil, h.attrs)
+	for _, a := range h.attrs {
+		if h.replFn != nil && a != nil {
+			a = h.replFn(a)
+		}
+		if a != nil {
+			h.boundKeys = append(h.boundKeys, a.Key())
+		}
+	}
+	return h
+}
+
+// appendObject encodes the input attribute lists as a single JSON object, with
+// its keys sorted. Keys present in more than one attribute take the value of
+// the last one
+func (h jsonHandler) appendObject(e *encodeState, b []byte, lists ...[]attr.Attr) ([]byte, error) {
+	b, err := h.appendFields(e, append(b, '{'), false, nil, lists...)
+	if err != nil {
+		return b, err
+	}
+	return append(b, '}'), nil
+}
+
+// appendFields encodes the input attribute lists as the sorted fields of a
+// JSON object, leading with a comma if `comma` is true. Attributes whose key
+// is listed in `skip` are left out
+func (h jsonHandler) appendFields(e *encodeState, b []byte, comma bool, skip []string, lists ...[]attr.Attr) ([]byte, error) {
+	offset := len(e.fields)
+	defer func() {
+		tail := e.fields[offset:]
+		for i := range tail {
+			tail[i] = field{}
+		}
+		e.fields = e.fields[:offset]
+	}()
+
+	for _, attrs := range lists {
+		for _, a := range attrs {
+			if h.replFn != nil && a != nil {
+				a = h.replFn(a)
+			}
+			if a == nil || contains(skip, a.Key()) {
+				continue
+			}
+			e.fields = setField(e.fields, offset, a.Key(), a.Value())
+		}
+	}
+
+	fields := e.fields[offset:]
+
+	// insertion sort; objects are small and sort.Slice allocates
+	for i := 1; i < len(fields); i++ {
+		for j := i; j > 0 && fields[j].key < fields[j-1].key; j-- {
+			fields[j], fields[j-1] = fields[j-1], fields[j]
+		}
+	}
+
+	var err error
+
+	for i := range fields {
+		if i > 0 || comma {
+			b = append(b, ',')
+		}
+		b = h.keys.Append(b, fields[i].key, appendString)
+		b = append(b, ':')
+		if b, err = h.appendValue(e, b, fields[i].value); err != nil {
+			return b, err
+		}
+	}
+	return b, nil
+}
+
+func contains(keys []string, key string) bool {
+	for i := range keys {
+		if keys[i] == key {
+			return true
+		}
+	}
+	return false
+}
+
+func setField(fields []field, offset int, key string, value any) []field {
+	for i := offset; i < len(fields); i++ {
+		if fields[i].key == key {
+			fields[i].value = value
+			return fields
+		}
+	}
+	return append(fields, field{key: key, value: value})
+}
+
+func (h jsonHandler) appendValue(e *encodeState, b []byte, value any) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(b, "null"...), nil
+	case string:
+		return appendString(b, v), nil
+	case bool:
+		return strconv.AppendBool(b, v), nil
+	case int:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int8:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int16:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int32:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(b, v, 10), nil
+	case uint:
+		return strconv.AppendUint(b, uint64(v), 10), nil
+	case uint8:
+		return strconv.AppendUint(b, uint64(v), 10), nil
+	case uint16:
+		return strconv.AppendUint(b, uint64(v), 10), nil
+	case uint32:
+		return strconv.AppendUint(b, uint64(v), 10), nil
+	case uint64:
+		return strconv.AppendUint(b, v, 10), nil
+	case float32:
+		return appendFloat(b, float64(v), 32)
+	case float64:
+		return appendFloat(b, v, 64)
+	case time.Duration:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case time.Time:
+		b = append(b, '"')
+		b = v.AppendFormat(b, time.RFC3339Nano)
+		return append(b, '"'), nil
+	case []attr.Attr:
+		return h.appendObject(e, b, v)
+	case attr.Attrs:
+		return h.appendObject(e, b, v)
+	case attr.Attr:
+		return h.appendObject(e, b, []attr.Attr{v})
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return b, err
+		}
+		return append(b, data...), nil
+	}
+}
+
+// appendFloat encodes floating-point numbers in the same way as encoding/json
+func appendFloat(b []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		_, err := json.Marshal(f)
+		return b, err
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) ||
+			bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	b = strconv.AppendFloat(b, f, format, -1, bits)
+
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b, nil
+}
+
+// appendString encodes the input string `s` as a quoted JSON string, escaping
+// HTML characters in the same way as encoding/json
+func appendString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if c == '\u2028' || c == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hex[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
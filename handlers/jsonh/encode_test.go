@@ -0,0 +1,51 @@
+package jsonh
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestAppendString(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"simple",
+		`quo"te \ back`,
+		"new\nline\ttab\rreturn",
+		"<html> & </html>",
+		"ctrl \x00\x01\x1f",
+		"utf-8: ação 日本",
+		"invalid \xff\xfe",
+		"separators \u2028 \u2029",
+	} {
+		wants, _ := json.Marshal(s)
+		out := appendString(nil, s)
+
+		if string(wants) != string(out) {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, out)
+		}
+	}
+}
+
+func TestAppendFloat(t *testing.T) {
+	for _, f := range []float64{
+		0, 1, -1, 0.5, 1.0 / 3.0, 1e-7, 1e20, 1e21, -1e-9, math.MaxFloat64, math.SmallestNonzeroFloat64,
+	} {
+		wants, _ := json.Marshal(f)
+		out, err := appendFloat(nil, f, 64)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+
+		if string(wants) != string(out) {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, out)
+		}
+	}
+
+	t.Run("NaN", func(t *testing.T) {
+		if _, err := appendFloat(nil, math.NaN(), 64); err == nil {
+			t.Errorf("expected an error when encoding NaN")
+		}
+	})
+}
@@ -1,13 +1,14 @@
 package jsonh
 
 import (
+	"context"
 	"errors"
 	"io"
-	"time"
 
-	json "github.com/goccy/go-json"
 	"github.com/zalgonoise/attr"
 	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/internal/intern"
 	"github.com/zalgonoise/logx/level"
 	"github.com/zalgonoise/logx/records"
 )
@@ -22,16 +23,16 @@ var (
 type jsonHandler struct {
 	w         io.Writer
 	addSource bool
-	levelRef  level.Level
+	levelRef  level.Leveler
 	replFn    func(a attr.Attr) attr.Attr
 	attrs     []attr.Attr
-}
+	keys      *intern.Cache
+	timeConf  handlers.TimeConfig
+	source    handlers.SourceConfig
 
-type jsonRecord struct {
-	T     time.Time              `json:"timestamp"`
-	M     string                 `json:"message"`
-	Level string                 `json:"level"`
-	Data  map[string]interface{} `json:"data,omitempty"`
+	bound     []byte
+	boundKeys []string
+	boundErr  error
 }
 
 // New creates a JSON handler based on the input io.Writer `w`
@@ -40,38 +41,42 @@ func New(w io.Writer) handlers.Handler {
 		return nil
 	}
 	return jsonHandler{
-		w: w,
+		w:    w,
+		keys: &intern.Cache{},
 	}
 }
 
+func init() {
+	handlers.Register("json", newFromConfig)
+}
+
+// newFromConfig creates a JSON handler writing to the io.Writer configured in
+// `config` (see handlers.ConfigWriter)
+func newFromConfig(config map[string]any) (handlers.Handler, error) {
+	w, err := handlers.ConfigWriter(config)
+	if err != nil {
+		return nil, err
+	}
+	return New(w), nil
+}
+
 // Handle will process the input Record, returning an error if raised
 func (h jsonHandler) Handle(r records.Record) error {
-	if h.levelRef != nil && r.Level().Int() < h.levelRef.Int() {
+	if !h.Enabled(r.Level()) {
 		return nil
 	}
 
 	var (
-		hdata = map[string]interface{}{}
-		out   = &jsonRecord{
-			T:     r.Time(),
-			M:     r.Message(),
-			Level: r.Level().String(),
-			Data:  map[string]interface{}{},
-		}
+		buf = buffer.Get()
+		e   = statePool.Get().(*encodeState)
 	)
-	if r.AttrLen() > 0 {
-		out.Data = h.asMap(r.Attrs())
-	}
-	if len(h.attrs) > 0 {
-		hdata = h.asMap(h.attrs)
-	}
-	if len(hdata) > 0 {
-		for k, v := range hdata {
-			out.Data[k] = v
-		}
-	}
+	defer func() {
+		buf.Free()
+		statePool.Put(e)
+	}()
 
-	b, err := json.Marshal(out)
+	b, err := h.appendRecord(e, *buf, r)
+	*buf = b
 	if err != nil {
 		return err
 	}
@@ -88,26 +93,27 @@ func (h jsonHandler) Handle(r records.Record) error {
 	return nil
 }
 
-func (h jsonHandler) asMap(attrs []attr.Attr) map[string]interface{} {
-	var out = map[string]interface{}{}
-	for _, a := range attrs {
-		if h.replFn != nil {
-			a = h.replFn(a)
-		}
-		if v, ok := (a.Value()).([]attr.Attr); ok {
-			out[a.Key()] = h.asMap(v)
-			continue
-		}
-		if v, ok := (a.Value()).(attr.Attr); ok {
-			out[a.Key()] = h.asMap([]attr.Attr{v})
-		}
-		out[a.Key()] = a.Value()
-	}
-	return out
+// Capabilities implements handlers.Capable, returning CapGroups and CapBinary
+// along with the capabilities of the handler's io.Writer
+func (h jsonHandler) Capabilities() handlers.Capability {
+	return handlers.EncoderCapabilities(handlers.CapGroups|handlers.CapBinary, h.w)
+}
+
+// Ping implements handlers.Pinger, checking the health of the handler's
+// io.Writer
+func (h jsonHandler) Ping(ctx context.Context) error {
+	return handlers.Ping(ctx, h.w)
+}
+
+// Shutdown implements handlers.Shutdowner, shutting down the handler's
+// io.Writer
+func (h jsonHandler) Shutdown(ctx context.Context) error {
+	return handlers.Shutdown(ctx, h.w)
 }
 
 // With will spawn a copy of this Handler with the input attributes
-// `attrs`
+// `attrs`. The attributes are encoded once, and the result is reused in
+// every record the returned Handler writes
 func (h jsonHandler) With(attrs ...attr.Attr) handlers.Handler {
 	return jsonHandler{
 		w:         h.w,
@@ -115,16 +121,20 @@ func (h jsonHandler) With(attrs ...attr.Attr) handlers.Handler {
 		levelRef:  h.levelRef,
 		replFn:    h.replFn,
 		attrs:     attrs,
-	}
+		keys:      h.keys,
+		timeConf:  h.timeConf,
+		source:    h.source,
+	}.encodeBound()
 }
 
 // Enabled returns a boolean on whether the Handler is accepting
 // records with log level `level`
 func (h jsonHandler) Enabled(level level.Level) bool {
-	if h.levelRef == nil || level == nil || level.Int() >= h.levelRef.Int() {
+	if h.levelRef == nil || level == nil {
 		return true
 	}
-	return false
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
 }
 
 // WithSource will spawn a new copy of this Handler with the setting
@@ -136,18 +146,30 @@ func (h jsonHandler) WithSource(addSource bool) handlers.Handler {
 		levelRef:  h.levelRef,
 		replFn:    h.replFn,
 		attrs:     h.attrs,
+		keys:      h.keys,
+		timeConf:  h.timeConf,
+		source:    h.source,
+		bound:     h.bound,
+		boundKeys: h.boundKeys,
+		boundErr:  h.boundErr,
 	}
 }
 
-// WithLevel will spawn a copy of this Handler with the input level `level`
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
 // as a verbosity filter
-func (h jsonHandler) WithLevel(level level.Level) handlers.Handler {
+func (h jsonHandler) WithLevel(level level.Leveler) handlers.Handler {
 	return jsonHandler{
 		w:         h.w,
 		addSource: h.addSource,
 		levelRef:  level,
 		replFn:    h.replFn,
 		attrs:     h.attrs,
+		keys:      h.keys,
+		timeConf:  h.timeConf,
+		source:    h.source,
+		bound:     h.bound,
+		boundKeys: h.boundKeys,
+		boundErr:  h.boundErr,
 	}
 }
 
@@ -160,5 +182,8 @@ func (h jsonHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) handlers.Hand
 		levelRef:  h.levelRef,
 		replFn:    fn,
 		attrs:     h.attrs,
-	}
+		keys:      h.keys,
+		timeConf:  h.timeConf,
+		source:    h.source,
+	}.encodeBound()
 }
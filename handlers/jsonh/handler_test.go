@@ -2,10 +2,16 @@ package jsonh
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
 	"github.com/zalgonoise/logx/level"
 	"github.com/zalgonoise/logx/records"
 )
@@ -63,7 +69,7 @@ func TestHandle(t *testing.T) {
 
 	t.Run("Simple", func(t *testing.T) {
 		b.Reset()
-		wants := `{"timestamp":"2022-11-18T21:21:27+01:00","message":"test message","level":"info"}`
+		wants := `{"timestamp":"2022-11-18T20:21:27Z","message":"test message","level":"info"}`
 
 		err := h.Handle(r1)
 		if err != nil {
@@ -77,7 +83,7 @@ func TestHandle(t *testing.T) {
 	})
 	t.Run("WithAttribute", func(t *testing.T) {
 		b.Reset()
-		wants := `{"timestamp":"2022-11-18T21:21:27+01:00","message":"test message","level":"info","data":{"a_key":"value"}}`
+		wants := `{"timestamp":"2022-11-18T20:21:27Z","message":"test message","level":"info","data":{"a_key":"value"}}`
 
 		err := h.Handle(r2)
 		if err != nil {
@@ -91,7 +97,7 @@ func TestHandle(t *testing.T) {
 	})
 	t.Run("WithAttributes", func(t *testing.T) {
 		b.Reset()
-		wants := `{"timestamp":"2022-11-18T21:21:27+01:00","message":"test message","level":"info","data":{"a_key":"value","b_test_no":1,"c_success_rate":1,"d_custom":{"key":"custom_key","value":2}}}`
+		wants := `{"timestamp":"2022-11-18T20:21:27Z","message":"test message","level":"info","data":{"a_key":"value","b_test_no":1,"c_success_rate":1,"d_custom":{"key":"custom_key","value":2}}}`
 
 		err := h.Handle(r3)
 		if err != nil {
@@ -119,7 +125,7 @@ func TestHandle(t *testing.T) {
 	})
 	t.Run("LevelHandlerAttr", func(t *testing.T) {
 		b.Reset()
-		wants := `{"timestamp":"2022-11-18T21:21:27+01:00","message":"test message","level":"info","data":{"a_key":"val","b_test_no":1,"c_success_rate":1,"d_custom":{"key":"custom_key","value":2}}}`
+		wants := `{"timestamp":"2022-11-18T20:21:27Z","message":"test message","level":"info","data":{"a_key":"val","b_test_no":1,"c_success_rate":1,"d_custom":{"key":"custom_key","value":2}}}`
 		newH := h.With(attr.New("a_key", "val"))
 
 		err := newH.Handle(r3)
@@ -134,7 +140,7 @@ func TestHandle(t *testing.T) {
 	})
 	t.Run("WithNamespaceAttr", func(t *testing.T) {
 		b.Reset()
-		wants := `{"timestamp":"2022-11-18T21:21:27+01:00","message":"test message","level":"info","data":{"a_key":"value","b_test_no":1,"namespace":{"c_success_rate":1,"d_custom":{"key":"custom_key","value":2}}}}`
+		wants := `{"timestamp":"2022-11-18T20:21:27Z","message":"test message","level":"info","data":{"a_key":"value","b_test_no":1,"namespace":{"c_success_rate":1,"d_custom":{"key":"custom_key","value":2}}}}`
 
 		err := h.Handle(r4)
 		if err != nil {
@@ -148,7 +154,7 @@ func TestHandle(t *testing.T) {
 	})
 	t.Run("WithReplFn", func(t *testing.T) {
 		b.Reset()
-		wants := `{"timestamp":"2022-11-18T21:21:27+01:00","message":"test message","level":"info","data":{"a_key":"val"}}`
+		wants := `{"timestamp":"2022-11-18T20:21:27Z","message":"test message","level":"info","data":{"a_key":"val"}}`
 
 		newH := h.WithReplaceFn(func(a attr.Attr) attr.Attr {
 			if a.Key() == "a_key" {
@@ -203,3 +209,211 @@ func TestWithSource(t *testing.T) {
 		}
 	})
 }
+
+func TestDynamicLevel(t *testing.T) {
+	b := &bytes.Buffer{}
+	lv := level.NewVar(level.Warn)
+	h := New(b).WithLevel(lv)
+
+	if err := h.Handle(r1); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if b.Len() != 0 {
+		t.Errorf("expected record to be filtered ; got %s", b.String())
+	}
+
+	lv.SetLevel(level.Info)
+	if err := h.Handle(r1); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if b.Len() == 0 {
+		t.Errorf("expected record to be written")
+	}
+}
+
+func TestBoundAttrs(t *testing.T) {
+	b := &bytes.Buffer{}
+	h := New(b)
+
+	t.Run("OnlyBound", func(t *testing.T) {
+		b.Reset()
+		wants := `{"timestamp":"2022-11-18T20:21:27Z","message":"test message","level":"info","data":{"k":"v","z":1}}`
+
+		err := h.With(attr.Int("z", 1), attr.String("k", "v")).Handle(r1)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		out := b.String()
+		if out != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, out)
+		}
+	})
+	t.Run("ReplaceFnAfterWith", func(t *testing.T) {
+		b.Reset()
+		wants := `{"timestamp":"2022-11-18T20:21:27Z","message":"test message","level":"info","data":{"k":"replaced","a_key":"replaced"}}`
+
+		newH := h.With(attr.String("k", "v")).WithReplaceFn(func(a attr.Attr) attr.Attr {
+			return a.WithValue("replaced")
+		})
+
+		err := newH.Handle(r2)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		out := b.String()
+		if out != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, out)
+		}
+	})
+	t.Run("KeptAcrossCopies", func(t *testing.T) {
+		b.Reset()
+		wants := `{"timestamp":"2022-11-18T20:21:27Z","message":"test message","level":"info","data":{"k":"v"}}`
+
+		err := h.With(attr.String("k", "v")).WithLevel(level.Info).WithSource(false).Handle(r1)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		out := b.String()
+		if out != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, out)
+		}
+	})
+}
+
+func TestPing(t *testing.T) {
+	t.Run("Writer", func(t *testing.T) {
+		w, err := handlers.Rotate(filepath.Join(t.TempDir(), "test.log"), 1024, 0)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		h := New(w)
+
+		if err := handlers.Ping(context.Background(), h); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		_ = w.Close()
+		if err := handlers.Ping(context.Background(), h); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("unexpected error: wanted %v ; got %v", os.ErrClosed, err)
+		}
+	})
+}
+
+func TestWithTimeConfig(t *testing.T) {
+	t.Run("Epoch", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithTimeConfig(New(b), handlers.TimeConfig{Precision: handlers.PrecisionMilli, Epoch: true})
+
+		if err := h.With(ta1).Handle(r1); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		wants := `{"timestamp":1668802887000,"message":"test message","level":"info","data":{"a_key":"value"}}`
+		if got := strings.TrimSpace(b.String()); got != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, got)
+		}
+	})
+	t.Run("Precision", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithTimeConfig(New(b), handlers.TimeConfig{Precision: handlers.PrecisionSecond})
+
+		if err := h.Handle(records.New(testTime.Add(123*time.Millisecond), testLevel, testMsg)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		wants := `{"timestamp":"` + testTime.UTC().Format(time.RFC3339) + `","message":"test message","level":"info"}`
+		if got := strings.TrimSpace(b.String()); got != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, got)
+		}
+	})
+	t.Run("Fail", func(t *testing.T) {
+		if h := WithTimeConfig(nil, handlers.TimeConfig{}); h != nil {
+			t.Errorf("expected output to be nil")
+		}
+	})
+}
+
+func TestWithSourceConfig(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := New(b).WithSource(true)
+
+		if err := h.Handle(r1); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if !strings.Contains(b.String(), `/handlers/jsonh/handler_test.go","line":`) {
+			t.Errorf("output mismatch error: wanted the caller's full path ; got %s", b.String())
+		}
+	})
+	t.Run("RelativeWithFunction", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithSourceConfig(New(b).WithSource(true), handlers.SourceConfig{Relative: true, Function: true})
+
+		if err := h.Handle(r1); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		for _, wants := range []string{
+			`"source":{"file":"handlers/jsonh/handler_test.go","line":`,
+			`"function":"github.com/zalgonoise/logx/handlers/jsonh.TestWithSourceConfig.func2"}`,
+		} {
+			if !strings.Contains(b.String(), wants) {
+				t.Errorf("output mismatch error: wanted %s in the output ; got %s", wants, b.String())
+			}
+		}
+	})
+	t.Run("Fail", func(t *testing.T) {
+		if h := WithSourceConfig(nil, handlers.SourceConfig{}); h != nil {
+			t.Errorf("expected output to be nil")
+		}
+	})
+}
+
+func TestCrashSafeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(`{"msg":"partial`), 0o644); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	h, err := handlers.Build("file", map[string]any{"path": path, "crash_safe": true, "sync_interval": "1s"})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	_ = h.Handle(r1)
+	_ = handlers.Shutdown(context.Background(), h)
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "partial") || !strings.Contains(string(data), testMsg) {
+		t.Errorf("output mismatch error: wanted only the new record ; got %q", data)
+	}
+}
+
+func TestNDJSONFileReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	for i := 0; i < 2; i++ {
+		w, err := handlers.NDJSONFile(path, handlers.SyncPolicy{})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if err := New(w).Handle(r1); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		_ = w.Close()
+	}
+
+	data, _ := os.ReadFile(path)
+	if lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"); len(lines) != 2 ||
+		!strings.Contains(lines[0], testMsg) || !strings.Contains(lines[1], testMsg) {
+		t.Errorf("output mismatch error: wanted two records ; got %q", data)
+	}
+}
@@ -0,0 +1,29 @@
+package jsonh
+
+import "github.com/zalgonoise/logx/handlers"
+
+// WithTimeConfig creates a copy the Handler `h`, which renders the records'
+// timestamps as configured by the TimeConfig `conf`; epoch timestamps are
+// written as JSON numbers. Returns nil if the Handler is not a jsonHandler
+func WithTimeConfig(h handlers.Handler, conf handlers.TimeConfig) handlers.Handler {
+	jsonH, ok := (h).(jsonHandler)
+	if !ok {
+		return nil
+	}
+
+	jsonH.timeConf = conf
+	return jsonH
+}
+
+// WithSourceConfig creates a copy the Handler `h`, which renders the source
+// references (added with WithSource) as configured by the SourceConfig `conf`.
+// Returns nil if the Handler is not a jsonHandler
+func WithSourceConfig(h handlers.Handler, conf handlers.SourceConfig) handlers.Handler {
+	jsonH, ok := (h).(jsonHandler)
+	if !ok {
+		return nil
+	}
+
+	jsonH.source = conf
+	return jsonH
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zalgonoise/attr"
+)
+
+const (
+	// EnvPodName is the environment variable that WithKubernetesInfo reads the
+	// pod's name from, as set with the Downward API:
+	//
+	//	env:
+	//	  - name: POD_NAME
+	//	    valueFrom:
+	//	      fieldRef:
+	//	        fieldPath: metadata.name
+	EnvPodName = "POD_NAME"
+	// EnvPodNamespace is the environment variable that WithKubernetesInfo
+	// reads the pod's namespace from (`metadata.namespace`)
+	EnvPodNamespace = "POD_NAMESPACE"
+	// EnvNodeName is the environment variable that WithKubernetesInfo reads
+	// the name of the pod's node from (`spec.nodeName`)
+	EnvNodeName = "NODE_NAME"
+
+	// DefaultLabelsFile is the path of the Downward API volume file that
+	// WithKubernetesInfo reads the pod's labels from (`metadata.labels`), if
+	// none is set
+	DefaultLabelsFile = "/etc/podinfo/labels"
+
+	// envServiceHost is set by the kubelet in every container
+	envServiceHost = "KUBERNETES_SERVICE_HOST"
+	// namespaceFile holds the pod's namespace, when a service account token
+	// is mounted
+	namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// WithKubernetesInfo decorates the Handler `h` so that every Record it handles
+// carries the pod's metadata, as a `k8s` attribute group, for clusters without
+// a log agent adding it:
+//
+//	"k8s": {"pod": "web-7d4b9", "namespace": "prod", "node": "node-1", "labels": {"app": "web"}}
+//
+// The metadata is read from the Downward API: the pod, namespace and node
+// names from the EnvPodName, EnvPodNamespace and EnvNodeName environment
+// variables, and the labels from a volume file at `labelsFile` (or at
+// DefaultLabelsFile, if empty). When running in a cluster, the pod's host name
+// and its service account's namespace are used if the variables are not set.
+//
+// As with the other metadata Enrichers, the attributes are static (see
+// Enricher), so label changes are not picked up. Fields that are not found are
+// omitted, and if none are (like outside of a cluster), the
+// Handler `h` is returned as-is
+func WithKubernetesInfo(h Handler, labelsFile string) Handler {
+	return Enrich(h, KubernetesInfo(labelsFile))
+}
+
+// KubernetesInfo returns a static Enricher with the `k8s` attribute group added
+// by WithKubernetesInfo, or with no attributes outside of a cluster. Wrap it
+// with Cached instead to pick up label changes:
+//
+//	h = handlers.Enrich(h, handlers.Cached(handlers.EnricherFunc(
+//		func(ctx context.Context) []attr.Attr {
+//			return handlers.KubernetesInfo("").Attrs(ctx)
+//		},
+//	), time.Minute))
+func KubernetesInfo(labelsFile string) Enricher {
+	if labelsFile == "" {
+		labelsFile = DefaultLabelsFile
+	}
+
+	group := kubernetesAttrs(os.Getenv, labelsFile, namespaceFile)
+	if len(group) == 0 {
+		return staticEnricher{}
+	}
+	return staticEnricher{attrs: []attr.Attr{attr.New("k8s", group)}}
+}
+
+// kubernetesAttrs returns the pod's metadata as attributes, reading the
+// environment with `getenv`, and the labels and namespace from the files at
+// `labelsFile` and `nsFile`
+func kubernetesAttrs(getenv func(string) string, labelsFile, nsFile string) []attr.Attr {
+	inCluster := getenv(envServiceHost) != ""
+
+	pod := getenv(EnvPodName)
+	if pod == "" && inCluster {
+		pod, _ = os.Hostname()
+	}
+
+	namespace := getenv(EnvPodNamespace)
+	if namespace == "" && inCluster {
+		if data, err := os.ReadFile(nsFile); err == nil {
+			namespace = strings.TrimSpace(string(data))
+		}
+	}
+
+	attrs := make([]attr.Attr, 0, 4)
+	for _, field := range []struct {
+		key   string
+		value string
+	}{
+		{"pod", pod},
+		{"namespace", namespace},
+		{"node", getenv(EnvNodeName)},
+	} {
+		if field.value != "" {
+			attrs = append(attrs, attr.String(field.key, field.value))
+		}
+	}
+
+	if labels := readLabels(labelsFile); len(labels) > 0 {
+		attrs = append(attrs, attr.New("labels", labels))
+	}
+
+	return attrs
+}
+
+// readLabels parses the Downward API labels file at `path`, which holds a
+// `key="value"` pair per line, returning the labels as attributes. Malformed
+// lines are skipped
+func readLabels(path string) []attr.Attr {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var labels []attr.Attr
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok || key == "" {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		labels = append(labels, attr.String(key, value))
+	}
+
+	return labels
+}
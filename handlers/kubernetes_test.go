@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestWithKubernetesInfo(t *testing.T) {
+	dir := t.TempDir()
+	labelsFile := filepath.Join(dir, "labels")
+	nsFile := filepath.Join(dir, "namespace")
+	if err := os.WriteFile(labelsFile, []byte("app=\"web\"\npod-template-hash=\"7d4b9\"\nmalformed\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(nsFile, []byte("prod\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+
+	t.Run("DownwardAPI", func(t *testing.T) {
+		attrs := kubernetesAttrs(env(map[string]string{
+			EnvPodName:      "web-7d4b9",
+			EnvPodNamespace: "staging",
+			EnvNodeName:     "node-1",
+		}), labelsFile, nsFile)
+
+		wants := map[string]any{
+			"pod":       "web-7d4b9",
+			"namespace": "staging",
+			"node":      "node-1",
+			"labels":    map[string]any{"app": "web", "pod-template-hash": "7d4b9"},
+		}
+		if got := attr.Map(attrs...); !reflect.DeepEqual(wants, got) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, got)
+		}
+	})
+	t.Run("InClusterFallbacks", func(t *testing.T) {
+		attrs := kubernetesAttrs(env(map[string]string{envServiceHost: "10.0.0.1"}), filepath.Join(dir, "missing"), nsFile)
+
+		hostname, _ := os.Hostname()
+		wants := map[string]any{"pod": hostname, "namespace": "prod"}
+		if got := attr.Map(attrs...); !reflect.DeepEqual(wants, got) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, got)
+		}
+	})
+	t.Run("OutsideCluster", func(t *testing.T) {
+		if attrs := kubernetesAttrs(env(nil), filepath.Join(dir, "missing"), nsFile); len(attrs) != 0 {
+			t.Errorf("output mismatch error: wanted no attributes ; got %v", attrs)
+		}
+	})
+	t.Run("Handler", func(t *testing.T) {
+		t.Setenv(EnvPodName, "web-7d4b9")
+
+		th := newTestHandler()
+		h := WithKubernetesInfo(th, labelsFile)
+
+		if err := h.Handle(records.New(time.Now(), level.Info, "test message")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		group, ok := attr.Map(th.Records()[0].Attrs()...)["k8s"].(map[string]any)
+		if !ok || group["pod"] != "web-7d4b9" {
+			t.Errorf("output mismatch error: wanted a k8s group ; got %v", th.Records()[0].Attrs())
+		}
+	})
+	t.Run("Nil", func(t *testing.T) {
+		if WithKubernetesInfo(nil, "") != nil {
+			t.Errorf("expected output to be nil")
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"path"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// LoggerKey is the key of the attribute holding the name of the logger that
+// logged a record, as added by named Loggers (see logx.Named)
+const LoggerKey = "logger"
+
+type loggerFilterHandler struct {
+	h        Handler
+	patterns []string
+	include  bool
+}
+
+// IncludeLoggers decorates the Handler `h` so that it only handles the records
+// logged by the named loggers whose name matches one of the glob patterns
+// `patterns` (as in path.Match, like `db.*`), dropping all others, including
+// the records with no logger name. This allows splitting the output of a
+// single Logger tree, like writing a subsystem's records to its own file.
+//
+// The name is read from the records' LoggerKey attribute; malformed patterns
+// never match
+func IncludeLoggers(h Handler, patterns ...string) Handler {
+	if h == nil {
+		return nil
+	}
+	return loggerFilterHandler{h: h, patterns: patterns, include: true}
+}
+
+// ExcludeLoggers decorates the Handler `h` so that it drops the records logged
+// by the named loggers whose name matches one of the glob patterns `patterns`
+// (as in path.Match, like `db.*`), handling all others. See IncludeLoggers
+func ExcludeLoggers(h Handler, patterns ...string) Handler {
+	if h == nil {
+		return nil
+	}
+	if len(patterns) == 0 {
+		return h
+	}
+	return loggerFilterHandler{h: h, patterns: patterns}
+}
+
+// accepts returns true if the Record `r` is handled, as per its logger name
+func (f loggerFilterHandler) accepts(r records.Record) bool {
+	attrs := r.Attrs()
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if attrs[i] == nil || attrs[i].Key() != LoggerKey {
+			continue
+		}
+
+		name, _ := attrs[i].Value().(string)
+		for _, pattern := range f.patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return f.include
+			}
+		}
+		break
+	}
+	return !f.include
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (f loggerFilterHandler) Enabled(level level.Level) bool {
+	return f.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (f loggerFilterHandler) Handle(r records.Record) error {
+	if !f.accepts(r) {
+		return nil
+	}
+	return f.h.Handle(r)
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (f loggerFilterHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, f.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (f loggerFilterHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, f.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (f loggerFilterHandler) With(attrs ...attr.Attr) Handler {
+	return loggerFilterHandler{
+		h:        f.h.With(attrs...),
+		patterns: f.patterns,
+		include:  f.include,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (f loggerFilterHandler) WithSource(addSource bool) Handler {
+	return loggerFilterHandler{
+		h:        f.h.WithSource(addSource),
+		patterns: f.patterns,
+		include:  f.include,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (f loggerFilterHandler) WithLevel(level level.Leveler) Handler {
+	return loggerFilterHandler{
+		h:        f.h.WithLevel(level),
+		patterns: f.patterns,
+		include:  f.include,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (f loggerFilterHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return loggerFilterHandler{
+		h:        f.h.WithReplaceFn(fn),
+		patterns: f.patterns,
+		include:  f.include,
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestLoggerFilters(t *testing.T) {
+	ts := time.Now()
+	recs := []records.Record{
+		records.New(ts, level.Info, "query", attr.String(LoggerKey, "db.pool")),
+		records.New(ts, level.Info, "request", attr.String(LoggerKey, "http")),
+		records.New(ts, level.Info, "startup"),
+	}
+
+	for _, test := range []struct {
+		name  string
+		fn    func(Handler, ...string) Handler
+		wants []string
+	}{
+		{"Include", IncludeLoggers, []string{"query"}},
+		{"Exclude", ExcludeLoggers, []string{"request", "startup"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			th := newTestHandler()
+			h := test.fn(th, "db.*", "[")
+
+			for _, r := range recs {
+				if err := h.Handle(r); err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+			}
+
+			out := th.Records()
+			if len(out) != len(test.wants) {
+				t.Errorf("output mismatch error: wanted %d records ; got %d", len(test.wants), len(out))
+				return
+			}
+			for i := range out {
+				if out[i].Message() != test.wants[i] {
+					t.Errorf("output mismatch error: wanted %q ; got %q", test.wants[i], out[i].Message())
+				}
+			}
+		})
+	}
+
+	t.Run("Build", func(t *testing.T) {
+		h, err := Build("test_console", map[string]any{
+			"output":          "stdout",
+			"exclude_loggers": []any{"db.*"},
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if _, ok := h.(loggerFilterHandler); !ok {
+			t.Errorf("output mismatch error: wanted a logger filter ; got %T", h)
+		}
+		if _, err := Build("test_console", map[string]any{"include_loggers": "db.*"}); err == nil {
+			t.Errorf("expected an error for a non-list of patterns")
+		}
+	})
+	t.Run("NilHandler", func(t *testing.T) {
+		if h := IncludeLoggers(nil, "db.*"); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+}
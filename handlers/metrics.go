@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// MetricsBackend is the metrics system that receives the updates derived from
+// the records handled by a Metrics Handler, like a Prometheus registry or a
+// statsd client
+type MetricsBackend interface {
+	// IncCounter increments the counter named `name` by one
+	IncCounter(name string)
+	// Observe records the value `value` in the histogram named `name`
+	Observe(name string, value float64)
+}
+
+// MetricRule describes the metric updates derived from the records matching
+// it
+type MetricRule struct {
+	// Message matches the records with this message; if empty, all records
+	// match
+	Message string
+	// Counter is the name of the counter incremented for each matching record.
+	// If empty, the record's message is used
+	Counter string
+	// DurationKey is the key of a time.Duration attribute whose value (in
+	// seconds) is observed in the Histogram, if present in the record
+	DurationKey string
+	// Histogram is the name of the histogram for the DurationKey attribute. If
+	// empty, the Counter's name with a `_seconds` suffix is used
+	Histogram string
+}
+
+func (m MetricRule) matches(r records.Record) bool {
+	return m.Message == "" || m.Message == r.Message()
+}
+
+type metricsHandler struct {
+	h       Handler
+	backend MetricsBackend
+	rules   []MetricRule
+	bound   []attr.Attr
+}
+
+// Metrics decorates the Handler `h` so that the records it handles update the
+// metrics in the MetricsBackend `backend`, as described by the MetricRules
+// `rules`. A record updates the metrics of every rule it matches.
+//
+// If no rules are provided, every record increments a counter named after its
+// message
+func Metrics(h Handler, backend MetricsBackend, rules ...MetricRule) Handler {
+	if h == nil {
+		return nil
+	}
+	if backend == nil {
+		return h
+	}
+	if len(rules) == 0 {
+		rules = []MetricRule{{}}
+	}
+
+	return metricsHandler{
+		h:       h,
+		backend: backend,
+		rules:   rules,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (m metricsHandler) Enabled(level level.Level) bool {
+	return m.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (m metricsHandler) Handle(r records.Record) error {
+	if !m.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	for _, rule := range m.rules {
+		if !rule.matches(r) {
+			continue
+		}
+
+		counter := rule.Counter
+		if counter == "" {
+			counter = r.Message()
+		}
+		m.backend.IncCounter(counter)
+
+		if rule.DurationKey == "" {
+			continue
+		}
+		a := lookup(rule.DurationKey, r.Attrs(), m.bound)
+		if a == nil {
+			continue
+		}
+		if d, ok := a.Value().(time.Duration); ok {
+			histogram := rule.Histogram
+			if histogram == "" {
+				histogram = counter + "_seconds"
+			}
+			m.backend.Observe(histogram, d.Seconds())
+		}
+	}
+
+	return m.h.Handle(r)
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (m metricsHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, m.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (m metricsHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, m.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (m metricsHandler) With(attrs ...attr.Attr) Handler {
+	bound := make([]attr.Attr, 0, len(m.bound)+len(attrs))
+	return metricsHandler{
+		h:       m.h.With(attrs...),
+		backend: m.backend,
+		rules:   m.rules,
+		bound:   append(append(bound, m.bound...), attrs...),
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (m metricsHandler) WithSource(addSource bool) Handler {
+	return metricsHandler{
+		h:       m.h.WithSource(addSource),
+		backend: m.backend,
+		rules:   m.rules,
+		bound:   m.bound,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (m metricsHandler) WithLevel(level level.Leveler) Handler {
+	return metricsHandler{
+		h:       m.h.WithLevel(level),
+		backend: m.backend,
+		rules:   m.rules,
+		bound:   m.bound,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (m metricsHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return metricsHandler{
+		h:       m.h.WithReplaceFn(fn),
+		backend: m.backend,
+		rules:   m.rules,
+		bound:   m.bound,
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+type testMetrics struct {
+	mu         sync.Mutex
+	counters   map[string]int
+	histograms map[string][]float64
+}
+
+func newTestMetrics() *testMetrics {
+	return &testMetrics{
+		counters:   map[string]int{},
+		histograms: map[string][]float64{},
+	}
+}
+
+func (m *testMetrics) IncCounter(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[name]++
+}
+
+func (m *testMetrics) Observe(name string, value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.histograms[name] = append(m.histograms[name], value)
+}
+
+func TestMetrics(t *testing.T) {
+	t.Run("DefaultRule", func(t *testing.T) {
+		m := newTestMetrics()
+		th := newTestHandler()
+		h := Metrics(th, m)
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "request served"))
+		_ = h.Handle(records.New(time.Now(), level.Info, "request served"))
+		_ = h.Handle(records.New(time.Now(), level.Warn, "cache miss"))
+
+		if m.counters["request served"] != 2 || m.counters["cache miss"] != 1 {
+			t.Errorf("output mismatch error: wanted a counter per message ; got %v", m.counters)
+		}
+		if len(th.Records()) != 3 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 3, len(th.Records()))
+		}
+	})
+	t.Run("Histogram", func(t *testing.T) {
+		m := newTestMetrics()
+		h := Metrics(newTestHandler(), m, MetricRule{
+			Message:     "http request",
+			Counter:     "http_requests",
+			DurationKey: "duration",
+		}).With(attr.New("duration", time.Second))
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "http request", attr.New("duration", 250*time.Millisecond)))
+		_ = h.Handle(records.New(time.Now(), level.Info, "http request"))
+		_ = h.Handle(records.New(time.Now(), level.Info, "other"))
+
+		if m.counters["http_requests"] != 2 || len(m.counters) != 1 {
+			t.Errorf("output mismatch error: wanted %v requests ; got %v", 2, m.counters)
+		}
+		if got := m.histograms["http_requests_seconds"]; len(got) != 2 || got[0] != 0.25 || got[1] != 1 {
+			t.Errorf("output mismatch error: wanted [0.25 1] ; got %v", got)
+		}
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		m := newTestMetrics()
+		h := Metrics(newTestHandler(), m).WithLevel(level.Warn)
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "skipped"))
+
+		if len(m.counters) != 0 {
+			t.Errorf("output mismatch error: wanted no metrics ; got %v", m.counters)
+		}
+	})
+}
@@ -0,0 +1,482 @@
+//go:build !logx_tiny
+
+// Package mqtth provides a handler that publishes records to an MQTT broker,
+// so that embedded fleets can ship structured logs over their existing broker,
+// on topics built from the records' attributes:
+//
+//	h, err := mqtth.New(mqtth.Config{
+//		Addr:  "broker.local:1883",
+//		Topic: "fleet/{device}/logs/{level}",
+//		QoS:   1,
+//	})
+//	// (...)
+//	logger := logx.New(logx.WithHandler(h.With(attr.String("device", serial))))
+//
+// The package speaks MQTT 3.1.1 directly, without depending on a client
+// library. Records are published one at a time, waiting for the broker's
+// acknowledgment with QoS 1 and 2
+package mqtth
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/internal/encode"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	defaultAddr    = "localhost:1883"
+	defaultTimeout = 5 * time.Second
+
+	protocolLevel = 4 // MQTT 3.1.1
+)
+
+// packet types
+const (
+	typeConnect    = 1
+	typeConnAck    = 2
+	typePublish    = 3
+	typePubAck     = 4
+	typePubRec     = 5
+	typePubRel     = 6
+	typePubComp    = 7
+	typeDisconnect = 14
+)
+
+var (
+	// ErrNoTopic is raised when creating a handler without a topic
+	ErrNoTopic error = errors.New("no topic provided")
+	// ErrInvalidQoS is raised when creating a handler with a QoS other than 0,
+	// 1 or 2
+	ErrInvalidQoS error = errors.New("invalid QoS")
+	// ErrConnRefused is raised when the broker refuses the connection
+	ErrConnRefused error = errors.New("connection refused by the broker")
+	// ErrProtocol is raised when the broker replies with an unexpected packet
+	ErrProtocol error = errors.New("MQTT protocol error")
+)
+
+// Config describes the MQTT broker and the topics that records are published
+// to
+type Config struct {
+	// Addr is the address of the broker, `localhost:1883` by default
+	Addr string
+	// ClientID identifies the client to the broker. A random one is generated
+	// if empty
+	ClientID string
+	// Username and Password authenticate the connection, if set
+	Username string
+	Password string
+	// TLS configures the connection with TLS, if set
+	TLS *handlers.TLSConfig
+	// Timeout bounds connecting to the broker and publishing each record, five
+	// seconds by default
+	Timeout time.Duration
+	// KeepAlive is the keep alive interval announced to the broker. The
+	// handler does not send pings, so the broker may close the connection
+	// after 1.5 times this interval without records, which is reopened for the
+	// next one. Zero (the default) disables it
+	KeepAlive time.Duration
+
+	// Topic is the template of the records' topics, where `{key}` placeholders
+	// are replaced with the value of the matching attribute (see
+	// handlers.Expand), and `{level}` with the record's level if it has no
+	// such attribute. Like `fleet/{device}/logs/{level}`
+	Topic string
+	// QoS is the quality of service level of the published records: 0 (at
+	// most once, the default), 1 (at least once) or 2 (exactly once)
+	QoS byte
+	// Retain sets the retain flag, for the broker to keep the last record of
+	// each topic for new subscribers
+	Retain bool
+	// Format creates the Handler that encodes the records' payloads, for the
+	// io.Writer it is given. Records are encoded as JSON by default (see the
+	// jsonh package)
+	Format func(w io.Writer) handlers.Handler
+}
+
+// client holds the connection to the broker, shared by the copies of a
+// handler
+type client struct {
+	mu       sync.Mutex
+	conf     Config
+	conn     net.Conn
+	r        *bufio.Reader
+	packetID uint16
+	closed   bool
+}
+
+type mqttHandler struct {
+	c        *client
+	enc      encode.Encoder
+	levelRef level.Leveler
+	attrs    []attr.Attr
+}
+
+// New creates a handler that publishes records to the MQTT broker described by
+// the Config `conf`. The connection is opened on the first record, and
+// reopened on the following one if publishing fails
+func New(conf Config) (handlers.Handler, error) {
+	if conf.Topic == "" {
+		return nil, ErrNoTopic
+	}
+	if conf.QoS > 2 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidQoS, conf.QoS)
+	}
+	if conf.Addr == "" {
+		conf.Addr = defaultAddr
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = defaultTimeout
+	}
+	if conf.ClientID == "" {
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return nil, err
+		}
+		conf.ClientID = "logx-" + hex.EncodeToString(id)
+	}
+
+	return mqttHandler{
+		c:   &client{conf: conf},
+		enc: encode.New(conf.Format),
+	}, nil
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h mqttHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	payload, err := h.enc.Encode(r)
+	if err != nil {
+		return err
+	}
+
+	lv := r.Level()
+	if lv == nil {
+		lv = level.Info
+	}
+
+	// the record's level is the fallback for the `{level}` placeholder
+	bound := make([]attr.Attr, 0, len(h.attrs)+1)
+	bound = append(append(bound, attr.String("level", lv.String())), h.attrs...)
+
+	return h.c.publish(handlers.Expand(h.c.conf.Topic, r.Attrs(), bound), payload)
+}
+
+// publish publishes the payload `payload` on the topic `topic`, connecting to
+// the broker first if needed. The connection is closed if publishing fails
+func (c *client) publish(topic string, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return os.ErrClosed
+	}
+
+	deadline := time.Now().Add(c.conf.Timeout)
+	if c.conn == nil {
+		if err := c.connect(deadline); err != nil {
+			return err
+		}
+	}
+
+	if err := c.roundTrip(deadline, topic, payload); err != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (c *client) connect(deadline time.Time) error {
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if c.conf.TLS != nil {
+		conn, err = handlers.DialTLS(ctx, "tcp", c.conf.Addr, *c.conf.TLS)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", c.conf.Addr)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := c.handshake(conn, deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	c.conn = conn
+	return nil
+}
+
+// handshake sends the CONNECT packet on the connection `conn`, and reads the
+// broker's CONNACK
+func (c *client) handshake(conn net.Conn, deadline time.Time) error {
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	flags := byte(0x02) // clean session
+	if c.conf.Username != "" {
+		flags |= 0x80
+	}
+	if c.conf.Password != "" {
+		flags |= 0x40
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, protocolLevel, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(c.conf.KeepAlive/time.Second))
+	body = appendString(body, c.conf.ClientID)
+	if c.conf.Username != "" {
+		body = appendString(body, c.conf.Username)
+	}
+	if c.conf.Password != "" {
+		body = appendString(body, c.conf.Password)
+	}
+
+	if _, err := conn.Write(appendPacket(nil, typeConnect<<4, body)); err != nil {
+		return err
+	}
+
+	c.r = bufio.NewReader(conn)
+	header, ack, err := readPacket(c.r)
+	switch {
+	case err != nil:
+		return err
+	case header>>4 != typeConnAck || len(ack) != 2:
+		return fmt.Errorf("%w: expected CONNACK ; got packet type %d", ErrProtocol, header>>4)
+	case ack[1] != 0:
+		return fmt.Errorf("%w: return code %d", ErrConnRefused, ack[1])
+	}
+	return nil
+}
+
+// roundTrip sends a PUBLISH packet, and completes its acknowledgment flow as
+// per the configured QoS
+func (c *client) roundTrip(deadline time.Time, topic string, payload []byte) error {
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	header := byte(typePublish<<4) | c.conf.QoS<<1
+	if c.conf.Retain {
+		header |= 0x01
+	}
+
+	body := appendString(make([]byte, 0, len(topic)+len(payload)+4), topic)
+	var id uint16
+	if c.conf.QoS > 0 {
+		c.packetID++
+		if c.packetID == 0 {
+			c.packetID = 1
+		}
+		id = c.packetID
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	body = append(body, payload...)
+
+	if _, err := c.conn.Write(appendPacket(nil, header, body)); err != nil {
+		return err
+	}
+
+	switch c.conf.QoS {
+	case 1:
+		return c.expect(typePubAck, id)
+	case 2:
+		if err := c.expect(typePubRec, id); err != nil {
+			return err
+		}
+		if _, err := c.conn.Write(appendPacket(nil, typePubRel<<4|0x02, binary.BigEndian.AppendUint16(nil, id))); err != nil {
+			return err
+		}
+		return c.expect(typePubComp, id)
+	default:
+		return nil
+	}
+}
+
+// expect reads a packet, returning an error if it is not of type `kind` for
+// the packet ID `id`
+func (c *client) expect(kind byte, id uint16) error {
+	header, body, err := readPacket(c.r)
+	switch {
+	case err != nil:
+		return err
+	case header>>4 != kind || len(body) < 2 || binary.BigEndian.Uint16(body) != id:
+		return fmt.Errorf("%w: expected packet type %d for ID %d ; got type %d", ErrProtocol, kind, id, header>>4)
+	}
+	return nil
+}
+
+// close sends a DISCONNECT packet and closes the connection
+func (c *client) close() error {
+	c.closed = true
+	if c.conn == nil {
+		return nil
+	}
+
+	_ = c.conn.SetDeadline(time.Now().Add(c.conf.Timeout))
+	_, _ = c.conn.Write([]byte{typeDisconnect << 4, 0})
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// appendPacket appends a packet with the fixed header byte `header` and the
+// body `body` to `b`
+func appendPacket(b []byte, header byte, body []byte) []byte {
+	b = append(b, header)
+	n := len(body)
+	for {
+		digit := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if n == 0 {
+			break
+		}
+	}
+	return append(b, body...)
+}
+
+// appendString appends the string `s` to `b`, prefixed with its length
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// readPacket reads a packet from `r`, returning its fixed header byte and its
+// body
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var n, shift int
+	for {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		n |= int(digit&0x7f) << shift
+		if digit&0x80 == 0 {
+			break
+		}
+		if shift += 7; shift > 21 {
+			return 0, nil, fmt.Errorf("%w: malformed remaining length", ErrProtocol)
+		}
+	}
+
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// Capabilities implements handlers.Capable, returning CapGroups and CapBinary (as
+// supported by the default JSON encoder) along with CapRemote
+func (h mqttHandler) Capabilities() handlers.Capability {
+	return handlers.CapGroups | handlers.CapBinary | handlers.CapRemote
+}
+
+// Ping implements handlers.Pinger, connecting to the broker if needed
+func (h mqttHandler) Ping(context.Context) error {
+	h.c.mu.Lock()
+	defer h.c.mu.Unlock()
+
+	switch {
+	case h.c.closed:
+		return os.ErrClosed
+	case h.c.conn != nil:
+		return nil
+	}
+	return h.c.connect(time.Now().Add(h.c.conf.Timeout))
+}
+
+// Shutdown implements handlers.Shutdowner, disconnecting from the broker
+func (h mqttHandler) Shutdown(context.Context) error {
+	h.c.mu.Lock()
+	defer h.c.mu.Unlock()
+
+	return h.c.close()
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h mqttHandler) With(attrs ...attr.Attr) handlers.Handler {
+	return mqttHandler{
+		c:        h.c,
+		enc:      h.enc.With(attrs...),
+		levelRef: h.levelRef,
+		attrs:    attrs,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h mqttHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h mqttHandler) WithSource(addSource bool) handlers.Handler {
+	return mqttHandler{
+		c:        h.c,
+		enc:      h.enc.WithSource(addSource),
+		levelRef: h.levelRef,
+		attrs:    h.attrs,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h mqttHandler) WithLevel(level level.Leveler) handlers.Handler {
+	return mqttHandler{
+		c:        h.c,
+		enc:      h.enc,
+		levelRef: level,
+		attrs:    h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h mqttHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) handlers.Handler {
+	return mqttHandler{
+		c:        h.c,
+		enc:      h.enc.WithReplaceFn(fn),
+		levelRef: h.levelRef,
+		attrs:    h.attrs,
+	}
+}
@@ -0,0 +1,269 @@
+//go:build !logx_tiny
+
+package mqtth
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// message is a PUBLISH packet received by the fake broker
+type message struct {
+	topic   string
+	qos     byte
+	retain  bool
+	payload string
+}
+
+// broker is a fake MQTT broker recording the messages it receives
+type broker struct {
+	ln       net.Listener
+	password string
+
+	mu       sync.Mutex
+	clientID string
+	msgs     []message
+	conns    []net.Conn
+}
+
+func newBroker(t *testing.T, password string) *broker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b := &broker{ln: ln, password: password}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			b.mu.Lock()
+			b.conns = append(b.conns, conn)
+			b.mu.Unlock()
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *broker) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for {
+		header, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+
+		switch header >> 4 {
+		case typeConnect:
+			// protocol name (6), level (1), flags (1), keep alive (2)
+			flags := body[7]
+			fields := readStrings(body[10:])
+			code := byte(0)
+			if flags&0x40 != 0 && fields[len(fields)-1] != b.password {
+				code = 5 // not authorized
+			}
+			b.mu.Lock()
+			b.clientID = fields[0]
+			b.mu.Unlock()
+			_, _ = conn.Write([]byte{typeConnAck << 4, 2, 0, code})
+		case typePublish:
+			qos := header >> 1 & 0x03
+			n := int(binary.BigEndian.Uint16(body))
+			msg := message{topic: string(body[2 : 2+n]), qos: qos, retain: header&0x01 != 0}
+			body = body[2+n:]
+			var id []byte
+			if qos > 0 {
+				id, body = body[:2], body[2:]
+			}
+			msg.payload = string(body)
+
+			b.mu.Lock()
+			b.msgs = append(b.msgs, msg)
+			b.mu.Unlock()
+
+			switch qos {
+			case 1:
+				_, _ = conn.Write(appendPacket(nil, typePubAck<<4, id))
+			case 2:
+				_, _ = conn.Write(appendPacket(nil, typePubRec<<4, id))
+			}
+		case typePubRel:
+			_, _ = conn.Write(appendPacket(nil, typePubComp<<4, body))
+		case typeDisconnect:
+			return
+		}
+	}
+}
+
+func readStrings(b []byte) []string {
+	var values []string
+	for len(b) >= 2 {
+		n := int(binary.BigEndian.Uint16(b))
+		values = append(values, string(b[2:2+n]))
+		b = b[2+n:]
+	}
+	return values
+}
+
+// wait waits for the broker to receive `n` messages, as QoS 0 messages are not
+// acknowledged
+func (b *broker) wait(n int) []message {
+	deadline := time.Now().Add(time.Second)
+	for len(b.messages()) < n && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return b.messages()
+}
+
+func (b *broker) messages() []message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]message(nil), b.msgs...)
+}
+
+// drop closes the broker's side of the open connections
+func (b *broker) drop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, conn := range b.conns {
+		_ = conn.Close()
+	}
+	b.conns = nil
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("QoS", func(t *testing.T) {
+		for _, qos := range []byte{0, 1, 2} {
+			srv := newBroker(t, "secret")
+			h, err := New(Config{
+				Addr:     srv.ln.Addr().String(),
+				ClientID: "device-01",
+				Username: "fleet",
+				Password: "secret",
+				Topic:    "fleet/{device}/logs/{level}",
+				QoS:      qos,
+				Retain:   true,
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			h = h.With(attr.String("device", "d01"))
+
+			for _, msg := range []string{"booted", "ready"} {
+				if err := h.Handle(records.New(time.Unix(0, 0).Add(time.Second), level.Warn, msg)); err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+			}
+			if err := handlers.Shutdown(context.Background(), h); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			msgs := srv.wait(2)
+			if len(msgs) != 2 {
+				t.Errorf("output mismatch error: wanted %v ; got %v", 2, len(msgs))
+				return
+			}
+			if msgs[0].topic != "fleet/d01/logs/warn" || msgs[0].qos != qos || !msgs[0].retain {
+				t.Errorf("output mismatch error: got %+v", msgs[0])
+			}
+			if !strings.Contains(msgs[1].payload, `"message":"ready"`) ||
+				!strings.Contains(msgs[1].payload, `"device":"d01"`) {
+				t.Errorf("output mismatch error: got %q", msgs[1].payload)
+			}
+		}
+	})
+	t.Run("RecordTopic", func(t *testing.T) {
+		srv := newBroker(t, "")
+		h, _ := New(Config{Addr: srv.ln.Addr().String(), Topic: "fleet/{device}/{level}"})
+		defer handlers.Shutdown(context.Background(), h)
+
+		r := records.New(time.Now(), level.Error, "overheat", attr.String("device", "d02"))
+		if err := h.Handle(r); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		msgs := srv.wait(1)
+		if len(msgs) != 1 || msgs[0].topic != "fleet/d02/error" {
+			t.Errorf("output mismatch error: got %+v", msgs)
+		}
+	})
+	t.Run("Reconnect", func(t *testing.T) {
+		srv := newBroker(t, "")
+		h, _ := New(Config{Addr: srv.ln.Addr().String(), Topic: "logs", QoS: 1})
+		defer handlers.Shutdown(context.Background(), h)
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "first"))
+		srv.drop()
+
+		// the first record after the connection is lost fails
+		if err := h.Handle(records.New(time.Now(), level.Info, "second")); err == nil {
+			t.Errorf("expected an error after the connection is lost")
+		}
+		if err := h.Handle(records.New(time.Now(), level.Info, "second")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		msgs := srv.messages()
+		if last := msgs[len(msgs)-1]; !strings.Contains(last.payload, `"message":"second"`) {
+			t.Errorf("output mismatch error: got %q", last.payload)
+		}
+	})
+	t.Run("ConnRefused", func(t *testing.T) {
+		srv := newBroker(t, "secret")
+		h, _ := New(Config{Addr: srv.ln.Addr().String(), Password: "wrong", Topic: "logs"})
+
+		if err := h.Handle(records.New(time.Now(), level.Info, "entry")); !errors.Is(err, ErrConnRefused) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrConnRefused, err)
+		}
+	})
+	t.Run("Ping", func(t *testing.T) {
+		srv := newBroker(t, "")
+		h, _ := New(Config{Addr: srv.ln.Addr().String(), Topic: "logs"})
+
+		if err := handlers.Ping(context.Background(), h); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if id := func() string {
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			return srv.clientID
+		}(); !strings.HasPrefix(id, "logx-") {
+			t.Errorf("output mismatch error: got client ID %q", id)
+		}
+		_ = handlers.Shutdown(context.Background(), h)
+		if err := handlers.Ping(context.Background(), h); err == nil {
+			t.Errorf("expected an error after shutdown")
+		}
+	})
+	t.Run("Config", func(t *testing.T) {
+		if _, err := New(Config{}); !errors.Is(err, ErrNoTopic) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrNoTopic, err)
+		}
+		if _, err := New(Config{Topic: "logs", QoS: 3}); !errors.Is(err, ErrInvalidQoS) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrInvalidQoS, err)
+		}
+	})
+}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/zalgonoise/attr"
 	"github.com/zalgonoise/logx/level"
@@ -10,99 +13,243 @@ import (
 
 type multiHandler struct {
 	handlers []Handler
+	sem      chan struct{}
 }
 
 // Multi will take any number of Handlers and return a multiHandler
 // that batches the method calls similarly across all Handlers
 //
+// The Handlers reporting their capabilities (see Capable) are adapted to the
+// attributes they do not support, with Adapt. Nil Handlers are skipped, and
+// sequential multiHandlers are flattened into
+// the returned one, so that nested Multi calls dispatch records with a single
+// loop. If only one Handler is set, it is returned as-is.
+//
 // The resulting multiHandler is immutable
 func Multi(h ...Handler) Handler {
-	switch len(h) {
+	var (
+		size int
+		last Handler
+		set  int
+	)
+	for _, handler := range h {
+		if handler == nil {
+			continue
+		}
+		set++
+		last = handler
+		if mh, ok := handler.(multiHandler); ok && mh.sem == nil {
+			size += len(mh.handlers)
+			continue
+		}
+		size++
+	}
+
+	switch set {
 	case 0:
 		return nil
 	case 1:
-		return h[0]
-	default:
-		var handlers []Handler
-		for _, handler := range h {
-			if handler == nil {
-				continue
-			}
-			if mh, ok := handler.(multiHandler); ok {
-				handlers = append(handlers, mh.handlers...)
-				continue
-			}
-			handlers = append(handlers, handler)
-		}
-		return multiHandler{
-			handlers: handlers,
-		}
+		return last
+	}
+
+	handlers := make([]Handler, 0, size)
+	for _, handler := range h {
+		handlers = appendFlat(handlers, handler)
+	}
+	for i := range handlers {
+		handlers[i] = Adapt(handlers[i])
+	}
+	return multiHandler{
+		handlers: handlers,
+	}
+}
+
+// appendFlat appends the Handler `h` to `handlers`, or the Handlers within it
+// if it is a sequential multiHandler. Nil Handlers are skipped
+func appendFlat(handlers []Handler, h Handler) []Handler {
+	if h == nil {
+		return handlers
+	}
+	if mh, ok := h.(multiHandler); ok && mh.sem == nil {
+		return append(handlers, mh.handlers...)
 	}
+	return append(handlers, h)
+}
+
+// MultiParallel is similar to Multi, but it dispatches each record to all
+// Handlers concurrently, with at most `workers` Handle calls in flight at once
+// (shared by all Handlers derived from it). Handle returns once all Handlers
+// are done.
+//
+// This is useful for fan-outs that include slow (e.g. network) Handlers. If
+// `workers` is not greater than one, it behaves like Multi
+func MultiParallel(workers int, h ...Handler) Handler {
+	handler := Multi(h...)
+	mh, ok := handler.(multiHandler)
+	if !ok || workers <= 1 {
+		return handler
+	}
+
+	mh.sem = make(chan struct{}, workers)
+	return mh
 }
 
 // Enabled returns a boolean on whether the Handler is accepting
 // records with log level `level`
+//
+// It returns true if any of the Handlers accepts the level, leaving the
+// filtering of the records to each Handler's Handle method
 func (mh multiHandler) Enabled(level level.Level) bool {
 	for _, h := range mh.handlers {
-		if ok := h.Enabled(level); !ok {
-			return false // first handler filtering this level returns false
+		if ok := h.Enabled(level); ok {
+			return true // first handler accepting this level returns true
 		}
 	}
-	return true // all handlers accept this level
+	return false // no handler accepts this level
 }
 
 // Handle will process the input Record, returning an error if raised
 func (mh multiHandler) Handle(r records.Record) error {
-	var err error
+	if mh.sem != nil {
+		return mh.handleParallel(r)
+	}
+
+	errs := make([]error, len(mh.handlers))
+	for idx, h := range mh.handlers {
+		errs[idx] = h.Handle(r)
+	}
+	return mh.join(errs)
+}
+
+// Pressure implements Pressurer, returning the highest pressure among all
+// Handlers
+func (mh multiHandler) Pressure() float64 {
+	var highest float64
+	for _, handler := range mh.handlers {
+		if p := Pressure(handler); p > highest {
+			highest = p
+		}
+	}
+	return highest
+}
+
+// Capabilities implements Capable, returning CapGroups and CapBinary if all
+// Handlers reporting their capabilities support them, and CapFlush and
+// CapRemote if any of them does
+func (mh multiHandler) Capabilities() Capability {
+	caps := CapGroups | CapBinary
+	for _, handler := range mh.handlers {
+		if c, ok := Capabilities(handler); ok {
+			caps = caps&(c|CapFlush|CapRemote) | c&(CapFlush|CapRemote)
+		}
+	}
+	return caps
+}
+
+// Ping implements Pinger, checking the health of all Handlers
+func (mh multiHandler) Ping(ctx context.Context) error {
+	errs := make([]error, len(mh.handlers))
+	for idx, h := range mh.handlers {
+		errs[idx] = Ping(ctx, h)
+	}
+	return mh.join(errs)
+}
+
+// Shutdown implements Shutdowner, shutting down all Handlers
+func (mh multiHandler) Shutdown(ctx context.Context) error {
+	errs := make([]error, len(mh.handlers))
+	for idx, h := range mh.handlers {
+		errs[idx] = Shutdown(ctx, h)
+	}
+	return mh.join(errs)
+}
+
+func (mh multiHandler) handleParallel(r records.Record) error {
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(mh.handlers))
+	)
+
+	for idx, h := range mh.handlers {
+		mh.sem <- struct{}{}
+		wg.Add(1)
+		go func(idx int, h Handler) {
+			defer func() {
+				<-mh.sem
+				wg.Done()
+			}()
+			errs[idx] = h.Handle(r)
+		}(idx, h)
+	}
+	wg.Wait()
+
+	return mh.join(errs)
+}
+
+// join aggregates the errors `errs` returned by each of the Handlers (in the
+// same order) with errors.Join, annotating each one with the position and type
+// of the Handler that raised it. All errors are kept in the chain, for
+// errors.Is and errors.As
+func (mh multiHandler) join(errs []error) error {
+	var joined []error
+	for idx, err := range errs {
+		if err != nil {
+			joined = append(joined, fmt.Errorf("handler #%d (%T): %w", idx, mh.handlers[idx], err))
+		}
+	}
+	return errors.Join(joined...)
+}
+
+// derive spawns a copy of this multiHandler with each of its Handlers replaced
+// by the result of calling `fn` on it, keeping its dispatch mode. The derived
+// Handlers are written to a single slice, sized upfront
+func (mh multiHandler) derive(fn func(Handler) Handler) Handler {
+	handlers := make([]Handler, 0, len(mh.handlers))
 	for _, h := range mh.handlers {
-		handlerErr := h.Handle(r)
-		if handlerErr != nil {
-			if err == nil {
-				err = handlerErr
-				continue
-			}
-			err = fmt.Errorf("%v -- %w", handlerErr, err)
+		handlers = appendFlat(handlers, fn(h))
+	}
+
+	switch len(handlers) {
+	case 0:
+		return nil
+	case 1:
+		return handlers[0]
+	default:
+		return multiHandler{
+			handlers: handlers,
+			sem:      mh.sem,
 		}
 	}
-	return err
 }
 
 // With will spawn a copy of this Handler with the input attributes
 // `attrs`
 func (mh multiHandler) With(attrs ...attr.Attr) Handler {
-	newHandlers := make([]Handler, len(mh.handlers), len(mh.handlers))
-	for idx, h := range mh.handlers {
-		newHandlers[idx] = h.With(attrs...)
-	}
-	return Multi(newHandlers...)
+	return mh.derive(func(h Handler) Handler {
+		return h.With(attrs...)
+	})
 }
 
 // WithSource will spawn a new copy of this Handler with the setting
 // to add a source file+line reference to `addSource` boolean
 func (mh multiHandler) WithSource(addSource bool) Handler {
-	newHandlers := make([]Handler, len(mh.handlers), len(mh.handlers))
-	for idx, h := range mh.handlers {
-		newHandlers[idx] = h.WithSource(addSource)
-	}
-	return Multi(newHandlers...)
+	return mh.derive(func(h Handler) Handler {
+		return h.WithSource(addSource)
+	})
 }
 
-// WithLevel will spawn a copy of this Handler with the input level `level`
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
 // as a verbosity filter
-func (mh multiHandler) WithLevel(level level.Level) Handler {
-	newHandlers := make([]Handler, len(mh.handlers), len(mh.handlers))
-	for idx, h := range mh.handlers {
-		newHandlers[idx] = h.WithLevel(level)
-	}
-	return Multi(newHandlers...)
+func (mh multiHandler) WithLevel(level level.Leveler) Handler {
+	return mh.derive(func(h Handler) Handler {
+		return h.WithLevel(level)
+	})
 }
 
 // WithReplaceFn will spawn a copy of this Handler with the input attribute
 // replace function `fn`
 func (mh multiHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
-	newHandlers := make([]Handler, len(mh.handlers), len(mh.handlers))
-	for idx, h := range mh.handlers {
-		newHandlers[idx] = h.WithReplaceFn(fn)
-	}
-	return Multi(newHandlers...)
+	return mh.derive(func(h Handler) Handler {
+		return h.WithReplaceFn(fn)
+	})
 }
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestMulti(t *testing.T) {
+	t.Run("Flatten", func(t *testing.T) {
+		th := newTestHandler()
+		h := Multi(Multi(th, th), nil, Multi(th, Multi(th, th)))
+
+		mh, ok := h.(multiHandler)
+		if !ok || len(mh.handlers) != 5 || cap(mh.handlers) != 5 {
+			t.Errorf("output mismatch error: wanted a flat multi handler with 5 handlers ; got %v", h)
+			return
+		}
+
+		derived, ok := mh.WithLevel(level.Warn).(multiHandler)
+		if !ok || len(derived.handlers) != 5 {
+			t.Errorf("output mismatch error: wanted a flat multi handler with 5 handlers ; got %v", derived)
+		}
+		if &derived.handlers[0] == &mh.handlers[0] {
+			t.Errorf("expected the derived handler not to share the original's handlers")
+		}
+	})
+	t.Run("Single", func(t *testing.T) {
+		th := newTestHandler()
+		if h := Multi(nil, th, nil); h != Handler(th) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", th, h)
+		}
+		if h := Multi(nil, nil); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+	t.Run("JoinedErrors", func(t *testing.T) {
+		errFirst := errors.New("first")
+		errSecond := errors.New("second")
+
+		th1, th2, th3 := newTestHandler(), newTestHandler(), newTestHandler()
+		th1.err, th3.err = errFirst, errSecond
+
+		err := Multi(th1, th2, th3).Handle(records.New(time.Now(), level.Info, "test message"))
+		for _, target := range []error{errFirst, errSecond} {
+			if !errors.Is(err, target) {
+				t.Errorf("unexpected error: wanted %v ; got %v", target, err)
+			}
+		}
+
+		joined, ok := err.(interface{ Unwrap() []error })
+		if !ok || len(joined.Unwrap()) != 2 {
+			t.Errorf("output mismatch error: wanted %v joined errors ; got %v", 2, err)
+			return
+		}
+		if msg := joined.Unwrap()[1].Error(); !strings.HasPrefix(msg, "handler #2 (") || !strings.HasSuffix(msg, "): second") {
+			t.Errorf("output mismatch error: wanted the failing handler's identity ; got %q", msg)
+		}
+		if len(th2.Records()) != 1 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 1, len(th2.Records()))
+		}
+	})
+}
+
+func TestMultiParallel(t *testing.T) {
+	t.Run("Concurrent", func(t *testing.T) {
+		gh := newGatedHandler()
+		h := MultiParallel(3, gh, gh, gh)
+
+		done := make(chan error)
+		go func() {
+			done <- h.Handle(records.New(time.Now(), level.Info, "test message"))
+		}()
+
+		// all three Handle calls start before any of them returns
+		for i := 0; i < 3; i++ {
+			<-gh.started
+		}
+		close(gh.gate)
+
+		if err := <-done; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(gh.Records()) != 3 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 3, len(gh.Records()))
+		}
+	})
+	t.Run("BoundedWorkers", func(t *testing.T) {
+		gh := newGatedHandler()
+		h := MultiParallel(2, gh, gh, gh)
+
+		done := make(chan error)
+		go func() {
+			done <- h.Handle(records.New(time.Now(), level.Info, "test message"))
+		}()
+
+		<-gh.started
+		<-gh.started
+		select {
+		case <-gh.started:
+			t.Errorf("expected at most 2 Handle calls in flight")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(gh.gate)
+		<-done
+	})
+	t.Run("Errors", func(t *testing.T) {
+		errFirst := errors.New("first")
+		errSecond := errors.New("second")
+
+		th1, th2 := newTestHandler(), newTestHandler()
+		th1.err, th2.err = errFirst, errSecond
+
+		err := MultiParallel(2, th1, th2).Handle(records.New(time.Now(), level.Info, "test message"))
+		if !errors.Is(err, errFirst) {
+			t.Errorf("unexpected error: wanted %v ; got %v", errFirst, err)
+		}
+	})
+	t.Run("DerivedStaysParallel", func(t *testing.T) {
+		h := MultiParallel(2, newTestHandler(), newTestHandler()).WithLevel(level.Warn)
+
+		if mh, ok := h.(multiHandler); !ok || mh.sem == nil {
+			t.Errorf("expected the derived handler to dispatch in parallel")
+		}
+	})
+	t.Run("SingleWorker", func(t *testing.T) {
+		h := MultiParallel(1, newTestHandler(), newTestHandler())
+
+		if mh, ok := h.(multiHandler); !ok || mh.sem != nil {
+			t.Errorf("expected a sequential multi handler")
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import "strings"
+
+// MultilineMode sets how text outputs (like the texth and Console handlers)
+// render the messages and values spanning several lines, like stack traces or
+// pretty-printed JSON
+type MultilineMode int
+
+const (
+	// MultilineRaw writes the multi-line messages and values as they are
+	MultilineRaw MultilineMode = iota
+	// MultilineEscape escapes the line breaks in messages and values (as `\n`),
+	// so that each record is written on a single line
+	MultilineEscape
+	// MultilineFold writes the first line of multi-line messages on the
+	// record's line, and the multi-line values below it, indented under their
+	// key, so that the records can be joined back by the agents that treat the
+	// lines starting with whitespace as continuations:
+	//
+	//	[2024-01-02T15:04:05Z] [error] request failed [ path: /items ]
+	//	  stack:
+	//	    goroutine 1 [running]:
+	//	    main.main()
+	MultilineFold
+)
+
+const (
+	foldIndent = "  "
+
+	lineBreaks = "\n\r"
+)
+
+var lineEscaper = strings.NewReplacer("\r\n", `\n`, "\n", `\n`, "\r", `\r`)
+
+// Multiline returns the text of the attribute value `v` (a string or an
+// error) if it spans several lines
+func Multiline(v any) (string, bool) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case error:
+		s = v.Error()
+	default:
+		return "", false
+	}
+	return s, strings.ContainsAny(s, lineBreaks)
+}
+
+// EscapeLines returns the string `s` with its line breaks escaped as `\n` (or
+// `\r`)
+func EscapeLines(s string) string {
+	if !strings.ContainsAny(s, lineBreaks) {
+		return s
+	}
+	return lineEscaper.Replace(s)
+}
+
+// CutFirstLine returns the first line of the string `s`, and the lines after
+// it
+func CutFirstLine(s string) (first, rest string) {
+	i := strings.IndexAny(s, lineBreaks)
+	if i < 0 {
+		return s, ""
+	}
+	first, rest = s[:i], s[i+1:]
+	if s[i] == '\r' && strings.HasPrefix(rest, "\n") {
+		rest = rest[1:]
+	}
+	return first, rest
+}
+
+// AppendFolded appends the lines of the string `s` to `b` as a folded block:
+// under the line `key:` and indented by four spaces, or indented by two spaces
+// if `key` is empty, each one ending with a line break
+func AppendFolded(b []byte, key, s string) []byte {
+	indent := foldIndent
+	if key != "" {
+		b = append(b, foldIndent...)
+		b = append(b, key...)
+		b = append(b, ':', '\n')
+		indent += foldIndent
+	}
+
+	s = strings.TrimRight(s, lineBreaks)
+	for s != "" {
+		var line string
+		line, s = CutFirstLine(s)
+		b = append(b, indent...)
+		b = append(b, line...)
+		b = append(b, '\n')
+	}
+	return b
+}
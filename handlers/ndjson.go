@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// SyncPolicy sets when an NDJSON file is committed to disk with fsync. If both
+// of its fields are zero, the file is only synced when closed
+type SyncPolicy struct {
+	// Records syncs the file after this many records are written; with 1,
+	// every record is synced before its Write call returns
+	Records int
+	// Interval syncs the file at most this long after a record is written
+	Interval time.Duration
+}
+
+type ndjsonFile struct {
+	mu      sync.Mutex
+	f       *os.File
+	size    int64
+	policy  SyncPolicy
+	pending int
+	timer   *time.Timer
+	buf     []byte
+}
+
+// NDJSONFile creates an io.WriteCloser that appends newline-delimited JSON
+// records to the file in `path`, syncing it to disk as set in the SyncPolicy
+// `policy`, so that the file is always parseable after a crash:
+//   - when opened, a partially-written trailing record (without a line break,
+//     or which is not valid JSON) is truncated away;
+//   - if a write fails midway, the file is truncated back to its last record.
+//
+// It is meant to be used with a JSON Handler, which writes each record in a
+// single Write call; records not ending in a line break are terminated with
+// one:
+//
+//	w, err := handlers.NDJSONFile("app.log", handlers.SyncPolicy{Interval: time.Second})
+//	if err != nil {
+//		// ...
+//	}
+//	logger := logx.New(logx.WithHandler(jsonh.New(w)))
+func NDJSONFile(path string, policy SyncPolicy) (io.WriteCloser, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := recoverNDJSON(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return &ndjsonFile{
+		f:      f,
+		size:   size,
+		policy: policy,
+	}, nil
+}
+
+// Write implements io.Writer, syncing the file if due as per its SyncPolicy
+func (w *ndjsonFile) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return 0, os.ErrClosed
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	record := p
+	if p[len(p)-1] != '\n' {
+		// the record is written in a single call along with its line break
+		w.buf = append(append(w.buf[:0], p...), '\n')
+		record = w.buf
+	}
+
+	n, err := w.f.Write(record)
+	if err != nil {
+		// drop the partial record, keeping the file parseable
+		if n > 0 {
+			_ = w.f.Truncate(w.size)
+		}
+		return 0, err
+	}
+	w.size += int64(n)
+	w.pending++
+
+	switch {
+	case w.policy.Records > 0 && w.pending >= w.policy.Records:
+		return len(p), w.sync()
+	case w.policy.Interval > 0 && w.timer == nil:
+		w.timer = time.AfterFunc(w.policy.Interval, func() {
+			_ = w.Sync()
+		})
+	}
+	return len(p), nil
+}
+
+// Sync implements WriteSyncer, committing the file's contents to disk
+func (w *ndjsonFile) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return os.ErrClosed
+	}
+	return w.sync()
+}
+
+// Close implements io.Closer, syncing and closing the file
+func (w *ndjsonFile) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return nil
+	}
+
+	syncErr := w.sync()
+	err := w.f.Close()
+	w.f = nil
+	if syncErr != nil {
+		return syncErr
+	}
+	return err
+}
+
+// Ping implements Pinger, returning an error if the file is closed or can no
+// longer be accessed
+func (w *ndjsonFile) Ping(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return os.ErrClosed
+	}
+	_, err := w.f.Stat()
+	return err
+}
+
+// Shutdown implements Shutdowner, syncing and closing the file
+func (w *ndjsonFile) Shutdown(context.Context) error {
+	return w.Close()
+}
+
+func (w *ndjsonFile) sync() error {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if w.pending == 0 {
+		return nil
+	}
+	w.pending = 0
+	return w.f.Sync()
+}
+
+// recoverNDJSON truncates a partially-written trailing record from the file
+// `f`, returning its resulting size
+func recoverNDJSON(f *os.File) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return 0, nil
+	}
+
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, size-1); err != nil {
+		return 0, err
+	}
+
+	end := size
+	if last[0] != '\n' {
+		// the trailing record was not fully written
+		if end, err = lineStart(f, size); err != nil {
+			return 0, err
+		}
+	}
+
+	if end > 0 {
+		start, err := lineStart(f, end-1)
+		if err != nil {
+			return 0, err
+		}
+
+		line := make([]byte, end-start)
+		if _, err := f.ReadAt(line, start); err != nil {
+			return 0, err
+		}
+		if !json.Valid(line) {
+			end = start
+		}
+	}
+
+	if end == size {
+		return size, nil
+	}
+	if err := f.Truncate(end); err != nil {
+		return 0, err
+	}
+	return end, f.Sync()
+}
+
+// lineStart returns the offset of the start of the line that ends at the
+// offset `end` of the file `f`, after the previous line break
+func lineStart(f *os.File, end int64) (int64, error) {
+	const chunkSize = 4096
+	chunk := make([]byte, chunkSize)
+
+	for end > 0 {
+		off := max(end-chunkSize, 0)
+		n, err := f.ReadAt(chunk[:end-off], off)
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if idx := bytes.LastIndexByte(chunk[:n], '\n'); idx >= 0 {
+			return off + int64(idx) + 1, nil
+		}
+		end = off
+	}
+	return 0, nil
+}
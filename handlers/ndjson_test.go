@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONFile(t *testing.T) {
+	t.Run("Recover", func(t *testing.T) {
+		for _, test := range []struct {
+			name  string
+			data  string
+			wants string
+		}{
+			{"Empty", "", ""},
+			{"Intact", "{\"a\":1}\n{\"b\":2}\n", "{\"a\":1}\n{\"b\":2}\n"},
+			{"PartialLine", "{\"a\":1}\n{\"b\":", "{\"a\":1}\n"},
+			{"OnlyPartialLine", "{\"a\":", ""},
+			{"InvalidLine", "{\"a\":1}\n{\"b\":\x00}\n", "{\"a\":1}\n"},
+			{"LongLine", "{\"a\":1}\n{\"b\":\"" + strings.Repeat("x", 10000), "{\"a\":1}\n"},
+		} {
+			t.Run(test.name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "app.log")
+				if err := os.WriteFile(path, []byte(test.data), 0o644); err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+
+				w, err := NDJSONFile(path, SyncPolicy{})
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				_, _ = w.Write([]byte("{\"c\":3}\n"))
+				_ = w.Close()
+
+				data, _ := os.ReadFile(path)
+				if string(data) != test.wants+"{\"c\":3}\n" {
+					t.Errorf("output mismatch error: wanted %q ; got %q", test.wants+"{\"c\":3}\n", data)
+				}
+			})
+		}
+	})
+	t.Run("SyncRecords", func(t *testing.T) {
+		w, err := NDJSONFile(filepath.Join(t.TempDir(), "app.log"), SyncPolicy{Records: 2})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		defer w.Close()
+
+		f := w.(*ndjsonFile)
+		for i, wants := range []int{1, 0, 1} {
+			_, _ = w.Write([]byte("{}\n"))
+			if f.pending != wants {
+				t.Errorf("output mismatch error: write #%d: wanted %d pending records ; got %d", i, wants, f.pending)
+			}
+		}
+	})
+	t.Run("Closed", func(t *testing.T) {
+		w, _ := NDJSONFile(filepath.Join(t.TempDir(), "app.log"), SyncPolicy{})
+		_ = w.Close()
+		if _, err := w.Write([]byte("{}\n")); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("unexpected error: wanted %v ; got %v", os.ErrClosed, err)
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import "context"
+
+// Pinger is implemented by Handlers and writers that are able to report on the
+// health of their underlying sink (like a file or a network connection), so
+// that it can be included in a service's readiness checks
+type Pinger interface {
+	// Ping returns an error if the sink is unable to accept records
+	Ping(ctx context.Context) error
+}
+
+// Ping checks the health of the Handler or writer `v`, if it implements
+// Pinger. Otherwise, it is considered healthy and Ping returns nil
+func Ping(ctx context.Context, v any) error {
+	if p, ok := v.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
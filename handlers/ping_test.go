@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type pingHandler struct {
+	testHandler
+	err error
+}
+
+func (h pingHandler) Ping(context.Context) error { return h.err }
+
+func TestPing(t *testing.T) {
+	errPing := errors.New("sink unavailable")
+
+	t.Run("NotAPinger", func(t *testing.T) {
+		if err := Ping(context.Background(), newTestHandler()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("Multi", func(t *testing.T) {
+		h := Multi(
+			pingHandler{testHandler: newTestHandler()},
+			Sample(pingHandler{testHandler: newTestHandler(), err: errPing}, 2),
+		)
+
+		if err := Ping(context.Background(), h); !errors.Is(err, errPing) {
+			t.Errorf("unexpected error: wanted %v ; got %v", errPing, err)
+		}
+	})
+	t.Run("Swap", func(t *testing.T) {
+		s := NewSwap(pingHandler{testHandler: newTestHandler()})
+
+		if err := Ping(context.Background(), s); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		s.Store(pingHandler{testHandler: newTestHandler(), err: errPing})
+		if err := Ping(context.Background(), s); !errors.Is(err, errPing) {
+			t.Errorf("unexpected error: wanted %v ; got %v", errPing, err)
+		}
+	})
+	t.Run("Async", func(t *testing.T) {
+		a := NewAsync(newTestHandler(), 1, DropNewest, 0)
+
+		if err := Ping(context.Background(), a); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		_ = a.Close()
+		if err := Ping(context.Background(), a); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("unexpected error: wanted %v ; got %v", os.ErrClosed, err)
+		}
+	})
+	t.Run("Writers", func(t *testing.T) {
+		w, err := Rotate(filepath.Join(t.TempDir(), "test.log"), 1024, 0)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		b := Batch(Lock(w), 0, 0)
+
+		if err := Ping(context.Background(), b); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		_ = w.Close()
+		if err := Ping(context.Background(), b); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("unexpected error: wanted %v ; got %v", os.ErrClosed, err)
+		}
+		_ = b.Close()
+	})
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import "github.com/zalgonoise/logx/level"
+
+// Pressurer is implemented by Handlers and writers that queue or buffer
+// records (like Async, Batch and Spool), reporting how saturated they are, so
+// that applications can shed optional logging before records are dropped
+type Pressurer interface {
+	// Pressure returns the fill ratio of the queue or buffer, from 0 (empty)
+	// to 1 (full)
+	Pressure() float64
+}
+
+// Pressure returns the pressure of the Handler or writer `v`, if it
+// implements Pressurer. Otherwise, it never saturates and Pressure returns 0
+func Pressure(v any) float64 {
+	if p, ok := v.(Pressurer); ok {
+		return p.Pressure()
+	}
+	return 0
+}
+
+type shedLeveler struct {
+	p         Pressurer
+	threshold float64
+	normal    level.Leveler
+	shed      level.Leveler
+}
+
+// ShedLevel returns a Leveler that yields the `normal` Level, or the `shed`
+// Level while the pressure of `p` is at or above `threshold`. Used as a
+// Handler's verbosity filter, it drops the less important records while the
+// pipeline is saturated:
+//
+//	async := handlers.NewAsync(h, 1024, handlers.DropNewest, 0)
+//	logger := logx.New(logx.WithHandler(
+//		async.WithLevel(handlers.ShedLevel(async, 0.8, level.Debug, level.Warn)),
+//	))
+func ShedLevel(p Pressurer, threshold float64, normal, shed level.Leveler) level.Leveler {
+	return shedLeveler{
+		p:         p,
+		threshold: threshold,
+		normal:    normal,
+		shed:      shed,
+	}
+}
+
+// Level implements level.Leveler
+func (s shedLeveler) Level() level.Level {
+	if s.p.Pressure() >= s.threshold {
+		return s.shed.Level()
+	}
+	return s.normal.Level()
+}
+
+// ratio returns n / size, capped to 1
+func ratio(n, size int64) float64 {
+	if size <= 0 || n >= size {
+		return 1
+	}
+	return float64(n) / float64(size)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestPressure(t *testing.T) {
+	t.Run("Async", func(t *testing.T) {
+		gh := newGatedHandler()
+		a := NewAsync(gh, 4, DropNewest, 0)
+		defer a.Close()
+		defer close(gh.gate)
+
+		shed := a.WithLevel(ShedLevel(a, 0.5, level.Debug, level.Warn))
+		if !shed.Enabled(level.Debug) {
+			t.Errorf("expected debug records to be enabled with an empty queue")
+		}
+
+		// the first record is taken by the worker, blocking on the gate
+		_ = a.Handle(records.New(time.Now(), level.Info, "first"))
+		<-gh.started
+		for i := 0; i < 2; i++ {
+			_ = a.Handle(records.New(time.Now(), level.Info, "queued"))
+		}
+
+		if p := Pressure(a); p != 0.5 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 0.5, p)
+		}
+		if shed.Enabled(level.Debug) {
+			t.Errorf("expected debug records to be shed with a half-full queue")
+		}
+		if !shed.Enabled(level.Warn) {
+			t.Errorf("expected warn records to be enabled with a half-full queue")
+		}
+	})
+	t.Run("Batch", func(t *testing.T) {
+		b := Batch(&countingWriter{}, 0, 10)
+		defer b.Close()
+
+		_, _ = b.Write([]byte("abcd"))
+		if p := Pressure(b); p != 0.4 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 0.4, p)
+		}
+	})
+	t.Run("Multi", func(t *testing.T) {
+		b := Batch(&countingWriter{}, 0, 10)
+		defer b.Close()
+		_, _ = b.Write([]byte("abcdefgh"))
+
+		a := NewAsync(newTestHandler(), 4, DropNewest, 0)
+		defer a.Close()
+
+		if p := Pressure(Multi(a, newTestHandler())); p != 0 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 0, p)
+		}
+		if p := Pressure(Multi(a, pressureHandler{newTestHandler(), b})); p != 0.8 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 0.8, p)
+		}
+	})
+	t.Run("Unimplemented", func(t *testing.T) {
+		if p := Pressure(newTestHandler()); p != 0 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 0, p)
+		}
+	})
+}
+
+// pressureHandler is a testHandler reporting the pressure of a writer
+type pressureHandler struct {
+	testHandler
+	w any
+}
+
+func (h pressureHandler) Pressure() float64 {
+	return Pressure(h.w)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"os"
+
+	"github.com/zalgonoise/attr"
+)
+
+// ProcessInfo returns a static Enricher with the host name, process ID and
+// `service` name as attributes. If `service` is empty, it is omitted
+func ProcessInfo(service string) Enricher {
+	attrs := make([]attr.Attr, 0, 3)
+	if hostname, err := os.Hostname(); err == nil {
+		attrs = append(attrs, attr.String("hostname", hostname))
+	}
+	attrs = append(attrs, attr.Int("pid", os.Getpid()))
+	if service != "" {
+		attrs = append(attrs, attr.String("service", service))
+	}
+
+	return staticEnricher{attrs: attrs}
+}
+
+// WithProcessInfo decorates the Handler `h` so that every Record it handles
+// carries the host name, process ID and `service` name as attributes, resolved
+// once (see Enricher). If `service` is empty, it is omitted
+func WithProcessInfo(h Handler, service string) Handler {
+	return Enrich(h, ProcessInfo(service))
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestWithProcessInfo(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		th := newTestHandler()
+		h := WithProcessInfo(th, "logx")
+
+		if err := h.Handle(records.New(time.Now(), level.Info, "test message")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		out := attr.Map(th.Records()[0].Attrs()...)
+		if out["pid"] != int64(os.Getpid()) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", os.Getpid(), out["pid"])
+		}
+		if out["service"] != "logx" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "logx", out["service"])
+		}
+		if hostname, _ := os.Hostname(); out["hostname"] != hostname {
+			t.Errorf("output mismatch error: wanted %v ; got %v", hostname, out["hostname"])
+		}
+	})
+	t.Run("NoService", func(t *testing.T) {
+		th := newTestHandler()
+		h := WithProcessInfo(th, "")
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "test message"))
+
+		if _, ok := attr.Map(th.Records()[0].Attrs()...)["service"]; ok {
+			t.Errorf("expected service attribute to be omitted")
+		}
+	})
+	t.Run("NilHandler", func(t *testing.T) {
+		if h := WithProcessInfo(nil, "logx"); h != nil {
+			t.Errorf("expected output handler to be nil")
+		}
+	})
+}
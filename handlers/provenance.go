@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	defaultProvenanceKey = "provenance"
+
+	// untracked is the origin reported for the attributes without one, like
+	// the ones passed to a log call
+	untracked = "untracked"
+)
+
+var provenance atomic.Bool
+
+// TrackProvenance enables or disables the tracking of the attributes'
+// provenance, a debug mode where the attributes attached to the Loggers and
+// Handlers record where they were attached: the call site of a Logger's With
+// method (including the ones in the logx middleware), the Enrich decorator
+// that added them, or the Extractors of a context-aware log call. The
+// provenance is then written by a Provenance decorator, to find out why
+// unexpected fields appear in the output.
+//
+// It is disabled by default, and should be enabled before the Loggers and
+// Handlers are set up, as only the attributes attached while it is enabled are
+// tracked. Tracking adds a call to runtime.Caller to each With call
+func TrackProvenance(enabled bool) {
+	provenance.Store(enabled)
+}
+
+type tracedAttr struct {
+	attr.Attr
+	origin string
+}
+
+// WithKey returns a copy of this Attr, with key `key`, keeping its origin
+func (a tracedAttr) WithKey(key string) attr.Attr {
+	return tracedAttr{Attr: a.Attr.WithKey(key), origin: a.origin}
+}
+
+// WithValue returns a copy of this Attr, with value `value`, keeping its
+// origin. It returns nil if `value` is not of the same type as the original
+func (a tracedAttr) WithValue(value any) attr.Attr {
+	v := a.Attr.WithValue(value)
+	if v == nil {
+		return nil
+	}
+	return tracedAttr{Attr: v, origin: a.origin}
+}
+
+// String implements fmt.Stringer, like the original Attr
+func (a tracedAttr) String() string {
+	return fmt.Sprint(a.Attr)
+}
+
+// Traced returns the attributes `attrs` marked as attached by `origin` (like
+// `session cache`), if provenance tracking is enabled (see TrackProvenance).
+// Otherwise, or if `origin` is empty, `attrs` is returned as-is. The
+// attributes that are already marked keep their first origin
+func Traced(origin string, attrs ...attr.Attr) []attr.Attr {
+	if origin == "" || len(attrs) == 0 || !provenance.Load() {
+		return attrs
+	}
+
+	traced := make([]attr.Attr, len(attrs))
+	for i, a := range attrs {
+		switch a.(type) {
+		case nil, tracedAttr:
+			traced[i] = a
+		default:
+			traced[i] = tracedAttr{Attr: a, origin: origin}
+		}
+	}
+	return traced
+}
+
+// TracedCaller returns the attributes `attrs` marked as attached by `kind`
+// at the call site of its caller, `skip` frames up; like `With
+// logxhttp/middleware.go:143` for the With method's caller, with a `skip` of
+// 1. It returns `attrs` as-is if provenance tracking is disabled (see
+// TrackProvenance)
+func TracedCaller(kind string, skip int, attrs ...attr.Attr) []attr.Attr {
+	if len(attrs) == 0 || !provenance.Load() {
+		return attrs
+	}
+	return Traced(callerOrigin(kind, skip+1), attrs...)
+}
+
+// callerOrigin returns `kind` along with the call site of its caller, `skip`
+// frames up, or an empty string if provenance tracking is disabled
+func callerOrigin(kind string, skip int) string {
+	if !provenance.Load() {
+		return ""
+	}
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return kind
+	}
+	return kind + " " + lastElements(filepath.ToSlash(file), 2) + ":" + strconv.Itoa(line)
+}
+
+// externalOrigin returns `kind` along with the call site of the first caller
+// outside of this module (see caller), or an empty string if provenance
+// tracking is disabled
+func externalOrigin(kind string) string {
+	if !provenance.Load() {
+		return ""
+	}
+	f, ok := caller()
+	if !ok {
+		return kind
+	}
+	return kind + " " + lastElements(filepath.ToSlash(f.File), 2) + ":" + strconv.Itoa(f.Line)
+}
+
+// Origin returns where the attribute `a` was attached, and a boolean on
+// whether it was tracked (see TrackProvenance)
+func Origin(a attr.Attr) (string, bool) {
+	if t, ok := a.(tracedAttr); ok {
+		return t.origin, true
+	}
+	return "", false
+}
+
+type provenanceHandler struct {
+	h     Handler
+	key   string
+	bound []attr.Attr
+}
+
+// Provenance decorates the Handler `h` so that every Record it handles
+// carries the provenance of its attributes (see TrackProvenance), as a group
+// with key `key` (or `provenance`, if empty) mapping each attribute's key to
+// where it was attached:
+//
+//	{"message":"order placed","data":{"user":"alice","region":"eu-west-1",
+//	"provenance":{"user":"With api/orders.go:42","region":"Enrich cmd/main.go:31"}}}
+//
+// The decorator should wrap the output Handler directly, under any other
+// decorators adding attributes (like Enrich), for their attributes to be
+// reported. The attributes bound to it with its With method are marked as
+// attached at the call site of the first caller outside of this module; and
+// the ones without a tracked origin, like the ones passed to the log calls,
+// are reported as `untracked`. As a debug tool, the decorator is meant to be
+// set up temporarily, along with TrackProvenance
+func Provenance(h Handler, key string) Handler {
+	if h == nil {
+		return nil
+	}
+	if key == "" {
+		key = defaultProvenanceKey
+	}
+
+	return provenanceHandler{
+		h:   h,
+		key: key,
+	}
+}
+
+// appendOrigins appends the origins of the attributes `attrs` to `dst`
+func appendOrigins(dst []attr.Attr, attrs []attr.Attr) []attr.Attr {
+	for _, a := range attrs {
+		if a == nil {
+			continue
+		}
+		origin, ok := Origin(a)
+		if !ok {
+			origin = untracked
+		}
+		dst = append(dst, attr.String(a.Key(), origin))
+	}
+	return dst
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (p provenanceHandler) Enabled(level level.Level) bool {
+	return p.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (p provenanceHandler) Handle(r records.Record) error {
+	if !p.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	attrs := r.Attrs()
+	origins := make([]attr.Attr, 0, len(attrs)+len(p.bound))
+	origins = appendOrigins(appendOrigins(origins, attrs), p.bound)
+	if len(origins) == 0 {
+		return p.h.Handle(r)
+	}
+	return p.h.Handle(r.AddAttr(attr.New(p.key, origins)))
+}
+
+// Capabilities implements Capable, returning the capabilities of the decorated
+// Handler
+func (p provenanceHandler) Capabilities() Capability {
+	return decoratedCapabilities(p.h)
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (p provenanceHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, p.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (p provenanceHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, p.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (p provenanceHandler) With(attrs ...attr.Attr) Handler {
+	attrs = Traced(externalOrigin("Handler.With"), attrs...)
+	return provenanceHandler{
+		h:     p.h.With(attrs...),
+		key:   p.key,
+		bound: attrs,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (p provenanceHandler) WithSource(addSource bool) Handler {
+	return provenanceHandler{
+		h:     p.h.WithSource(addSource),
+		key:   p.key,
+		bound: p.bound,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (p provenanceHandler) WithLevel(level level.Leveler) Handler {
+	return provenanceHandler{
+		h:     p.h.WithLevel(level),
+		key:   p.key,
+		bound: p.bound,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (p provenanceHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return provenanceHandler{
+		h:     p.h.WithReplaceFn(fn),
+		key:   p.key,
+		bound: p.bound,
+	}
+}
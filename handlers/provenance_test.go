@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestProvenance(t *testing.T) {
+	TrackProvenance(true)
+	defer TrackProvenance(false)
+
+	origins := func(t *testing.T, r records.Record) map[string]string {
+		var group []attr.Attr
+		for _, a := range r.Attrs() {
+			if a.Key() == defaultProvenanceKey {
+				group, _ = a.Value().([]attr.Attr)
+			}
+		}
+		if group == nil {
+			t.Errorf("output mismatch error: wanted a %s group ; got %v", defaultProvenanceKey, r.Attrs())
+			return nil
+		}
+		m := make(map[string]string, len(group))
+		for _, a := range group {
+			m[a.Key()] = a.Value().(string)
+		}
+		return m
+	}
+
+	t.Run("Origins", func(t *testing.T) {
+		th := newTestHandler()
+		h := Enrich(Provenance(th, ""), Static(EnricherFunc(func(context.Context) []attr.Attr {
+			return []attr.Attr{attr.String("region", "eu-west-1")}
+		}))).With(attr.String("service", "api"))
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "event",
+			append(Traced("session cache", attr.String("user", "alice")), attr.Int("status", 200))...,
+		))
+
+		rs := th.Records()
+		if len(rs) != 1 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 1, len(rs))
+			return
+		}
+
+		got := origins(t, rs[0])
+		if got["user"] != "session cache" || got["status"] != untracked {
+			t.Errorf("output mismatch error: wanted the record's origins ; got %v", got)
+		}
+		if !strings.HasPrefix(got["service"], "Handler.With handlers/provenance_test.go:") {
+			t.Errorf("output mismatch error: wanted the With call site ; got %v", got["service"])
+		}
+		if !strings.HasPrefix(got["region"], "Enrich handlers/provenance_test.go:") {
+			t.Errorf("output mismatch error: wanted the Enrich call site ; got %v", got["region"])
+		}
+	})
+	t.Run("KeepFirstOrigin", func(t *testing.T) {
+		attrs := Traced("second", Traced("first", attr.String("k", "v"))...)
+		origin, ok := Origin(attrs[0].WithKey("key"))
+		if !ok || origin != "first" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "first", origin)
+		}
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		TrackProvenance(false)
+		defer TrackProvenance(true)
+
+		if _, ok := Origin(Traced("origin", attr.String("k", "v"))[0]); ok {
+			t.Errorf("expected the attribute not to be tracked")
+		}
+	})
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/stats"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	defaultQuotaInterval = time.Minute
+
+	quotaMessage = "log quota exceeded"
+)
+
+// QuotaConfig configures the limits enforced by a Quota
+type QuotaConfig struct {
+	// Key is the key of the attribute whose value the quotas are kept by, like
+	// a tenant's ID or a logger's name, defaulting to DefaultRouteKey.
+	// Attributes bound to the Quota (with With) are also looked up. Records
+	// without it share a quota
+	Key string
+	// Records is the maximum number of records handled per Interval for each
+	// key, if greater than zero
+	Records int
+	// Bytes is the maximum size of the records handled per Interval for each
+	// key, if greater than zero. The size of a record is estimated as the
+	// length of its message and of its attributes' keys and values, as text
+	Bytes int
+	// Interval is the period the quotas apply to, defaulting to one minute.
+	// Quotas are reset at the start of every Interval
+	Interval time.Duration
+}
+
+// Quota is a Handler decorator that enforces a maximum number of records (or
+// bytes) handled per interval for each value of an attribute, like a tenant's
+// ID, protecting shared pipelines from a single noisy tenant.
+//
+// Records over a quota are dropped. When a key goes over its quota, a single
+// Warn record is handled in its place (per interval), with the message
+// "log quota exceeded", the key's attribute and the limits as attributes.
+//
+// Handlers derived from a Quota (with its With* methods) share its quotas and
+// counters
+type Quota struct {
+	quotaHandler
+}
+
+type quotaHandler struct {
+	h     Handler
+	conf  QuotaConfig
+	bound []attr.Attr
+	q     *quotas
+}
+
+type quotaUsage struct {
+	records int
+	bytes   int
+	over    bool
+}
+
+type quotas struct {
+	mu      sync.Mutex
+	start   time.Time
+	usage   map[string]*quotaUsage
+	dropped atomic.Uint64
+	now     func() time.Time
+}
+
+// NewQuota creates a Quota for the Handler `h`, as configured by the
+// QuotaConfig `conf`
+func NewQuota(h Handler, conf QuotaConfig) *Quota {
+	if h == nil {
+		h = Unimpl()
+	}
+	if conf.Key == "" {
+		conf.Key = DefaultRouteKey
+	}
+	if conf.Interval <= 0 {
+		conf.Interval = defaultQuotaInterval
+	}
+
+	return &Quota{
+		quotaHandler: quotaHandler{
+			h:    h,
+			conf: conf,
+			q: &quotas{
+				usage: make(map[string]*quotaUsage),
+				now:   time.Now,
+			},
+		},
+	}
+}
+
+// Dropped returns the number of records discarded so far for being over a
+// quota
+func (q *Quota) Dropped() uint64 {
+	return q.q.dropped.Load()
+}
+
+// take accounts for a record of size `size` in the quota of the key `key`,
+// returning whether it is within the quota, and whether it is the first one
+// over it in the current interval
+func (q *quotas) take(key string, size int, conf QuotaConfig) (ok, first bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// resetting all quotas at once also releases the keys no longer in use
+	if now := q.now(); now.Sub(q.start) >= conf.Interval {
+		q.start = now
+		clear(q.usage)
+	}
+
+	u, found := q.usage[key]
+	if !found {
+		u = &quotaUsage{}
+		q.usage[key] = u
+	}
+
+	if (conf.Records > 0 && u.records+1 > conf.Records) || (conf.Bytes > 0 && u.bytes+size > conf.Bytes) {
+		first = !u.over
+		u.over = true
+		return false, first
+	}
+
+	u.records++
+	u.bytes += size
+	return true, false
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (q quotaHandler) Enabled(level level.Level) bool {
+	return q.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (q quotaHandler) Handle(r records.Record) error {
+	if !q.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	var (
+		key string
+		a   = lookup(q.conf.Key, r.Attrs(), q.bound)
+	)
+	if a != nil {
+		key = fmt.Sprint(a.Value())
+	}
+
+	size := 0
+	if q.conf.Bytes > 0 {
+		size = recordSize(r)
+	}
+
+	ok, first := q.q.take(key, size, q.conf)
+	if ok {
+		return q.h.Handle(r)
+	}
+
+	q.q.dropped.Add(1)
+	stats.Dropped.Add(1)
+	if !first {
+		return nil
+	}
+
+	attrs := []attr.Attr{attr.New("quota_interval", q.conf.Interval)}
+	if a != nil && lookup(q.conf.Key, nil, q.bound) == nil {
+		attrs = append([]attr.Attr{attr.String(q.conf.Key, key)}, attrs...)
+	}
+	if q.conf.Records > 0 {
+		attrs = append(attrs, attr.Int("quota_records", q.conf.Records))
+	}
+	if q.conf.Bytes > 0 {
+		attrs = append(attrs, attr.Int("quota_bytes", q.conf.Bytes))
+	}
+	return q.h.Handle(records.New(r.Time(), level.Warn, quotaMessage, attrs...))
+}
+
+// recordSize estimates the encoded size of the Record `r`
+func recordSize(r records.Record) int {
+	return len(r.Message()) + attrsSize(r.Attrs())
+}
+
+func attrsSize(attrs []attr.Attr) int {
+	var n int
+	for _, a := range attrs {
+		if a == nil {
+			continue
+		}
+		n += len(a.Key())
+		switch v := a.Value().(type) {
+		case string:
+			n += len(v)
+		case []attr.Attr:
+			n += attrsSize(v)
+		default:
+			n += len(fmt.Sprint(v))
+		}
+	}
+	return n
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (q quotaHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, q.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (q quotaHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, q.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (q quotaHandler) With(attrs ...attr.Attr) Handler {
+	bound := make([]attr.Attr, 0, len(q.bound)+len(attrs))
+	return quotaHandler{
+		h:     q.h.With(attrs...),
+		conf:  q.conf,
+		bound: append(append(bound, q.bound...), attrs...),
+		q:     q.q,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (q quotaHandler) WithSource(addSource bool) Handler {
+	return quotaHandler{
+		h:     q.h.WithSource(addSource),
+		conf:  q.conf,
+		bound: q.bound,
+		q:     q.q,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (q quotaHandler) WithLevel(level level.Leveler) Handler {
+	return quotaHandler{
+		h:     q.h.WithLevel(level),
+		conf:  q.conf,
+		bound: q.bound,
+		q:     q.q,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (q quotaHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return quotaHandler{
+		h:     q.h.WithReplaceFn(fn),
+		conf:  q.conf,
+		bound: q.bound,
+		q:     q.q,
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestQuota(t *testing.T) {
+	handle := func(t *testing.T, h Handler, times int, attrs ...attr.Attr) {
+		for i := 0; i < times; i++ {
+			if err := h.Handle(records.New(time.Now(), level.Info, "test message", attrs...)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}
+	count := func(rs []records.Record, msg string) int {
+		var n int
+		for _, r := range rs {
+			if r.Message() == msg {
+				n++
+			}
+		}
+		return n
+	}
+
+	t.Run("Records", func(t *testing.T) {
+		th := newTestHandler()
+		q := NewQuota(th, QuotaConfig{Records: 2})
+
+		handle(t, q, 5, attr.String("tenant_id", "noisy"))
+		handle(t, q.With(attr.String("tenant_id", "quiet")), 2)
+
+		rs := th.Records()
+		if n := count(rs, "test message"); n != 4 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 4, n)
+		}
+		if n := count(rs, quotaMessage); n != 1 {
+			t.Errorf("output mismatch error: wanted %v marker records ; got %v", 1, n)
+			return
+		}
+		for _, r := range rs {
+			if r.Message() != quotaMessage {
+				continue
+			}
+			if r.Level() != level.Warn || lookup("tenant_id", r.Attrs(), nil).Value() != "noisy" {
+				t.Errorf("output mismatch error: wanted a warning for the noisy tenant ; got %v", r.Attrs())
+			}
+		}
+		if q.Dropped() != 3 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 3, q.Dropped())
+		}
+	})
+
+	t.Run("Bytes", func(t *testing.T) {
+		th := newTestHandler()
+		q := NewQuota(th, QuotaConfig{Bytes: 40})
+
+		// "test message" and "tenant_id" "a" add up to 22 bytes
+		handle(t, q, 3, attr.String("tenant_id", "a"))
+
+		if n := count(th.Records(), "test message"); n != 1 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 1, n)
+		}
+	})
+
+	t.Run("Interval", func(t *testing.T) {
+		th := newTestHandler()
+		q := NewQuota(th, QuotaConfig{Records: 1, Interval: time.Minute})
+		now := time.Now()
+		q.q.now = func() time.Time { return now }
+
+		handle(t, q, 2)
+		now = now.Add(time.Minute)
+		handle(t, q, 2)
+
+		rs := th.Records()
+		if count(rs, "test message") != 2 || count(rs, quotaMessage) != 2 {
+			t.Errorf("output mismatch error: wanted a record and a marker per interval ; got %v records", len(rs))
+		}
+	})
+}
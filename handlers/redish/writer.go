@@ -0,0 +1,326 @@
+//go:build !logx_tiny
+
+// Package redish provides a handler that appends records to a Redis Stream,
+// for teams using Redis as a lightweight log or event bus.
+//
+// Each record is added with XADD as an entry with a single field holding the
+// encoded record (as JSON, by default), and the stream is trimmed to an
+// approximate maximum length on every addition, if set:
+//
+//	h, err := redish.New(redish.Config{
+//		Addr:   "localhost:6379",
+//		Stream: "logs:api",
+//		MaxLen: 100_000,
+//	})
+//
+// The package speaks the Redis protocol (RESP) directly, without depending on
+// a client library
+package redish
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+)
+
+const (
+	defaultAddr    = "localhost:6379"
+	defaultField   = "record"
+	defaultTimeout = 5 * time.Second
+)
+
+var (
+	// ErrNoStream is raised when creating a writer without a stream key
+	ErrNoStream error = errors.New("no stream provided")
+	// ErrRedis is raised when the Redis server replies with an error
+	ErrRedis error = errors.New("redis error")
+)
+
+// Config describes the Redis server and the stream that records are added to
+type Config struct {
+	// Addr is the address of the Redis server, `localhost:6379` by default
+	Addr string
+	// Username and Password authenticate the connection with AUTH, if set
+	Username string
+	Password string
+	// DB is the index of the database selected with SELECT, if not zero
+	DB int
+	// TLS configures the connection with TLS, if set
+	TLS *handlers.TLSConfig
+	// Timeout bounds connecting to the server and each command, five seconds
+	// by default
+	Timeout time.Duration
+
+	// Stream is the key of the stream
+	Stream string
+	// Field is the name of the entries' field holding the encoded record,
+	// `record` by default
+	Field string
+	// MaxLen trims the stream to about this many entries (with `MAXLEN ~`)
+	// on every addition, if greater than zero
+	MaxLen int64
+	// ExactTrim trims the stream to exactly MaxLen entries, which is slower
+	// than the approximate trimming
+	ExactTrim bool
+}
+
+type streamWriter struct {
+	mu   sync.Mutex
+	conf Config
+	conn net.Conn
+	r    *bufio.Reader
+	args [][]byte
+
+	closed bool
+}
+
+// New creates a JSON handler (see the jsonh package) that adds records to the
+// Redis Stream described by the Config `conf`. See Writer
+func New(conf Config) (handlers.Handler, error) {
+	w, err := Writer(conf)
+	if err != nil {
+		return nil, err
+	}
+	return jsonh.New(w), nil
+}
+
+// Writer creates an io.Writer that adds each write as an entry to the Redis
+// Stream described by the Config `conf`, for handlers in other formats:
+//
+//	w, err := redish.Writer(conf)
+//	// (...)
+//	h := texth.New(w)
+//
+// Each write is an entry, so the writer must not be wrapped with a
+// handlers.Batch. The trailing line break of each write is removed.
+//
+// The connection is opened on the first write, and reopened on the following
+// one if a command fails. The writer implements handlers.ContextWriter,
+// handlers.Pinger and handlers.Shutdowner
+func Writer(conf Config) (io.Writer, error) {
+	if conf.Stream == "" {
+		return nil, ErrNoStream
+	}
+	if conf.Addr == "" {
+		conf.Addr = defaultAddr
+	}
+	if conf.Field == "" {
+		conf.Field = defaultField
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = defaultTimeout
+	}
+
+	w := &streamWriter{conf: conf}
+	w.args = append(w.args, []byte("XADD"), []byte(conf.Stream))
+	if conf.MaxLen > 0 {
+		w.args = append(w.args, []byte("MAXLEN"))
+		if !conf.ExactTrim {
+			w.args = append(w.args, []byte("~"))
+		}
+		w.args = append(w.args, strconv.AppendInt(nil, conf.MaxLen, 10))
+	}
+	w.args = append(w.args, []byte("*"), []byte(conf.Field))
+	return w, nil
+}
+
+// Write implements io.Writer, adding `p` as an entry to the stream
+func (w *streamWriter) Write(p []byte) (int, error) {
+	return w.WriteContext(context.Background(), p)
+}
+
+// WriteContext implements handlers.ContextWriter, adding `p` as an entry to
+// the stream within the deadline of the context `ctx`
+func (w *streamWriter) WriteContext(ctx context.Context, p []byte) (int, error) {
+	value := p
+	if n := len(value); n > 0 && value[n-1] == '\n' {
+		value = value[:n-1]
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	args := append(w.args[:len(w.args):len(w.args)], value)
+	if _, err := w.do(ctx, args...); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Capabilities implements handlers.Capable, returning CapRemote
+func (w *streamWriter) Capabilities() handlers.Capability {
+	return handlers.CapRemote
+}
+
+// Ping implements handlers.Pinger, sending a PING command to the server
+func (w *streamWriter) Ping(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := w.do(ctx, []byte("PING"))
+	return err
+}
+
+// Shutdown implements handlers.Shutdowner, closing the connection
+func (w *streamWriter) Shutdown(context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// do sends the command with arguments `args` and reads its reply, connecting
+// to the server first if needed. The connection is closed if the command
+// fails, other than with an error reply
+func (w *streamWriter) do(ctx context.Context, args ...[]byte) (any, error) {
+	if w.closed {
+		return nil, os.ErrClosed
+	}
+
+	if w.conn == nil {
+		if err := w.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := w.roundTrip(ctx, args...)
+	if err != nil && !errors.Is(err, ErrRedis) {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	return reply, err
+}
+
+func (w *streamWriter) connect(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, w.conf.Timeout)
+	defer cancel()
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if w.conf.TLS != nil {
+		conn, err = handlers.DialTLS(ctx, "tcp", w.conf.Addr, *w.conf.TLS)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", w.conf.Addr)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.conn = conn
+	w.r = bufio.NewReader(conn)
+
+	var setup [][][]byte
+	switch {
+	case w.conf.Username != "":
+		setup = append(setup, [][]byte{[]byte("AUTH"), []byte(w.conf.Username), []byte(w.conf.Password)})
+	case w.conf.Password != "":
+		setup = append(setup, [][]byte{[]byte("AUTH"), []byte(w.conf.Password)})
+	}
+	if w.conf.DB != 0 {
+		setup = append(setup, [][]byte{[]byte("SELECT"), strconv.AppendInt(nil, int64(w.conf.DB), 10)})
+	}
+
+	for _, cmd := range setup {
+		if _, err := w.roundTrip(ctx, cmd...); err != nil {
+			_ = conn.Close()
+			w.conn = nil
+			return fmt.Errorf("%s: %w", cmd[0], err)
+		}
+	}
+	return nil
+}
+
+func (w *streamWriter) roundTrip(ctx context.Context, args ...[]byte) (any, error) {
+	deadline, ok := ctx.Deadline()
+	if limit := time.Now().Add(w.conf.Timeout); !ok || limit.Before(deadline) {
+		deadline = limit
+	}
+	if err := w.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if _, err := w.conn.Write(appendCommand(nil, args...)); err != nil {
+		return nil, err
+	}
+	return readReply(w.r)
+}
+
+// appendCommand appends the command with arguments `args` to `b`, as a RESP
+// array of bulk strings
+func appendCommand(b []byte, args ...[]byte) []byte {
+	b = append(b, '*')
+	b = strconv.AppendInt(b, int64(len(args)), 10)
+	b = append(b, '\r', '\n')
+	for _, arg := range args {
+		b = append(b, '$')
+		b = strconv.AppendInt(b, int64(len(arg)), 10)
+		b = append(b, '\r', '\n')
+		b = append(b, arg...)
+		b = append(b, '\r', '\n')
+	}
+	return b
+}
+
+// readReply reads a RESP value from `r`, as a string, an int64, a []any or
+// nil. Error replies are returned as errors wrapping ErrRedis
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	kind, line := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return line, nil
+	case '-':
+		return nil, fmt.Errorf("%w: %s", ErrRedis, line)
+	case ':':
+		return strconv.ParseInt(line, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		values := make([]any, n)
+		for i := range values {
+			if values[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+}
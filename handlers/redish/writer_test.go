@@ -0,0 +1,177 @@
+//go:build !logx_tiny
+
+package redish
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// server is a fake Redis server recording the commands it receives
+type server struct {
+	ln net.Listener
+
+	mu    sync.Mutex
+	cmds  []string
+	conns []net.Conn
+}
+
+func newServer(t *testing.T) *server {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := &server{ln: ln}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns = append(s.conns, conn)
+			s.mu.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *server) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for {
+		cmd, err := readReply(r)
+		if err != nil {
+			return
+		}
+
+		var args []string
+		for _, arg := range cmd.([]any) {
+			args = append(args, arg.(string))
+		}
+		s.mu.Lock()
+		s.cmds = append(s.cmds, strings.Join(args, " "))
+		s.mu.Unlock()
+
+		switch {
+		case args[0] == "AUTH" && args[len(args)-1] != "secret":
+			_, _ = conn.Write([]byte("-WRONGPASS invalid password\r\n"))
+		case args[0] == "XADD":
+			_, _ = conn.Write([]byte("$15\r\n1700000000000-0\r\n"))
+		case args[0] == "PING":
+			_, _ = conn.Write([]byte("+PONG\r\n"))
+		default:
+			_, _ = conn.Write([]byte("+OK\r\n"))
+		}
+	}
+}
+
+func (s *server) commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.cmds...)
+}
+
+// drop closes the server's side of the open connections
+func (s *server) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.conns = nil
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("XADD", func(t *testing.T) {
+		srv := newServer(t)
+		h, err := New(Config{
+			Addr:     srv.ln.Addr().String(),
+			Password: "secret",
+			DB:       2,
+			Stream:   "logs:api",
+			MaxLen:   1000,
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		defer handlers.Shutdown(context.Background(), h)
+
+		if err := h.Handle(records.New(time.Unix(0, 0).Add(time.Second), level.Info, "ready")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		cmds := srv.commands()
+		if len(cmds) != 3 || cmds[0] != "AUTH secret" || cmds[1] != "SELECT 2" {
+			t.Errorf("output mismatch error: got %q", cmds)
+			return
+		}
+		if !strings.HasPrefix(cmds[2], "XADD logs:api MAXLEN ~ 1000 * record {") ||
+			!strings.HasSuffix(cmds[2], `"message":"ready","level":"info"}`) {
+			t.Errorf("output mismatch error: got %q", cmds[2])
+		}
+	})
+	t.Run("Reconnect", func(t *testing.T) {
+		srv := newServer(t)
+		w, _ := Writer(Config{Addr: srv.ln.Addr().String(), Stream: "logs", MaxLen: 10, ExactTrim: true})
+		defer handlers.Shutdown(context.Background(), w)
+
+		_, _ = w.Write([]byte("first\n"))
+		srv.drop()
+
+		// the first write after the connection is lost may fail
+		if _, err := w.Write([]byte("second\n")); err != nil {
+			_, err = w.Write([]byte("second\n"))
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+		}
+
+		cmds := srv.commands()
+		if last := cmds[len(cmds)-1]; last != "XADD logs MAXLEN 10 * record second" {
+			t.Errorf("output mismatch error: got %q", last)
+		}
+	})
+	t.Run("AuthError", func(t *testing.T) {
+		srv := newServer(t)
+		w, _ := Writer(Config{Addr: srv.ln.Addr().String(), Password: "wrong", Stream: "logs"})
+
+		if _, err := w.Write([]byte("entry")); !errors.Is(err, ErrRedis) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrRedis, err)
+		}
+	})
+	t.Run("Ping", func(t *testing.T) {
+		srv := newServer(t)
+		w, _ := Writer(Config{Addr: srv.ln.Addr().String(), Stream: "logs"})
+
+		if err := handlers.Ping(context.Background(), w); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		_ = handlers.Shutdown(context.Background(), w)
+		if err := handlers.Ping(context.Background(), w); err == nil {
+			t.Errorf("expected an error after shutdown")
+		}
+	})
+	t.Run("NoStream", func(t *testing.T) {
+		if _, err := Writer(Config{}); !errors.Is(err, ErrNoStream) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrNoStream, err)
+		}
+	})
+}
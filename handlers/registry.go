@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/logx/level"
+)
+
+var (
+	// ErrUnknownHandler is raised when building a Handler with a name that
+	// was not registered
+	ErrUnknownHandler error = errors.New("unknown handler")
+	// ErrInvalidConfig is raised when a Handler's configuration holds a
+	// missing or invalid value
+	ErrInvalidConfig error = errors.New("invalid handler config")
+)
+
+// Factory creates a Handler from its configuration, as decoded from a YAML or
+// JSON document
+type Factory func(config map[string]any) (Handler, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{}
+)
+
+// Register makes the Factory `factory` available under the name `name`, so
+// that configuration loaders (and Build) can create Handlers by name. It is
+// meant to be called from the init function of the package providing the
+// Handler, like jsonh and texth do for `json` and `text`.
+//
+// Besides those, this package registers:
+//   - `file`: writes to the file in `path`, rotated with `max_size` and
+//     `max_backups` if set, with the Handler in `format` (`json` by default).
+//     With `crash_safe`, it is written as an NDJSONFile synced every
+//     `sync_every` records or `sync_interval`, which cannot be rotated
+//   - `http`: sends records to the endpoint in `url`, with the `header`,
+//     `content_type` and `timeout` settings (see HTTPWriter), with the Handler
+//     in `format` (`json` by default)
+//   - `syslog`: writes to the syslog daemon at `address` over `network` (or
+//     the local one, if unset) with the `tag`, with the Handler in `format`
+//     (`text` by default). Not available on Windows
+//   - `multi`: sends records to each of the Handlers in `handlers`, a list of
+//     configurations with the registered name in their `type`
+//
+// Like sql.Register, it panics if the Factory is nil or if the name is already
+// registered
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic("handlers: Register factory is nil")
+	}
+	if _, dup := factories[name]; dup {
+		panic("handlers: Register called twice for handler " + name)
+	}
+	factories[name] = factory
+}
+
+// Registered returns the sorted names of the registered Factories
+func Registered() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Build creates a Handler with the Factory registered under the name `name`,
+// from the configuration `config`. The `level` and `source` keys are applied to
+// any Handler, as its verbosity filter and source references setting; as are
+// the `include_loggers` and `exclude_loggers` lists of logger name patterns
+// (see IncludeLoggers and ExcludeLoggers)
+func Build(name string, config map[string]any) (Handler, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[name]
+	factoriesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownHandler, name)
+	}
+
+	h, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if h == nil {
+		return nil, fmt.Errorf("%s: %w: no handler was created", name, ErrInvalidConfig)
+	}
+
+	lv, err := ConfigString(config, "level")
+	if err != nil {
+		return nil, err
+	}
+	if lv != "" {
+		parsed, err := level.Parse(lv)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+		h = h.WithLevel(parsed)
+	}
+
+	if v, ok := config["source"]; ok {
+		source, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q must be a boolean", ErrInvalidConfig, "source")
+		}
+		h = h.WithSource(source)
+	}
+
+	include, err := ConfigStrings(config, "include_loggers")
+	if err != nil {
+		return nil, err
+	}
+	if len(include) > 0 {
+		h = IncludeLoggers(h, include...)
+	}
+
+	exclude, err := ConfigStrings(config, "exclude_loggers")
+	if err != nil {
+		return nil, err
+	}
+	return ExcludeLoggers(h, exclude...), nil
+}
+
+// ConfigWriter returns the io.Writer configured in `config` for the Handlers
+// that write to one: the io.Writer in the `writer` key, as set by the `file`,
+// `http` and `syslog` Handlers, or the standard output or error as set in the
+// `output` key (`stdout` or `stderr`, the default)
+func ConfigWriter(config map[string]any) (io.Writer, error) {
+	if v, ok := config["writer"]; ok {
+		w, ok := v.(io.Writer)
+		if !ok || w == nil {
+			return nil, fmt.Errorf("%w: %q must be an io.Writer", ErrInvalidConfig, "writer")
+		}
+		return w, nil
+	}
+
+	output, err := ConfigString(config, "output")
+	if err != nil {
+		return nil, err
+	}
+	switch output {
+	case "", "stderr":
+		return os.Stderr, nil
+	case "stdout":
+		return os.Stdout, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown output %q", ErrInvalidConfig, output)
+	}
+}
+
+// ConfigString returns the string in the key `key` of `config`, or an empty
+// string if unset
+func ConfigString(config map[string]any, key string) (string, error) {
+	v, ok := config[key]
+	if !ok || v == nil {
+		return "", nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %q must be a string", ErrInvalidConfig, key)
+	}
+	return s, nil
+}
+
+// ConfigStrings returns the list of strings in the key `key` of `config`, or
+// nil if unset
+func ConfigStrings(config map[string]any, key string) ([]string, error) {
+	switch v := config[key].(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return v, nil
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %q must be a list of strings", ErrInvalidConfig, key)
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("%w: %q must be a list of strings", ErrInvalidConfig, key)
+	}
+}
+
+// ConfigInt returns the integer in the key `key` of `config`, or zero if
+// unset. Whole floating-point numbers, as decoded from JSON, are accepted
+func ConfigInt(config map[string]any, key string) (int, error) {
+	switch v := config[key].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q must be an integer", ErrInvalidConfig, key)
+}
+
+// configDuration returns the duration in the key `key` of `config`, as a
+// string like `5s` or a number of seconds
+func configDuration(config map[string]any, key string) (time.Duration, error) {
+	if s, ok := config[key].(string); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q: %w", ErrInvalidConfig, key, err)
+		}
+		return d, nil
+	}
+
+	secs, err := ConfigInt(config, key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q must be a duration", ErrInvalidConfig, key)
+	}
+	return time.Duration(secs) * time.Second, nil
+}
+
+// withWriter builds the Handler registered under the name in the `format` key
+// of `config` (or `def`, if unset), writing to the io.Writer `w`. The writer is
+// closed if the Handler cannot be built
+func withWriter(config map[string]any, def string, w io.Writer) (Handler, error) {
+	format, err := ConfigString(config, "format")
+	if err != nil {
+		_ = Shutdown(context.Background(), w)
+		return nil, err
+	}
+	if format == "" {
+		format = def
+	}
+
+	inner := make(map[string]any, len(config)+1)
+	for k, v := range config {
+		switch k {
+		case "format", "level", "source", "include_loggers", "exclude_loggers":
+			// applied to the outer Handler
+		default:
+			inner[k] = v
+		}
+	}
+	inner["writer"] = w
+
+	h, err := Build(format, inner)
+	if err != nil {
+		_ = Shutdown(context.Background(), w)
+		return nil, err
+	}
+	return h, nil
+}
+
+// closingWriter is an io.WriteCloser that is closed on Shutdown
+type closingWriter struct {
+	io.WriteCloser
+}
+
+// Shutdown implements Shutdowner, closing the writer
+func (w closingWriter) Shutdown(context.Context) error {
+	return w.Close()
+}
+
+func newFileHandler(config map[string]any) (Handler, error) {
+	path, err := ConfigString(config, "path")
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, fmt.Errorf("%w: %q is required", ErrInvalidConfig, "path")
+	}
+
+	maxSize, err := ConfigInt(config, "max_size")
+	if err != nil {
+		return nil, err
+	}
+	maxBackups, err := ConfigInt(config, "max_backups")
+	if err != nil {
+		return nil, err
+	}
+
+	crashSafe, ok := config["crash_safe"].(bool)
+	if _, set := config["crash_safe"]; set && !ok {
+		return nil, fmt.Errorf("%w: %q must be a boolean", ErrInvalidConfig, "crash_safe")
+	}
+	if crashSafe {
+		return newNDJSONFileHandler(config, path, maxSize)
+	}
+
+	var w io.Writer
+	if maxSize > 0 {
+		w, err = Rotate(path, int64(maxSize), maxBackups)
+	} else {
+		var f *os.File
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		w = closingWriter{f}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return withWriter(config, "json", w)
+}
+
+func newNDJSONFileHandler(config map[string]any, path string, maxSize int) (Handler, error) {
+	if maxSize > 0 {
+		return nil, fmt.Errorf("%w: %q cannot be rotated", ErrInvalidConfig, "crash_safe")
+	}
+	if format, err := ConfigString(config, "format"); err != nil || (format != "" && format != "json") {
+		return nil, fmt.Errorf("%w: %q requires the json format", ErrInvalidConfig, "crash_safe")
+	}
+
+	var (
+		policy SyncPolicy
+		err    error
+	)
+	if policy.Records, err = ConfigInt(config, "sync_every"); err != nil {
+		return nil, err
+	}
+	if policy.Interval, err = configDuration(config, "sync_interval"); err != nil {
+		return nil, err
+	}
+
+	w, err := NDJSONFile(path, policy)
+	if err != nil {
+		return nil, err
+	}
+	return withWriter(config, "json", w)
+}
+
+func newMultiHandler(config map[string]any) (Handler, error) {
+	list, ok := config["handlers"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q must be a list", ErrInvalidConfig, "handlers")
+	}
+
+	hs := make([]Handler, 0, len(list))
+	for idx, item := range list {
+		sub, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: handler #%d must be a map", ErrInvalidConfig, idx)
+		}
+		name, err := ConfigString(sub, "type")
+		if err != nil {
+			return nil, err
+		}
+
+		h, err := Build(name, sub)
+		if err != nil {
+			_ = Shutdown(context.Background(), Multi(hs...))
+			return nil, fmt.Errorf("handler #%d: %w", idx, err)
+		}
+		hs = append(hs, h)
+	}
+
+	return Multi(hs...), nil
+}
+
+func init() {
+	Register("file", newFileHandler)
+	Register("multi", newMultiHandler)
+}
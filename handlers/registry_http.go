@@ -0,0 +1,49 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func newHTTPHandler(config map[string]any) (Handler, error) {
+	endpoint, err := ConfigString(config, "url")
+	if err != nil {
+		return nil, err
+	}
+
+	conf := HTTPConfig{}
+	if conf.ContentType, err = ConfigString(config, "content_type"); err != nil {
+		return nil, err
+	}
+	if conf.Timeout, err = configDuration(config, "timeout"); err != nil {
+		return nil, err
+	}
+	if v, ok := config["header"]; ok {
+		header, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q must be a map", ErrInvalidConfig, "header")
+		}
+
+		conf.Header = make(http.Header, len(header))
+		for key := range header {
+			value, err := ConfigString(header, key)
+			if err != nil {
+				return nil, err
+			}
+			conf.Header.Set(key, value)
+		}
+	}
+
+	w, err := HTTPWriter(endpoint, conf)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	return withWriter(config, "json", w)
+}
+
+func init() {
+	Register("http", newHTTPHandler)
+}
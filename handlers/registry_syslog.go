@@ -0,0 +1,31 @@
+//go:build !windows && !plan9 && !js && !logx_tiny
+
+package handlers
+
+import "log/syslog"
+
+func newSyslogHandler(config map[string]any) (Handler, error) {
+	network, err := ConfigString(config, "network")
+	if err != nil {
+		return nil, err
+	}
+	address, err := ConfigString(config, "address")
+	if err != nil {
+		return nil, err
+	}
+	tag, err := ConfigString(config, "tag")
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return withWriter(config, "text", closingWriter{w})
+}
+
+func init() {
+	Register("syslog", newSyslogHandler)
+}
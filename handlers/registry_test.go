@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func init() {
+	Register("test_console", func(config map[string]any) (Handler, error) {
+		w, err := ConfigWriter(config)
+		if err != nil {
+			return nil, err
+		}
+		return Console(w, MonochromeTheme), nil
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	r := records.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), level.Info, "event")
+
+	t.Run("Registered", func(t *testing.T) {
+		names := strings.Join(Registered(), ",")
+		for _, name := range []string{"file", "multi", "test_console"} {
+			if !strings.Contains(names, name) {
+				t.Errorf("output mismatch error: wanted %q to be registered ; got %v", name, names)
+			}
+		}
+	})
+	t.Run("Duplicate", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected Register to panic for a registered name")
+			}
+		}()
+		Register("file", newFileHandler)
+	})
+	t.Run("Unknown", func(t *testing.T) {
+		if _, err := Build("xml", nil); !errors.Is(err, ErrUnknownHandler) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrUnknownHandler, err)
+		}
+	})
+	t.Run("File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		h, err := Build("file", map[string]any{
+			"path":   path,
+			"format": "test_console",
+			"level":  "warn",
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		_ = h.Handle(r)
+		_ = h.Handle(records.New(r.Time(), level.Error, "failed"))
+		if err := Shutdown(context.Background(), h); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		data, _ := os.ReadFile(path)
+		if strings.Contains(string(data), "event") || !strings.Contains(string(data), "failed") {
+			t.Errorf("output mismatch error: wanted only the error record ; got %q", data)
+		}
+	})
+	t.Run("Multi", func(t *testing.T) {
+		dir := t.TempDir()
+		h, err := Build("multi", map[string]any{
+			"handlers": []any{
+				map[string]any{"type": "file", "path": filepath.Join(dir, "a.log"), "format": "test_console"},
+				map[string]any{"type": "file", "path": filepath.Join(dir, "b.log"), "format": "test_console"},
+			},
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		_ = h.Handle(r)
+		_ = Shutdown(context.Background(), h)
+
+		for _, name := range []string{"a.log", "b.log"} {
+			if data, _ := os.ReadFile(filepath.Join(dir, name)); !strings.Contains(string(data), "event") {
+				t.Errorf("output mismatch error: wanted the record in %s ; got %q", name, data)
+			}
+		}
+	})
+	t.Run("InvalidConfig", func(t *testing.T) {
+		for _, config := range []map[string]any{
+			{},
+			{"path": 1},
+			{"path": "app.log", "max_size": "large"},
+			{"path": "app.log", "crash_safe": "yes"},
+			{"path": "app.log", "crash_safe": true, "max_size": 1024},
+			{"path": "app.log", "crash_safe": true, "format": "test_console"},
+		} {
+			if _, err := Build("file", config); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("unexpected error: wanted %v ; got %v", ErrInvalidConfig, err)
+			}
+		}
+		if _, err := Build("multi", map[string]any{"handlers": []any{map[string]any{"type": "xml"}}}); !errors.Is(err, ErrUnknownHandler) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrUnknownHandler, err)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// RemapRule describes a level change for the records matching it, like
+// downgrading a noisy dependency's errors to warnings, or upgrading a specific
+// event to an error. A record matches a rule if it matches all of its set
+// fields
+type RemapRule struct {
+	// From matches the records with this level; if nil, all levels match
+	From level.Level
+	// Message matches the records whose message contains it; if empty, all
+	// records match
+	Message string
+	// Key matches the records with an attribute with this key (including the
+	// ones bound to the Handler, with With); if empty, all records match
+	Key string
+	// Value, if set, matches the records whose attribute with key Key has this
+	// value
+	Value any
+	// Filter, if set, matches the records it returns true for (see HasAttr)
+	Filter func(r records.Record) bool
+	// To is the level set on the matching records
+	To level.Level
+}
+
+func (m RemapRule) matches(r records.Record, bound []attr.Attr) bool {
+	if m.From != nil && (r.Level() == nil || r.Level().Int() != m.From.Int()) {
+		return false
+	}
+	if m.Message != "" && !strings.Contains(r.Message(), m.Message) {
+		return false
+	}
+	if m.Key != "" {
+		a := lookup(m.Key, r.Attrs(), bound)
+		if a == nil || (m.Value != nil && a.Value() != m.Value) {
+			return false
+		}
+	}
+	return m.Filter == nil || m.Filter(r)
+}
+
+type remapHandler struct {
+	h     Handler
+	rules []RemapRule
+	bound []attr.Attr
+}
+
+// Remap decorates the Handler `h` so that the records it handles have their
+// level changed as described by the RemapRules `rules`. Only the first rule
+// that a record matches is applied, and rules without a target level are
+// ignored. The Handler's verbosity filter applies to the remapped level.
+//
+// If no rules are provided, the Handler `h` is returned as-is
+func Remap(h Handler, rules ...RemapRule) Handler {
+	if h == nil {
+		return nil
+	}
+
+	valid := make([]RemapRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.To != nil {
+			valid = append(valid, rule)
+		}
+	}
+	if len(valid) == 0 {
+		return h
+	}
+
+	return remapHandler{
+		h:     h,
+		rules: valid,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+//
+// As records may be upgraded, it also accepts the levels that a rule could
+// remap to an enabled one
+func (m remapHandler) Enabled(level level.Level) bool {
+	if m.h.Enabled(level) {
+		return true
+	}
+	for _, rule := range m.rules {
+		if (rule.From == nil || (level != nil && level.Int() == rule.From.Int())) && m.h.Enabled(rule.To) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle will process the input Record, returning an error if raised
+func (m remapHandler) Handle(r records.Record) error {
+	for _, rule := range m.rules {
+		if rule.matches(r, m.bound) {
+			return m.h.Handle(records.WithPC(records.New(r.Time(), rule.To, r.Message(), r.Attrs()...), records.PC(r)))
+		}
+	}
+	return m.h.Handle(r)
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (m remapHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, m.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (m remapHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, m.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (m remapHandler) With(attrs ...attr.Attr) Handler {
+	bound := make([]attr.Attr, 0, len(m.bound)+len(attrs))
+	return remapHandler{
+		h:     m.h.With(attrs...),
+		rules: m.rules,
+		bound: append(append(bound, m.bound...), attrs...),
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (m remapHandler) WithSource(addSource bool) Handler {
+	return remapHandler{
+		h:     m.h.WithSource(addSource),
+		rules: m.rules,
+		bound: m.bound,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (m remapHandler) WithLevel(level level.Leveler) Handler {
+	return remapHandler{
+		h:     m.h.WithLevel(level),
+		rules: m.rules,
+		bound: m.bound,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (m remapHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return remapHandler{
+		h:     m.h.WithReplaceFn(fn),
+		rules: m.rules,
+		bound: m.bound,
+	}
+}
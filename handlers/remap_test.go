@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestRemap(t *testing.T) {
+	rules := []RemapRule{
+		{From: level.Error, Key: "component", Value: "kafka", To: level.Warn},
+		{Message: "payment failed", To: level.Error},
+	}
+
+	for _, test := range []struct {
+		name  string
+		bound []attr.Attr
+		r     records.Record
+		wants level.Level
+	}{
+		{
+			name:  "Downgrade",
+			r:     records.New(time.Now(), level.Error, "broker unreachable", attr.String("component", "kafka")),
+			wants: level.Warn,
+		},
+		{
+			name:  "DowngradeBound",
+			bound: []attr.Attr{attr.String("component", "kafka")},
+			r:     records.New(time.Now(), level.Error, "broker unreachable"),
+			wants: level.Warn,
+		},
+		{
+			name:  "OtherValue",
+			r:     records.New(time.Now(), level.Error, "broker unreachable", attr.String("component", "db")),
+			wants: level.Error,
+		},
+		{
+			name:  "Upgrade",
+			r:     records.New(time.Now(), level.Info, "payment failed: card declined"),
+			wants: level.Error,
+		},
+		{
+			name:  "NoMatch",
+			r:     records.New(time.Now(), level.Info, "payment accepted"),
+			wants: level.Info,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			th := newTestHandler()
+			h := Remap(th, rules...).With(test.bound...)
+
+			if err := h.Handle(test.r); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			rs := th.Records()
+			if len(rs) != 1 {
+				t.Errorf("output mismatch error: wanted %v records ; got %v", 1, len(rs))
+				return
+			}
+			if rs[0].Level() != test.wants || rs[0].Message() != test.r.Message() {
+				t.Errorf("output mismatch error: wanted %v ; got %v", test.wants, rs[0].Level())
+			}
+		})
+	}
+
+	t.Run("Enabled", func(t *testing.T) {
+		th := newTestHandler()
+		h := Remap(th.WithLevel(level.Error), RemapRule{From: level.Debug, Message: "audit", To: level.Error})
+
+		if !h.Enabled(level.Debug) || h.Enabled(level.Info) {
+			t.Errorf("output mismatch error: wanted only debug and error records to be enabled")
+		}
+
+		_ = h.Handle(records.New(time.Now(), level.Debug, "audit: user deleted"))
+		_ = h.Handle(records.New(time.Now(), level.Debug, "cache hit"))
+		if rs := th.Records(); len(rs) != 1 || rs[0].Level() != level.Error {
+			t.Errorf("output mismatch error: wanted the upgraded record only ; got %v", rs)
+		}
+	})
+
+	t.Run("NoRules", func(t *testing.T) {
+		th := newTestHandler()
+		if _, ok := Remap(th, RemapRule{Message: "no target"}).(testHandler); !ok {
+			t.Errorf("expected the Handler to be returned as-is")
+		}
+	})
+}
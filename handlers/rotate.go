@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ErrInvalidMaxSize is raised when creating a rotating file writer with a
+// maximum size that is not greater than zero
+var ErrInvalidMaxSize error = errors.New("max size must be greater than zero")
+
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	size       int64
+	f          *os.File
+}
+
+// Rotate creates an io.WriteCloser that appends to the file in `path`, rotating
+// it when a write would grow it beyond `maxSize` bytes.
+//
+// Rotated files are renamed with a numeric suffix (`path.1` being the most
+// recent), keeping at most `maxBackups` of them. If `maxBackups` is zero, the
+// file is truncated on rotation instead
+func Rotate(path string, maxSize int64, maxBackups int) (io.WriteCloser, error) {
+	if maxSize <= 0 {
+		return nil, ErrInvalidMaxSize
+	}
+
+	r := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write implements io.Writer, rotating the file beforehand if needed
+func (r *rotatingFile) Write(p []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err = r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err = r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Sync implements WriteSyncer, committing the current file's contents to disk
+func (r *rotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return os.ErrClosed
+	}
+	return r.f.Sync()
+}
+
+// Close implements io.Closer, closing the current file
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+// Ping implements Pinger, returning an error if the file is closed or can no
+// longer be accessed
+func (r *rotatingFile) Ping(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return os.ErrClosed
+	}
+	_, err := r.f.Stat()
+	return err
+}
+
+// Shutdown implements Shutdowner, closing the current file
+func (r *rotatingFile) Shutdown(context.Context) error {
+	return r.Close()
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	r.f = f
+	r.size = info.Size()
+	return nil
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+
+	if r.maxBackups <= 0 {
+		if err := os.Truncate(r.path, 0); err != nil {
+			return err
+		}
+		return r.open()
+	}
+
+	_ = os.Remove(backupName(r.path, r.maxBackups))
+	for i := r.maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(backupName(r.path, i), backupName(r.path, i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(r.path, backupName(r.path, 1)); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func backupName(path string, idx int) string {
+	return fmt.Sprintf("%s.%d", path, idx)
+}
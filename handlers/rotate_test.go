@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotate(t *testing.T) {
+	t.Run("Rotation", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.log")
+		w, err := Rotate(path, 10, 2)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		defer w.Close()
+
+		for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+			if _, err := w.Write([]byte(line)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+		}
+
+		wants := map[string]string{
+			path:        "fourth\n",
+			path + ".1": "third\n",
+			path + ".2": "second\n",
+		}
+		for p, content := range wants {
+			b, err := os.ReadFile(p)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				continue
+			}
+			if string(b) != content {
+				t.Errorf("output mismatch error in %s: wanted %q ; got %q", p, content, string(b))
+			}
+		}
+		if _, err := os.Stat(path + ".3"); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected no more than 2 backups")
+		}
+	})
+	t.Run("Truncate", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.log")
+		w, _ := Rotate(path, 10, 0)
+		defer w.Close()
+
+		_, _ = w.Write([]byte("first\n"))
+		_, _ = w.Write([]byte("second\n"))
+
+		b, _ := os.ReadFile(path)
+		if string(b) != "second\n" {
+			t.Errorf("output mismatch error: wanted %q ; got %q", "second\n", string(b))
+		}
+	})
+	t.Run("InvalidMaxSize", func(t *testing.T) {
+		_, err := Rotate(filepath.Join(t.TempDir(), "test.log"), 0, 1)
+		if !errors.Is(err, ErrInvalidMaxSize) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrInvalidMaxSize, err)
+		}
+	})
+	t.Run("WriteAfterClose", func(t *testing.T) {
+		w, _ := Rotate(filepath.Join(t.TempDir(), "test.log"), 10, 0)
+		_ = w.Close()
+
+		if _, err := w.Write([]byte("data")); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("unexpected error: wanted %v ; got %v", os.ErrClosed, err)
+		}
+	})
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// DefaultRouteKey is the attribute key that a Router dispatches records by, if
+// none is set
+const DefaultRouteKey = "tenant_id"
+
+// RouterConfig configures how a Router dispatches records
+type RouterConfig struct {
+	// Key is the key of the attribute whose value selects the route of a
+	// record, defaulting to DefaultRouteKey. Attributes bound to the Router
+	// (with With) are also looked up
+	Key string
+	// New creates the Handler for the route with the value `value` (formatted
+	// as a string), like a file or a Loki client with the tenant's labels. It
+	// is called on the first record with a value, and until it returns a
+	// Handler for it. If nil or if it returns nil, the route's records are
+	// handled by the Default Handler
+	New func(value string) Handler
+	// Default is the Handler for the records without the Key attribute, or
+	// whose route has no Handler. If nil, these records are dropped
+	Default Handler
+	// MaxRoutes caps the number of routes created, if greater than zero, so
+	// that attribute values from untrusted input do not create unbounded
+	// Handlers. Once reached, the records of new values are handled by the
+	// Default Handler
+	MaxRoutes int
+}
+
+// Router is a Handler that dispatches records to a Handler per value of an
+// attribute, like a tenant's ID, so that each tenant's records go to separate
+// files or streams. The Handlers are created lazily, on the first record for a
+// value.
+//
+// Handlers derived from a Router (with its With* methods) share its routes,
+// and apply the same changes to the routes' Handlers
+type Router struct {
+	routerHandler
+}
+
+type routerHandler struct {
+	key    string
+	bound  []attr.Attr
+	derive []func(Handler) Handler
+	r      *routes
+}
+
+type routes struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	order    []string // the values of the routes, in creation order
+	new      func(value string) Handler
+	def      Handler
+	max      int
+}
+
+// NewRouter creates a Router as configured by the RouterConfig `conf`
+func NewRouter(conf RouterConfig) *Router {
+	if conf.Key == "" {
+		conf.Key = DefaultRouteKey
+	}
+	if conf.Default == nil {
+		conf.Default = Unimpl()
+	}
+
+	return &Router{
+		routerHandler: routerHandler{
+			key: conf.Key,
+			r: &routes{
+				handlers: make(map[string]Handler),
+				new:      conf.New,
+				def:      conf.Default,
+				max:      conf.MaxRoutes,
+			},
+		},
+	}
+}
+
+// Routes returns the values of the routes created so far, sorted
+func (r *Router) Routes() []string {
+	r.r.mu.RLock()
+	defer r.r.mu.RUnlock()
+
+	values := append([]string{}, r.r.order...)
+	sort.Strings(values)
+	return values
+}
+
+// get returns the Handler of the route with the value `value`, creating it if
+// needed, or nil if it has none
+func (r *routes) get(value string) Handler {
+	r.mu.RLock()
+	h, ok := r.handlers[value]
+	r.mu.RUnlock()
+	if ok {
+		return h
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if h, ok = r.handlers[value]; ok {
+		return h
+	}
+	if r.new == nil || (r.max > 0 && len(r.order) >= r.max) {
+		return nil
+	}
+
+	if h = r.new(value); h != nil {
+		r.handlers[value] = h
+		r.order = append(r.order, value)
+	}
+	return h
+}
+
+// all returns the Default Handler and the Handlers of the routes created so
+// far, in order
+func (r *routes) all() []Handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hs := make([]Handler, 0, len(r.order)+1)
+	hs = append(hs, r.def)
+	for _, value := range r.order {
+		hs = append(hs, r.handlers[value])
+	}
+	return hs
+}
+
+// apply returns the Handler `h` with the changes made to this Handler
+func (rh routerHandler) apply(h Handler) Handler {
+	for _, fn := range rh.derive {
+		h = fn(h)
+	}
+	return h
+}
+
+// route returns the Handler for the Record `r`
+func (rh routerHandler) route(r records.Record) Handler {
+	if a := lookup(rh.key, r.Attrs(), rh.bound); a != nil {
+		if h := rh.r.get(fmt.Sprint(a.Value())); h != nil {
+			return rh.apply(h)
+		}
+	}
+	return rh.apply(rh.r.def)
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+//
+// As the route of a record is not known beforehand, it accepts the levels
+// enabled by any of the routes' Handlers (or all of them, before any route
+// is created)
+func (rh routerHandler) Enabled(level level.Level) bool {
+	hs := rh.r.all()
+	if len(hs) == 1 && rh.r.new != nil {
+		return true
+	}
+	for _, h := range hs {
+		if rh.apply(h).Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle will process the input Record, returning an error if raised
+func (rh routerHandler) Handle(r records.Record) error {
+	return rh.route(r).Handle(r)
+}
+
+// Ping implements Pinger, checking the health of the Default Handler and of
+// the routes' Handlers
+func (rh routerHandler) Ping(ctx context.Context) error {
+	var errs []error
+	for _, h := range rh.r.all() {
+		if err := Ping(ctx, h); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown implements Shutdowner, shutting down the Default Handler and the
+// routes' Handlers
+func (rh routerHandler) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, h := range rh.r.all() {
+		if err := Shutdown(ctx, h); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// with returns a copy of this Handler, applying `fn` to the routes' Handlers
+func (rh routerHandler) with(fn func(Handler) Handler) routerHandler {
+	derive := make([]func(Handler) Handler, 0, len(rh.derive)+1)
+	return routerHandler{
+		key:    rh.key,
+		bound:  rh.bound,
+		derive: append(append(derive, rh.derive...), fn),
+		r:      rh.r,
+	}
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (rh routerHandler) With(attrs ...attr.Attr) Handler {
+	cp := rh.with(func(h Handler) Handler { return h.With(attrs...) })
+	bound := make([]attr.Attr, 0, len(rh.bound)+len(attrs))
+	cp.bound = append(append(bound, rh.bound...), attrs...)
+	return cp
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (rh routerHandler) WithSource(addSource bool) Handler {
+	return rh.with(func(h Handler) Handler { return h.WithSource(addSource) })
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (rh routerHandler) WithLevel(level level.Leveler) Handler {
+	return rh.with(func(h Handler) Handler { return h.WithLevel(level) })
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (rh routerHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return rh.with(func(h Handler) Handler { return h.WithReplaceFn(fn) })
+}
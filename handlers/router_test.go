@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestRouter(t *testing.T) {
+	newRouter := func(max int) (*Router, map[string]testHandler, testHandler) {
+		children := map[string]testHandler{}
+		def := newTestHandler()
+		return NewRouter(RouterConfig{
+			New: func(value string) Handler {
+				if value == "unknown" {
+					return nil
+				}
+				th := newTestHandler()
+				children[value] = th
+				return th
+			},
+			Default:   def,
+			MaxRoutes: max,
+		}), children, def
+	}
+	handle := func(t *testing.T, h Handler, attrs ...attr.Attr) {
+		if err := h.Handle(records.New(time.Now(), level.Info, "test message", attrs...)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	t.Run("Dispatch", func(t *testing.T) {
+		router, children, def := newRouter(0)
+
+		handle(t, router, attr.String("tenant_id", "acme"))
+		handle(t, router, attr.String("tenant_id", "acme"))
+		handle(t, router, attr.Int("tenant_id", 42))
+		handle(t, router.With(attr.String("tenant_id", "globex")))
+		handle(t, router, attr.String("tenant_id", "unknown"))
+		handle(t, router)
+
+		if wants := []string{"42", "acme", "globex"}; !reflect.DeepEqual(router.Routes(), wants) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, router.Routes())
+		}
+		for value, wants := range map[string]int{"acme": 2, "42": 1, "globex": 1} {
+			if n := len(children[value].Records()); n != wants {
+				t.Errorf("output mismatch error for %s: wanted %v records ; got %v", value, wants, n)
+			}
+		}
+		if n := len(def.Records()); n != 2 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 2, n)
+		}
+	})
+
+	t.Run("MaxRoutes", func(t *testing.T) {
+		router, _, def := newRouter(1)
+
+		handle(t, router, attr.String("tenant_id", "acme"))
+		handle(t, router, attr.String("tenant_id", "globex"))
+
+		if wants := []string{"acme"}; !reflect.DeepEqual(router.Routes(), wants) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, router.Routes())
+		}
+		if n := len(def.Records()); n != 1 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 1, n)
+		}
+	})
+
+	t.Run("Derived", func(t *testing.T) {
+		router, children, _ := newRouter(0)
+
+		h := router.WithLevel(level.Warn)
+		handle(t, h, attr.String("tenant_id", "acme"))
+		_ = h.Handle(records.New(time.Now(), level.Error, "failed", attr.String("tenant_id", "acme")))
+
+		if rs := children["acme"].Records(); len(rs) != 1 || rs[0].Level() != level.Error {
+			t.Errorf("output mismatch error: wanted the error record only ; got %v", rs)
+		}
+		if h.Enabled(level.Info) || !h.Enabled(level.Warn) {
+			t.Errorf("output mismatch error: wanted the routes' level to apply")
+		}
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		router, _, _ := newRouter(0)
+		handle(t, router, attr.String("tenant_id", "acme"))
+
+		if err := router.Shutdown(context.Background()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+type sampleHandler struct {
+	h       Handler
+	every   uint64
+	counter *atomic.Uint64
+}
+
+// Sample decorates the Handler `h` so that only one in every `every` records
+// is handled, starting with the first one. Records are dropped silently.
+//
+// If `every` is not greater than one, the Handler `h` is returned as-is
+func Sample(h Handler, every int) Handler {
+	if h == nil {
+		return nil
+	}
+	if every <= 1 {
+		return h
+	}
+
+	return sampleHandler{
+		h:       h,
+		every:   uint64(every),
+		counter: &atomic.Uint64{},
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (s sampleHandler) Enabled(level level.Level) bool {
+	return s.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (s sampleHandler) Handle(r records.Record) error {
+	if (s.counter.Add(1)-1)%s.every != 0 {
+		return nil
+	}
+	return s.h.Handle(r)
+}
+
+// Capabilities implements Capable, returning the capabilities of the decorated
+// Handler
+func (s sampleHandler) Capabilities() Capability {
+	return decoratedCapabilities(s.h)
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (s sampleHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, s.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (s sampleHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, s.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (s sampleHandler) With(attrs ...attr.Attr) Handler {
+	return sampleHandler{
+		h:       s.h.With(attrs...),
+		every:   s.every,
+		counter: s.counter,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (s sampleHandler) WithSource(addSource bool) Handler {
+	return sampleHandler{
+		h:       s.h.WithSource(addSource),
+		every:   s.every,
+		counter: s.counter,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (s sampleHandler) WithLevel(level level.Leveler) Handler {
+	return sampleHandler{
+		h:       s.h.WithLevel(level),
+		every:   s.every,
+		counter: s.counter,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (s sampleHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return sampleHandler{
+		h:       s.h.WithReplaceFn(fn),
+		every:   s.every,
+		counter: s.counter,
+	}
+}
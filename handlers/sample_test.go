@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestSample(t *testing.T) {
+	t.Run("EveryThird", func(t *testing.T) {
+		th := newTestHandler()
+		h := Sample(th, 3)
+
+		for i := 0; i < 7; i++ {
+			_ = h.Handle(records.New(time.Now(), level.Info, "test message"))
+		}
+
+		if n := len(th.Records()); n != 3 {
+			t.Errorf("unexpected number of records: wanted %v ; got %v", 3, n)
+		}
+	})
+	t.Run("NoSampling", func(t *testing.T) {
+		th := newTestHandler()
+		h := Sample(th, 1)
+
+		if _, ok := h.(testHandler); !ok {
+			t.Errorf("expected the input handler to be returned ; got %T", h)
+		}
+	})
+}
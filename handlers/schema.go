@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const violationsKey = "schema_violations"
+
+// ErrSchemaViolation is raised by a Handler enforcing a Schema with the
+// ViolationError policy, when a record does not conform to it
+var ErrSchemaViolation error = errors.New("record violates schema")
+
+// Kind is the type constraint of an attribute in a Schema
+type Kind int
+
+const (
+	// KindAny accepts any value
+	KindAny Kind = iota
+	// KindString accepts strings
+	KindString
+	// KindInt accepts signed and unsigned integers
+	KindInt
+	// KindFloat accepts floating-point numbers and integers
+	KindFloat
+	// KindBool accepts booleans
+	KindBool
+	// KindTime accepts time.Time values
+	KindTime
+	// KindDuration accepts time.Duration values
+	KindDuration
+	// KindGroup accepts groups of attributes
+	KindGroup
+)
+
+// String returns the name of the Kind
+func (k Kind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindBool:
+		return "bool"
+	case KindTime:
+		return "time"
+	case KindDuration:
+		return "duration"
+	case KindGroup:
+		return "group"
+	default:
+		return "any"
+	}
+}
+
+// Field describes an attribute in a Schema
+type Field struct {
+	// Key is the attribute's key
+	Key string
+	// Kind is the type that the attribute's value must have, if present
+	Kind Kind
+	// Required makes the records without the attribute violate the Schema
+	Required bool
+}
+
+// Schema declares the attributes that records are expected to carry
+type Schema struct {
+	// Fields apply to all records
+	Fields []Field
+	// Levels apply to the records with a level, keyed by its name (e.g.
+	// "error")
+	Levels map[string][]Field
+	// Events apply to the records with a message, keyed by the message
+	Events map[string][]Field
+}
+
+// Violation describes an attribute that does not conform to a Schema
+type Violation struct {
+	Key    string
+	Reason string
+}
+
+// String returns the Violation as text
+func (v Violation) String() string {
+	return v.Key + ": " + v.Reason
+}
+
+// Validate checks the Record `r` against the Schema, returning the
+// violations found, if any
+func (s Schema) Validate(r records.Record) []Violation {
+	return s.validate(r, nil)
+}
+
+func (s Schema) validate(r records.Record, bound []attr.Attr) []Violation {
+	var violations []Violation
+
+	check := func(fields []Field) {
+		for _, f := range fields {
+			a := lookup(f.Key, r.Attrs(), bound)
+			switch {
+			case a == nil && f.Required:
+				violations = append(violations, Violation{Key: f.Key, Reason: "missing required attribute"})
+			case a != nil && !f.Kind.matches(a.Value()):
+				violations = append(violations, Violation{
+					Key:    f.Key,
+					Reason: fmt.Sprintf("expected %s value, got %T", f.Kind, a.Value()),
+				})
+			}
+		}
+	}
+
+	check(s.Fields)
+	if r.Level() != nil {
+		check(s.Levels[r.Level().String()])
+	}
+	check(s.Events[r.Message()])
+
+	return violations
+}
+
+// lookup returns the last attribute with the key `key` in the record's
+// attributes, or in the bound attributes if not present
+func lookup(key string, attrs, bound []attr.Attr) attr.Attr {
+	for _, list := range [][]attr.Attr{attrs, bound} {
+		for i := len(list) - 1; i >= 0; i-- {
+			if list[i] != nil && list[i].Key() == key {
+				return list[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (k Kind) matches(value any) bool {
+	switch value.(type) {
+	case time.Duration:
+		return k == KindAny || k == KindDuration
+	case string:
+		return k == KindAny || k == KindString
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return k == KindAny || k == KindInt || k == KindFloat
+	case float32, float64:
+		return k == KindAny || k == KindFloat
+	case bool:
+		return k == KindAny || k == KindBool
+	case time.Time:
+		return k == KindAny || k == KindTime
+	case []attr.Attr, attr.Attrs, attr.Attr:
+		return k == KindAny || k == KindGroup
+	default:
+		return k == KindAny
+	}
+}
+
+// ViolationPolicy defines what a Handler enforcing a Schema does with the
+// records that violate it
+type ViolationPolicy int
+
+const (
+	// ViolationAnnotate handles the violating records, adding their violations
+	// as a `schema_violations` attribute
+	ViolationAnnotate ViolationPolicy = iota
+	// ViolationDrop discards the violating records
+	ViolationDrop
+	// ViolationError discards the violating records, returning an error
+	// wrapping ErrSchemaViolation
+	ViolationError
+)
+
+type schemaHandler struct {
+	h      Handler
+	schema Schema
+	policy ViolationPolicy
+	bound  []attr.Attr
+}
+
+// Enforce decorates the Handler `h` so that every Record it handles is
+// validated against the Schema `schema`, applying the ViolationPolicy
+// `policy` to the ones that violate it. Attributes bound with the Handler's
+// With method count towards the required ones
+func Enforce(h Handler, schema Schema, policy ViolationPolicy) Handler {
+	if h == nil {
+		return nil
+	}
+
+	return schemaHandler{
+		h:      h,
+		schema: schema,
+		policy: policy,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (s schemaHandler) Enabled(level level.Level) bool {
+	return s.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (s schemaHandler) Handle(r records.Record) error {
+	if !s.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	violations := s.schema.validate(r, s.bound)
+	if len(violations) == 0 {
+		return s.h.Handle(r)
+	}
+
+	switch s.policy {
+	case ViolationDrop:
+		return nil
+	case ViolationError:
+		return fmt.Errorf("%w: %q: %v", ErrSchemaViolation, r.Message(), violations)
+	default:
+		reasons := make([]string, len(violations))
+		for i := range violations {
+			reasons[i] = violations[i].String()
+		}
+		return s.h.Handle(r.AddAttr(attr.New(violationsKey, reasons)))
+	}
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (s schemaHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, s.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (s schemaHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, s.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (s schemaHandler) With(attrs ...attr.Attr) Handler {
+	bound := make([]attr.Attr, 0, len(s.bound)+len(attrs))
+	return schemaHandler{
+		h:      s.h.With(attrs...),
+		schema: s.schema,
+		policy: s.policy,
+		bound:  append(append(bound, s.bound...), attrs...),
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (s schemaHandler) WithSource(addSource bool) Handler {
+	return schemaHandler{
+		h:      s.h.WithSource(addSource),
+		schema: s.schema,
+		policy: s.policy,
+		bound:  s.bound,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (s schemaHandler) WithLevel(level level.Leveler) Handler {
+	return schemaHandler{
+		h:      s.h.WithLevel(level),
+		schema: s.schema,
+		policy: s.policy,
+		bound:  s.bound,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (s schemaHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return schemaHandler{
+		h:      s.h.WithReplaceFn(fn),
+		schema: s.schema,
+		policy: s.policy,
+		bound:  s.bound,
+	}
+}
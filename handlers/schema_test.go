@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+var testSchema = Schema{
+	Fields: []Field{
+		{Key: "service", Kind: KindString, Required: true},
+		{Key: "duration", Kind: KindDuration},
+	},
+	Levels: map[string][]Field{
+		"error": {{Key: "error", Required: true}},
+	},
+	Events: map[string][]Field{
+		"order placed": {{Key: "order_id", Kind: KindInt, Required: true}},
+	},
+}
+
+func TestSchema(t *testing.T) {
+	for _, testcase := range []struct {
+		name  string
+		r     records.Record
+		wants []string
+	}{
+		{
+			name: "Valid",
+			r:    records.New(time.Now(), level.Info, "started", attr.String("service", "api")),
+		},
+		{
+			name:  "MissingField",
+			r:     records.New(time.Now(), level.Info, "started"),
+			wants: []string{"service: missing required attribute"},
+		},
+		{
+			name:  "WrongKind",
+			r:     records.New(time.Now(), level.Info, "started", attr.Int("service", 1), attr.Int("duration", 3)),
+			wants: []string{"service: expected string value, got int64", "duration: expected duration value, got int64"},
+		},
+		{
+			name:  "Level",
+			r:     records.New(time.Now(), level.Error, "failed", attr.String("service", "api")),
+			wants: []string{"error: missing required attribute"},
+		},
+		{
+			name:  "Event",
+			r:     records.New(time.Now(), level.Info, "order placed", attr.String("service", "api"), attr.String("order_id", "42")),
+			wants: []string{"order_id: expected int value, got string"},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			violations := testSchema.Validate(testcase.r)
+			if len(violations) != len(testcase.wants) {
+				t.Errorf("output mismatch error: wanted %v ; got %v", testcase.wants, violations)
+				return
+			}
+			for i := range violations {
+				if violations[i].String() != testcase.wants[i] {
+					t.Errorf("output mismatch error: wanted %q ; got %q", testcase.wants[i], violations[i].String())
+				}
+			}
+		})
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	invalid := records.New(time.Now(), level.Info, "started")
+
+	t.Run("Annotate", func(t *testing.T) {
+		th := newTestHandler()
+		if err := Enforce(th, testSchema, ViolationAnnotate).Handle(invalid); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		out := attr.Map(th.Records()[0].Attrs()...)
+		reasons, _ := out[violationsKey].([]string)
+		if len(reasons) != 1 || reasons[0] != "service: missing required attribute" {
+			t.Errorf("output mismatch error: wanted the violation annotated ; got %v", out)
+		}
+	})
+	t.Run("Drop", func(t *testing.T) {
+		th := newTestHandler()
+		if err := Enforce(th, testSchema, ViolationDrop).Handle(invalid); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(th.Records()) != 0 {
+			t.Errorf("output mismatch error: wanted no records ; got %v", th.Records())
+		}
+	})
+	t.Run("Error", func(t *testing.T) {
+		th := newTestHandler()
+		if err := Enforce(th, testSchema, ViolationError).Handle(invalid); !errors.Is(err, ErrSchemaViolation) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrSchemaViolation, err)
+		}
+		if len(th.Records()) != 0 {
+			t.Errorf("output mismatch error: wanted no records ; got %v", th.Records())
+		}
+	})
+	t.Run("BoundAttrs", func(t *testing.T) {
+		th := newTestHandler()
+		h := Enforce(th, testSchema, ViolationError).With(attr.String("service", "api"))
+
+		if err := h.Handle(invalid); err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+		}
+		if len(th.Records()) != 1 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 1, len(th.Records()))
+		}
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		th := newTestHandler()
+		h := Enforce(th, testSchema, ViolationError).WithLevel(level.Warn)
+
+		if err := h.Handle(invalid); err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+		}
+	})
+}
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const defaultMask = "[REDACTED]"
+
+// ScrubRule describes data to be masked by a Scrub Handler: either the
+// matches of Pattern in the messages and string values of records, or the
+// whole value of the attributes whose key is in Keys
+type ScrubRule struct {
+	// Pattern is the expression to find in messages and string values
+	Pattern *regexp.Regexp
+	// Validate, if set, is called with each match of Pattern, which is only
+	// masked if it returns true. It allows discarding false positives
+	Validate func(match string) bool
+	// Keys are the attribute keys whose values are masked entirely,
+	// regardless of their type
+	Keys []string
+	// Mask replaces the matched data. Defaults to "[REDACTED]"
+	Mask string
+}
+
+var (
+	// ScrubEmails masks email addresses
+	ScrubEmails = ScrubRule{
+		Pattern: regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`),
+	}
+	// ScrubCreditCards masks payment card numbers (13 to 19 digits, optionally
+	// separated by spaces or dashes) that pass the Luhn checksum
+	ScrubCreditCards = ScrubRule{
+		Pattern:  regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		Validate: luhn,
+	}
+	// ScrubBearerTokens masks bearer tokens, such as the ones in HTTP
+	// Authorization headers
+	ScrubBearerTokens = ScrubRule{
+		Pattern: regexp.MustCompile(`(?i)\bbearer\s+[a-zA-Z0-9\-._~+/]+=*`),
+	}
+	// ScrubIPs masks IPv4 and IPv6 addresses
+	ScrubIPs = ScrubRule{
+		Pattern: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b|(?i)(?:[0-9a-f]{0,4}:){2,7}[0-9a-f]{0,4}`),
+		Validate: func(match string) bool {
+			return net.ParseIP(match) != nil
+		},
+	}
+)
+
+// DefaultScrubRules returns the built-in ScrubRules, masking emails, payment
+// card numbers, bearer tokens and IP addresses
+func DefaultScrubRules() []ScrubRule {
+	return []ScrubRule{ScrubEmails, ScrubCreditCards, ScrubBearerTokens, ScrubIPs}
+}
+
+// ScrubKeys returns a ScrubRule masking the values of the attributes with the
+// keys `keys`, such as "password" or "token"
+func ScrubKeys(keys ...string) ScrubRule {
+	return ScrubRule{Keys: keys}
+}
+
+// ScrubString masks the data matching the patterns of the ScrubRules `rules`
+// in the string `str`, as a Scrub Handler does in messages and string values,
+// for data scrubbed before being logged. If no rules are provided, the
+// DefaultScrubRules are used
+func ScrubString(str string, rules ...ScrubRule) string {
+	if len(rules) == 0 {
+		rules = DefaultScrubRules()
+	}
+
+	str, _ = scrubHandler{rules: rules}.scrubString(str)
+	return str
+}
+
+// ScrubJSON masks the data matching the ScrubRules `rules` in the JSON
+// document `data`: the whole values of the object fields whose key is in their
+// Keys, at any depth, and the matches of their patterns in the string values.
+// If no rules are provided, the DefaultScrubRules are used. Returns an error
+// if `data` is not valid JSON
+func ScrubJSON(data []byte, rules ...ScrubRule) ([]byte, error) {
+	if len(rules) == 0 {
+		rules = DefaultScrubRules()
+	}
+
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(scrubHandler{rules: rules}.scrubJSON(v))
+}
+
+type scrubHandler struct {
+	h     Handler
+	rules []ScrubRule
+}
+
+// Scrub decorates the Handler `h` so that the data matching the ScrubRules
+// `rules` is masked in the message and attributes of every Record it handles,
+// as well as in the attributes bound with its With method. Attribute values
+// in nested groups are also scrubbed. If no rules are provided, the
+// DefaultScrubRules are used
+func Scrub(h Handler, rules ...ScrubRule) Handler {
+	if h == nil {
+		return nil
+	}
+	if len(rules) == 0 {
+		rules = DefaultScrubRules()
+	}
+
+	return scrubHandler{
+		h:     h,
+		rules: rules,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (s scrubHandler) Enabled(level level.Level) bool {
+	return s.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (s scrubHandler) Handle(r records.Record) error {
+	msg, msgChanged := s.scrubString(r.Message())
+	attrs, attrsChanged := s.scrubAttrs(r.Attrs())
+	if !msgChanged && !attrsChanged {
+		return s.h.Handle(r)
+	}
+	return s.h.Handle(records.WithPC(records.New(r.Time(), r.Level(), msg, attrs...), records.PC(r)))
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (s scrubHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, s.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (s scrubHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, s.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`, scrubbed
+func (s scrubHandler) With(attrs ...attr.Attr) Handler {
+	attrs, _ = s.scrubAttrs(attrs)
+	return scrubHandler{
+		h:     s.h.With(attrs...),
+		rules: s.rules,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (s scrubHandler) WithSource(addSource bool) Handler {
+	return scrubHandler{
+		h:     s.h.WithSource(addSource),
+		rules: s.rules,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (s scrubHandler) WithLevel(level level.Leveler) Handler {
+	return scrubHandler{
+		h:     s.h.WithLevel(level),
+		rules: s.rules,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (s scrubHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return scrubHandler{
+		h:     s.h.WithReplaceFn(fn),
+		rules: s.rules,
+	}
+}
+
+// scrubAttrs returns the scrubbed copy of `attrs`, or `attrs` itself if
+// nothing was masked
+func (s scrubHandler) scrubAttrs(attrs []attr.Attr) ([]attr.Attr, bool) {
+	var out []attr.Attr
+	for i, a := range attrs {
+		scrubbed, changed := s.scrubAttr(a)
+		if !changed {
+			if out != nil {
+				out = append(out, a)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]attr.Attr, i, len(attrs))
+			copy(out, attrs[:i])
+		}
+		out = append(out, scrubbed)
+	}
+
+	if out == nil {
+		return attrs, false
+	}
+	return out, true
+}
+
+func (s scrubHandler) scrubAttr(a attr.Attr) (attr.Attr, bool) {
+	if a == nil {
+		return a, false
+	}
+
+	if mask, ok := s.keyMask(a.Key()); ok {
+		return attr.String(a.Key(), mask), true
+	}
+
+	switch v := a.Value().(type) {
+	case string:
+		if scrubbed, ok := s.scrubString(v); ok {
+			return attr.String(a.Key(), scrubbed), true
+		}
+	case error:
+		if scrubbed, ok := s.scrubString(v.Error()); ok {
+			return attr.String(a.Key(), scrubbed), true
+		}
+	case []attr.Attr:
+		if scrubbed, ok := s.scrubAttrs(v); ok {
+			return attr.New(a.Key(), scrubbed), true
+		}
+	case attr.Attrs:
+		if scrubbed, ok := s.scrubAttrs(v); ok {
+			return attr.New(a.Key(), scrubbed), true
+		}
+	}
+	return a, false
+}
+
+// scrubJSON scrubs the decoded JSON value `v` in place, returning it
+func (s scrubHandler) scrubJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if mask, ok := s.keyMask(key); ok {
+				v[key] = mask
+				continue
+			}
+			v[key] = s.scrubJSON(value)
+		}
+	case []any:
+		for i := range v {
+			v[i] = s.scrubJSON(v[i])
+		}
+	case string:
+		scrubbed, _ := s.scrubString(v)
+		return scrubbed
+	}
+	return v
+}
+
+// keyMask returns the mask of the first rule masking the attributes with key
+// `key`, if any
+func (s scrubHandler) keyMask(key string) (string, bool) {
+	for i := range s.rules {
+		for _, k := range s.rules[i].Keys {
+			if key == k {
+				return s.rules[i].mask(), true
+			}
+		}
+	}
+	return "", false
+}
+
+func (s scrubHandler) scrubString(str string) (string, bool) {
+	changed := false
+	for i := range s.rules {
+		rule := s.rules[i]
+		if rule.Pattern == nil || !rule.Pattern.MatchString(str) {
+			continue
+		}
+
+		str = rule.Pattern.ReplaceAllStringFunc(str, func(match string) string {
+			if rule.Validate != nil && !rule.Validate(match) {
+				return match
+			}
+			changed = true
+			return rule.mask()
+		})
+	}
+	return str, changed
+}
+
+func (r ScrubRule) mask() string {
+	if r.Mask == "" {
+		return defaultMask
+	}
+	return r.Mask
+}
+
+// luhn returns true if the digits in `s` pass the Luhn checksum
+func luhn(s string) bool {
+	s = strings.NewReplacer(" ", "", "-", "").Replace(s)
+
+	var sum int
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		d := int(s[i] - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// ShardConfig configures how a Shard Handler distributes records
+type ShardConfig struct {
+	// Key is the key of the attribute whose value selects the shard of a
+	// record, by its hash, so that the records with the same value (like a
+	// request's or a partition's ID) are handled by the same Handler, in
+	// order. Attributes bound to the Handler (with With) are also looked up.
+	// If empty, or absent from a record, the shards are taken in turns
+	Key string
+	// Weights are the relative weights of the Handlers, in the same order, so
+	// that a Handler with weight 2 takes twice as many records as one with
+	// weight 1. Missing weights, or not greater than zero, are 1
+	Weights []int
+}
+
+type shardHandler struct {
+	key      string
+	handlers []Handler
+	slots    []int
+	bound    []attr.Attr
+	next     *atomic.Uint64
+}
+
+// Shard distributes the records across the Handlers `h`, as configured in the
+// ShardConfig `conf`, with each record handled by a single one of them; to
+// scale the write throughput across several files, connections or partitions.
+// The shards are weighted, either when taken in turns or by hash.
+//
+// Nil Handlers are skipped (along with their weights). If only one Handler is
+// set, it is returned as-is. Handlers derived from a Shard Handler (with its
+// With* methods) share its turns
+func Shard(conf ShardConfig, h ...Handler) Handler {
+	var (
+		handlers = make([]Handler, 0, len(h))
+		weights  = make([]int, 0, len(h))
+	)
+	for i, handler := range h {
+		if handler == nil {
+			continue
+		}
+		weight := 1
+		if i < len(conf.Weights) && conf.Weights[i] > 0 {
+			weight = conf.Weights[i]
+		}
+		handlers = append(handlers, handler)
+		weights = append(weights, weight)
+	}
+
+	switch len(handlers) {
+	case 0:
+		return nil
+	case 1:
+		return handlers[0]
+	}
+
+	return shardHandler{
+		key:      conf.Key,
+		handlers: handlers,
+		slots:    shardSlots(weights),
+		next:     &atomic.Uint64{},
+	}
+}
+
+// shardSlots returns the sequence of the shards' indices in a round of turns,
+// where each shard appears as many times as its weight, spread with the
+// smooth weighted round-robin algorithm (so that a heavier shard does not take
+// all of its turns at once)
+func shardSlots(weights []int) []int {
+	divisor := weights[0]
+	total := 0
+	for _, w := range weights {
+		divisor = gcd(divisor, w)
+	}
+	for i := range weights {
+		weights[i] /= divisor
+		total += weights[i]
+	}
+
+	var (
+		slots   = make([]int, 0, total)
+		current = make([]int, len(weights))
+	)
+	for len(slots) < total {
+		best := 0
+		for i, w := range weights {
+			current[i] += w
+			if current[i] > current[best] {
+				best = i
+			}
+		}
+		current[best] -= total
+		slots = append(slots, best)
+	}
+	return slots
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// shard returns the Handler for the Record `r`
+func (s shardHandler) shard(r records.Record) Handler {
+	if s.key != "" {
+		if a := lookup(s.key, r.Attrs(), s.bound); a != nil {
+			hash := fnv.New64a()
+			if v, ok := a.Value().(string); ok {
+				_, _ = hash.Write([]byte(v))
+			} else {
+				_, _ = fmt.Fprint(hash, a.Value())
+			}
+			return s.handlers[s.slots[hash.Sum64()%uint64(len(s.slots))]]
+		}
+	}
+
+	n := s.next.Add(1) - 1
+	return s.handlers[s.slots[n%uint64(len(s.slots))]]
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (s shardHandler) Enabled(level level.Level) bool {
+	for _, h := range s.handlers {
+		if h.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle will process the input Record, returning an error if raised
+func (s shardHandler) Handle(r records.Record) error {
+	return s.shard(r).Handle(r)
+}
+
+// Capabilities implements Capable, returning the capabilities of the shards
+// like a Multi Handler does
+func (s shardHandler) Capabilities() Capability {
+	return multiHandler{handlers: s.handlers}.Capabilities()
+}
+
+// Ping implements Pinger, checking the health of all shards
+func (s shardHandler) Ping(ctx context.Context) error {
+	return multiHandler{handlers: s.handlers}.Ping(ctx)
+}
+
+// Shutdown implements Shutdowner, shutting down all shards
+func (s shardHandler) Shutdown(ctx context.Context) error {
+	return multiHandler{handlers: s.handlers}.Shutdown(ctx)
+}
+
+// derive spawns a copy of this Handler with each of its shards replaced by
+// the result of calling `fn` on it
+func (s shardHandler) derive(fn func(Handler) Handler) shardHandler {
+	handlers := make([]Handler, len(s.handlers))
+	for i, h := range s.handlers {
+		handlers[i] = fn(h)
+	}
+
+	s.handlers = handlers
+	return s
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (s shardHandler) With(attrs ...attr.Attr) Handler {
+	bound := make([]attr.Attr, 0, len(s.bound)+len(attrs))
+	cp := s.derive(func(h Handler) Handler {
+		return h.With(attrs...)
+	})
+	cp.bound = append(append(bound, s.bound...), attrs...)
+	return cp
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (s shardHandler) WithSource(addSource bool) Handler {
+	return s.derive(func(h Handler) Handler {
+		return h.WithSource(addSource)
+	})
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (s shardHandler) WithLevel(level level.Leveler) Handler {
+	return s.derive(func(h Handler) Handler {
+		return h.WithLevel(level)
+	})
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (s shardHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return s.derive(func(h Handler) Handler {
+		return h.WithReplaceFn(fn)
+	})
+}
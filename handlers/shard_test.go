@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestShard(t *testing.T) {
+	t.Run("RoundRobin", func(t *testing.T) {
+		a, b := newTestHandler(), newTestHandler()
+		h := Shard(ShardConfig{Weights: []int{2, 1}}, a, nil, b)
+
+		for i := 0; i < 6; i++ {
+			_ = h.Handle(records.New(time.Now(), level.Info, "event"))
+		}
+
+		if len(a.Records()) != 4 || len(b.Records()) != 2 {
+			t.Errorf("output mismatch error: wanted 4 and 2 records ; got %v and %v", len(a.Records()), len(b.Records()))
+		}
+	})
+	t.Run("Key", func(t *testing.T) {
+		shards := []testHandler{newTestHandler(), newTestHandler(), newTestHandler()}
+		h := Shard(ShardConfig{Key: "partition"}, shards[0], shards[1], shards[2])
+
+		for i := 0; i < 30; i++ {
+			_ = h.Handle(records.New(time.Now(), level.Info, "event", attr.String("partition", fmt.Sprint(i%5))))
+		}
+
+		seen := map[string]int{}
+		for idx, shard := range shards {
+			for _, r := range shard.Records() {
+				key := fmt.Sprint(r.Attrs()[0].Value())
+				if prev, ok := seen[key]; ok && prev != idx {
+					t.Errorf("output mismatch error: wanted partition %s in shard %d ; got %d", key, prev, idx)
+				}
+				seen[key] = idx
+			}
+		}
+		if len(seen) != 5 {
+			t.Errorf("output mismatch error: wanted %v partitions ; got %v", 5, len(seen))
+		}
+	})
+	t.Run("BoundKey", func(t *testing.T) {
+		a, b := newTestHandler(), newTestHandler()
+		h := Shard(ShardConfig{Key: "partition"}, a, b).With(attr.String("partition", "p1"))
+
+		for i := 0; i < 4; i++ {
+			_ = h.Handle(records.New(time.Now(), level.Info, "event"))
+		}
+
+		if n := len(a.Records()) + len(b.Records()); n != 4 || len(a.Records())%4 != 0 {
+			t.Errorf("output mismatch error: wanted all records in one shard ; got %v and %v", len(a.Records()), len(b.Records()))
+		}
+	})
+	t.Run("Single", func(t *testing.T) {
+		a := newTestHandler()
+		if h := Shard(ShardConfig{}, nil, a); !reflect.DeepEqual(h, Handler(a)) {
+			t.Errorf("output mismatch error: wanted the Handler as-is ; got %v", h)
+		}
+		if h := Shard(ShardConfig{}); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+	t.Run("Slots", func(t *testing.T) {
+		wants := []int{0, 1, 0, 2, 0}
+		if got := shardSlots([]int{6, 2, 2}); !reflect.DeepEqual(got, wants) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, got)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import "context"
+
+// Shutdowner is implemented by Handlers and writers that hold resources to be
+// released when the application exits, like buffered data, background
+// goroutines or open files.
+//
+// Decorating Handlers and writers also shut down the ones they wrap, so that a
+// single call covers the entire tree. Shutdown is expected to be idempotent
+type Shutdowner interface {
+	// Shutdown flushes any pending data and releases the resources held,
+	// returning early with the context's error if it is done first
+	Shutdown(ctx context.Context) error
+}
+
+// Shutdown shuts down the Handler or writer `v`, if it implements Shutdowner.
+// Otherwise, there is nothing to release and Shutdown returns nil
+func Shutdown(ctx context.Context, v any) error {
+	if s, ok := v.(Shutdowner); ok {
+		return s.Shutdown(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+// Package siemh provides handlers that write records in the ArcSight Common
+// Event Format (CEF) and the IBM Log Event Extended Format (LEEF), for
+// security information and event management (SIEM) platforms to ingest
+package siemh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const leefTimeFormat = "2006-01-02T15:04:05.000-0700"
+
+// ErrZeroBytes is raised when the `io.Writer` in the handler
+// returns a zero-length of bytes written, when the `Write()`
+// method is called
+var ErrZeroBytes error = errors.New("zero bytes written")
+
+type format int
+
+const (
+	formatCEF format = iota
+	formatLEEF
+)
+
+// Config describes the device that emits the records, and how their attributes
+// are mapped into CEF extensions or LEEF attributes
+type Config struct {
+	// Vendor, Product and Version identify the device in the records' headers
+	Vendor  string
+	Product string
+	Version string
+	// EventIDKey is the key of the attribute holding the event's class ID (or
+	// signature). If empty or absent from a record, its message is used
+	EventIDKey string
+	// Keys maps attribute keys to the extension (or LEEF attribute) keys that
+	// they are written with, like `client_ip` to `src`. Other attributes keep
+	// their key, with the keys of nested groups joined with dots
+	Keys map[string]string
+}
+
+type siemHandler struct {
+	w        io.Writer
+	format   format
+	conf     Config
+	levelRef level.Leveler
+	replFn   func(a attr.Attr) attr.Attr
+	attrs    []attr.Attr
+}
+
+// NewCEF creates a handler that writes records to the io.Writer `w` as CEF
+// (version 0) lines, described by the Config `conf`:
+//
+//	CEF:0|Vendor|Product|Version|EventID|Message|Severity|rt=... key=value
+//
+// Log levels are mapped to a 0-10 severity, from Trace (0) to Fatal (10)
+func NewCEF(w io.Writer, conf Config) handlers.Handler {
+	if w == nil {
+		return nil
+	}
+	return siemHandler{
+		w:      w,
+		format: formatCEF,
+		conf:   conf,
+	}
+}
+
+// NewLEEF creates a handler that writes records to the io.Writer `w` as LEEF
+// (version 1.0) lines, described by the Config `conf`, with tab-separated
+// attributes:
+//
+//	LEEF:1.0|Vendor|Product|Version|EventID|devTime=...	sev=...	msg=...
+//
+// Log levels are mapped to a 1-10 severity, from Trace (1) to Fatal (10)
+func NewLEEF(w io.Writer, conf Config) handlers.Handler {
+	if w == nil {
+		return nil
+	}
+	return siemHandler{
+		w:      w,
+		format: formatLEEF,
+		conf:   conf,
+	}
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h siemHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	eventID := r.Message()
+	if h.conf.EventIDKey != "" {
+		if a := h.find(h.conf.EventIDKey, r.Attrs()); a != nil {
+			eventID = fmt.Sprint(a.Value())
+		}
+	}
+
+	if h.format == formatCEF {
+		h.writeCEF(b, r, eventID)
+	} else {
+		h.writeLEEF(b, r, eventID)
+	}
+	b.WriteByte('\n')
+
+	n, err := h.w.Write(b.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrZeroBytes
+	}
+
+	return nil
+}
+
+func (h siemHandler) writeCEF(b *buffer.Buffer, r records.Record, eventID string) {
+	b.WriteString("CEF:0|")
+	for _, field := range []string{h.conf.Vendor, h.conf.Product, h.conf.Version, eventID, r.Message()} {
+		b.WriteString(escapeHeader(field))
+		b.WriteByte('|')
+	}
+	b.WriteString(strconv.Itoa(severity(r.Level(), 0)))
+	b.WriteString("|rt=")
+	b.WriteString(strconv.FormatInt(r.Time().UnixMilli(), 10))
+
+	h.writeFields(b, "", r.Attrs(), ' ', escapeCEF)
+	h.writeFields(b, "", h.attrs, ' ', escapeCEF)
+}
+
+func (h siemHandler) writeLEEF(b *buffer.Buffer, r records.Record, eventID string) {
+	b.WriteString("LEEF:1.0|")
+	for _, field := range []string{h.conf.Vendor, h.conf.Product, h.conf.Version, eventID} {
+		b.WriteString(escapeHeader(field))
+		b.WriteByte('|')
+	}
+	b.WriteString("devTime=")
+	b.WriteString(r.Time().Format(leefTimeFormat))
+	b.WriteString("\tdevTimeFormat=yyyy-MM-dd'T'HH:mm:ss.SSSZ\tsev=")
+	b.WriteString(strconv.Itoa(severity(r.Level(), 1)))
+	b.WriteString("\tmsg=")
+	b.WriteString(escapeLEEF(r.Message()))
+
+	h.writeFields(b, "", r.Attrs(), '\t', escapeLEEF)
+	h.writeFields(b, "", h.attrs, '\t', escapeLEEF)
+}
+
+// writeFields writes the attributes `attrs` as key-value pairs, each one led
+// by the separator `sep`, flattening the groups with dot-separated keys
+func (h siemHandler) writeFields(b *buffer.Buffer, prefix string, attrs []attr.Attr, sep byte, escape func(string) string) {
+	for _, a := range attrs {
+		if h.replFn != nil && a != nil {
+			a = h.replFn(a)
+		}
+		if a == nil {
+			continue
+		}
+
+		key := prefix + a.Key()
+		if group, ok := a.Value().([]attr.Attr); ok {
+			h.writeFields(b, key+".", group, sep, escape)
+			continue
+		}
+		if mapped, ok := h.conf.Keys[key]; ok {
+			key = mapped
+		}
+
+		b.WriteByte(sep)
+		b.WriteString(key)
+		b.WriteByte('=')
+
+		switch v := a.Value().(type) {
+		case string:
+			b.WriteString(escape(v))
+		case time.Time:
+			b.WriteString(escape(v.Format(time.RFC3339Nano)))
+		case error:
+			b.WriteString(escape(v.Error()))
+		default:
+			b.WriteString(escape(fmt.Sprint(v)))
+		}
+	}
+}
+
+// find returns the last attribute with the key `key` in the record's
+// attributes `attrs`, or in the handler's bound attributes
+func (h siemHandler) find(key string, attrs []attr.Attr) attr.Attr {
+	for _, list := range [2][]attr.Attr{attrs, h.attrs} {
+		for i := len(list) - 1; i >= 0; i-- {
+			if list[i] != nil && list[i].Key() == key {
+				return list[i]
+			}
+		}
+	}
+	return nil
+}
+
+// severity maps the level `lv` to a severity between `floor` and 10
+func severity(lv level.Level, floor int) int {
+	if lv == nil {
+		return 3
+	}
+
+	switch n := lv.Int(); {
+	case n >= level.Fatal.Int():
+		return 10
+	case n >= level.Error.Int():
+		return 8
+	case n >= level.Warn.Int():
+		return 6
+	case n >= level.Info.Int():
+		return 3
+	case n >= level.Debug.Int():
+		return max(1, floor)
+	default:
+		return floor
+	}
+}
+
+var (
+	headerEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\t", " ", "\n", " ", "\r", " ")
+	cefEscaper    = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+	leefEscaper   = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+)
+
+func escapeHeader(s string) string { return headerEscaper.Replace(s) }
+
+func escapeCEF(s string) string { return cefEscaper.Replace(s) }
+
+func escapeLEEF(s string) string { return leefEscaper.Replace(s) }
+
+// Capabilities implements handlers.Capable, returning CapGroups (as groups are
+// flattened with dotted keys) along with the capabilities of the handler's
+// io.Writer
+func (h siemHandler) Capabilities() handlers.Capability {
+	return handlers.EncoderCapabilities(handlers.CapGroups, h.w)
+}
+
+// Ping implements handlers.Pinger, checking the health of the handler's
+// io.Writer
+func (h siemHandler) Ping(ctx context.Context) error {
+	return handlers.Ping(ctx, h.w)
+}
+
+// Shutdown implements handlers.Shutdowner, shutting down the handler's
+// io.Writer
+func (h siemHandler) Shutdown(ctx context.Context) error {
+	return handlers.Shutdown(ctx, h.w)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h siemHandler) With(attrs ...attr.Attr) handlers.Handler {
+	return siemHandler{
+		w:        h.w,
+		format:   h.format,
+		conf:     h.conf,
+		levelRef: h.levelRef,
+		replFn:   h.replFn,
+		attrs:    attrs,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h siemHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h siemHandler) WithSource(bool) handlers.Handler {
+	return h
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h siemHandler) WithLevel(level level.Leveler) handlers.Handler {
+	return siemHandler{
+		w:        h.w,
+		format:   h.format,
+		conf:     h.conf,
+		levelRef: level,
+		replFn:   h.replFn,
+		attrs:    h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h siemHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) handlers.Handler {
+	return siemHandler{
+		w:        h.w,
+		format:   h.format,
+		conf:     h.conf,
+		levelRef: h.levelRef,
+		replFn:   fn,
+		attrs:    h.attrs,
+	}
+}
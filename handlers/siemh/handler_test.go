@@ -0,0 +1,97 @@
+package siemh
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+var (
+	testTime   = time.Date(2024, 1, 2, 3, 4, 5, 600000000, time.UTC)
+	testConfig = Config{
+		Vendor:     "Acme",
+		Product:    "Gate|Keeper",
+		Version:    "1.0",
+		EventIDKey: "event_id",
+		Keys:       map[string]string{"client_ip": "src"},
+	}
+)
+
+func TestCEF(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := NewCEF(b, testConfig).With(attr.String("service", "auth"))
+
+		err := h.Handle(records.New(testTime, level.Warn, "login failed",
+			attr.String("event_id", "AUTH-401"),
+			attr.String("client_ip", "10.0.0.1"),
+			attr.String("query", "a=b"),
+			attr.New("user", []attr.Attr{attr.String("name", "gopher")}),
+		))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		wants := `CEF:0|Acme|Gate\|Keeper|1.0|AUTH-401|login failed|6|rt=1704164645600` +
+			` event_id=AUTH-401 src=10.0.0.1 query=a\=b user.name=gopher service=auth` + "\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("Severity", func(t *testing.T) {
+		for _, test := range []struct {
+			lv    level.Level
+			wants int
+		}{
+			{level.Trace, 0},
+			{level.Debug, 1},
+			{level.Info, 3},
+			{level.Warn, 6},
+			{level.Error, 8},
+			{level.Fatal, 10},
+		} {
+			if got := severity(test.lv, 0); got != test.wants {
+				t.Errorf("output mismatch error for %s: wanted %v ; got %v", test.lv, test.wants, got)
+			}
+		}
+	})
+	t.Run("WithLevel", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := NewCEF(b, testConfig).WithLevel(level.Error)
+
+		_ = h.Handle(records.New(testTime, level.Info, "skipped"))
+
+		if b.Len() != 0 {
+			t.Errorf("output mismatch error: wanted no output ; got %q", b.String())
+		}
+	})
+	t.Run("NilWriter", func(t *testing.T) {
+		if h := NewCEF(nil, testConfig); h != nil {
+			t.Errorf("expected output to be nil")
+		}
+	})
+}
+
+func TestLEEF(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := NewLEEF(b, testConfig)
+
+		err := h.Handle(records.New(testTime, level.Trace, "user\tcreated", attr.String("client_ip", "10.0.0.1")))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		wants := "LEEF:1.0|Acme|Gate\\|Keeper|1.0|user created|devTime=2024-01-02T03:04:05.600+0000" +
+			"\tdevTimeFormat=yyyy-MM-dd'T'HH:mm:ss.SSSZ\tsev=1\tmsg=user created\tsrc=10.0.0.1\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+}
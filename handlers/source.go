@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/zalgonoise/logx/records"
+)
+
+const modulePath = "github.com/zalgonoise/logx"
+
+// SourceConfig describes how a Handler renders the file path in the source
+// references it adds (with WithSource), to keep records compact and
+// independent of the machine that built the binary
+//
+// The zero value keeps the Handler's default, which is the file's base name
+// for the Console, ECS and Template handlers, and its full path for the others
+type SourceConfig struct {
+	// Relative renders the file path relative to the root of the module that
+	// the code belongs to, like `handlers/source.go`, as read from the
+	// binary's build info. For modules not found in the build info (like the
+	// main package's), the last two path elements are kept
+	Relative bool
+	// Elements keeps only the last N elements of the file path, if greater
+	// than zero. With Relative, it applies to the module-relative path
+	Elements int
+	// Function adds the fully qualified name of the caller's function to the
+	// source references, like `github.com/org/app/server.(*Server).Serve`.
+	// The ECS, GCP and Datadog handlers always include it, in their own fields
+	Function bool
+}
+
+// Caller returns the frame of the code that logged the record being handled,
+// which is the first caller outside of this module (or within its tests). It
+// is meant to be called from a Handler's Handle method, when adding a source
+// reference to a record that does not carry its call site (see Source)
+func Caller() (runtime.Frame, bool) {
+	return caller()
+}
+
+// Source returns the frame of the code that logged the Record `r`, resolved
+// from the program counter it carries (see records.WithPC), which the Loggers
+// capture at their call site. For records without one it returns the same as
+// Caller.
+//
+// Unlike Caller, it returns the right frame when the record is handled in
+// another goroutine, like behind an Async handler
+func Source(r records.Record) (runtime.Frame, bool) {
+	if pc := records.PC(r); pc != 0 {
+		f := framesFor(pc)[0]
+		return f, f.File != ""
+	}
+	return caller()
+}
+
+// Path returns the file path of the frame `f` as configured, defaulting to its
+// full path
+func (c SourceConfig) Path(f runtime.Frame) string {
+	return c.file(f, keepPath)
+}
+
+// sourceConfigurer is implemented by the Handlers whose source references can
+// be configured with WithSourceConfig
+type sourceConfigurer interface {
+	withSourceConfig(conf SourceConfig) Handler
+}
+
+// WithSourceConfig creates a copy of the Handler `h`, rendering the file paths
+// in its source references as configured by the SourceConfig `conf`. Returns
+// nil if the Handler is not a Console, ECS, GCP, Datadog or Template handler
+func WithSourceConfig(h Handler, conf SourceConfig) Handler {
+	if s, ok := h.(sourceConfigurer); ok {
+		return s.withSourceConfig(conf)
+	}
+	return nil
+}
+
+// file returns the file path of the frame `f` as configured, or the one
+// returned by `def` if the SourceConfig is not set
+func (c SourceConfig) file(f runtime.Frame, def func(string) string) string {
+	if !c.Relative && c.Elements <= 0 {
+		return def(f.File)
+	}
+
+	p := filepath.ToSlash(f.File)
+	if c.Relative {
+		p = moduleRelative(f)
+	}
+	if c.Elements > 0 {
+		p = lastElements(p, c.Elements)
+	}
+	return p
+}
+
+// keepPath is the default file path of the source references, kept as-is
+func keepPath(file string) string {
+	return file
+}
+
+// buildModules returns the paths of the modules in the binary's build info
+var buildModules = sync.OnceValue(func() []string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	mods := make([]string, 0, len(info.Deps)+1)
+	if info.Main.Path != "" {
+		mods = append(mods, info.Main.Path)
+	}
+	for _, dep := range info.Deps {
+		mods = append(mods, dep.Path)
+	}
+	return mods
+})
+
+// moduleRelative returns the file path of the frame `f` relative to the root
+// of its module, which is found by matching the package of its function with
+// the modules in the build info
+func moduleRelative(f runtime.Frame) string {
+	pkg := funcPackage(f.Function)
+
+	var mod string
+	for _, m := range buildModules() {
+		if (pkg == m || strings.HasPrefix(pkg, m+"/")) && len(m) > len(mod) {
+			mod = m
+		}
+	}
+
+	if mod == "" {
+		return lastElements(filepath.ToSlash(f.File), 2)
+	}
+	dir := strings.TrimPrefix(strings.TrimPrefix(pkg, mod), "/")
+	return path.Join(dir, filepath.Base(f.File))
+}
+
+// funcPackage returns the import path of the package of the fully qualified
+// function name `fn`, like `github.com/zalgonoise/logx/handlers` for
+// `github.com/zalgonoise/logx/handlers.(*Async).Close`
+func funcPackage(fn string) string {
+	slash := strings.LastIndexByte(fn, '/')
+	if dot := strings.IndexByte(fn[slash+1:], '.'); dot >= 0 {
+		return fn[:slash+1+dot]
+	}
+	return fn
+}
+
+// lastElements returns the last `n` elements of the slash-separated path `p`
+func lastElements(p string, n int) string {
+	idx := len(p)
+	for ; n > 0; n-- {
+		idx = strings.LastIndexByte(p[:idx], '/')
+		if idx < 0 {
+			return p
+		}
+	}
+	return p[idx+1:]
+}
+
+// frameCache holds the frames resolved for each program counter, so that the
+// hot log sites resolve theirs once, rather than with every record. It is
+// bounded by the number of call sites in the binary
+var frameCache sync.Map
+
+// framesFor returns the frames for the program counter `pc`, as returned by
+// runtime.Callers, which are more than one if calls were inlined at it
+func framesFor(pc uintptr) []runtime.Frame {
+	if fs, ok := frameCache.Load(pc); ok {
+		return fs.([]runtime.Frame)
+	}
+
+	var (
+		fs     []runtime.Frame
+		frames = runtime.CallersFrames([]uintptr{pc})
+	)
+	for {
+		f, more := frames.Next()
+		fs = append(fs, f)
+		if !more {
+			break
+		}
+	}
+
+	frameCache.Store(pc, fs)
+	return fs
+}
+
+// caller returns the frame of the first caller outside of this module, or
+// within its tests
+func caller() (runtime.Frame, bool) {
+	var pcs [32]uintptr
+	for _, pc := range pcs[:runtime.Callers(3, pcs[:])] {
+		for _, f := range framesFor(pc) {
+			if !strings.HasPrefix(f.Function, modulePath) || strings.HasSuffix(f.File, "_test.go") {
+				return f, f.File != ""
+			}
+		}
+	}
+	return runtime.Frame{}, false
+}
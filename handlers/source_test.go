@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestSourceConfig(t *testing.T) {
+	pc, file, line, _ := runtime.Caller(0)
+	f := runtime.Frame{PC: pc, File: file, Line: line, Function: runtime.FuncForPC(pc).Name()}
+
+	for _, test := range []struct {
+		name  string
+		conf  SourceConfig
+		wants string
+	}{
+		{"Default", SourceConfig{}, file},
+		{"Relative", SourceConfig{Relative: true}, "handlers/source_test.go"},
+		{"Elements", SourceConfig{Elements: 1}, "source_test.go"},
+		{"RelativeElements", SourceConfig{Relative: true, Elements: 5}, "handlers/source_test.go"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.conf.file(f, keepPath); got != test.wants {
+				t.Errorf("output mismatch error: wanted %s ; got %s", test.wants, got)
+			}
+		})
+	}
+
+	t.Run("UnknownModule", func(t *testing.T) {
+		f := runtime.Frame{File: "/home/gopher/app/cmd/server/main.go", Function: "main.main"}
+		if got := (SourceConfig{Relative: true}).file(f, keepPath); got != "server/main.go" {
+			t.Errorf("output mismatch error: wanted %s ; got %s", "server/main.go", got)
+		}
+	})
+	t.Run("FuncPackage", func(t *testing.T) {
+		for fn, wants := range map[string]string{
+			"github.com/zalgonoise/logx/handlers.(*Async).Close": "github.com/zalgonoise/logx/handlers",
+			"github.com/zalgonoise/logx.New.func1":               "github.com/zalgonoise/logx",
+			"main.main":                                          "main",
+		} {
+			if got := funcPackage(fn); got != wants {
+				t.Errorf("output mismatch error: wanted %s ; got %s", wants, got)
+			}
+		}
+	})
+	t.Run("Handler", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := WithSourceConfig(ECS(buf).WithSource(true), SourceConfig{Relative: true})
+
+		if err := h.Handle(records.New(time.Now(), level.Info, "msg")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		var out map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if got := out["log.origin.file.name"]; got != "handlers/source_test.go" {
+			t.Errorf("output mismatch error: wanted %s ; got %v", "handlers/source_test.go", got)
+		}
+	})
+	t.Run("Unsupported", func(t *testing.T) {
+		if h := WithSourceConfig(newTestHandler(), SourceConfig{}); h != nil {
+			t.Errorf("expected output to be nil")
+		}
+	})
+}
+
+func TestCaller(t *testing.T) {
+	_, file, line, _ := runtime.Caller(0)
+	for i := 0; i < 2; i++ {
+		// the second call resolves the frames from the cache
+		f, ok := Caller()
+		if !ok || f.File != file || f.Line != line+3 {
+			t.Errorf("output mismatch error: wanted %s:%d ; got %s:%d", file, line+3, f.File, f.Line)
+		}
+	}
+}
+
+func BenchmarkCaller(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = Caller()
+	}
+}
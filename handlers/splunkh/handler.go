@@ -0,0 +1,604 @@
+//go:build !logx_tiny
+
+// Package splunkh provides a batching handler that sends records to the
+// Splunk HTTP Event Collector (HEC):
+//
+//	h, err := splunkh.New(splunkh.Config{
+//		Endpoint:   "https://splunk.example.com:8088",
+//		Token:      hecToken,
+//		Index:      "app",
+//		SourceType: "logx",
+//		Ack:        true,
+//	})
+//
+// Records are sent in batches of JSON events to the `/services/collector/event`
+// endpoint, each event holding the record's `message`, `level`, `source` (if
+// enabled with WithSource) and attributes.
+//
+// With indexer acknowledgment enabled on the token, Config.Ack sends the
+// batches on a request channel, and polls the collector for their
+// acknowledgment in the background. Batches that are not acknowledged in time
+// are sent again, so they are delivered at least once
+package splunkh
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	defaultSourceType    = "_json"
+	defaultBatchInterval = time.Second
+	defaultBatchSize     = 1 << 20 // 1 MiB
+	defaultAckInterval   = time.Second
+	defaultAckTimeout    = time.Minute
+	defaultMaxPending    = 64
+
+	eventPath     = "/services/collector/event"
+	ackPath       = "/services/collector/ack"
+	healthPath    = "/services/collector/health"
+	maxReplyBytes = 64 << 10
+)
+
+var (
+	// ErrNoToken is raised when creating a handler without a HEC token
+	ErrNoToken error = errors.New("no HEC token provided")
+	// ErrAckPending is raised when sending a batch while the maximum number of
+	// batches are waiting to be acknowledged
+	ErrAckPending error = errors.New("too many batches pending acknowledgment")
+	// ErrUnacknowledged is raised on Shutdown if batches are still not
+	// acknowledged once its context is done
+	ErrUnacknowledged error = errors.New("batches not acknowledged")
+)
+
+// Config describes the HTTP Event Collector that records are sent to, and the
+// metadata of their events
+type Config struct {
+	// Endpoint is the base URL of the collector, like
+	// `https://splunk.example.com:8088`
+	Endpoint string
+	// Token is the HEC token authenticating the requests
+	Token string
+	// HTTP configures the requests, like their TLS settings and compression
+	HTTP handlers.HTTPConfig
+
+	// Index is the index that events are stored in, or the token's default
+	// index if empty
+	Index string
+	// SourceType is the events' sourcetype, `_json` by default
+	SourceType string
+	// Source is the events' source, or the token's default source if empty
+	Source string
+	// Host is the events' host, the machine's hostname by default
+	Host string
+
+	// BatchInterval is how often batches are sent, one second by default
+	BatchInterval time.Duration
+	// BatchSize is the size (in bytes) from which a batch is sent before its
+	// interval, 1 MiB by default
+	BatchSize int
+
+	// Ack enables indexer acknowledgment, which must be enabled on the token
+	// as well
+	Ack bool
+	// Channel is the ID of the request channel (a GUID) that batches are sent
+	// on with Ack, a random one by default
+	Channel string
+	// AckInterval is how often the collector is polled for acknowledgments,
+	// one second by default
+	AckInterval time.Duration
+	// AckTimeout is how long a batch is waited on before it is sent again, one
+	// minute by default
+	AckTimeout time.Duration
+	// MaxPending is the maximum number of batches waiting to be acknowledged,
+	// 64 by default. Once reached, new batches are held back (see
+	// handlers.AckedBatch) until earlier ones are acknowledged
+	MaxPending int
+}
+
+type splunkHandler struct {
+	w         io.Writer
+	meta      []byte
+	addSource bool
+	levelRef  level.Leveler
+	replFn    func(a attr.Attr) attr.Attr
+	attrs     []attr.Attr
+}
+
+// New creates a handler that sends records to the HTTP Event Collector
+// described by the Config `conf`, in batches. Failed batches are retried on the
+// following intervals (see handlers.AckedBatch), and any buffered records are
+// sent on Shutdown, which also waits for the pending acknowledgments with Ack
+func New(conf Config) (handlers.Handler, error) {
+	if conf.Token == "" {
+		return nil, ErrNoToken
+	}
+	if conf.SourceType == "" {
+		conf.SourceType = defaultSourceType
+	}
+	if conf.Host == "" {
+		conf.Host, _ = os.Hostname()
+	}
+
+	w, err := newWriter(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := conf.BatchInterval
+	if interval <= 0 {
+		interval = defaultBatchInterval
+	}
+	size := conf.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	return splunkHandler{
+		w:    handlers.AckedBatch(w, interval, size),
+		meta: metadata(conf),
+	}, nil
+}
+
+// metadata returns the JSON fields of the events' metadata, as set in the
+// Config `conf`
+func metadata(conf Config) []byte {
+	var meta []byte
+	for _, field := range [...]struct{ key, value string }{
+		{"host", conf.Host},
+		{"source", conf.Source},
+		{"sourcetype", conf.SourceType},
+		{"index", conf.Index},
+	} {
+		if field.value == "" {
+			continue
+		}
+		value, _ := json.Marshal(field.value)
+		meta = append(meta, `,"`+field.key+`":`...)
+		meta = append(meta, value...)
+	}
+	return meta
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h splunkHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	lv := r.Level()
+	if lv == nil {
+		lv = level.Info
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	// the event time is in seconds, with a millisecond precision
+	b.WriteString(`{"time":`)
+	b.WriteString(strconv.FormatFloat(float64(r.Time().UnixMilli())/1000, 'f', 3, 64))
+	_, _ = b.Write(h.meta)
+
+	b.WriteString(`,"event":{"message":`)
+	b.WriteJSON(r.Message())
+	b.WriteString(`,"level":`)
+	b.WriteJSON(lv.String())
+
+	if h.addSource {
+		if f, ok := handlers.Source(r); ok {
+			b.WriteString(`,"source":`)
+			b.WriteJSON(f.File + ":" + strconv.Itoa(f.Line))
+		}
+	}
+
+	// the attributes are spliced into the event object
+	if attrs := handlers.AttrsJSON(h.replFn, r.Attrs(), h.attrs); len(attrs) > 2 {
+		b.WriteByte(',')
+		b.WriteString(attrs[1 : len(attrs)-1])
+	}
+	b.WriteString("}}\n")
+
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+// pendingBatch is a batch waiting to be acknowledged
+type pendingBatch struct {
+	body []byte
+	sent time.Time
+}
+
+// writer sends the batches of events to the collector, and tracks their
+// acknowledgment
+type writer struct {
+	eventURL  string
+	ackURL    string
+	healthURL string
+	client    *http.Client
+	header    http.Header
+
+	ack         bool
+	ackInterval time.Duration
+	ackTimeout  time.Duration
+	maxPending  int
+
+	mu      sync.Mutex
+	pending map[int64]pendingBatch
+	done    chan struct{}
+	closed  bool
+	wg      sync.WaitGroup
+
+	now func() time.Time
+}
+
+func newWriter(conf Config) (*writer, error) {
+	base, err := url.ParseRequestURI(strings.TrimRight(conf.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := conf.HTTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	header := conf.HTTP.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Authorization", "Splunk "+conf.Token)
+
+	w := &writer{
+		eventURL:  base.String() + eventPath,
+		healthURL: base.String() + healthPath,
+		client:    client,
+		header:    header,
+		ack:       conf.Ack,
+		now:       time.Now,
+	}
+	if !conf.Ack {
+		return w, nil
+	}
+
+	channel := conf.Channel
+	if channel == "" {
+		if channel, err = newChannel(); err != nil {
+			return nil, err
+		}
+	}
+	header.Set("X-Splunk-Request-Channel", channel)
+	w.ackURL = base.String() + ackPath + "?channel=" + url.QueryEscape(channel)
+
+	w.ackInterval = conf.AckInterval
+	if w.ackInterval <= 0 {
+		w.ackInterval = defaultAckInterval
+	}
+	w.ackTimeout = conf.AckTimeout
+	if w.ackTimeout <= 0 {
+		w.ackTimeout = defaultAckTimeout
+	}
+	w.maxPending = conf.MaxPending
+	if w.maxPending <= 0 {
+		w.maxPending = defaultMaxPending
+	}
+	w.pending = make(map[int64]pendingBatch)
+	w.done = make(chan struct{})
+
+	w.wg.Add(1)
+	go w.run()
+	return w, nil
+}
+
+// newChannel returns a random (version 4) GUID
+func newChannel() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	s := hex.EncodeToString(id)
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:], nil
+}
+
+// Write implements io.Writer, sending the events in `p` in a request
+func (w *writer) Write(p []byte) (int, error) {
+	return w.WriteContext(context.Background(), p)
+}
+
+// WriteContext implements handlers.ContextWriter, sending the events in `p` in
+// a request bound to the context `ctx`. With Ack, the batch is then tracked
+// until it is acknowledged
+func (w *writer) WriteContext(ctx context.Context, p []byte) (int, error) {
+	if !w.ack {
+		if _, err := w.send(ctx, p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) >= w.maxPending {
+		return 0, fmt.Errorf("%w: %d batches", ErrAckPending, len(w.pending))
+	}
+
+	body := append([]byte(nil), p...)
+	id, err := w.send(ctx, body)
+	if err != nil {
+		return 0, err
+	}
+	w.pending[id] = pendingBatch{body: body, sent: w.now()}
+	return len(p), nil
+}
+
+// Sync implements handlers.WriteSyncer, as a no-op since batches are
+// acknowledged in the background
+func (w *writer) Sync() error {
+	return nil
+}
+
+// send posts the events in `body` to the collector, returning the batch's
+// acknowledgment ID
+func (w *writer) send(ctx context.Context, body []byte) (int64, error) {
+	var reply struct {
+		AckID int64 `json:"ackId"`
+	}
+	if err := w.do(ctx, http.MethodPost, w.eventURL, body, &reply); err != nil {
+		return 0, err
+	}
+	return reply.AckID, nil
+}
+
+// do sends a request to the collector, decoding its JSON reply into `reply`
+// if not nil
+func (w *writer) do(ctx context.Context, method, target string, body []byte, reply any) error {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range w.header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxReplyBytes))
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var status struct {
+			Text string `json:"text"`
+		}
+		if json.Unmarshal(data, &status) == nil && status.Text != "" {
+			return fmt.Errorf("%w: %s: %s", handlers.ErrHTTPStatus, strings.TrimSpace(res.Status), status.Text)
+		}
+		return fmt.Errorf("%w: %s", handlers.ErrHTTPStatus, strings.TrimSpace(res.Status))
+	}
+
+	if reply == nil {
+		return nil
+	}
+	return json.Unmarshal(data, reply)
+}
+
+// run polls the collector for acknowledgments, until the writer is shut down
+func (w *writer) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.ackInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), w.ackInterval)
+			_ = w.poll(ctx)
+			cancel()
+		}
+	}
+}
+
+// poll queries the acknowledgment of the pending batches, dropping the
+// acknowledged ones and sending again the ones past the ack timeout
+func (w *writer) poll(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(w.pending))
+	for id := range w.pending {
+		ids = append(ids, id)
+	}
+	query, err := json.Marshal(map[string][]int64{"acks": ids})
+	if err != nil {
+		return err
+	}
+
+	var reply struct {
+		Acks map[string]bool `json:"acks"`
+	}
+	if err := w.do(ctx, http.MethodPost, w.ackURL, query, &reply); err != nil {
+		return err
+	}
+
+	now := w.now()
+	for _, id := range ids {
+		if reply.Acks[strconv.FormatInt(id, 10)] {
+			delete(w.pending, id)
+			continue
+		}
+
+		batch := w.pending[id]
+		if now.Sub(batch.sent) < w.ackTimeout {
+			continue
+		}
+		newID, err := w.send(ctx, batch.body)
+		if err != nil {
+			return err
+		}
+		delete(w.pending, id)
+		w.pending[newID] = pendingBatch{body: batch.body, sent: now}
+	}
+	return nil
+}
+
+// Ping implements handlers.Pinger, checking the collector's health endpoint
+func (w *writer) Ping(ctx context.Context) error {
+	return w.do(ctx, http.MethodGet, w.healthURL, nil, nil)
+}
+
+// Shutdown implements handlers.Shutdowner, waiting for the pending batches to
+// be acknowledged until the context `ctx` is done, and closing the idle
+// connections
+func (w *writer) Shutdown(ctx context.Context) error {
+	defer w.client.CloseIdleConnections()
+
+	if !w.ack {
+		return nil
+	}
+
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.done)
+	}
+	w.mu.Unlock()
+	w.wg.Wait()
+
+	ticker := time.NewTicker(w.ackInterval)
+	defer ticker.Stop()
+
+	for {
+		err := w.poll(ctx)
+
+		w.mu.Lock()
+		n := len(w.pending)
+		w.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+			}
+			return fmt.Errorf("%w: %d batches: %w", ErrUnacknowledged, n, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Capabilities implements handlers.Capable, returning CapGroups and CapBinary,
+// along with CapFlush and CapRemote as records are sent in batches
+func (h splunkHandler) Capabilities() handlers.Capability {
+	return handlers.CapGroups | handlers.CapBinary | handlers.CapFlush | handlers.CapRemote
+}
+
+// Ping implements handlers.Pinger, checking the health of the batch writer
+func (h splunkHandler) Ping(ctx context.Context) error {
+	return handlers.Ping(ctx, h.w)
+}
+
+// Shutdown implements handlers.Shutdowner, sending any buffered records
+func (h splunkHandler) Shutdown(ctx context.Context) error {
+	return handlers.Shutdown(ctx, h.w)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h splunkHandler) With(attrs ...attr.Attr) handlers.Handler {
+	return splunkHandler{
+		w:         h.w,
+		meta:      h.meta,
+		addSource: h.addSource,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     attrs,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h splunkHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h splunkHandler) WithSource(addSource bool) handlers.Handler {
+	return splunkHandler{
+		w:         h.w,
+		meta:      h.meta,
+		addSource: addSource,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h splunkHandler) WithLevel(level level.Leveler) handlers.Handler {
+	return splunkHandler{
+		w:         h.w,
+		meta:      h.meta,
+		addSource: h.addSource,
+		levelRef:  level,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h splunkHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) handlers.Handler {
+	return splunkHandler{
+		w:         h.w,
+		meta:      h.meta,
+		addSource: h.addSource,
+		levelRef:  h.levelRef,
+		replFn:    fn,
+		attrs:     h.attrs,
+	}
+}
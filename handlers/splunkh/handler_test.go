@@ -0,0 +1,223 @@
+//go:build !logx_tiny
+
+package splunkh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// collector is a fake HTTP Event Collector recording the batches it receives
+type collector struct {
+	mu      sync.Mutex
+	batches [][]byte
+	header  http.Header
+	acked   bool
+	nextID  int64
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Splunk token" {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"text":"Invalid token","code":4}`))
+		return
+	}
+
+	switch r.URL.Path {
+	case eventPath:
+		c.batches = append(c.batches, body)
+		c.header = r.Header
+		_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":` + strconv.FormatInt(c.nextID, 10) + `}`))
+		c.nextID++
+	case ackPath:
+		var query struct {
+			Acks []int64 `json:"acks"`
+		}
+		_ = json.Unmarshal(body, &query)
+		acks := map[string]bool{}
+		for _, id := range query.Acks {
+			acks[strconv.FormatInt(id, 10)] = c.acked
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"acks": acks})
+	case healthPath:
+		_, _ = w.Write([]byte(`{"text":"HEC is healthy","code":17}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (c *collector) received() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]byte(nil), c.batches...)
+}
+
+func (c *collector) setAcked(acked bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.acked = acked
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("Events", func(t *testing.T) {
+		c := &collector{}
+		srv := httptest.NewServer(c)
+		defer srv.Close()
+
+		h, err := New(Config{
+			Endpoint:      srv.URL,
+			Token:         "token",
+			Index:         "app",
+			Host:          "web-01",
+			BatchInterval: time.Hour,
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		h = h.With(attr.String("service", "api"))
+
+		for _, msg := range []string{"started", "served"} {
+			r := records.New(time.UnixMilli(1500), level.Warn, msg, attr.Int("status", 200))
+			if err := h.Handle(r); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+		}
+		if err := handlers.Shutdown(context.Background(), h); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		batches := c.received()
+		if len(batches) != 1 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 1, len(batches))
+			return
+		}
+		lines := bytes.Split(bytes.TrimSpace(batches[0]), []byte{'\n'})
+		if len(lines) != 2 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 2, len(lines))
+			return
+		}
+
+		wants := `{"time":1.500,"host":"web-01","sourcetype":"_json","index":"app",` +
+			`"event":{"message":"served","level":"warn","status":200,"service":"api"}}`
+		if string(lines[1]) != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, lines[1])
+		}
+		if c.header.Get("X-Splunk-Request-Channel") != "" {
+			t.Errorf("unexpected request channel without acknowledgment")
+		}
+	})
+	t.Run("Ack", func(t *testing.T) {
+		c := &collector{}
+		srv := httptest.NewServer(c)
+		defer srv.Close()
+
+		now := time.Unix(0, 0)
+		w, err := newWriter(Config{Endpoint: srv.URL, Token: "token", Ack: true, AckInterval: time.Hour, MaxPending: 2})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		w.now = func() time.Time { return now }
+		defer w.Shutdown(context.Background())
+
+		for i := 0; i < 2; i++ {
+			if _, err := w.Write([]byte(`{"event":"x"}`)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+		}
+		if _, err := w.Write([]byte(`{"event":"x"}`)); !errors.Is(err, ErrAckPending) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrAckPending, err)
+		}
+		if channel := c.header.Get("X-Splunk-Request-Channel"); len(channel) != 36 {
+			t.Errorf("output mismatch error: got channel %q", channel)
+		}
+
+		// batches past the ack timeout are sent again
+		now = now.Add(defaultAckTimeout)
+		if err := w.poll(context.Background()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if n := len(c.received()); n != 4 || len(w.pending) != 2 {
+			t.Errorf("output mismatch error: got %d batches sent and %d pending", n, len(w.pending))
+		}
+
+		c.setAcked(true)
+		if err := w.poll(context.Background()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(w.pending) != 0 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 0, len(w.pending))
+		}
+	})
+	t.Run("Unacknowledged", func(t *testing.T) {
+		c := &collector{}
+		srv := httptest.NewServer(c)
+		defer srv.Close()
+
+		w, _ := newWriter(Config{Endpoint: srv.URL, Token: "token", Ack: true, AckInterval: 10 * time.Millisecond})
+		if _, err := w.Write([]byte(`{"event":"x"}`)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := w.Shutdown(ctx); !errors.Is(err, ErrUnacknowledged) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrUnacknowledged, err)
+		}
+	})
+	t.Run("InvalidToken", func(t *testing.T) {
+		c := &collector{}
+		srv := httptest.NewServer(c)
+		defer srv.Close()
+
+		w, _ := newWriter(Config{Endpoint: srv.URL, Token: "wrong"})
+		if _, err := w.Write([]byte(`{"event":"x"}`)); !errors.Is(err, handlers.ErrHTTPStatus) ||
+			!strings.Contains(err.Error(), "Invalid token") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("Ping", func(t *testing.T) {
+		c := &collector{}
+		srv := httptest.NewServer(c)
+		defer srv.Close()
+
+		h, _ := New(Config{Endpoint: srv.URL, Token: "token"})
+		defer handlers.Shutdown(context.Background(), h)
+
+		if err := handlers.Ping(context.Background(), h); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("NoToken", func(t *testing.T) {
+		if _, err := New(Config{Endpoint: "https://splunk:8088"}); !errors.Is(err, ErrNoToken) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrNoToken, err)
+		}
+	})
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	spoolFile          = "spool.log"
+	spoolHeaderSize    = 12 // 8-byte timestamp and 4-byte length
+	spoolCopySize      = 32 << 10
+	defaultSpoolRetry  = 5 * time.Second
+	defaultSpoolMaxLen = 64 << 20 // 64 MiB
+)
+
+// ErrSpoolFull is raised when a write cannot be spooled, as the spool has
+// reached its maximum size
+var ErrSpoolFull error = errors.New("spool is full")
+
+// SpoolConfig configures the limits of a Spool writer
+type SpoolConfig struct {
+	// MaxBytes is the maximum size of the spool file, defaulting to 64 MiB.
+	// Writes that do not fit are dropped, with ErrSpoolFull
+	MaxBytes int64
+	// MaxAge is the age after which spooled writes are discarded instead of
+	// sent, if greater than zero
+	MaxAge time.Duration
+	// RetryInterval is the interval between attempts to drain the spool into
+	// the sink, defaulting to 5 seconds
+	RetryInterval time.Duration
+}
+
+type spoolWriter struct {
+	mu     sync.Mutex
+	ws     WriteSyncer
+	f      *os.File
+	conf   SpoolConfig
+	offset int64 // position of the first entry not yet drained
+	size   int64
+	closed bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Spool creates a WriteSyncCloser that writes to `w`, and that spools the
+// writes to a file in the directory `dir` while `w` is unavailable (its Write
+// calls fail). Spooled writes are sent to `w` in their original order, every
+// retry interval, once it recovers; new writes are spooled behind them in the
+// meantime.
+//
+// This keeps the records encoded by a handler (like jsonh) when a remote sink
+// or collector is down, up to the limits in the SpoolConfig `conf`. Writes
+// left in the spool when the process exits are drained by the next Spool on
+// the same directory, so delivery is at-least-once.
+//
+// Closing the writer attempts a final drain, and does not close `w`
+func Spool(w io.Writer, dir string, conf SpoolConfig) (WriteSyncCloser, error) {
+	if conf.MaxBytes <= 0 {
+		conf.MaxBytes = defaultSpoolMaxLen
+	}
+	if conf.RetryInterval <= 0 {
+		conf.RetryInterval = defaultSpoolRetry
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, spoolFile), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	s := &spoolWriter{
+		ws:   AddSync(w),
+		f:    f,
+		conf: conf,
+		size: info.Size(),
+		done: make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// Write implements io.Writer, writing `p` to the sink if nothing is spooled,
+// or spooling it otherwise (and if the sink fails)
+func (s *spoolWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, os.ErrClosed
+	}
+
+	if s.pending() == 0 {
+		if n, err := s.ws.Write(p); err == nil {
+			return n, nil
+		}
+	}
+
+	if err := s.spool(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync implements WriteSyncer, attempting to drain the spool and syncing the
+// sink and the spool file
+func (s *spoolWriter) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.drain(context.Background()); err != nil {
+		return err
+	}
+	if err := s.ws.Sync(); err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+// Close implements io.Closer, stopping the background drain and attempting a
+// final one. Writes that cannot be sent are kept in the spool file
+func (s *spoolWriter) Close() error {
+	return s.close(context.Background())
+}
+
+func (s *spoolWriter) close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.drain(ctx)
+	if closeErr := s.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Ping implements Pinger, checking the health of the sink
+func (s *spoolWriter) Ping(ctx context.Context) error {
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+
+	if closed {
+		return os.ErrClosed
+	}
+	return Ping(ctx, s.ws)
+}
+
+// Pressure implements Pressurer, returning the fill ratio of the spool
+func (s *spoolWriter) Pressure() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return ratio(s.pending(), s.conf.MaxBytes)
+}
+
+// Capabilities implements Capable, returning CapFlush (as writes are spooled)
+// along with the capabilities of the underlying writer
+func (s *spoolWriter) Capabilities() Capability {
+	return EncoderCapabilities(CapFlush, s.ws)
+}
+
+// Shutdown implements Shutdowner, closing the writer and shutting down the
+// sink
+func (s *spoolWriter) Shutdown(ctx context.Context) error {
+	if err := s.close(ctx); err != nil {
+		return err
+	}
+	return Shutdown(ctx, s.ws)
+}
+
+func (s *spoolWriter) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.conf.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			_ = s.drain(context.Background())
+			s.mu.Unlock()
+		}
+	}
+}
+
+// pending returns the size of the entries not yet drained
+func (s *spoolWriter) pending() int64 {
+	return s.size - s.offset
+}
+
+// spool appends `p` to the spool file, framed with its timestamp and length
+func (s *spoolWriter) spool(p []byte) error {
+	if s.pending()+spoolHeaderSize+int64(len(p)) > s.conf.MaxBytes {
+		return ErrSpoolFull
+	}
+
+	entry := make([]byte, spoolHeaderSize, spoolHeaderSize+len(p))
+	binary.BigEndian.PutUint64(entry[:8], uint64(time.Now().UnixNano()))
+	binary.BigEndian.PutUint32(entry[8:], uint32(len(p)))
+	entry = append(entry, p...)
+
+	n, err := s.f.WriteAt(entry, s.size)
+	s.size += int64(n)
+	return err
+}
+
+// drain sends the spooled entries to the sink, in order, until one fails or
+// the context `ctx` is done. Entries older than the maximum age are
+// discarded. Once empty, the spool file is truncated; otherwise, it is
+// compacted to reclaim the space of the entries sent
+func (s *spoolWriter) drain(ctx context.Context) error {
+	if s.pending() == 0 {
+		return nil
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(s.f, s.offset, s.pending()))
+	header := make([]byte, spoolHeaderSize)
+
+	for s.pending() > 0 {
+		if _, err := io.ReadFull(r, header); err != nil {
+			// a partially written entry; discard the rest of the spool
+			s.offset = s.size
+			break
+		}
+
+		ts := time.Unix(0, int64(binary.BigEndian.Uint64(header[:8])))
+		length := int64(binary.BigEndian.Uint32(header[8:]))
+		if length > s.pending()-spoolHeaderSize {
+			// a length past the end of the spool is a torn (or corrupted) tail
+			s.offset = s.size
+			break
+		}
+
+		p := make([]byte, length)
+		if _, err := io.ReadFull(r, p); err != nil {
+			s.offset = s.size
+			break
+		}
+
+		if s.conf.MaxAge <= 0 || time.Since(ts) <= s.conf.MaxAge {
+			if _, err := WriteContext(ctx, s.ws, p); err != nil {
+				return errors.Join(err, s.compact())
+			}
+		}
+		s.offset += spoolHeaderSize + int64(len(p))
+	}
+
+	if err := s.f.Truncate(0); err != nil {
+		return err
+	}
+	s.offset, s.size = 0, 0
+	return nil
+}
+
+// compact moves the entries not yet drained to the start of the spool file,
+// reclaiming the space of the ones already sent
+func (s *spoolWriter) compact() error {
+	if s.offset == 0 {
+		return nil
+	}
+
+	var (
+		pending = s.pending()
+		buf     = make([]byte, min(pending, spoolCopySize))
+	)
+	for n := int64(0); n < pending; {
+		chunk := buf[:min(pending-n, int64(len(buf)))]
+		if m, err := s.f.ReadAt(chunk, s.offset+n); m < len(chunk) {
+			return err
+		}
+		if _, err := s.f.WriteAt(chunk, n); err != nil {
+			return err
+		}
+		n += int64(len(chunk))
+	}
+
+	if err := s.f.Truncate(pending); err != nil {
+		return err
+	}
+	s.offset, s.size = 0, pending
+	return nil
+}
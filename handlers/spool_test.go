@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var errFlaky = errors.New("sink went down")
+
+func TestSpool(t *testing.T) {
+	errDown := errors.New("sink is down")
+
+	t.Run("SpoolAndDrain", func(t *testing.T) {
+		w := &countingWriter{err: errDown}
+		s, err := Spool(w, t.TempDir(), SpoolConfig{RetryInterval: time.Hour})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		defer s.Close()
+
+		for _, line := range []string{"a\n", "b\n"} {
+			if _, err := s.Write([]byte(line)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+		if err := s.Sync(); !errors.Is(err, errDown) {
+			t.Errorf("unexpected error: wanted %v ; got %v", errDown, err)
+		}
+
+		w.mu.Lock()
+		w.err = nil
+		w.mu.Unlock()
+
+		// new writes queue behind the spooled ones
+		_, _ = s.Write([]byte("c\n"))
+		if out, _ := w.state(); out != "" {
+			t.Errorf("output mismatch error: wanted no output before draining ; got %q", out)
+		}
+
+		if err := s.Sync(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if out, _ := w.state(); out != "a\nb\nc\n" {
+			t.Errorf("output mismatch error: wanted %q ; got %q", "a\nb\nc\n", out)
+		}
+
+		// with an empty spool, writes go straight to the sink
+		_, _ = s.Write([]byte("d\n"))
+		if out, _ := w.state(); out != "a\nb\nc\nd\n" {
+			t.Errorf("output mismatch error: wanted %q ; got %q", "a\nb\nc\nd\n", out)
+		}
+	})
+	t.Run("BackgroundDrain", func(t *testing.T) {
+		w := &countingWriter{err: errDown}
+		s, _ := Spool(w, t.TempDir(), SpoolConfig{RetryInterval: time.Millisecond})
+		defer s.Close()
+
+		_, _ = s.Write([]byte("a\n"))
+		w.mu.Lock()
+		w.err = nil
+		w.mu.Unlock()
+
+		deadline := time.Now().Add(time.Second)
+		for out, _ := w.state(); out != "a\n"; out, _ = w.state() {
+			if time.Now().After(deadline) {
+				t.Errorf("output mismatch error: wanted %q ; got %q", "a\n", out)
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+	t.Run("MaxBytes", func(t *testing.T) {
+		s, _ := Spool(&countingWriter{err: errDown}, t.TempDir(), SpoolConfig{MaxBytes: 32, RetryInterval: time.Hour})
+		defer s.Close()
+
+		if _, err := s.Write([]byte("first\n")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if _, err := s.Write([]byte("a line that does not fit\n")); !errors.Is(err, ErrSpoolFull) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrSpoolFull, err)
+		}
+	})
+	t.Run("MaxAge", func(t *testing.T) {
+		w := &countingWriter{err: errDown}
+		s, _ := Spool(w, t.TempDir(), SpoolConfig{MaxAge: time.Millisecond, RetryInterval: time.Hour})
+		defer s.Close()
+
+		_, _ = s.Write([]byte("stale\n"))
+		time.Sleep(5 * time.Millisecond)
+
+		w.mu.Lock()
+		w.err = nil
+		w.mu.Unlock()
+
+		_ = s.Sync()
+		if out, _ := w.state(); out != "" {
+			t.Errorf("output mismatch error: wanted stale writes to be discarded ; got %q", out)
+		}
+	})
+	t.Run("Restart", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s, _ := Spool(&countingWriter{err: errDown}, dir, SpoolConfig{RetryInterval: time.Hour})
+		_, _ = s.Write([]byte("kept\n"))
+		_ = s.Close()
+
+		if info, err := os.Stat(filepath.Join(dir, spoolFile)); err != nil || info.Size() == 0 {
+			t.Errorf("expected the spool file to hold the pending write")
+		}
+
+		w := &countingWriter{}
+		s, _ = Spool(w, dir, SpoolConfig{RetryInterval: time.Hour})
+		if err := s.Close(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if out, _ := w.state(); out != "kept\n" {
+			t.Errorf("output mismatch error: wanted %q ; got %q", "kept\n", out)
+		}
+	})
+	t.Run("PartialDrainCompacts", func(t *testing.T) {
+		dir := t.TempDir()
+		w := &flakyWriter{}
+		w.err = errDown
+		s, _ := Spool(w, dir, SpoolConfig{RetryInterval: time.Hour})
+		defer s.Close()
+
+		for _, line := range []string{"a\n", "b\n"} {
+			_, _ = s.Write([]byte(line))
+		}
+
+		w.mu.Lock()
+		w.err, w.left = nil, 1
+		w.mu.Unlock()
+
+		if err := s.Sync(); !errors.Is(err, errFlaky) {
+			t.Errorf("unexpected error: wanted %v ; got %v", errFlaky, err)
+		}
+		info, err := os.Stat(filepath.Join(dir, spoolFile))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if wants := int64(spoolHeaderSize + len("b\n")); info.Size() != wants {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, info.Size())
+		}
+
+		w.mu.Lock()
+		w.left = -1
+		w.mu.Unlock()
+
+		if err := s.Sync(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if out, _ := w.state(); out != "a\nb\n" {
+			t.Errorf("output mismatch error: wanted %q ; got %q", "a\nb\n", out)
+		}
+	})
+	t.Run("TornLength", func(t *testing.T) {
+		dir := t.TempDir()
+
+		// a header announcing a length past the end of the file
+		entry := make([]byte, spoolHeaderSize, spoolHeaderSize+2)
+		binary.BigEndian.PutUint64(entry[:8], uint64(time.Now().UnixNano()))
+		binary.BigEndian.PutUint32(entry[8:], 1<<31)
+		entry = append(entry, "a\n"...)
+		if err := os.WriteFile(filepath.Join(dir, spoolFile), entry, 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		w := &countingWriter{}
+		s, _ := Spool(w, dir, SpoolConfig{RetryInterval: time.Hour})
+		if err := s.Close(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if out, _ := w.state(); out != "" {
+			t.Errorf("output mismatch error: wanted no output ; got %q", out)
+		}
+		if info, err := os.Stat(filepath.Join(dir, spoolFile)); err != nil || info.Size() != 0 {
+			t.Errorf("expected the torn spool to be discarded")
+		}
+	})
+}
+
+// flakyWriter is a countingWriter that fails once `left` writes succeed, if not
+// negative
+type flakyWriter struct {
+	countingWriter
+	left int
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	if w.err == nil && w.left == 0 {
+		w.mu.Unlock()
+		return 0, errFlaky
+	}
+	if w.left > 0 {
+		w.left--
+	}
+	w.mu.Unlock()
+
+	return w.countingWriter.Write(p)
+}
@@ -0,0 +1,377 @@
+// Package sqliteh provides a handler that writes records into a SQLite
+// database, giving small deployments queryable local logs:
+//
+//	SELECT message, attrs FROM logs WHERE level_value >= 8 ORDER BY time DESC;
+//
+// The handler takes a *sql.DB opened with any SQLite driver (like
+// modernc.org/sqlite or github.com/mattn/go-sqlite3), so this package does not
+// depend on one. Records are stored in a table with the columns:
+//   - `id`: an auto-incremented row ID
+//   - `time`: the record's timestamp, in nanoseconds since the Unix epoch
+//   - `level` and `level_value`: the record's level name and value
+//   - `message`: the record's message
+//   - `source`: the record's source reference, if enabled with WithSource
+//   - `attrs`: the record's attributes, as a JSON object
+//   - one column per attribute key listed in Config.Columns
+//
+// The time, level and attribute columns are indexed
+package sqliteh
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	defaultTable         = "logs"
+	defaultPruneInterval = time.Minute
+)
+
+// ErrNoDB is raised when creating a handler with a nil *sql.DB
+var ErrNoDB error = errors.New("no database provided")
+
+// Config describes the table that records are written to, and how long they
+// are kept
+type Config struct {
+	// Table is the name of the table, `logs` by default. It is created if it
+	// does not exist
+	Table string
+	// Columns lists the keys of the attributes (including the ones bound to
+	// the handler, with With) stored and indexed in their own column, besides
+	// the `attrs` column. Characters other than letters, digits and
+	// underscores are replaced with underscores in the column names, like
+	// `http.status` to `http_status`, and the keys clashing with the built-in
+	// columns are prefixed with `attr_`
+	Columns []string
+	// Retention deletes the records older than this duration, if set
+	Retention time.Duration
+	// MaxRows deletes the oldest records beyond this number of rows, if set
+	MaxRows int
+	// PruneInterval is how often the records beyond the Retention and MaxRows
+	// limits are deleted, one minute by default. Pruning is done by the first
+	// Handle call after it is due
+	PruneInterval time.Duration
+}
+
+// sink holds the state shared by the copies of a handler
+type sink struct {
+	db      *sql.DB
+	conf    Config
+	columns []string
+	insert  *sql.Stmt
+
+	mu        sync.Mutex
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+type sqliteHandler struct {
+	s         *sink
+	addSource bool
+	levelRef  level.Leveler
+	replFn    func(a attr.Attr) attr.Attr
+	attrs     []attr.Attr
+}
+
+// New creates a handler that writes records into the SQLite database `db`, in
+// the table described by the Config `conf`, which is created (along with its
+// indexes) if it does not exist. Records beyond the configured limits are
+// pruned right away, and then periodically.
+//
+// The handler does not close the database on Shutdown, as it is owned by the
+// caller
+func New(db *sql.DB, conf Config) (handlers.Handler, error) {
+	if db == nil {
+		return nil, ErrNoDB
+	}
+	if conf.Table == "" {
+		conf.Table = defaultTable
+	}
+	if conf.PruneInterval <= 0 {
+		conf.PruneInterval = defaultPruneInterval
+	}
+
+	s := &sink{
+		db:      db,
+		conf:    conf,
+		columns: make([]string, len(conf.Columns)),
+		now:     time.Now,
+	}
+	for i, key := range conf.Columns {
+		s.columns[i] = columnName(key)
+	}
+
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+	if err := s.prune(); err != nil {
+		_ = s.insert.Close()
+		return nil, err
+	}
+
+	return sqliteHandler{s: s}, nil
+}
+
+// init creates the table and its indexes, and prepares the insert statement
+func (s *sink) init() error {
+	table := quote(s.conf.Table)
+
+	var cols strings.Builder
+	for _, col := range s.columns {
+		cols.WriteString(", ")
+		cols.WriteString(quote(col))
+	}
+
+	stmts := []string{
+		"CREATE TABLE IF NOT EXISTS " + table + " (" +
+			"id INTEGER PRIMARY KEY AUTOINCREMENT, " +
+			"time INTEGER NOT NULL, " +
+			"level TEXT NOT NULL, " +
+			"level_value INTEGER NOT NULL, " +
+			"message TEXT NOT NULL, " +
+			"source TEXT, " +
+			"attrs TEXT NOT NULL" + cols.String() + ")",
+		s.index("time"),
+		s.index("level_value"),
+	}
+	for _, col := range s.columns {
+		stmts = append(stmts, s.index(col))
+	}
+
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	placeholders := strings.Repeat(", ?", len(s.columns))
+	insert, err := s.db.Prepare("INSERT INTO " + table +
+		" (time, level, level_value, message, source, attrs" + cols.String() + ")" +
+		" VALUES (?, ?, ?, ?, ?, ?" + placeholders + ")")
+	if err != nil {
+		return err
+	}
+	s.insert = insert
+	return nil
+}
+
+func (s *sink) index(col string) string {
+	return "CREATE INDEX IF NOT EXISTS " + quote(s.conf.Table+"_"+col) +
+		" ON " + quote(s.conf.Table) + " (" + quote(col) + ")"
+}
+
+// pruneIfDue deletes the records beyond the configured limits, if the prune
+// interval has elapsed since the last time
+func (s *sink) pruneIfDue() error {
+	if s.conf.Retention <= 0 && s.conf.MaxRows <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	due := s.now().Sub(s.lastPrune) >= s.conf.PruneInterval
+	s.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return s.prune()
+}
+
+func (s *sink) prune() error {
+	if s.conf.Retention <= 0 && s.conf.MaxRows <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.lastPrune = now
+
+	table := quote(s.conf.Table)
+	if s.conf.Retention > 0 {
+		if _, err := s.db.Exec("DELETE FROM "+table+" WHERE time < ?", now.Add(-s.conf.Retention).UnixNano()); err != nil {
+			return err
+		}
+	}
+	if s.conf.MaxRows > 0 {
+		if _, err := s.db.Exec("DELETE FROM "+table+" WHERE id <= "+
+			"(SELECT id FROM "+table+" ORDER BY id DESC LIMIT 1 OFFSET ?)", s.conf.MaxRows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h sqliteHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	lv := r.Level()
+	if lv == nil {
+		lv = level.Info
+	}
+
+	var source any
+	if h.addSource {
+		if f, ok := handlers.Source(r); ok {
+			source = f.File + ":" + strconv.Itoa(f.Line)
+		}
+	}
+
+	args := make([]any, 0, 6+len(h.s.columns))
+	args = append(args,
+		r.Time().UnixNano(),
+		lv.String(),
+		lv.Int(),
+		r.Message(),
+		source,
+		handlers.AttrsJSON(h.replFn, r.Attrs(), h.attrs),
+	)
+	for _, key := range h.s.conf.Columns {
+		args = append(args, h.column(key, r.Attrs()))
+	}
+
+	if _, err := h.s.insert.Exec(args...); err != nil {
+		return err
+	}
+	return h.s.pruneIfDue()
+}
+
+// column returns the value stored in the column for the attribute key `key`,
+// or nil if the record has no such attribute
+func (h sqliteHandler) column(key string, attrs []attr.Attr) any {
+	for _, list := range [2][]attr.Attr{attrs, h.attrs} {
+		for i := len(list) - 1; i >= 0; i-- {
+			a := list[i]
+			if a == nil || a.Key() != key {
+				continue
+			}
+			if h.replFn != nil {
+				if a = h.replFn(a); a == nil {
+					return nil
+				}
+			}
+
+			switch v := a.Value().(type) {
+			case []attr.Attr:
+				return handlers.AttrsJSON(h.replFn, v)
+			case error:
+				return v.Error()
+			case time.Duration:
+				return int64(v)
+			default:
+				if value, err := driver.DefaultParameterConverter.ConvertValue(v); err == nil {
+					return value
+				}
+				return fmt.Sprint(v)
+			}
+		}
+	}
+	return nil
+}
+
+// Ping implements handlers.Pinger, checking the database connection
+func (h sqliteHandler) Ping(ctx context.Context) error {
+	return h.s.db.PingContext(ctx)
+}
+
+// Shutdown implements handlers.Shutdowner, releasing the handler's prepared
+// statement. The database is left open
+func (h sqliteHandler) Shutdown(context.Context) error {
+	return h.s.insert.Close()
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h sqliteHandler) With(attrs ...attr.Attr) handlers.Handler {
+	return sqliteHandler{
+		s:         h.s,
+		addSource: h.addSource,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     attrs,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h sqliteHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h sqliteHandler) WithSource(addSource bool) handlers.Handler {
+	return sqliteHandler{
+		s:         h.s,
+		addSource: addSource,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h sqliteHandler) WithLevel(level level.Leveler) handlers.Handler {
+	return sqliteHandler{
+		s:         h.s,
+		addSource: h.addSource,
+		levelRef:  level,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h sqliteHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) handlers.Handler {
+	return sqliteHandler{
+		s:         h.s,
+		addSource: h.addSource,
+		levelRef:  h.levelRef,
+		replFn:    fn,
+		attrs:     h.attrs,
+	}
+}
+
+// columnName returns the column name for the attribute key `key`, prefixed
+// with `attr_` if it clashes with one of the built-in columns
+func columnName(key string) string {
+	switch key {
+	case "id", "time", "level", "level_value", "message", "source", "attrs":
+		key = "attr_" + key
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
+
+// quote returns the identifier `name` quoted for SQLite
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,204 @@
+package sqliteh
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestNew(t *testing.T) {
+	t.Run("Schema", func(t *testing.T) {
+		db, rec := open(t)
+		if _, err := New(db, Config{Columns: []string{"http.status", "level"}}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		stmts := rec.queries()
+		for _, wants := range []string{
+			`CREATE TABLE IF NOT EXISTS "logs" (`,
+			`attrs TEXT NOT NULL, "http_status", "attr_level")`,
+			`CREATE INDEX IF NOT EXISTS "logs_time" ON "logs" ("time")`,
+			`CREATE INDEX IF NOT EXISTS "logs_http_status" ON "logs" ("http_status")`,
+		} {
+			if !strings.Contains(strings.Join(stmts, "\n"), wants) {
+				t.Errorf("output mismatch error: wanted %s in the statements ; got %v", wants, stmts)
+			}
+		}
+	})
+	t.Run("NoDB", func(t *testing.T) {
+		if _, err := New(nil, Config{}); !errors.Is(err, ErrNoDB) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrNoDB, err)
+		}
+	})
+}
+
+func TestHandle(t *testing.T) {
+	db, rec := open(t)
+	h, err := New(db, Config{Table: "app_logs", Columns: []string{"user_id", "latency"}})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	h = h.With(attr.String("service", "api"), attr.Int("user_id", 7)).WithLevel(level.Info)
+
+	_ = h.Handle(records.New(testTime, level.Debug, "filtered"))
+	err = h.Handle(records.New(testTime, level.Warn, "slow request",
+		attr.New("latency", 1500*time.Millisecond),
+		attr.New("req", []attr.Attr{attr.String("path", "/")}),
+	))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	inserts := rec.execs("INSERT")
+	if len(inserts) != 1 {
+		t.Errorf("output mismatch error: wanted 1 insert ; got %d", len(inserts))
+		return
+	}
+
+	wants := []driver.Value{
+		testTime.UnixNano(), "warn", int64(4), "slow request", nil,
+		`{"latency":1500000000,"req":{"path":"/"},"service":"api","user_id":7}`,
+		int64(7), int64(1500 * time.Millisecond),
+	}
+	if got := inserts[0]; len(got) != len(wants) {
+		t.Errorf("output mismatch error: wanted %v ; got %v", wants, got)
+		return
+	}
+	for i := range wants {
+		if inserts[0][i] != wants[i] {
+			t.Errorf("output mismatch error: arg #%d: wanted %v (%T) ; got %v (%T)", i, wants[i], wants[i], inserts[0][i], inserts[0][i])
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	db, rec := open(t)
+	h, err := New(db, Config{Retention: time.Hour, MaxRows: 100, PruneInterval: time.Minute})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	now := testTime
+	s := h.(sqliteHandler).s
+	s.now = func() time.Time { return now }
+	s.lastPrune = now
+
+	if n := len(rec.execs("DELETE")); n != 2 {
+		t.Errorf("output mismatch error: wanted 2 deletes on creation ; got %d", n)
+	}
+
+	_ = h.Handle(records.New(now, level.Info, "not due"))
+	if n := len(rec.execs("DELETE")); n != 2 {
+		t.Errorf("output mismatch error: wanted no deletes before the interval ; got %d", n-2)
+	}
+
+	now = now.Add(time.Minute)
+	_ = h.Handle(records.New(now, level.Info, "due"))
+
+	deletes := rec.execs("DELETE")
+	if len(deletes) != 4 {
+		t.Errorf("output mismatch error: wanted 2 more deletes ; got %d", len(deletes)-2)
+		return
+	}
+	if cutoff := now.Add(-time.Hour).UnixNano(); deletes[2][0] != cutoff {
+		t.Errorf("output mismatch error: wanted cutoff %v ; got %v", cutoff, deletes[2][0])
+	}
+	if deletes[3][0] != int64(100) {
+		t.Errorf("output mismatch error: wanted %v ; got %v", 100, deletes[3][0])
+	}
+}
+
+// recorder is a database/sql driver that records the executed statements
+type recorder struct {
+	mu    sync.Mutex
+	stmts []string
+	args  [][]driver.Value
+}
+
+var (
+	driverOnce sync.Once
+	recorders  sync.Map
+)
+
+func open(t *testing.T) (*sql.DB, *recorder) {
+	driverOnce.Do(func() { sql.Register("sqliteh_test", testDriver{}) })
+
+	rec := &recorder{}
+	recorders.Store(t.Name(), rec)
+
+	db, err := sql.Open("sqliteh_test", t.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db, rec
+}
+
+func (r *recorder) queries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.stmts...)
+}
+
+func (r *recorder) execs(prefix string) [][]driver.Value {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out [][]driver.Value
+	for i, stmt := range r.stmts {
+		if strings.HasPrefix(stmt, prefix) {
+			out = append(out, r.args[i])
+		}
+	}
+	return out
+}
+
+type testDriver struct{}
+
+func (testDriver) Open(name string) (driver.Conn, error) {
+	rec, _ := recorders.Load(name)
+	return testConn{rec.(*recorder)}, nil
+}
+
+type testConn struct{ rec *recorder }
+
+func (c testConn) Prepare(query string) (driver.Stmt, error) { return testStmt{c.rec, query}, nil }
+func (testConn) Close() error                                { return nil }
+func (testConn) Begin() (driver.Tx, error)                   { return nil, errors.New("not supported") }
+func (testConn) Ping(context.Context) error                  { return nil }
+
+type testStmt struct {
+	rec   *recorder
+	query string
+}
+
+func (testStmt) Close() error  { return nil }
+func (testStmt) NumInput() int { return -1 }
+
+func (s testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.rec.mu.Lock()
+	defer s.rec.mu.Unlock()
+
+	s.rec.stmts = append(s.rec.stmts, s.query)
+	s.rec.args = append(s.rec.args, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (testStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
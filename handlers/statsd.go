@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const defaultStatsDCounter = "log.records"
+
+// StatsDConfig describes the statsd metrics derived from the records handled
+// by a StatsD Handler
+type StatsDConfig struct {
+	// Prefix is prepended to the metrics' names, like `api.`
+	Prefix string
+	// Counter is the name of the counter incremented for each record,
+	// `log.records` by default
+	Counter string
+	// EventKey is the key of the attribute holding the record's event name. If
+	// empty or absent from a record, its message is used. As each event name
+	// is a distinct series, it should be bounded (like the events package's
+	// definitions)
+	EventKey string
+	// DogStatsD tags the counter with the `level` and `event` tags, in the
+	// DogStatsD format. Otherwise, they are appended to the counter's name,
+	// like `log.records.warn.cache_miss`
+	DogStatsD bool
+	// Tags are added to every metric and event, with DogStatsD, like
+	// `env:prod`
+	Tags []string
+	// EventLevel is the level from which records are also sent as DogStatsD
+	// events, if set
+	EventLevel level.Level
+}
+
+type statsdHandler struct {
+	h     Handler
+	w     io.Writer
+	conf  StatsDConfig
+	bound []attr.Attr
+}
+
+// StatsD decorates the Handler `h` so that the records it handles increment a
+// statsd counter written to the io.Writer `w` (like a UDP connection to the
+// statsd agent), by level and event name, as described by the StatsDConfig
+// `conf`:
+//
+//	conn, err := net.Dial("udp", "127.0.0.1:8125")
+//	// (...)
+//	h := handlers.StatsD(jsonh.New(os.Stderr), conn, handlers.StatsDConfig{
+//		Prefix:     "api.",
+//		DogStatsD:  true,
+//		EventLevel: level.Error,
+//	})
+//
+// Each metric is a separate write, so each one is a datagram over UDP. Errors
+// writing the metrics are ignored, as they must not prevent the record from
+// being handled
+func StatsD(h Handler, w io.Writer, conf StatsDConfig) Handler {
+	if h == nil {
+		return nil
+	}
+	if w == nil {
+		return h
+	}
+	if conf.Counter == "" {
+		conf.Counter = defaultStatsDCounter
+	}
+
+	return statsdHandler{
+		h:    h,
+		w:    w,
+		conf: conf,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (s statsdHandler) Enabled(level level.Level) bool {
+	return s.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (s statsdHandler) Handle(r records.Record) error {
+	if !s.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	lv := r.Level()
+	if lv == nil {
+		lv = level.Info
+	}
+
+	event := r.Message()
+	if s.conf.EventKey != "" {
+		if a := lookup(s.conf.EventKey, r.Attrs(), s.bound); a != nil {
+			if name, ok := a.Value().(string); ok {
+				event = name
+			}
+		}
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	b.WriteString(s.conf.Prefix)
+	b.WriteString(s.conf.Counter)
+	if s.conf.DogStatsD {
+		b.WriteString(":1|c|#level:")
+		b.WriteString(statsdTag(lv.String()))
+		b.WriteString(",event:")
+		b.WriteString(statsdTag(event))
+		s.writeTags(b)
+	} else {
+		b.WriteByte('.')
+		b.WriteString(statsdName(lv.String()))
+		b.WriteByte('.')
+		b.WriteString(statsdName(event))
+		b.WriteString(":1|c")
+	}
+	_, _ = s.w.Write(b.Bytes())
+
+	if s.conf.DogStatsD && s.conf.EventLevel != nil && lv.Int() >= s.conf.EventLevel.Int() {
+		e := buffer.Get()
+		s.writeEvent(e, r, lv)
+		_, _ = s.w.Write(e.Bytes())
+		e.Free()
+	}
+
+	return s.h.Handle(r)
+}
+
+// writeEvent writes the Record `r` as a DogStatsD event, titled with its
+// message and with its attributes as a JSON object in the text
+func (s statsdHandler) writeEvent(b *buffer.Buffer, r records.Record, lv level.Level) {
+	title := statsdText(r.Message())
+	text := statsdText(AttrsJSON(nil, r.Attrs(), s.bound))
+
+	alert := "info"
+	switch {
+	case lv.Int() >= level.Error.Int():
+		alert = "error"
+	case lv.Int() >= level.Warn.Int():
+		alert = "warning"
+	}
+
+	b.WriteString("_e{")
+	b.WriteString(strconv.Itoa(len(title)))
+	b.WriteByte(',')
+	b.WriteString(strconv.Itoa(len(text)))
+	b.WriteString("}:")
+	b.WriteString(title)
+	b.WriteByte('|')
+	b.WriteString(text)
+	b.WriteString("|d:")
+	b.WriteString(strconv.FormatInt(r.Time().Unix(), 10))
+	b.WriteString("|t:")
+	b.WriteString(alert)
+	b.WriteString("|#level:")
+	b.WriteString(statsdTag(lv.String()))
+	s.writeTags(b)
+}
+
+// writeTags writes the configured tags, each one led by a comma
+func (s statsdHandler) writeTags(b *buffer.Buffer) {
+	for _, tag := range s.conf.Tags {
+		b.WriteByte(',')
+		b.WriteString(statsdTag(tag))
+	}
+}
+
+// statsdName returns `s` as a metric name segment, replacing the characters
+// other than letters, digits, hyphens and underscores with underscores
+func statsdName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// statsdTag returns `s` as a DogStatsD tag (or tag value), replacing the
+// characters delimiting the tags and the datagram with underscores
+func statsdTag(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ',', '|', '#', '\n', '\r', ' ':
+			return '_'
+		default:
+			return r
+		}
+	}, s)
+}
+
+// statsdText returns `s` as a DogStatsD event title or text, with its line
+// breaks escaped and its pipes replaced
+func statsdText(s string) string {
+	return strings.NewReplacer("\n", `\n`, "\r", "", "|", "/").Replace(s)
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (s statsdHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, s.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (s statsdHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, s.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (s statsdHandler) With(attrs ...attr.Attr) Handler {
+	bound := make([]attr.Attr, 0, len(s.bound)+len(attrs))
+	return statsdHandler{
+		h:     s.h.With(attrs...),
+		w:     s.w,
+		conf:  s.conf,
+		bound: append(append(bound, s.bound...), attrs...),
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (s statsdHandler) WithSource(addSource bool) Handler {
+	return statsdHandler{
+		h:     s.h.WithSource(addSource),
+		w:     s.w,
+		conf:  s.conf,
+		bound: s.bound,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (s statsdHandler) WithLevel(level level.Leveler) Handler {
+	return statsdHandler{
+		h:     s.h.WithLevel(level),
+		w:     s.w,
+		conf:  s.conf,
+		bound: s.bound,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (s statsdHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return statsdHandler{
+		h:     s.h.WithReplaceFn(fn),
+		w:     s.w,
+		conf:  s.conf,
+		bound: s.bound,
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// datagrams records each write as a separate datagram
+type datagrams struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (d *datagrams) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.msgs = append(d.msgs, string(p))
+	return len(p), nil
+}
+
+func TestStatsD(t *testing.T) {
+	t.Run("Plain", func(t *testing.T) {
+		d := &datagrams{}
+		th := newTestHandler()
+		h := StatsD(th, d, StatsDConfig{Prefix: "api."})
+
+		_ = h.Handle(records.New(time.Now(), level.Warn, "cache miss"))
+
+		if len(d.msgs) != 1 || d.msgs[0] != "api.log.records.warn.cache_miss:1|c" {
+			t.Errorf("output mismatch error: got %q", d.msgs)
+		}
+		if len(th.Records()) != 1 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 1, len(th.Records()))
+		}
+	})
+	t.Run("DogStatsD", func(t *testing.T) {
+		d := &datagrams{}
+		h := StatsD(newTestHandler(), d, StatsDConfig{
+			EventKey:  "event",
+			DogStatsD: true,
+			Tags:      []string{"env:prod"},
+		}).With(attr.String("event", "user.login"))
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "user logged in"))
+
+		if len(d.msgs) != 1 || d.msgs[0] != "log.records:1|c|#level:info,event:user.login,env:prod" {
+			t.Errorf("output mismatch error: got %q", d.msgs)
+		}
+	})
+	t.Run("Events", func(t *testing.T) {
+		d := &datagrams{}
+		h := StatsD(newTestHandler(), d, StatsDConfig{DogStatsD: true, EventLevel: level.Error})
+
+		_ = h.Handle(records.New(time.Unix(1700000000, 0), level.Warn, "slow query"))
+		_ = h.Handle(records.New(time.Unix(1700000000, 0), level.Error, "db down", attr.String("host", "db-1")))
+
+		if len(d.msgs) != 3 {
+			t.Errorf("output mismatch error: wanted %v datagrams ; got %q", 3, d.msgs)
+			return
+		}
+		wants := `_e{7,15}:db down|{"host":"db-1"}|d:1700000000|t:error|#level:error`
+		if d.msgs[2] != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, d.msgs[2])
+		}
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		d := &datagrams{}
+		h := StatsD(newTestHandler(), d, StatsDConfig{}).WithLevel(level.Error)
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "ignored"))
+
+		if len(d.msgs) != 0 {
+			t.Errorf("output mismatch error: got %q", d.msgs)
+		}
+	})
+	t.Run("Sanitize", func(t *testing.T) {
+		if got := statsdTag("a|b,c d"); got != "a_b_c_d" {
+			t.Errorf("output mismatch error: got %q", got)
+		}
+		if got := statsdText("line\nbreak|pipe"); !strings.Contains(got, `\n`) || strings.Contains(got, "|") {
+			t.Errorf("output mismatch error: got %q", got)
+		}
+	})
+	t.Run("NilHandler", func(t *testing.T) {
+		if h := StatsD(nil, &datagrams{}, StatsDConfig{}); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+}
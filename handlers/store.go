@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// Store is a Handler that retains the last records it handles in memory, to
+// be queried later, e.g. for a debug page (see its ServeHTTP method) or for
+// assertions in tests.
+//
+// Handlers derived from a Store (with its With* methods) add their records to
+// the same buffer
+type Store struct {
+	storeHandler
+}
+
+type storeHandler struct {
+	b        *storeBuffer
+	attrs    []attr.Attr
+	levelRef level.Leveler
+	replFn   func(a attr.Attr) attr.Attr
+}
+
+type storeBuffer struct {
+	mu      sync.RWMutex
+	records []records.Record
+	next    int
+	full    bool
+}
+
+// Query describes the records to retrieve from a Store. Its zero value
+// matches all records
+type Query struct {
+	// MinLevel filters out the records below this level
+	MinLevel level.Level
+	// Since filters out the records older than this time
+	Since time.Time
+	// Until filters out the records newer than this time
+	Until time.Time
+	// Message filters out the records whose message does not contain it
+	Message string
+	// Filter, if set, filters out the records it returns false for
+	Filter func(r records.Record) bool
+	// Limit caps the number of records returned, keeping the newest ones
+	Limit int
+}
+
+// NewStore creates a Store that retains up to the last `size` records
+func NewStore(size int) *Store {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &Store{
+		storeHandler: storeHandler{
+			b: &storeBuffer{
+				records: make([]records.Record, size),
+			},
+		},
+	}
+}
+
+// HasAttr returns a Query Filter matching the records with an attribute with
+// key `key` and value `value`
+func HasAttr(key string, value any) func(r records.Record) bool {
+	return func(r records.Record) bool {
+		for _, a := range r.Attrs() {
+			if a != nil && a.Key() == key && a.Value() == value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Query returns the retained records matching the Query `q`, from the oldest
+// to the newest
+func (s *Store) Query(q Query) []records.Record {
+	s.b.mu.RLock()
+	defer s.b.mu.RUnlock()
+
+	var out []records.Record
+	s.b.each(func(r records.Record) {
+		if q.matches(r) {
+			out = append(out, r)
+		}
+	})
+
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out
+}
+
+// Records returns all retained records, from the oldest to the newest
+func (s *Store) Records() []records.Record {
+	return s.Query(Query{})
+}
+
+// Len returns the number of retained records
+func (s *Store) Len() int {
+	s.b.mu.RLock()
+	defer s.b.mu.RUnlock()
+
+	if s.b.full {
+		return len(s.b.records)
+	}
+	return s.b.next
+}
+
+// Reset discards all retained records
+func (s *Store) Reset() {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+
+	for i := range s.b.records {
+		s.b.records[i] = nil
+	}
+	s.b.next, s.b.full = 0, false
+}
+
+func (b *storeBuffer) add(r records.Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.records[b.next] = r
+	if b.next++; b.next == len(b.records) {
+		b.next, b.full = 0, true
+	}
+}
+
+func (b *storeBuffer) each(fn func(records.Record)) {
+	if b.full {
+		for _, r := range b.records[b.next:] {
+			fn(r)
+		}
+	}
+	for _, r := range b.records[:b.next] {
+		fn(r)
+	}
+}
+
+func (q Query) matches(r records.Record) bool {
+	switch {
+	case q.MinLevel != nil && r.Level().Int() < q.MinLevel.Int():
+		return false
+	case !q.Since.IsZero() && r.Time().Before(q.Since):
+		return false
+	case !q.Until.IsZero() && r.Time().After(q.Until):
+		return false
+	case q.Message != "" && !strings.Contains(r.Message(), q.Message):
+		return false
+	case q.Filter != nil && !q.Filter(r):
+		return false
+	default:
+		return true
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (s storeHandler) Enabled(level level.Level) bool {
+	if s.levelRef == nil || level == nil {
+		return true
+	}
+	ref := s.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// Handle will process the input Record, returning an error if raised
+func (s storeHandler) Handle(r records.Record) error {
+	if !s.Enabled(r.Level()) {
+		return nil
+	}
+
+	r = r.Clone()
+	if len(s.attrs) > 0 {
+		r = r.AddAttr(s.attrs...)
+	}
+	if s.replFn != nil {
+		attrs := make([]attr.Attr, 0, r.AttrLen())
+		for _, a := range r.Attrs() {
+			if a = s.replFn(a); a != nil {
+				attrs = append(attrs, a)
+			}
+		}
+		r = records.WithPC(records.New(r.Time(), r.Level(), r.Message(), attrs...), records.PC(r))
+	}
+
+	s.b.add(r)
+	return nil
+}
+
+// Ping implements Pinger; a Store is always healthy
+func (s storeHandler) Ping(context.Context) error {
+	return nil
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (s storeHandler) With(attrs ...attr.Attr) Handler {
+	s.attrs = attrs
+	return s
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (s storeHandler) WithSource(bool) Handler {
+	return s
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (s storeHandler) WithLevel(level level.Leveler) Handler {
+	s.levelRef = level
+	return s
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (s storeHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	s.replFn = fn
+	return s
+}
@@ -0,0 +1,185 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/zalgonoise/logx/records"
+)
+
+const defaultDebugLimit = 100
+
+// StoreDebugConfig configures the debug page served by a Store's DebugHandler
+type StoreDebugConfig struct {
+	// Authorize is called on each request, which is refused (with a 403
+	// Forbidden status) unless it returns true, like to check a session or an
+	// operator's token. It is required: if nil, all requests are refused, as
+	// the client's address cannot be trusted behind a proxy
+	Authorize func(req *http.Request) bool
+	// Limit is the number of records served, keeping the newest ones, if the
+	// request does not set one with the `limit` parameter. Defaults to 100
+	Limit int
+}
+
+type debugView struct {
+	Level    string
+	Message  string
+	Limit    int
+	Filters  string
+	JSONLink string
+	Records  []debugRecord
+}
+
+type debugRecord struct {
+	Time    string
+	Level   string
+	Message string
+	Attrs   string
+}
+
+var debugTemplate = template.Must(template.New("logs").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>logs</title>
+<style>
+body { font-family: monospace; margin: 1em; }
+table { border-collapse: collapse; width: 100%; }
+td, th { border-bottom: 1px solid #ddd; padding: 2px 8px; text-align: left; vertical-align: top; }
+td.attrs { color: #555; }
+</style>
+</head>
+<body>
+<form method="get">
+level <input name="level" value="{{.Level}}" size="6">
+message <input name="q" value="{{.Message}}">
+limit <input name="limit" value="{{.Limit}}" size="5">
+<input type="submit" value="filter">
+{{if .Filters}}<span>{{.Filters}}</span>{{end}}
+</form>
+<p>{{len .Records}} records, newest last (<a href="{{.JSONLink}}">json</a>)</p>
+<table>
+<tr><th>time</th><th>level</th><th>message</th><th>attributes</th></tr>
+{{range .Records}}<tr><td>{{.Time}}</td><td>{{.Level}}</td><td>{{.Message}}</td><td class="attrs">{{.Attrs}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// DebugHandler returns an http.Handler serving the records retained by the
+// Store for quick triage in production, like on `/debug/logs`: as an HTML page
+// with a filter form, or as a JSON array (like ServeHTTP) for the requests
+// with the `format=json` parameter or accepting `application/json`.
+//
+// The records are filtered with the same query parameters as ServeHTTP's, and
+// the requests are authorized as configured in StoreDebugConfig `conf`
+func (s *Store) DebugHandler(conf StoreDebugConfig) http.Handler {
+	if conf.Authorize == nil {
+		conf.Authorize = func(*http.Request) bool { return false }
+	}
+	if conf.Limit <= 0 {
+		conf.Limit = defaultDebugLimit
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !conf.Authorize(req) {
+			writeStoreJSON(w, http.StatusForbidden, storeError{Error: "forbidden"})
+			return
+		}
+		if req.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeStoreJSON(w, http.StatusMethodNotAllowed, storeError{Error: "method not allowed"})
+			return
+		}
+
+		values := req.URL.Query()
+		asJSON := values.Get("format") == "json" ||
+			values.Get("format") == "" && strings.Contains(req.Header.Get("Accept"), "application/json")
+		values.Del("format")
+		for key := range values {
+			// empty form fields are not filters
+			if values.Get(key) == "" {
+				values.Del(key)
+			}
+		}
+
+		q, err := parseQuery(values)
+		if err != nil {
+			writeStoreJSON(w, http.StatusBadRequest, storeError{Error: err.Error()})
+			return
+		}
+		if q.Limit <= 0 {
+			q.Limit = conf.Limit
+		}
+		rs := s.Query(q)
+
+		if asJSON {
+			writeStoreJSON(w, http.StatusOK, storeEntries(rs))
+			return
+		}
+
+		view := debugView{
+			Level:    values.Get("level"),
+			Message:  q.Message,
+			Limit:    q.Limit,
+			Filters:  debugFilters(values),
+			JSONLink: jsonLink(values),
+			Records:  make([]debugRecord, 0, len(rs)),
+		}
+		for _, r := range rs {
+			view.Records = append(view.Records, newDebugRecord(r))
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = debugTemplate.Execute(w, view)
+	})
+}
+
+// newDebugRecord formats the Record `r` for the debug page
+func newDebugRecord(r records.Record) debugRecord {
+	attrs := make([]string, 0, r.AttrLen())
+	for _, a := range r.Attrs() {
+		if a != nil {
+			attrs = append(attrs, a.Key()+"="+fmt.Sprint(a.Value()))
+		}
+	}
+
+	return debugRecord{
+		Time:    r.Time().Format("2006-01-02 15:04:05.000"),
+		Level:   r.Level().String(),
+		Message: r.Message(),
+		Attrs:   strings.Join(attrs, " "),
+	}
+}
+
+// debugFilters describes the attribute filters in the query parameters
+// `values`, sorted by key
+func debugFilters(values map[string][]string) string {
+	filters := make([]string, 0, len(values))
+	for key := range values {
+		switch key {
+		case "level", "since", "until", "q", "limit":
+		default:
+			filters = append(filters, key+"="+values[key][0])
+		}
+	}
+	sort.Strings(filters)
+	return strings.Join(filters, " ")
+}
+
+// jsonLink returns the link to the JSON output of the debug page, keeping the
+// filters in the query parameters `values`
+func jsonLink(values url.Values) string {
+	link := make(url.Values, len(values)+1)
+	for key := range values {
+		link[key] = values[key]
+	}
+	link.Set("format", "json")
+	return "?" + link.Encode()
+}
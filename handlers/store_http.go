@@ -0,0 +1,122 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+type storeEntry struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Message   string         `json:"message"`
+	Level     string         `json:"level"`
+	Data      map[string]any `json:"data,omitempty"`
+}
+
+type storeError struct {
+	Error string `json:"error"`
+}
+
+// ServeHTTP implements http.Handler, returning the retained records as a JSON
+// array, to be mounted on a debug endpoint like `/debug/logs`. The records
+// are filtered with the query parameters:
+//   - `level`: the minimum level (e.g. `warn`)
+//   - `since` and `until`: the time range, in RFC3339 format
+//   - `q`: a substring of the message
+//   - `limit`: the maximum number of records, keeping the newest ones
+//   - any other parameter is matched against the records' attributes, as
+//     strings (e.g. `request_id=abc`)
+func (s *Store) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeStoreJSON(w, http.StatusMethodNotAllowed, storeError{Error: "method not allowed"})
+		return
+	}
+
+	q, err := parseQuery(req.URL.Query())
+	if err != nil {
+		writeStoreJSON(w, http.StatusBadRequest, storeError{Error: err.Error()})
+		return
+	}
+
+	writeStoreJSON(w, http.StatusOK, storeEntries(s.Query(q)))
+}
+
+// storeEntries converts the records `rs` into their JSON representation
+func storeEntries(rs []records.Record) []storeEntry {
+	out := make([]storeEntry, 0, len(rs))
+	for _, r := range rs {
+		e := storeEntry{
+			Timestamp: r.Time(),
+			Message:   r.Message(),
+			Level:     r.Level().String(),
+		}
+		if r.AttrLen() > 0 {
+			e.Data = attr.Map(r.Attrs()...)
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+func parseQuery(values url.Values) (Query, error) {
+	var (
+		q     Query
+		err   error
+		attrs = map[string]string{}
+	)
+
+	for key := range values {
+		v := values.Get(key)
+		switch key {
+		case "level":
+			if q.MinLevel, err = level.Parse(v); err != nil {
+				return q, err
+			}
+		case "since":
+			if q.Since, err = time.Parse(time.RFC3339, v); err != nil {
+				return q, err
+			}
+		case "until":
+			if q.Until, err = time.Parse(time.RFC3339, v); err != nil {
+				return q, err
+			}
+		case "q":
+			q.Message = v
+		case "limit":
+			if q.Limit, err = strconv.Atoi(v); err != nil {
+				return q, err
+			}
+		default:
+			attrs[key] = v
+		}
+	}
+
+	if len(attrs) > 0 {
+		q.Filter = func(r records.Record) bool {
+			matched := make(map[string]struct{}, len(attrs))
+			for _, a := range r.Attrs() {
+				if v, ok := attrs[a.Key()]; ok && fmt.Sprint(a.Value()) == v {
+					matched[a.Key()] = struct{}{}
+				}
+			}
+			return len(matched) == len(attrs)
+		}
+	}
+	return q, nil
+}
+
+func writeStoreJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,134 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestStoreServeHTTP(t *testing.T) {
+	base := time.Date(2024, 5, 6, 7, 0, 0, 0, time.UTC)
+	s := NewStore(10)
+	for i, lv := range []level.Level{level.Debug, level.Info, level.Warn, level.Error, level.Info} {
+		_ = s.Handle(records.New(base.Add(time.Duration(i)*time.Minute), lv, "event", attr.Int("i", i)))
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/logs?level=info&i=4", nil))
+
+	var out []storeEntry
+	if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if len(out) != 1 || out[0].Level != "info" || out[0].Data["i"] != float64(4) {
+		t.Errorf("output mismatch error: wanted the matching record ; got %v", out)
+	}
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/logs?level=loud", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("output mismatch error: wanted %v ; got %v", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestWindowServeHTTP(t *testing.T) {
+	w := NewWindow(newTestHandler(), WindowConfig{Level: level.Info})
+
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/verbose", strings.NewReader(`{"duration":"10m"}`)))
+
+	var out windowPayload
+	if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if !out.Active || out.End == nil || !w.Enabled(level.Debug) {
+		t.Errorf("output mismatch error: wanted an active window ; got %+v", out)
+	}
+
+	rec = httptest.NewRecorder()
+	w.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/admin/verbose", nil))
+	if w.Active() || w.Enabled(level.Debug) {
+		t.Errorf("output mismatch error: wanted a closed window ; got %s", rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	w.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/verbose", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("output mismatch error: wanted %v ; got %v", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestStoreDebugHandler(t *testing.T) {
+	s := NewStore(10)
+	for i, lv := range []level.Level{level.Debug, level.Info, level.Warn} {
+		_ = s.Handle(records.New(time.Now(), lv, "event <"+lv.String()+">", attr.Int("i", i)))
+	}
+
+	t.Run("DeniedByDefault", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/debug/logs", nil)
+		req.RemoteAddr = "127.0.0.1:4321"
+		rec := httptest.NewRecorder()
+		s.DebugHandler(StoreDebugConfig{}).ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("output mismatch error: wanted %v ; got %v", http.StatusForbidden, rec.Code)
+		}
+	})
+
+	h := s.DebugHandler(StoreDebugConfig{
+		Authorize: func(req *http.Request) bool { return req.Header.Get("X-Token") == "secret" },
+		Limit:     1,
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/logs", nil))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("output mismatch error: wanted %v ; got %v", http.StatusForbidden, rec.Code)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/debug/logs?format=json&level=info&q=", nil)
+		req.Header.Set("X-Token", "secret")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		var out []storeEntry
+		if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(out) != 1 || out[0].Level != "warn" {
+			t.Errorf("output mismatch error: wanted the newest record ; got %v", out)
+		}
+	})
+
+	t.Run("HTML", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/debug/logs?limit=5&i=1", nil)
+		req.Header.Set("X-Token", "secret")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		body := rec.Body.String()
+		if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "text/html", rec.Header().Get("Content-Type"))
+		}
+		if !strings.Contains(body, "event &lt;info&gt;") || strings.Contains(body, "event &lt;warn&gt;") {
+			t.Errorf("output mismatch error: wanted the escaped info record only ; got %s", body)
+		}
+		if wants := `href="?format=json&amp;i=1&amp;limit=5"`; !strings.Contains(body, wants) {
+			t.Errorf("output mismatch error: wanted the JSON link %s ; got %s", wants, body)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestStore(t *testing.T) {
+	base := time.Date(2024, 5, 6, 7, 0, 0, 0, time.UTC)
+	fill := func(s *Store) {
+		for i, lv := range []level.Level{level.Debug, level.Info, level.Warn, level.Error, level.Info} {
+			_ = s.Handle(records.New(base.Add(time.Duration(i)*time.Minute), lv, "event", attr.Int("i", i)))
+		}
+	}
+
+	t.Run("Ring", func(t *testing.T) {
+		s := NewStore(3)
+		fill(s)
+
+		rs := s.Records()
+		if s.Len() != 3 || len(rs) != 3 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 3, len(rs))
+			return
+		}
+		for idx, wants := range []int64{2, 3, 4} {
+			if got := rs[idx].Attrs()[0].Value(); got != wants {
+				t.Errorf("output mismatch error: wanted %v ; got %v", wants, got)
+			}
+		}
+
+		s.Reset()
+		if s.Len() != 0 || len(s.Records()) != 0 {
+			t.Errorf("output mismatch error: wanted an empty store ; got %v", s.Records())
+		}
+	})
+	t.Run("Query", func(t *testing.T) {
+		s := NewStore(10)
+		fill(s)
+
+		for _, testcase := range []struct {
+			name  string
+			q     Query
+			wants int
+		}{
+			{"All", Query{}, 5},
+			{"MinLevel", Query{MinLevel: level.Warn}, 2},
+			{"TimeRange", Query{Since: base.Add(time.Minute), Until: base.Add(3 * time.Minute)}, 3},
+			{"Filter", Query{Filter: HasAttr("i", int64(1))}, 1},
+			{"Limit", Query{Limit: 2}, 2},
+		} {
+			t.Run(testcase.name, func(t *testing.T) {
+				if got := len(s.Query(testcase.q)); got != testcase.wants {
+					t.Errorf("output mismatch error: wanted %v records ; got %v", testcase.wants, got)
+				}
+			})
+		}
+	})
+	t.Run("Derived", func(t *testing.T) {
+		s := NewStore(10)
+		h := s.With(attr.String("component", "db")).WithLevel(level.Warn)
+
+		_ = h.Handle(records.New(base, level.Info, "filtered"))
+		_ = h.Handle(records.New(base, level.Error, "failed"))
+
+		rs := s.Records()
+		if len(rs) != 1 || !HasAttr("component", "db")(rs[0]) {
+			t.Errorf("output mismatch error: wanted the derived handler's record ; got %v", rs)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// Swap is a Handler that delegates to another Handler, which can be atomically
+// replaced at runtime with its Store method
+//
+// Handlers derived from a Swap (with its With* methods) also follow the
+// replacements, by applying the same derivations to the new Handler
+type Swap struct {
+	swapHandler
+}
+
+type swapHandler struct {
+	ref   *atomic.Pointer[Handler]
+	ops   []func(Handler) Handler
+	cache *atomic.Pointer[swapCache]
+}
+
+type swapCache struct {
+	base *Handler
+	h    Handler
+}
+
+// NewSwap creates a Swap delegating to the Handler `h`
+func NewSwap(h Handler) *Swap {
+	s := &Swap{
+		swapHandler: swapHandler{
+			ref:   &atomic.Pointer[Handler]{},
+			cache: &atomic.Pointer[swapCache]{},
+		},
+	}
+	s.Store(h)
+	return s
+}
+
+// Store atomically replaces the Handler that this Swap delegates to with `h`,
+// returning the previous one. If `h` is nil, records are discarded
+func (s *Swap) Store(h Handler) Handler {
+	if h == nil {
+		h = Unimpl()
+	}
+	prev := s.ref.Swap(&h)
+	if prev == nil {
+		return nil
+	}
+	return *prev
+}
+
+// Load returns the Handler that this Swap currently delegates to
+func (s *Swap) Load() Handler {
+	return *s.ref.Load()
+}
+
+func (s swapHandler) current() Handler {
+	base := s.ref.Load()
+	if len(s.ops) == 0 {
+		return *base
+	}
+
+	if c := s.cache.Load(); c != nil && c.base == base {
+		return c.h
+	}
+
+	h := *base
+	for _, op := range s.ops {
+		h = op(h)
+	}
+	s.cache.Store(&swapCache{base: base, h: h})
+	return h
+}
+
+func (s swapHandler) derive(op func(Handler) Handler) Handler {
+	ops := make([]func(Handler) Handler, len(s.ops), len(s.ops)+1)
+	copy(ops, s.ops)
+
+	return swapHandler{
+		ref:   s.ref,
+		ops:   append(ops, op),
+		cache: &atomic.Pointer[swapCache]{},
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (s swapHandler) Enabled(level level.Level) bool {
+	return s.current().Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (s swapHandler) Handle(r records.Record) error {
+	return s.current().Handle(r)
+}
+
+// Ping implements Pinger, checking the health of the current Handler
+func (s swapHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, s.current())
+}
+
+// Shutdown implements Shutdowner, shutting down the current Handler
+func (s swapHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, s.current())
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (s swapHandler) With(attrs ...attr.Attr) Handler {
+	return s.derive(func(h Handler) Handler {
+		return h.With(attrs...)
+	})
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (s swapHandler) WithSource(addSource bool) Handler {
+	return s.derive(func(h Handler) Handler {
+		return h.WithSource(addSource)
+	})
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (s swapHandler) WithLevel(level level.Leveler) Handler {
+	return s.derive(func(h Handler) Handler {
+		return h.WithLevel(level)
+	})
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (s swapHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return s.derive(func(h Handler) Handler {
+		return h.WithReplaceFn(fn)
+	})
+}
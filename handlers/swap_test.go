@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestSwap(t *testing.T) {
+	t.Run("Store", func(t *testing.T) {
+		first := newTestHandler()
+		second := newTestHandler()
+		s := NewSwap(first)
+		r := records.New(time.Now(), level.Info, "test message")
+
+		_ = s.Handle(r)
+		if prev := s.Store(second); prev == nil {
+			t.Errorf("expected previous handler not to be nil")
+		}
+		_ = s.Handle(r)
+
+		if len(first.Records()) != 1 || len(second.Records()) != 1 {
+			t.Errorf("unexpected number of records: %d ; %d", len(first.Records()), len(second.Records()))
+		}
+	})
+	t.Run("DerivedFollowsSwap", func(t *testing.T) {
+		first := newTestHandler()
+		second := newTestHandler()
+		s := NewSwap(first)
+		derived := s.WithLevel(level.Warn)
+
+		_ = derived.Handle(records.New(time.Now(), level.Info, "filtered"))
+		s.Store(second)
+		_ = derived.Handle(records.New(time.Now(), level.Info, "filtered"))
+		_ = derived.Handle(records.New(time.Now(), level.Error, "handled"))
+
+		if len(first.Records()) != 0 {
+			t.Errorf("unexpected number of records: wanted 0 ; got %d", len(first.Records()))
+		}
+		if out := second.Records(); len(out) != 1 || out[0].Message() != "handled" {
+			t.Errorf("unexpected records: %v", out)
+		}
+	})
+	t.Run("NilStore", func(t *testing.T) {
+		s := NewSwap(nil)
+
+		if s.Enabled(level.Fatal) {
+			t.Errorf("expected a nil handler not to be enabled")
+		}
+	})
+}
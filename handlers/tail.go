@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const defaultTailRecords = 256
+
+// TailConfig configures which records a Tail holds, and how many
+type TailConfig struct {
+	// Level is the level from which records are handled right away, defaulting
+	// to level.Warn. Records below it are held until the Tail is flushed
+	Level level.Level
+	// Min is the lowest level of the records held, defaulting to level.Debug.
+	// Records below it are dropped
+	Min level.Level
+	// MaxRecords is the maximum number of records held, defaulting to 256.
+	// Once full, the oldest records are dropped to make room for new ones
+	MaxRecords int
+}
+
+// Tail is a Handler that holds the low-level records of a unit of work (like a
+// request) in memory, so that they are only handled if it fails. Records at or
+// above the configured level are handled right away, while the ones below it
+// are held until Flush is called, or dropped with Discard.
+//
+// This keeps the debug output of failed requests, while discarding it for the
+// successful ones, which are the vast majority. Held records are handled
+// regardless of the verbosity of the wrapped Handler, as they are only flushed
+// when they are needed.
+//
+// A Tail is meant to be short-lived, and created for each unit of work (see
+// logx.Tail). Handlers derived from a Tail (with its With* methods) share its
+// buffer
+type Tail struct {
+	tailHandler
+}
+
+type tailHandler struct {
+	h     Handler
+	conf  TailConfig
+	state *tailState
+}
+
+type tailJob struct {
+	h Handler
+	r records.Record
+}
+
+type tailState struct {
+	mu   sync.Mutex
+	jobs []tailJob
+}
+
+// NewTail creates a Tail for the Handler `h`, as configured by the TailConfig
+// `conf`
+func NewTail(h Handler, conf TailConfig) *Tail {
+	if h == nil {
+		h = Unimpl()
+	}
+	if conf.Level == nil {
+		conf.Level = level.Warn
+	}
+	if conf.Min == nil {
+		conf.Min = level.Debug
+	}
+	if conf.MaxRecords <= 0 {
+		conf.MaxRecords = defaultTailRecords
+	}
+
+	return &Tail{
+		tailHandler: tailHandler{
+			h:     h,
+			conf:  conf,
+			state: &tailState{},
+		},
+	}
+}
+
+// Len returns the number of records held
+func (t *Tail) Len() int {
+	t.state.mu.Lock()
+	defer t.state.mu.Unlock()
+
+	return len(t.state.jobs)
+}
+
+// Flush handles the records held so far, in order, with no verbosity filter,
+// and releases them. It returns the errors raised when handling them, joined
+func (t *Tail) Flush() error {
+	jobs := t.take()
+
+	var errs []error
+	for _, job := range jobs {
+		if err := job.h.WithLevel(nil).Handle(job.r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Discard drops the records held so far
+func (t *Tail) Discard() {
+	_ = t.take()
+}
+
+func (t *Tail) take() []tailJob {
+	t.state.mu.Lock()
+	defer t.state.mu.Unlock()
+
+	jobs := t.state.jobs
+	t.state.jobs = nil
+	return jobs
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (t tailHandler) Enabled(level level.Level) bool {
+	if level == nil || level.Int() >= t.conf.Level.Int() {
+		return t.h.Enabled(level)
+	}
+	return level.Int() >= t.conf.Min.Int()
+}
+
+// Handle will process the input Record, returning an error if raised
+func (t tailHandler) Handle(r records.Record) error {
+	lv := r.Level()
+	if lv == nil || lv.Int() >= t.conf.Level.Int() {
+		return t.h.Handle(r)
+	}
+	if lv.Int() < t.conf.Min.Int() {
+		return nil
+	}
+
+	t.state.mu.Lock()
+	defer t.state.mu.Unlock()
+
+	if len(t.state.jobs) >= t.conf.MaxRecords {
+		copy(t.state.jobs, t.state.jobs[1:])
+		t.state.jobs = t.state.jobs[:len(t.state.jobs)-1]
+	}
+	// records may be reused by the caller once Handle returns
+	t.state.jobs = append(t.state.jobs, tailJob{h: t.h, r: r.Clone()})
+	return nil
+}
+
+// Ping implements Pinger, checking the health of the wrapped Handler
+func (t tailHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, t.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the wrapped Handler. Records
+// still held are discarded
+func (t tailHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, t.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (t tailHandler) With(attrs ...attr.Attr) Handler {
+	return tailHandler{
+		h:     t.h.With(attrs...),
+		conf:  t.conf,
+		state: t.state,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (t tailHandler) WithSource(addSource bool) Handler {
+	return tailHandler{
+		h:     t.h.WithSource(addSource),
+		conf:  t.conf,
+		state: t.state,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (t tailHandler) WithLevel(level level.Leveler) Handler {
+	return tailHandler{
+		h:     t.h.WithLevel(level),
+		conf:  t.conf,
+		state: t.state,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (t tailHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return tailHandler{
+		h:     t.h.WithReplaceFn(fn),
+		conf:  t.conf,
+		state: t.state,
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestTail(t *testing.T) {
+	handle := func(t *testing.T, h Handler, levels ...level.Level) {
+		for _, lv := range levels {
+			if err := h.Handle(records.New(time.Now(), lv, lv.String())); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}
+	messages := func(rs []records.Record) []string {
+		msgs := make([]string, 0, len(rs))
+		for _, r := range rs {
+			msgs = append(msgs, r.Message())
+		}
+		return msgs
+	}
+
+	t.Run("Flush", func(t *testing.T) {
+		th := newTestHandler()
+		tail := NewTail(th.WithLevel(level.Info), TailConfig{})
+
+		handle(t, tail, level.Trace, level.Debug, level.Info, level.Warn)
+		if got := messages(th.Records()); len(got) != 1 || got[0] != "warn" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", []string{"warn"}, got)
+		}
+		if tail.Len() != 2 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 2, tail.Len())
+		}
+
+		if err := tail.Flush(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		wants := []string{"warn", "debug", "info"}
+		got := messages(th.Records())
+		if len(got) != len(wants) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, got)
+			return
+		}
+		for i := range wants {
+			if got[i] != wants[i] {
+				t.Errorf("output mismatch error: wanted %v ; got %v", wants, got)
+			}
+		}
+		if tail.Len() != 0 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 0, tail.Len())
+		}
+	})
+
+	t.Run("Discard", func(t *testing.T) {
+		th := newTestHandler()
+		tail := NewTail(th, TailConfig{})
+
+		handle(t, tail.With(), level.Debug, level.Info)
+		tail.Discard()
+		if err := tail.Flush(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if got := th.Records(); len(got) != 0 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 0, len(got))
+		}
+	})
+
+	t.Run("MaxRecords", func(t *testing.T) {
+		th := newTestHandler()
+		tail := NewTail(th, TailConfig{Level: level.Error, MaxRecords: 2})
+
+		handle(t, tail, level.Debug, level.Info, level.Warn)
+		if err := tail.Flush(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if got := messages(th.Records()); len(got) != 2 || got[0] != "info" || got[1] != "warn" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", []string{"info", "warn"}, got)
+		}
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		tail := NewTail(newTestHandler().WithLevel(level.Error), TailConfig{})
+
+		for _, test := range []struct {
+			lv    level.Level
+			wants bool
+		}{
+			{level.Trace, false},
+			{level.Debug, true},
+			{level.Info, true},
+			{level.Warn, false},
+			{level.Error, true},
+		} {
+			if got := tail.Enabled(test.lv); got != test.wants {
+				t.Errorf("output mismatch error for %s: wanted %v ; got %v", test.lv, test.wants, got)
+			}
+		}
+	})
+}
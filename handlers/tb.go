@@ -0,0 +1,145 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+type tbHandler struct {
+	t         testing.TB
+	failLevel level.Level
+	levelRef  level.Leveler
+	replFn    func(a attr.Attr) attr.Attr
+	attrs     []attr.Attr
+}
+
+// TB creates a Handler that writes records to the test or benchmark `t`, with
+// its Log method, so that they are shown with `go test -v` (or when the test
+// fails) alongside the test's own output.
+//
+// Each line is prefixed with the file and line of the code that logged the
+// record, as the one reported by testing.TB points to this package.
+//
+// Records with a level of at least `failLevel` are written with t.Error
+// instead, failing the test. If `failLevel` is nil, no record fails the test.
+//
+// Records must not be handled after the test completes, as testing.TB panics
+func TB(t testing.TB, failLevel level.Level) Handler {
+	if t == nil {
+		return nil
+	}
+
+	return tbHandler{
+		t:         t,
+		failLevel: failLevel,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h tbHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h tbHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	sb := &strings.Builder{}
+	if f, ok := Source(r); ok {
+		fmt.Fprintf(sb, "%s:%d: ", filepath.Base(f.File), f.Line)
+	}
+	if lv := r.Level(); lv != nil {
+		fmt.Fprintf(sb, "[%s] ", lv.String())
+	}
+	sb.WriteString(r.Message())
+	h.writeAttrs(sb, r.Attrs())
+	h.writeAttrs(sb, h.attrs)
+
+	h.t.Helper()
+	if h.failLevel != nil && r.Level() != nil && r.Level().Int() >= h.failLevel.Int() {
+		h.t.Error(sb.String())
+		return nil
+	}
+	h.t.Log(sb.String())
+	return nil
+}
+
+func (h tbHandler) writeAttrs(sb *strings.Builder, attrs []attr.Attr) {
+	for _, a := range attrs {
+		if h.replFn != nil {
+			a = h.replFn(a)
+		}
+		if a == nil || a.Key() == "" {
+			continue
+		}
+
+		sb.WriteByte(' ')
+		sb.WriteString(a.Key())
+		sb.WriteByte('=')
+
+		if group, ok := a.Value().([]attr.Attr); ok {
+			sb.WriteByte('{')
+			h.writeAttrs(sb, group)
+			sb.WriteString(" }")
+			continue
+		}
+		fmt.Fprintf(sb, "%v", a.Value())
+	}
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h tbHandler) With(attrs ...attr.Attr) Handler {
+	return tbHandler{
+		t:         h.t,
+		failLevel: h.failLevel,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     attrs,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h tbHandler) WithSource(bool) Handler {
+	return h
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h tbHandler) WithLevel(level level.Leveler) Handler {
+	return tbHandler{
+		t:         h.t,
+		failLevel: h.failLevel,
+		levelRef:  level,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h tbHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return tbHandler{
+		t:         h.t,
+		failLevel: h.failLevel,
+		levelRef:  h.levelRef,
+		replFn:    fn,
+		attrs:     h.attrs,
+	}
+}
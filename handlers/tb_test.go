@@ -0,0 +1,81 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+type fakeTB struct {
+	testing.TB
+	logs   []string
+	errors []string
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Log(args ...any) {
+	t.logs = append(t.logs, fmt.Sprint(args...))
+}
+
+func (t *fakeTB) Error(args ...any) {
+	t.errors = append(t.errors, fmt.Sprint(args...))
+}
+
+func TestTB(t *testing.T) {
+	t.Run("Log", func(t *testing.T) {
+		tb := &fakeTB{}
+		h := TB(tb, nil).With(attr.String("service", "api"))
+
+		err := h.Handle(records.New(time.Now(), level.Info, "hello",
+			attr.Int("id", 7),
+			attr.New("req", []attr.Attr{attr.String("method", "GET")}),
+		))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if len(tb.logs) != 1 || len(tb.errors) != 0 {
+			t.Errorf("output mismatch error: wanted a single log line ; got %v and %v", tb.logs, tb.errors)
+			return
+		}
+		wants := "[info] hello id=7 req={ method=GET } service=api"
+		if !strings.HasPrefix(tb.logs[0], "tb_test.go:") || !strings.HasSuffix(tb.logs[0], wants) {
+			t.Errorf("output mismatch error: wanted %q ; got %q", "tb_test.go:NN: "+wants, tb.logs[0])
+		}
+	})
+	t.Run("FailLevel", func(t *testing.T) {
+		tb := &fakeTB{}
+		h := TB(tb, level.Error)
+
+		_ = h.Handle(records.New(time.Now(), level.Warn, "careful"))
+		_ = h.Handle(records.New(time.Now(), level.Error, "failed"))
+
+		if len(tb.logs) != 1 || len(tb.errors) != 1 || !strings.HasSuffix(tb.errors[0], "[error] failed") {
+			t.Errorf("output mismatch error: wanted the error record to fail the test ; got %v and %v", tb.logs, tb.errors)
+		}
+	})
+	t.Run("WithLevel", func(t *testing.T) {
+		tb := &fakeTB{}
+		h := TB(tb, nil).WithLevel(level.Warn)
+
+		_ = h.Handle(records.New(time.Now(), level.Info, "skipped"))
+
+		if len(tb.logs) != 0 {
+			t.Errorf("output mismatch error: wanted no output ; got %v", tb.logs)
+		}
+	})
+	t.Run("NilTB", func(t *testing.T) {
+		if h := TB(nil, nil); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+}
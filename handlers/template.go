@@ -0,0 +1,197 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// TemplateData is the data that a Template handler renders its template with,
+// for each record
+type TemplateData struct {
+	// Time is the record's timestamp
+	Time time.Time
+	// Level is the name of the record's level
+	Level string
+	// Message is the record's message
+	Message string
+	// Source is the caller's file and line (as `file.go:42`), set if the
+	// handler is configured WithSource
+	Source string
+	// Function is the fully qualified name of the caller's function, set if
+	// the handler is configured WithSource
+	Function string
+	// Attrs holds the record's attributes followed by the handler's, keyed by
+	// their key, with groups as nested maps
+	Attrs map[string]any
+}
+
+type templateHandler struct {
+	w         io.Writer
+	tmpl      *template.Template
+	addSource bool
+	source    SourceConfig
+	levelRef  level.Leveler
+	replFn    func(a attr.Attr) attr.Attr
+	attrs     []attr.Attr
+}
+
+// Template creates a handler that writes records to the io.Writer `w`, by
+// executing the template `tmpl` with each record's TemplateData. A newline is
+// appended to the output, if it does not end with one:
+//
+//	tmpl := template.Must(template.New("log").Parse(
+//		`{{ .Time.Format "15:04:05" }} {{ .Level }} {{ .Message }} user={{ .Attrs.user }}`,
+//	))
+//	h := handlers.Template(os.Stdout, tmpl)
+func Template(w io.Writer, tmpl *template.Template) Handler {
+	if w == nil || tmpl == nil {
+		return nil
+	}
+	return templateHandler{
+		w:    w,
+		tmpl: tmpl,
+	}
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h templateHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	data := TemplateData{
+		Time:    r.Time(),
+		Message: r.Message(),
+		Attrs:   h.mapAttrs(r.Attrs()),
+	}
+	if lv := r.Level(); lv != nil {
+		data.Level = lv.String()
+	}
+	if h.addSource {
+		if f, ok := Source(r); ok {
+			data.Source = h.source.file(f, filepath.Base) + ":" + strconv.Itoa(f.Line)
+			data.Function = f.Function
+		}
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	if err := h.tmpl.Execute(b, data); err != nil {
+		return err
+	}
+	if n := len(*b); n == 0 || (*b)[n-1] != '\n' {
+		b.WriteByte('\n')
+	}
+
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+func (h templateHandler) mapAttrs(attrs []attr.Attr) map[string]any {
+	all := make([]attr.Attr, 0, len(h.attrs)+len(attrs))
+	for _, list := range [2][]attr.Attr{attrs, h.attrs} {
+		for _, a := range list {
+			if h.replFn != nil && a != nil {
+				a = h.replFn(a)
+			}
+			if a != nil {
+				all = append(all, a)
+			}
+		}
+	}
+	return attr.Map(all...)
+}
+
+// Ping implements Pinger, checking the health of the handler's io.Writer
+func (h templateHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, h.w)
+}
+
+// Shutdown implements Shutdowner, shutting down the handler's io.Writer
+func (h templateHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, h.w)
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h templateHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h templateHandler) With(attrs ...attr.Attr) Handler {
+	return templateHandler{
+		w:         h.w,
+		tmpl:      h.tmpl,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     attrs,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h templateHandler) WithSource(addSource bool) Handler {
+	return templateHandler{
+		w:         h.w,
+		tmpl:      h.tmpl,
+		addSource: addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// withSourceConfig implements sourceConfigurer
+func (h templateHandler) withSourceConfig(conf SourceConfig) Handler {
+	h.source = conf
+	return h
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h templateHandler) WithLevel(level level.Leveler) Handler {
+	return templateHandler{
+		w:         h.w,
+		tmpl:      h.tmpl,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  level,
+		replFn:    h.replFn,
+		attrs:     h.attrs,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h templateHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return templateHandler{
+		w:         h.w,
+		tmpl:      h.tmpl,
+		addSource: h.addSource,
+		source:    h.source,
+		levelRef:  h.levelRef,
+		replFn:    fn,
+		attrs:     h.attrs,
+	}
+}
@@ -0,0 +1,66 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestTemplate(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		tmpl := template.Must(template.New("log").Parse(
+			`{{ .Time.Format "15:04:05" }} {{ .Level }} {{ .Message }} user={{ .Attrs.user }} status={{ .Attrs.http.status }}`,
+		))
+		h := Template(b, tmpl).With(attr.String("user", "gopher"))
+
+		err := h.Handle(records.New(ts, level.Warn, "slow request",
+			attr.New("http", []attr.Attr{attr.Int("status", 504)}),
+		))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		wants := "03:04:05 warn slow request user=gopher status=504\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("WithSource", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := Template(b, template.Must(template.New("log").Parse("{{ .Source }} {{ .Function }}\n"))).WithSource(true)
+
+		_ = h.Handle(records.New(ts, level.Info, "message"))
+
+		if !strings.HasPrefix(b.String(), "template_test.go:") || !strings.Contains(b.String(), " github.com/zalgonoise/logx/handlers.TestTemplate.func") {
+			t.Errorf("output mismatch error: wanted the caller's source ; got %q", b.String())
+		}
+	})
+	t.Run("ExecError", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := Template(b, template.Must(template.New("log").Parse("{{ .Missing }}")))
+
+		if err := h.Handle(records.New(ts, level.Info, "message")); err == nil {
+			t.Errorf("expected an error executing the template")
+		}
+		if b.Len() != 0 {
+			t.Errorf("output mismatch error: wanted no output ; got %q", b.String())
+		}
+	})
+	t.Run("NilTemplate", func(t *testing.T) {
+		if h := Template(&bytes.Buffer{}, nil); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+}
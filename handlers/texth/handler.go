@@ -1,14 +1,16 @@
 package texth
 
 import (
-	"bytes"
+	"context"
 	"errors"
-	"fmt"
 	"io"
+	"runtime"
+	"strconv"
 	"time"
 
 	"github.com/zalgonoise/attr"
 	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/internal/buffer"
 	"github.com/zalgonoise/logx/level"
 	"github.com/zalgonoise/logx/records"
 )
@@ -32,10 +34,13 @@ var (
 type textHandler struct {
 	w         io.Writer
 	addSource bool
-	levelRef  level.Level
+	levelRef  level.Leveler
 	replFn    func(a attr.Attr) attr.Attr
 	attrs     []attr.Attr
 	conf      textHandlerConfig
+
+	bound       []byte
+	boundFolded []byte
 }
 
 type textHandlerConfig struct {
@@ -45,6 +50,11 @@ type textHandlerConfig struct {
 	sepAttr    rune
 	whitespace rune
 	timeFmt    string
+	timeConf   handlers.TimeConfig
+	source     handlers.SourceConfig
+	template   bool
+	catalog    handlers.Catalog
+	multiline  handlers.MultilineMode
 }
 
 // New creates a text handler based on the input io.Writer `w`
@@ -65,37 +75,97 @@ func New(w io.Writer) handlers.Handler {
 	}
 }
 
+func init() {
+	handlers.Register("text", newFromConfig)
+}
+
+// newFromConfig creates a text handler writing to the io.Writer configured in
+// `config` (see handlers.ConfigWriter)
+func newFromConfig(config map[string]any) (handlers.Handler, error) {
+	w, err := handlers.ConfigWriter(config)
+	if err != nil {
+		return nil, err
+	}
+	return New(w), nil
+}
+
 // Handle will process the input Record, returning an error if raised
 func (h textHandler) Handle(r records.Record) error {
-	if h.levelRef != nil && r.Level().Int() < h.levelRef.Int() {
+	if !h.Enabled(r.Level()) {
 		return nil
 	}
 
-	var b = &bytes.Buffer{}
+	b := buffer.Get()
+	defer b.Free()
 
 	b.WriteRune(h.conf.wrapperL)
-	b.WriteString(r.Time().Format(h.conf.timeFmt))
+	if h.conf.timeConf.Epoch {
+		*b = h.conf.timeConf.AppendTime(*b, r.Time())
+	} else {
+		*b = h.conf.timeConf.Convert(r.Time()).AppendFormat(*b, h.conf.timeFmt)
+	}
 	b.WriteRune(h.conf.wrapperR)
 	b.WriteRune(h.conf.whitespace)
 	b.WriteRune(h.conf.wrapperL)
 	b.WriteString(r.Level().String())
 	b.WriteRune(h.conf.wrapperR)
 	b.WriteRune(h.conf.whitespace)
-	b.WriteString(r.Message())
+	if h.addSource {
+		if f, ok := handlers.Source(r); ok {
+			h.writeSource(b, f)
+		}
+	}
+	msg := r.Message()
+	if h.conf.catalog != nil {
+		attrs := r.Attrs()
+		msg = h.conf.catalog.Message(msg, append(attrs[:len(attrs):len(attrs)], h.attrs...))
+	}
+
+	var folded *buffer.Buffer
+	switch h.conf.multiline {
+	case handlers.MultilineEscape:
+		msg = handlers.EscapeLines(msg)
+	case handlers.MultilineFold:
+		folded = buffer.Get()
+		defer folded.Free()
+
+		var rest string
+		if msg, rest = handlers.CutFirstLine(msg); rest != "" {
+			*folded = handlers.AppendFolded(*folded, "", rest)
+		}
+	}
+
+	if h.conf.template {
+		h.writeTemplate(b, msg, r.Attrs())
+	} else {
+		b.WriteString(msg)
+	}
 
-	var attributes []attr.Attr
-	attributes = append(attributes, h.attrs...)
-	attributes = append(attributes, r.Attrs()...)
-	if len(attributes) > 0 {
+	attrs := buffer.Get()
+	defer attrs.Free()
+	h.writeAttrs(attrs, "", r.Attrs(), folded)
+
+	if len(h.bound) > 0 || attrs.Len() > 0 {
 		b.WriteRune(h.conf.whitespace)
 		b.WriteRune(h.conf.wrapperL)
 		b.WriteRune(h.conf.whitespace)
-		b.WriteString(h.asString(attributes))
+		_, _ = b.Write(h.bound)
+		if len(h.bound) > 0 && attrs.Len() > 0 {
+			b.WriteRune(h.conf.whitespace)
+			b.WriteRune(h.conf.sepAttr)
+			b.WriteRune(h.conf.whitespace)
+		}
+		_, _ = b.Write(attrs.Bytes())
 		b.WriteRune(h.conf.whitespace)
 		b.WriteRune(h.conf.wrapperR)
 	}
 	b.WriteByte(10) // newline
 
+	if folded != nil {
+		_, _ = b.Write(folded.Bytes())
+		_, _ = b.Write(h.boundFolded)
+	}
+
 	n, err := h.w.Write(b.Bytes())
 	if err != nil {
 		return err
@@ -108,36 +178,112 @@ func (h textHandler) Handle(r records.Record) error {
 	return nil
 }
 
-func (h textHandler) asString(attrs []attr.Attr) string {
-	var out = &bytes.Buffer{}
-	for idx, a := range attrs {
+// writeSource writes the source reference of the frame `f` as its file and
+// line (and optionally function), within wrappers
+func (h textHandler) writeSource(b *buffer.Buffer, f runtime.Frame) {
+	b.WriteRune(h.conf.wrapperL)
+	b.WriteString(h.conf.source.Path(f))
+	b.WriteByte(':')
+	*b = strconv.AppendInt(*b, int64(f.Line), 10)
+	if h.conf.source.Function {
+		b.WriteRune(h.conf.whitespace)
+		b.WriteString(f.Function)
+	}
+	b.WriteRune(h.conf.wrapperR)
+	b.WriteRune(h.conf.whitespace)
+}
+
+// Capabilities implements handlers.Capable, returning CapGroups along with the
+// capabilities of the handler's io.Writer
+func (h textHandler) Capabilities() handlers.Capability {
+	return handlers.EncoderCapabilities(handlers.CapGroups, h.w)
+}
+
+// Ping implements handlers.Pinger, checking the health of the handler's
+// io.Writer
+func (h textHandler) Ping(ctx context.Context) error {
+	return handlers.Ping(ctx, h.w)
+}
+
+// Shutdown implements handlers.Shutdowner, shutting down the handler's
+// io.Writer
+func (h textHandler) Shutdown(ctx context.Context) error {
+	return handlers.Shutdown(ctx, h.w)
+}
+
+// writeAttrs writes the input attributes into the Buffer `b`, as a sequence of
+// key-value pairs. With MultilineFold, the multi-line values are written to the
+// Buffer `folded` instead, under their key (prefixed with `prefix`, in groups)
+func (h textHandler) writeAttrs(b *buffer.Buffer, prefix string, attrs []attr.Attr, folded *buffer.Buffer) {
+	first := true
+	for _, a := range attrs {
 		if h.replFn != nil {
 			a = h.replFn(a)
 		}
-		out.WriteString(a.Key())
-		out.WriteString(h.conf.sepKV)
 
-		switch v := (a.Value()).(type) {
+		value := a.Value()
+		if h.conf.multiline != handlers.MultilineRaw {
+			if s, ok := handlers.Multiline(value); ok {
+				if h.conf.multiline == handlers.MultilineFold && folded != nil {
+					*folded = handlers.AppendFolded(*folded, prefix+a.Key(), s)
+					continue
+				}
+				value = handlers.EscapeLines(s)
+			}
+		}
+
+		if !first {
+			b.WriteRune(h.conf.whitespace)
+			b.WriteRune(h.conf.sepAttr)
+			b.WriteRune(h.conf.whitespace)
+		}
+		first = false
+
+		b.WriteString(a.Key())
+		b.WriteString(h.conf.sepKV)
+
+		switch v := value.(type) {
 		case []attr.Attr:
-			out.WriteRune(h.conf.wrapperL)
-			out.WriteRune(h.conf.whitespace)
-			out.WriteString(h.asString(v))
-			out.WriteRune(h.conf.whitespace)
-			out.WriteRune(h.conf.wrapperR)
+			b.WriteRune(h.conf.wrapperL)
+			b.WriteRune(h.conf.whitespace)
+			h.writeAttrs(b, prefix+a.Key()+".", v, folded)
+			b.WriteRune(h.conf.whitespace)
+			b.WriteRune(h.conf.wrapperR)
 		default:
-			out.WriteString(fmt.Sprint(v))
-		}
-		if idx < len(attrs)-1 {
-			out.WriteRune(h.conf.whitespace)
-			out.WriteRune(h.conf.sepAttr)
-			out.WriteRune(h.conf.whitespace)
+			b.WriteValue(v)
 		}
 	}
-	return out.String()
+}
+
+// encodeBound pre-encodes the handler's attributes, to be spliced into every
+// record it handles
+func (h textHandler) encodeBound() textHandler {
+	h.bound = nil
+	h.boundFolded = nil
+	if len(h.attrs) == 0 {
+		return h
+	}
+
+	b := buffer.Get()
+	defer b.Free()
+
+	var folded *buffer.Buffer
+	if h.conf.multiline == handlers.MultilineFold {
+		folded = buffer.Get()
+		defer folded.Free()
+	}
+
+	h.writeAttrs(b, "", h.attrs, folded)
+	h.bound = append([]byte(nil), b.Bytes()...)
+	if folded != nil && folded.Len() > 0 {
+		h.boundFolded = append([]byte(nil), folded.Bytes()...)
+	}
+	return h
 }
 
 // With will spawn a copy of this Handler with the input attributes
-// `attrs`
+// `attrs`. The attributes are encoded once, and the result is reused in
+// every record the returned Handler writes
 func (h textHandler) With(attrs ...attr.Attr) handlers.Handler {
 	return textHandler{
 		w:         h.w,
@@ -146,16 +292,17 @@ func (h textHandler) With(attrs ...attr.Attr) handlers.Handler {
 		replFn:    h.replFn,
 		attrs:     attrs,
 		conf:      h.conf,
-	}
+	}.encodeBound()
 }
 
 // Enabled returns a boolean on whether the Handler is accepting
 // records with log level `level`
 func (h textHandler) Enabled(level level.Level) bool {
-	if h.levelRef == nil || level.Int() >= h.levelRef.Int() {
+	if h.levelRef == nil || level == nil {
 		return true
 	}
-	return false
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
 }
 
 // WithSource will spawn a new copy of this Handler with the setting
@@ -168,12 +315,15 @@ func (h textHandler) WithSource(addSource bool) handlers.Handler {
 		replFn:    h.replFn,
 		attrs:     h.attrs,
 		conf:      h.conf,
+		bound:     h.bound,
+
+		boundFolded: h.boundFolded,
 	}
 }
 
-// WithLevel will spawn a copy of this Handler with the input level `level`
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
 // as a verbosity filter
-func (h textHandler) WithLevel(level level.Level) handlers.Handler {
+func (h textHandler) WithLevel(level level.Leveler) handlers.Handler {
 	return textHandler{
 		w:         h.w,
 		addSource: h.addSource,
@@ -181,6 +331,9 @@ func (h textHandler) WithLevel(level level.Level) handlers.Handler {
 		replFn:    h.replFn,
 		attrs:     h.attrs,
 		conf:      h.conf,
+		bound:     h.bound,
+
+		boundFolded: h.boundFolded,
 	}
 }
 
@@ -194,5 +347,5 @@ func (h textHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) handlers.Hand
 		replFn:    fn,
 		attrs:     h.attrs,
 		conf:      h.conf,
-	}
+	}.encodeBound()
 }
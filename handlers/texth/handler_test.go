@@ -70,7 +70,7 @@ func TestHandle(t *testing.T) {
 
 	t.Run("Simple", func(t *testing.T) {
 		b.Reset()
-		wants := `[2022-11-18T21:21:27+01:00] [info] test message
+		wants := `[2022-11-18T20:21:27Z] [info] test message
 `
 
 		err := h.Handle(r1)
@@ -85,7 +85,7 @@ func TestHandle(t *testing.T) {
 	})
 	t.Run("WithAttribute", func(t *testing.T) {
 		b.Reset()
-		wants := `[2022-11-18T21:21:27+01:00] [info] test message [ a_key: value ]
+		wants := `[2022-11-18T20:21:27Z] [info] test message [ a_key: value ]
 `
 
 		err := h.Handle(r2)
@@ -100,7 +100,7 @@ func TestHandle(t *testing.T) {
 	})
 	t.Run("WithAttributes", func(t *testing.T) {
 		b.Reset()
-		wants := `[2022-11-18T21:21:27+01:00] [info] test message [ a_key: value ; b_test_no: 1 ; c_success_rate: 1 ; d_custom: {custom_key 2} ]
+		wants := `[2022-11-18T20:21:27Z] [info] test message [ a_key: value ; b_test_no: 1 ; c_success_rate: 1 ; d_custom: {custom_key 2} ]
 `
 
 		err := h.Handle(r3)
@@ -115,7 +115,7 @@ func TestHandle(t *testing.T) {
 	})
 	t.Run("WithSliceAttribute", func(t *testing.T) {
 		b.Reset()
-		wants := `[2022-11-18T21:21:27+01:00] [info] test message [ e_slice: [a b c] ]
+		wants := `[2022-11-18T20:21:27Z] [info] test message [ e_slice: [a b c] ]
 `
 
 		err := h.Handle(r5)
@@ -130,7 +130,7 @@ func TestHandle(t *testing.T) {
 	})
 	t.Run("PointerAttr", func(t *testing.T) {
 		b.Reset()
-		wants := `[2022-11-18T21:21:27+01:00] [info] test message [ f_string_pointer: test message ]
+		wants := `[2022-11-18T20:21:27Z] [info] test message [ f_string_pointer: test message ]
 `
 
 		err := h.Handle(r6)
@@ -145,7 +145,7 @@ func TestHandle(t *testing.T) {
 	})
 	t.Run("NilPointerAttr", func(t *testing.T) {
 		b.Reset()
-		wants := `[2022-11-18T21:21:27+01:00] [info] test message [ g_string_pointer_nil: <nil> ]
+		wants := `[2022-11-18T20:21:27Z] [info] test message [ g_string_pointer_nil: <nil> ]
 `
 
 		err := h.Handle(r7)
@@ -173,7 +173,7 @@ func TestHandle(t *testing.T) {
 		}
 	})
 	t.Run("LevelHandlerAttr", func(t *testing.T) {
-		wants := `[2022-11-18T21:21:27+01:00] [info] test message [ k: v ; a_key: value ; b_test_no: 1 ; c_success_rate: 1 ; d_custom: {custom_key 2} ]
+		wants := `[2022-11-18T20:21:27Z] [info] test message [ k: v ; a_key: value ; b_test_no: 1 ; c_success_rate: 1 ; d_custom: {custom_key 2} ]
 `
 		newH := h.With(attr.New("k", "v"))
 
@@ -189,7 +189,7 @@ func TestHandle(t *testing.T) {
 	})
 	t.Run("WithNamespaceAttr", func(t *testing.T) {
 		b.Reset()
-		wants := `[2022-11-18T21:21:27+01:00] [info] test message [ a_key: value ; b_test_no: 1 ; namespace: [ c_success_rate: 1 ; d_custom: {custom_key 2} ] ]
+		wants := `[2022-11-18T20:21:27Z] [info] test message [ a_key: value ; b_test_no: 1 ; namespace: [ c_success_rate: 1 ; d_custom: {custom_key 2} ] ]
 `
 
 		err := h.Handle(r4)
@@ -204,7 +204,7 @@ func TestHandle(t *testing.T) {
 	})
 	t.Run("WithReplFn", func(t *testing.T) {
 		b.Reset()
-		wants := `[2022-11-18T21:21:27+01:00] [info] test message [ a_key: val ]
+		wants := `[2022-11-18T20:21:27Z] [info] test message [ a_key: val ]
 `
 
 		newH := h.WithReplaceFn(func(a attr.Attr) attr.Attr {
@@ -260,3 +260,41 @@ func TestWithSource(t *testing.T) {
 		}
 	})
 }
+
+func TestBoundAttrs(t *testing.T) {
+	b := &bytes.Buffer{}
+	h := New(b)
+
+	t.Run("OnlyBound", func(t *testing.T) {
+		b.Reset()
+		wants := `[2022-11-18T20:21:27Z] [info] test message [ k: v ]
+`
+
+		err := h.With(attr.String("k", "v")).Handle(r1)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		out := b.String()
+		if out != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, out)
+		}
+	})
+	t.Run("OptionsAfterWith", func(t *testing.T) {
+		b.Reset()
+		wants := `[2022-11-18T20:21:27Z] [info] test message [ k=v | a_key=value ]
+`
+
+		newH := WithAttrSeparator(WithKVSeparator(h.With(attr.String("k", "v")), "="), '|')
+
+		err := newH.Handle(r2)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		out := b.String()
+		if out != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, out)
+		}
+	})
+}
@@ -26,8 +26,12 @@ func WithWrapper(h handlers.Handler, leftWrapper, rightWrapper rune) handlers.Ha
 			sepAttr:    textH.conf.sepAttr,
 			whitespace: textH.conf.whitespace,
 			timeFmt:    textH.conf.timeFmt,
+			timeConf:   textH.conf.timeConf,
+			source:     textH.conf.source,
+			template:   textH.conf.template,
+			catalog:    textH.conf.catalog,
 		},
-	}
+	}.encodeBound()
 }
 
 // WithWrapper creates a copy the Handler `h`, with the key-value separator
@@ -55,8 +59,12 @@ func WithKVSeparator(h handlers.Handler, kvSeparator string) handlers.Handler {
 			sepAttr:    textH.conf.sepAttr,
 			whitespace: textH.conf.whitespace,
 			timeFmt:    textH.conf.timeFmt,
+			timeConf:   textH.conf.timeConf,
+			source:     textH.conf.source,
+			template:   textH.conf.template,
+			catalog:    textH.conf.catalog,
 		},
-	}
+	}.encodeBound()
 }
 
 // WithWrapper creates a copy the Handler `h`, with the attribute key-value separator
@@ -80,8 +88,12 @@ func WithAttrSeparator(h handlers.Handler, attrSeparator rune) handlers.Handler
 			sepAttr:    attrSeparator,
 			whitespace: textH.conf.whitespace,
 			timeFmt:    textH.conf.timeFmt,
+			timeConf:   textH.conf.timeConf,
+			source:     textH.conf.source,
+			template:   textH.conf.template,
+			catalog:    textH.conf.catalog,
 		},
-	}
+	}.encodeBound()
 }
 
 // WithWrapper creates a copy the Handler `h`, with the time format string
@@ -108,8 +120,12 @@ func WithTimeFormat(h handlers.Handler, timeFmt string) handlers.Handler {
 			sepAttr:    textH.conf.sepAttr,
 			whitespace: textH.conf.whitespace,
 			timeFmt:    timeFmt,
+			timeConf:   textH.conf.timeConf,
+			source:     textH.conf.source,
+			template:   textH.conf.template,
+			catalog:    textH.conf.catalog,
 		},
-	}
+	}.encodeBound()
 }
 
 // WithWhitespace creates a copy the Handler `h`, with the whitespace rune
@@ -133,6 +149,137 @@ func WithWhitespace(h handlers.Handler, whitespace rune) handlers.Handler {
 			sepAttr:    textH.conf.sepAttr,
 			whitespace: whitespace,
 			timeFmt:    textH.conf.timeFmt,
+			timeConf:   textH.conf.timeConf,
+			source:     textH.conf.source,
+			template:   textH.conf.template,
+			catalog:    textH.conf.catalog,
 		},
+	}.encodeBound()
+}
+
+// WithTemplate creates a copy the Handler `h`, which renders the `{key}`
+// placeholders in the records' messages with the value of the attribute with
+// that key, if `enabled` is true. The attributes are still written as usual.
+// Returns nil if the Handler is not a textHandler
+func WithTemplate(h handlers.Handler, enabled bool) handlers.Handler {
+	textH, ok := (h).(textHandler)
+	if !ok {
+		return nil
+	}
+
+	conf := textH.conf
+	conf.template = enabled
+
+	return textHandler{
+		w:         textH.w,
+		addSource: textH.addSource,
+		levelRef:  textH.levelRef,
+		replFn:    textH.replFn,
+		attrs:     textH.attrs,
+		conf:      conf,
+		bound:     textH.bound,
+
+		boundFolded: textH.boundFolded,
+	}
+}
+
+// WithTimeConfig creates a copy the Handler `h`, which renders the records'
+// timestamps as configured by the TimeConfig `timeConf`, replacing the time format
+// string with the RFC3339 layout for its precision. Returns nil if the Handler
+// is not a textHandler
+func WithTimeConfig(h handlers.Handler, timeConf handlers.TimeConfig) handlers.Handler {
+	textH, ok := (h).(textHandler)
+	if !ok {
+		return nil
+	}
+
+	conf := textH.conf
+	conf.timeFmt = timeConf.Layout()
+	conf.timeConf = timeConf
+
+	return textHandler{
+		w:         textH.w,
+		addSource: textH.addSource,
+		levelRef:  textH.levelRef,
+		replFn:    textH.replFn,
+		attrs:     textH.attrs,
+		conf:      conf,
+		bound:     textH.bound,
+
+		boundFolded: textH.boundFolded,
+	}
+}
+
+// WithSourceConfig creates a copy the Handler `h`, which renders the source
+// references (added with WithSource) as configured by the SourceConfig `conf`.
+// Returns nil if the Handler is not a textHandler
+func WithSourceConfig(h handlers.Handler, conf handlers.SourceConfig) handlers.Handler {
+	textH, ok := (h).(textHandler)
+	if !ok {
+		return nil
+	}
+
+	textConf := textH.conf
+	textConf.source = conf
+
+	return textHandler{
+		w:         textH.w,
+		addSource: textH.addSource,
+		levelRef:  textH.levelRef,
+		replFn:    textH.replFn,
+		attrs:     textH.attrs,
+		conf:      textConf,
+		bound:     textH.bound,
+
+		boundFolded: textH.boundFolded,
 	}
 }
+
+// WithCatalog creates a copy the Handler `h`, which renders the records'
+// messages as localized by the Catalog `catalog`. With WithTemplate, the
+// localized messages' placeholders are replaced as well. Returns nil if the
+// Handler is not a textHandler
+func WithCatalog(h handlers.Handler, catalog handlers.Catalog) handlers.Handler {
+	textH, ok := (h).(textHandler)
+	if !ok {
+		return nil
+	}
+
+	conf := textH.conf
+	conf.catalog = catalog
+
+	return textHandler{
+		w:         textH.w,
+		addSource: textH.addSource,
+		levelRef:  textH.levelRef,
+		replFn:    textH.replFn,
+		attrs:     textH.attrs,
+		conf:      conf,
+		bound:     textH.bound,
+
+		boundFolded: textH.boundFolded,
+	}
+}
+
+// WithMultiline creates a copy the Handler `h`, which renders the multi-line
+// messages and values (like stack traces) in the MultilineMode `mode`: as they
+// are, with their line breaks escaped, or folded below the record's line.
+// Returns nil if the Handler is not a textHandler
+func WithMultiline(h handlers.Handler, mode handlers.MultilineMode) handlers.Handler {
+	textH, ok := (h).(textHandler)
+	if !ok {
+		return nil
+	}
+
+	conf := textH.conf
+	conf.multiline = mode
+
+	return textHandler{
+		w:         textH.w,
+		addSource: textH.addSource,
+		levelRef:  textH.levelRef,
+		replFn:    textH.replFn,
+		attrs:     textH.attrs,
+		conf:      conf,
+	}.encodeBound()
+}
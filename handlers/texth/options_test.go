@@ -2,8 +2,14 @@ package texth
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
 )
 
 func TestWithWrapper(t *testing.T) {
@@ -122,3 +128,180 @@ func TestWithWhitespace(t *testing.T) {
 		}
 	})
 }
+
+func TestWithTemplate(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithTemplate(New(b), true).With(attr.String("service", "api"))
+
+		err := h.Handle(records.New(ts, level.Info, "user {user_id} logged in to {service} {missing}",
+			attr.String("user_id", "gopher"),
+		))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		wants := "[2024-01-01T00:00:00Z] [info] user gopher logged in to api {missing} [ service: api ; user_id: gopher ]\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("ReplaceFn", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithTemplate(New(b), true).WithReplaceFn(func(a attr.Attr) attr.Attr {
+			if a.Key() == "token" {
+				return attr.String("token", "***")
+			}
+			return a
+		})
+
+		_ = h.Handle(records.New(ts, level.Info, "token {token}", attr.String("token", "secret")))
+
+		if bytes.Contains(b.Bytes(), []byte("secret")) {
+			t.Errorf("output mismatch error: wanted the replaced value ; got %q", b.String())
+		}
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithTemplate(New(b), false)
+
+		_ = h.Handle(records.New(ts, level.Info, "user {user_id}", attr.String("user_id", "gopher")))
+
+		if !bytes.Contains(b.Bytes(), []byte("user {user_id}")) {
+			t.Errorf("output mismatch error: wanted the raw message ; got %q", b.String())
+		}
+	})
+	t.Run("Fail", func(t *testing.T) {
+		if wh := WithTemplate(nil, true); wh != nil {
+			t.Errorf("expected output to be nil")
+		}
+	})
+}
+
+func TestWithCatalog(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	catalog := handlers.Messages(map[string]string{
+		"file downloaded": "Datei {name} heruntergeladen",
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithCatalog(New(b), catalog)
+
+		_ = h.Handle(records.New(ts, level.Info, "file downloaded", attr.String("name", "a.txt")))
+		_ = h.Handle(records.New(ts, level.Info, "file deleted"))
+
+		wants := "[2024-01-01T00:00:00Z] [info] Datei a.txt heruntergeladen [ name: a.txt ]\n" +
+			"[2024-01-01T00:00:00Z] [info] file deleted\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("NotTextHandler", func(t *testing.T) {
+		if h := WithCatalog(handlers.Unimpl(), catalog); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+}
+
+func TestWithTimeConfig(t *testing.T) {
+	ts := time.Date(2023, 11, 14, 22, 13, 20, 123456789, time.UTC)
+
+	t.Run("Precision", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithTimeConfig(New(b), handlers.TimeConfig{Precision: handlers.PrecisionMilli})
+
+		if err := h.Handle(records.New(ts, level.Info, "msg")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if wants := "[2023-11-14T22:13:20.123Z] [info] msg\n"; b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("Epoch", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithTimeConfig(New(b), handlers.TimeConfig{Precision: handlers.PrecisionSecond, Epoch: true})
+
+		if err := h.With(attr.String("k", "v")).Handle(records.New(ts, level.Info, "msg")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if wants := "[1700000000] [info] msg [ k: v ]\n"; b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("Fail", func(t *testing.T) {
+		if h := WithTimeConfig(nil, handlers.TimeConfig{}); h != nil {
+			t.Errorf("expected output to be nil")
+		}
+	})
+}
+
+func TestWithSourceConfig(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithSourceConfig(New(b).WithSource(true), handlers.SourceConfig{Elements: 1, Function: true})
+
+		if err := h.Handle(records.New(time.Now(), level.Info, "msg")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		wants := "] [info] [options_test.go:"
+		if !strings.Contains(b.String(), wants) ||
+			!strings.Contains(b.String(), " github.com/zalgonoise/logx/handlers/texth.TestWithSourceConfig.func1] msg\n") {
+			t.Errorf("output mismatch error: wanted the caller's source ; got %q", b.String())
+		}
+	})
+	t.Run("Fail", func(t *testing.T) {
+		if h := WithSourceConfig(nil, handlers.SourceConfig{}); h != nil {
+			t.Errorf("expected output to be nil")
+		}
+	})
+}
+
+func TestWithMultiline(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stack := "goroutine 1 [running]:\nmain.main()\n"
+
+	t.Run("Escape", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithMultiline(New(b), handlers.MultilineEscape)
+
+		_ = h.Handle(records.New(ts, level.Error, "request\nfailed", attr.String("stack", stack)))
+
+		wants := "[2024-01-01T00:00:00Z] [error] request\\nfailed [ stack: goroutine 1 [running]:\\nmain.main()\\n ]\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("Fold", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := WithMultiline(New(b), handlers.MultilineFold).With(attr.String("trace", "a\r\nb"))
+
+		_ = h.Handle(records.New(ts, level.Error, "request failed\nafter 3 retries",
+			attr.String("path", "/items"),
+			attr.New("panic", []attr.Attr{attr.String("stack", stack)}),
+		))
+
+		wants := "[2024-01-01T00:00:00Z] [error] request failed [ path: /items ; panic: [  ] ]\n" +
+			"  after 3 retries\n" +
+			"  panic.stack:\n" +
+			"    goroutine 1 [running]:\n" +
+			"    main.main()\n" +
+			"  trace:\n" +
+			"    a\n" +
+			"    b\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("NotTextHandler", func(t *testing.T) {
+		if h := WithMultiline(handlers.Unimpl(), handlers.MultilineFold); h != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", h)
+		}
+	})
+}
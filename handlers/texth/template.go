@@ -0,0 +1,56 @@
+package texth
+
+import (
+	"strings"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/buffer"
+)
+
+// writeTemplate writes the message `msg` into the Buffer `b`, replacing its
+// `{key}` placeholders with the value of the matching attribute, looked up in
+// the record's attributes `attrs` and then in the handler's bound ones.
+// Placeholders without a matching attribute are written as-is
+func (h textHandler) writeTemplate(b *buffer.Buffer, msg string, attrs []attr.Attr) {
+	for {
+		start := strings.IndexByte(msg, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(msg[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		b.WriteString(msg[:start])
+		if v, ok := h.lookup(msg[start+1:end], attrs); ok {
+			b.WriteValue(v)
+		} else {
+			b.WriteString(msg[start : end+1])
+		}
+		msg = msg[end+1:]
+	}
+	b.WriteString(msg)
+}
+
+func (h textHandler) lookup(key string, attrs []attr.Attr) (any, bool) {
+	if key == "" {
+		return nil, false
+	}
+
+	for _, list := range [2][]attr.Attr{attrs, h.attrs} {
+		for _, a := range list {
+			if a == nil || a.Key() != key {
+				continue
+			}
+			if h.replFn != nil {
+				if a = h.replFn(a); a == nil {
+					return nil, false
+				}
+			}
+			return a.Value(), true
+		}
+	}
+	return nil, false
+}
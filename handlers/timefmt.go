@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+)
+
+// TimePrecision is the resolution that a Handler renders timestamps with
+type TimePrecision int
+
+const (
+	// PrecisionNano renders timestamps with up to nanosecond resolution, as
+	// time.RFC3339Nano does (trimming trailing zeros). It is the default
+	PrecisionNano TimePrecision = iota
+	// PrecisionMicro renders timestamps with microsecond resolution
+	PrecisionMicro
+	// PrecisionMilli renders timestamps with millisecond resolution
+	PrecisionMilli
+	// PrecisionSecond renders timestamps with second resolution
+	PrecisionSecond
+)
+
+// TimeConfig describes how a Handler renders the records' timestamps, for
+// ingestion systems that reject or truncate RFC3339 strings with nanoseconds
+//
+// The zero value renders RFC3339 timestamps with nanoseconds in UTC, like the
+// Handlers do by default
+type TimeConfig struct {
+	// Precision is the resolution of the timestamps. RFC3339 timestamps with
+	// a precision other than PrecisionNano have a fixed number of fractional
+	// digits
+	Precision TimePrecision
+	// Epoch renders the timestamps as a number of units (of the Precision)
+	// since the Unix epoch, like 1700000000123 for PrecisionMilli, instead of
+	// an RFC3339 string
+	Epoch bool
+	// Location is the time zone that RFC3339 timestamps are rendered in, like
+	// time.Local or one loaded with time.LoadLocation, regardless of the
+	// host's. If unset, timestamps are rendered in UTC
+	Location *time.Location
+}
+
+// Convert returns the timestamp `t` in the TimeConfig's time zone
+func (c TimeConfig) Convert(t time.Time) time.Time {
+	if c.Location == nil {
+		return t.UTC()
+	}
+	return t.In(c.Location)
+}
+
+// Layout returns the RFC3339 layout for the TimeConfig's precision
+func (c TimeConfig) Layout() string {
+	switch c.Precision {
+	case PrecisionMicro:
+		return "2006-01-02T15:04:05.000000Z07:00"
+	case PrecisionMilli:
+		return "2006-01-02T15:04:05.000Z07:00"
+	case PrecisionSecond:
+		return time.RFC3339
+	default:
+		return time.RFC3339Nano
+	}
+}
+
+// AppendTime appends the timestamp `t` to `b`, as configured, returning the
+// extended buffer. Epoch timestamps are appended as a number, and RFC3339 ones
+// without quotes
+func (c TimeConfig) AppendTime(b []byte, t time.Time) []byte {
+	if !c.Epoch {
+		return c.Convert(t).AppendFormat(b, c.Layout())
+	}
+
+	switch c.Precision {
+	case PrecisionMicro:
+		return strconv.AppendInt(b, t.UnixMicro(), 10)
+	case PrecisionMilli:
+		return strconv.AppendInt(b, t.UnixMilli(), 10)
+	case PrecisionSecond:
+		return strconv.AppendInt(b, t.Unix(), 10)
+	default:
+		return strconv.AppendInt(b, t.UnixNano(), 10)
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeConfig(t *testing.T) {
+	// the default time zone is UTC, regardless of the timestamp's
+	ts := time.Date(2023, 11, 14, 23, 13, 20, 123456789, time.FixedZone("", 3600))
+
+	for _, test := range []struct {
+		name  string
+		conf  TimeConfig
+		wants string
+	}{
+		{"Default", TimeConfig{}, "2023-11-14T22:13:20.123456789Z"},
+		{"Micro", TimeConfig{Precision: PrecisionMicro}, "2023-11-14T22:13:20.123456Z"},
+		{"Milli", TimeConfig{Precision: PrecisionMilli}, "2023-11-14T22:13:20.123Z"},
+		{"Second", TimeConfig{Precision: PrecisionSecond}, "2023-11-14T22:13:20Z"},
+		{"EpochNano", TimeConfig{Epoch: true}, "1700000000123456789"},
+		{"EpochMicro", TimeConfig{Precision: PrecisionMicro, Epoch: true}, "1700000000123456"},
+		{"EpochMilli", TimeConfig{Precision: PrecisionMilli, Epoch: true}, "1700000000123"},
+		{"EpochSecond", TimeConfig{Precision: PrecisionSecond, Epoch: true}, "1700000000"},
+		{"Location", TimeConfig{Precision: PrecisionSecond, Location: time.FixedZone("", -5*3600)}, "2023-11-14T17:13:20-05:00"},
+		{"LocationEpoch", TimeConfig{Precision: PrecisionSecond, Epoch: true, Location: time.FixedZone("", 3600)}, "1700000000"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := string(test.conf.AppendTime(nil, ts)); got != test.wants {
+				t.Errorf("output mismatch error: wanted %s ; got %s", test.wants, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Package tinyh provides a minimal text Handler for constrained targets, like
+// TinyGo builds and microcontrollers. Records are formatted into a static,
+// fixed-size buffer with no reflection, and with no allocations for the common
+// attribute types, as lines like:
+//
+//	2024-01-01T00:00:00Z info connected addr=10.0.0.2 retry.count=3
+//
+// It is the default Handler of the logx_tiny build profile, which leaves out
+// the network, compression, encryption, template and test handlers (the
+// remaining handlers still depend on reflect and encoding/json):
+//
+//	tinygo build -tags logx_tiny ./...
+package tinyh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	// BufferSize is the maximum length of a line, including its line break.
+	// Longer lines are cut short
+	BufferSize = 512
+
+	tFmt = time.RFC3339
+)
+
+var (
+	// ErrZeroBytes is raised when the `io.Writer` in the handler
+	// returns a zero-length of bytes written, when the `Write()`
+	// method is called
+	ErrZeroBytes error = errors.New("zero bytes written")
+)
+
+type tinyHandler struct {
+	w        io.Writer
+	levelRef level.Leveler
+	replFn   func(a attr.Attr) attr.Attr
+	attrs    []attr.Attr
+	out      *output
+}
+
+// output holds the static buffers that records are formatted into, shared by
+// the handlers derived from the same one, and guarded by its mutex
+type output struct {
+	mu      sync.Mutex
+	buf     [BufferSize]byte
+	scratch [64]byte
+	n       int
+}
+
+// New creates a tiny text handler based on the input io.Writer `w`
+func New(w io.Writer) handlers.Handler {
+	if w == nil {
+		return nil
+	}
+	return tinyHandler{
+		w:   w,
+		out: &output{},
+	}
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h tinyHandler) Handle(r records.Record) error {
+	if !h.Enabled(r.Level()) {
+		return nil
+	}
+
+	o := h.out
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.n = 0
+	o.writeBytes(r.Time().AppendFormat(o.scratch[:0], tFmt))
+	o.writeByte(' ')
+	if lv := r.Level(); lv != nil {
+		o.writeString(lv.String())
+		o.writeByte(' ')
+	}
+	o.writeString(r.Message())
+
+	var prefix [8]string
+	h.writeAttrs(prefix[:0], h.attrs)
+	h.writeAttrs(prefix[:0], r.Attrs())
+
+	// the last byte is always kept for the line break
+	o.buf[o.n] = '\n'
+	n, err := h.w.Write(o.buf[:o.n+1])
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrZeroBytes
+	}
+
+	return nil
+}
+
+// writeAttrs writes the input attributes as a sequence of key=value pairs,
+// with the keys of the attributes in groups prefixed by the groups' keys
+func (h tinyHandler) writeAttrs(prefix []string, attrs []attr.Attr) {
+	for _, a := range attrs {
+		if h.replFn != nil && a != nil {
+			a = h.replFn(a)
+		}
+		if a == nil {
+			continue
+		}
+
+		if group, ok := a.Value().([]attr.Attr); ok {
+			h.writeAttrs(append(prefix, a.Key()), group)
+			continue
+		}
+
+		h.out.writeByte(' ')
+		for _, key := range prefix {
+			h.out.writeString(key)
+			h.out.writeByte('.')
+		}
+		h.out.writeString(a.Key())
+		h.out.writeByte('=')
+		h.out.writeValue(a.Value())
+	}
+}
+
+// writeValue writes the value `v`, formatted with no reflection. Values of
+// types other than the built-in ones, errors and Stringers are written as `?`
+func (o *output) writeValue(v any) {
+	switch v := v.(type) {
+	case string:
+		o.writeString(v)
+	case bool:
+		o.writeBytes(strconv.AppendBool(o.scratch[:0], v))
+	case int:
+		o.writeBytes(strconv.AppendInt(o.scratch[:0], int64(v), 10))
+	case int8:
+		o.writeBytes(strconv.AppendInt(o.scratch[:0], int64(v), 10))
+	case int16:
+		o.writeBytes(strconv.AppendInt(o.scratch[:0], int64(v), 10))
+	case int32:
+		o.writeBytes(strconv.AppendInt(o.scratch[:0], int64(v), 10))
+	case int64:
+		o.writeBytes(strconv.AppendInt(o.scratch[:0], v, 10))
+	case uint:
+		o.writeBytes(strconv.AppendUint(o.scratch[:0], uint64(v), 10))
+	case uint8:
+		o.writeBytes(strconv.AppendUint(o.scratch[:0], uint64(v), 10))
+	case uint16:
+		o.writeBytes(strconv.AppendUint(o.scratch[:0], uint64(v), 10))
+	case uint32:
+		o.writeBytes(strconv.AppendUint(o.scratch[:0], uint64(v), 10))
+	case uint64:
+		o.writeBytes(strconv.AppendUint(o.scratch[:0], v, 10))
+	case float32:
+		o.writeBytes(strconv.AppendFloat(o.scratch[:0], float64(v), 'g', -1, 32))
+	case float64:
+		o.writeBytes(strconv.AppendFloat(o.scratch[:0], v, 'g', -1, 64))
+	case time.Time:
+		o.writeBytes(v.AppendFormat(o.scratch[:0], tFmt))
+	case error:
+		o.writeString(v.Error())
+	case interface{ String() string }:
+		o.writeString(v.String())
+	case nil:
+		o.writeString("<nil>")
+	default:
+		o.writeByte('?')
+	}
+}
+
+// writeString writes the string `s` into the buffer, dropping the bytes that
+// do not fit
+func (o *output) writeString(s string) {
+	o.n += copy(o.buf[o.n:BufferSize-1], s)
+}
+
+// writeBytes writes the bytes `p` into the buffer, dropping the ones that do
+// not fit
+func (o *output) writeBytes(p []byte) {
+	o.n += copy(o.buf[o.n:BufferSize-1], p)
+}
+
+// writeByte writes the byte `c` into the buffer, if it fits
+func (o *output) writeByte(c byte) {
+	if o.n < BufferSize-1 {
+		o.buf[o.n] = c
+		o.n++
+	}
+}
+
+// Capabilities implements handlers.Capable, returning CapGroups (as groups are
+// flattened with dotted keys) along with the capabilities of the handler's
+// io.Writer
+func (h tinyHandler) Capabilities() handlers.Capability {
+	return handlers.EncoderCapabilities(handlers.CapGroups, h.w)
+}
+
+// Ping implements handlers.Pinger, checking the health of the handler's
+// io.Writer
+func (h tinyHandler) Ping(ctx context.Context) error {
+	return handlers.Ping(ctx, h.w)
+}
+
+// Shutdown implements handlers.Shutdowner, shutting down the handler's
+// io.Writer
+func (h tinyHandler) Shutdown(ctx context.Context) error {
+	return handlers.Shutdown(ctx, h.w)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h tinyHandler) With(attrs ...attr.Attr) handlers.Handler {
+	return tinyHandler{
+		w:        h.w,
+		levelRef: h.levelRef,
+		replFn:   h.replFn,
+		attrs:    attrs,
+		out:      h.out,
+	}
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h tinyHandler) Enabled(level level.Level) bool {
+	if h.levelRef == nil || level == nil {
+		return true
+	}
+	ref := h.levelRef.Level()
+	return ref == nil || level.Int() >= ref.Int()
+}
+
+// WithSource returns this Handler as-is, as source references are not
+// supported, to keep the runtime's symbol tables out of the hot path
+func (h tinyHandler) WithSource(addSource bool) handlers.Handler {
+	return h
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (h tinyHandler) WithLevel(level level.Leveler) handlers.Handler {
+	return tinyHandler{
+		w:        h.w,
+		levelRef: level,
+		replFn:   h.replFn,
+		attrs:    h.attrs,
+		out:      h.out,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h tinyHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) handlers.Handler {
+	return tinyHandler{
+		w:        h.w,
+		levelRef: h.levelRef,
+		replFn:   fn,
+		attrs:    h.attrs,
+		out:      h.out,
+	}
+}
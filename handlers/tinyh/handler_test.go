@@ -0,0 +1,136 @@
+package tinyh
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+var testTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestHandle(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		r      records.Record
+		wants  string
+		attrs  []attr.Attr
+		replFn func(attr.Attr) attr.Attr
+	}{
+		{
+			name:  "Message",
+			r:     records.New(testTime, level.Info, "connected"),
+			wants: "2024-01-01T00:00:00Z info connected\n",
+		},
+		{
+			name: "Attrs",
+			r: records.New(testTime, level.Warn, "retrying",
+				attr.String("addr", "10.0.0.2"),
+				attr.Int("count", 3),
+				attr.Float("ratio", 0.5),
+				attr.New("ok", false),
+				attr.New("err", errors.New("timeout")),
+				attr.New("wait", 2*time.Second),
+				attr.New("opaque", struct{}{}),
+			),
+			wants: "2024-01-01T00:00:00Z warn retrying addr=10.0.0.2 count=3 ratio=0.5 ok=false err=timeout wait=2s opaque=?\n",
+		},
+		{
+			name: "Groups",
+			r: records.New(testTime, level.Info, "done",
+				attr.New("req", []attr.Attr{
+					attr.String("id", "a1"),
+					attr.New("db", []attr.Attr{attr.Int("calls", 2)}),
+				}),
+			),
+			wants: "2024-01-01T00:00:00Z info done req.id=a1 req.db.calls=2\n",
+		},
+		{
+			name:  "BoundAttrs",
+			r:     records.New(testTime, level.Info, "done", attr.Int("n", 1)),
+			attrs: []attr.Attr{attr.String("service", "sensor")},
+			wants: "2024-01-01T00:00:00Z info done service=sensor n=1\n",
+		},
+		{
+			name: "ReplaceFn",
+			r:    records.New(testTime, level.Info, "login", attr.String("password", "hunter2")),
+			replFn: func(a attr.Attr) attr.Attr {
+				if a.Key() == "password" {
+					return attr.String("password", "***")
+				}
+				return a
+			},
+			wants: "2024-01-01T00:00:00Z info login password=***\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := New(buf)
+			if test.attrs != nil {
+				h = h.With(test.attrs...)
+			}
+			if test.replFn != nil {
+				h = h.WithReplaceFn(test.replFn)
+			}
+
+			if err := h.Handle(test.r); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if buf.String() != test.wants {
+				t.Errorf("output mismatch error: wanted %q ; got %q", test.wants, buf.String())
+			}
+		})
+	}
+
+	t.Run("LongLine", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := New(buf)
+
+		if err := h.Handle(records.New(testTime, level.Info, strings.Repeat("x", 2*BufferSize))); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if buf.Len() != BufferSize || !strings.HasSuffix(buf.String(), "x\n") {
+			t.Errorf("output mismatch error: wanted a %v-byte line ; got %v bytes", BufferSize, buf.Len())
+		}
+	})
+
+	t.Run("Level", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := New(buf).WithLevel(level.Warn)
+
+		_ = h.Handle(records.New(testTime, level.Info, "skipped"))
+		if buf.Len() != 0 {
+			t.Errorf("output mismatch error: wanted no output ; got %q", buf.String())
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	if h := New(nil); h != nil {
+		t.Errorf("output mismatch error: wanted nil ; got %v", h)
+	}
+}
+
+func BenchmarkHandle(b *testing.B) {
+	h := New(discard{})
+	r := records.New(testTime, level.Info, "event", attr.String("addr", "10.0.0.2"), attr.Int("count", 3))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = h.Handle(r)
+	}
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -0,0 +1,127 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+)
+
+var (
+	// ErrInvalidCA is raised when a TLSConfig's CA bundle holds no PEM
+	// certificates
+	ErrInvalidCA error = errors.New("no valid PEM certificates in CA bundle")
+	// ErrIncompleteKeyPair is raised when a TLSConfig sets a client certificate
+	// without its private key, or vice-versa
+	ErrIncompleteKeyPair error = errors.New("client certificate and key must be set together")
+)
+
+// TLSConfig describes the TLS settings of the connections to a remote sink,
+// like a log collector over TCP or an HTTP intake endpoint, and is shared by
+// the handlers and writers that connect to one.
+//
+// Certificates and keys are read either from PEM files or from PEM-encoded
+// bytes; the latter take precedence when both are set
+type TLSConfig struct {
+	// CAFile is the path to a PEM bundle of the certificate authorities that
+	// the sink's certificate is verified against. If unset (and CAPEM as
+	// well), the system's roots are used
+	CAFile string
+	// CAPEM is a PEM bundle of certificate authorities, as an alternative to
+	// CAFile
+	CAPEM []byte
+
+	// CertFile and KeyFile are the paths to the PEM-encoded client certificate
+	// and private key presented to the sink, for mutual TLS
+	CertFile string
+	KeyFile  string
+	// CertPEM and KeyPEM are the PEM-encoded client certificate and private
+	// key, as an alternative to CertFile and KeyFile
+	CertPEM []byte
+	KeyPEM  []byte
+
+	// ServerName is the host name sent in the SNI extension and verified
+	// against the sink's certificate. If unset, the host of the address
+	// being dialed is used
+	ServerName string
+	// MinVersion is the minimum TLS version accepted, defaulting to TLS 1.2
+	MinVersion uint16
+	// InsecureSkipVerify disables the verification of the sink's certificate,
+	// and should only be used in tests
+	InsecureSkipVerify bool
+}
+
+// Build creates a *tls.Config from the TLSConfig, loading its CA bundle and
+// client key pair. An error is returned if any of them cannot be read or
+// parsed
+func (c TLSConfig) Build() (*tls.Config, error) {
+	conf := &tls.Config{
+		ServerName:         c.ServerName,
+		MinVersion:         c.MinVersion,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if conf.MinVersion == 0 {
+		conf.MinVersion = tls.VersionTLS12
+	}
+
+	ca := c.CAPEM
+	if len(ca) == 0 && c.CAFile != "" {
+		var err error
+		if ca, err = os.ReadFile(c.CAFile); err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %w", err)
+		}
+	}
+	if len(ca) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, ErrInvalidCA
+		}
+		conf.RootCAs = pool
+	}
+
+	cert, key := c.CertPEM, c.KeyPEM
+	if len(cert) == 0 && c.CertFile != "" {
+		var err error
+		if cert, err = os.ReadFile(c.CertFile); err != nil {
+			return nil, fmt.Errorf("reading client certificate: %w", err)
+		}
+	}
+	if len(key) == 0 && c.KeyFile != "" {
+		var err error
+		if key, err = os.ReadFile(c.KeyFile); err != nil {
+			return nil, fmt.Errorf("reading client key: %w", err)
+		}
+	}
+
+	switch {
+	case len(cert) == 0 && len(key) == 0:
+	case len(cert) == 0, len(key) == 0:
+		return nil, ErrIncompleteKeyPair
+	default:
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("loading client key pair: %w", err)
+		}
+		conf.Certificates = []tls.Certificate{pair}
+	}
+
+	return conf, nil
+}
+
+// DialTLS connects to the address `addr` on the network `network` (like
+// "tcp"), performing a TLS handshake with the settings in the TLSConfig
+// `conf`. Dialing and the handshake are bound to the context `ctx`
+func DialTLS(ctx context.Context, network, addr string, conf TLSConfig) (net.Conn, error) {
+	tlsConf, err := conf.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &tls.Dialer{Config: tlsConf}
+	return dialer.DialContext(ctx, network, addr)
+}
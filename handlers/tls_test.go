@@ -0,0 +1,164 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestCert creates a self-signed certificate for the host `host`, returning
+// it and its private key PEM-encoded
+func newTestCert(t *testing.T, host string) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: host},
+		DNSNames:              []string{host},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestTLSConfig(t *testing.T) {
+	serverCert, serverKey := newTestCert(t, "logs.example.com")
+	clientCert, clientKey := newTestCert(t, "client")
+
+	pair, err := tls.X509KeyPair(serverCert, serverKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(clientCert)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ln.Close()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	for path, data := range map[string][]byte{caFile: serverCert, certFile: clientCert, keyFile: clientKey} {
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	dial := func(conf TLSConfig) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		conn, err := DialTLS(ctx, "tcp", ln.Addr().String(), conf)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		// the server verifies the client certificate after the client's
+		// handshake, and closes the connection once done
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		if _, err = conn.Read(make([]byte, 1)); errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	t.Run("MutualTLSFromFiles", func(t *testing.T) {
+		err := dial(TLSConfig{
+			CAFile:     caFile,
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+			ServerName: "logs.example.com",
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("MutualTLSFromPEM", func(t *testing.T) {
+		conf, err := TLSConfig{CAPEM: serverCert, CertPEM: clientCert, KeyPEM: clientKey}.Build()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if conf.RootCAs == nil || len(conf.Certificates) != 1 || conf.MinVersion != tls.VersionTLS12 {
+			t.Errorf("output mismatch error: wanted a CA pool, a client certificate and TLS 1.2 ; got %v", conf)
+		}
+	})
+	t.Run("WrongServerName", func(t *testing.T) {
+		err := dial(TLSConfig{CAPEM: serverCert, CertPEM: clientCert, KeyPEM: clientKey, ServerName: "other.example.com"})
+		var hostErr x509.HostnameError
+		if !errors.As(err, &hostErr) {
+			t.Errorf("unexpected error: wanted a hostname error ; got %v", err)
+		}
+	})
+	t.Run("UnknownCA", func(t *testing.T) {
+		err := dial(TLSConfig{ServerName: "logs.example.com"})
+		if err == nil {
+			t.Errorf("expected an error verifying the server certificate with the system roots")
+		}
+	})
+	t.Run("InvalidCA", func(t *testing.T) {
+		if _, err := (TLSConfig{CAPEM: []byte("not a certificate")}).Build(); !errors.Is(err, ErrInvalidCA) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrInvalidCA, err)
+		}
+	})
+	t.Run("IncompleteKeyPair", func(t *testing.T) {
+		if _, err := (TLSConfig{CertFile: certFile}).Build(); !errors.Is(err, ErrIncompleteKeyPair) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrIncompleteKeyPair, err)
+		}
+	})
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	defaultMaxRecordSize = 256 << 10
+
+	// TruncatedKey is the key of the attribute added to truncated records,
+	// holding the number of attributes dropped from them
+	TruncatedKey = "truncated"
+)
+
+// TruncateConfig configures the size limit enforced by a Truncator
+type TruncateConfig struct {
+	// MaxBytes is the maximum size of a record, defaulting to 256 KiB
+	MaxBytes int
+	// Size returns the size of the Record `r`, as encoded by the wrapped
+	// Handler. It defaults to an estimate, as the length of the record's
+	// message and of its attributes' keys and values, as text; which leaves
+	// out the encoding's overhead (quotes, separators, timestamp and level)
+	Size func(r records.Record) int
+}
+
+// Truncator is a Handler decorator that keeps the records it handles under a
+// maximum size, so that oversize records are truncated rather than rejected
+// by the downstream agents (which commonly cap lines at 256 KiB).
+//
+// Oversize records have their largest attributes dropped, one at a time, until
+// they fit; with ties broken by their order. The number of attributes dropped
+// is added as a TruncatedKey attribute. If the message alone does not fit, it
+// is cut short as well. Attributes bound to the wrapped Handler (with With)
+// count towards the size, but are never dropped.
+//
+// Handlers derived from a Truncator (with its With* methods) share its
+// counters
+type Truncator struct {
+	truncateHandler
+}
+
+type truncateHandler struct {
+	h         Handler
+	conf      TruncateConfig
+	boundSize int
+	c         *truncateCounters
+}
+
+type truncateCounters struct {
+	records   atomic.Uint64
+	bytes     atomic.Uint64
+	truncated atomic.Uint64
+}
+
+// NewTruncator creates a Truncator for the Handler `h`, as configured by the
+// TruncateConfig `conf`
+func NewTruncator(h Handler, conf TruncateConfig) *Truncator {
+	if h == nil {
+		h = Unimpl()
+	}
+	if conf.MaxBytes <= 0 {
+		conf.MaxBytes = defaultMaxRecordSize
+	}
+	if conf.Size == nil {
+		conf.Size = recordSize
+	}
+
+	return &Truncator{
+		truncateHandler: truncateHandler{
+			h:    h,
+			conf: conf,
+			c:    &truncateCounters{},
+		},
+	}
+}
+
+// Records returns the number of records handled so far
+func (t *Truncator) Records() uint64 {
+	return t.c.records.Load()
+}
+
+// Bytes returns the total size of the records handled so far, after
+// truncation
+func (t *Truncator) Bytes() uint64 {
+	return t.c.bytes.Load()
+}
+
+// Truncated returns the number of records truncated so far
+func (t *Truncator) Truncated() uint64 {
+	return t.c.truncated.Load()
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (t truncateHandler) Enabled(level level.Level) bool {
+	return t.h.Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (t truncateHandler) Handle(r records.Record) error {
+	if !t.h.Enabled(r.Level()) {
+		return nil
+	}
+
+	size := t.conf.Size(r) + t.boundSize
+	if size > t.conf.MaxBytes {
+		r, size = t.truncate(r)
+		t.c.truncated.Add(1)
+	}
+
+	t.c.records.Add(1)
+	t.c.bytes.Add(uint64(size))
+	return t.h.Handle(r)
+}
+
+// truncate returns a copy of the Record `r` that fits in the maximum size,
+// along with its size
+func (t truncateHandler) truncate(r records.Record) (records.Record, int) {
+	attrs := append([]attr.Attr{}, r.Attrs()...)
+	sizes := make([]int, len(attrs))
+	for i, a := range attrs {
+		sizes[i] = attrsSize([]attr.Attr{a})
+	}
+
+	var (
+		dropped int
+		msg     = r.Message()
+		marker  = attr.Int(TruncatedKey, 0)
+		out     = records.New(r.Time(), r.Level(), msg, attrs...)
+		size    = t.conf.Size(out) + t.boundSize
+	)
+
+	for size > t.conf.MaxBytes && len(attrs) > 0 {
+		largest := 0
+		for i := range sizes {
+			if sizes[i] > sizes[largest] {
+				largest = i
+			}
+		}
+		attrs = append(attrs[:largest], attrs[largest+1:]...)
+		sizes = append(sizes[:largest], sizes[largest+1:]...)
+		dropped++
+
+		marker = attr.Int(TruncatedKey, dropped)
+		out = records.New(r.Time(), r.Level(), msg, append(attrs[:len(attrs):len(attrs)], marker)...)
+		size = t.conf.Size(out) + t.boundSize
+	}
+
+	if dropped == 0 {
+		out = records.New(r.Time(), r.Level(), msg, append(attrs[:len(attrs):len(attrs)], marker)...)
+		size = t.conf.Size(out) + t.boundSize
+	}
+	if over := size - t.conf.MaxBytes; over > 0 {
+		msg = cut(msg, len(msg)-over)
+		out = records.New(r.Time(), r.Level(), msg, append(attrs[:len(attrs):len(attrs)], marker)...)
+		size = t.conf.Size(out) + t.boundSize
+	}
+
+	return records.WithPC(out, records.PC(r)), size
+}
+
+// cut returns the longest prefix of `s` with at most `n` bytes that does not
+// split a UTF-8 sequence
+func cut(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if n >= len(s) {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (t truncateHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, t.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (t truncateHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, t.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (t truncateHandler) With(attrs ...attr.Attr) Handler {
+	return truncateHandler{
+		h:         t.h.With(attrs...),
+		conf:      t.conf,
+		boundSize: t.boundSize + attrsSize(attrs),
+		c:         t.c,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (t truncateHandler) WithSource(addSource bool) Handler {
+	return truncateHandler{
+		h:         t.h.WithSource(addSource),
+		conf:      t.conf,
+		boundSize: t.boundSize,
+		c:         t.c,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (t truncateHandler) WithLevel(level level.Leveler) Handler {
+	return truncateHandler{
+		h:         t.h.WithLevel(level),
+		conf:      t.conf,
+		boundSize: t.boundSize,
+		c:         t.c,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (t truncateHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return truncateHandler{
+		h:         t.h.WithReplaceFn(fn),
+		conf:      t.conf,
+		boundSize: t.boundSize,
+		c:         t.c,
+	}
+}
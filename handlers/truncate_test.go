@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestTruncator(t *testing.T) {
+	t.Run("DropLargest", func(t *testing.T) {
+		th := newTestHandler()
+		tr := NewTruncator(th, TruncateConfig{MaxBytes: 64})
+
+		err := tr.Handle(records.New(time.Now(), level.Info, "request",
+			attr.String("id", "abc"),
+			attr.String("body", strings.Repeat("x", 100)),
+			attr.String("headers", strings.Repeat("y", 40)),
+		))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		rs := th.Records()
+		if len(rs) != 1 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 1, len(rs))
+			return
+		}
+		keys := make([]string, 0, rs[0].AttrLen())
+		for _, a := range rs[0].Attrs() {
+			keys = append(keys, a.Key())
+		}
+		if strings.Join(keys, ",") != "id,"+TruncatedKey {
+			t.Errorf("output mismatch error: wanted %v ; got %v", []string{"id", TruncatedKey}, keys)
+		}
+		if a := lookup(TruncatedKey, rs[0].Attrs(), nil); a == nil || fmt.Sprint(a.Value()) != "2" {
+			t.Errorf("output mismatch error: wanted %v dropped attributes ; got %v", 2, a)
+		}
+		if tr.Truncated() != 1 || tr.Records() != 1 || tr.Bytes() > 64 {
+			t.Errorf("output mismatch error: wanted one truncated record under 64 bytes ; got %v, %v, %v", tr.Truncated(), tr.Records(), tr.Bytes())
+		}
+	})
+
+	t.Run("Message", func(t *testing.T) {
+		th := newTestHandler()
+		tr := NewTruncator(th, TruncateConfig{MaxBytes: 32})
+
+		_ = tr.Handle(records.New(time.Now(), level.Info, strings.Repeat("é", 40)))
+
+		rs := th.Records()
+		if len(rs) != 1 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 1, len(rs))
+			return
+		}
+		if msg := rs[0].Message(); recordSize(rs[0]) > 32 || !strings.HasPrefix(strings.Repeat("é", 40), msg) {
+			t.Errorf("output mismatch error: wanted a message cut at a rune boundary ; got %q", msg)
+		}
+	})
+
+	t.Run("Fits", func(t *testing.T) {
+		th := newTestHandler()
+		tr := NewTruncator(th, TruncateConfig{})
+
+		r := records.New(time.Now(), level.Info, "small", attr.Int("n", 1))
+		_ = tr.Handle(r)
+
+		if rs := th.Records(); len(rs) != 1 || rs[0].AttrLen() != 1 || tr.Truncated() != 0 {
+			t.Errorf("output mismatch error: wanted the record as-is")
+		}
+	})
+}
@@ -20,7 +20,7 @@ func (u unimplemented) With(attrs ...attr.Attr) Handler {
 func (u unimplemented) WithSource(addSource bool) Handler {
 	return u
 }
-func (u unimplemented) WithLevel(level level.Level) Handler {
+func (u unimplemented) WithLevel(level level.Leveler) Handler {
 	return u
 }
 func (u unimplemented) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
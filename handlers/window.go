@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// WindowConfig configures the time window of a Window Handler
+type WindowConfig struct {
+	// Level is the lowest level that is handled outside of the window: the
+	// records below it are only handled while the window is active. If nil, no
+	// records are handled outside of the window
+	Level level.Level
+	// Start is when the window opens. If zero, the window is open from the
+	// start, until End
+	Start time.Time
+	// End is the deadline when the window closes, and it expires on its own. If
+	// zero, the window does not close, after Start.
+	//
+	// If both Start and End are zero, the window is closed until it is opened
+	// with Open or Set
+	End time.Time
+}
+
+// Window is a Handler that drops the records outside of a time window, like
+// the Debug records after a warmup period, or all but the Warn records except
+// for the ten minutes after an operator asked for verbose logs.
+//
+// The window can be changed at runtime with its Open, Set and Close methods,
+// or over HTTP (see ServeHTTP). Handlers derived from a Window (with its With*
+// methods) share its window
+type Window struct {
+	windowHandler
+}
+
+type windowHandler struct {
+	h Handler
+	w *timeWindow
+}
+
+type timeWindow struct {
+	mu    sync.RWMutex
+	level level.Level
+	start time.Time
+	end   time.Time
+	now   func() time.Time
+}
+
+// NewWindow decorates the Handler `h` so that the records below the configured
+// level are only handled within the time window in WindowConfig `conf`.
+//
+// Returns nil if `h` is nil
+func NewWindow(h Handler, conf WindowConfig) *Window {
+	if h == nil {
+		return nil
+	}
+
+	return &Window{
+		windowHandler: windowHandler{
+			h: h,
+			w: &timeWindow{
+				level: conf.Level,
+				start: conf.Start,
+				end:   conf.End,
+				now:   time.Now,
+			},
+		},
+	}
+}
+
+// Open opens the window from now, for the duration `d`, after which it expires
+func (w *Window) Open(d time.Duration) {
+	now := w.w.now()
+	w.Set(now, now.Add(d))
+}
+
+// Set replaces the window's bounds with `start` and `end` (see WindowConfig)
+func (w *Window) Set(start, end time.Time) {
+	w.w.mu.Lock()
+	w.w.start, w.w.end = start, end
+	w.w.mu.Unlock()
+}
+
+// Close closes the window, until it is opened again with Open or Set
+func (w *Window) Close() {
+	w.Set(time.Time{}, time.Time{})
+}
+
+// Bounds returns the window's current start and end
+func (w *Window) Bounds() (start, end time.Time) {
+	w.w.mu.RLock()
+	defer w.w.mu.RUnlock()
+
+	return w.w.start, w.w.end
+}
+
+// Active returns a boolean on whether the window is currently open
+func (w *Window) Active() bool {
+	return w.w.active()
+}
+
+func (t *timeWindow) active() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.start.IsZero() && t.end.IsZero() {
+		return false
+	}
+
+	now := t.now()
+	return (t.start.IsZero() || !now.Before(t.start)) &&
+		(t.end.IsZero() || now.Before(t.end))
+}
+
+// allows returns a boolean on whether records with log level `l` are handled
+// at this time
+func (t *timeWindow) allows(l level.Level) bool {
+	if l == nil || (t.level != nil && l.Int() >= t.level.Int()) {
+		return true
+	}
+	return t.active()
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (w windowHandler) Enabled(level level.Level) bool {
+	return w.h.Enabled(level) && w.w.allows(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (w windowHandler) Handle(r records.Record) error {
+	if !w.Enabled(r.Level()) {
+		return nil
+	}
+	return w.h.Handle(r)
+}
+
+// Ping implements Pinger, checking the health of the decorated Handler
+func (w windowHandler) Ping(ctx context.Context) error {
+	return Ping(ctx, w.h)
+}
+
+// Shutdown implements Shutdowner, shutting down the decorated Handler
+func (w windowHandler) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, w.h)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (w windowHandler) With(attrs ...attr.Attr) Handler {
+	return windowHandler{
+		h: w.h.With(attrs...),
+		w: w.w,
+	}
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (w windowHandler) WithSource(addSource bool) Handler {
+	return windowHandler{
+		h: w.h.WithSource(addSource),
+		w: w.w,
+	}
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter
+func (w windowHandler) WithLevel(level level.Leveler) Handler {
+	return windowHandler{
+		h: w.h.WithLevel(level),
+		w: w.w,
+	}
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (w windowHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Handler {
+	return windowHandler{
+		h: w.h.WithReplaceFn(fn),
+		w: w.w,
+	}
+}
@@ -0,0 +1,75 @@
+//go:build !logx_tiny
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+type windowPayload struct {
+	Active bool       `json:"active"`
+	Start  *time.Time `json:"start,omitempty"`
+	End    *time.Time `json:"end,omitempty"`
+}
+
+type windowRequest struct {
+	Duration string    `json:"duration"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+}
+
+var errEmptyWindow = errors.New("either a duration or a start and end are required")
+
+// ServeHTTP implements http.Handler, allowing the Window to be reported and
+// changed at runtime, to be mounted on an admin endpoint:
+//   - a GET request returns the window as
+//     `{"active":true,"start":"...","end":"..."}`
+//   - a PUT request with a body like `{"duration":"10m"}` opens the window from
+//     now, for that duration; or with the bounds in RFC3339 format, like
+//     `{"start":"...","end":"..."}`
+//   - a DELETE request closes the window
+func (w *Window) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var body windowRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			writeStoreJSON(rw, http.StatusBadRequest, storeError{Error: err.Error()})
+			return
+		}
+
+		switch {
+		case body.Duration != "":
+			d, err := time.ParseDuration(body.Duration)
+			if err != nil {
+				writeStoreJSON(rw, http.StatusBadRequest, storeError{Error: err.Error()})
+				return
+			}
+			w.Open(d)
+		case !body.Start.IsZero() || !body.End.IsZero():
+			w.Set(body.Start, body.End)
+		default:
+			writeStoreJSON(rw, http.StatusBadRequest, storeError{Error: errEmptyWindow.Error()})
+			return
+		}
+	case http.MethodDelete:
+		w.Close()
+	default:
+		rw.Header().Set("Allow", "GET, PUT, DELETE")
+		writeStoreJSON(rw, http.StatusMethodNotAllowed, storeError{Error: "method not allowed"})
+		return
+	}
+
+	out := windowPayload{Active: w.Active()}
+	start, end := w.Bounds()
+	if !start.IsZero() {
+		out.Start = &start
+	}
+	if !end.IsZero() {
+		out.End = &end
+	}
+	writeStoreJSON(rw, http.StatusOK, out)
+}
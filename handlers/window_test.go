@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestWindow(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	handle := func(h Handler, lv level.Level) {
+		_ = h.Handle(records.New(now, lv, "event"))
+	}
+
+	t.Run("Warmup", func(t *testing.T) {
+		th := newTestHandler()
+		w := NewWindow(th, WindowConfig{Level: level.Info, End: now.Add(time.Minute)})
+		w.w.now = func() time.Time { return now }
+
+		handle(w, level.Debug)
+		handle(w, level.Info)
+
+		now = now.Add(time.Minute)
+		handle(w, level.Debug)
+		handle(w, level.Warn)
+
+		if w.Enabled(level.Debug) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", false, true)
+		}
+
+		rs := th.Records()
+		if len(rs) != 3 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 3, len(rs))
+			return
+		}
+		if rs[2].Level() != level.Warn {
+			t.Errorf("output mismatch error: wanted %v ; got %v", level.Warn, rs[2].Level())
+		}
+	})
+	t.Run("Open", func(t *testing.T) {
+		th := newTestHandler()
+		w := NewWindow(th, WindowConfig{Level: level.Warn})
+		w.w.now = func() time.Time { return now }
+		h := w.With(attr.String("svc", "api"))
+
+		handle(h, level.Debug)
+		if w.Active() {
+			t.Errorf("output mismatch error: wanted %v ; got %v", false, true)
+		}
+
+		w.Open(10 * time.Minute)
+		handle(h, level.Debug)
+
+		now = now.Add(10 * time.Minute)
+		handle(h, level.Debug)
+		handle(h, level.Error)
+
+		if rs := th.Records(); len(rs) != 2 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 2, len(rs))
+		}
+	})
+	t.Run("Close", func(t *testing.T) {
+		th := newTestHandler()
+		w := NewWindow(th, WindowConfig{Start: now.Add(-time.Minute)})
+		w.w.now = func() time.Time { return now }
+
+		handle(w, level.Error)
+		w.Close()
+		handle(w, level.Error)
+
+		if rs := th.Records(); len(rs) != 1 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 1, len(rs))
+		}
+	})
+	t.Run("NilHandler", func(t *testing.T) {
+		if w := NewWindow(nil, WindowConfig{}); w != nil {
+			t.Errorf("output mismatch error: wanted nil ; got %v", w)
+		}
+	})
+}
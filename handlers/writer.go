@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// WriteSyncer is an io.Writer that is also able to commit any buffered data to
+// its underlying storage, like an *os.File
+type WriteSyncer interface {
+	io.Writer
+	// Sync commits the written data to the underlying storage
+	Sync() error
+}
+
+type writerWrapper struct {
+	io.Writer
+}
+
+// Sync implements WriteSyncer, as a no-op
+func (writerWrapper) Sync() error {
+	return nil
+}
+
+// WriteContext implements ContextWriter, writing to the wrapped io.Writer
+// with WriteContext
+func (w writerWrapper) WriteContext(ctx context.Context, p []byte) (int, error) {
+	return WriteContext(ctx, w.Writer, p)
+}
+
+// Capabilities implements Capable, returning the capabilities of the wrapped
+// io.Writer
+func (w writerWrapper) Capabilities() Capability {
+	caps, _ := Capabilities(w.Writer)
+	return caps
+}
+
+// AddSync converts the input io.Writer `w` into a WriteSyncer. If `w` does not
+// implement WriteSyncer already, its Sync method is a no-op
+func AddSync(w io.Writer) WriteSyncer {
+	if ws, ok := w.(WriteSyncer); ok {
+		return ws
+	}
+	return writerWrapper{w}
+}
+
+type lockedWriter struct {
+	mu sync.Mutex
+	ws WriteSyncer
+}
+
+// Lock wraps the input io.Writer `w` with a mutex, making it safe for
+// concurrent use. Each Write call is written to `w` as a whole, so that records
+// from different Loggers sharing the same output (like os.Stdout) never
+// interleave.
+//
+// Locking an already locked writer returns it as-is
+func Lock(w io.Writer) WriteSyncer {
+	if lw, ok := w.(*lockedWriter); ok {
+		return lw
+	}
+	return &lockedWriter{ws: AddSync(w)}
+}
+
+// Write implements io.Writer
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.ws.Write(p)
+}
+
+// WriteContext implements ContextWriter, writing to the underlying writer with
+// WriteContext while holding the lock
+func (w *lockedWriter) WriteContext(ctx context.Context, p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return WriteContext(ctx, w.ws, p)
+}
+
+// Sync implements WriteSyncer
+func (w *lockedWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.ws.Sync()
+}
+
+// Ping implements Pinger, checking the health of the underlying writer
+func (w *lockedWriter) Ping(ctx context.Context) error {
+	return Ping(ctx, w.ws)
+}
+
+// Capabilities implements Capable, returning the capabilities of the
+// underlying writer
+func (w *lockedWriter) Capabilities() Capability {
+	caps, _ := Capabilities(w.ws)
+	return caps
+}
+
+// Shutdown implements Shutdowner, shutting down the underlying writer
+func (w *lockedWriter) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx, w.ws)
+}
+
+// ContextWriter is implemented by writers to remote sinks (like an HTTP
+// endpoint or a log collector) that are able to bound each write with a
+// context, so that a slow or unresponsive sink does not block its caller
+// indefinitely
+type ContextWriter interface {
+	// WriteContext writes `p` like io.Writer, returning early with the
+	// context's error if it is done first
+	WriteContext(ctx context.Context, p []byte) (int, error)
+}
+
+// deadliner is implemented by network connections, like net.Conn
+type deadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// WriteContext writes `p` to the io.Writer `w`, respecting the deadline and
+// cancellation of the context `ctx`:
+//   - if `w` is a ContextWriter, its WriteContext method is called;
+//   - if `w` is a network connection (with a SetWriteDeadline method), the
+//     context's deadline is set as its write deadline for the call, and
+//     cancelling the context interrupts the write;
+//   - otherwise, `p` is only written if the context is not done yet.
+func WriteContext(ctx context.Context, w io.Writer, p []byte) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	switch v := w.(type) {
+	case ContextWriter:
+		return v.WriteContext(ctx, p)
+	case deadliner:
+		deadline, _ := ctx.Deadline()
+		if err := v.SetWriteDeadline(deadline); err != nil {
+			return w.Write(p)
+		}
+
+		interrupted := make(chan struct{})
+		stop := context.AfterFunc(ctx, func() {
+			_ = v.SetWriteDeadline(time.Now())
+			close(interrupted)
+		})
+
+		n, err := w.Write(p)
+
+		// wait for an interruption in progress before clearing the deadline
+		if !stop() {
+			<-interrupted
+		}
+		_ = v.SetWriteDeadline(time.Time{})
+
+		switch {
+		case err == nil:
+		case ctx.Err() != nil:
+			return n, ctx.Err()
+		case !deadline.IsZero() && errors.Is(err, os.ErrDeadlineExceeded):
+			// the connection's deadline may expire just before the context's
+			return n, context.DeadlineExceeded
+		}
+		return n, err
+	default:
+		return w.Write(p)
+	}
+}
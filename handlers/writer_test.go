@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// overlapWriter records whether two Write calls ever overlap
+type overlapWriter struct {
+	active  atomic.Int32
+	overlap atomic.Bool
+	buf     bytes.Buffer
+	synced  int
+}
+
+func (w *overlapWriter) Write(p []byte) (int, error) {
+	if w.active.Add(1) > 1 {
+		w.overlap.Store(true)
+	}
+	defer w.active.Add(-1)
+
+	// write byte by byte to widen the window for interleaving
+	for i := range p {
+		w.buf.WriteByte(p[i])
+	}
+	return len(p), nil
+}
+
+func (w *overlapWriter) Sync() error {
+	w.synced++
+	return nil
+}
+
+func TestLock(t *testing.T) {
+	t.Run("NoOverlap", func(t *testing.T) {
+		w := &overlapWriter{}
+		lw := Lock(w)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					_, _ = lw.Write([]byte("a full line of output\n"))
+				}
+			}()
+		}
+		wg.Wait()
+
+		if w.overlap.Load() {
+			t.Errorf("expected writes not to overlap")
+		}
+		if w.buf.Len() != 8*100*len("a full line of output\n") {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 8*100*len("a full line of output\n"), w.buf.Len())
+		}
+	})
+	t.Run("Sync", func(t *testing.T) {
+		w := &overlapWriter{}
+
+		if err := Lock(w).Sync(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if w.synced != 1 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 1, w.synced)
+		}
+	})
+	t.Run("Idempotent", func(t *testing.T) {
+		lw := Lock(&bytes.Buffer{})
+
+		if Lock(lw) != lw {
+			t.Errorf("expected a locked writer not to be wrapped again")
+		}
+	})
+}
+
+func TestAddSync(t *testing.T) {
+	t.Run("NoOp", func(t *testing.T) {
+		if err := AddSync(&bytes.Buffer{}).Sync(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("RotatingFile", func(t *testing.T) {
+		w, err := Rotate(filepath.Join(t.TempDir(), "test.log"), 1024, 0)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		ws := AddSync(w)
+		if _, err := ws.Write([]byte("line\n")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := ws.Sync(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		_ = w.Close()
+		if err := ws.Sync(); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("unexpected error: wanted %v ; got %v", os.ErrClosed, err)
+		}
+	})
+}
+
+func TestWriteContext(t *testing.T) {
+	t.Run("Writer", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		if _, err := WriteContext(context.Background(), b, []byte("data")); err != nil || b.String() != "data" {
+			t.Errorf("output mismatch error: wanted %q ; got %q (%v)", "data", b.String(), err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := WriteContext(ctx, b, []byte("more")); !errors.Is(err, context.Canceled) || b.String() != "data" {
+			t.Errorf("unexpected error: wanted %v ; got %v", context.Canceled, err)
+		}
+	})
+	t.Run("Conn", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		defer server.Close()
+
+		// nothing reads from the server side, so the write blocks
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if _, err := WriteContext(ctx, client, []byte("data")); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error: wanted %v ; got %v", context.DeadlineExceeded, err)
+		}
+
+		ctx, cancel = context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		if _, err := WriteContext(ctx, client, []byte("data")); !errors.Is(err, context.Canceled) {
+			t.Errorf("unexpected error: wanted %v ; got %v", context.Canceled, err)
+		}
+	})
+	t.Run("Wrapped", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if _, err := WriteContext(ctx, Lock(stalledWriter{}), []byte("data")); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error: wanted %v ; got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+// Package buffer provides a pool of byte buffers shared by the logx handlers,
+// so that encoding a record reuses memory instead of allocating a new buffer
+// on every Handle call
+package buffer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// initialSize is the capacity of newly allocated buffers
+	initialSize = 1 << 10 // 1 KiB
+	// maxSize is the capacity above which a buffer is not returned to the pool,
+	// to avoid retaining the memory of exceptionally large records
+	maxSize = 64 << 10 // 64 KiB
+)
+
+var pool = sync.Pool{
+	New: func() any {
+		b := make(Buffer, 0, initialSize)
+		return &b
+	},
+}
+
+// Buffer is a byte slice that implements io.Writer, taken from a shared pool
+type Buffer []byte
+
+// Get returns an empty Buffer from the shared pool
+func Get() *Buffer {
+	return pool.Get().(*Buffer)
+}
+
+// Free resets the Buffer and returns it to the shared pool. Buffers that grew
+// beyond the maximum pooled size are dropped.
+//
+// The Buffer must not be used after it is freed
+func (b *Buffer) Free() {
+	if cap(*b) > maxSize {
+		return
+	}
+	*b = (*b)[:0]
+	pool.Put(b)
+}
+
+// Write appends the contents of `p` to the Buffer
+func (b *Buffer) Write(p []byte) (int, error) {
+	*b = append(*b, p...)
+	return len(p), nil
+}
+
+// WriteString appends the string `s` to the Buffer
+func (b *Buffer) WriteString(s string) (int, error) {
+	*b = append(*b, s...)
+	return len(s), nil
+}
+
+// WriteByte appends the byte `c` to the Buffer
+func (b *Buffer) WriteByte(c byte) error {
+	*b = append(*b, c)
+	return nil
+}
+
+// WriteRune appends the UTF-8 encoding of the rune `r` to the Buffer
+func (b *Buffer) WriteRune(r rune) (int, error) {
+	n := len(*b)
+	*b = utf8.AppendRune(*b, r)
+	return len(*b) - n, nil
+}
+
+// WriteValue appends the value `v` in its default format, as fmt.Fprint would.
+// Strings, booleans, numbers, times and durations are appended with strconv
+// and the time package, without boxing them or building intermediate strings;
+// other types fall back to fmt
+func (b *Buffer) WriteValue(v any) {
+	switch v := v.(type) {
+	case string:
+		*b = append(*b, v...)
+	case bool:
+		*b = strconv.AppendBool(*b, v)
+	case int:
+		*b = strconv.AppendInt(*b, int64(v), 10)
+	case int8:
+		*b = strconv.AppendInt(*b, int64(v), 10)
+	case int16:
+		*b = strconv.AppendInt(*b, int64(v), 10)
+	case int32:
+		*b = strconv.AppendInt(*b, int64(v), 10)
+	case int64:
+		*b = strconv.AppendInt(*b, v, 10)
+	case uint:
+		*b = strconv.AppendUint(*b, uint64(v), 10)
+	case uint8:
+		*b = strconv.AppendUint(*b, uint64(v), 10)
+	case uint16:
+		*b = strconv.AppendUint(*b, uint64(v), 10)
+	case uint32:
+		*b = strconv.AppendUint(*b, uint64(v), 10)
+	case uint64:
+		*b = strconv.AppendUint(*b, v, 10)
+	case float32:
+		*b = strconv.AppendFloat(*b, float64(v), 'g', -1, 32)
+	case float64:
+		*b = strconv.AppendFloat(*b, v, 'g', -1, 64)
+	case time.Time:
+		*b = v.AppendFormat(*b, "2006-01-02 15:04:05.999999999 -0700 MST")
+	case time.Duration:
+		*b = append(*b, v.String()...)
+	default:
+		fmt.Fprint(b, v)
+	}
+}
+
+// WriteJSON appends the value `v` encoded as JSON, or as a JSON string with
+// its default format if it cannot be encoded
+func (b *Buffer) WriteJSON(v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	*b = append(*b, data...)
+}
+
+// Len returns the number of bytes in the Buffer
+func (b *Buffer) Len() int {
+	return len(*b)
+}
+
+// Bytes returns the contents of the Buffer
+func (b *Buffer) Bytes() []byte {
+	return *b
+}
+
+// String returns the contents of the Buffer as a string
+func (b *Buffer) String() string {
+	return string(*b)
+}
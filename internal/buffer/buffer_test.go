@@ -0,0 +1,112 @@
+package buffer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBuffer(t *testing.T) {
+	t.Run("Write", func(t *testing.T) {
+		wants := "[info] ação\n"
+
+		b := Get()
+		defer b.Free()
+
+		_ = b.WriteByte('[')
+		_, _ = b.WriteString("info")
+		_, _ = b.WriteRune(']')
+		_, _ = b.WriteRune(' ')
+		_, _ = b.Write([]byte("ação"))
+		_ = b.WriteByte('\n')
+
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+		if b.Len() != len(wants) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", len(wants), b.Len())
+		}
+	})
+	t.Run("FreeResets", func(t *testing.T) {
+		b := Get()
+		_, _ = b.WriteString("data")
+		b.Free()
+
+		if b.Len() != 0 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 0, b.Len())
+		}
+	})
+	t.Run("FreeDropsLargeBuffers", func(t *testing.T) {
+		b := Get()
+		_, _ = b.Write(make([]byte, maxSize+1))
+		b.Free()
+
+		if b.Len() != maxSize+1 {
+			t.Errorf("expected an oversized buffer to be left untouched")
+		}
+	})
+}
+
+func TestWriteValue(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 600, time.UTC)
+
+	for _, v := range []any{
+		"text", true, -1, int8(-8), int16(-16), int32(-32), int64(-64),
+		uint(1), uint8(8), uint16(16), uint32(32), uint64(64),
+		float32(0.1), 1.5e-7, 3.0, 1e21, ts, 1500 * time.Millisecond,
+		errors.New("failed"), []byte("hi"), nil, []int{1, 2},
+	} {
+		t.Run(fmt.Sprintf("%T", v), func(t *testing.T) {
+			b := Get()
+			defer b.Free()
+
+			b.WriteValue(v)
+
+			if wants := fmt.Sprint(v); b.String() != wants {
+				t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+			}
+		})
+	}
+
+	t.Run("NoAllocs", func(t *testing.T) {
+		b := Get()
+		defer b.Free()
+
+		var values = []any{42, 3.14, true, ts}
+		allocs := testing.AllocsPerRun(100, func() {
+			*b = (*b)[:0]
+			for _, v := range values {
+				b.WriteValue(v)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("output mismatch error: wanted %v allocations ; got %v", 0, allocs)
+		}
+	})
+}
+
+func TestWriteJSON(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		v     any
+		wants string
+	}{
+		{"String", `say "hi"`, `"say \"hi\""`},
+		{"Number", 42, `42`},
+		{"Map", map[string]int{"a": 1}, `{"a":1}`},
+		{"Unsupported", math.NaN(), `"NaN"`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			b := Get()
+			defer b.Free()
+
+			b.WriteJSON(test.v)
+
+			if b.String() != test.wants {
+				t.Errorf("output mismatch error: wanted %q ; got %q", test.wants, b.String())
+			}
+		})
+	}
+}
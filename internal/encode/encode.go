@@ -0,0 +1,71 @@
+// Package encode encodes single records with a Handler, for the handlers that
+// send each record on its own (like to a message broker) and need its encoding
+// as a whole, rather than writing it to an io.Writer
+package encode
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/records"
+)
+
+// Encoder encodes records with a Handler writing to a shared buffer. Copies of
+// an Encoder (with its With methods) share the buffer, and encode one record
+// at a time
+type Encoder struct {
+	mu  *sync.Mutex
+	buf *bytes.Buffer
+	h   handlers.Handler
+}
+
+// New creates an Encoder with the Handler created by `format` for its buffer,
+// or a JSON handler (see the jsonh package) if nil
+func New(format func(w io.Writer) handlers.Handler) Encoder {
+	if format == nil {
+		format = jsonh.New
+	}
+
+	buf := &bytes.Buffer{}
+	return Encoder{
+		mu:  &sync.Mutex{},
+		buf: buf,
+		h:   format(buf),
+	}
+}
+
+// Encode returns the encoded Record `r`, without a trailing line break
+func (e Encoder) Encode(r records.Record) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.buf.Reset()
+	if err := e.h.Handle(r); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(bytes.TrimSuffix(e.buf.Bytes(), []byte{'\n'})), nil
+}
+
+// With returns a copy of the Encoder with the bound attributes `attrs`
+func (e Encoder) With(attrs ...attr.Attr) Encoder {
+	e.h = e.h.With(attrs...)
+	return e
+}
+
+// WithSource returns a copy of the Encoder adding source references as set by
+// `addSource`
+func (e Encoder) WithSource(addSource bool) Encoder {
+	e.h = e.h.WithSource(addSource)
+	return e
+}
+
+// WithReplaceFn returns a copy of the Encoder with the attribute replace
+// function `fn`
+func (e Encoder) WithReplaceFn(fn func(a attr.Attr) attr.Attr) Encoder {
+	e.h = e.h.WithReplaceFn(fn)
+	return e
+}
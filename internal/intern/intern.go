@@ -0,0 +1,128 @@
+// Package intern provides bounded tables of interned strings, so that the
+// attribute keys (and common values) repeated across many records share the
+// same backing memory, and caches of their encoded form for the handlers
+package intern
+
+import "sync"
+
+const (
+	// MaxEntries is the number of distinct strings held by the default Table
+	// and by each Cache; strings beyond it are returned (or encoded) as-is
+	MaxEntries = 4096
+	// MaxValueLen is the length above which a string value is not considered
+	// common, and is not interned by Value
+	MaxValueLen = 64
+)
+
+var std = New(MaxEntries)
+
+// Table is a bounded set of interned strings, safe for concurrent use
+type Table struct {
+	mu  sync.RWMutex
+	m   map[string]string
+	max int
+}
+
+// New creates a Table holding up to `max` strings
+func New(max int) *Table {
+	return &Table{
+		m:   make(map[string]string),
+		max: max,
+	}
+}
+
+// String returns the interned copy of the string `s`, adding it to the Table
+// if not yet present. If the Table is full, `s` is returned as-is.
+//
+// A copy of `s` is stored, so that it does not retain the memory of a larger
+// string it may be a slice of (like a decoded line)
+func (t *Table) String(s string) string {
+	t.mu.RLock()
+	interned, ok := t.m[s]
+	t.mu.RUnlock()
+	if ok {
+		return interned
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if interned, ok = t.m[s]; ok {
+		return interned
+	}
+	if len(t.m) >= t.max {
+		return s
+	}
+
+	interned = string([]byte(s))
+	t.m[interned] = interned
+	return interned
+}
+
+// Bytes returns the interned string with the contents of `b`. Looking up a
+// string that is already interned does not allocate
+func (t *Table) Bytes(b []byte) string {
+	t.mu.RLock()
+	interned, ok := t.m[string(b)]
+	t.mu.RUnlock()
+	if ok {
+		return interned
+	}
+	return t.String(string(b))
+}
+
+// Len returns the number of strings in the Table
+func (t *Table) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.m)
+}
+
+// Key returns the interned copy of the attribute key `key`, from the default
+// Table
+func Key(key string) string {
+	return std.String(key)
+}
+
+// Value returns the interned copy of the string value `v` from the default
+// Table, if it is short enough to be a common value (like an enum, a status
+// or a host name); longer values are returned as-is
+func Value(v string) string {
+	if len(v) > MaxValueLen {
+		return v
+	}
+	return std.String(v)
+}
+
+// Cache holds the encoded form of strings, so that a handler encodes each
+// distinct attribute key once. It is bounded to MaxEntries strings, and is
+// safe for concurrent use
+type Cache struct {
+	m  sync.Map
+	n  int64
+	mu sync.Mutex
+}
+
+// Append appends the encoded form of the string `s` to `b`, with the function
+// `encode` called on the first use of `s` only
+func (c *Cache) Append(b []byte, s string, encode func(b []byte, s string) []byte) []byte {
+	if c == nil {
+		return encode(b, s)
+	}
+	if enc, ok := c.m.Load(s); ok {
+		return append(b, enc.([]byte)...)
+	}
+
+	start := len(b)
+	b = encode(b, s)
+
+	c.mu.Lock()
+	if c.n < MaxEntries {
+		if _, loaded := c.m.LoadOrStore(Key(s), append([]byte(nil), b[start:]...)); !loaded {
+			c.n++
+		}
+	}
+	c.mu.Unlock()
+
+	return b
+}
@@ -0,0 +1,79 @@
+package intern
+
+import (
+	"strconv"
+	"testing"
+	"unsafe"
+)
+
+func TestTable(t *testing.T) {
+	t.Run("SharesMemory", func(t *testing.T) {
+		table := New(8)
+		line := "request_id=abc status=200"
+
+		a := table.String(line[:10])
+		b := table.Bytes([]byte("request_id"))
+
+		if a != "request_id" || b != "request_id" {
+			t.Errorf("output mismatch error: wanted %q ; got %q and %q", "request_id", a, b)
+		}
+		if unsafe.StringData(a) != unsafe.StringData(b) {
+			t.Errorf("expected both strings to share the same backing memory")
+		}
+		if unsafe.StringData(a) == unsafe.StringData(line) {
+			t.Errorf("expected the interned string not to retain the input line")
+		}
+	})
+	t.Run("Bounded", func(t *testing.T) {
+		table := New(2)
+		for i := 0; i < 4; i++ {
+			if s := table.String(strconv.Itoa(i)); s != strconv.Itoa(i) {
+				t.Errorf("output mismatch error: wanted %q ; got %q", strconv.Itoa(i), s)
+			}
+		}
+		if table.Len() != 2 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 2, table.Len())
+		}
+	})
+	t.Run("BytesNoAllocs", func(t *testing.T) {
+		table := New(8)
+		key := []byte("status")
+		table.Bytes(key)
+
+		if allocs := testing.AllocsPerRun(100, func() { table.Bytes(key) }); allocs != 0 {
+			t.Errorf("output mismatch error: wanted %v allocations ; got %v", 0, allocs)
+		}
+	})
+	t.Run("Value", func(t *testing.T) {
+		long := string(make([]byte, MaxValueLen+1))
+		if v := Value(long); unsafe.StringData(v) != unsafe.StringData(long) {
+			t.Errorf("expected long values not to be interned")
+		}
+		if a, b := Value("eu-west-1"), Value(string([]byte("eu-west-1"))); unsafe.StringData(a) != unsafe.StringData(b) {
+			t.Errorf("expected short values to be interned")
+		}
+	})
+}
+
+func TestCache(t *testing.T) {
+	var calls int
+	encode := func(b []byte, s string) []byte {
+		calls++
+		return append(append(append(b, '"'), s...), '"')
+	}
+
+	c := &Cache{}
+	for i := 0; i < 3; i++ {
+		if out := string(c.Append([]byte("x"), "key", encode)); out != `x"key"` {
+			t.Errorf("output mismatch error: wanted %q ; got %q", `x"key"`, out)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("output mismatch error: wanted %v encode calls ; got %v", 1, calls)
+	}
+
+	var nilCache *Cache
+	if out := string(nilCache.Append(nil, "key", encode)); out != `"key"` {
+		t.Errorf("output mismatch error: wanted %q ; got %q", `"key"`, out)
+	}
+}
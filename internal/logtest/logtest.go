@@ -0,0 +1,36 @@
+// Package logtest holds the helpers shared by the tests decoding the records
+// written by a jsonh Handler
+package logtest
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+// Entry is a record as written by a jsonh Handler
+type Entry struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Message   string         `json:"message"`
+	Level     string         `json:"level"`
+	Data      map[string]any `json:"data"`
+}
+
+// DecodeEntries decodes the records written by a jsonh Handler to `r`,
+// failing the test `t` if they cannot be decoded
+func DecodeEntries(t testing.TB, r io.Reader) []Entry {
+	t.Helper()
+
+	var entries []Entry
+	dec := json.NewDecoder(r)
+	for dec.More() {
+		var e Entry
+		if err := dec.Decode(&e); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return nil
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
@@ -0,0 +1,51 @@
+// Package stats holds the process-wide counters updated by the logx Loggers
+// and handlers, which are exposed with logx.ReadStats
+package stats
+
+import "sync/atomic"
+
+// Level buckets for the Records counters, matching the built-in log levels
+const (
+	Trace = iota
+	Debug
+	Info
+	Warn
+	Error
+	Fatal
+
+	NumLevels
+)
+
+var (
+	// Records counts the records handed to a Handler, per level bucket
+	Records [NumLevels]atomic.Uint64
+	// Dropped counts the records discarded by the Async, Breaker and Quota
+	// handlers
+	Dropped atomic.Uint64
+	// Errors counts the errors raised by the Loggers' Handlers
+	Errors atomic.Uint64
+	// Queued is the number of records waiting in the Async handlers' queues
+	Queued atomic.Int64
+)
+
+// Bucket returns the index in Records for the level value `lv`. Levels between
+// two built-in ones (like `info+2`) count as the lower one
+func Bucket(lv int) int {
+	switch {
+	case lv < -8:
+		return Trace
+	case lv >= 12:
+		return Fatal
+	default:
+		return (lv + 8) / 4
+	}
+}
+
+// Reset zeroes all counters, except for Queued which tracks live state
+func Reset() {
+	for i := range Records {
+		Records[i].Store(0)
+	}
+	Dropped.Store(0)
+	Errors.Store(0)
+}
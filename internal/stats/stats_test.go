@@ -0,0 +1,28 @@
+package stats
+
+import "testing"
+
+func TestBucket(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		lv    int
+		wants int
+	}{
+		{"BelowTrace", -20, Trace},
+		{"Trace", -8, Trace},
+		{"BetweenTraceAndDebug", -5, Trace},
+		{"Debug", -4, Debug},
+		{"Info", 0, Info},
+		{"InfoOffset", 2, Info},
+		{"Warn", 4, Warn},
+		{"Error", 8, Error},
+		{"Fatal", 12, Fatal},
+		{"AboveFatal", 40, Fatal},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Bucket(test.lv); got != test.wants {
+				t.Errorf("output mismatch error: wanted %v ; got %v", test.wants, got)
+			}
+		})
+	}
+}
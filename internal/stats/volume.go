@@ -0,0 +1,182 @@
+package stats
+
+import (
+	"math/bits"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// NumSlots is the number of slots a Volume's window is divided into, as it
+	// slides one slot at a time
+	NumSlots = 8
+	// NumSizes is the number of buckets in a Site's histogram of record sizes
+	NumSizes = 17
+	// MaxSites is the maximum number of distinct messages tracked per slot;
+	// other messages are counted under the Other key
+	MaxSites = 1024
+	// Other is the key counting the records logged once MaxSites is reached
+	Other = "(other)"
+)
+
+// Tracker is the Volume updated by the Loggers, if set
+var Tracker atomic.Pointer[Volume]
+
+// Counter holds a number of records and their volume in bytes
+type Counter struct {
+	Records uint64
+	Bytes   uint64
+}
+
+func (c *Counter) add(o Counter) {
+	c.Records += o.Records
+	c.Bytes += o.Bytes
+}
+
+// Site holds the volume of the records logged with a message, with a histogram
+// of their sizes in exponential buckets: bucket i counts the records with up to
+// 2^i bytes (SizeBound), the last one all larger records
+type Site struct {
+	Key   string
+	Level int
+	Counter
+	Sizes [NumSizes]uint64
+}
+
+type slot struct {
+	epoch  int64
+	levels [NumLevels]Counter
+	sites  map[string]*Site
+}
+
+// Volume tracks the number and volume of records per level and per message
+// over a sliding window
+type Volume struct {
+	mu     sync.Mutex
+	window time.Duration
+	width  time.Duration
+	slots  [NumSlots]slot
+	top    int
+
+	now func() time.Time
+}
+
+// NewVolume creates a Volume over the window `window`, reporting the `top`
+// messages with the most records
+func NewVolume(window time.Duration, top int) *Volume {
+	width := window / NumSlots
+	if width <= 0 {
+		width = 1
+	}
+	return &Volume{
+		window: window,
+		width:  width,
+		top:    top,
+		now:    time.Now,
+	}
+}
+
+// Window returns the duration of the Volume's sliding window
+func (v *Volume) Window() time.Duration {
+	return v.window
+}
+
+// Top returns the number of sites reported by Read
+func (v *Volume) Top() int {
+	return v.top
+}
+
+// Add counts a record with level value `lv` and message `key`, of `size` bytes
+func (v *Volume) Add(lv int, key string, size int) {
+	epoch := v.now().UnixNano() / int64(v.width)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s := &v.slots[epoch%NumSlots]
+	if s.epoch != epoch || s.sites == nil {
+		*s = slot{epoch: epoch, sites: make(map[string]*Site, len(s.sites))}
+	}
+
+	c := Counter{Records: 1, Bytes: uint64(size)}
+	s.levels[Bucket(lv)].add(c)
+
+	site, ok := s.sites[key]
+	if !ok {
+		if len(s.sites) >= MaxSites {
+			key = Other
+			site = s.sites[key]
+		}
+		if site == nil {
+			site = &Site{Key: key, Level: lv}
+			s.sites[key] = site
+		}
+	}
+	site.add(c)
+	site.Sizes[sizeBucket(size)]++
+}
+
+// Read returns the per-level counters and the top sites in the current window,
+// sorted by their number of records
+func (v *Volume) Read() ([NumLevels]Counter, []Site) {
+	epoch := v.now().UnixNano() / int64(v.width)
+
+	var levels [NumLevels]Counter
+	sites := map[string]*Site{}
+
+	v.mu.Lock()
+	for i := range v.slots {
+		s := &v.slots[i]
+		if s.sites == nil || s.epoch <= epoch-NumSlots || s.epoch > epoch {
+			continue
+		}
+		for lv := range s.levels {
+			levels[lv].add(s.levels[lv])
+		}
+		for key, site := range s.sites {
+			sum, ok := sites[key]
+			if !ok {
+				sum = &Site{Key: key, Level: site.Level}
+				sites[key] = sum
+			}
+			sum.add(site.Counter)
+			for b := range site.Sizes {
+				sum.Sizes[b] += site.Sizes[b]
+			}
+		}
+	}
+	v.mu.Unlock()
+
+	top := make([]Site, 0, len(sites))
+	for _, site := range sites {
+		top = append(top, *site)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Records != top[j].Records {
+			return top[i].Records > top[j].Records
+		}
+		return top[i].Key < top[j].Key
+	})
+	if len(top) > v.top {
+		top = top[:v.top]
+	}
+	return levels, top
+}
+
+// SizeBound returns the upper bound, in bytes, of the histogram bucket `b`, or
+// zero for the last (unbounded) bucket
+func SizeBound(b int) int {
+	if b >= NumSizes-1 {
+		return 0
+	}
+	return 1 << b
+}
+
+func sizeBucket(size int) int {
+	if size <= 1 {
+		return 0
+	}
+	return min(bits.Len(uint(size-1)), NumSizes-1)
+}
@@ -0,0 +1,65 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVolume(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := NewVolume(8*time.Second, 2)
+	v.now = func() time.Time { return now }
+
+	v.Add(0, "request", 100)
+	v.Add(0, "request", 300)
+	v.Add(4, "slow query", 50)
+	now = now.Add(4 * time.Second)
+	v.Add(-4, "cache miss", 20)
+
+	t.Run("Window", func(t *testing.T) {
+		levels, top := v.Read()
+		if levels[Info] != (Counter{Records: 2, Bytes: 400}) || levels[Warn].Records != 1 || levels[Debug].Records != 1 {
+			t.Errorf("output mismatch error: got %v", levels)
+		}
+		if len(top) != 2 || top[0].Key != "request" || top[1].Key != "cache miss" {
+			t.Errorf("output mismatch error: wanted the top 2 sites ; got %v", top)
+			return
+		}
+		if top[0].Sizes[7] != 1 || top[0].Sizes[9] != 1 {
+			t.Errorf("output mismatch error: wanted sizes in the 128 and 512 buckets ; got %v", top[0].Sizes)
+		}
+	})
+	t.Run("Slide", func(t *testing.T) {
+		now = now.Add(5 * time.Second)
+		levels, top := v.Read()
+		if levels[Info].Records != 0 || levels[Debug].Records != 1 || len(top) != 1 {
+			t.Errorf("output mismatch error: wanted only the last record ; got %v %v", levels, top)
+		}
+	})
+	t.Run("MaxSites", func(t *testing.T) {
+		v := NewVolume(time.Minute, 1)
+		for i := 0; i <= MaxSites; i++ {
+			v.Add(0, string(rune('a'+i%26))+string(rune(i)), 1)
+		}
+		v.Add(0, "other message", 1)
+		if _, top := v.Read(); top[0].Key != Other || top[0].Records != 2 {
+			t.Errorf("output mismatch error: wanted %q to count the overflow ; got %v", Other, top)
+		}
+	})
+}
+
+func TestSizeBucket(t *testing.T) {
+	for _, test := range []struct {
+		size  int
+		wants int
+	}{
+		{0, 0}, {1, 0}, {2, 1}, {3, 2}, {4, 2}, {5, 3}, {1024, 10}, {1025, 11}, {1 << 20, NumSizes - 1},
+	} {
+		if got := sizeBucket(test.size); got != test.wants {
+			t.Errorf("output mismatch error: size %d: wanted %v ; got %v", test.size, test.wants, got)
+		}
+		if b := SizeBound(sizeBucket(test.size)); b != 0 && test.size > b {
+			t.Errorf("output mismatch error: size %d is over its bucket's bound %d", test.size, b)
+		}
+	}
+}
@@ -1,20 +1,92 @@
 package level
 
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
 var (
-	lvKeys = map[string]Level{
-		"trace": lTrace,
-		"debug": lDebug,
-		"info":  lInfo,
-		"warn":  lWarn,
-		"error": lError,
-		"fatal": lFatal,
+	// ErrEmptyName is raised when registering a level with an empty name
+	ErrEmptyName error = errors.New("level name cannot be empty")
+	// ErrDuplicateName is raised when registering a level with a name that
+	// is already in use
+	ErrDuplicateName error = errors.New("level name is already registered")
+	// ErrDuplicateValue is raised when registering a level with a value that
+	// is already in use
+	ErrDuplicateValue error = errors.New("level value is already registered")
+)
+
+type levelTable struct {
+	keys   map[string]Level
+	values map[lv]string
+}
+
+var (
+	regMu sync.Mutex
+	table atomic.Pointer[levelTable]
+)
+
+func init() {
+	table.Store(&levelTable{
+		keys: map[string]Level{
+			"trace": lTrace,
+			"debug": lDebug,
+			"info":  lInfo,
+			"warn":  lWarn,
+			"error": lError,
+			"fatal": lFatal,
+		},
+		values: map[lv]string{
+			lTrace: "trace",
+			lDebug: "debug",
+			lInfo:  "info",
+			lWarn:  "warn",
+			lError: "error",
+			lFatal: "fatal",
+		},
+	})
+}
+
+// Register adds a custom level with name `name` and value `value` to the set of
+// known levels, returning it as a Level.
+//
+// Once registered, the level is recognized by AsLevel, and is rendered with its
+// name by handlers. Both the name and the value must be unique across all
+// built-in and registered levels, otherwise an error is returned; names are
+// compared case-insensitively, as Parse matches them regardless of case
+func Register(name string, value int) (Level, error) {
+	if name == "" {
+		return nil, ErrEmptyName
 	}
-	lvValues = map[lv]string{
-		lTrace: "trace",
-		lDebug: "debug",
-		lInfo:  "info",
-		lWarn:  "warn",
-		lError: "error",
-		lFatal: "fatal",
+
+	regMu.Lock()
+	defer regMu.Unlock()
+
+	cur := table.Load()
+	for key := range cur.keys {
+		if strings.EqualFold(key, name) {
+			return nil, ErrDuplicateName
+		}
 	}
-)
+	if _, ok := cur.values[lv(value)]; ok {
+		return nil, ErrDuplicateValue
+	}
+
+	next := &levelTable{
+		keys:   make(map[string]Level, len(cur.keys)+1),
+		values: make(map[lv]string, len(cur.values)+1),
+	}
+	for k, v := range cur.keys {
+		next.keys[k] = v
+	}
+	for k, v := range cur.values {
+		next.values[k] = v
+	}
+	next.keys[name] = lv(value)
+	next.values[lv(value)] = name
+
+	table.Store(next)
+	return lv(value), nil
+}
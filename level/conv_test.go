@@ -0,0 +1,50 @@
+package level
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		l, err := Register("notice", 10)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if l.String() != "notice" {
+			t.Errorf("unexpected output error: wanted %s ; got %s", "notice", l.String())
+		}
+		if l.Int() != 10 {
+			t.Errorf("unexpected output error: wanted %v ; got %v", 10, l.Int())
+		}
+		if AsLevel("notice") != l {
+			t.Errorf("unexpected output error: wanted %v ; got %v", l, AsLevel("notice"))
+		}
+	})
+	t.Run("EmptyName", func(t *testing.T) {
+		_, err := Register("", 11)
+		if !errors.Is(err, ErrEmptyName) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrEmptyName, err)
+		}
+	})
+	t.Run("DuplicateName", func(t *testing.T) {
+		_, err := Register("info", 12)
+		if !errors.Is(err, ErrDuplicateName) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrDuplicateName, err)
+		}
+	})
+	t.Run("DuplicateNameCase", func(t *testing.T) {
+		_, err := Register("INFO", 13)
+		if !errors.Is(err, ErrDuplicateName) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrDuplicateName, err)
+		}
+	})
+	t.Run("DuplicateValue", func(t *testing.T) {
+		_, err := Register("audit", 4)
+		if !errors.Is(err, ErrDuplicateValue) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrDuplicateValue, err)
+		}
+	})
+}
@@ -1,44 +1,93 @@
 package level
 
+import "strconv"
+
 // Level interface describes the behavior that a log level should have
 //
-// It must provide methods to be casted as a string or as an int
+// It must provide methods to be casted as a string or as an int. A Level is
+// also a Leveler that returns itself
 type Level interface {
 	// String returns the level as a string
 	String() string
 	// Int returns the level as an int
 	Int() int
+	// Level returns the level itself, implementing Leveler
+	Level() Level
+	// Offset returns a Level `n` steps above (or below, if negative) this
+	// one, for fine-grained verbosity between the named levels
+	Offset(n int) Level
+}
+
+// Leveler interface describes a type that provides a Level, which is
+// consulted every time the Level is needed
+//
+// This allows the Level to change at runtime without replacing the types
+// that reference it, as is the case with a *Var
+type Leveler interface {
+	// Level returns the current Level
+	Level() Level
 }
 
 type lv int
 
+// the built-in levels are spaced by 4, leaving room for offset levels
+// (see Level.Offset) and custom levels (see Register) between them
 const (
-	lTrace lv = iota
-	lDebug
-	lInfo
-	lWarn
-	lError
-	lFatal
+	lTrace lv = -8
+	lDebug lv = -4
+	lInfo  lv = 0
+	lWarn  lv = 4
+	lError lv = 8
+	lFatal lv = 12
 )
 
 var (
-	// Trace represents log level 0
+	// Trace represents log level -8
 	Trace Level = lTrace
-	// Debug represents log level 1
+	// Debug represents log level -4
 	Debug Level = lDebug
-	// Info represents log level 2
+	// Info represents log level 0
 	Info Level = lInfo
-	// Warn represents log level 3
+	// Warn represents log level 4
 	Warn Level = lWarn
-	// Error represents log level 4
+	// Error represents log level 8
 	Error Level = lError
-	// Fatal represents log level 5
+	// Fatal represents log level 12
 	Fatal Level = lFatal
 )
 
 // String returns the level as a string
+//
+// Levels without a name are rendered relative to the closest named level
+// below them (e.g. "debug+2"), or to the lowest named level if there is
+// none (e.g. "trace-2")
 func (l lv) String() string {
-	return lvValues[l]
+	values := table.Load().values
+	if name, ok := values[l]; ok {
+		return name
+	}
+
+	var (
+		base  lv
+		found bool
+		low   lv
+	)
+	for v := range values {
+		if v <= l && (!found || v > base) {
+			base, found = v, true
+		}
+		if v < low {
+			low = v
+		}
+	}
+	if !found {
+		base = low
+	}
+
+	if l < base {
+		return values[base] + strconv.Itoa(int(l-base))
+	}
+	return values[base] + "+" + strconv.Itoa(int(l-base))
 }
 
 // Int returns the level as an int
@@ -46,10 +95,21 @@ func (l lv) Int() int {
 	return (int)(l)
 }
 
+// Level returns the level itself, implementing Leveler
+func (l lv) Level() Level {
+	return l
+}
+
+// Offset returns a Level `n` steps above (or below, if negative) this
+// one, for fine-grained verbosity between the named levels
+func (l lv) Offset(n int) Level {
+	return l + lv(n)
+}
+
 // AsLevel converts an input string to a Level, returning nil if
-// invalid
+// invalid. Custom levels added with Register are also recognized
 func AsLevel(level string) Level {
-	if l, ok := lvKeys[level]; ok {
+	if l, ok := table.Load().keys[level]; ok {
 		return l
 	}
 	return nil
@@ -14,20 +14,26 @@ func TestLevelString(t *testing.T) {
 			input: lDebug,
 			wants: "debug",
 		}, {
-			input: lv(2),
+			input: lv(0),
 			wants: "info",
 		}, {
-			input: lv(3),
+			input: lv(4),
 			wants: "warn",
 		}, {
-			input: lv(4),
+			input: lv(8),
 			wants: "error",
 		}, {
-			input: lv(5),
+			input: lv(12),
 			wants: "fatal",
+		}, {
+			input: lv(-2),
+			wants: "debug+2",
+		}, {
+			input: lv(-10),
+			wants: "trace-2",
 		}, {
 			input: lv(99),
-			wants: "",
+			wants: "fatal+87",
 		},
 	}
 
@@ -44,22 +50,22 @@ func TestLevelInt(t *testing.T) {
 	}{
 		{
 			input: Trace,
-			wants: 0,
+			wants: -8,
 		}, {
 			input: lDebug,
-			wants: 1,
-		}, {
-			input: lv(2),
-			wants: 2,
+			wants: -4,
 		}, {
-			input: lv(3),
-			wants: 3,
+			input: Info,
+			wants: 0,
 		}, {
-			input: lv(4),
+			input: Warn,
 			wants: 4,
 		}, {
-			input: lv(5),
-			wants: 5,
+			input: Error,
+			wants: 8,
+		}, {
+			input: Fatal,
+			wants: 12,
 		}, {
 			input: lv(99),
 			wants: 99,
@@ -107,3 +113,27 @@ func TestAsLevel(t *testing.T) {
 		}
 	}
 }
+
+func TestLevelOffset(t *testing.T) {
+	tc := []struct {
+		input Level
+		wants Level
+	}{
+		{
+			input: Debug.Offset(-2),
+			wants: lv(-6),
+		}, {
+			input: Info.Offset(4),
+			wants: Warn,
+		}, {
+			input: Warn.Offset(0),
+			wants: Warn,
+		},
+	}
+
+	for _, tt := range tc {
+		if tt.input != tt.wants {
+			t.Errorf("unexpected output error: wanted %v ; got %v", tt.wants, tt.input)
+		}
+	}
+}
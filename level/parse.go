@@ -0,0 +1,68 @@
+package level
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidLevel is raised when parsing a string that does not match any
+// known level name nor an integer value
+var ErrInvalidLevel error = errors.New("invalid level")
+
+// Parse converts the input string `s` into a Level, matching the names of the
+// built-in and registered levels case-insensitively.
+//
+// If no name matches, `s` is parsed as an integer level value, or as a level
+// name with an offset (e.g. "debug+2" or "info-1"). An error is returned if
+// `s` is neither of these
+func Parse(s string) (Level, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, ErrInvalidLevel
+	}
+
+	keys := table.Load().keys
+	if l, ok := keys[s]; ok {
+		return l, nil
+	}
+	for name, l := range keys {
+		if strings.EqualFold(name, s) {
+			return l, nil
+		}
+	}
+
+	if n, err := strconv.Atoi(s); err == nil {
+		return lv(n), nil
+	}
+
+	idx := strings.LastIndexAny(s, "+-")
+	if idx <= 0 {
+		return nil, ErrInvalidLevel
+	}
+	offset, err := strconv.Atoi(s[idx:])
+	if err != nil {
+		return nil, ErrInvalidLevel
+	}
+	base, err := Parse(s[:idx])
+	if err != nil {
+		return nil, ErrInvalidLevel
+	}
+	return base.Offset(offset), nil
+}
+
+// FromEnv parses the level set in the environment variable `key`, returning
+// `fallback` if the variable is unset or does not hold a valid level
+func FromEnv(key string, fallback Level) Level {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	l, err := Parse(v)
+	if err != nil {
+		return fallback
+	}
+	return l
+}
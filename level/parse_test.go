@@ -0,0 +1,81 @@
+package level
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tc := []struct {
+		input string
+		wants Level
+		err   error
+	}{
+		{
+			input: "debug",
+			wants: Debug,
+		}, {
+			input: "WARN",
+			wants: Warn,
+		}, {
+			input: " Error ",
+			wants: Error,
+		}, {
+			input: "-4",
+			wants: Debug,
+		}, {
+			input: "debug-2",
+			wants: lv(-6),
+		}, {
+			input: "INFO+1",
+			wants: lv(1),
+		}, {
+			input: "verbose+1",
+			err:   ErrInvalidLevel,
+		}, {
+			input: "42",
+			wants: lv(42),
+		}, {
+			input: "verbose",
+			err:   ErrInvalidLevel,
+		}, {
+			input: "",
+			err:   ErrInvalidLevel,
+		},
+	}
+
+	for _, tt := range tc {
+		out, err := Parse(tt.input)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("unexpected error: wanted %v ; got %v", tt.err, err)
+			continue
+		}
+		if out != tt.wants {
+			t.Errorf("unexpected output error: wanted %v ; got %v", tt.wants, out)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	const key = "LOGX_TEST_LEVEL"
+
+	t.Run("Set", func(t *testing.T) {
+		t.Setenv(key, "Debug")
+
+		if out := FromEnv(key, Info); out != Debug {
+			t.Errorf("unexpected output error: wanted %v ; got %v", Debug, out)
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		t.Setenv(key, "verbose")
+
+		if out := FromEnv(key, Info); out != Info {
+			t.Errorf("unexpected output error: wanted %v ; got %v", Info, out)
+		}
+	})
+	t.Run("Unset", func(t *testing.T) {
+		if out := FromEnv(key, Warn); out != Warn {
+			t.Errorf("unexpected output error: wanted %v ; got %v", Warn, out)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package level
+
+import "strings"
+
+// RFC 5424 severities, as used by syslog
+const (
+	SyslogEmergency = iota
+	SyslogAlert
+	SyslogCritical
+	SyslogError
+	SyslogWarning
+	SyslogNotice
+	SyslogInformational
+	SyslogDebug
+)
+
+// OpenTelemetry SeverityNumbers, the first of each range of 4
+const (
+	OTelTrace = 1
+	OTelDebug = 5
+	OTelInfo  = 9
+	OTelWarn  = 13
+	OTelError = 17
+	OTelFatal = 21
+
+	otelMax = 24
+)
+
+// severity is a rung of the severity ladder shared by the syslog and Cloud
+// Logging mappings, from the highest level
+type severity struct {
+	level  lv
+	syslog int
+	name   string
+	gcp    string
+}
+
+var severities = [...]severity{
+	{lFatal, SyslogCritical, "Critical", "CRITICAL"},
+	{lError, SyslogError, "Error", "ERROR"},
+	{lWarn, SyslogWarning, "Warning", "WARNING"},
+	{lInfo, SyslogInformational, "Informational", "INFO"},
+}
+
+// debugSeverity covers the levels below Info
+var debugSeverity = severity{lDebug, SyslogDebug, "Debug", "DEBUG"}
+
+// ladder returns the rung of the severity ladder for the level `l`
+func ladder(l Level) severity {
+	for _, s := range severities {
+		if l.Int() >= int(s.level) {
+			return s
+		}
+	}
+	return debugSeverity
+}
+
+// SyslogSeverity maps the level `l` to an RFC 5424 severity code: Critical for
+// Fatal, Error, Warning, Informational for Info, and Debug for the levels below
+// it. The levels in between are mapped like the named level below them, and
+// nil like Info
+func SyslogSeverity(l Level) int {
+	if l == nil {
+		return SyslogInformational
+	}
+	return ladder(l).syslog
+}
+
+// SyslogName returns the RFC 5424 name of the severity of the level `l` (see
+// SyslogSeverity), like "Warning" or "Informational"
+func SyslogName(l Level) string {
+	if l == nil {
+		return "Informational"
+	}
+	return ladder(l).name
+}
+
+// SyslogPriority returns the syslog PRI value of a message with the facility
+// code `facility` (like 1 for user-level messages) and the level `l`
+func SyslogPriority(facility int, l Level) int {
+	return facility<<3 | SyslogSeverity(l)
+}
+
+// FromSyslogSeverity maps the RFC 5424 severity code `code` to a level, as the
+// reverse of SyslogSeverity: Emergency and Alert are mapped to Fatal, and
+// Notice to Info. Returns nil for unknown codes
+func FromSyslogSeverity(code int) Level {
+	switch code {
+	case SyslogEmergency, SyslogAlert, SyslogCritical:
+		return Fatal
+	case SyslogError:
+		return Error
+	case SyslogWarning:
+		return Warn
+	case SyslogNotice, SyslogInformational:
+		return Info
+	case SyslogDebug:
+		return Debug
+	default:
+		return nil
+	}
+}
+
+// OTelSeverity maps the level `l` to an OpenTelemetry SeverityNumber. As both
+// scales are spaced by 4, the levels between the named ones (like
+// Info.Offset(1)) are mapped to the severities in between (like INFO2), and
+// the levels beyond the scale are clamped to it. Returns 0 (unspecified) for
+// nil
+func OTelSeverity(l Level) int {
+	if l == nil {
+		return 0
+	}
+	return min(max(l.Int()-int(lTrace)+OTelTrace, OTelTrace), otelMax)
+}
+
+// FromOTelSeverity maps the OpenTelemetry SeverityNumber `n` to a level, as
+// the reverse of OTelSeverity. Returns nil for 0 (unspecified) or below
+func FromOTelSeverity(n int) Level {
+	if n <= 0 {
+		return nil
+	}
+	return Trace.Offset(n - OTelTrace)
+}
+
+// GCPSeverity maps the level `l` to a Cloud Logging LogSeverity: CRITICAL for
+// Fatal, ERROR, WARNING, INFO, and DEBUG for the levels below Info. The levels
+// in between are mapped like the named level below them, and nil to DEFAULT
+func GCPSeverity(l Level) string {
+	if l == nil {
+		return "DEFAULT"
+	}
+	return ladder(l).gcp
+}
+
+// FromGCPSeverity maps the Cloud Logging LogSeverity `s` to a level, as the
+// reverse of GCPSeverity: ALERT and EMERGENCY are mapped to Fatal, and NOTICE
+// to Info. Returns nil for DEFAULT and unknown severities
+func FromGCPSeverity(s string) Level {
+	switch strings.ToUpper(s) {
+	case "EMERGENCY", "ALERT", "CRITICAL":
+		return Fatal
+	case "ERROR":
+		return Error
+	case "WARNING":
+		return Warn
+	case "NOTICE", "INFO":
+		return Info
+	case "DEBUG":
+		return Debug
+	default:
+		return nil
+	}
+}
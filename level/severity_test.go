@@ -0,0 +1,102 @@
+package level
+
+import "testing"
+
+func TestSeverity(t *testing.T) {
+	t.Run("Syslog", func(t *testing.T) {
+		for _, test := range []struct {
+			lv    Level
+			code  int
+			name  string
+			level Level
+		}{
+			{nil, SyslogInformational, "Informational", Info},
+			{Trace, SyslogDebug, "Debug", Debug},
+			{Debug, SyslogDebug, "Debug", Debug},
+			{Info, SyslogInformational, "Informational", Info},
+			{Info.Offset(2), SyslogInformational, "Informational", Info},
+			{Warn, SyslogWarning, "Warning", Warn},
+			{Error, SyslogError, "Error", Error},
+			{Fatal, SyslogCritical, "Critical", Fatal},
+			{Fatal.Offset(3), SyslogCritical, "Critical", Fatal},
+		} {
+			if got := SyslogSeverity(test.lv); got != test.code {
+				t.Errorf("output mismatch error for %v: wanted %v ; got %v", test.lv, test.code, got)
+			}
+			if got := SyslogName(test.lv); got != test.name {
+				t.Errorf("output mismatch error for %v: wanted %v ; got %v", test.lv, test.name, got)
+			}
+			if got := FromSyslogSeverity(test.code); got != test.level {
+				t.Errorf("output mismatch error for %v: wanted %v ; got %v", test.code, test.level, got)
+			}
+		}
+
+		if got := SyslogPriority(1, Error); got != 11 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 11, got)
+		}
+		if got := FromSyslogSeverity(SyslogAlert); got != Fatal {
+			t.Errorf("output mismatch error: wanted %v ; got %v", Fatal, got)
+		}
+		if got := FromSyslogSeverity(9); got != nil {
+			t.Errorf("output mismatch error: wanted %v ; got %v", nil, got)
+		}
+	})
+	t.Run("OTel", func(t *testing.T) {
+		for _, test := range []struct {
+			lv    Level
+			wants int
+		}{
+			{nil, 0},
+			{Trace, OTelTrace},
+			{Debug, OTelDebug},
+			{Info, OTelInfo},
+			{Info.Offset(1), OTelInfo + 1},
+			{Warn, OTelWarn},
+			{Error, OTelError},
+			{Fatal, OTelFatal},
+			{Fatal.Offset(10), 24},
+			{Trace.Offset(-4), OTelTrace},
+		} {
+			if got := OTelSeverity(test.lv); got != test.wants {
+				t.Errorf("output mismatch error for %v: wanted %v ; got %v", test.lv, test.wants, got)
+			}
+		}
+
+		for _, lv := range []Level{Trace, Debug, Info.Offset(3), Warn, Error, Fatal} {
+			if got := FromOTelSeverity(OTelSeverity(lv)); got != lv {
+				t.Errorf("output mismatch error: wanted %v ; got %v", lv, got)
+			}
+		}
+		if got := FromOTelSeverity(0); got != nil {
+			t.Errorf("output mismatch error: wanted %v ; got %v", nil, got)
+		}
+	})
+	t.Run("GCP", func(t *testing.T) {
+		for _, test := range []struct {
+			lv    Level
+			wants string
+		}{
+			{nil, "DEFAULT"},
+			{Trace, "DEBUG"},
+			{Info, "INFO"},
+			{Warn.Offset(1), "WARNING"},
+			{Error, "ERROR"},
+			{Fatal, "CRITICAL"},
+		} {
+			if got := GCPSeverity(test.lv); got != test.wants {
+				t.Errorf("output mismatch error for %v: wanted %v ; got %v", test.lv, test.wants, got)
+			}
+		}
+
+		for s, wants := range map[string]Level{
+			"EMERGENCY": Fatal,
+			"notice":    Info,
+			"WARNING":   Warn,
+			"DEFAULT":   nil,
+		} {
+			if got := FromGCPSeverity(s); got != wants {
+				t.Errorf("output mismatch error for %v: wanted %v ; got %v", s, wants, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package level
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
+)
+
+// MarshalText implements encoding.TextMarshaler, encoding the level by its
+// name, or by its integer value if it has no name
+func (l lv) MarshalText() ([]byte, error) {
+	return []byte(text(l)), nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the level as a JSON string
+func (l lv) MarshalJSON() ([]byte, error) {
+	return json.Marshal(text(l))
+}
+
+// Var is a Level container that can be safely changed at runtime.
+//
+// It implements the Level interface itself, so a *Var can be used anywhere a
+// Level is expected (such as a Handler's WithLevel method), with changes to it
+// taking effect immediately.
+//
+// It also implements encoding.TextMarshaler, encoding.TextUnmarshaler,
+// json.Marshaler, json.Unmarshaler and flag.Value, so that levels round-trip
+// through configuration files and can be set with `flag.Var`.
+//
+// The zero value of a Var holds level Info
+type Var struct {
+	l atomic.Pointer[Level]
+}
+
+// NewVar creates a Var holding the input Level `l`
+func NewVar(l Level) *Var {
+	v := &Var{}
+	v.SetLevel(l)
+	return v
+}
+
+// Level returns the Level currently held by the Var
+func (v *Var) Level() Level {
+	if v == nil {
+		return Info
+	}
+	if l := v.l.Load(); l != nil && *l != nil {
+		return *l
+	}
+	return Info
+}
+
+// SetLevel replaces the Level held by the Var with `l`. A nil Level resets
+// the Var to level Info
+func (v *Var) SetLevel(l Level) {
+	if l == nil {
+		l = Info
+	}
+	v.l.Store(&l)
+}
+
+// String returns the level as a string
+func (v *Var) String() string {
+	return text(v.Level())
+}
+
+// Int returns the level as an int
+func (v *Var) Int() int {
+	return v.Level().Int()
+}
+
+// Offset returns a Level `n` steps above (or below, if negative) the
+// Level currently held by the Var
+func (v *Var) Offset(n int) Level {
+	return v.Level().Offset(n)
+}
+
+// Set implements flag.Value, parsing the input string `s` with Parse
+func (v *Var) Set(s string) error {
+	l, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	v.SetLevel(l)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (v *Var) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (v *Var) UnmarshalText(text []byte) error {
+	return v.Set(string(text))
+}
+
+// MarshalJSON implements json.Marshaler, encoding the level as a JSON string
+func (v *Var) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting either a JSON string
+// or a JSON number
+func (v *Var) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n int
+		if numErr := json.Unmarshal(data, &n); numErr != nil {
+			return ErrInvalidLevel
+		}
+		v.SetLevel(lv(n))
+		return nil
+	}
+	return v.Set(s)
+}
+
+func text(l Level) string {
+	if s := l.String(); s != "" {
+		return s
+	}
+	return strconv.Itoa(l.Int())
+}
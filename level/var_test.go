@@ -0,0 +1,99 @@
+package level
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"testing"
+)
+
+func TestLevelMarshal(t *testing.T) {
+	t.Run("Named", func(t *testing.T) {
+		out, err := json.Marshal(map[string]Level{"level": Warn})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if wants := `{"level":"warn"}`; string(out) != wants {
+			t.Errorf("unexpected output error: wanted %s ; got %s", wants, string(out))
+		}
+	})
+	t.Run("Offset", func(t *testing.T) {
+		out, err := Debug.Offset(-2).(lv).MarshalText()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if wants := "trace+2"; string(out) != wants {
+			t.Errorf("unexpected output error: wanted %s ; got %s", wants, string(out))
+		}
+	})
+}
+
+func TestVar(t *testing.T) {
+	t.Run("ZeroValue", func(t *testing.T) {
+		v := &Var{}
+
+		if v.Level() != Info {
+			t.Errorf("unexpected output error: wanted %v ; got %v", Info, v.Level())
+		}
+	})
+	t.Run("SetLevel", func(t *testing.T) {
+		v := NewVar(Debug)
+		v.SetLevel(Error)
+
+		if v.Int() != Error.Int() || v.String() != Error.String() {
+			t.Errorf("unexpected output error: wanted %v ; got %v", Error, v)
+		}
+	})
+	t.Run("JSON", func(t *testing.T) {
+		var conf struct {
+			Level *Var `json:"level"`
+		}
+
+		if err := json.Unmarshal([]byte(`{"level":"ERROR"}`), &conf); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if conf.Level.Level() != Error {
+			t.Errorf("unexpected output error: wanted %v ; got %v", Error, conf.Level.Level())
+		}
+
+		if err := json.Unmarshal([]byte(`{"level":4}`), &conf); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if conf.Level.Level() != Warn {
+			t.Errorf("unexpected output error: wanted %v ; got %v", Warn, conf.Level.Level())
+		}
+
+		out, err := json.Marshal(conf)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if wants := `{"level":"warn"}`; string(out) != wants {
+			t.Errorf("unexpected output error: wanted %s ; got %s", wants, string(out))
+		}
+	})
+	t.Run("InvalidJSON", func(t *testing.T) {
+		v := &Var{}
+
+		if err := json.Unmarshal([]byte(`{}`), v); !errors.Is(err, ErrInvalidLevel) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrInvalidLevel, err)
+		}
+	})
+	t.Run("Flag", func(t *testing.T) {
+		v := NewVar(Info)
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.Var(v, "level", "log level")
+
+		if err := fs.Parse([]string{"-level", "debug"}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if v.Level() != Debug {
+			t.Errorf("unexpected output error: wanted %v ; got %v", Debug, v.Level())
+		}
+	})
+}
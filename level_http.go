@@ -0,0 +1,43 @@
+//go:build !logx_tiny
+
+package logx
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/zalgonoise/logx/level"
+)
+
+type levelPayload struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler returns an http.Handler allowing the level.Var `v` to be
+// reported and changed at runtime:
+//   - a GET request returns the current level as `{"level":"info"}`
+//   - a PUT request with a body like `{"level":"debug"}` changes the level,
+//     returning the new level
+func LevelHandler(v *level.Var) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, httpError{Error: err.Error()})
+				return
+			}
+			if err := v.Set(req.Level); err != nil {
+				writeJSON(w, http.StatusBadRequest, httpError{Error: err.Error()})
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeJSON(w, http.StatusMethodNotAllowed, httpError{Error: "method not allowed"})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, levelPayload{Level: v.String()})
+	})
+}
@@ -0,0 +1,63 @@
+//go:build !logx_tiny
+
+package logx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestLevelHandler(t *testing.T) {
+	t.Run("Get", func(t *testing.T) {
+		v := level.NewVar(level.Warn)
+		rec := httptest.NewRecorder()
+
+		LevelHandler(v).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/level", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("unexpected status code: wanted %v ; got %v", http.StatusOK, rec.Code)
+		}
+		if wants := `{"level":"warn"}`; strings.TrimSpace(rec.Body.String()) != wants {
+			t.Errorf("unexpected output error: wanted %s ; got %s", wants, rec.Body.String())
+		}
+	})
+	t.Run("Put", func(t *testing.T) {
+		v := level.NewVar(level.Warn)
+		rec := httptest.NewRecorder()
+
+		LevelHandler(v).ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/level", strings.NewReader(`{"level":"debug"}`)))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("unexpected status code: wanted %v ; got %v", http.StatusOK, rec.Code)
+		}
+		if v.Level() != level.Debug {
+			t.Errorf("unexpected output error: wanted %v ; got %v", level.Debug, v.Level())
+		}
+	})
+	t.Run("PutInvalid", func(t *testing.T) {
+		v := level.NewVar(level.Warn)
+		rec := httptest.NewRecorder()
+
+		LevelHandler(v).ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/level", strings.NewReader(`{"level":"verbose"}`)))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("unexpected status code: wanted %v ; got %v", http.StatusBadRequest, rec.Code)
+		}
+		if v.Level() != level.Warn {
+			t.Errorf("unexpected output error: wanted %v ; got %v", level.Warn, v.Level())
+		}
+	})
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		LevelHandler(level.NewVar(level.Info)).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/level", nil))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("unexpected status code: wanted %v ; got %v", http.StatusMethodNotAllowed, rec.Code)
+		}
+	})
+}
@@ -1,6 +1,8 @@
 package logx
 
 import (
+	"context"
+
 	"github.com/zalgonoise/attr"
 	"github.com/zalgonoise/logx/level"
 )
@@ -47,6 +49,48 @@ func Log(level level.Level, msg string, attrs ...attr.Attr) {
 	std.Log(level, msg, attrs...)
 }
 
+// TraceContext prints the input `msg` and `attrs` attributes, along with
+// those extracted from `ctx`, as a Trace-level log message
+func TraceContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	std.TraceContext(ctx, msg, attrs...)
+}
+
+// DebugContext prints the input `msg` and `attrs` attributes, along with
+// those extracted from `ctx`, as a Debug-level log message
+func DebugContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	std.DebugContext(ctx, msg, attrs...)
+}
+
+// InfoContext prints the input `msg` and `attrs` attributes, along with
+// those extracted from `ctx`, as a Info-level log message
+func InfoContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	std.InfoContext(ctx, msg, attrs...)
+}
+
+// WarnContext prints the input `msg` and `attrs` attributes, along with
+// those extracted from `ctx`, as a Warn-level log message
+func WarnContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	std.WarnContext(ctx, msg, attrs...)
+}
+
+// ErrorContext prints the input `msg` and `attrs` attributes, along with
+// those extracted from `ctx`, as a Error-level log message
+func ErrorContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	std.ErrorContext(ctx, msg, attrs...)
+}
+
+// FatalContext prints the input `msg` and `attrs` attributes, along with
+// those extracted from `ctx`, as a Fatal-level log message
+func FatalContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	std.FatalContext(ctx, msg, attrs...)
+}
+
+// LogContext prints the input `msg` and `attrs` attributes, along with
+// those extracted from `ctx`, as a log message with level `level`
+func LogContext(ctx context.Context, level level.Level, msg string, attrs ...attr.Attr) {
+	std.LogContext(ctx, level, msg, attrs...)
+}
+
 // SetDefault replaces this library's standard logger with `l`
 func SetDefault(l Logger) {
 	std = l
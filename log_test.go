@@ -17,7 +17,7 @@ var (
 
 func setup() {
 	stdL = Default()
-	SetDefault(New(h))
+	SetDefault(New(WithHandler(h)))
 }
 func teardown() {
 	SetDefault(stdL)
@@ -2,11 +2,13 @@ package logx
 
 import (
 	"os"
+	"sync/atomic"
+	"time"
 
 	"github.com/zalgonoise/attr"
 	"github.com/zalgonoise/logx/handlers"
-	"github.com/zalgonoise/logx/handlers/jsonh"
 	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
 )
 
 // Logger interface describes the behavior that a logger should
@@ -17,6 +19,9 @@ import (
 type Logger interface {
 	// Printer interface allows registering log messages
 	Printer
+	// ContextPrinter interface allows registering log messages with the
+	// attributes extracted from a Context
+	ContextPrinter
 	// Enabled returns a boolean on whether the logger is accepting
 	// records with log level `level`
 	Enabled(level level.Level) bool
@@ -25,22 +30,57 @@ type Logger interface {
 	// With will spawn a copy of this Logger with the input attributes
 	// `attrs`
 	With(attrs ...attr.Attr) Logger
+	// WithClock will spawn a copy of this Logger using the input Clock
+	// `clock` as the source of its records' timestamps
+	WithClock(clock records.Clock) Logger
+	// WithSequence will spawn a copy of this Logger that attaches a
+	// monotonic sequence number (as a `seq` attribute) to its records, if
+	// `enabled` is true
+	WithSequence(enabled bool) Logger
+	// Named will spawn a copy of this Logger with the input name segment
+	// `name` appended to its name, with a dot (e.g. "http" and "server" as
+	// "http.server"). Its records carry the name in a handlers.LoggerKey
+	// attribute
+	Named(name string) Logger
 }
 
-var std = New(jsonh.New(os.Stderr))
+var std = New(WithHandler(newHandler(FormatJSON, os.Stderr)))
 
 type logger struct {
-	h     handlers.Handler
-	attrs []attr.Attr
+	h          handlers.Handler
+	attrs      []attr.Attr
+	clock      records.Clock
+	seq        *atomic.Uint64
+	module     *module
+	registry   *Registry
+	name       string
+	pool       bool
+	onError    ErrorHandler
+	extractors []Extractor
+	pprofKeys  []string
 }
 
-// New spawns a new logger based on the handler `h`
-func New(h handlers.Handler) Logger {
-	if h == nil {
-		h = handlers.Unimpl()
+// New spawns a new logger configured with the input Options `opts`
+//
+// With no options, the logger writes JSON records to os.Stderr. Use the
+// WithHandler option to provide a custom Handler, or the WithWriter and
+// WithFormat options for the common setups
+func New(opts ...Option) Logger {
+	c := &config{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
 	}
+
 	return &logger{
-		h: h,
+		h:          c.handler(),
+		attrs:      c.attrs,
+		clock:      c.clock,
+		pool:       c.pool,
+		onError:    c.onError,
+		extractors: c.extractors,
+		pprofKeys:  c.pprofKeys,
 	}
 }
 
@@ -54,17 +94,65 @@ func Default() Logger {
 func With(attrs ...attr.Attr) Logger {
 	return &logger{
 		h:     std.Handler(),
-		attrs: attrs,
+		attrs: handlers.TracedCaller("With", 1, attrs...),
 	}
 }
 
 // With will spawn a copy of this Logger with the input attributes
 // `attrs`
 func (l *logger) With(attrs ...attr.Attr) Logger {
-	return &logger{
-		h:     l.h,
-		attrs: attrs,
+	cp := *l
+	cp.attrs = handlers.TracedCaller("With", 1, attrs...)
+	return &cp
+}
+
+// WithClock will spawn a copy of this Logger using the input Clock
+// `clock` as the source of its records' timestamps
+func (l *logger) WithClock(clock records.Clock) Logger {
+	cp := *l
+	cp.clock = clock
+	return &cp
+}
+
+// WithSequence will spawn a copy of this Logger that attaches a
+// monotonic sequence number (as a `seq` attribute) to its records, if
+// `enabled` is true
+//
+// The sequence counter is shared with the copies spawned from the
+// returned Logger (with its With and WithClock methods)
+func (l *logger) WithSequence(enabled bool) Logger {
+	cp := *l
+	cp.seq = nil
+	if enabled {
+		cp.seq = &atomic.Uint64{}
+	}
+	return &cp
+}
+
+// Named will spawn a copy of this Logger with the input name segment
+// `name` appended to its name, with a dot (e.g. "http" and "server" as
+// "http.server"). Its records carry the name in a handlers.LoggerKey
+// attribute, which is kept in the copies spawned from the returned Logger
+// (with its With* methods)
+//
+// For the Loggers from a Registry, the verbosity of the returned Logger is
+// driven by the level overrides for its full name
+func (l *logger) Named(name string) Logger {
+	cp := *l
+	if name == "" {
+		return &cp
+	}
+
+	if cp.name == "" {
+		cp.name = name
+	} else {
+		cp.name = l.name + "." + name
 	}
+	if cp.registry != nil {
+		cp.module = cp.registry.module(cp.name)
+		cp.h = newModuleHandler(cp.h, cp.module)
+	}
+	return &cp
 }
 
 // Enabled returns a boolean on whether the logger is accepting
@@ -73,10 +161,17 @@ func (l *logger) Enabled(level level.Level) bool {
 	if level == nil {
 		return true
 	}
-	return l.h.Enabled(level)
+	return l.module.enabled(level) && l.h.Enabled(level)
 }
 
 // Handler returns this Logger's Handler interface
 func (l *logger) Handler() handlers.Handler {
 	return l.h
 }
+
+func (l *logger) now() time.Time {
+	if l.clock == nil {
+		return time.Now()
+	}
+	return l.clock.Now()
+}
@@ -2,27 +2,33 @@ package logx
 
 import (
 	"bytes"
+	"context"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
 	"github.com/zalgonoise/logx/handlers/jsonh"
 	"github.com/zalgonoise/logx/handlers/texth"
 	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
 )
 
 func TestNewLogger(t *testing.T) {
 	b := &bytes.Buffer{}
 	h := jsonh.New(b)
 	t.Run("Success", func(t *testing.T) {
-		out := New(h)
+		out := New(WithHandler(h))
 
 		if out == nil {
 			t.Error("expected output not to be nil")
 		}
 	})
 	t.Run("Fail", func(t *testing.T) {
-		out := New(nil)
+		out := New(WithHandler(nil))
 		if out != nil {
 			t.Errorf("expected output to be nil; got %v", out)
 		}
@@ -82,7 +88,7 @@ func TestLoggerWith(t *testing.T) {
 	}
 
 	t.Run("Replace", func(t *testing.T) {
-		l := New(h)
+		l := New(WithHandler(h))
 		out := l.With(a1...)
 
 		if len(out.(*logger).attrs) != 1 {
@@ -94,7 +100,7 @@ func TestLoggerWith(t *testing.T) {
 		}
 	})
 	t.Run("Erase", func(t *testing.T) {
-		l := New(h).With(a2...)
+		l := New(WithHandler(h)).With(a2...)
 
 		if len(l.(*logger).attrs) != 2 {
 			t.Errorf("unexpected attributes length: %v", len(l.(*logger).attrs))
@@ -115,21 +121,21 @@ func TestLoggerEnabled(t *testing.T) {
 	hWarn := h.WithLevel(level.Warn)
 
 	t.Run("Default", func(t *testing.T) {
-		out := New(h)
+		out := New(WithHandler(h))
 
 		if !out.Enabled(level.Trace) {
 			t.Errorf("expected default logger to accept all levels")
 		}
 	})
 	t.Run("FilterWarn", func(t *testing.T) {
-		out := New(hWarn)
+		out := New(WithHandler(hWarn))
 
 		if out.Enabled(level.Info) {
 			t.Errorf("expected default logger to accept only accept Warn and above")
 		}
 	})
 	t.Run("NilInput", func(t *testing.T) {
-		out := New(hWarn)
+		out := New(WithHandler(hWarn))
 
 		if !out.Enabled(nil) {
 			t.Errorf("expected nil input to return true")
@@ -143,7 +149,7 @@ func TestLoggerHandler(t *testing.T) {
 	th := texth.New(b)
 
 	t.Run("JSONHandler", func(t *testing.T) {
-		l := New(jh)
+		l := New(WithHandler(jh))
 
 		out := l.Handler()
 
@@ -152,7 +158,7 @@ func TestLoggerHandler(t *testing.T) {
 		}
 	})
 	t.Run("TextHandler", func(t *testing.T) {
-		l := New(th)
+		l := New(WithHandler(th))
 
 		out := l.Handler()
 
@@ -161,3 +167,104 @@ func TestLoggerHandler(t *testing.T) {
 		}
 	})
 }
+
+func TestLoggerWithClock(t *testing.T) {
+	testTime := time.Unix(1668802887, 0).UTC()
+	clock := records.ClockFunc(func() time.Time { return testTime })
+
+	t.Run("FixedClock", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := New(WithHandler(jsonh.New(b))).WithClock(clock)
+
+		l.Info("test message")
+
+		wants := `{"timestamp":"2022-11-18T20:21:27Z","message":"test message","level":"info"}`
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants, b.String())
+		}
+	})
+	t.Run("PropagatedWith", func(t *testing.T) {
+		l := New(WithHandler(jsonh.New(&bytes.Buffer{}))).WithClock(clock)
+		out := l.With(attr.New("a", 1))
+
+		if out.(*logger).clock == nil {
+			t.Errorf("expected clock to be propagated in With")
+		}
+	})
+}
+
+func TestLoggerWithSequence(t *testing.T) {
+	t.Run("Enabled", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := New(WithHandler(texth.New(b))).WithSequence(true)
+
+		l.Info("first")
+		l.With(attr.New("a", 1)).Info("second")
+
+		wants := regexp.MustCompile(`first \[ seq: 1 \]\n.*second \[ a: 1 ; seq: 2 \]\n$`)
+		if !wants.MatchString(b.String()) {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants.String(), b.String())
+		}
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := New(WithHandler(texth.New(b))).WithSequence(true).WithSequence(false)
+
+		l.Info("message")
+
+		if strings.Contains(b.String(), "seq") {
+			t.Errorf("unexpected seq attribute in output: %s", b.String())
+		}
+	})
+}
+
+func TestLoggerProvenance(t *testing.T) {
+	handlers.TrackProvenance(true)
+	defer handlers.TrackProvenance(false)
+
+	b := &bytes.Buffer{}
+	l := New(WithHandler(handlers.Provenance(texth.New(b), "")), WithExtractors(ValueExtractor(tenantKey{}, "tenant")))
+
+	l.With(attr.String("user", "alice")).InfoContext(context.WithValue(context.Background(), tenantKey{}, "acme"), "message")
+
+	wants := regexp.MustCompile(`provenance: \[ tenant: Extractor ; user: With \w+/logger_test.go:\d+ \]`)
+	if !wants.MatchString(b.String()) {
+		t.Errorf("output mismatch error: wanted %s ; got %s", wants.String(), b.String())
+	}
+}
+
+func TestLoggerNamed(t *testing.T) {
+	t.Run("Segments", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := New(WithHandler(texth.New(b))).Named("http").Named("server")
+
+		l.With(attr.New("a", 1)).Info("message")
+
+		wants := regexp.MustCompile(`message \[ a: 1 ; logger: http.server \]\n$`)
+		if !wants.MatchString(b.String()) {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants.String(), b.String())
+		}
+	})
+	t.Run("Empty", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		New(WithHandler(texth.New(b))).Named("").Info("message")
+
+		if strings.Contains(b.String(), "logger") {
+			t.Errorf("unexpected logger attribute in output: %s", b.String())
+		}
+	})
+	t.Run("Registry", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		r := NewRegistry()
+		r.SetLevel("http.*", level.Info)
+		r.SetLevel("http.client", level.Debug)
+
+		l := r.Named("http", texth.New(b))
+		l.Named("server").Debug("hidden")
+		l.Named("client").Debug("visible")
+
+		if strings.Contains(b.String(), "hidden") || !strings.Contains(b.String(), "logger: http.client") {
+			t.Errorf("output mismatch error: wanted only the http.client record ; got %s", b.String())
+		}
+	})
+}
@@ -0,0 +1,250 @@
+// Package logxbench provides load generators for logx Handlers, reporting
+// their throughput, allocations and drop rates, so that sink configurations
+// (like an Async queue's size, or a batching Handler's flush interval) can be
+// evaluated before they are rolled out to production.
+//
+// Run generates a load against a Handler, for a number of records or a
+// duration, while Benchmark does so within a Go benchmark:
+//
+//	func BenchmarkSink(b *testing.B) {
+//		logxbench.Benchmark(b, newSink(), logxbench.Shape{Attrs: 8, Depth: 2})
+//	}
+package logxbench
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/internal/stats"
+)
+
+const (
+	defaultRecords = 100_000
+
+	// latencySample is the ratio of the records whose Handle call latency is
+	// recorded
+	latencySample = 8
+)
+
+// Config configures the load generated by Run
+type Config struct {
+	// Shapes are the shapes of the generated records, used in turns. If empty,
+	// the records are shaped like a zero Shape
+	Shapes []Shape
+	// Records is the number of records to generate, 100000 by default. It is
+	// ignored if Duration is set
+	Records int
+	// Duration is how long to generate records for
+	Duration time.Duration
+	// Concurrency is the number of goroutines generating records, the value of
+	// GOMAXPROCS by default
+	Concurrency int
+	// Shutdown sets whether the Handler is shut down after the load (if it
+	// implements handlers.Shutdowner), so that the records it queued are
+	// flushed, and accounted for in the report
+	Shutdown bool
+}
+
+// Report holds the results of a load
+type Report struct {
+	// Records is the number of records generated
+	Records uint64
+	// Errors is the number of records whose Handle call returned an error
+	Errors uint64
+	// Dropped is the number of records discarded by the Async, Breaker and
+	// Quota handlers during the load. As it is read from the process-wide
+	// counters (see logx.ReadStats), it includes the records dropped by other
+	// Handlers at the same time
+	Dropped uint64
+	// Duration is how long the load took, including the Handler's shutdown
+	Duration time.Duration
+	// Throughput is the number of records generated per second
+	Throughput float64
+	// AllocsPerRecord is the number of heap allocations per record
+	AllocsPerRecord float64
+	// BytesPerRecord is the number of bytes allocated per record
+	BytesPerRecord float64
+	// P50, P99 and Max are the percentiles of the Handle call latency, from a
+	// sample of the records
+	P50, P99, Max time.Duration
+}
+
+// DropRate returns the ratio of the records that were dropped
+func (r Report) DropRate() float64 {
+	if r.Records == 0 {
+		return 0
+	}
+	return float64(r.Dropped) / float64(r.Records)
+}
+
+// ErrorRate returns the ratio of the records whose Handle call returned an
+// error
+func (r Report) ErrorRate() float64 {
+	if r.Records == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.Records)
+}
+
+// String implements fmt.Stringer, formatting the report as a single line
+func (r Report) String() string {
+	return fmt.Sprintf(
+		"records=%d duration=%s throughput=%.0f/s allocs/record=%.2f bytes/record=%.0f "+
+			"errors=%d (%.2f%%) dropped=%d (%.2f%%) p50=%s p99=%s max=%s",
+		r.Records, r.Duration, r.Throughput, r.AllocsPerRecord, r.BytesPerRecord,
+		r.Errors, r.ErrorRate()*100, r.Dropped, r.DropRate()*100, r.P50, r.P99, r.Max,
+	)
+}
+
+// Run generates the load configured in Config `conf` against the Handler `h`,
+// until the configured number of records or duration is reached, or until the
+// context `ctx` is done; returning a report of the Handler's performance.
+//
+// Returns an error if the Handler raised one while shutting down
+func Run(ctx context.Context, h handlers.Handler, conf Config) (Report, error) {
+	if h == nil {
+		h = handlers.Unimpl()
+	}
+	if conf.Concurrency <= 0 {
+		conf.Concurrency = runtime.GOMAXPROCS(0)
+	}
+	if conf.Records <= 0 {
+		conf.Records = defaultRecords
+	}
+
+	shapes := conf.Shapes
+	if len(shapes) == 0 {
+		shapes = []Shape{{}}
+	}
+	templates := make([]template, 0, len(shapes))
+	for _, s := range shapes {
+		templates = append(templates, s.template())
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		generated atomic.Uint64
+		errs      atomic.Uint64
+		latencies []time.Duration
+		limit     = uint64(conf.Records)
+		bounded   = conf.Duration <= 0
+	)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	dropped := stats.Dropped.Load()
+	start := time.Now()
+
+	if conf.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, conf.Duration)
+		defer cancel()
+	}
+
+	for i := 0; i < conf.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var sampled []time.Duration
+			for ctx.Err() == nil {
+				n := generated.Add(1)
+				if bounded && n > limit {
+					generated.Add(^uint64(0))
+					break
+				}
+
+				t := templates[int(n-1)%len(templates)]
+				if n%latencySample != 0 {
+					if err := h.Handle(t.record(time.Now())); err != nil {
+						errs.Add(1)
+					}
+					continue
+				}
+
+				ts := time.Now()
+				if err := h.Handle(t.record(ts)); err != nil {
+					errs.Add(1)
+				}
+				sampled = append(sampled, time.Since(ts))
+			}
+
+			mu.Lock()
+			latencies = append(latencies, sampled...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	var err error
+	if conf.Shutdown {
+		err = handlers.Shutdown(context.WithoutCancel(ctx), h)
+	}
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	r := Report{
+		Records:  generated.Load(),
+		Errors:   errs.Load(),
+		Dropped:  stats.Dropped.Load() - dropped,
+		Duration: elapsed,
+	}
+	if r.Records > 0 {
+		r.Throughput = float64(r.Records) / elapsed.Seconds()
+		r.AllocsPerRecord = float64(after.Mallocs-before.Mallocs) / float64(r.Records)
+		r.BytesPerRecord = float64(after.TotalAlloc-before.TotalAlloc) / float64(r.Records)
+	}
+	if len(latencies) > 0 {
+		slices.Sort(latencies)
+		r.P50 = percentile(latencies, 0.50)
+		r.P99 = percentile(latencies, 0.99)
+		r.Max = latencies[len(latencies)-1]
+	}
+
+	return r, err
+}
+
+// percentile returns the percentile `p` of the sorted durations `d`
+func percentile(d []time.Duration, p float64) time.Duration {
+	return d[int(p*float64(len(d)-1))]
+}
+
+// Benchmark generates records shaped like Shape `shape` against the Handler
+// `h` in the benchmark `b`, from parallel goroutines (see
+// testing.B.RunParallel), reporting its allocations, and its errors and drops
+// per record as the `errors/op` and `drops/op` metrics
+func Benchmark(b *testing.B, h handlers.Handler, shape Shape) {
+	b.Helper()
+
+	if h == nil {
+		h = handlers.Unimpl()
+	}
+	t := shape.template()
+
+	var errs atomic.Uint64
+	dropped := stats.Dropped.Load()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := h.Handle(t.record(time.Now())); err != nil {
+				errs.Add(1)
+			}
+		}
+	})
+	b.StopTimer()
+
+	b.ReportMetric(float64(errs.Load())/float64(b.N), "errors/op")
+	b.ReportMetric(float64(stats.Dropped.Load()-dropped)/float64(b.N), "drops/op")
+}
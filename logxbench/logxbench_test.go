@@ -0,0 +1,150 @@
+package logxbench
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+var errTestHandle = errors.New("handle failed")
+
+type testHandler struct {
+	handlers.Handler
+
+	mu  sync.Mutex
+	rs  []records.Record
+	err error
+}
+
+func (h *testHandler) Enabled(level.Level) bool { return true }
+
+func (h *testHandler) Handle(r records.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.rs = append(h.rs, r)
+	return h.err
+}
+
+func TestShape(t *testing.T) {
+	r := Shape{Level: level.Warn, Attrs: 5, Depth: 2, ValueSize: 3}.template().record(time.Now())
+
+	if r.Message() != defaultMessage || r.Level() != level.Warn {
+		t.Errorf("output mismatch error: wanted %v ; got %v", defaultMessage, r.Message())
+	}
+
+	attrs := r.Attrs()
+	if len(attrs) != 6 {
+		t.Errorf("output mismatch error: wanted %v attrs ; got %v", 6, len(attrs))
+		return
+	}
+	if attrs[0].Value() != "xxx" {
+		t.Errorf("output mismatch error: wanted %v ; got %v", "xxx", attrs[0].Value())
+	}
+
+	group, ok := attrs[5].Value().([]attr.Attr)
+	if !ok || len(group) != 1 || group[0].Key() != "group_1" {
+		t.Errorf("output mismatch error: wanted a nested group ; got %v", attrs[5])
+		return
+	}
+	if leaves, ok := group[0].Value().([]attr.Attr); !ok || len(leaves) != groupLeaves {
+		t.Errorf("output mismatch error: wanted %v leaves ; got %v", groupLeaves, group[0].Value())
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("Records", func(t *testing.T) {
+		h := &testHandler{}
+		r, err := Run(context.Background(), h, Config{
+			Shapes:      []Shape{{Message: "a"}, {Message: "b"}},
+			Records:     1000,
+			Concurrency: 4,
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if r.Records != 1000 || len(h.rs) != 1000 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v (%v handled)", 1000, r.Records, len(h.rs))
+		}
+		if r.Throughput <= 0 || r.Max < r.P99 || r.P99 < r.P50 {
+			t.Errorf("output mismatch error: wanted valid measurements ; got %v", r)
+		}
+
+		var a int
+		for _, rec := range h.rs {
+			if rec.Message() == "a" {
+				a++
+			}
+		}
+		if a != 500 {
+			t.Errorf("output mismatch error: wanted %v records of shape a ; got %v", 500, a)
+		}
+	})
+	t.Run("Errors", func(t *testing.T) {
+		r, _ := Run(context.Background(), &testHandler{err: errTestHandle}, Config{Records: 10})
+
+		if r.Errors != 10 || r.ErrorRate() != 1 {
+			t.Errorf("output mismatch error: wanted %v errors ; got %v", 10, r.Errors)
+		}
+	})
+	t.Run("Dropped", func(t *testing.T) {
+		block := make(chan struct{})
+		h := handlers.NewAsync(blockingHandler{block}, 1, handlers.DropNewest, 0)
+
+		r, err := Run(context.Background(), h, Config{Records: 100, Concurrency: 1})
+		close(block)
+		_ = h.Shutdown(context.Background())
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if r.Dropped == 0 || r.DropRate() <= 0 {
+			t.Errorf("output mismatch error: wanted dropped records ; got %v", r)
+		}
+		if !strings.Contains(r.String(), "dropped=") {
+			t.Errorf("output mismatch error: wanted a summary ; got %s", r)
+		}
+	})
+	t.Run("Duration", func(t *testing.T) {
+		r, err := Run(context.Background(), jsonh.New(io.Discard), Config{
+			Duration: 20 * time.Millisecond,
+			Shutdown: true,
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if r.Records == 0 || r.Duration < 20*time.Millisecond {
+			t.Errorf("output mismatch error: wanted a 20ms load ; got %v", r)
+		}
+	})
+}
+
+type blockingHandler struct {
+	block chan struct{}
+}
+
+func (blockingHandler) Enabled(level.Level) bool                                   { return true }
+func (h blockingHandler) Handle(records.Record) error                              { <-h.block; return nil }
+func (h blockingHandler) With(...attr.Attr) handlers.Handler                       { return h }
+func (h blockingHandler) WithSource(bool) handlers.Handler                         { return h }
+func (h blockingHandler) WithLevel(level.Leveler) handlers.Handler                 { return h }
+func (h blockingHandler) WithReplaceFn(func(attr.Attr) attr.Attr) handlers.Handler { return h }
+
+func BenchmarkJSON(b *testing.B) {
+	Benchmark(b, jsonh.New(io.Discard), Shape{Attrs: 8, Depth: 2})
+}
@@ -0,0 +1,103 @@
+package logxbench
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const (
+	defaultMessage   = "benchmark record"
+	defaultValueSize = 16
+
+	// groupLeaves is the number of attributes in the innermost group of a Shape
+	// with a Depth
+	groupLeaves = 4
+)
+
+// Shape describes the records generated by a load, so that the Handlers are
+// measured with records like the ones they will handle in production
+type Shape struct {
+	// Message is the records' message, "benchmark record" by default
+	Message string
+	// Level is the records' level, level.Info by default
+	Level level.Level
+	// Attrs is the number of attributes in the records, cycling through
+	// strings, integers, floats and booleans
+	Attrs int
+	// Depth is the nesting depth of a `group` attribute added to the records,
+	// whose innermost group holds one attribute of each kind. If zero, the
+	// records have no groups
+	Depth int
+	// ValueSize is the length of the string values, 16 by default
+	ValueSize int
+}
+
+// template holds the parts of the records of a Shape, built once and shared by
+// all records
+type template struct {
+	msg   string
+	level level.Level
+	attrs []attr.Attr
+}
+
+func (s Shape) template() template {
+	t := template{
+		msg:   s.Message,
+		level: s.Level,
+	}
+	if t.msg == "" {
+		t.msg = defaultMessage
+	}
+	if t.level == nil {
+		t.level = level.Info
+	}
+
+	size := s.ValueSize
+	if size <= 0 {
+		size = defaultValueSize
+	}
+	value := strings.Repeat("x", size)
+
+	t.attrs = make([]attr.Attr, 0, s.Attrs+1)
+	for i := 0; i < s.Attrs; i++ {
+		t.attrs = append(t.attrs, leaf("attr_"+strconv.Itoa(i), i, value))
+	}
+
+	if s.Depth > 0 {
+		group := make([]attr.Attr, 0, groupLeaves)
+		for i := 0; i < groupLeaves; i++ {
+			group = append(group, leaf("leaf_"+strconv.Itoa(i), i, value))
+		}
+		for i := s.Depth - 1; i > 0; i-- {
+			group = []attr.Attr{attr.New("group_"+strconv.Itoa(i), group)}
+		}
+		t.attrs = append(t.attrs, attr.New("group", group))
+	}
+
+	return t
+}
+
+// leaf returns the attribute `i` of a record, whose kind cycles through
+// strings, integers, floats and booleans
+func leaf(key string, i int, value string) attr.Attr {
+	switch i % 4 {
+	case 0:
+		return attr.String(key, value)
+	case 1:
+		return attr.Int(key, i)
+	case 2:
+		return attr.Float(key, float64(i)+0.5)
+	default:
+		return attr.New(key, true)
+	}
+}
+
+// record returns a new record from the template, timestamped with `ts`
+func (t template) record(ts time.Time) records.Record {
+	return records.New(ts, t.level, t.msg, t.attrs...)
+}
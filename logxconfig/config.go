@@ -0,0 +1,283 @@
+package logxconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/handlers/texth"
+	"github.com/zalgonoise/logx/level"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	formatJSON = "json"
+	formatText = "text"
+
+	outputStdout = "stdout"
+	outputStderr = "stderr"
+)
+
+var (
+	// ErrUnknownFormat is raised when an output is configured with a format
+	// that is not supported
+	ErrUnknownFormat error = errors.New("unknown output format")
+	// ErrInvalidRotation is raised when rotation is configured for an output
+	// that is not a file
+	ErrInvalidRotation error = errors.New("rotation is only supported for file outputs")
+)
+
+// Config describes a logger and its handler tree
+//
+// It is decoded from a YAML or JSON document, such as:
+//
+//	level: info
+//	outputs:
+//	  - format: text
+//	    path: stderr
+//	  - format: json
+//	    path: /var/log/app.log
+//	    level: debug
+//	    rotation:
+//	      max_size: 10485760
+//	      max_backups: 3
+//	    sampling:
+//	      every: 10
+//	  - format: http
+//	    options:
+//	      url: https://logs.example.com/ingest
+//	      timeout: 5s
+type Config struct {
+	// Level is the default verbosity for all outputs
+	Level string `yaml:"level" json:"level"`
+	// Source is the default setting for adding source information to records
+	Source bool `yaml:"source" json:"source"`
+	// Outputs lists the sinks for the logger. If empty, JSON records are
+	// written to standard error
+	Outputs []Output `yaml:"outputs" json:"outputs"`
+}
+
+// Output describes a single sink in the handler tree
+type Output struct {
+	// Format is the encoding of the records, either `json` (default) or `text`;
+	// or the name of a Handler registered with handlers.Register, built from
+	// the Options (with the output's writer in their `writer` key)
+	Format string `yaml:"format" json:"format"`
+	// Path is either `stdout`, `stderr` (default), or a file path
+	Path string `yaml:"path" json:"path"`
+	// Level overrides the Config's default verbosity for this output
+	Level string `yaml:"level" json:"level"`
+	// Source overrides the Config's default source setting for this output
+	Source *bool `yaml:"source" json:"source"`
+	// Rotation configures size-based file rotation for file outputs
+	Rotation *Rotation `yaml:"rotation" json:"rotation"`
+	// Sampling configures record sampling for this output
+	Sampling *Sampling `yaml:"sampling" json:"sampling"`
+	// Options holds the configuration of a registered Handler, as set in
+	// Format
+	Options map[string]any `yaml:"options" json:"options"`
+}
+
+// Rotation configures size-based rotation for a file output
+type Rotation struct {
+	// MaxSize is the size in bytes after which the file is rotated
+	MaxSize int64 `yaml:"max_size" json:"max_size"`
+	// MaxBackups is the number of rotated files to keep
+	MaxBackups int `yaml:"max_backups" json:"max_backups"`
+}
+
+// Sampling configures record sampling for an output
+type Sampling struct {
+	// Every keeps one record in every N records
+	Every int `yaml:"every" json:"every"`
+}
+
+// Parse decodes the YAML or JSON document `data` into a Config
+func Parse(data []byte) (*Config, error) {
+	conf := &Config{}
+	// YAML is a superset of JSON, so both are decoded the same way
+	if err := yaml.Unmarshal(data, conf); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// Load reads the YAML or JSON document in the file `path`, and builds a Logger
+// from it
+//
+// The returned io.Closer closes all files opened by the outputs, and should be
+// called when the Logger is no longer used
+func Load(path string) (logx.Logger, io.Closer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	conf, err := Parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conf.Build()
+}
+
+// Build creates a Logger with the handler tree described in the Config
+//
+// The returned io.Closer closes all files opened by the outputs, and should be
+// called when the Logger is no longer used
+func (c *Config) Build() (logx.Logger, io.Closer, error) {
+	h, closer, err := c.Handler()
+	if err != nil {
+		return nil, nil, err
+	}
+	return logx.New(logx.WithHandler(h)), closer, nil
+}
+
+// Handler creates the handler tree described in the Config
+//
+// The returned io.Closer closes all files opened by the outputs, and should be
+// called when the Handler is no longer used
+func (c *Config) Handler() (handlers.Handler, io.Closer, error) {
+	defaultLevel, err := parseLevel(c.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outputs := c.Outputs
+	if len(outputs) == 0 {
+		outputs = []Output{{}}
+	}
+
+	var (
+		closer = closers{}
+		hs     = make([]handlers.Handler, 0, len(outputs))
+	)
+	for idx, out := range outputs {
+		h, err := out.build(defaultLevel, c.Source, &closer)
+		if err != nil {
+			_ = closer.Close()
+			return nil, nil, fmt.Errorf("output #%d: %w", idx, err)
+		}
+		hs = append(hs, h)
+	}
+
+	return handlers.Multi(hs...), closer, nil
+}
+
+func (o Output) build(defaultLevel level.Level, defaultSource bool, closer *closers) (handlers.Handler, error) {
+	w, err := o.writer(closer)
+	if err != nil {
+		return nil, err
+	}
+
+	var h handlers.Handler
+	switch o.Format {
+	case "", formatJSON:
+		h = jsonh.New(w)
+	case formatText:
+		h = texth.New(w)
+	default:
+		config := make(map[string]any, len(o.Options)+1)
+		for k, v := range o.Options {
+			config[k] = v
+		}
+		config["writer"] = w
+
+		if h, err = handlers.Build(o.Format, config); err != nil {
+			if errors.Is(err, handlers.ErrUnknownHandler) {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, o.Format)
+			}
+			return nil, err
+		}
+		*closer = append(*closer, shutdownCloser{h})
+	}
+
+	lv := defaultLevel
+	if o.Level != "" {
+		if lv, err = parseLevel(o.Level); err != nil {
+			return nil, err
+		}
+	}
+	if lv != nil {
+		h = h.WithLevel(lv)
+	}
+
+	source := defaultSource
+	if o.Source != nil {
+		source = *o.Source
+	}
+	if source {
+		h = h.WithSource(true)
+	}
+
+	if o.Sampling != nil {
+		h = handlers.Sample(h, o.Sampling.Every)
+	}
+	// registered Handlers may not support all attributes
+	return handlers.Adapt(h), nil
+}
+
+func (o Output) writer(closer *closers) (io.Writer, error) {
+	switch o.Path {
+	case "", outputStderr:
+		if o.Rotation != nil {
+			return nil, ErrInvalidRotation
+		}
+		return os.Stderr, nil
+	case outputStdout:
+		if o.Rotation != nil {
+			return nil, ErrInvalidRotation
+		}
+		return os.Stdout, nil
+	}
+
+	var (
+		w   io.WriteCloser
+		err error
+	)
+	if o.Rotation != nil {
+		w, err = handlers.Rotate(o.Path, o.Rotation.MaxSize, o.Rotation.MaxBackups)
+	} else {
+		w, err = os.OpenFile(o.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	*closer = append(*closer, w)
+	return w, nil
+}
+
+func parseLevel(s string) (level.Level, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return level.Parse(s)
+}
+
+// shutdownCloser is an io.Closer that shuts down a registered Handler, which
+// owns the resources it opened
+type shutdownCloser struct {
+	h handlers.Handler
+}
+
+// Close implements io.Closer, shutting down the Handler
+func (c shutdownCloser) Close() error {
+	return handlers.Shutdown(context.Background(), c.h)
+}
+
+type closers []io.Closer
+
+// Close implements io.Closer, closing all files and returning the first error
+func (c closers) Close() error {
+	var err error
+	for _, closer := range c {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+package logxconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("YAML", func(t *testing.T) {
+		conf, err := Parse([]byte(`
+level: warn
+outputs:
+  - format: text
+    path: stdout
+    sampling:
+      every: 2
+`))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if conf.Level != "warn" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "warn", conf.Level)
+		}
+		if len(conf.Outputs) != 1 || conf.Outputs[0].Format != "text" || conf.Outputs[0].Sampling.Every != 2 {
+			t.Errorf("unexpected outputs: %+v", conf.Outputs)
+		}
+	})
+	t.Run("JSON", func(t *testing.T) {
+		conf, err := Parse([]byte(`{"level":"debug","outputs":[{"format":"json","path":"stderr"}]}`))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if conf.Level != "debug" || len(conf.Outputs) != 1 || conf.Outputs[0].Path != "stderr" {
+			t.Errorf("unexpected config: %+v", conf)
+		}
+	})
+}
+
+func TestBuild(t *testing.T) {
+	t.Run("FileOutputs", func(t *testing.T) {
+		dir := t.TempDir()
+		textPath := filepath.Join(dir, "text.log")
+		jsonPath := filepath.Join(dir, "json.log")
+
+		conf := &Config{
+			Level: "info",
+			Outputs: []Output{
+				{Format: "text", Path: textPath, Level: "warn"},
+				{Format: "json", Path: jsonPath, Rotation: &Rotation{MaxSize: 1 << 20, MaxBackups: 1}},
+			},
+		}
+
+		logger, closer, err := conf.Build()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		logger.Debug("debug message")
+		logger.Info("info message")
+		logger.Warn("warn message")
+		if err := closer.Close(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		text, _ := os.ReadFile(textPath)
+		if strings.Contains(string(text), "info message") || !strings.Contains(string(text), "warn message") {
+			t.Errorf("unexpected text output: %s", string(text))
+		}
+		jsonOut, _ := os.ReadFile(jsonPath)
+		if strings.Contains(string(jsonOut), "debug message") || !strings.Contains(string(jsonOut), "info message") {
+			t.Errorf("unexpected JSON output: %s", string(jsonOut))
+		}
+	})
+	t.Run("RegisteredFormat", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		conf := &Config{
+			Outputs: []Output{
+				{Format: "file", Options: map[string]any{"path": path, "format": "text"}},
+			},
+		}
+
+		logger, closer, err := conf.Build()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		logger.Info("info message")
+		if err := closer.Close(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		if out, _ := os.ReadFile(path); !strings.Contains(string(out), "[info] info message") {
+			t.Errorf("unexpected text output: %s", string(out))
+		}
+	})
+	t.Run("UnknownFormat", func(t *testing.T) {
+		conf := &Config{Outputs: []Output{{Format: "xml"}}}
+
+		if _, _, err := conf.Build(); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrUnknownFormat, err)
+		}
+	})
+	t.Run("InvalidLevel", func(t *testing.T) {
+		conf := &Config{Level: "verbose"}
+
+		if _, _, err := conf.Build(); !errors.Is(err, level.ErrInvalidLevel) {
+			t.Errorf("unexpected error: wanted %v ; got %v", level.ErrInvalidLevel, err)
+		}
+	})
+	t.Run("InvalidRotation", func(t *testing.T) {
+		conf := &Config{Outputs: []Output{{Path: "stdout", Rotation: &Rotation{MaxSize: 10}}}}
+
+		if _, _, err := conf.Build(); !errors.Is(err, ErrInvalidRotation) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrInvalidRotation, err)
+		}
+	})
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logx.yaml")
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	_ = os.WriteFile(path, []byte("outputs:\n  - format: text\n    path: "+logPath+"\n"), 0o644)
+
+	logger, closer, err := Load(path)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	logger.Info("loaded")
+	_ = closer.Close()
+
+	out, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(out), "[info] loaded") {
+		t.Errorf("unexpected output: %s", string(out))
+	}
+}
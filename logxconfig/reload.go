@@ -0,0 +1,201 @@
+package logxconfig
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers"
+)
+
+// defaultCloseGrace is the time the outputs of a replaced handler tree are kept
+// open for, for the records still being handled by it to be written
+const defaultCloseGrace = 5 * time.Second
+
+// Reloader keeps a Logger whose handler tree is built from a configuration
+// file, and rebuilds it when the file changes or when the process receives a
+// SIGHUP, without having to restart the process.
+//
+// The handler tree is swapped atomically: records are either handled by the
+// previous tree or by the new one. Files opened by the previous tree are closed
+// a few seconds after the swap, for the records still being handled by it to be
+// written; or when the Reloader is closed
+type Reloader struct {
+	path  string
+	swap  *handlers.Swap
+	grace time.Duration
+
+	mu       sync.Mutex
+	closer   io.Closer
+	draining []*drainingCloser
+	modTime  time.Time
+}
+
+// drainingCloser is the io.Closer of a replaced handler tree, closed once,
+// either after the grace period or when the Reloader is closed
+type drainingCloser struct {
+	once   sync.Once
+	closed atomic.Bool
+	c      io.Closer
+	err    error
+}
+
+func (d *drainingCloser) Close() error {
+	d.once.Do(func() {
+		d.err = d.c.Close()
+		d.closed.Store(true)
+	})
+	return d.err
+}
+
+// NewReloader creates a Reloader for the configuration file in `path`, loading
+// it for the first time
+func NewReloader(path string) (*Reloader, error) {
+	r := &Reloader{
+		path:  path,
+		swap:  handlers.NewSwap(nil),
+		grace: defaultCloseGrace,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Logger returns a Logger backed by the Reloader's current handler tree,
+// which follows the subsequent reloads
+func (r *Reloader) Logger() logx.Logger {
+	return logx.New(logx.WithHandler(r.swap))
+}
+
+// Handler returns a Handler backed by the Reloader's current handler tree,
+// which follows the subsequent reloads
+func (r *Reloader) Handler() handlers.Handler {
+	return r.swap
+}
+
+// Reload reads the configuration file and swaps the active handler tree with
+// the one it describes. If the configuration is invalid, an error is returned
+// and the active handler tree is kept.
+//
+// The files opened by the previous tree are closed after a grace period, so
+// the errors raised when closing them are not reported
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+	conf, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	h, closer, err := conf.Handler()
+	if err != nil {
+		return err
+	}
+
+	r.swap.Store(h)
+	prev := r.closer
+	r.closer = closer
+	r.modTime = info.ModTime()
+
+	if prev != nil {
+		r.drain(prev)
+	}
+	return nil
+}
+
+// drain closes the io.Closer `c` of a replaced handler tree after the
+// Reloader's grace period. The caller must hold the Reloader's lock
+func (r *Reloader) drain(c io.Closer) {
+	draining := r.draining[:0]
+	for _, d := range r.draining {
+		if !d.closed.Load() {
+			draining = append(draining, d)
+		}
+	}
+
+	d := &drainingCloser{c: c}
+	r.draining = append(draining, d)
+	time.AfterFunc(r.grace, func() { _ = d.Close() })
+}
+
+// Watch reloads the configuration whenever the process receives a SIGHUP (on the
+// platforms that support it), and whenever the configuration file's modification time changes (checked every
+// `interval`, if greater than zero), until the Context `ctx` is done.
+//
+// Reload errors are passed to `onError`, if not nil
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	sig := make(chan os.Signal, 1)
+	notifyReload(sig)
+	defer signal.Stop(sig)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case <-sig:
+			err = r.Reload()
+		case <-tick:
+			if r.changed() {
+				err = r.Reload()
+			}
+		}
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// Close closes the files opened by the active handler tree, as well as the
+// ones of the replaced trees still in their grace period
+func (r *Reloader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.swap.Store(nil)
+
+	errs := make([]error, 0, len(r.draining)+1)
+	for _, d := range r.draining {
+		errs = append(errs, d.Close())
+	}
+	r.draining = nil
+
+	if r.closer != nil {
+		errs = append(errs, r.closer.Close())
+		r.closer = nil
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Reloader) changed() bool {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !info.ModTime().Equal(r.modTime)
+}
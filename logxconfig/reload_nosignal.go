@@ -0,0 +1,9 @@
+//go:build js || wasip1 || plan9
+
+package logxconfig
+
+import "os"
+
+// notifyReload is a no-op on the platforms without SIGHUP, where the
+// configuration is only reloaded when the file changes
+func notifyReload(chan<- os.Signal) {}
@@ -0,0 +1,14 @@
+//go:build !js && !wasip1 && !plan9
+
+package logxconfig
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyReload relays the SIGHUP signals received by the process to `c`
+func notifyReload(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGHUP)
+}
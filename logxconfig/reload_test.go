@@ -0,0 +1,91 @@
+package logxconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, path, logPath, lv string) {
+	t.Helper()
+	conf := "level: " + lv + "\noutputs:\n  - format: text\n    path: " + logPath + "\n"
+	if err := os.WriteFile(path, []byte(conf), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	t.Run("Reload", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "logx.yaml")
+		logPath := filepath.Join(dir, "app.log")
+		writeConfig(t, path, logPath, "warn")
+
+		r, err := NewReloader(path)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		defer r.Close()
+
+		logger := r.Logger()
+		logger.Info("first")
+
+		writeConfig(t, path, logPath, "info")
+		if err := r.Reload(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		logger.Info("second")
+
+		out, _ := os.ReadFile(logPath)
+		if strings.Contains(string(out), "first") || !strings.Contains(string(out), "second") {
+			t.Errorf("unexpected output: %s", string(out))
+		}
+	})
+	t.Run("InvalidReloadKeepsTree", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "logx.yaml")
+		logPath := filepath.Join(dir, "app.log")
+		writeConfig(t, path, logPath, "info")
+
+		r, _ := NewReloader(path)
+		defer r.Close()
+
+		writeConfig(t, path, logPath, "verbose")
+		if err := r.Reload(); err == nil {
+			t.Errorf("expected an error")
+		}
+
+		r.Logger().Info("kept")
+		out, _ := os.ReadFile(logPath)
+		if !strings.Contains(string(out), "kept") {
+			t.Errorf("unexpected output: %s", string(out))
+		}
+	})
+	t.Run("DelayedClose", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "logx.yaml")
+		logPath := filepath.Join(dir, "app.log")
+		writeConfig(t, path, logPath, "info")
+
+		r, _ := NewReloader(path)
+		if err := r.Reload(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(r.draining) != 1 || r.draining[0].closed.Load() {
+			t.Errorf("expected the previous outputs to be kept open")
+			return
+		}
+		d := r.draining[0]
+
+		if err := r.Close(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !d.closed.Load() {
+			t.Errorf("expected the previous outputs to be closed")
+		}
+	})
+}
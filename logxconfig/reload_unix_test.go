@@ -0,0 +1,49 @@
+//go:build unix
+
+package logxconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestReloaderSIGHUP(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logx.yaml")
+	logPath := filepath.Join(dir, "app.log")
+	writeConfig(t, path, logPath, "warn")
+
+	r, _ := NewReloader(path)
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Watch(ctx, 0, nil)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	writeConfig(t, path, logPath, "info")
+	// wait for the signal handler to be registered
+	time.Sleep(50 * time.Millisecond)
+	_ = syscall.Kill(os.Getpid(), syscall.SIGHUP)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if r.Handler().Enabled(level.Info) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("expected configuration to be reloaded on SIGHUP")
+}
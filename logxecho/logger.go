@@ -0,0 +1,206 @@
+// Package logxecho integrates logx with Echo, providing an echo.Logger backed
+// by a logx.Logger and a request logging middleware
+package logxecho
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync/atomic"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/level"
+)
+
+// jsonMessage is the message used for records logged with the JSON variants
+// of the echo.Logger methods, when the input has no `message` key
+const jsonMessage = "echo"
+
+type echoLogger struct {
+	l      logx.Logger
+	prefix atomic.Pointer[string]
+	lvl    atomic.Uint32
+}
+
+// NewLogger returns an echo.Logger that writes to the Logger `logger`, to be
+// set as an Echo instance's Logger.
+//
+// Echo's levels are mapped to the corresponding logx levels; JSON values are
+// logged as attributes; and the prefix, if set, is logged as a `prefix`
+// attribute. The io.Writer returned by Output logs each line written to it as
+// an Info-level record, so that writers configured from it (like the one in
+// Echo's own Logger middleware) also flow into `logger`. SetOutput and
+// SetHeader have no effect
+func NewLogger(logger logx.Logger) echo.Logger {
+	if logger == nil {
+		logger = logx.Default()
+	}
+
+	l := &echoLogger{l: logger}
+	l.lvl.Store(uint32(log.DEBUG))
+	return l
+}
+
+// Output returns an io.Writer that logs each line written to it
+func (l *echoLogger) Output() io.Writer {
+	return lineWriter{l: l}
+}
+
+// SetOutput has no effect; records are written by the logx.Logger's Handler
+func (l *echoLogger) SetOutput(io.Writer) {}
+
+// Prefix returns the prefix set with SetPrefix
+func (l *echoLogger) Prefix() string {
+	if p := l.prefix.Load(); p != nil {
+		return *p
+	}
+	return ""
+}
+
+// SetPrefix sets the prefix logged as a `prefix` attribute
+func (l *echoLogger) SetPrefix(p string) {
+	l.prefix.Store(&p)
+}
+
+// Level returns the minimum Echo level being logged
+func (l *echoLogger) Level() log.Lvl {
+	return log.Lvl(l.lvl.Load())
+}
+
+// SetLevel sets the minimum Echo level being logged
+func (l *echoLogger) SetLevel(v log.Lvl) {
+	l.lvl.Store(uint32(v))
+}
+
+// SetHeader has no effect; records are formatted by the logx.Logger's Handler
+func (l *echoLogger) SetHeader(string) {}
+
+func (l *echoLogger) Print(i ...interface{}) { l.log(log.INFO, level.Info, fmt.Sprint(i...)) }
+func (l *echoLogger) Printf(format string, args ...interface{}) {
+	l.logf(log.INFO, level.Info, format, args)
+}
+func (l *echoLogger) Printj(j log.JSON)      { l.logj(log.INFO, level.Info, j) }
+func (l *echoLogger) Debug(i ...interface{}) { l.log(log.DEBUG, level.Debug, fmt.Sprint(i...)) }
+func (l *echoLogger) Debugf(format string, args ...interface{}) {
+	l.logf(log.DEBUG, level.Debug, format, args)
+}
+func (l *echoLogger) Debugj(j log.JSON)     { l.logj(log.DEBUG, level.Debug, j) }
+func (l *echoLogger) Info(i ...interface{}) { l.log(log.INFO, level.Info, fmt.Sprint(i...)) }
+func (l *echoLogger) Infof(format string, args ...interface{}) {
+	l.logf(log.INFO, level.Info, format, args)
+}
+func (l *echoLogger) Infoj(j log.JSON)      { l.logj(log.INFO, level.Info, j) }
+func (l *echoLogger) Warn(i ...interface{}) { l.log(log.WARN, level.Warn, fmt.Sprint(i...)) }
+func (l *echoLogger) Warnf(format string, args ...interface{}) {
+	l.logf(log.WARN, level.Warn, format, args)
+}
+func (l *echoLogger) Warnj(j log.JSON)       { l.logj(log.WARN, level.Warn, j) }
+func (l *echoLogger) Error(i ...interface{}) { l.log(log.ERROR, level.Error, fmt.Sprint(i...)) }
+func (l *echoLogger) Errorf(format string, args ...interface{}) {
+	l.logf(log.ERROR, level.Error, format, args)
+}
+func (l *echoLogger) Errorj(j log.JSON) { l.logj(log.ERROR, level.Error, j) }
+
+// Fatal logs the message with Fatal level, and exits the process
+func (l *echoLogger) Fatal(i ...interface{}) {
+	l.log(log.OFF, level.Fatal, fmt.Sprint(i...))
+	os.Exit(1)
+}
+
+// Fatalj logs the JSON value with Fatal level, and exits the process
+func (l *echoLogger) Fatalj(j log.JSON) {
+	l.logj(log.OFF, level.Fatal, j)
+	os.Exit(1)
+}
+
+// Fatalf logs the formatted message with Fatal level, and exits the process
+func (l *echoLogger) Fatalf(format string, args ...interface{}) {
+	l.logf(log.OFF, level.Fatal, format, args)
+	os.Exit(1)
+}
+
+// Panic logs the message with Fatal level, and panics with it
+func (l *echoLogger) Panic(i ...interface{}) {
+	msg := fmt.Sprint(i...)
+	l.log(log.OFF, level.Fatal, msg)
+	panic(msg)
+}
+
+// Panicj logs the JSON value with Fatal level, and panics with it
+func (l *echoLogger) Panicj(j log.JSON) {
+	l.logj(log.OFF, level.Fatal, j)
+	panic(j)
+}
+
+// Panicf logs the formatted message with Fatal level, and panics with it
+func (l *echoLogger) Panicf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	l.log(log.OFF, level.Fatal, msg)
+	panic(msg)
+}
+
+func (l *echoLogger) enabled(lvl log.Lvl) bool {
+	// Fatal and Panic are always logged, like in Echo's default logger
+	return lvl == log.OFF || lvl >= l.Level()
+}
+
+func (l *echoLogger) log(lvl log.Lvl, lv level.Level, msg string, attrs ...attr.Attr) {
+	if !l.enabled(lvl) {
+		return
+	}
+	if p := l.Prefix(); p != "" {
+		attrs = append(attrs, attr.String("prefix", p))
+	}
+	l.l.Log(lv, msg, attrs...)
+}
+
+func (l *echoLogger) logf(lvl log.Lvl, lv level.Level, format string, args []interface{}) {
+	if !l.enabled(lvl) {
+		return
+	}
+	l.log(lvl, lv, fmt.Sprintf(format, args...))
+}
+
+func (l *echoLogger) logj(lvl log.Lvl, lv level.Level, j log.JSON) {
+	if !l.enabled(lvl) {
+		return
+	}
+
+	msg := jsonMessage
+	if m, ok := j["message"].(string); ok && m != "" {
+		msg = m
+	}
+
+	keys := make([]string, 0, len(j))
+	for k := range j {
+		if k != "message" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	attrs := make([]attr.Attr, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, attr.New(k, j[k]))
+	}
+	l.log(lvl, lv, msg, attrs...)
+}
+
+// lineWriter logs each line written to it as an Info-level record
+type lineWriter struct {
+	l *echoLogger
+}
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte{'\n'}) {
+		if len(line) > 0 {
+			w.l.log(log.INFO, level.Info, string(line))
+		}
+	}
+	return len(p), nil
+}
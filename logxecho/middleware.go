@@ -0,0 +1,81 @@
+package logxecho
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/logxhttp"
+)
+
+const requestMessage = "http request"
+
+// Middleware returns an echo.MiddlewareFunc that logs each request with the
+// Logger `logger` once it is done, with its method, route, path, status,
+// duration, response size, real IP and request ID as attributes, and with a
+// level matching its status (see logxhttp.StatusLevel).
+//
+// Errors returned by the following handlers are bound to the response with
+// the Echo instance's HTTPErrorHandler, so that the logged status is the one
+// sent to the client, and are logged as an `error` attribute.
+//
+// The request ID is read from the request's headers (or from the response's,
+// if set by Echo's RequestID middleware), or generated if missing. The
+// request's context carries it (see logxhttp.RequestID), as well as a Logger
+// with the request ID, method and path as attributes, which is retrieved with
+// logx.From
+func Middleware(logger logx.Logger) echo.MiddlewareFunc {
+	if logger == nil {
+		logger = logx.Default()
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+			res := c.Response()
+
+			id := req.Header.Get(echo.HeaderXRequestID)
+			if !logxhttp.ValidRequestID(id) {
+				id = res.Header().Get(echo.HeaderXRequestID)
+			}
+			if !logxhttp.ValidRequestID(id) {
+				id = logxhttp.NewRequestID()
+			}
+			res.Header().Set(echo.HeaderXRequestID, id)
+
+			reqLogger := logger.With(
+				attr.String("request_id", id),
+				attr.String("method", req.Method),
+				attr.String("path", req.URL.Path),
+			)
+			ctx := logxhttp.WithRequestID(req.Context(), id)
+			c.SetRequest(req.WithContext(logx.InContext(ctx, reqLogger)))
+
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			attrs := []attr.Attr{
+				attr.String("method", req.Method),
+				attr.String("route", c.Path()),
+				attr.String("path", req.URL.Path),
+				attr.Int("status", res.Status),
+				attr.New("duration", time.Since(start)),
+				attr.Int("bytes", res.Size),
+				attr.String("remote_addr", c.RealIP()),
+				attr.String("request_id", id),
+			}
+			if err != nil {
+				attrs = append(attrs, attr.String("error", err.Error()))
+			}
+
+			logger.Log(logxhttp.StatusLevel(res.Status), requestMessage, attrs...)
+
+			// the error was already handled
+			return nil
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package logxecho
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/internal/logtest"
+)
+
+func newTestServer(b *bytes.Buffer) *echo.Echo {
+	e := echo.New()
+	e.Use(Middleware(logx.New(logx.WithHandler(jsonh.New(b)))))
+	e.GET("/items/:id", func(c echo.Context) error {
+		logx.From(c.Request().Context()).Info("fetching item")
+		return c.String(http.StatusOK, "item "+c.Param("id"))
+	})
+	e.GET("/fail", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusBadGateway, "backend unavailable")
+	})
+	e.GET("/internal", func(c echo.Context) error {
+		return errors.New("unexpected")
+	})
+	return e
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("LogsRequest", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+		req.Header.Set(echo.HeaderXRequestID, "abc123")
+		rec := httptest.NewRecorder()
+		newTestServer(b).ServeHTTP(rec, req)
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 2 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 2, len(entries))
+			return
+		}
+		if entries[0].Message != "fetching item" || entries[0].Data["request_id"] != "abc123" {
+			t.Errorf("output mismatch error: wanted the context logger's entry ; got %v", entries[0])
+		}
+
+		e := entries[1]
+		for key, wants := range map[string]any{
+			"method":     "GET",
+			"route":      "/items/:id",
+			"path":       "/items/42",
+			"status":     float64(200),
+			"bytes":      float64(len("item 42")),
+			"request_id": "abc123",
+		} {
+			if e.Data[key] != wants {
+				t.Errorf("output mismatch error for %s: wanted %v ; got %v", key, wants, e.Data[key])
+			}
+		}
+		if e.Level != "info" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "info", e.Level)
+		}
+		if rec.Header().Get(echo.HeaderXRequestID) != "abc123" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "abc123", rec.Header().Get(echo.HeaderXRequestID))
+		}
+	})
+	t.Run("HTTPError", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		rec := httptest.NewRecorder()
+		newTestServer(b).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+		if rec.Code != http.StatusBadGateway {
+			t.Errorf("output mismatch error: wanted %v ; got %v", http.StatusBadGateway, rec.Code)
+		}
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 1 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 1, len(entries))
+			return
+		}
+		if entries[0].Level != "error" || entries[0].Data["status"] != float64(502) || entries[0].Data["error"] == nil {
+			t.Errorf("output mismatch error: wanted the bound error at error level ; got %v at %s level", entries[0].Data, entries[0].Level)
+		}
+		if id, _ := entries[0].Data["request_id"].(string); id == "" || rec.Header().Get(echo.HeaderXRequestID) != id {
+			t.Errorf("output mismatch error: wanted a generated request ID ; got %q", id)
+		}
+	})
+	t.Run("Error", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		rec := httptest.NewRecorder()
+		newTestServer(b).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/internal", nil))
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("output mismatch error: wanted %v ; got %v", http.StatusInternalServerError, rec.Code)
+		}
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 1 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 1, len(entries))
+			return
+		}
+		if entries[0].Data["status"] != float64(500) || entries[0].Data["error"] != "unexpected" {
+			t.Errorf("output mismatch error: wanted the internal error ; got %v", entries[0].Data)
+		}
+	})
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("Levels", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := NewLogger(logx.New(logx.WithHandler(jsonh.New(b))))
+		l.SetLevel(log.WARN)
+		l.SetPrefix("api")
+
+		l.Info("skipped")
+		l.Warnf("slow request: %dms", 300)
+		l.Errorj(log.JSON{"message": "failed", "code": 7})
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 2 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 2, len(entries))
+			return
+		}
+		if entries[0].Message != "slow request: 300ms" || entries[0].Level != "warn" || entries[0].Data["prefix"] != "api" {
+			t.Errorf("output mismatch error: wanted the warning ; got %v", entries[0])
+		}
+		if entries[1].Message != "failed" || entries[1].Level != "error" || entries[1].Data["code"] != float64(7) {
+			t.Errorf("output mismatch error: wanted the JSON error ; got %v", entries[1])
+		}
+	})
+	t.Run("Output", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := NewLogger(logx.New(logx.WithHandler(jsonh.New(b))))
+
+		_, _ = l.Output().Write([]byte("first\nsecond\n"))
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 2 || entries[0].Message != "first" || entries[1].Message != "second" {
+			t.Errorf("output mismatch error: wanted one entry per line ; got %v", entries)
+		}
+	})
+	t.Run("Panic", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := NewLogger(logx.New(logx.WithHandler(jsonh.New(b))))
+
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Errorf("output mismatch error: wanted %v ; got %v", "boom", v)
+			}
+			entries := logtest.DecodeEntries(t, b)
+			if len(entries) != 1 || entries[0].Level != "fatal" {
+				t.Errorf("output mismatch error: wanted a fatal entry ; got %v", entries)
+			}
+		}()
+		l.Panic("boom")
+	})
+}
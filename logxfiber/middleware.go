@@ -0,0 +1,92 @@
+// Package logxfiber provides a Fiber middleware that logs requests with logx,
+// replacing Fiber's default logger middleware
+package logxfiber
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/logxhttp"
+)
+
+const requestMessage = "http request"
+
+// Middleware returns a fiber.Handler that logs each request with the Logger
+// `logger` once it is done, with its method, route, path, status, latency,
+// response size, client IP and request ID as attributes, and with a level
+// matching its status (see logxhttp.StatusLevel).
+//
+// Errors returned by the following handlers are passed to the app's
+// ErrorHandler, so that the logged status is the one sent to the client, and
+// are logged as an `error` attribute.
+//
+// The request ID is read from the request's headers (or from the response's,
+// if set by Fiber's requestid middleware), or generated if missing. The
+// user context (see fiber.Ctx.UserContext) carries it (see
+// logxhttp.RequestID), as well as a Logger with the request ID, method and
+// path as attributes, which is retrieved with logx.From.
+//
+// As fasthttp reuses its buffers across requests, all string attributes are
+// copied before being logged
+func Middleware(logger logx.Logger) fiber.Handler {
+	if logger == nil {
+		logger = logx.Default()
+	}
+
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		id := c.Get(fiber.HeaderXRequestID)
+		if !logxhttp.ValidRequestID(id) {
+			id = c.GetRespHeader(fiber.HeaderXRequestID)
+		}
+		if !logxhttp.ValidRequestID(id) {
+			id = logxhttp.NewRequestID()
+		}
+		id = strings.Clone(id)
+		c.Set(fiber.HeaderXRequestID, id)
+
+		var (
+			method = strings.Clone(c.Method())
+			path   = strings.Clone(c.Path())
+		)
+
+		reqLogger := logger.With(
+			attr.String("request_id", id),
+			attr.String("method", method),
+			attr.String("path", path),
+		)
+		ctx := logxhttp.WithRequestID(c.UserContext(), id)
+		c.SetUserContext(logx.InContext(ctx, reqLogger))
+
+		err := c.Next()
+		if err != nil {
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		attrs := []attr.Attr{
+			attr.String("method", method),
+			attr.String("route", strings.Clone(c.Route().Path)),
+			attr.String("path", path),
+			attr.Int("status", status),
+			attr.New("latency", time.Since(start)),
+			attr.Int("bytes", len(c.Response().Body())),
+			attr.String("client_ip", strings.Clone(c.IP())),
+			attr.String("request_id", id),
+		}
+		if err != nil {
+			attrs = append(attrs, attr.String("error", err.Error()))
+		}
+
+		logger.Log(logxhttp.StatusLevel(status), requestMessage, attrs...)
+
+		// the error was already handled
+		return nil
+	}
+}
@@ -0,0 +1,114 @@
+package logxfiber
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/internal/logtest"
+)
+
+func newTestApp(b *bytes.Buffer) *fiber.App {
+	app := fiber.New()
+	app.Use(Middleware(logx.New(logx.WithHandler(jsonh.New(b)))))
+	app.Get("/items/:id", func(c *fiber.Ctx) error {
+		logx.From(c.UserContext()).Info("fetching item")
+		return c.SendString("item " + c.Params("id"))
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusBadGateway, "backend unavailable")
+	})
+	app.Get("/internal", func(c *fiber.Ctx) error {
+		return errors.New("unexpected")
+	})
+	return app
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("LogsRequest", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+		req.Header.Set(fiber.HeaderXRequestID, "abc123")
+		res, err := newTestApp(b).Test(req)
+		if err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 2 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 2, len(entries))
+			return
+		}
+		if entries[0].Message != "fetching item" || entries[0].Data["request_id"] != "abc123" {
+			t.Errorf("output mismatch error: wanted the context logger's entry ; got %v", entries[0])
+		}
+
+		e := entries[1]
+		for key, wants := range map[string]any{
+			"method":     "GET",
+			"route":      "/items/:id",
+			"path":       "/items/42",
+			"status":     float64(200),
+			"bytes":      float64(len("item 42")),
+			"request_id": "abc123",
+		} {
+			if e.Data[key] != wants {
+				t.Errorf("output mismatch error for %s: wanted %v ; got %v", key, wants, e.Data[key])
+			}
+		}
+		if _, ok := e.Data["latency"].(float64); !ok {
+			t.Errorf("output mismatch error: wanted a latency attribute ; got %v", e.Data["latency"])
+		}
+		if e.Level != "info" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "info", e.Level)
+		}
+		if res.Header.Get(fiber.HeaderXRequestID) != "abc123" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "abc123", res.Header.Get(fiber.HeaderXRequestID))
+		}
+	})
+	t.Run("FiberError", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		res, err := newTestApp(b).Test(httptest.NewRequest(http.MethodGet, "/fail", nil))
+		if err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+		if res.StatusCode != fiber.StatusBadGateway {
+			t.Errorf("output mismatch error: wanted %v ; got %v", fiber.StatusBadGateway, res.StatusCode)
+		}
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 1 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 1, len(entries))
+			return
+		}
+		if entries[0].Level != "error" || entries[0].Data["status"] != float64(502) || entries[0].Data["error"] != "backend unavailable" {
+			t.Errorf("output mismatch error: wanted the handled error at error level ; got %v at %s level", entries[0].Data, entries[0].Level)
+		}
+		if id, _ := entries[0].Data["request_id"].(string); id == "" || res.Header.Get(fiber.HeaderXRequestID) != id {
+			t.Errorf("output mismatch error: wanted a generated request ID ; got %q", id)
+		}
+	})
+	t.Run("Error", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		res, err := newTestApp(b).Test(httptest.NewRequest(http.MethodGet, "/internal", nil))
+		if err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+		if res.StatusCode != fiber.StatusInternalServerError {
+			t.Errorf("output mismatch error: wanted %v ; got %v", fiber.StatusInternalServerError, res.StatusCode)
+		}
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 1 || entries[0].Data["status"] != float64(500) || entries[0].Data["error"] != "unexpected" {
+			t.Errorf("output mismatch error: wanted the internal error ; got %v", entries)
+		}
+	})
+}
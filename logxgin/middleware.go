@@ -0,0 +1,91 @@
+// Package logxgin provides a gin middleware that logs requests and recovers
+// from panics with logx, replacing gin's default Logger and Recovery
+// middlewares
+package logxgin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/logxhttp"
+)
+
+const (
+	requestMessage = "http request"
+	panicMessage   = "panic recovered"
+)
+
+// Middleware returns a gin.HandlerFunc that logs each request with the Logger
+// `logger` once it is done, with its method, route, path, status, duration,
+// response size, client IP and request ID as attributes, and with a level
+// matching its status (see logxhttp.StatusLevel). Errors attached to the gin
+// context are logged as an `errors` attribute.
+//
+// Panics raised by the following handlers are recovered, logged with Error
+// level along with the stack trace, and answered with a 500 status.
+//
+// The request ID is read from the request's headers, or generated if missing.
+// The request's context carries it (see logxhttp.RequestID), as well as a
+// Logger with the request ID, method and path as attributes, which is
+// retrieved with logx.From
+func Middleware(logger logx.Logger) gin.HandlerFunc {
+	if logger == nil {
+		logger = logx.Default()
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		id := c.GetHeader(logxhttp.DefaultRequestIDHeader)
+		if !logxhttp.ValidRequestID(id) {
+			id = logxhttp.NewRequestID()
+		}
+		c.Header(logxhttp.DefaultRequestIDHeader, id)
+
+		reqLogger := logger.With(
+			attr.String("request_id", id),
+			attr.String("method", c.Request.Method),
+			attr.String("path", c.Request.URL.Path),
+		)
+		ctx := logxhttp.WithRequestID(c.Request.Context(), id)
+		c.Request = c.Request.WithContext(logx.InContext(ctx, reqLogger))
+
+		defer func() {
+			if v := recover(); v != nil {
+				if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(v)
+				}
+
+				reqLogger.Error(panicMessage,
+					attr.String("panic", fmt.Sprint(v)),
+					attr.String("stack", string(debug.Stack())),
+				)
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+
+			attrs := []attr.Attr{
+				attr.String("method", c.Request.Method),
+				attr.String("route", c.FullPath()),
+				attr.String("path", c.Request.URL.Path),
+				attr.Int("status", c.Writer.Status()),
+				attr.New("duration", time.Since(start)),
+				attr.Int("bytes", max(c.Writer.Size(), 0)),
+				attr.String("client_ip", c.ClientIP()),
+				attr.String("request_id", id),
+			}
+			if len(c.Errors) > 0 {
+				attrs = append(attrs, attr.New("errors", c.Errors.Errors()))
+			}
+
+			logger.Log(logxhttp.StatusLevel(c.Writer.Status()), requestMessage, attrs...)
+		}()
+
+		c.Next()
+	}
+}
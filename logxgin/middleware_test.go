@@ -0,0 +1,108 @@
+package logxgin
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/internal/logtest"
+	"github.com/zalgonoise/logx/logxhttp"
+)
+
+func newTestRouter(b *bytes.Buffer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Middleware(logx.New(logx.WithHandler(jsonh.New(b)))))
+	r.GET("/items/:id", func(c *gin.Context) {
+		logx.From(c.Request.Context()).Info("fetching item")
+		c.String(http.StatusOK, "item %s", c.Param("id"))
+	})
+	r.GET("/fail", func(c *gin.Context) {
+		_ = c.Error(errors.New("backend unavailable"))
+		c.Status(http.StatusBadGateway)
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	return r
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("LogsRequest", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+		req.Header.Set(logxhttp.DefaultRequestIDHeader, "abc123")
+		rec := httptest.NewRecorder()
+		newTestRouter(b).ServeHTTP(rec, req)
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 2 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 2, len(entries))
+			return
+		}
+		if entries[0].Message != "fetching item" || entries[0].Data["request_id"] != "abc123" {
+			t.Errorf("output mismatch error: wanted the context logger's entry ; got %v", entries[0])
+		}
+
+		e := entries[1]
+		for key, wants := range map[string]any{
+			"method":     "GET",
+			"route":      "/items/:id",
+			"path":       "/items/42",
+			"status":     float64(200),
+			"bytes":      float64(len("item 42")),
+			"request_id": "abc123",
+		} {
+			if e.Data[key] != wants {
+				t.Errorf("output mismatch error for %s: wanted %v ; got %v", key, wants, e.Data[key])
+			}
+		}
+		if e.Level != "info" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "info", e.Level)
+		}
+		if rec.Header().Get(logxhttp.DefaultRequestIDHeader) != "abc123" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "abc123", rec.Header().Get(logxhttp.DefaultRequestIDHeader))
+		}
+	})
+	t.Run("Errors", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		newTestRouter(b).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 1 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 1, len(entries))
+			return
+		}
+		errs, _ := entries[0].Data["errors"].([]any)
+		if entries[0].Level != "error" || len(errs) != 1 || errs[0] != "backend unavailable" {
+			t.Errorf("output mismatch error: wanted the gin error at error level ; got %v at %s level", entries[0].Data, entries[0].Level)
+		}
+	})
+	t.Run("Recovery", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		rec := httptest.NewRecorder()
+		newTestRouter(b).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("output mismatch error: wanted %v ; got %v", http.StatusInternalServerError, rec.Code)
+		}
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 2 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 2, len(entries))
+			return
+		}
+		if entries[0].Message != panicMessage || entries[0].Data["panic"] != "boom" || entries[0].Data["stack"] == "" {
+			t.Errorf("output mismatch error: wanted the recovered panic ; got %v", entries[0])
+		}
+		if entries[1].Data["status"] != float64(500) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 500, entries[1].Data["status"])
+		}
+	})
+}
@@ -0,0 +1,178 @@
+// Package logxgrpc provides gRPC interceptors that log calls with logx
+package logxgrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/logxhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDKey is the metadata key used to propagate the request ID of the
+// call's context (see logxhttp.RequestID) to the server
+const RequestIDKey = "x-request-id"
+
+// DebugKey is the metadata key used to propagate the debug token of the call's
+// context (see logxhttp.Debug) to the server
+const DebugKey = "x-debug-log"
+
+const clientMessage = "grpc client call"
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that logs each
+// call with the Logger `logger` once it is done, with its method, target,
+// status code and duration as attributes.
+//
+// The call's context carries a Logger with the method and target as
+// attributes (derived from the context's Logger, if set), and its request ID
+// and debug token are propagated in the outgoing metadata
+func UnaryClientInterceptor(logger logx.Logger) grpc.UnaryClientInterceptor {
+	if logger == nil {
+		logger = logx.Default()
+	}
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+
+		err := invoker(callContext(ctx, logger, method, cc.Target()), method, req, reply, cc, opts...)
+		logCall(logger, method, cc.Target(), start, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor that logs
+// each stream with the Logger `logger` once it is done (when a message
+// receive fails, io.EOF is reached, the server's only message is received
+// for the streams without server streaming, or the call's context is done),
+// with the same attributes and context propagation as UnaryClientInterceptor
+func StreamClientInterceptor(logger logx.Logger) grpc.StreamClientInterceptor {
+	if logger == nil {
+		logger = logx.Default()
+	}
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+
+		stream, err := streamer(callContext(ctx, logger, method, cc.Target()), desc, cc, method, opts...)
+		if err != nil {
+			logCall(logger, method, cc.Target(), start, err)
+			return nil, err
+		}
+
+		return newClientStream(ctx, stream, desc.ServerStreams, func(err error) {
+			logCall(logger, method, cc.Target(), start, err)
+		}), nil
+	}
+}
+
+func callContext(ctx context.Context, logger logx.Logger, method, target string) context.Context {
+	if l, ok := ctx.Value(logx.StandardCtxKey).(logx.Logger); ok {
+		logger = l
+	}
+	ctx = logx.InContext(ctx, logger.With(
+		attr.String("grpc_method", method),
+		attr.String("grpc_target", target),
+	))
+
+	if id := logxhttp.RequestID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, RequestIDKey, id)
+	}
+	if token := logxhttp.Debug(ctx); token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, DebugKey, token)
+	}
+	return ctx
+}
+
+func logCall(logger logx.Logger, method, target string, start time.Time, err error) {
+	code := status.Code(err)
+
+	attrs := []attr.Attr{
+		attr.String("grpc_method", method),
+		attr.String("grpc_target", target),
+		attr.String("grpc_code", code.String()),
+		attr.New("duration", time.Since(start)),
+	}
+	if err != nil {
+		attrs = append(attrs, attr.String("error", err.Error()))
+	}
+
+	logger.Log(codeLevel(code), clientMessage, attrs...)
+}
+
+// codeLevel maps a gRPC status code to a log level: Info for OK, Warn for
+// codes caused by the caller, and Error for the remaining ones
+func codeLevel(code codes.Code) level.Level {
+	switch code {
+	case codes.OK:
+		return level.Info
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange:
+		return level.Warn
+	default:
+		return level.Error
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	serverStreams bool
+	once          sync.Once
+	stop          chan struct{}
+	done          func(err error)
+}
+
+// newClientStream wraps the grpc.ClientStream `stream` to call `done` once it
+// ends, or once the call's context `ctx` is done, as streams may be abandoned
+// by canceling their context without receiving their last message
+func newClientStream(ctx context.Context, stream grpc.ClientStream, serverStreams bool, done func(err error)) *clientStream {
+	s := &clientStream{
+		ClientStream:  stream,
+		serverStreams: serverStreams,
+		stop:          make(chan struct{}),
+		done:          done,
+	}
+
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				s.finish(status.FromContextError(ctx.Err()).Err())
+			case <-s.stop:
+			}
+		}()
+	}
+	return s
+}
+
+func (s *clientStream) finish(err error) {
+	s.once.Do(func() {
+		close(s.stop)
+		s.done(err)
+	})
+}
+
+// RecvMsg implements grpc.ClientStream, logging the stream once it ends
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		// without server streaming, the server sends a single message
+		if !s.serverStreams {
+			s.finish(nil)
+		}
+	case errors.Is(err, io.EOF):
+		s.finish(nil)
+	default:
+		s.finish(err)
+	}
+	return err
+}
@@ -0,0 +1,187 @@
+package logxgrpc
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/internal/logtest"
+	"github.com/zalgonoise/logx/logxhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// mdHealthServer records the request ID in the incoming metadata
+type mdHealthServer struct {
+	*health.Server
+	requestID chan string
+}
+
+func (s mdHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(RequestIDKey); len(ids) > 0 {
+		s.requestID <- ids[0]
+	}
+	return s.Server.Check(ctx, req)
+}
+
+func newTestClient(t *testing.T, l logx.Logger) (healthpb.HealthClient, mdHealthServer) {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	hs := mdHealthServer{Server: health.NewServer(), requestID: make(chan string, 1)}
+	hs.SetServingStatus("known", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(l)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(l)),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return healthpb.NewHealthClient(conn), hs
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	b := &bytes.Buffer{}
+	client, _ := newTestClient(t, logx.New(logx.WithHandler(jsonh.New(b))))
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "known"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"}); err == nil {
+		t.Errorf("expected an error for an unknown service")
+	}
+
+	entries := logtest.DecodeEntries(t, b)
+	if len(entries) != 2 {
+		t.Errorf("output mismatch error: wanted %v entries ; got %v", 2, len(entries))
+		return
+	}
+	if entries[0].Level != "info" || entries[0].Data["grpc_code"] != codes.OK.String() ||
+		entries[0].Data["grpc_method"] != "/grpc.health.v1.Health/Check" {
+		t.Errorf("output mismatch error: wanted an OK Check call at info level ; got %v at %s level", entries[0].Data, entries[0].Level)
+	}
+	if entries[1].Level != "warn" || entries[1].Data["grpc_code"] != codes.NotFound.String() {
+		t.Errorf("output mismatch error: wanted a NotFound call at warn level ; got %v at %s level", entries[1].Data, entries[1].Level)
+	}
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	b := &bytes.Buffer{}
+	client, _ := newTestClient(t, logx.New(logx.WithHandler(jsonh.New(b))))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "known"})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		cancel()
+		return
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if b.Len() != 0 {
+		t.Errorf("expected the stream not to be logged before it ends ; got %s", b.String())
+	}
+
+	cancel()
+	if _, err := stream.Recv(); err == nil {
+		t.Errorf("expected an error after canceling the stream")
+	}
+
+	entries := logtest.DecodeEntries(t, b)
+	if len(entries) != 1 {
+		t.Errorf("output mismatch error: wanted %v entries ; got %v", 1, len(entries))
+		return
+	}
+	if entries[0].Data["grpc_code"] != codes.Canceled.String() {
+		t.Errorf("output mismatch error: wanted %v ; got %v", codes.Canceled.String(), entries[0].Data["grpc_code"])
+	}
+}
+
+// fakeClientStream is a grpc.ClientStream receiving a message successfully
+type fakeClientStream struct {
+	grpc.ClientStream
+}
+
+func (fakeClientStream) RecvMsg(any) error {
+	return nil
+}
+
+func TestClientStreamDone(t *testing.T) {
+	t.Run("ClientStreaming", func(t *testing.T) {
+		var errs []error
+		s := newClientStream(context.Background(), fakeClientStream{}, false, func(err error) {
+			errs = append(errs, err)
+		})
+
+		_ = s.RecvMsg(nil)
+		_ = s.RecvMsg(nil)
+
+		if len(errs) != 1 || errs[0] != nil {
+			t.Errorf("output mismatch error: wanted a single successful call ; got %v", errs)
+		}
+	})
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		_ = newClientStream(ctx, fakeClientStream{}, true, func(err error) {
+			done <- err
+		})
+
+		cancel()
+		select {
+		case err := <-done:
+			if status.Code(err) != codes.Canceled {
+				t.Errorf("output mismatch error: wanted %v ; got %v", codes.Canceled, status.Code(err))
+			}
+		case <-time.After(time.Second):
+			t.Errorf("expected the stream to be logged once its context is canceled")
+		}
+	})
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	client, hs := newTestClient(t, logx.New(logx.WithHandler(jsonh.New(&bytes.Buffer{}))))
+
+	// the request ID is set in the context by the logxhttp middleware
+	var ctx context.Context
+	h := logxhttp.Middleware(logx.New(logx.WithHandler(jsonh.New(&bytes.Buffer{}))))(
+		http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			ctx = r.Context()
+		}),
+	)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(logxhttp.DefaultRequestIDHeader, "req-42")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "known"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if id := <-hs.requestID; id != "req-42" {
+		t.Errorf("output mismatch error: wanted %v ; got %v", "req-42", id)
+	}
+}
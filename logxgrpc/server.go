@@ -0,0 +1,81 @@
+package logxgrpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/logxhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DebugUnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
+// raises the verbosity of the call's context Logger to the level `lv` (or
+// level.Debug, if nil) for the calls carrying a valid debug token in their
+// DebugKey metadata, signed with the key `key` (see logxhttp.NewDebugToken).
+// This is the gRPC counterpart of logxhttp.WithDebugToken.
+//
+// The context Logger is the one set by a previous interceptor (see
+// logx.InContext), or `logger` otherwise. The token is kept in the call's
+// context (see logxhttp.Debug), to be propagated by the client interceptors.
+// Calls with a missing, invalid or expired token are served as usual, and an
+// empty key disables the interceptor
+func DebugUnaryServerInterceptor(logger logx.Logger, key []byte, lv level.Level) grpc.UnaryServerInterceptor {
+	if lv == nil {
+		lv = level.Debug
+	}
+
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(debugContext(ctx, logger, key, lv), req)
+	}
+}
+
+// DebugStreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// raises the verbosity of the stream's context Logger, like
+// DebugUnaryServerInterceptor
+func DebugStreamServerInterceptor(logger logx.Logger, key []byte, lv level.Level) grpc.StreamServerInterceptor {
+	if lv == nil {
+		lv = level.Debug
+	}
+
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := debugContext(ss.Context(), logger, key, lv)
+		if ctx == ss.Context() {
+			return handler(srv, ss)
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// debugContext returns a copy of the context `ctx` with a Logger raised to the
+// level `lv`, if its incoming metadata carries a valid debug token; or `ctx`
+// as-is otherwise
+func debugContext(ctx context.Context, logger logx.Logger, key []byte, lv level.Level) context.Context {
+	if len(key) == 0 {
+		return ctx
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get(DebugKey)
+	if len(tokens) == 0 || !logxhttp.VerifyDebugToken(key, tokens[0], time.Now()) {
+		return ctx
+	}
+
+	if l, ok := ctx.Value(logx.StandardCtxKey).(logx.Logger); ok {
+		logger = l
+	}
+	return logx.InContext(logxhttp.WithDebug(ctx, tokens[0]), logx.Verbose(logger, lv))
+}
+
+// serverStream overrides the context of a grpc.ServerStream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream, returning the overridden context
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,91 @@
+package logxgrpc
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/internal/logtest"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/logxhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeServerStream is a grpc.ServerStream with a context
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestDebugServerInterceptor(t *testing.T) {
+	key := []byte("secret")
+	valid := logxhttp.NewDebugToken(key, time.Now().Add(time.Minute))
+
+	for _, test := range []struct {
+		name   string
+		token  string
+		wants  int
+		traced bool
+	}{
+		{"ValidToken", valid, 1, true},
+		{"InvalidToken", logxhttp.NewDebugToken([]byte("other"), time.Now().Add(time.Minute)), 0, false},
+		{"NoToken", "", 0, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(DebugKey, test.token))
+			}
+
+			t.Run("Unary", func(t *testing.T) {
+				b := &bytes.Buffer{}
+				l := logx.New(logx.WithHandler(jsonh.New(b)), logx.WithLevel(level.Info))
+
+				var token string
+				_, err := DebugUnaryServerInterceptor(l, key, nil)(logx.InContext(ctx, l), nil, &grpc.UnaryServerInfo{},
+					func(ctx context.Context, _ any) (any, error) {
+						token = logxhttp.Debug(ctx)
+						logx.From(ctx).Debug("details")
+						return nil, nil
+					},
+				)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				if entries := logtest.DecodeEntries(t, b); len(entries) != test.wants {
+					t.Errorf("output mismatch error: wanted %v entries ; got %v", test.wants, len(entries))
+				}
+				if (token != "") != test.traced {
+					t.Errorf("output mismatch error: wanted the token in the context: %v ; got %q", test.traced, token)
+				}
+			})
+			t.Run("Stream", func(t *testing.T) {
+				b := &bytes.Buffer{}
+				l := logx.New(logx.WithHandler(jsonh.New(b)), logx.WithLevel(level.Info))
+
+				err := DebugStreamServerInterceptor(l, key, nil)(nil, fakeServerStream{ctx: logx.InContext(ctx, l)}, &grpc.StreamServerInfo{},
+					func(_ any, ss grpc.ServerStream) error {
+						logx.From(ss.Context()).Debug("details")
+						return nil
+					},
+				)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				if entries := logtest.DecodeEntries(t, b); len(entries) != test.wants {
+					t.Errorf("output mismatch error: wanted %v entries ; got %v", test.wants, len(entries))
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package logxhttp
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+)
+
+const defaultBodyMaxSize = 4096
+
+// defaultBodyContentTypes are the content types of the bodies captured by
+// default: JSON, XML, forms and text
+var defaultBodyContentTypes = []string{
+	"application/json", "+json",
+	"application/xml", "+xml",
+	"application/x-www-form-urlencoded",
+	"text/",
+}
+
+// BodyConfig describes the request and response bodies captured by the
+// Middleware, set with WithBodyCapture
+type BodyConfig struct {
+	// Request captures the request bodies, as read by the handler, in the
+	// `request_body` attribute
+	Request bool
+	// Response captures the response bodies in the `response_body` attribute
+	Response bool
+	// MaxSize is the number of bytes captured from each body, 4096 by default.
+	// The bodies cut short are flagged with a `request_body_truncated` or
+	// `response_body_truncated` attribute
+	MaxSize int
+	// ContentTypes are the media types of the bodies captured: either full
+	// ones (`application/json`), type prefixes (`text/`) or structured syntax
+	// suffixes (`+json`). Defaults to JSON, XML, form and text bodies
+	ContentTypes []string
+	// Level is the minimum level of the requests' records with their bodies
+	// attached, if set. For example, level.Warn only logs the bodies of the
+	// failed requests
+	Level level.Level
+	// Rules are the ScrubRules masking the personal data in the bodies before
+	// they are attached. Defaults to handlers.DefaultScrubRules. As the fields
+	// of a JSON body can only be masked by key once it is parsed, truncated or
+	// invalid JSON bodies are omitted if any rule has Keys
+	Rules []handlers.ScrubRule
+}
+
+type routeBody struct {
+	prefix string
+	conf   BodyConfig
+}
+
+// WithBodyCapture attaches the request and response bodies of the requests
+// whose path starts with `prefix` to their records, as configured by the
+// BodyConfig `conf`, after scrubbing them with its rules. When several
+// prefixes match a path, the longest one is used; so a BodyConfig without
+// Request and Response disables the capture in a narrower route:
+//
+//	logxhttp.Middleware(logger,
+//		logxhttp.WithBodyCapture("/", logxhttp.BodyConfig{Request: true, Response: true, Level: level.Warn}),
+//		logxhttp.WithBodyCapture("/login", logxhttp.BodyConfig{}),
+//	)
+//
+// Only the bytes read by the handler are captured from the request bodies
+func WithBodyCapture(prefix string, conf BodyConfig) Option {
+	return func(m *middleware) {
+		if conf.MaxSize <= 0 {
+			conf.MaxSize = defaultBodyMaxSize
+		}
+		if len(conf.ContentTypes) == 0 {
+			conf.ContentTypes = defaultBodyContentTypes
+		}
+		m.bodies = append(m.bodies, routeBody{prefix: prefix, conf: conf})
+	}
+}
+
+// bodyConfig returns the BodyConfig for the path `path`, or nil if its bodies
+// are not captured
+func (m *middleware) bodyConfig(path string) *BodyConfig {
+	var (
+		conf    *BodyConfig
+		longest = -1
+	)
+	for i := range m.bodies {
+		if strings.HasPrefix(path, m.bodies[i].prefix) && len(m.bodies[i].prefix) > longest {
+			conf, longest = &m.bodies[i].conf, len(m.bodies[i].prefix)
+		}
+	}
+	if conf == nil || (!conf.Request && !conf.Response) {
+		return nil
+	}
+	return conf
+}
+
+// bodyCapture holds the first bytes of a request or response body
+type bodyCapture struct {
+	conf      *BodyConfig
+	mediaType string
+	data      []byte
+	truncated bool
+}
+
+// newBodyCapture returns a bodyCapture for a body with the content type
+// `contentType`, or nil if it is not captured
+func newBodyCapture(conf *BodyConfig, contentType string) *bodyCapture {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+
+	for _, t := range conf.ContentTypes {
+		if mediaType == t ||
+			(strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t)) ||
+			(strings.HasPrefix(t, "+") && strings.HasSuffix(mediaType, t)) {
+			return &bodyCapture{conf: conf, mediaType: mediaType}
+		}
+	}
+	return nil
+}
+
+func (c *bodyCapture) write(p []byte) {
+	if room := c.conf.MaxSize - len(c.data); len(p) > room {
+		p = p[:room]
+		c.truncated = true
+	}
+	c.data = append(c.data, p...)
+}
+
+// attrs returns the scrubbed body as the attribute with key `key`, along with
+// the truncation flag, if set
+func (c *bodyCapture) attrs(key string) []attr.Attr {
+	if c == nil || (len(c.data) == 0 && !c.truncated) {
+		return nil
+	}
+
+	var attrs []attr.Attr
+	if body, ok := c.scrub(); ok {
+		attrs = append(attrs, attr.String(key, body))
+	}
+	if c.truncated {
+		attrs = append(attrs, attr.New(key+"_truncated", true))
+	}
+	return attrs
+}
+
+// scrub returns the body with the data matching the configured rules masked,
+// or false if it cannot be scrubbed
+func (c *bodyCapture) scrub() (string, bool) {
+	if c.mediaType == "application/json" || strings.HasSuffix(c.mediaType, "+json") {
+		if !c.truncated {
+			if data, err := handlers.ScrubJSON(c.data, c.conf.Rules...); err == nil {
+				return string(data), true
+			}
+		}
+		for i := range c.conf.Rules {
+			if len(c.conf.Rules[i].Keys) > 0 {
+				return "", false
+			}
+		}
+	}
+	return handlers.ScrubString(string(c.data), c.conf.Rules...), true
+}
+
+// bodyReader captures the bytes read from a request body
+type bodyReader struct {
+	io.ReadCloser
+	capture *bodyCapture
+}
+
+func (r *bodyReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.capture.write(p[:n])
+	return n, err
+}
+
+// captureRequest wraps the body of the request `r` to capture it, if its
+// content type is configured, returning its bodyCapture
+func captureRequest(conf *BodyConfig, r *http.Request) *bodyCapture {
+	if !conf.Request || r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	capture := newBodyCapture(conf, r.Header.Get("Content-Type"))
+	if capture != nil {
+		r.Body = &bodyReader{ReadCloser: r.Body, capture: capture}
+	}
+	return capture
+}
@@ -0,0 +1,104 @@
+package logxhttp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zalgonoise/logx/level"
+)
+
+// DefaultDebugHeader is the header carrying the debug tokens that raise the
+// verbosity of a single request (see WithDebugToken)
+const DefaultDebugHeader = "X-Debug-Log"
+
+type debugCtxKey struct{}
+
+type debugConfig struct {
+	key []byte
+	lv  level.Level
+}
+
+// WithDebugToken raises the verbosity of the request's Logger (from logx.From)
+// to the level `lv` (or level.Debug, if nil) for the requests carrying a valid
+// debug token in their headers, signed with the key `key` (see NewDebugToken).
+// This lets operators get the debug output of a single call in production.
+//
+// Tokens are read from the DefaultDebugHeader header, or from the one set with
+// WithDebugHeader. Requests with a missing, invalid or expired token are served
+// as usual. An empty key disables this option
+func WithDebugToken(key []byte, lv level.Level) Option {
+	return func(m *middleware) {
+		if len(key) == 0 {
+			m.debug = nil
+			return
+		}
+		if lv == nil {
+			lv = level.Debug
+		}
+		m.debug = &debugConfig{key: key, lv: lv}
+	}
+}
+
+// WithDebugHeader sets the header that debug tokens are read from. Defaults to
+// DefaultDebugHeader
+func WithDebugHeader(header string) Option {
+	return func(m *middleware) {
+		if header != "" {
+			m.debugHeader = header
+		}
+	}
+}
+
+// NewDebugToken creates a debug token signed with the key `key`, which is valid
+// until `expires`. Tokens are formatted as `<expiry>.<signature>`, where the
+// expiry is a Unix timestamp in seconds, and the signature is the hex-encoded
+// HMAC-SHA256 of the expiry
+func NewDebugToken(key []byte, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + sign(key, exp)
+}
+
+// VerifyDebugToken returns true if the debug token `token` is signed with the
+// key `key`, and has not expired at the time `now`
+func VerifyDebugToken(key []byte, token string, now time.Time) bool {
+	exp, sig, ok := strings.Cut(token, ".")
+	if !ok || len(key) == 0 {
+		return false
+	}
+
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || now.Unix() > unix {
+		return false
+	}
+
+	mac, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	want, _ := hex.DecodeString(sign(key, exp))
+	return hmac.Equal(mac, want)
+}
+
+// WithDebug returns a copy of the context `ctx` carrying the (valid) debug
+// token `token`, to be retrieved with Debug and propagated to other services
+func WithDebug(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, debugCtxKey{}, token)
+}
+
+// Debug returns the debug token stored in the context `ctx` by the Middleware,
+// or an empty string if there is none
+func Debug(ctx context.Context) string {
+	token, _ := ctx.Value(debugCtxKey{}).(string)
+	return token
+}
+
+func sign(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
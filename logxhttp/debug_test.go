@@ -0,0 +1,76 @@
+package logxhttp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/internal/logtest"
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestDebugToken(t *testing.T) {
+	key := []byte("secret")
+	now := time.Unix(1700000000, 0)
+	valid := NewDebugToken(key, now.Add(time.Minute))
+
+	for _, test := range []struct {
+		name  string
+		key   []byte
+		token string
+		wants bool
+	}{
+		{"Valid", key, valid, true},
+		{"WrongKey", []byte("other"), valid, false},
+		{"Expired", key, NewDebugToken(key, now.Add(-time.Second)), false},
+		{"Tampered", key, "1900000000" + valid[len("1700000060"):], false},
+		{"Malformed", key, "not-a-token", false},
+		{"NoKey", nil, valid, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := VerifyDebugToken(test.key, test.token, now); got != test.wants {
+				t.Errorf("output mismatch error: wanted %v ; got %v", test.wants, got)
+			}
+		})
+	}
+}
+
+func TestMiddlewareDebug(t *testing.T) {
+	key := []byte("secret")
+	b := &bytes.Buffer{}
+	l := logx.New(logx.WithHandler(jsonh.New(b).WithLevel(level.Info)))
+
+	var tokens []string
+	h := Middleware(l, WithDebugToken(key, nil))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, Debug(r.Context()))
+		logx.From(r.Context()).Debug("handling")
+	}))
+
+	valid := NewDebugToken(key, time.Now().Add(time.Minute))
+	for _, token := range []string{"", valid, NewDebugToken([]byte("other"), time.Now().Add(time.Minute))} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			req.Header.Set(DefaultDebugHeader, token)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	wants := []string{requestMessage, "handling", requestMessage, requestMessage}
+	entries := logtest.DecodeEntries(t, b)
+	if len(entries) != len(wants) {
+		t.Errorf("output mismatch error: wanted %v entries ; got %v", len(wants), len(entries))
+		return
+	}
+	for i := range wants {
+		if entries[i].Message != wants[i] {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants[i], entries[i].Message)
+		}
+	}
+	if len(tokens) != 3 || tokens[0] != "" || tokens[1] != valid || tokens[2] != "" {
+		t.Errorf("output mismatch error: wanted %v ; got %v", []string{"", valid, ""}, tokens)
+	}
+}
@@ -0,0 +1,325 @@
+// Package logxhttp provides net/http integrations for logx, logging the
+// requests served by a handler and the requests sent by a client
+package logxhttp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+)
+
+// DefaultRequestIDHeader is the header used to read and propagate request IDs
+const DefaultRequestIDHeader = "X-Request-ID"
+
+const requestMessage = "http request"
+
+// maxRequestIDLen is the length above which an incoming request ID is replaced
+const maxRequestIDLen = 128
+
+type ctxKey struct{}
+
+// Option configures the Middleware
+type Option func(*middleware)
+
+type routeLevel struct {
+	prefix string
+	lv     level.Level
+}
+
+type middleware struct {
+	logger   logx.Logger
+	idHeader string
+	routes   []routeLevel
+
+	tail        *handlers.TailConfig
+	tailLatency time.Duration
+
+	debug       *debugConfig
+	debugHeader string
+
+	bodies []routeBody
+}
+
+// WithRequestIDHeader sets the header used to read and propagate request IDs.
+// Defaults to DefaultRequestIDHeader
+func WithRequestIDHeader(header string) Option {
+	return func(m *middleware) {
+		if header != "" {
+			m.idHeader = header
+		}
+	}
+}
+
+// WithRouteLevel sets the level `lv` used when logging the requests whose path
+// starts with `prefix`, regardless of their status. When several prefixes
+// match a path, the longest one is used.
+//
+// This is useful to lower the verbosity of noisy routes, like health checks
+func WithRouteLevel(prefix string, lv level.Level) Option {
+	return func(m *middleware) {
+		m.routes = append(m.routes, routeLevel{prefix: prefix, lv: lv})
+	}
+}
+
+// WithTail holds the low-level records logged with the request's Logger (from
+// logx.From) in memory, as configured by the TailConfig `conf`, and only
+// writes them if the request fails with a 5xx status, or if it takes longer
+// than `latency` (when greater than zero). Otherwise, they are discarded once
+// the request is served.
+//
+// This captures the debug output of the failing requests without paying for
+// it on the successful ones. The records at or above the TailConfig's level
+// are written right away, as usual
+func WithTail(conf handlers.TailConfig, latency time.Duration) Option {
+	return func(m *middleware) {
+		m.tail = &conf
+		m.tailLatency = latency
+	}
+}
+
+// Middleware returns a decorator for http.Handlers that logs each request
+// served with the Logger `logger`, once it is done. Requests are logged with
+// their method, path, status, duration, response size, remote address,
+// request ID and protocol as attributes (as well as their query, referer and
+// user agent, if set); with Info level, or Warn and Error level for 4xx and
+// 5xx responses respectively.
+//
+// The request ID is read from the request's headers, or generated if missing,
+// and set in the response's headers. The request's context carries a Logger
+// with the request ID, method and path as attributes, which is retrieved with
+// logx.From
+func Middleware(logger logx.Logger, opts ...Option) func(http.Handler) http.Handler {
+	if logger == nil {
+		logger = logx.Default()
+	}
+
+	m := &middleware{
+		logger:      logger,
+		idHeader:    DefaultRequestIDHeader,
+		debugHeader: DefaultDebugHeader,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(m)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m.serve(next, w, r)
+		})
+	}
+}
+
+func (m *middleware) serve(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	id := r.Header.Get(m.idHeader)
+	if !ValidRequestID(id) {
+		id = NewRequestID()
+	}
+	w.Header().Set(m.idHeader, id)
+
+	ctx := WithRequestID(r.Context(), id)
+
+	reqLogger := m.logger
+	if m.debug != nil {
+		if token := r.Header.Get(m.debugHeader); token != "" && VerifyDebugToken(m.debug.key, token, start) {
+			reqLogger = logx.Verbose(reqLogger, m.debug.lv)
+			ctx = WithDebug(ctx, token)
+		}
+	}
+
+	var tail *handlers.Tail
+	if m.tail != nil {
+		reqLogger, tail = logx.Tail(reqLogger, *m.tail)
+	}
+
+	ctx = logx.InContext(ctx, reqLogger.With(
+		attr.String("request_id", id),
+		attr.String("method", r.Method),
+		attr.String("path", r.URL.Path),
+	))
+
+	req := r.WithContext(ctx)
+	rw := &responseWriter{ResponseWriter: w}
+
+	var reqBody *bodyCapture
+	body := m.bodyConfig(r.URL.Path)
+	if body != nil {
+		reqBody = captureRequest(body, req)
+		if body.Response {
+			rw.body = body
+		}
+	}
+
+	next.ServeHTTP(rw, req)
+
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+
+	if tail != nil {
+		if rw.status >= http.StatusInternalServerError ||
+			(m.tailLatency > 0 && time.Since(start) > m.tailLatency) {
+			_ = tail.Flush()
+		} else {
+			tail.Discard()
+		}
+	}
+
+	attrs := []attr.Attr{
+		attr.String("method", r.Method),
+		attr.String("path", r.URL.Path),
+		attr.Int("status", rw.status),
+		attr.New("duration", time.Since(start)),
+		attr.Int("bytes", rw.bytes),
+		attr.String("remote_addr", r.RemoteAddr),
+		attr.String("request_id", id),
+		attr.String("proto", r.Proto),
+	}
+	for _, a := range [...]struct{ key, value string }{
+		{"query", r.URL.RawQuery},
+		{"referer", r.Referer()},
+		{"user_agent", r.UserAgent()},
+	} {
+		if a.value != "" {
+			attrs = append(attrs, attr.String(a.key, a.value))
+		}
+	}
+
+	lv := m.level(r.URL.Path, rw.status)
+	if body != nil && (body.Level == nil || lv.Int() >= body.Level.Int()) {
+		attrs = append(attrs, reqBody.attrs("request_body")...)
+		attrs = append(attrs, rw.capture.attrs("response_body")...)
+	}
+
+	m.logger.Log(lv, requestMessage, attrs...)
+}
+
+func (m *middleware) level(path string, status int) level.Level {
+	var (
+		lv      level.Level
+		longest = -1
+	)
+	for _, route := range m.routes {
+		if strings.HasPrefix(path, route.prefix) && len(route.prefix) > longest {
+			lv, longest = route.lv, len(route.prefix)
+		}
+	}
+	if lv != nil {
+		return lv
+	}
+	return StatusLevel(status)
+}
+
+// StatusLevel returns the level for logging a request with the response status
+// `status`: Error for 5xx, Warn for 4xx and Info for the remaining ones
+func StatusLevel(status int) level.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return level.Error
+	case status >= http.StatusBadRequest:
+		return level.Warn
+	default:
+		return level.Info
+	}
+}
+
+// WithRequestID returns a copy of the context `ctx` carrying the request ID
+// `id`, to be retrieved with RequestID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// NewRequestID generates a random request ID
+func NewRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ValidRequestID returns a boolean on whether `id` is fit to be used as an
+// incoming request ID: not empty, up to 128 bytes long, and only made of ASCII
+// letters, digits and the `.`, `_`, `:` and `-` characters. The middlewares
+// replace invalid IDs with a new one (see NewRequestID), so that clients
+// cannot inject arbitrary or oversized values in the logs and responses
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		switch c := id[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.', c == '_', c == ':', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// RequestID returns the request ID stored in the context `ctx` by the
+// Middleware, or an empty string if there is none
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// responseWriter records the status and size of a response, as well as its
+// body if captured
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+
+	body    *BodyConfig
+	capture *bodyCapture
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.body != nil && w.bytes == 0 && w.capture == nil {
+		contentType := w.Header().Get("Content-Type")
+		if contentType == "" {
+			contentType = http.DetectContentType(b)
+		}
+		w.capture = newBodyCapture(w.body, contentType)
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	if w.capture != nil {
+		w.capture.write(b[:n])
+	}
+	return n, err
+}
+
+// Flush implements http.Flusher, if the wrapped http.ResponseWriter does
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
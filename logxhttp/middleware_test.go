@@ -0,0 +1,226 @@
+package logxhttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/internal/logtest"
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Run("LogsRequest", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := logx.New(logx.WithHandler(jsonh.New(b)))
+
+		h := Middleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("created"))
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/items?page=2", nil)
+		req.Header.Set(DefaultRequestIDHeader, "abc123")
+		req.Header.Set("User-Agent", "test-agent")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 1 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 1, len(entries))
+			return
+		}
+
+		e := entries[0]
+		if e.Message != requestMessage || e.Level != "info" {
+			t.Errorf("output mismatch error: wanted %s at info level ; got %s at %s level", requestMessage, e.Message, e.Level)
+		}
+		for key, wants := range map[string]any{
+			"method":     "POST",
+			"path":       "/items",
+			"status":     float64(201),
+			"bytes":      float64(7),
+			"request_id": "abc123",
+			"proto":      "HTTP/1.1",
+			"query":      "page=2",
+			"user_agent": "test-agent",
+		} {
+			if e.Data[key] != wants {
+				t.Errorf("output mismatch error for %s: wanted %v ; got %v", key, wants, e.Data[key])
+			}
+		}
+		if _, ok := e.Data["duration"]; !ok {
+			t.Errorf("expected a duration attribute")
+		}
+		if rec.Header().Get(DefaultRequestIDHeader) != "abc123" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "abc123", rec.Header().Get(DefaultRequestIDHeader))
+		}
+	})
+	t.Run("InvalidRequestID", func(t *testing.T) {
+		for _, id := range []string{
+			strings.Repeat("a", maxRequestIDLen+1),
+			"abc\n123",
+			"<script>",
+		} {
+			h := Middleware(logx.New(logx.WithHandler(jsonh.New(io.Discard))))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(DefaultRequestIDHeader, id)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get(DefaultRequestIDHeader); got == id || !ValidRequestID(got) {
+				t.Errorf("expected request ID %q to be replaced ; got %q", id, got)
+			}
+		}
+	})
+	t.Run("ContextLogger", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := logx.New(logx.WithHandler(jsonh.New(b)))
+
+		var id string
+		h := Middleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id = RequestID(r.Context())
+			logx.From(r.Context()).Info("handling")
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 2 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 2, len(entries))
+			return
+		}
+		if id == "" || entries[0].Data["request_id"] != id {
+			t.Errorf("output mismatch error: wanted %v ; got %v", id, entries[0].Data["request_id"])
+		}
+	})
+	t.Run("StatusLevels", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := logx.New(logx.WithHandler(jsonh.New(b)))
+
+		h := Middleware(l, WithRouteLevel("/healthz", level.Debug))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasPrefix(r.URL.Path, "/missing"):
+				http.NotFound(w, r)
+			case strings.HasPrefix(r.URL.Path, "/fail"), strings.HasPrefix(r.URL.Path, "/healthz"):
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+
+		for _, path := range []string{"/", "/missing", "/fail", "/healthz"} {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+
+		wants := []string{"info", "warn", "error", "debug"}
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != len(wants) {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", len(wants), len(entries))
+			return
+		}
+		for i := range wants {
+			if entries[i].Level != wants[i] {
+				t.Errorf("output mismatch error: wanted %v ; got %v", wants[i], entries[i].Level)
+			}
+		}
+	})
+	t.Run("Tail", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := logx.New(logx.WithHandler(jsonh.New(b).WithLevel(level.Info)))
+
+		h := Middleware(l, WithTail(handlers.TailConfig{}, 0))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logx.From(r.Context()).Debug("handling")
+			if r.URL.Path == "/fail" {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+
+		for _, path := range []string{"/", "/fail"} {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+
+		wants := []struct{ message, path string }{
+			{requestMessage, "/"},
+			{"handling", "/fail"},
+			{requestMessage, "/fail"},
+		}
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != len(wants) {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", len(wants), len(entries))
+			return
+		}
+		for i := range wants {
+			if entries[i].Message != wants[i].message || entries[i].Data["path"] != wants[i].path {
+				t.Errorf("output mismatch error: wanted %v ; got %v %v", wants[i], entries[i].Message, entries[i].Data["path"])
+			}
+		}
+	})
+	t.Run("BodyCapture", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := logx.New(logx.WithHandler(jsonh.New(b)))
+
+		h := Middleware(l,
+			WithBodyCapture("/", BodyConfig{
+				Request:  true,
+				Response: true,
+				MaxSize:  64,
+				Level:    level.Warn,
+				Rules:    []handlers.ScrubRule{handlers.ScrubEmails, handlers.ScrubKeys("password")},
+			}),
+			WithBodyCapture("/login", BodyConfig{}),
+		)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.ReadAll(r.Body)
+			switch r.URL.Path {
+			case "/ok":
+				_, _ = w.Write([]byte("fine"))
+			case "/image":
+				w.Header().Set("Content-Type", "image/png")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte("png"))
+			case "/large":
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+			default:
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid user gopher@example.com"}`))
+			}
+		}))
+
+		for _, path := range []string{"/users", "/ok", "/image", "/large", "/login"} {
+			req := httptest.NewRequest(http.MethodPost, path,
+				strings.NewReader(`{"email":"gopher@example.com","password":"hunter2","age":7}`))
+			req.Header.Set("Content-Type", "application/json; charset=utf-8")
+			h.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 5 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 5, len(entries))
+			return
+		}
+
+		for _, test := range []struct {
+			data  map[string]any
+			key   string
+			wants any
+		}{
+			{entries[0].Data, "request_body", `{"age":7,"email":"[REDACTED]","password":"[REDACTED]"}`},
+			{entries[0].Data, "response_body", `{"error":"invalid user [REDACTED]"}`},
+			{entries[1].Data, "request_body", nil},
+			{entries[2].Data, "response_body", nil},
+			{entries[3].Data, "response_body", strings.Repeat("x", 64)},
+			{entries[3].Data, "response_body_truncated", true},
+			{entries[4].Data, "request_body", nil},
+		} {
+			if got := test.data[test.key]; got != test.wants {
+				t.Errorf("output mismatch error for %s: wanted %v ; got %v", test.key, test.wants, got)
+			}
+		}
+	})
+}
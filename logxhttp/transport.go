@@ -0,0 +1,239 @@
+package logxhttp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/level"
+)
+
+const (
+	outboundMessage = "http outbound request"
+	redacted        = "[REDACTED]"
+)
+
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+type attemptsKey struct{}
+
+// TransportOption configures the Transport
+type TransportOption func(*transport)
+
+type transport struct {
+	base    http.RoundTripper
+	logger  logx.Logger
+	lv      level.Level
+	headers bool
+	body    *BodyConfig
+	redact  map[string]struct{}
+}
+
+// WithLevel sets the level used to log successful requests. Requests with 4xx
+// and 5xx responses are logged with Warn and Error level respectively, and
+// failed requests with Error level. Defaults to level.Info
+func WithLevel(lv level.Level) TransportOption {
+	return func(t *transport) {
+		if lv != nil {
+			t.lv = lv
+		}
+	}
+}
+
+// WithHeaders enables capturing the request and response headers. Sensitive
+// headers (like Authorization or Cookie) are redacted.
+//
+// Headers are only captured when the Logger is enabled for Debug level
+func WithHeaders(enabled bool) TransportOption {
+	return func(t *transport) {
+		t.headers = enabled
+	}
+}
+
+// WithBody enables capturing up to `maxBytes` of the request and response
+// bodies. The response body's leading bytes are read before the response is
+// returned to the caller.
+//
+// Like with the Middleware's WithBodyCapture, only JSON, XML, form and text
+// bodies are captured, and they are scrubbed with the
+// handlers.DefaultScrubRules. The streamed responses, like server-sent events
+// or the ones without a known length, are not captured, as reading them would
+// block the caller until the server sends enough data.
+//
+// Bodies are only captured when the Logger is enabled for Debug level
+func WithBody(maxBytes int) TransportOption {
+	return func(t *transport) {
+		if maxBytes <= 0 {
+			t.body = nil
+			return
+		}
+		t.body = &BodyConfig{
+			Request:      true,
+			Response:     true,
+			MaxSize:      maxBytes,
+			ContentTypes: defaultBodyContentTypes,
+		}
+	}
+}
+
+// WithRedactedHeaders adds the headers `names` to the list of headers whose
+// values are redacted when capturing headers
+func WithRedactedHeaders(names ...string) TransportOption {
+	return func(t *transport) {
+		for _, name := range names {
+			t.redact[http.CanonicalHeaderKey(name)] = struct{}{}
+		}
+	}
+}
+
+// Transport returns an http.RoundTripper that logs the requests sent through
+// `base` with the Logger `logger`, once their response headers are received.
+// Requests are logged with their method, URL (with any password redacted),
+// status, duration and response size as attributes.
+//
+// Requests sent with a context created by WithAttempts are also logged with
+// their attempt number, to trace retries. If `base` is nil,
+// http.DefaultTransport is used
+func Transport(base http.RoundTripper, logger logx.Logger, opts ...TransportOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if logger == nil {
+		logger = logx.Default()
+	}
+
+	t := &transport{
+		base:   base,
+		logger: logger,
+		lv:     level.Info,
+		redact: make(map[string]struct{}, len(defaultRedactedHeaders)),
+	}
+	for _, name := range defaultRedactedHeaders {
+		t.redact[name] = struct{}{}
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(t)
+		}
+	}
+	return t
+}
+
+// WithAttempts returns a copy of the context `ctx` that counts the requests
+// sent with it through a Transport. Retrying clients should reuse the same
+// context for all attempts of a request
+func WithAttempts(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attemptsKey{}, &atomic.Int64{})
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		start   = time.Now()
+		capture = t.logger.Enabled(level.Debug)
+		attrs   = []attr.Attr{
+			attr.String("method", req.Method),
+			attr.String("url", req.URL.Redacted()),
+		}
+	)
+
+	if counter, ok := req.Context().Value(attemptsKey{}).(*atomic.Int64); ok {
+		attrs = append(attrs, attr.Int("attempt", counter.Add(1)))
+	}
+	if capture && t.headers {
+		attrs = append(attrs, attr.New("request_headers", t.headerAttrs(req.Header)))
+	}
+	if capture && t.body != nil && req.GetBody != nil {
+		if c := newBodyCapture(t.body, req.Header.Get("Content-Type")); c != nil {
+			if body, err := req.GetBody(); err == nil {
+				// reading an extra byte flags the truncated bodies
+				c.write(readUpTo(body, t.body.MaxSize+1))
+				_ = body.Close()
+				attrs = append(attrs, c.attrs("request_body")...)
+			}
+		}
+	}
+
+	res, err := t.base.RoundTrip(req)
+	attrs = append(attrs, attr.New("duration", time.Since(start)))
+
+	if err != nil {
+		t.logger.Log(level.Error, outboundMessage, append(attrs, attr.String("error", err.Error()))...)
+		return res, err
+	}
+
+	attrs = append(attrs,
+		attr.Int("status", res.StatusCode),
+		attr.Int("bytes", res.ContentLength),
+	)
+	if capture && t.headers {
+		attrs = append(attrs, attr.New("response_headers", t.headerAttrs(res.Header)))
+	}
+	if capture && t.body != nil && res.Body != nil && !streaming(res) {
+		if c := newBodyCapture(t.body, res.Header.Get("Content-Type")); c != nil {
+			peek := readUpTo(res.Body, t.body.MaxSize+1)
+			res.Body = readCloser{
+				Reader: io.MultiReader(bytes.NewReader(peek), res.Body),
+				Closer: res.Body,
+			}
+			c.write(peek)
+			attrs = append(attrs, c.attrs("response_body")...)
+		}
+	}
+
+	t.logger.Log(t.level(res.StatusCode), outboundMessage, attrs...)
+	return res, nil
+}
+
+func (t *transport) level(status int) level.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return level.Error
+	case status >= http.StatusBadRequest:
+		return level.Warn
+	default:
+		return t.lv
+	}
+}
+
+// streaming returns a boolean on whether the response `res` is streamed: sent
+// as server-sent events, or without a known length (like a chunked one)
+func streaming(res *http.Response) bool {
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	return mediaType == "text/event-stream" || res.ContentLength < 0
+}
+
+func (t *transport) headerAttrs(h http.Header) []attr.Attr {
+	attrs := make([]attr.Attr, 0, len(h))
+	for name, values := range h {
+		value := strings.Join(values, ", ")
+		if _, ok := t.redact[http.CanonicalHeaderKey(name)]; ok {
+			value = redacted
+		}
+		attrs = append(attrs, attr.String(name, value))
+	}
+	return attrs
+}
+
+func readUpTo(r io.Reader, n int) []byte {
+	b, _ := io.ReadAll(io.LimitReader(r, int64(n)))
+	return b
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
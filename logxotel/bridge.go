@@ -0,0 +1,183 @@
+// Package logxotel implements the OpenTelemetry logs bridge API with logx
+// handlers, so that the libraries emitting records with the OpenTelemetry
+// logging API write to the same outputs as the application's logx Loggers
+package logxotel
+
+import (
+	"context"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/embedded"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	scopeNameKey    = "otel.scope.name"
+	scopeVersionKey = "otel.scope.version"
+	traceIDKey      = "trace_id"
+	spanIDKey       = "span_id"
+)
+
+// Option configures a LoggerProvider
+type Option func(*LoggerProvider)
+
+// WithErrorHandler sets the logx.ErrorHandler `fn` called when the Handler
+// fails to handle an emitted record, as the OpenTelemetry API does not return
+// errors to its callers. By default, these errors are discarded
+func WithErrorHandler(fn logx.ErrorHandler) Option {
+	return func(p *LoggerProvider) {
+		p.onError = fn
+	}
+}
+
+// LoggerProvider is a log.LoggerProvider that writes the records emitted by
+// its Loggers to a logx Handler
+type LoggerProvider struct {
+	embedded.LoggerProvider
+
+	h       handlers.Handler
+	onError logx.ErrorHandler
+}
+
+// NewLoggerProvider creates a LoggerProvider writing to the Handler `h`, or to
+// the standard Logger's Handler if nil
+func NewLoggerProvider(h handlers.Handler, opts ...Option) *LoggerProvider {
+	if h == nil {
+		h = logx.Default().Handler()
+	}
+
+	p := &LoggerProvider{h: h}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+	return p
+}
+
+// Logger returns a log.Logger for the instrumentation scope `name`, whose
+// records carry the scope's name and version as attributes
+func (p *LoggerProvider) Logger(name string, options ...log.LoggerOption) log.Logger {
+	cfg := log.NewLoggerConfig(options...)
+
+	attrs := make([]attr.Attr, 0, 2)
+	if name != "" {
+		attrs = append(attrs, attr.String(scopeNameKey, name))
+	}
+	if v := cfg.InstrumentationVersion(); v != "" {
+		attrs = append(attrs, attr.String(scopeVersionKey, v))
+	}
+
+	h := p.h
+	if len(attrs) > 0 {
+		h = h.With(attrs...)
+	}
+
+	return otelLogger{h: h, onError: p.onError}
+}
+
+type otelLogger struct {
+	embedded.Logger
+
+	h       handlers.Handler
+	onError logx.ErrorHandler
+}
+
+// Emit converts the log.Record `r` to a logx record, and writes it to the
+// Handler. The trace and span IDs of the span in `ctx` are added as
+// attributes, if valid
+func (l otelLogger) Emit(ctx context.Context, r log.Record) {
+	lv := Level(r.Severity())
+	if lv == nil {
+		lv = level.Info
+	}
+	if !l.h.Enabled(lv) {
+		return
+	}
+
+	ts := r.Timestamp()
+	if ts.IsZero() {
+		ts = r.ObservedTimestamp()
+	}
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	attrs := make([]attr.Attr, 0, r.AttributesLen()+2)
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs = append(attrs, attr.New(kv.Key, value(kv.Value)))
+		return true
+	})
+
+	if ctx != nil {
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			attrs = append(attrs,
+				attr.String(traceIDKey, sc.TraceID().String()),
+				attr.String(spanIDKey, sc.SpanID().String()),
+			)
+		}
+	}
+
+	rec := records.New(ts, lv, message(r.Body()), attrs...)
+	if err := l.h.Handle(rec); err != nil && l.onError != nil {
+		l.onError(err, rec)
+	}
+}
+
+// Enabled returns whether the Handler accepts records with the severity of
+// the log.Record `r`. Records without a severity are reported as enabled
+func (l otelLogger) Enabled(_ context.Context, r log.Record) bool {
+	return l.h.Enabled(Level(r.Severity()))
+}
+
+// Level converts the OpenTelemetry severity `s` to a logx level. As both
+// scales are spaced by 4, the severities between the named ones (like
+// SeverityInfo2) are converted to offset levels (like level.Info.Offset(1)).
+// Returns nil for log.SeverityUndefined
+func Level(s log.Severity) level.Level {
+	return level.FromOTelSeverity(int(s))
+}
+
+func message(body log.Value) string {
+	if body.Kind() == log.KindString {
+		return body.AsString()
+	}
+	return body.String()
+}
+
+func value(v log.Value) any {
+	switch v.Kind() {
+	case log.KindBool:
+		return v.AsBool()
+	case log.KindInt64:
+		return v.AsInt64()
+	case log.KindFloat64:
+		return v.AsFloat64()
+	case log.KindString:
+		return v.AsString()
+	case log.KindBytes:
+		return v.AsBytes()
+	case log.KindSlice:
+		values := v.AsSlice()
+		out := make([]any, len(values))
+		for i := range values {
+			out[i] = value(values[i])
+		}
+		return out
+	case log.KindMap:
+		kvs := v.AsMap()
+		out := make([]attr.Attr, len(kvs))
+		for i := range kvs {
+			out[i] = attr.New(kvs[i].Key, value(kvs[i].Value))
+		}
+		return out
+	default:
+		return nil
+	}
+}
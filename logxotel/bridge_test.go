@@ -0,0 +1,129 @@
+package logxotel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/internal/logtest"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errTestWrite = errors.New("write failed")
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errTestWrite }
+
+func TestLoggerProvider(t *testing.T) {
+	t.Run("Emit", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := NewLoggerProvider(jsonh.New(b)).Logger("github.com/example/lib", log.WithInstrumentationVersion("v1.2.0"))
+
+		ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		traceID := trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+		spanID := trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8}
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}))
+
+		var r log.Record
+		r.SetTimestamp(ts)
+		r.SetSeverity(log.SeverityWarn)
+		r.SetBody(log.StringValue("cache miss"))
+		r.AddAttributes(
+			log.String("key", "users/42"),
+			log.Int("attempt", 2),
+			log.Map("origin", log.String("region", "eu")),
+		)
+		l.Emit(ctx, r)
+
+		var e logtest.Entry
+		if err := json.Unmarshal(b.Bytes(), &e); err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+		if e.Message != "cache miss" || e.Level != "warn" || !e.Timestamp.Equal(ts) {
+			t.Errorf("output mismatch error: wanted the emitted record ; got %v", e)
+		}
+		for key, wants := range map[string]any{
+			"key":           "users/42",
+			"attempt":       float64(2),
+			scopeNameKey:    "github.com/example/lib",
+			scopeVersionKey: "v1.2.0",
+			traceIDKey:      traceID.String(),
+			spanIDKey:       spanID.String(),
+		} {
+			if e.Data[key] != wants {
+				t.Errorf("output mismatch error for %s: wanted %v ; got %v", key, wants, e.Data[key])
+			}
+		}
+		if origin, _ := e.Data["origin"].(map[string]any); origin["region"] != "eu" {
+			t.Errorf("output mismatch error: wanted a nested object ; got %v", e.Data["origin"])
+		}
+	})
+	t.Run("Enabled", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := NewLoggerProvider(jsonh.New(b).WithLevel(level.Warn)).Logger("lib")
+
+		var r log.Record
+		r.SetSeverity(log.SeverityInfo)
+		if l.Enabled(context.Background(), r) {
+			t.Errorf("output mismatch error: wanted info records to be disabled")
+		}
+		r.SetBody(log.StringValue("filtered"))
+		l.Emit(context.Background(), r)
+		if b.Len() != 0 {
+			t.Errorf("output mismatch error: wanted no output ; got %s", b.String())
+		}
+
+		r.SetSeverity(log.SeverityUndefined)
+		if !l.Enabled(context.Background(), r) {
+			t.Errorf("output mismatch error: wanted records without severity to be enabled")
+		}
+	})
+	t.Run("ErrorHandler", func(t *testing.T) {
+		var gotErr error
+		l := NewLoggerProvider(jsonh.New(errWriter{}), WithErrorHandler(func(err error, _ records.Record) {
+			gotErr = err
+		})).Logger("lib")
+
+		var r log.Record
+		r.SetBody(log.StringValue("lost"))
+		l.Emit(context.Background(), r)
+
+		if !errors.Is(gotErr, errTestWrite) {
+			t.Errorf("unexpected error: wanted %v ; got %v", errTestWrite, gotErr)
+		}
+	})
+}
+
+func TestLevel(t *testing.T) {
+	for _, testcase := range []struct {
+		severity log.Severity
+		wants    level.Level
+	}{
+		{log.SeverityUndefined, nil},
+		{log.SeverityTrace, level.Trace},
+		{log.SeverityDebug, level.Debug},
+		{log.SeverityInfo, level.Info},
+		{log.SeverityInfo2, level.Info.Offset(1)},
+		{log.SeverityWarn, level.Warn},
+		{log.SeverityError, level.Error},
+		{log.SeverityFatal4, level.Fatal.Offset(3)},
+	} {
+		t.Run(testcase.severity.String(), func(t *testing.T) {
+			if lv := Level(testcase.severity); lv != testcase.wants {
+				t.Errorf("output mismatch error: wanted %v ; got %v", testcase.wants, lv)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package logxotel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const levelKey = "log.level"
+
+type spanLogger struct {
+	logx.Logger
+}
+
+// SpanEvents decorates the Logger `logger` so that the records logged with its
+// context-aware methods (InfoContext, LogContext, etc.) are also added as an
+// event to the span in the input Context, if it is recording. The event is
+// named after the record's message, with its level and attributes (call-site
+// ones only) as the event's attributes.
+//
+// This keeps traces self-contained, with the logs emitted during a span
+// visible without a separate lookup
+func SpanEvents(logger logx.Logger) logx.Logger {
+	if logger == nil {
+		logger = logx.Default()
+	}
+	return spanLogger{logger}
+}
+
+func (l spanLogger) addEvent(ctx context.Context, lv level.Level, msg string, attrs []attr.Attr) {
+	if ctx == nil || msg == "" || !l.Enabled(lv) {
+		return
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	kvs := make([]attribute.KeyValue, 0, len(attrs)+1)
+	kvs = append(kvs, attribute.String(levelKey, lv.String()))
+	span.AddEvent(msg, trace.WithAttributes(appendKeyValues(kvs, "", attrs)...))
+}
+
+// appendKeyValues converts the attributes `attrs` into OpenTelemetry
+// attributes, flattening groups with dot-separated keys
+func appendKeyValues(kvs []attribute.KeyValue, prefix string, attrs []attr.Attr) []attribute.KeyValue {
+	for _, a := range attrs {
+		if a == nil {
+			continue
+		}
+
+		key := prefix + a.Key()
+		switch v := a.Value().(type) {
+		case []attr.Attr:
+			kvs = appendKeyValues(kvs, key+".", v)
+		case string:
+			kvs = append(kvs, attribute.String(key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(key, v))
+		case time.Duration:
+			kvs = append(kvs, attribute.String(key, v.String()))
+		case int:
+			kvs = append(kvs, attribute.Int(key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(key, v))
+		case uint64:
+			kvs = append(kvs, attribute.Int64(key, int64(v)))
+		case float64:
+			kvs = append(kvs, attribute.Float64(key, v))
+		case float32:
+			kvs = append(kvs, attribute.Float64(key, float64(v)))
+		case time.Time:
+			kvs = append(kvs, attribute.String(key, v.Format(time.RFC3339Nano)))
+		case error:
+			kvs = append(kvs, attribute.String(key, v.Error()))
+		default:
+			kvs = append(kvs, attribute.String(key, fmt.Sprint(v)))
+		}
+	}
+	return kvs
+}
+
+// LogContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with `level` log level
+func (l spanLogger) LogContext(ctx context.Context, lv level.Level, msg string, attrs ...attr.Attr) {
+	if lv == nil {
+		lv = level.Info
+	}
+	l.addEvent(ctx, lv, msg, attrs)
+	l.Logger.LogContext(ctx, lv, msg, attrs...)
+}
+
+// TraceContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with Trace-level
+func (l spanLogger) TraceContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	l.LogContext(ctx, level.Trace, msg, attrs...)
+}
+
+// DebugContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with Debug-level
+func (l spanLogger) DebugContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	l.LogContext(ctx, level.Debug, msg, attrs...)
+}
+
+// InfoContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with Info-level
+func (l spanLogger) InfoContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	l.LogContext(ctx, level.Info, msg, attrs...)
+}
+
+// WarnContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with Warn-level
+func (l spanLogger) WarnContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	l.LogContext(ctx, level.Warn, msg, attrs...)
+}
+
+// ErrorContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with Error-level
+func (l spanLogger) ErrorContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	l.LogContext(ctx, level.Error, msg, attrs...)
+}
+
+// FatalContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with Fatal-level
+func (l spanLogger) FatalContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	l.LogContext(ctx, level.Fatal, msg, attrs...)
+}
+
+// With will spawn a copy of this Logger with the input attributes
+// `attrs`
+func (l spanLogger) With(attrs ...attr.Attr) logx.Logger {
+	return spanLogger{l.Logger.With(handlers.TracedCaller("With", 1, attrs...)...)}
+}
+
+// WithClock will spawn a copy of this Logger using the input Clock
+// `clock` as the source of its records' timestamps
+func (l spanLogger) WithClock(clock records.Clock) logx.Logger {
+	return spanLogger{l.Logger.WithClock(clock)}
+}
+
+// WithSequence will spawn a copy of this Logger that attaches a
+// monotonic sequence number (as a `seq` attribute) to its records, if
+// `enabled` is true
+func (l spanLogger) WithSequence(enabled bool) logx.Logger {
+	return spanLogger{l.Logger.WithSequence(enabled)}
+}
+
+// Named will spawn a copy of this Logger with the input name segment
+// `name` appended to its name
+func (l spanLogger) Named(name string) logx.Logger {
+	return spanLogger{l.Logger.Named(name)}
+}
@@ -0,0 +1,94 @@
+package logxotel
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/level"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type testEvent struct {
+	name  string
+	attrs map[attribute.Key]attribute.Value
+}
+
+type testSpan struct {
+	trace.Span
+	recording bool
+	events    []testEvent
+}
+
+func (s *testSpan) IsRecording() bool { return s.recording }
+
+func (s *testSpan) AddEvent(name string, opts ...trace.EventOption) {
+	e := testEvent{name: name, attrs: map[attribute.Key]attribute.Value{}}
+	cfg := trace.NewEventConfig(opts...)
+	for _, kv := range cfg.Attributes() {
+		e.attrs[kv.Key] = kv.Value
+	}
+	s.events = append(s.events, e)
+}
+
+func TestSpanEvents(t *testing.T) {
+	t.Run("Recording", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		span := &testSpan{recording: true}
+		ctx := trace.ContextWithSpan(context.Background(), span)
+		logger := SpanEvents(logx.New(logx.WithHandler(jsonh.New(b)))).With(attr.String("service", "api"))
+
+		logger.WarnContext(ctx, "slow query",
+			attr.New("duration", 2*time.Second),
+			attr.New("db", []attr.Attr{attr.String("table", "users")}),
+		)
+
+		if b.Len() == 0 {
+			t.Errorf("output mismatch error: wanted the record to be logged")
+		}
+		if len(span.events) != 1 {
+			t.Errorf("output mismatch error: wanted %v events ; got %v", 1, len(span.events))
+			return
+		}
+
+		e := span.events[0]
+		if e.name != "slow query" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "slow query", e.name)
+		}
+		for key, wants := range map[attribute.Key]string{
+			levelKey:   "warn",
+			"duration": "2s",
+			"db.table": "users",
+		} {
+			if got := e.attrs[key].AsString(); got != wants {
+				t.Errorf("output mismatch error for %s: wanted %v ; got %v", key, wants, got)
+			}
+		}
+	})
+	t.Run("NotRecording", func(t *testing.T) {
+		span := &testSpan{}
+		ctx := trace.ContextWithSpan(context.Background(), span)
+
+		SpanEvents(logx.New(logx.WithHandler(jsonh.New(&bytes.Buffer{})))).InfoContext(ctx, "message")
+
+		if len(span.events) != 0 {
+			t.Errorf("output mismatch error: wanted no events ; got %v", span.events)
+		}
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		span := &testSpan{recording: true}
+		ctx := trace.ContextWithSpan(context.Background(), span)
+		logger := SpanEvents(logx.New(logx.WithHandler(jsonh.New(&bytes.Buffer{})), logx.WithLevel(level.Warn)))
+
+		logger.InfoContext(ctx, "message")
+
+		if len(span.events) != 0 {
+			t.Errorf("output mismatch error: wanted no events ; got %v", span.events)
+		}
+	})
+}
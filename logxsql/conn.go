@@ -0,0 +1,209 @@
+package logxsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+)
+
+type conn struct {
+	driver.Conn
+	l *logging
+}
+
+func wrapConn(c driver.Conn, l *logging) driver.Conn {
+	return &conn{Conn: c, l: l}
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		start = time.Now()
+		s     driver.Stmt
+		err   error
+	)
+
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = pc.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		c.l.log("prepare", query, nil, start, nil, err)
+		return nil, err
+	}
+	return &stmt{Stmt: s, query: query, l: c.l}, nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var (
+		start = time.Now()
+		t     driver.Tx
+		err   error
+	)
+
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		t, err = bc.BeginTx(ctx, opts)
+	} else {
+		t, err = c.Conn.Begin()
+	}
+	c.l.log("begin", "", nil, start, nil, err)
+	if err != nil {
+		return nil, err
+	}
+	return tx{Tx: t, l: c.l}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	res, err := ec.ExecContext(ctx, query, args)
+	c.l.log("exec", query, args, start, res, err)
+	return res, err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	c.l.log("query", query, args, start, nil, err)
+	return rows, err
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	p, ok := c.Conn.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+
+	start := time.Now()
+	err := p.Ping(ctx)
+	if err != nil {
+		c.l.log("ping", "", nil, start, nil, err)
+	}
+	return err
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type stmt struct {
+	driver.Stmt
+	query string
+	l     *logging
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	var (
+		start = time.Now()
+		res   driver.Result
+		err   error
+	)
+
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = ec.ExecContext(ctx, args)
+	} else {
+		res, err = s.Stmt.Exec(values(args))
+	}
+	s.l.log("exec", s.query, args, start, res, err)
+	return res, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var (
+		start = time.Now()
+		rows  driver.Rows
+		err   error
+	)
+
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(values(args))
+	}
+	s.l.log("query", s.query, args, start, nil, err)
+	return rows, err
+}
+
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type tx struct {
+	driver.Tx
+	l *logging
+}
+
+func (t tx) Commit() error {
+	start := time.Now()
+	err := t.Tx.Commit()
+	t.l.log("commit", "", nil, start, nil, err)
+	return err
+}
+
+func (t tx) Rollback() error {
+	start := time.Now()
+	err := t.Tx.Rollback()
+	t.l.log("rollback", "", nil, start, nil, err)
+	return err
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: args[i]}
+	}
+	return named
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	vs := make([]driver.Value, len(args))
+	for i := range args {
+		vs[i] = args[i].Value
+	}
+	return vs
+}
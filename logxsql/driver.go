@@ -0,0 +1,208 @@
+// Package logxsql wraps database/sql drivers to log their queries with logx.
+//
+// Successful operations are logged with Debug level, along with the query,
+// its arguments, the number of affected rows and the latency; failed ones
+// are logged with Error level, along with the error
+package logxsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/level"
+)
+
+const redacted = "[REDACTED]"
+
+// Redactor is a function that returns the value to log for the query
+// argument `arg`, allowing sensitive values to be masked or left out
+type Redactor func(arg driver.NamedValue) any
+
+// Option configures the logging of a wrapped driver
+type Option func(*logging)
+
+type logging struct {
+	logger logx.Logger
+	args   bool
+	redact Redactor
+}
+
+// WithArgs enables or disables logging the queries' arguments. Enabled by
+// default
+func WithArgs(enabled bool) Option {
+	return func(l *logging) {
+		l.args = enabled
+	}
+}
+
+// WithRedactor sets the Redactor applied to the queries' arguments before
+// they are logged
+func WithRedactor(fn Redactor) Option {
+	return func(l *logging) {
+		l.redact = fn
+	}
+}
+
+// RedactNames returns a Redactor that masks the named arguments whose name
+// is in `names`, leaving the others untouched
+func RedactNames(names ...string) Redactor {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+
+	return func(arg driver.NamedValue) any {
+		if _, ok := set[arg.Name]; ok {
+			return redacted
+		}
+		return arg.Value
+	}
+}
+
+// RedactAll is a Redactor that masks all arguments, only logging their count
+// and names or ordinals
+func RedactAll(driver.NamedValue) any {
+	return redacted
+}
+
+func newLogging(logger logx.Logger, opts []Option) *logging {
+	if logger == nil {
+		logger = logx.Default()
+	}
+
+	l := &logging{
+		logger: logger,
+		args:   true,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(l)
+		}
+	}
+	return l
+}
+
+// Wrap returns a driver.Driver that logs the operations of the connections
+// opened by `d` with the Logger `logger`. It can be registered with
+// sql.Register under a new name, to be used with sql.Open
+func Wrap(d driver.Driver, logger logx.Logger, opts ...Option) driver.Driver {
+	l := newLogging(logger, opts)
+	if dc, ok := d.(driver.DriverContext); ok {
+		return wrappedDriverContext{wrappedDriver{d, l}, dc}
+	}
+	return wrappedDriver{d, l}
+}
+
+// WrapConnector returns a driver.Connector that logs the operations of the
+// connections opened by `c` with the Logger `logger`, to be used with
+// sql.OpenDB
+func WrapConnector(c driver.Connector, logger logx.Logger, opts ...Option) driver.Connector {
+	return wrappedConnector{c: c, d: wrappedDriver{c.Driver(), newLogging(logger, opts)}}
+}
+
+type wrappedDriver struct {
+	d driver.Driver
+	l *logging
+}
+
+func (d wrappedDriver) Open(name string) (driver.Conn, error) {
+	start := time.Now()
+	c, err := d.d.Open(name)
+	if err != nil {
+		d.l.log("open", "", nil, start, nil, err)
+		return nil, err
+	}
+	return wrapConn(c, d.l), nil
+}
+
+type wrappedDriverContext struct {
+	wrappedDriver
+	dc driver.DriverContext
+}
+
+func (d wrappedDriverContext) OpenConnector(name string) (driver.Connector, error) {
+	c, err := d.dc.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return wrappedConnector{c: c, d: d.wrappedDriver}, nil
+}
+
+type wrappedConnector struct {
+	c driver.Connector
+	d wrappedDriver
+}
+
+func (c wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	start := time.Now()
+	conn, err := c.c.Connect(ctx)
+	if err != nil {
+		c.d.l.log("connect", "", nil, start, nil, err)
+		return nil, err
+	}
+	return wrapConn(conn, c.d.l), nil
+}
+
+func (c wrappedConnector) Driver() driver.Driver {
+	return c.d
+}
+
+// log registers the outcome of the operation `op`. Errors are logged with
+// Error level, except for driver.ErrSkip which only signals that database/sql
+// should fall back to another method
+func (l *logging) log(op, query string, args []driver.NamedValue, start time.Time, res driver.Result, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	lv := level.Debug
+	if err != nil {
+		lv = level.Error
+	}
+	if !l.logger.Enabled(lv) {
+		return
+	}
+
+	attrs := make([]attr.Attr, 0, 6)
+	attrs = append(attrs, attr.String("op", op))
+	if query != "" {
+		attrs = append(attrs, attr.String("query", query))
+	}
+	if l.args && len(args) > 0 {
+		attrs = append(attrs, attr.New("args", l.argAttrs(args)))
+	}
+	if res != nil {
+		if n, err := res.RowsAffected(); err == nil {
+			attrs = append(attrs, attr.Int("rows_affected", n))
+		}
+	}
+	attrs = append(attrs, attr.New("duration", time.Since(start)))
+
+	if err != nil {
+		attrs = append(attrs, attr.String("error", err.Error()))
+	}
+
+	l.logger.Log(lv, "sql "+op, attrs...)
+}
+
+func (l *logging) argAttrs(args []driver.NamedValue) []attr.Attr {
+	attrs := make([]attr.Attr, 0, len(args))
+	for _, arg := range args {
+		key := arg.Name
+		if key == "" {
+			key = "$" + strconv.Itoa(arg.Ordinal)
+		}
+
+		value := arg.Value
+		if l.redact != nil {
+			value = l.redact(arg)
+		}
+		attrs = append(attrs, attr.New(key, value))
+	}
+	return attrs
+}
@@ -0,0 +1,205 @@
+package logxsql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/internal/logtest"
+)
+
+var errTable = errors.New("no such table")
+
+// testDriver is a minimal driver whose statements affect one row, and whose
+// queries on the `missing` table fail
+type testDriver struct{}
+
+func (testDriver) Open(string) (driver.Conn, error) { return testConn{}, nil }
+
+type testConnector struct{}
+
+func (testConnector) Connect(context.Context) (driver.Conn, error) { return testConn{}, nil }
+func (testConnector) Driver() driver.Driver                        { return testDriver{} }
+
+type testConn struct{}
+
+func (testConn) Prepare(query string) (driver.Stmt, error) { return testStmt{query}, nil }
+func (testConn) Close() error                              { return nil }
+func (testConn) Begin() (driver.Tx, error)                 { return testTx{}, nil }
+
+func (testConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if query == "DELETE FROM missing" {
+		return nil, errTable
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type testStmt struct{ query string }
+
+func (testStmt) Close() error  { return nil }
+func (testStmt) NumInput() int { return -1 }
+
+func (testStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (testStmt) Query([]driver.Value) (driver.Rows, error)  { return &testRows{}, nil }
+
+type testRows struct{ done bool }
+
+func (*testRows) Columns() []string { return []string{"id"} }
+func (*testRows) Close() error      { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+type testTx struct{}
+
+func (testTx) Commit() error   { return nil }
+func (testTx) Rollback() error { return nil }
+
+func TestWrapConnector(t *testing.T) {
+	newDB := func(b *bytes.Buffer, opts ...Option) *sql.DB {
+		return sql.OpenDB(WrapConnector(testConnector{}, logx.New(logx.WithHandler(jsonh.New(b))), opts...))
+	}
+
+	t.Run("Exec", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		db := newDB(b)
+		defer db.Close()
+
+		if _, err := db.Exec("UPDATE users SET name = $1 WHERE id = $2", "gopher", 7); err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 1 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 1, len(entries))
+			return
+		}
+		e := entries[0]
+		if e.Message != "sql exec" || e.Level != "debug" || e.Data["rows_affected"] != float64(1) {
+			t.Errorf("output mismatch error: wanted a debug exec entry ; got %v", e)
+		}
+		args, _ := e.Data["args"].(map[string]any)
+		if args["$1"] != "gopher" || args["$2"] != float64(7) {
+			t.Errorf("output mismatch error: wanted the query args ; got %v", e.Data["args"])
+		}
+	})
+	t.Run("Error", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		db := newDB(b)
+		defer db.Close()
+
+		if _, err := db.Exec("DELETE FROM missing"); !errors.Is(err, errTable) {
+			t.Errorf("unexpected error: wanted %v ; got %v", errTable, err)
+		}
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 1 || entries[0].Level != "error" || entries[0].Data["error"] != errTable.Error() {
+			t.Errorf("output mismatch error: wanted an error entry ; got %v", entries)
+		}
+	})
+	t.Run("PreparedQuery", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		db := newDB(b)
+		defer db.Close()
+
+		// testConn has no QueryerContext, so the query is prepared
+		var id int
+		if err := db.QueryRow("SELECT id FROM users WHERE name = ?", "gopher").Scan(&id); err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 1 || entries[0].Message != "sql query" || entries[0].Data["query"] != "SELECT id FROM users WHERE name = ?" {
+			t.Errorf("output mismatch error: wanted a single query entry ; got %v", entries)
+		}
+	})
+	t.Run("Redaction", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		db := newDB(b, WithRedactor(RedactNames("password")))
+		defer db.Close()
+
+		if _, err := db.Exec("UPDATE users SET password = @password WHERE name = @name",
+			sql.Named("password", "hunter2"), sql.Named("name", "gopher")); err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 1 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 1, len(entries))
+			return
+		}
+		args, _ := entries[0].Data["args"].(map[string]any)
+		if args["password"] != redacted || args["name"] != "gopher" {
+			t.Errorf("output mismatch error: wanted the password redacted ; got %v", args)
+		}
+	})
+	t.Run("Tx", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		db := newDB(b, WithArgs(false))
+		defer db.Close()
+
+		tx, err := db.Begin()
+		if err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+		if _, err := tx.Exec("DELETE FROM users WHERE id = $1", 7); err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+			return
+		}
+
+		entries := logtest.DecodeEntries(t, b)
+		if len(entries) != 3 {
+			t.Errorf("output mismatch error: wanted %v entries ; got %v", 3, len(entries))
+			return
+		}
+		for i, wants := range []string{"sql begin", "sql exec", "sql commit"} {
+			if entries[i].Message != wants {
+				t.Errorf("output mismatch error: wanted %v ; got %v", wants, entries[i].Message)
+			}
+		}
+		if _, ok := entries[1].Data["args"]; ok {
+			t.Errorf("output mismatch error: wanted no args ; got %v", entries[1].Data["args"])
+		}
+	})
+}
+
+func TestWrap(t *testing.T) {
+	b := &bytes.Buffer{}
+	sql.Register("logxsql-test", Wrap(testDriver{}, logx.New(logx.WithHandler(jsonh.New(b)))))
+
+	db, err := sql.Open("logxsql-test", "")
+	if err != nil {
+		t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+		return
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("DELETE FROM users"); err != nil {
+		t.Errorf("unexpected error: wanted %v ; got %v", nil, err)
+		return
+	}
+
+	entries := logtest.DecodeEntries(t, b)
+	if len(entries) != 1 || entries[0].Message != "sql exec" {
+		t.Errorf("output mismatch error: wanted a single exec entry ; got %v", entries)
+	}
+}
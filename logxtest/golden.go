@@ -0,0 +1,142 @@
+// Package logxtest provides helpers for snapshot-testing the output of logx
+// Loggers and Handlers against golden files.
+//
+// Golden files are stored in the `testdata` directory of the package under
+// test, and are (re)written by running the tests with the `-update` flag:
+//
+//	go test ./... -update
+package logxtest
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/records"
+)
+
+const updateFlag = "update"
+
+// Time is the timestamp of the records logged by a Logger created with Capture
+var Time = logx.TestTime
+
+var (
+	timeRegex   = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+	sourceRegex = regexp.MustCompile(`[\w./-]+\.go:\d+`)
+)
+
+func init() {
+	// the package under test may declare its own -update flag
+	if flag.Lookup(updateFlag) == nil {
+		flag.Bool(updateFlag, false, "update the logxtest golden files")
+	}
+}
+
+// Capture creates a Logger with the Handler returned by `newHandler` and the
+// Options `opts`, writing to the returned buffer. Its records are timestamped
+// with Time, so that the output is deterministic. Add the logx.TestMode
+// Option to also sort the attributes and leave out source references and
+// colors.
+//
+// The returned buffer is not safe for concurrent use
+func Capture(newHandler func(w io.Writer) handlers.Handler, opts ...logx.Option) (logx.Logger, *bytes.Buffer) {
+	b := &bytes.Buffer{}
+
+	opts = append(opts,
+		logx.WithHandler(newHandler(b)),
+		logx.WithClock(records.ClockFunc(func() time.Time { return Time })),
+	)
+	return logx.New(opts...), b
+}
+
+// Normalize replaces the RFC3339 timestamps in `output` with `<time>`, and the
+// source file references (like `main.go:42`) with `<source>`, so that it can
+// be compared across runs and machines
+func Normalize(output []byte) []byte {
+	output = timeRegex.ReplaceAll(output, []byte("<time>"))
+	return sourceRegex.ReplaceAll(output, []byte("<source>"))
+}
+
+// Golden compares the normalized `output` (see Normalize) with the contents of
+// the `testdata/<name>.golden` file, failing the test `t` with a line diff if
+// they differ.
+//
+// When the tests run with the `-update` flag, the golden file is written with
+// `output` instead
+func Golden(t testing.TB, name string, output []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	output = Normalize(output)
+
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("unexpected error creating golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, output, 0o644); err != nil {
+			t.Fatalf("unexpected error writing golden file: %v", err)
+		}
+		return
+	}
+
+	wants, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading golden file (run with -%s to create it): %v", updateFlag, err)
+	}
+
+	if !bytes.Equal(wants, output) {
+		t.Errorf("output mismatch error for %s (-wanted +got):\n%s", path, Diff(string(wants), string(output)))
+	}
+}
+
+// Diff returns a line diff between `wants` and `got`, with the removed lines
+// prefixed by `-`, the added ones by `+`, and the common ones by two spaces
+func Diff(wants, got string) string {
+	a, b := strings.Split(wants, "\n"), strings.Split(got, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+				continue
+			}
+			lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+		}
+	}
+
+	sb := &strings.Builder{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			sb.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return sb.String()
+}
+
+func updating() bool {
+	f := flag.Lookup(updateFlag)
+	return f != nil && f.Value.String() == "true"
+}
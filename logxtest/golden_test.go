@@ -0,0 +1,41 @@
+package logxtest
+
+import (
+	"testing"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/handlers/texth"
+)
+
+func TestGolden(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		logger, b := Capture(jsonh.New)
+		logger.Info("hello", attr.String("user", "gopher"))
+
+		Golden(t, "json", b.Bytes())
+	})
+	t.Run("Text", func(t *testing.T) {
+		logger, b := Capture(texth.New)
+		logger.Warn("careful", attr.Int("retries", 3))
+
+		Golden(t, "text", b.Bytes())
+	})
+}
+
+func TestNormalize(t *testing.T) {
+	input := `{"timestamp":"2026-10-15T05:18:14.540838742+02:00","source":"handlers/tb.go:42","at":"2024-01-01T00:00:00Z"}`
+	wants := `{"timestamp":"<time>","source":"<source>","at":"<time>"}`
+
+	if got := string(Normalize([]byte(input))); got != wants {
+		t.Errorf("output mismatch error: wanted %s ; got %s", wants, got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	wants := "  a\n- b\n+ x\n  c\n+ d\n"
+
+	if got := Diff("a\nb\nc", "a\nx\nc\nd"); got != wants {
+		t.Errorf("output mismatch error: wanted %q ; got %q", wants, got)
+	}
+}
@@ -0,0 +1,63 @@
+package logx
+
+import (
+	"io"
+	"os"
+
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+)
+
+// Output describes one of the sinks of a Logger created with NewMulti
+type Output struct {
+	// Writer is where the records are written to, defaulting to standard
+	// error
+	Writer io.Writer
+	// Format is the encoding of the records, defaulting to FormatJSON
+	Format Format
+	// Level is the verbosity of this Output; all records are written if nil
+	Level level.Level
+	// Source sets whether a source file+line reference is added to the
+	// records
+	Source bool
+}
+
+// handler creates the Handler writing the records to the Output
+func (o Output) handler() handlers.Handler {
+	w := o.Writer
+	if w == nil {
+		w = os.Stderr
+	}
+
+	h := newHandler(o.Format, w)
+	if o.Level != nil {
+		h = h.WithLevel(o.Level)
+	}
+	if o.Source {
+		h = h.WithSource(true)
+	}
+	return h
+}
+
+// NewMulti creates a Logger writing each record to all of the Outputs
+// `outputs` accepting its level, each with its own encoding, like text lines
+// on a terminal and JSON objects in a file:
+//
+//	logger := logx.NewMulti(
+//		logx.Output{Writer: os.Stdout, Format: logx.FormatText, Level: level.Debug},
+//		logx.Output{Writer: file, Format: logx.FormatJSON, Level: level.Info},
+//	)
+//
+// With no Outputs, records are written as JSON objects to standard error
+func NewMulti(outputs ...Output) Logger {
+	if len(outputs) == 0 {
+		outputs = []Output{{}}
+	}
+
+	hs := make([]handlers.Handler, 0, len(outputs))
+	for _, out := range outputs {
+		hs = append(hs, out.handler())
+	}
+
+	return New(WithHandler(handlers.Multi(hs...)))
+}
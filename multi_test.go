@@ -0,0 +1,61 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestNewMulti(t *testing.T) {
+	skipTiny(t)
+
+	var (
+		text = &bytes.Buffer{}
+		json = &bytes.Buffer{}
+	)
+	logger := NewMulti(
+		Output{Writer: text, Format: FormatText, Level: level.Debug},
+		Output{Writer: json, Format: FormatJSON, Level: level.Info},
+	)
+
+	logger.Debug("debug message")
+	logger.Info("info message", attr.Int("n", 1))
+	logger.Trace("trace message")
+
+	if out := text.String(); !strings.Contains(out, "[debug] debug message") || !strings.Contains(out, "[info] info message [ n: 1 ]") {
+		t.Errorf("output mismatch error: wanted the debug and info records ; got %q", out)
+	}
+	if out := json.String(); strings.Contains(out, "debug message") || !strings.Contains(out, `"message":"info message"`) {
+		t.Errorf("output mismatch error: wanted only the info record ; got %q", out)
+	}
+	if strings.Contains(text.String()+json.String(), "trace message") {
+		t.Errorf("output mismatch error: wanted no trace records")
+	}
+}
+
+func TestNewMultiContext(t *testing.T) {
+	skipTiny(t)
+
+	var (
+		text = &bytes.Buffer{}
+		json = &bytes.Buffer{}
+	)
+	logger := NewMulti(
+		Output{Writer: text, Format: FormatText, Level: level.Debug},
+		Output{Writer: json, Format: FormatJSON, Level: level.Info},
+	)
+
+	logger.DebugContext(context.Background(), "debug message")
+	logger.InfoContext(context.Background(), "info message")
+
+	if out := text.String(); !strings.Contains(out, "[debug] debug message") || !strings.Contains(out, "[info] info message") {
+		t.Errorf("output mismatch error: wanted the debug and info records ; got %q", out)
+	}
+	if out := json.String(); strings.Contains(out, "debug message") || !strings.Contains(out, `"message":"info message"`) {
+		t.Errorf("output mismatch error: wanted only the info record ; got %q", out)
+	}
+}
@@ -0,0 +1,159 @@
+package logx
+
+import (
+	"io"
+	"os"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// Format describes the encoding of the records written by a Logger created
+// with the WithWriter option.
+//
+// In the logx_tiny build profile, meant for TinyGo and other constrained
+// targets, all formats are encoded with the tinyh Handler instead
+type Format int
+
+const (
+	// FormatJSON encodes records as JSON objects, with the jsonh Handler
+	FormatJSON Format = iota
+	// FormatText encodes records as text lines, with the texth Handler
+	FormatText
+)
+
+// Option describes a setting applied to a Logger when it is created with New
+type Option func(*config)
+
+type config struct {
+	h          handlers.Handler
+	hasH       bool
+	w          io.Writer
+	format     Format
+	level      level.Leveler
+	addSource  *bool
+	attrs      []attr.Attr
+	clock      records.Clock
+	pool       bool
+	onError    ErrorHandler
+	extractors []Extractor
+	pprofKeys  []string
+
+	deterministic bool
+}
+
+// WithHandler sets the Handler `h` as the Logger's Handler, taking precedence
+// over the WithWriter and WithFormat options.
+//
+// If `h` is nil, the Logger will not handle any records
+func WithHandler(h handlers.Handler) Option {
+	return func(c *config) {
+		c.h = h
+		c.hasH = true
+	}
+}
+
+// WithWriter sets the io.Writer `w` as the output for the Logger's records,
+// which defaults to os.Stderr
+func WithWriter(w io.Writer) Option {
+	return func(c *config) {
+		c.w = w
+	}
+}
+
+// WithFormat sets the encoding of the records written to the Logger's
+// io.Writer, which defaults to FormatJSON
+func WithFormat(format Format) Option {
+	return func(c *config) {
+		c.format = format
+	}
+}
+
+// WithLevel sets the Leveler `lv` as the verbosity filter of the Logger's
+// Handler
+func WithLevel(lv level.Leveler) Option {
+	return func(c *config) {
+		c.level = lv
+	}
+}
+
+// WithSource sets whether the Logger's Handler adds source information to the
+// records
+func WithSource(addSource bool) Option {
+	return func(c *config) {
+		c.addSource = &addSource
+	}
+}
+
+// WithAttrs sets the attributes `attrs` to be added to all of the Logger's
+// records
+func WithAttrs(attrs ...attr.Attr) Option {
+	attrs = handlers.TracedCaller("WithAttrs", 1, attrs...)
+	return func(c *config) {
+		c.attrs = attrs
+	}
+}
+
+// WithClock sets the Clock `clock` as the source of the timestamps of the
+// Logger's records
+func WithClock(clock records.Clock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
+// WithRecordPool sets whether the Logger reuses its records from a shared pool,
+// which removes the allocation of a record on every log call.
+//
+// When enabled, a record is only valid during its Handler's Handle call.
+// Handlers that retain records beyond it must retain a copy of them, with the
+// Record's Clone method
+func WithRecordPool(enabled bool) Option {
+	return func(c *config) {
+		c.pool = enabled
+	}
+}
+
+// WithErrorHandler sets the ErrorHandler `fn` called when the Logger's Handler
+// fails, instead of the one set with SetErrorHandler
+func WithErrorHandler(fn ErrorHandler) Option {
+	return func(c *config) {
+		c.onError = fn
+	}
+}
+
+// WithExtractors sets the Extractors `fns` run by the Logger's context-aware
+// methods, instead of the ones set with SetExtractors
+func WithExtractors(fns ...Extractor) Option {
+	return func(c *config) {
+		c.extractors = append(c.extractors, fns...)
+	}
+}
+
+func (c *config) handler() handlers.Handler {
+	h := c.h
+	if !c.hasH {
+		w := c.w
+		if w == nil {
+			w = os.Stderr
+		}
+
+		h = newHandler(c.format, w)
+	}
+	if h == nil {
+		return handlers.Unimpl()
+	}
+
+	if c.level != nil {
+		h = h.WithLevel(c.level)
+	}
+	if c.addSource != nil {
+		h = h.WithSource(*c.addSource)
+	}
+	if c.deterministic {
+		h = handlers.Deterministic(h)
+	}
+	return h
+}
@@ -0,0 +1,78 @@
+package logx
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/texth"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+func TestNewOptions(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		l := New()
+
+		if l == nil || l.Handler() == nil {
+			t.Errorf("expected output logger and handler not to be nil")
+		}
+	})
+	t.Run("WriterJSON", func(t *testing.T) {
+		skipTiny(t)
+
+		b := &bytes.Buffer{}
+		l := New(WithWriter(b))
+
+		l.Info("test message")
+
+		wants := regexp.MustCompile(`{"timestamp":".*","message":"test message","level":"info"}`)
+		if !wants.MatchString(b.String()) {
+			t.Errorf("output mismatch error: wanted %s ; got %s", wants.String(), b.String())
+		}
+	})
+	t.Run("WriterText", func(t *testing.T) {
+		skipTiny(t)
+
+		b := &bytes.Buffer{}
+		l := New(
+			WithWriter(b),
+			WithFormat(FormatText),
+			WithLevel(level.Warn),
+			WithAttrs(attr.String("service", "logx")),
+			WithClock(records.ClockFunc(func() time.Time {
+				return time.Unix(1668802887, 0).UTC()
+			})),
+		)
+
+		l.Info("hidden")
+		l.Warn("test message")
+
+		wants := "[2022-11-18T20:21:27Z] [warn] test message [ service: logx ]\n"
+		if b.String() != wants {
+			t.Errorf("output mismatch error: wanted %q ; got %q", wants, b.String())
+		}
+	})
+	t.Run("HandlerPrecedence", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		other := &bytes.Buffer{}
+		l := New(WithWriter(other), WithHandler(texth.New(b)))
+
+		l.Info("test message")
+
+		if other.Len() != 0 || !strings.Contains(b.String(), "[info] test message") {
+			t.Errorf("expected the handler to take precedence over the writer")
+		}
+	})
+	t.Run("NilHandler", func(t *testing.T) {
+		l := New(WithHandler(nil))
+
+		if l.Handler() != handlers.Unimpl() {
+			t.Errorf("expected an unimplemented handler ; got %v", l.Handler())
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package logx
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"sort"
+
+	"github.com/zalgonoise/attr"
+)
+
+// WithPprofLabels sets the keys of the attributes that the Logger's
+// context-aware methods (see ContextPrinter) set as pprof labels on the
+// calling goroutine, along with the labels in the Context, like a request's
+// ID. The CPU profile samples taken afterwards carry these labels, so that
+// they can be correlated with the request's records.
+//
+// The attributes are looked up in the ones logged, extracted from the Context
+// and set in the Logger. See PprofExtractor for the opposite direction
+func WithPprofLabels(keys ...string) Option {
+	return func(c *config) {
+		c.pprofKeys = append(c.pprofKeys, keys...)
+	}
+}
+
+// PprofExtractor returns an Extractor that adds the pprof labels in a Context
+// (set with pprof.Do or pprof.WithLabels) as attributes, sorted by key. If
+// `keys` are provided, only the labels with these keys are added
+func PprofExtractor(keys ...string) Extractor {
+	allowed := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		allowed[key] = struct{}{}
+	}
+
+	return func(ctx context.Context) []attr.Attr {
+		var attrs []attr.Attr
+		pprof.ForLabels(ctx, func(key, value string) bool {
+			if _, ok := allowed[key]; ok || len(allowed) == 0 {
+				attrs = append(attrs, attr.String(key, value))
+			}
+			return true
+		})
+
+		sort.Slice(attrs, func(i, j int) bool {
+			return attrs[i].Key() < attrs[j].Key()
+		})
+		return attrs
+	}
+}
+
+// setPprofLabels sets the values of the attributes in `sets` with the keys
+// `keys` as pprof labels on the calling goroutine, along with the labels in
+// the Context `ctx`
+func setPprofLabels(ctx context.Context, keys []string, sets ...[]attr.Attr) {
+	var labels []string
+	for _, key := range keys {
+		for _, attrs := range sets {
+			if a := findAttr(key, attrs); a != nil {
+				labels = append(labels, key, fmt.Sprint(a.Value()))
+				break
+			}
+		}
+	}
+
+	if len(labels) > 0 {
+		pprof.SetGoroutineLabels(pprof.WithLabels(ctx, pprof.Labels(labels...)))
+	}
+}
+
+// findAttr returns the last attribute in `attrs` with the key `key`, or nil if
+// there is none
+func findAttr(key string, attrs []attr.Attr) attr.Attr {
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if attrs[i] != nil && attrs[i].Key() == key {
+			return attrs[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+)
+
+func TestPprofExtractor(t *testing.T) {
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("worker", "billing", "request_id", "abc"))
+
+	for _, test := range []struct {
+		name  string
+		keys  []string
+		wants string
+	}{
+		{"All", nil, `"data":{"request_id":"abc","worker":"billing"}`},
+		{"Keys", []string{"worker"}, `"data":{"worker":"billing"}`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			New(WithHandler(jsonh.New(b)), WithExtractors(PprofExtractor(test.keys...))).InfoContext(ctx, "job done")
+
+			if !strings.Contains(b.String(), test.wants) {
+				t.Errorf("output mismatch error: wanted %s ; got %s", test.wants, b.String())
+			}
+		})
+	}
+}
+
+func TestWithPprofLabels(t *testing.T) {
+	logger := New(WithHandler(jsonh.New(&bytes.Buffer{})), WithPprofLabels("request_id"))
+
+	done := make(chan struct{})
+	logged := make(chan struct{})
+	go func() {
+		logger.InfoContext(context.Background(), "request received", attr.String("request_id", "req-42"))
+		close(logged)
+		<-done
+	}()
+	<-logged
+	defer close(done)
+
+	b := &bytes.Buffer{}
+	if err := pprof.Lookup("goroutine").WriteTo(b, 1); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if !strings.Contains(b.String(), `"request_id":"req-42"`) {
+		t.Errorf("expected a goroutine labeled with the request ID")
+	}
+}
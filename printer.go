@@ -1,9 +1,14 @@
 package logx
 
 import (
-	"time"
+	"context"
+	"runtime"
+	"strings"
+	"sync"
 
 	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/internal/stats"
 	"github.com/zalgonoise/logx/level"
 	"github.com/zalgonoise/logx/records"
 )
@@ -36,89 +41,239 @@ type Printer interface {
 	Log(level level.Level, msg string, attrs ...attr.Attr)
 }
 
+// ContextPrinter interface describes the context-aware counterpart of the
+// Printer interface. Its methods add the attributes returned by the Logger's
+// Extractors for the input Context to the log message, and a
+// handlers.CanceledKey attribute if the Context is already done (see
+// handlers.Canceled)
+type ContextPrinter interface {
+	// TraceContext prints a log message `msg` with attributes `attrs` and
+	// those extracted from `ctx`, with Trace-level
+	TraceContext(ctx context.Context, msg string, attrs ...attr.Attr)
+	// DebugContext prints a log message `msg` with attributes `attrs` and
+	// those extracted from `ctx`, with Debug-level
+	DebugContext(ctx context.Context, msg string, attrs ...attr.Attr)
+	// InfoContext prints a log message `msg` with attributes `attrs` and
+	// those extracted from `ctx`, with Info-level
+	InfoContext(ctx context.Context, msg string, attrs ...attr.Attr)
+	// WarnContext prints a log message `msg` with attributes `attrs` and
+	// those extracted from `ctx`, with Warn-level
+	WarnContext(ctx context.Context, msg string, attrs ...attr.Attr)
+	// ErrorContext prints a log message `msg` with attributes `attrs` and
+	// those extracted from `ctx`, with Error-level
+	ErrorContext(ctx context.Context, msg string, attrs ...attr.Attr)
+	// FatalContext prints a log message `msg` with attributes `attrs` and
+	// those extracted from `ctx`, with Fatal-level
+	FatalContext(ctx context.Context, msg string, attrs ...attr.Attr)
+	// LogContext prints a log message `msg` with attributes `attrs` and
+	// those extracted from `ctx`, with `level` log level
+	LogContext(ctx context.Context, level level.Level, msg string, attrs ...attr.Attr)
+}
+
 // Log prints a log message `msg` with attributes `attrs`, with
 // `level` log level
 func (l *logger) Log(lv level.Level, msg string, attrs ...attr.Attr) {
-	if msg == "" {
-		return
-	}
 	if lv == nil {
 		lv = level.Info
 	}
-
-	rAttr := append(attrs, l.attrs...)
-	r := records.New(time.Now(), lv, msg, rAttr...)
-	_ = l.h.Handle(r)
+	l.log(nil, lv, msg, attrs)
 }
 
 // Trace prints a log message `msg` with attributes `attrs`, with
 // Trace-level
 func (l *logger) Trace(msg string, attrs ...attr.Attr) {
-	if msg == "" {
-		return
-	}
-
-	rAttr := append(attrs, l.attrs...)
-	r := records.New(time.Now(), level.Trace, msg, rAttr...)
-	_ = l.h.Handle(r)
+	l.log(nil, level.Trace, msg, attrs)
 }
 
 // Debug prints a log message `msg` with attributes `attrs`, with
 // Debug-level
 func (l *logger) Debug(msg string, attrs ...attr.Attr) {
-	if msg == "" {
-		return
-	}
-
-	rAttr := append(attrs, l.attrs...)
-	r := records.New(time.Now(), level.Debug, msg, rAttr...)
-	_ = l.h.Handle(r)
+	l.log(nil, level.Debug, msg, attrs)
 }
 
 // Info prints a log message `msg` with attributes `attrs`, with
 // Info-level
 func (l *logger) Info(msg string, attrs ...attr.Attr) {
-	if msg == "" {
-		return
-	}
-
-	rAttr := append(attrs, l.attrs...)
-	r := records.New(time.Now(), level.Info, msg, rAttr...)
-	_ = l.h.Handle(r)
+	l.log(nil, level.Info, msg, attrs)
 }
 
 // Warn prints a log message `msg` with attributes `attrs`, with
 // Warn-level
 func (l *logger) Warn(msg string, attrs ...attr.Attr) {
-	if msg == "" {
-		return
-	}
-
-	rAttr := append(attrs, l.attrs...)
-	r := records.New(time.Now(), level.Warn, msg, rAttr...)
-	_ = l.h.Handle(r)
+	l.log(nil, level.Warn, msg, attrs)
 }
 
 // Error prints a log message `msg` with attributes `attrs`, with
 // Error-level
 func (l *logger) Error(msg string, attrs ...attr.Attr) {
-	if msg == "" {
-		return
-	}
-
-	rAttr := append(attrs, l.attrs...)
-	r := records.New(time.Now(), level.Error, msg, rAttr...)
-	_ = l.h.Handle(r)
+	l.log(nil, level.Error, msg, attrs)
 }
 
 // Fatal prints a log message `msg` with attributes `attrs`, with
 // Fatal-level
 func (l *logger) Fatal(msg string, attrs ...attr.Attr) {
-	if msg == "" {
+	l.log(nil, level.Fatal, msg, attrs)
+}
+
+// maxStackAttrs is the number of attributes that a pooled record can be built
+// with before its attributes' buffer is moved to the heap
+const maxStackAttrs = 16
+
+// LogContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with `level` log level
+func (l *logger) LogContext(ctx context.Context, lv level.Level, msg string, attrs ...attr.Attr) {
+	if lv == nil {
+		lv = level.Info
+	}
+	l.log(ctx, lv, msg, attrs)
+}
+
+// TraceContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with Trace-level
+func (l *logger) TraceContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	l.log(ctx, level.Trace, msg, attrs)
+}
+
+// DebugContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with Debug-level
+func (l *logger) DebugContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	l.log(ctx, level.Debug, msg, attrs)
+}
+
+// InfoContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with Info-level
+func (l *logger) InfoContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	l.log(ctx, level.Info, msg, attrs)
+}
+
+// WarnContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with Warn-level
+func (l *logger) WarnContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	l.log(ctx, level.Warn, msg, attrs)
+}
+
+// ErrorContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with Error-level
+func (l *logger) ErrorContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	l.log(ctx, level.Error, msg, attrs)
+}
+
+// FatalContext prints a log message `msg` with attributes `attrs` and
+// those extracted from `ctx`, with Fatal-level
+func (l *logger) FatalContext(ctx context.Context, msg string, attrs ...attr.Attr) {
+	l.log(ctx, level.Fatal, msg, attrs)
+}
+
+func (l *logger) log(ctx context.Context, lv level.Level, msg string, attrs []attr.Attr) {
+	l.logPC(ctx, 0, lv, msg, attrs)
+}
+
+// logPC logs a record as the call site with the program counter `pc`, or as
+// the caller of the Logger if zero
+func (l *logger) logPC(ctx context.Context, pc uintptr, lv level.Level, msg string, attrs []attr.Attr) {
+	if msg == "" || !l.module.enabled(lv) || !l.h.Enabled(lv) {
+		return
+	}
+	if pc == 0 {
+		pc = callerPC()
+	}
+
+	if ctx != nil {
+		attrs = l.extract(ctx, attrs)
+		if err := ctx.Err(); err != nil {
+			attrs = append(attrs[:len(attrs):len(attrs)], attr.String(handlers.CanceledKey, err.Error()))
+		}
+		if len(l.pprofKeys) > 0 {
+			setPprofLabels(ctx, l.pprofKeys, attrs, l.attrs)
+		}
+	}
+
+	stats.Records[stats.Bucket(lv.Int())].Add(1)
+	if v := stats.Tracker.Load(); v != nil {
+		v.Add(lv.Int(), msg, recordSize(msg, attrs, l.attrs))
+	}
+
+	if !l.pool {
+		r := records.WithPC(records.New(l.now(), lv, msg, l.recordAttrs(nil, attrs)...), pc)
+		if err := l.h.Handle(r); err != nil {
+			l.handleError(err, r)
+		}
 		return
 	}
 
-	rAttr := append(attrs, l.attrs...)
-	r := records.New(time.Now(), level.Fatal, msg, rAttr...)
-	_ = l.h.Handle(r)
+	var buf [maxStackAttrs]attr.Attr
+
+	r := records.WithPC(records.Acquire(l.now(), lv, msg, l.recordAttrs(buf[:0], attrs)...), pc)
+	if err := l.h.Handle(r); err != nil {
+		l.handleError(err, r)
+	}
+	records.Release(r)
+}
+
+const modulePath = "github.com/zalgonoise/logx"
+
+// passThrough lists the prefixes of the functions that log records on behalf
+// of their callers, skipped when capturing the call site of a record: the
+// ones in this package (like the package-level functions, and the log and
+// slog bridges), in the log and log/slog packages, and the span Logger of
+// the logxotel package
+var passThrough = []string{
+	modulePath + ".",
+	modulePath + "/logxotel.spanLogger.",
+	"log.",
+	"log/slog.",
+}
+
+// callSites caches whether each program counter belongs to a function listed
+// in passThrough, so that the hot log sites resolve theirs once
+var callSites sync.Map
+
+// callerPC returns the program counter of the code that logged the record,
+// which is the first caller that is not passing through (or that is within
+// this package's tests)
+func callerPC() uintptr {
+	var pcs [16]uintptr
+	for _, pc := range pcs[:runtime.Callers(3, pcs[:])] {
+		if !passingThrough(pc) {
+			return pc
+		}
+	}
+	return 0
+}
+
+// passingThrough returns whether the program counter `pc` belongs to a
+// function listed in passThrough, outside of tests
+func passingThrough(pc uintptr) bool {
+	if skip, ok := callSites.Load(pc); ok {
+		return skip.(bool)
+	}
+
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	skip := false
+	if !strings.HasSuffix(f.File, "_test.go") {
+		for _, prefix := range passThrough {
+			if strings.HasPrefix(f.Function, prefix) {
+				skip = true
+				break
+			}
+		}
+	}
+
+	callSites.Store(pc, skip)
+	return skip
+}
+
+func (l *logger) recordAttrs(dst, attrs []attr.Attr) []attr.Attr {
+	if l.seq == nil && len(l.attrs) == 0 && l.name == "" && dst == nil {
+		return attrs
+	}
+
+	dst = append(append(dst, attrs...), l.attrs...)
+	if l.name != "" {
+		dst = append(dst, attr.String(handlers.LoggerKey, l.name))
+	}
+	if l.seq != nil {
+		dst = append(dst, attr.Uint("seq", l.seq.Add(1)))
+	}
+	return dst
 }
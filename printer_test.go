@@ -2,9 +2,14 @@ package logx
 
 import (
 	"bytes"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
 	"regexp"
+	"runtime"
 	"testing"
 
+	"github.com/zalgonoise/logx/handlers"
 	"github.com/zalgonoise/logx/handlers/jsonh"
 	"github.com/zalgonoise/logx/level"
 )
@@ -18,7 +23,7 @@ func TestLoggerLog(t *testing.T) {
 
 		wants := regexp.MustCompile(`{"timestamp":".*","message":"test message","level":"info"}`)
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Log(testLevel, testMsg)
 
 		if !wants.MatchString(b.String()) {
@@ -32,7 +37,7 @@ func TestLoggerLog(t *testing.T) {
 
 		wants := regexp.MustCompile(`{"timestamp":".*","message":"test message","level":"info"}`)
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Log(nil, testMsg)
 
 		if !wants.MatchString(b.String()) {
@@ -46,7 +51,7 @@ func TestLoggerLog(t *testing.T) {
 
 		wants := ""
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Log(testLevel, "")
 
 		if b.String() != wants {
@@ -64,7 +69,7 @@ func TestLoggerTrace(t *testing.T) {
 
 		wants := regexp.MustCompile(`{"timestamp":".*","message":"test message","level":"trace"}`)
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Trace(testMsg)
 
 		if !wants.MatchString(b.String()) {
@@ -77,7 +82,7 @@ func TestLoggerTrace(t *testing.T) {
 
 		wants := ""
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Trace("")
 
 		if b.String() != wants {
@@ -95,7 +100,7 @@ func TestLoggerDebug(t *testing.T) {
 
 		wants := regexp.MustCompile(`{"timestamp":".*","message":"test message","level":"debug"}`)
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Debug(testMsg)
 
 		if !wants.MatchString(b.String()) {
@@ -108,7 +113,7 @@ func TestLoggerDebug(t *testing.T) {
 
 		wants := ""
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Debug("")
 
 		if b.String() != wants {
@@ -125,7 +130,7 @@ func TestLoggerInfo(t *testing.T) {
 
 		wants := regexp.MustCompile(`{"timestamp":".*","message":"test message","level":"info"}`)
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Info(testMsg)
 
 		if !wants.MatchString(b.String()) {
@@ -138,7 +143,7 @@ func TestLoggerInfo(t *testing.T) {
 
 		wants := ""
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Info("")
 
 		if b.String() != wants {
@@ -155,7 +160,7 @@ func TestLoggerWarn(t *testing.T) {
 
 		wants := regexp.MustCompile(`{"timestamp":".*","message":"test message","level":"warn"}`)
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Warn(testMsg)
 
 		if !wants.MatchString(b.String()) {
@@ -168,7 +173,7 @@ func TestLoggerWarn(t *testing.T) {
 
 		wants := ""
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Warn("")
 
 		if b.String() != wants {
@@ -185,7 +190,7 @@ func TestLoggerError(t *testing.T) {
 
 		wants := regexp.MustCompile(`{"timestamp":".*","message":"test message","level":"error"}`)
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Error(testMsg)
 
 		if !wants.MatchString(b.String()) {
@@ -198,7 +203,7 @@ func TestLoggerError(t *testing.T) {
 
 		wants := ""
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Error("")
 
 		if b.String() != wants {
@@ -215,7 +220,7 @@ func TestLoggerFatal(t *testing.T) {
 
 		wants := regexp.MustCompile(`{"timestamp":".*","message":"test message","level":"fatal"}`)
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Fatal(testMsg)
 
 		if !wants.MatchString(b.String()) {
@@ -228,7 +233,7 @@ func TestLoggerFatal(t *testing.T) {
 
 		wants := ""
 
-		l := New(h)
+		l := New(WithHandler(h))
 		l.Fatal("")
 
 		if b.String() != wants {
@@ -236,3 +241,45 @@ func TestLoggerFatal(t *testing.T) {
 		}
 	})
 }
+
+func TestLoggerSource(t *testing.T) {
+	// source decodes the source reference of the record in `b`, as a
+	// file:line pair
+	source := func(t *testing.T, b []byte) (string, int) {
+		var entry struct {
+			Source struct {
+				File string `json:"file"`
+				Line int    `json:"line"`
+			} `json:"source"`
+		}
+		if err := json.Unmarshal(b, &entry); err != nil {
+			t.Errorf("unexpected error: %v ; output: %s", err, b)
+		}
+		return filepath.Base(entry.Source.File), entry.Source.Line
+	}
+
+	t.Run("Async", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		a := handlers.NewAsync(jsonh.New(b).WithSource(true), 8, handlers.Block, 0)
+		l := New(WithHandler(a))
+
+		_, _, line, _ := runtime.Caller(0)
+		l.Info("test message")
+		_ = a.Close()
+
+		if file, got := source(t, b.Bytes()); file != "printer_test.go" || got != line+1 {
+			t.Errorf("output mismatch error: wanted printer_test.go:%d ; got %s:%d", line+1, file, got)
+		}
+	})
+	t.Run("Slog", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := slog.New(NewSlogHandler(New(WithHandler(jsonh.New(b).WithSource(true)))))
+
+		_, _, line, _ := runtime.Caller(0)
+		l.Info("test message")
+
+		if file, got := source(t, b.Bytes()); file != "printer_test.go" || got != line+1 {
+			t.Errorf("output mismatch error: wanted printer_test.go:%d ; got %s:%d", line+1, file, got)
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package records
+
+import "time"
+
+// Clock interface describes a source of time for Record timestamps
+//
+// It allows loggers to be configured with a deterministic or virtual time
+// source, instead of the wall clock
+type Clock interface {
+	// Now returns the current time, as perceived by this Clock
+	Now() time.Time
+}
+
+// ClockFunc is a function type that implements the Clock interface
+type ClockFunc func() time.Time
+
+// Now returns the current time, as perceived by this Clock
+func (fn ClockFunc) Now() time.Time {
+	return fn()
+}
+
+// SystemClock is a Clock backed by time.Now
+var SystemClock Clock = ClockFunc(time.Now)
@@ -0,0 +1,24 @@
+package records
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClockFunc(t *testing.T) {
+	t.Run("Fixed", func(t *testing.T) {
+		clock := ClockFunc(func() time.Time { return testTime })
+
+		if out := clock.Now(); !out.Equal(testTime) {
+			t.Errorf("unexpected output error: wanted %v ; got %v", testTime, out)
+		}
+	})
+	t.Run("SystemClock", func(t *testing.T) {
+		before := time.Now()
+		out := SystemClock.Now()
+
+		if out.Before(before) {
+			t.Errorf("expected system clock time %v not to be before %v", out, before)
+		}
+	})
+}
@@ -0,0 +1,338 @@
+package codec
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+const maxFrameSize = 64 << 20 // 64 MiB
+
+// maxDepth is the deepest nesting of groups of attributes that is decoded, so
+// that a crafted frame cannot exhaust the stack
+const maxDepth = 64
+
+const (
+	typeString byte = iota
+	typeInt
+	typeUint
+	typeFloat
+	typeBool
+	typeTime
+	typeDuration
+	typeGroup
+	typeNil
+	typeOther
+)
+
+var (
+	// ErrFrameTooLarge is raised when a decoded frame's length exceeds the
+	// maximum supported frame size
+	ErrFrameTooLarge error = errors.New("frame exceeds maximum size")
+	// ErrInvalidFrame is raised when a frame's contents cannot be decoded
+	// into a Record
+	ErrInvalidFrame error = errors.New("invalid frame")
+	// ErrNilRecord is raised when attempting to encode a nil Record
+	ErrNilRecord error = errors.New("nil record")
+)
+
+// Encoder writes varint-framed, binary-encoded Records to an io.Writer
+type Encoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewEncoder creates an Encoder writing to the input io.Writer `w`
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the input Record `r` as a single frame, prefixed with its
+// length as an unsigned varint
+func (e *Encoder) Encode(r records.Record) error {
+	if r == nil {
+		return ErrNilRecord
+	}
+
+	payload := appendRecord(e.buf[:0], r)
+	frame := make([]byte, 0, binary.MaxVarintLen64+len(payload))
+	frame = binary.AppendUvarint(frame, uint64(len(payload)))
+	frame = append(frame, payload...)
+	e.buf = payload
+
+	_, err := e.w.Write(frame)
+	return err
+}
+
+// Decoder reads varint-framed, binary-encoded Records from an io.Reader
+type Decoder struct {
+	r *bufio.Reader
+}
+
+// NewDecoder creates a Decoder reading from the input io.Reader `r`
+func NewDecoder(r io.Reader) *Decoder {
+	if br, ok := r.(*bufio.Reader); ok {
+		return &Decoder{r: br}
+	}
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next frame and returns it as a Record. It returns io.EOF
+// when there are no more frames to read
+func (d *Decoder) Decode() (records.Record, error) {
+	size, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxFrameSize {
+		return nil, ErrFrameTooLarge
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(d.r, payload); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return Unmarshal(payload)
+}
+
+// Marshal encodes the input Record `r` as an (unframed) binary payload
+func Marshal(r records.Record) ([]byte, error) {
+	if r == nil {
+		return nil, ErrNilRecord
+	}
+	return appendRecord(nil, r), nil
+}
+
+// Unmarshal decodes the input (unframed) binary payload `b` into a Record. An
+// error is returned if the payload holds more than a Record, or if its level is
+// not known (see level.Parse)
+func Unmarshal(b []byte) (records.Record, error) {
+	d := &reader{b: b}
+
+	ts := d.varint()
+	lvName := d.string()
+	msg := d.string()
+	attrs := d.attrs()
+
+	if d.err != nil {
+		return nil, d.err
+	}
+	if len(d.b) > 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidFrame, len(d.b))
+	}
+
+	lv, err := level.Parse(lvName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFrame, err)
+	}
+	return records.New(time.Unix(0, ts), lv, msg, attrs...), nil
+}
+
+func appendRecord(b []byte, r records.Record) []byte {
+	b = binary.AppendVarint(b, r.Time().UnixNano())
+	b = appendString(b, r.Level().String())
+	b = appendString(b, r.Message())
+	return appendAttrs(b, r.Attrs())
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendAttrs(b []byte, attrs []attr.Attr) []byte {
+	var n uint64
+	for _, a := range attrs {
+		if a != nil {
+			n++
+		}
+	}
+	b = binary.AppendUvarint(b, n)
+
+	for _, a := range attrs {
+		if a == nil {
+			continue
+		}
+		b = appendString(b, a.Key())
+		b = appendValue(b, a.Value())
+	}
+	return b
+}
+
+func appendValue(b []byte, value any) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(b, typeNil)
+	case string:
+		return appendString(append(b, typeString), v)
+	case int:
+		return binary.AppendVarint(append(b, typeInt), int64(v))
+	case int8:
+		return binary.AppendVarint(append(b, typeInt), int64(v))
+	case int16:
+		return binary.AppendVarint(append(b, typeInt), int64(v))
+	case int32:
+		return binary.AppendVarint(append(b, typeInt), int64(v))
+	case int64:
+		return binary.AppendVarint(append(b, typeInt), v)
+	case uint:
+		return binary.AppendUvarint(append(b, typeUint), uint64(v))
+	case uint8:
+		return binary.AppendUvarint(append(b, typeUint), uint64(v))
+	case uint16:
+		return binary.AppendUvarint(append(b, typeUint), uint64(v))
+	case uint32:
+		return binary.AppendUvarint(append(b, typeUint), uint64(v))
+	case uint64:
+		return binary.AppendUvarint(append(b, typeUint), v)
+	case float32:
+		return binary.BigEndian.AppendUint64(append(b, typeFloat), math.Float64bits(float64(v)))
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, typeFloat), math.Float64bits(v))
+	case bool:
+		if v {
+			return append(b, typeBool, 1)
+		}
+		return append(b, typeBool, 0)
+	case time.Time:
+		return binary.AppendVarint(append(b, typeTime), v.UnixNano())
+	case time.Duration:
+		return binary.AppendVarint(append(b, typeDuration), int64(v))
+	case []attr.Attr:
+		return appendAttrs(append(b, typeGroup), v)
+	case attr.Attrs:
+		return appendAttrs(append(b, typeGroup), v)
+	default:
+		return appendString(append(b, typeOther), fmt.Sprint(v))
+	}
+}
+
+type reader struct {
+	b     []byte
+	err   error
+	depth int
+}
+
+func (d *reader) fail() {
+	if d.err == nil {
+		d.err = ErrInvalidFrame
+	}
+	d.b = nil
+}
+
+func (d *reader) byte() byte {
+	if d.err != nil || len(d.b) == 0 {
+		d.fail()
+		return 0
+	}
+	v := d.b[0]
+	d.b = d.b[1:]
+	return v
+}
+
+func (d *reader) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.b)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *reader) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *reader) string() string {
+	size := d.uvarint()
+	if d.err != nil || size > uint64(len(d.b)) {
+		d.fail()
+		return ""
+	}
+	v := string(d.b[:size])
+	d.b = d.b[size:]
+	return v
+}
+
+func (d *reader) attrs() []attr.Attr {
+	n := d.uvarint()
+	if d.err != nil || n > uint64(len(d.b)) {
+		d.fail()
+		return nil
+	}
+
+	attrs := make([]attr.Attr, 0, n)
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		key := d.string()
+		if a := d.value(key); a != nil {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
+}
+
+func (d *reader) value(key string) attr.Attr {
+	switch d.byte() {
+	case typeNil:
+		return attr.New[any](key, nil)
+	case typeString:
+		return attr.String(key, d.string())
+	case typeInt:
+		return attr.Int(key, d.varint())
+	case typeUint:
+		return attr.Uint(key, d.uvarint())
+	case typeFloat:
+		if len(d.b) < 8 {
+			d.fail()
+			return nil
+		}
+		v := math.Float64frombits(binary.BigEndian.Uint64(d.b))
+		d.b = d.b[8:]
+		return attr.Float(key, v)
+	case typeBool:
+		return attr.New(key, d.byte() == 1)
+	case typeTime:
+		return attr.New(key, time.Unix(0, d.varint()))
+	case typeDuration:
+		return attr.New(key, time.Duration(d.varint()))
+	case typeGroup:
+		if d.depth >= maxDepth {
+			d.fail()
+			return nil
+		}
+		d.depth++
+		attrs := d.attrs()
+		d.depth--
+		return attr.New(key, attrs)
+	case typeOther:
+		return attr.String(key, d.string())
+	default:
+		d.fail()
+		return nil
+	}
+}
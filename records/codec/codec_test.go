@@ -0,0 +1,175 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+var (
+	testTime = time.Unix(1668802887, 0)
+	testMsg  = "test message"
+
+	testAttrs = []attr.Attr{
+		attr.String("a_key", "value"),
+		attr.Int("b_test_no", int64(-1)),
+		attr.Uint("c_count", uint64(3)),
+		attr.Float("d_success_rate", 0.5),
+		attr.New("e_ok", true),
+		attr.New("f_elapsed", time.Second),
+		attr.New("g_group", []attr.Attr{
+			attr.String("inner", "value"),
+		}),
+	}
+)
+
+func TestMarshalUnmarshal(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		r := records.New(testTime, level.Warn, testMsg)
+
+		b, err := Marshal(r)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		out, err := Unmarshal(b)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if !out.Time().Equal(testTime) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", testTime, out.Time())
+		}
+		if out.Level() != level.Warn {
+			t.Errorf("output mismatch error: wanted %v ; got %v", level.Warn, out.Level())
+		}
+		if out.Message() != testMsg {
+			t.Errorf("output mismatch error: wanted %v ; got %v", testMsg, out.Message())
+		}
+	})
+	t.Run("WithAttrs", func(t *testing.T) {
+		r := records.New(testTime, level.Info, testMsg, testAttrs...)
+
+		b, err := Marshal(r)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		out, err := Unmarshal(b)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if !reflect.DeepEqual(attr.Map(testAttrs...), attr.Map(out.Attrs()...)) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", testAttrs, out.Attrs())
+		}
+	})
+	t.Run("NilRecord", func(t *testing.T) {
+		_, err := Marshal(nil)
+		if !errors.Is(err, ErrNilRecord) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrNilRecord, err)
+		}
+	})
+	t.Run("Truncated", func(t *testing.T) {
+		b, _ := Marshal(records.New(testTime, level.Info, testMsg, testAttrs...))
+
+		_, err := Unmarshal(b[:len(b)-3])
+		if !errors.Is(err, ErrInvalidFrame) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrInvalidFrame, err)
+		}
+	})
+	t.Run("TrailingBytes", func(t *testing.T) {
+		b, _ := Marshal(records.New(testTime, level.Info, testMsg, testAttrs...))
+
+		_, err := Unmarshal(append(b, 0))
+		if !errors.Is(err, ErrInvalidFrame) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrInvalidFrame, err)
+		}
+	})
+	t.Run("UnknownLevel", func(t *testing.T) {
+		b := binary.AppendVarint(nil, testTime.UnixNano())
+		b = appendString(b, "loud")
+		b = appendString(b, testMsg)
+		b = appendAttrs(b, nil)
+
+		_, err := Unmarshal(b)
+		if !errors.Is(err, ErrInvalidFrame) || !errors.Is(err, level.ErrInvalidLevel) {
+			t.Errorf("unexpected error: wanted %v ; got %v", level.ErrInvalidLevel, err)
+		}
+	})
+	t.Run("TooDeep", func(t *testing.T) {
+		nested := func(depth int) records.Record {
+			a := attr.String("leaf", "value")
+			for i := 0; i < depth; i++ {
+				a = attr.New("group", []attr.Attr{a})
+			}
+			return records.New(testTime, level.Info, testMsg, a)
+		}
+
+		b, _ := Marshal(nested(maxDepth))
+		if _, err := Unmarshal(b); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		b, _ = Marshal(nested(maxDepth + 1))
+		if _, err := Unmarshal(b); !errors.Is(err, ErrInvalidFrame) {
+			t.Errorf("unexpected error: wanted %v ; got %v", ErrInvalidFrame, err)
+		}
+	})
+}
+
+func TestEncoderDecoder(t *testing.T) {
+	t.Run("Stream", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf)
+
+		input := []records.Record{
+			records.New(testTime, level.Debug, "first"),
+			records.New(testTime, level.Error, "second", testAttrs...),
+		}
+		for _, r := range input {
+			if err := enc.Encode(r); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+		}
+
+		dec := NewDecoder(buf)
+		for _, r := range input {
+			out, err := dec.Decode()
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if out.Message() != r.Message() {
+				t.Errorf("output mismatch error: wanted %v ; got %v", r.Message(), out.Message())
+			}
+			if out.AttrLen() != r.AttrLen() {
+				t.Errorf("output mismatch error: wanted %v ; got %v", r.AttrLen(), out.AttrLen())
+			}
+		}
+
+		if _, err := dec.Decode(); !errors.Is(err, io.EOF) {
+			t.Errorf("unexpected error: wanted %v ; got %v", io.EOF, err)
+		}
+	})
+	t.Run("ShortFrame", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		_ = NewEncoder(buf).Encode(records.New(testTime, level.Info, testMsg))
+
+		dec := NewDecoder(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+		if _, err := dec.Decode(); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("unexpected error: wanted %v ; got %v", io.ErrUnexpectedEOF, err)
+		}
+	})
+}
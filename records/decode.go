@@ -0,0 +1,308 @@
+package records
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/intern"
+	"github.com/zalgonoise/logx/level"
+)
+
+const (
+	keyTimestamp = "timestamp"
+	keyMessage   = "message"
+	keyLevel     = "level"
+	keyData      = "data"
+)
+
+// ErrInvalidEncoding is raised when the Decoder's input is not in the JSON
+// nor text format written by the jsonh and texth handlers
+var ErrInvalidEncoding error = errors.New("invalid record encoding")
+
+// Decoder reads the records written by the jsonh and texth handlers (with
+// their default settings) from an io.Reader, as Records.
+//
+// The format is detected from the first byte of the input: JSON objects
+// start with `{`, and text lines with `[`. JSON numbers are decoded as int64
+// (or uint64, if too large) and float64 values, and objects as groups of
+// attributes, sorted by key. As the text format is not typed, its values are
+// decoded as bool, int64 or float64 values when they parse as one, or as
+// strings otherwise; and string values containing the attribute separators
+// are not decoded correctly
+type Decoder struct {
+	r    *bufio.Reader
+	json *json.Decoder
+	text *bufio.Scanner
+}
+
+// NewDecoder creates a Decoder reading from the input io.Reader `r`
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode returns the next Record in the input, or io.EOF if there are none
+// left
+func (d *Decoder) Decode() (Record, error) {
+	if d.json == nil && d.text == nil {
+		if err := d.detect(); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.json != nil {
+		return d.decodeJSON()
+	}
+	return d.decodeText()
+}
+
+func (d *Decoder) detect() error {
+	for {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{':
+			_ = d.r.UnreadByte()
+			d.json = json.NewDecoder(d.r)
+			d.json.UseNumber()
+			return nil
+		case '[':
+			_ = d.r.UnreadByte()
+			d.text = bufio.NewScanner(d.r)
+			d.text.Buffer(make([]byte, 0, 64*1024), 64<<20)
+			return nil
+		default:
+			return fmt.Errorf("%w: unexpected %q", ErrInvalidEncoding, c)
+		}
+	}
+}
+
+func (d *Decoder) decodeJSON() (Record, error) {
+	var obj map[string]any
+	if err := d.json.Decode(&obj); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+
+	var (
+		ts    time.Time
+		msg   string
+		lv    level.Level
+		attrs []attr.Attr
+		err   error
+	)
+
+	if v, ok := obj[keyTimestamp].(string); ok {
+		if ts, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+		}
+	}
+	msg, _ = obj[keyMessage].(string)
+	if v, ok := obj[keyLevel].(string); ok {
+		if lv, err = level.Parse(v); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+		}
+	}
+	if data, ok := obj[keyData].(map[string]any); ok {
+		attrs = jsonAttrs(data)
+	}
+
+	// keep any other top-level fields as attributes
+	for _, key := range sortedKeys(obj) {
+		switch key {
+		case keyTimestamp, keyMessage, keyLevel, keyData:
+		default:
+			attrs = append(attrs, attr.New(intern.Key(key), jsonValue(obj[key])))
+		}
+	}
+
+	return New(ts, lv, msg, attrs...), nil
+}
+
+func sortedKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func jsonAttrs(obj map[string]any) []attr.Attr {
+	attrs := make([]attr.Attr, 0, len(obj))
+	for _, key := range sortedKeys(obj) {
+		attrs = append(attrs, attr.New(intern.Key(key), jsonValue(obj[key])))
+	}
+	return attrs
+}
+
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return intern.Value(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return u
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		return jsonAttrs(v)
+	case []any:
+		for i := range v {
+			v[i] = jsonValue(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func (d *Decoder) decodeText() (Record, error) {
+	for d.text.Scan() {
+		line := bytes.TrimSpace(d.text.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		return parseText(string(line))
+	}
+	if err := d.text.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// parseText parses a line in the format `[time] [level] message [ key: value ; ... ]`
+func parseText(line string) (Record, error) {
+	ts, rest, ok := cutWrapped(line)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing timestamp: %q", ErrInvalidEncoding, line)
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+
+	name, rest, ok := cutWrapped(strings.TrimPrefix(rest, " "))
+	if !ok {
+		return nil, fmt.Errorf("%w: missing level: %q", ErrInvalidEncoding, line)
+	}
+	lv, err := level.Parse(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+
+	msg := strings.TrimPrefix(rest, " ")
+	var attrs []attr.Attr
+	if start := groupStart(msg); start >= 0 {
+		attrs = textAttrs(msg[start+1 : len(msg)-1])
+		msg = strings.TrimSuffix(msg[:start], " ")
+	}
+
+	return New(t, lv, msg, attrs...), nil
+}
+
+// cutWrapped returns the contents of the leading `[...]` in `s`, and the
+// remainder after it
+func cutWrapped(s string) (inner, rest string, ok bool) {
+	if !strings.HasPrefix(s, "[") {
+		return "", s, false
+	}
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return "", s, false
+	}
+	return s[1:end], s[end+1:], true
+}
+
+// groupStart returns the index of the `[` opening the attributes group that
+// ends `s`, or -1 if `s` does not end with one
+func groupStart(s string) int {
+	if !strings.HasSuffix(s, " ]") {
+		return -1
+	}
+
+	depth := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		switch {
+		case s[i] == ']' && i > 0 && s[i-1] == ' ':
+			depth++
+		case s[i] == '[' && i < len(s)-1 && s[i+1] == ' ':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// textAttrs parses the contents of an attributes group, in the format
+// ` key: value ; key: [ key: value ] `
+func textAttrs(s string) []attr.Attr {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return []attr.Attr{}
+	}
+
+	var (
+		attrs []attr.Attr
+		depth int
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '[' && i+1 < len(s) && s[i+1] == ' ':
+			depth++
+		case s[i] == ']' && i > 0 && s[i-1] == ' ':
+			depth--
+		case depth == 0 && strings.HasPrefix(s[i:], " ; "):
+			attrs = append(attrs, textAttr(s[start:i]))
+			start = i + len(" ; ")
+			i = start - 1
+		}
+	}
+	return append(attrs, textAttr(s[start:]))
+}
+
+func textAttr(s string) attr.Attr {
+	key, value, _ := strings.Cut(s, ": ")
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		return attr.New(intern.Key(key), textAttrs(value[1:len(value)-1]))
+	}
+	return attr.New(intern.Key(key), textValue(value))
+}
+
+func textValue(s string) any {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return intern.Value(s)
+}
@@ -0,0 +1,104 @@
+package records
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+)
+
+var decodeTime = time.Date(2024, 5, 6, 7, 8, 9, 123000000, time.UTC)
+
+func decodeAll(t *testing.T, input string) []Record {
+	var (
+		out []Record
+		dec = NewDecoder(strings.NewReader(input))
+	)
+	for {
+		r, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			return out
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return out
+		}
+		out = append(out, r)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		out := decodeAll(t, `{"timestamp":"2024-05-06T07:08:09.123Z","message":"cache miss","level":"warn","data":{"attempt":2,"key":"users/42","ok":false,"origin":{"region":"eu","zone":3},"ratio":0.5}}`+
+			`{"timestamp":"2024-05-06T07:08:09.123Z","message":"plain","level":"info+2"}`)
+		if len(out) != 2 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 2, len(out))
+			return
+		}
+
+		r := out[0]
+		if !r.Time().Equal(decodeTime) || r.Level() != level.Warn || r.Message() != "cache miss" {
+			t.Errorf("output mismatch error: wanted the first record ; got %v", r)
+		}
+		wants := map[string]any{
+			"attempt": int64(2),
+			"key":     "users/42",
+			"ok":      false,
+			"origin":  map[string]any{"region": "eu", "zone": int64(3)},
+			"ratio":   0.5,
+		}
+		if got := attr.Map(r.Attrs()...); !reflect.DeepEqual(got, wants) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, got)
+		}
+		if out[1].Level() != level.Info.Offset(2) || out[1].AttrLen() != 0 {
+			t.Errorf("output mismatch error: wanted an offset level and no attributes ; got %v", out[1])
+		}
+	})
+	t.Run("Text", func(t *testing.T) {
+		out := decodeAll(t, "[2024-05-06T07:08:09.123Z] [warn] cache miss [ key: users/42 ; attempt: 2 ; ratio: 0.5 ; ok: false ; origin: [ region: eu ; zone: 3 ] ]\n"+
+			"\n[2024-05-06T07:08:09.123Z] [info+2] plain [x]\n")
+		if len(out) != 2 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 2, len(out))
+			return
+		}
+
+		r := out[0]
+		if !r.Time().Equal(decodeTime) || r.Level() != level.Warn || r.Message() != "cache miss" {
+			t.Errorf("output mismatch error: wanted the first record ; got %v", r)
+		}
+		wants := map[string]any{
+			"attempt": int64(2),
+			"key":     "users/42",
+			"ok":      false,
+			"origin":  map[string]any{"region": "eu", "zone": int64(3)},
+			"ratio":   0.5,
+		}
+		if got := attr.Map(r.Attrs()...); !reflect.DeepEqual(got, wants) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, got)
+		}
+		if out[1].Message() != "plain [x]" || out[1].Level() != level.Info.Offset(2) {
+			t.Errorf("output mismatch error: wanted the message with brackets ; got %v", out[1])
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		for _, input := range []string{
+			"not a record",
+			`{"timestamp":"yesterday","message":"m","level":"info"}`,
+			"[2024-05-06T07:08:09.123Z] [loud] message\n",
+		} {
+			if _, err := NewDecoder(strings.NewReader(input)).Decode(); !errors.Is(err, ErrInvalidEncoding) {
+				t.Errorf("unexpected error: wanted %v ; got %v", ErrInvalidEncoding, err)
+			}
+		}
+	})
+	t.Run("Empty", func(t *testing.T) {
+		if _, err := NewDecoder(strings.NewReader(" \n")).Decode(); !errors.Is(err, io.EOF) {
+			t.Errorf("unexpected error: wanted %v ; got %v", io.EOF, err)
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package records
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/zalgonoise/attr"
+)
+
+// HashOptions selects the attributes covered by Hash
+type HashOptions struct {
+	// Keys are the keys of the (top-level) attributes covered by the hash. If
+	// empty, all attributes are covered
+	Keys []string
+	// Exclude are the keys of the (top-level) attributes left out of the hash,
+	// like request IDs or other values unique to each occurrence
+	Exclude []string
+}
+
+// Hash returns a stable hash of the Record `r`, covering its message, its level
+// and the attributes selected by the HashOptions `opts`, but not its time. It
+// identifies the occurrences of the same record, to deduplicate them or to
+// derive idempotency keys from them.
+//
+// The hash is canonical: it does not depend on the order of the attributes
+// (which are sorted by key, in groups as well), and numbers hash the same
+// regardless of their type. So a Record decoded from its JSON output (with a
+// Decoder) hashes the same as the original one, as long as its attributes hold
+// strings, numbers, booleans and groups
+func Hash(r Record, opts HashOptions) uint64 {
+	h := fnv.New64a()
+
+	writeHashString(h, r.Message())
+	if lv := r.Level(); lv != nil {
+		writeHashString(h, lv.String())
+	} else {
+		writeHashString(h, "")
+	}
+
+	attrs := make([]attr.Attr, 0, r.AttrLen())
+	for _, a := range r.Attrs() {
+		if a != nil && hashCovers(a.Key(), opts) {
+			attrs = append(attrs, a)
+		}
+	}
+	writeHashAttrs(h, attrs)
+
+	return h.Sum64()
+}
+
+// hashCovers returns whether the attribute with key `key` is covered by the
+// hash, as selected by `opts`
+func hashCovers(key string, opts HashOptions) bool {
+	for _, k := range opts.Exclude {
+		if k == key {
+			return false
+		}
+	}
+	if len(opts.Keys) == 0 {
+		return true
+	}
+	for _, k := range opts.Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// writeHashAttrs writes the attributes `attrs` sorted by key, preserving the
+// order of the ones sharing a key. The slice is sorted in place
+func writeHashAttrs(h hash.Hash64, attrs []attr.Attr) {
+	sort.SliceStable(attrs, func(i, j int) bool {
+		return attrs[i].Key() < attrs[j].Key()
+	})
+
+	writeHashLen(h, len(attrs))
+	for _, a := range attrs {
+		writeHashString(h, a.Key())
+		writeHashValue(h, a.Value())
+	}
+}
+
+// writeHashValue writes the attribute value `v` led by a tag of its kind:
+// groups, numbers, booleans, nil, and text for anything else
+func writeHashValue(h hash.Hash64, v any) {
+	var group []attr.Attr
+	switch v := v.(type) {
+	case []attr.Attr:
+		group = v
+	case attr.Attrs:
+		group = v
+	case nil:
+		_, _ = h.Write([]byte{'z'})
+		return
+	case bool:
+		if v {
+			_, _ = h.Write([]byte{'b', 1})
+		} else {
+			_, _ = h.Write([]byte{'b', 0})
+		}
+		return
+	case int:
+		writeHashNumber(h, strconv.FormatInt(int64(v), 10))
+		return
+	case int8:
+		writeHashNumber(h, strconv.FormatInt(int64(v), 10))
+		return
+	case int16:
+		writeHashNumber(h, strconv.FormatInt(int64(v), 10))
+		return
+	case int32:
+		writeHashNumber(h, strconv.FormatInt(int64(v), 10))
+		return
+	case int64:
+		writeHashNumber(h, strconv.FormatInt(v, 10))
+		return
+	case uint:
+		writeHashNumber(h, strconv.FormatUint(uint64(v), 10))
+		return
+	case uint8:
+		writeHashNumber(h, strconv.FormatUint(uint64(v), 10))
+		return
+	case uint16:
+		writeHashNumber(h, strconv.FormatUint(uint64(v), 10))
+		return
+	case uint32:
+		writeHashNumber(h, strconv.FormatUint(uint64(v), 10))
+		return
+	case uint64:
+		writeHashNumber(h, strconv.FormatUint(v, 10))
+		return
+	case float32:
+		writeHashFloat(h, float64(v))
+		return
+	case float64:
+		writeHashFloat(h, v)
+		return
+	case string:
+		writeHashText(h, v)
+		return
+	case time.Time:
+		writeHashText(h, v.Format(time.RFC3339Nano))
+		return
+	case time.Duration:
+		writeHashText(h, v.String())
+		return
+	case error:
+		writeHashText(h, v.Error())
+		return
+	default:
+		writeHashText(h, fmt.Sprint(v))
+		return
+	}
+
+	// groups are copied, as they are sorted in place
+	_, _ = h.Write([]byte{'g'})
+	attrs := make([]attr.Attr, 0, len(group))
+	for _, a := range group {
+		if a != nil {
+			attrs = append(attrs, a)
+		}
+	}
+	writeHashAttrs(h, attrs)
+}
+
+// writeHashFloat writes the float `v` as an integer if it has no fractional
+// part, so that it matches the integers with the same value
+func writeHashFloat(h hash.Hash64, v float64) {
+	if v == math.Trunc(v) && math.Abs(v) < 1<<63 {
+		writeHashNumber(h, strconv.FormatInt(int64(v), 10))
+		return
+	}
+	writeHashNumber(h, strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+func writeHashNumber(h hash.Hash64, s string) {
+	_, _ = h.Write([]byte{'n'})
+	writeHashString(h, s)
+}
+
+func writeHashText(h hash.Hash64, s string) {
+	_, _ = h.Write([]byte{'s'})
+	writeHashString(h, s)
+}
+
+// writeHashString writes the string `s` prefixed with its length, so that
+// adjacent strings cannot be confused
+func writeHashString(h hash.Hash64, s string) {
+	writeHashLen(h, len(s))
+	_, _ = h.Write([]byte(s))
+}
+
+func writeHashLen(h hash.Hash64, n int) {
+	var b [binary.MaxVarintLen64]byte
+	_, _ = h.Write(b[:binary.PutUvarint(b[:], uint64(n))])
+}
@@ -0,0 +1,107 @@
+package records
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestHash(t *testing.T) {
+	base := New(testTime, level.Warn, "payment failed",
+		attr.String("user", "gopher"),
+		attr.Int("attempt", 2),
+		attr.New("card", []attr.Attr{attr.String("brand", "visa"), attr.Float("amount", 10.0)}),
+		attr.String("request_id", "abc"),
+	)
+
+	for _, testcase := range []struct {
+		name  string
+		r     Record
+		opts  HashOptions
+		equal bool
+	}{
+		{
+			name: "IgnoresTime",
+			r: New(testTime.Add(time.Hour), level.Warn, "payment failed",
+				attr.String("user", "gopher"),
+				attr.Int("attempt", 2),
+				attr.New("card", []attr.Attr{attr.String("brand", "visa"), attr.Float("amount", 10.0)}),
+				attr.String("request_id", "abc"),
+			),
+			equal: true,
+		},
+		{
+			name: "AttrOrder",
+			r: New(testTime, level.Warn, "payment failed",
+				attr.String("request_id", "abc"),
+				attr.New("card", []attr.Attr{attr.Int("amount", 10), attr.String("brand", "visa")}),
+				attr.New("attempt", int64(2)),
+				attr.String("user", "gopher"),
+			),
+			equal: true,
+		},
+		{
+			name:  "Level",
+			r:     New(testTime, level.Error, "payment failed", base.Attrs()...),
+			equal: false,
+		},
+		{
+			name:  "Message",
+			r:     New(testTime, level.Warn, "payment declined", base.Attrs()...),
+			equal: false,
+		},
+		{
+			name: "Value",
+			r: New(testTime, level.Warn, "payment failed",
+				attr.String("user", "gopher"),
+				attr.String("attempt", "2"),
+				attr.New("card", []attr.Attr{attr.String("brand", "visa"), attr.Float("amount", 10.0)}),
+				attr.String("request_id", "abc"),
+			),
+			equal: false,
+		},
+		{
+			name:  "Exclude",
+			r:     New(testTime, level.Warn, "payment failed", append(base.Attrs()[:3:3], attr.String("request_id", "xyz"))...),
+			opts:  HashOptions{Exclude: []string{"request_id"}},
+			equal: true,
+		},
+		{
+			name:  "Keys",
+			r:     New(testTime, level.Warn, "payment failed", attr.String("user", "gopher"), attr.Int("attempt", 7)),
+			opts:  HashOptions{Keys: []string{"user"}},
+			equal: true,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			a, b := Hash(base, testcase.opts), Hash(testcase.r, testcase.opts)
+			if (a == b) != testcase.equal {
+				t.Errorf("output mismatch error: wanted equal=%v ; got %016x and %016x", testcase.equal, a, b)
+			}
+		})
+	}
+
+	t.Run("Decoded", func(t *testing.T) {
+		r, err := NewDecoder(strings.NewReader(
+			`{"timestamp":"2022-11-18T20:21:27Z","message":"payment failed","level":"warn",` +
+				`"data":{"user":"gopher","attempt":2,"card":{"brand":"visa","amount":10},"request_id":"abc"}}`,
+		)).Decode()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if a, b := Hash(base, HashOptions{}), Hash(r, HashOptions{}); a != b {
+			t.Errorf("output mismatch error: wanted %016x ; got %016x", a, b)
+		}
+	})
+	t.Run("AttrsUntouched", func(t *testing.T) {
+		r := New(testTime, level.Info, "msg", attr.String("b", "1"), attr.String("a", "2"))
+		_ = Hash(r, HashOptions{})
+		if r.Attrs()[0].Key() != "b" {
+			t.Errorf("output mismatch error: wanted %v ; got %v", "b", r.Attrs()[0].Key())
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package records
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+)
+
+// maxPooledAttrs is the attributes' capacity above which a Record is not
+// returned to the pool, to avoid retaining large slices
+const maxPooledAttrs = 64
+
+var pool = sync.Pool{
+	New: func() any {
+		return &record{
+			attrs: make([]attr.Attr, 0, 8),
+		}
+	},
+}
+
+// Acquire returns a Record from a shared pool, set with the input time.Time
+// `t`, level.Level `lv`, message string `msg` and attributes `attrs`, with
+// the same defaults as New.
+//
+// The Record must be returned to the pool with Release once it is handled.
+// Handlers that retain a pooled Record beyond their Handle call must retain
+// a copy of it, with its Clone method
+func Acquire(t time.Time, lv level.Level, msg string, attrs ...attr.Attr) Record {
+	if t.IsZero() || t == time.Unix(0, 0) {
+		t = time.Now()
+	}
+	if lv == nil {
+		lv = level.Info
+	}
+
+	r := pool.Get().(*record)
+	r.timestamp = t
+	r.message = msg
+	r.level = lv
+	for _, a := range attrs {
+		if a != nil {
+			r.attrs = append(r.attrs, a)
+		}
+	}
+	return r
+}
+
+// Release returns a Record created with Acquire to the shared pool. Records
+// that were not created with Acquire are ignored
+//
+// The Record must not be used after it is released
+func Release(r Record) {
+	rec, ok := r.(*record)
+	if !ok || rec == nil {
+		return
+	}
+	if cap(rec.attrs) > maxPooledAttrs {
+		return
+	}
+
+	for i := range rec.attrs {
+		rec.attrs[i] = nil
+	}
+	rec.attrs = rec.attrs[:0]
+	rec.message = ""
+	rec.level = nil
+	rec.timestamp = time.Time{}
+	rec.pc = 0
+	pool.Put(rec)
+}
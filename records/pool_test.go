@@ -0,0 +1,48 @@
+package records
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zalgonoise/attr"
+)
+
+func TestAcquire(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		r := Acquire(testTime, testLevel, testMsg, ta1, nil, ta2)
+		defer Release(r)
+
+		if !r.Time().Equal(testTime) || r.Level() != testLevel || r.Message() != testMsg {
+			t.Errorf("unexpected output error: got %v", r)
+		}
+		if wants := []attr.Attr{ta1, ta2}; !reflect.DeepEqual(wants, r.Attrs()) {
+			t.Errorf("unexpected output error: wanted %v ; got %v", wants, r.Attrs())
+		}
+	})
+	t.Run("CloneOutlivesRelease", func(t *testing.T) {
+		r := Acquire(testTime, testLevel, testMsg, ta1)
+		c := r.Clone()
+		Release(r)
+
+		if c.Message() != testMsg || c.AttrLen() != 1 || c.Attrs()[0] != ta1 {
+			t.Errorf("unexpected output error: got %v", c)
+		}
+	})
+	t.Run("AddAttrOutlivesRelease", func(t *testing.T) {
+		r := Acquire(testTime, testLevel, testMsg, ta1)
+		added := r.AddAttr(ta2)
+		Release(r)
+
+		next := Acquire(testTime, testLevel, testMsg, ta3, ta4)
+		defer Release(next)
+
+		if wants := []attr.Attr{ta1, ta2}; !reflect.DeepEqual(wants, added.Attrs()) {
+			t.Errorf("unexpected output error: wanted %v ; got %v", wants, added.Attrs())
+		}
+	})
+	t.Run("ReleaseNonPooled", func(t *testing.T) {
+		// must not panic
+		Release(New(testTime, testLevel, testMsg))
+		Release(nil)
+	})
+}
@@ -13,6 +13,7 @@ import (
 //   - `AddAttr()` will return a copy of this Record with the input Attr appended
 //     to the existing ones
 //   - `AttrLen()` will return the length of the attributes in the record
+//   - `Clone()` will return a deep copy of the record, safe to retain
 type Record interface {
 	// AddAttr returns a copy of this Record with the input Attr appended to the
 	// existing ones
@@ -27,6 +28,9 @@ type Record interface {
 	Time() time.Time
 	// Level returns the level.Level level associated to this Record
 	Level() level.Level
+	// Clone returns a deep, independent copy of this Record, including its
+	// attributes, which is safe to retain beyond the scope of a Handle call
+	Clone() Record
 }
 
 // New will return a Record based on the input time.Time `t`, level.Level `lv`,
@@ -64,12 +68,49 @@ type record struct {
 	message   string
 	level     level.Level
 	attrs     []attr.Attr
+	pc        uintptr
+}
+
+// PC returns the program counter of the call site that logged this Record, or
+// zero if it is not known
+func (r record) PC() uintptr {
+	return r.pc
+}
+
+// PC returns the program counter of the call site that logged the Record `r`
+// (see WithPC), or zero if it is not known
+func PC(r Record) uintptr {
+	if p, ok := r.(interface{ PC() uintptr }); ok {
+		return p.PC()
+	}
+	return 0
+}
+
+// WithPC returns a copy of the Record `r` carrying the program counter `pc` of
+// the call site that logged it, as returned by runtime.Callers. Handlers
+// resolve their source references from it, which keeps them accurate when the
+// Record is handled in another goroutine.
+//
+// Records created with Acquire are set in place, so that they can still be
+// released. Other implementations of Record are returned as-is
+func WithPC(r Record, pc uintptr) Record {
+	switch rec := r.(type) {
+	case record:
+		rec.pc = pc
+		return rec
+	case *record:
+		rec.pc = pc
+		return rec
+	default:
+		return r
+	}
 }
 
 // AddAttr returns a copy of this Record with the input Attr appended to the
 // existing ones
 func (r record) AddAttr(attrs ...attr.Attr) Record {
-	as := r.attrs
+	as := make([]attr.Attr, len(r.attrs), len(r.attrs)+len(attrs))
+	copy(as, r.attrs)
 	for _, a := range attrs {
 		if a != nil {
 			as = append(as, a)
@@ -80,6 +121,7 @@ func (r record) AddAttr(attrs ...attr.Attr) Record {
 		message:   r.message,
 		level:     r.level,
 		attrs:     as,
+		pc:        r.pc,
 	}
 }
 
@@ -107,3 +149,36 @@ func (r record) Time() time.Time {
 func (r record) Level() level.Level {
 	return r.level
 }
+
+// Clone returns a deep, independent copy of this Record, including its
+// attributes, which is safe to retain beyond the scope of a Handle call
+func (r record) Clone() Record {
+	return record{
+		timestamp: r.timestamp,
+		message:   r.message,
+		level:     r.level,
+		attrs:     cloneAttrs(r.attrs),
+		pc:        r.pc,
+	}
+}
+
+func cloneAttrs(attrs []attr.Attr) []attr.Attr {
+	as := make([]attr.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if a == nil {
+			continue
+		}
+		switch v := a.Value().(type) {
+		case []attr.Attr:
+			if c := a.WithValue(cloneAttrs(v)); c != nil {
+				a = c
+			}
+		case attr.Attrs:
+			if c := a.WithValue(attr.Attrs(cloneAttrs(v))); c != nil {
+				a = c
+			}
+		}
+		as = append(as, a)
+	}
+	return as
+}
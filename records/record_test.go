@@ -322,3 +322,73 @@ func TestLevel(t *testing.T) {
 		}
 	})
 }
+
+func TestRecordClone(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		r := New(testTime, testLevel, testMsg, testAttrs...)
+		c := r.Clone()
+
+		if !reflect.DeepEqual(r, c) {
+			t.Errorf("unexpected output error: wanted %v ; got %v", r, c)
+		}
+	})
+	t.Run("Independent", func(t *testing.T) {
+		r := New(testTime, testLevel, testMsg, ta1, ta2)
+		c := r.Clone()
+
+		r.Attrs()[0] = ta3
+
+		if c.Attrs()[0] != ta1 {
+			t.Errorf("unexpected output error: wanted %v ; got %v", ta1, c.Attrs()[0])
+		}
+	})
+	t.Run("Nested", func(t *testing.T) {
+		nested := []attr.Attr{ta1, ta2}
+		r := New(testTime, testLevel, testMsg, attr.New("group", nested))
+		c := r.Clone()
+
+		nested[0] = ta3
+
+		group, ok := c.Attrs()[0].Value().([]attr.Attr)
+		if !ok {
+			t.Errorf("unexpected value type: %T", c.Attrs()[0].Value())
+			return
+		}
+		if group[0] != ta1 {
+			t.Errorf("unexpected output error: wanted %v ; got %v", ta1, group[0])
+		}
+	})
+}
+
+func TestWithPC(t *testing.T) {
+	const pc uintptr = 42
+
+	t.Run("Record", func(t *testing.T) {
+		r := WithPC(New(testTime, testLevel, testMsg), pc)
+
+		if got := PC(r); got != pc {
+			t.Errorf("unexpected output error: wanted %v ; got %v", pc, got)
+		}
+		if got := PC(r.AddAttr(ta1)); got != pc {
+			t.Errorf("unexpected output error: wanted %v ; got %v", pc, got)
+		}
+		if got := PC(r.Clone()); got != pc {
+			t.Errorf("unexpected output error: wanted %v ; got %v", pc, got)
+		}
+	})
+	t.Run("Pooled", func(t *testing.T) {
+		r := Acquire(testTime, testLevel, testMsg)
+		if WithPC(r, pc) != r {
+			t.Errorf("expected the pooled record to be set in place")
+		}
+		if got := PC(r); got != pc {
+			t.Errorf("unexpected output error: wanted %v ; got %v", pc, got)
+		}
+		Release(r)
+	})
+	t.Run("Unknown", func(t *testing.T) {
+		if got := PC(New(testTime, testLevel, testMsg)); got != 0 {
+			t.Errorf("unexpected output error: wanted %v ; got %v", 0, got)
+		}
+	})
+}
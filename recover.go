@@ -0,0 +1,82 @@
+package logx
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+)
+
+const panicMessage = "panic recovered"
+
+// RecoverOption describes a setting applied to Recover and Go
+type RecoverOption func(*recoverConfig)
+
+type recoverConfig struct {
+	lv      level.Level
+	repanic bool
+}
+
+// WithPanicLevel sets the level that recovered panics are logged with, which
+// defaults to level.Error
+func WithPanicLevel(lv level.Level) RecoverOption {
+	return func(c *recoverConfig) {
+		c.lv = lv
+	}
+}
+
+// WithRepanic sets whether the recovered panics are raised again after being
+// logged, so that the process still crashes but leaves a structured record
+// behind
+func WithRepanic(repanic bool) RecoverOption {
+	return func(c *recoverConfig) {
+		c.repanic = repanic
+	}
+}
+
+// Recover recovers a panic, logging its value and stack trace with the Logger
+// `logger` (or the standard Logger, if nil). It must be deferred directly:
+//
+//	defer logx.Recover(logger)
+func Recover(logger Logger, opts ...RecoverOption) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	c := &recoverConfig{lv: level.Error}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	if c.lv == nil {
+		c.lv = level.Error
+	}
+	if logger == nil {
+		logger = std
+	}
+
+	attrs := []attr.Attr{
+		attr.String("panic", fmt.Sprint(v)),
+		attr.String("stack", string(debug.Stack())),
+	}
+	if err, ok := v.(error); ok {
+		attrs = append(attrs, attr.String("error", err.Error()))
+	}
+	logger.Log(c.lv, panicMessage, attrs...)
+
+	if c.repanic {
+		panic(v)
+	}
+}
+
+// Go runs the function `fn` in a new goroutine, recovering and logging its
+// panics with Recover
+func Go(logger Logger, fn func(), opts ...RecoverOption) {
+	go func() {
+		defer Recover(logger, opts...)
+		fn()
+	}()
+}
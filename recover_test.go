@@ -0,0 +1,95 @@
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/level"
+)
+
+type panicEntry struct {
+	Message string `json:"message"`
+	Level   string `json:"level"`
+	Data    struct {
+		Panic string `json:"panic"`
+		Stack string `json:"stack"`
+		Error string `json:"error"`
+	} `json:"data"`
+}
+
+func decodePanic(t *testing.T, b *bytes.Buffer) panicEntry {
+	var e panicEntry
+	if err := json.Unmarshal(b.Bytes(), &e); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	return e
+}
+
+func TestRecover(t *testing.T) {
+	t.Run("Swallow", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		func() {
+			defer Recover(New(WithHandler(jsonh.New(b))))
+			panic("boom")
+		}()
+
+		e := decodePanic(t, b)
+		if e.Message != panicMessage || e.Level != "error" || e.Data.Panic != "boom" {
+			t.Errorf("output mismatch error: wanted the recovered panic ; got %v", e)
+		}
+		if !strings.Contains(e.Data.Stack, "recover_test.go") {
+			t.Errorf("output mismatch error: wanted the panic's stack ; got %s", e.Data.Stack)
+		}
+	})
+	t.Run("Repanic", func(t *testing.T) {
+		var (
+			b   = &bytes.Buffer{}
+			err = errors.New("boom")
+			got any
+		)
+		func() {
+			defer func() { got = recover() }()
+			defer Recover(New(WithHandler(jsonh.New(b))), WithRepanic(true), WithPanicLevel(level.Fatal))
+			panic(err)
+		}()
+
+		if got != err {
+			t.Errorf("output mismatch error: wanted %v ; got %v", err, got)
+		}
+		if e := decodePanic(t, b); e.Level != "fatal" || e.Data.Error != "boom" {
+			t.Errorf("output mismatch error: wanted a fatal record with the error ; got %v", e)
+		}
+	})
+	t.Run("NoPanic", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		func() {
+			defer Recover(New(WithHandler(jsonh.New(b))))
+		}()
+
+		if b.Len() != 0 {
+			t.Errorf("output mismatch error: wanted no output ; got %s", b.String())
+		}
+	})
+}
+
+type chanWriter chan []byte
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- append([]byte(nil), p...)
+	return len(p), nil
+}
+
+func TestGo(t *testing.T) {
+	w := make(chanWriter, 1)
+	Go(New(WithHandler(jsonh.New(w))), func() {
+		panic("in goroutine")
+	})
+
+	if e := decodePanic(t, bytes.NewBuffer(<-w)); e.Data.Panic != "in goroutine" {
+		t.Errorf("output mismatch error: wanted the goroutine's panic ; got %v", e)
+	}
+}
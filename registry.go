@@ -0,0 +1,273 @@
+package logx
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+	"github.com/zalgonoise/logx/records"
+)
+
+// Registry keeps the level overrides for named loggers, allowing the
+// verbosity of a single subsystem to be changed at runtime.
+//
+// Overrides are set for patterns, which are either an exact logger name
+// (e.g. "http.server"), a dot-separated prefix followed by a wildcard (e.g.
+// "http.*", matching "http.server" and "http.client.tls"), or a single
+// wildcard ("*") matching all names. When several patterns match a name,
+// an exact match takes precedence, followed by the longest prefix
+type Registry struct {
+	mu      sync.Mutex
+	rules   map[string]level.Level
+	modules map[string]*module
+}
+
+type module struct {
+	l atomic.Pointer[level.Level]
+}
+
+var registry = NewRegistry()
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		rules:   map[string]level.Level{},
+		modules: map[string]*module{},
+	}
+}
+
+// DefaultRegistry returns the Registry used by this library's Named function
+func DefaultRegistry() *Registry {
+	return registry
+}
+
+// Named returns a copy of this library's standard Logger, whose verbosity is
+// driven by the level overrides for `name` in the default Registry
+func Named(name string) Logger {
+	return registry.Named(name, std.Handler())
+}
+
+// SetLevel sets the level override for the names matching `pattern` in
+// the default Registry
+func SetLevel(pattern string, lv level.Level) {
+	registry.SetLevel(pattern, lv)
+}
+
+// Named returns a Logger for the Handler `h`, whose verbosity is driven by
+// the level overrides for `name` in this Registry. Its records carry the name
+// in a handlers.LoggerKey attribute, so that handlers can be set to only
+// accept the records of some loggers (see handlers.IncludeLoggers)
+//
+// While an override is set for `name`, it sets the effective level of the
+// Logger, replacing the level of the Handler `h`: an override can raise the
+// verbosity above it (like to Debug, for a Handler set to Info), as well as
+// lower it. Without an override, the Handler's level applies.
+//
+// The Loggers spawned from it with their Named method are also driven by this
+// Registry, for their full names
+func (r *Registry) Named(name string, h handlers.Handler) Logger {
+	l := New(WithHandler(h)).(*logger)
+	l.module = r.module(name)
+	l.registry = r
+	l.name = name
+	l.h = newModuleHandler(l.h, l.module)
+	return l
+}
+
+// Leveler returns a level.Leveler for the logger name `name`, which reflects
+// the changes to its level overrides in this Registry. Its Level method
+// returns nil if there is no override for `name`
+//
+// It can be used to drive the filtering of a Handler, with its WithLevel method
+func (r *Registry) Leveler(name string) level.Leveler {
+	return r.module(name)
+}
+
+// SetLevel sets the level override for the names matching `pattern`. If
+// `lv` is nil, the override for `pattern` is removed
+func (r *Registry) SetLevel(pattern string, lv level.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if lv == nil {
+		delete(r.rules, pattern)
+	} else {
+		r.rules[pattern] = lv
+	}
+
+	for name, m := range r.modules {
+		m.set(r.resolve(name))
+	}
+}
+
+// Level returns the effective level override for the logger name `name`,
+// or nil if there is none
+func (r *Registry) Level(name string) level.Level {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.resolve(name)
+}
+
+// Levels returns a copy of the level overrides in this Registry, keyed by
+// their pattern
+func (r *Registry) Levels() map[string]level.Level {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	levels := make(map[string]level.Level, len(r.rules))
+	for pattern, lv := range r.rules {
+		levels[pattern] = lv
+	}
+	return levels
+}
+
+func (r *Registry) module(name string) *module {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if m, ok := r.modules[name]; ok {
+		return m
+	}
+
+	m := &module{}
+	m.set(r.resolve(name))
+	r.modules[name] = m
+	return m
+}
+
+func (r *Registry) resolve(name string) level.Level {
+	var (
+		lv    level.Level
+		score = -1
+	)
+	for pattern, l := range r.rules {
+		if s := match(pattern, name); s > score {
+			lv, score = l, s
+		}
+	}
+	return lv
+}
+
+// match returns the specificity score of `pattern` for the logger name
+// `name`, or -1 if it does not match
+func match(pattern, name string) int {
+	switch {
+	case pattern == name:
+		return len(pattern) + 1
+	case pattern == "*":
+		return 0
+	case strings.HasSuffix(pattern, ".*"):
+		prefix := pattern[:len(pattern)-1]
+		if strings.HasPrefix(name, prefix) {
+			return len(prefix)
+		}
+	}
+	return -1
+}
+
+func (m *module) set(lv level.Level) {
+	if lv == nil {
+		m.l.Store(nil)
+		return
+	}
+	m.l.Store(&lv)
+}
+
+// Level returns the level override for this module, or nil if unset
+func (m *module) Level() level.Level {
+	ref := m.l.Load()
+	if ref == nil {
+		return nil
+	}
+	return *ref
+}
+
+func (m *module) enabled(lv level.Level) bool {
+	if m == nil {
+		return true
+	}
+	ref := m.Level()
+	return ref == nil || lv.Int() >= ref.Int()
+}
+
+// moduleHandler is the Handler of the Loggers from a Registry: while their
+// module has a level override, the records are handled by `override`, which
+// is the `base` Handler with its level set by the module; otherwise, by the
+// `base` Handler as configured
+type moduleHandler struct {
+	base     handlers.Handler
+	override handlers.Handler
+	m        *module
+}
+
+func newModuleHandler(h handlers.Handler, m *module) handlers.Handler {
+	if h == nil {
+		return nil
+	}
+	if mh, ok := h.(moduleHandler); ok {
+		h = mh.base
+	}
+	return moduleHandler{
+		base:     h,
+		override: h.WithLevel(m),
+		m:        m,
+	}
+}
+
+func (h moduleHandler) handler() handlers.Handler {
+	if h.m.Level() != nil {
+		return h.override
+	}
+	return h.base
+}
+
+// Enabled returns a boolean on whether the Handler is accepting
+// records with log level `level`
+func (h moduleHandler) Enabled(level level.Level) bool {
+	return h.handler().Enabled(level)
+}
+
+// Handle will process the input Record, returning an error if raised
+func (h moduleHandler) Handle(r records.Record) error {
+	return h.handler().Handle(r)
+}
+
+// Ping implements handlers.Pinger, checking the health of the Handler
+func (h moduleHandler) Ping(ctx context.Context) error {
+	return handlers.Ping(ctx, h.base)
+}
+
+// Shutdown implements handlers.Shutdowner, shutting down the Handler
+func (h moduleHandler) Shutdown(ctx context.Context) error {
+	return handlers.Shutdown(ctx, h.base)
+}
+
+// With will spawn a copy of this Handler with the input attributes
+// `attrs`
+func (h moduleHandler) With(attrs ...attr.Attr) handlers.Handler {
+	return newModuleHandler(h.base.With(attrs...), h.m)
+}
+
+// WithSource will spawn a new copy of this Handler with the setting
+// to add a source file+line reference to `addSource` boolean
+func (h moduleHandler) WithSource(addSource bool) handlers.Handler {
+	return newModuleHandler(h.base.WithSource(addSource), h.m)
+}
+
+// WithLevel will spawn a copy of this Handler with the input Leveler `level`
+// as a verbosity filter, which is no longer driven by the module's level
+// overrides
+func (h moduleHandler) WithLevel(level level.Leveler) handlers.Handler {
+	return h.base.WithLevel(level)
+}
+
+// WithReplaceFn will spawn a copy of this Handler with the input attribute
+// replace function `fn`
+func (h moduleHandler) WithReplaceFn(fn func(a attr.Attr) attr.Attr) handlers.Handler {
+	return newModuleHandler(h.base.WithReplaceFn(fn), h.m)
+}
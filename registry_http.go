@@ -0,0 +1,80 @@
+//go:build !logx_tiny
+
+package logx
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/zalgonoise/logx/level"
+)
+
+type registryLevels struct {
+	Levels map[string]string `json:"levels"`
+}
+
+type registryLevel struct {
+	Logger string `json:"logger"`
+	Level  string `json:"level"`
+}
+
+type httpError struct {
+	Error string `json:"error"`
+}
+
+// ServeHTTP implements http.Handler, allowing the Registry's level overrides
+// to be reported and changed at runtime:
+//   - a GET request returns all overrides as `{"levels":{"http.*":"debug"}}`
+//   - a GET request with a `logger` query parameter returns the effective level
+//     for that name as `{"logger":"http.server","level":"debug"}`
+//   - a PUT request with a body like `{"logger":"http.*","level":"debug"}`
+//     sets the override for that pattern; an empty level removes it
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		if name := req.URL.Query().Get("logger"); name != "" {
+			out := registryLevel{Logger: name}
+			if lv := r.Level(name); lv != nil {
+				out.Level = lv.String()
+			}
+			writeJSON(w, http.StatusOK, out)
+			return
+		}
+
+		out := registryLevels{Levels: map[string]string{}}
+		for pattern, lv := range r.Levels() {
+			out.Levels[pattern] = lv.String()
+		}
+		writeJSON(w, http.StatusOK, out)
+	case http.MethodPut:
+		var in registryLevel
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			writeJSON(w, http.StatusBadRequest, httpError{Error: err.Error()})
+			return
+		}
+		if in.Logger == "" {
+			writeJSON(w, http.StatusBadRequest, httpError{Error: "logger pattern cannot be empty"})
+			return
+		}
+
+		var lv level.Level
+		if in.Level != "" {
+			var err error
+			if lv, err = level.Parse(in.Level); err != nil {
+				writeJSON(w, http.StatusBadRequest, httpError{Error: err.Error()})
+				return
+			}
+		}
+		r.SetLevel(in.Logger, lv)
+		writeJSON(w, http.StatusOK, in)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeJSON(w, http.StatusMethodNotAllowed, httpError{Error: "method not allowed"})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,64 @@
+//go:build !logx_tiny
+
+package logx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestRegistryServeHTTP(t *testing.T) {
+	t.Run("GetAll", func(t *testing.T) {
+		r := NewRegistry()
+		r.SetLevel("http.*", level.Debug)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/levels", nil))
+
+		if wants := `{"levels":{"http.*":"debug"}}`; strings.TrimSpace(rec.Body.String()) != wants {
+			t.Errorf("unexpected output error: wanted %s ; got %s", wants, rec.Body.String())
+		}
+	})
+	t.Run("GetLogger", func(t *testing.T) {
+		r := NewRegistry()
+		r.SetLevel("http.*", level.Debug)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/levels?logger=http.server", nil))
+
+		if wants := `{"logger":"http.server","level":"debug"}`; strings.TrimSpace(rec.Body.String()) != wants {
+			t.Errorf("unexpected output error: wanted %s ; got %s", wants, rec.Body.String())
+		}
+	})
+	t.Run("PutAndRemove", func(t *testing.T) {
+		r := NewRegistry()
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/levels", strings.NewReader(`{"logger":"db","level":"WARN"}`)))
+		if rec.Code != http.StatusOK || r.Level("db") != level.Warn {
+			t.Errorf("unexpected result: status %v ; level %v", rec.Code, r.Level("db"))
+		}
+
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/levels", strings.NewReader(`{"logger":"db","level":""}`)))
+		if rec.Code != http.StatusOK || r.Level("db") != nil {
+			t.Errorf("unexpected result: status %v ; level %v", rec.Code, r.Level("db"))
+		}
+	})
+	t.Run("PutInvalid", func(t *testing.T) {
+		r := NewRegistry()
+
+		for _, body := range []string{`{"logger":"db","level":"verbose"}`, `{"level":"debug"}`, `not json`} {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/levels", strings.NewReader(body)))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("unexpected status code for %s: wanted %v ; got %v", body, http.StatusBadRequest, rec.Code)
+			}
+		}
+	})
+}
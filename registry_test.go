@@ -0,0 +1,144 @@
+package logx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/texth"
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestRegistryLevel(t *testing.T) {
+	r := NewRegistry()
+	r.SetLevel("*", level.Warn)
+	r.SetLevel("http.*", level.Info)
+	r.SetLevel("http.server", level.Debug)
+
+	tc := []struct {
+		name  string
+		wants level.Level
+	}{
+		{
+			name:  "db",
+			wants: level.Warn,
+		}, {
+			name:  "http.client",
+			wants: level.Info,
+		}, {
+			name:  "http.client.tls",
+			wants: level.Info,
+		}, {
+			name:  "http.server",
+			wants: level.Debug,
+		}, {
+			name:  "http",
+			wants: level.Warn,
+		},
+	}
+
+	for _, tt := range tc {
+		if out := r.Level(tt.name); out != tt.wants {
+			t.Errorf("unexpected output error for %s: wanted %v ; got %v", tt.name, tt.wants, out)
+		}
+	}
+}
+
+func TestRegistryNamed(t *testing.T) {
+	t.Run("NoOverride", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		r := NewRegistry()
+		l := r.Named("http.server", texth.New(b))
+
+		l.Trace("test message")
+
+		if !strings.Contains(b.String(), "test message") {
+			t.Errorf("expected record to be written ; got %q", b.String())
+		}
+	})
+	t.Run("RuntimeOverride", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		r := NewRegistry()
+		r.SetLevel("*", level.Info)
+		l := r.Named("http.server", texth.New(b)).With()
+
+		l.Debug("hidden")
+		if b.Len() != 0 {
+			t.Errorf("expected record to be filtered ; got %q", b.String())
+		}
+		if l.Enabled(level.Debug) {
+			t.Errorf("expected logger not to be enabled for debug")
+		}
+
+		r.SetLevel("http.*", level.Debug)
+		l.Debug("visible")
+		if !strings.Contains(b.String(), "visible") {
+			t.Errorf("expected record to be written ; got %q", b.String())
+		}
+
+		r.SetLevel("http.*", nil)
+		b.Reset()
+		l.Log(level.Debug, "hidden")
+		if b.Len() != 0 {
+			t.Errorf("expected record to be filtered ; got %q", b.String())
+		}
+	})
+	t.Run("AboveHandlerLevel", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		r := NewRegistry()
+		l := r.Named("db", texth.New(b).WithLevel(level.Info)).Named("pool")
+
+		l.Debug("hidden")
+		if b.Len() != 0 {
+			t.Errorf("expected record to be filtered ; got %q", b.String())
+		}
+
+		r.SetLevel("db.*", level.Debug)
+		l.With().Debug("visible")
+		if !strings.Contains(b.String(), "visible") {
+			t.Errorf("expected record to be written ; got %q", b.String())
+		}
+
+		r.SetLevel("db.*", nil)
+		b.Reset()
+		l.Debug("hidden")
+		if b.Len() != 0 {
+			t.Errorf("expected record to be filtered ; got %q", b.String())
+		}
+	})
+}
+
+func TestRegistryLoggerName(t *testing.T) {
+	store := handlers.NewStore(4)
+	l := NewRegistry().Named("db.pool", handlers.IncludeLoggers(store, "db.*"))
+
+	l.Info("query")
+	New(WithHandler(handlers.IncludeLoggers(store, "db.*"))).Info("dropped")
+
+	if store.Len() != 1 {
+		t.Errorf("output mismatch error: wanted 1 record ; got %d", store.Len())
+		return
+	}
+	if !handlers.HasAttr(handlers.LoggerKey, "db.pool")(store.Records()[0]) {
+		t.Errorf("expected record to carry the logger name ; got %v", store.Records()[0].Attrs())
+	}
+}
+
+func TestRegistryLeveler(t *testing.T) {
+	b := &bytes.Buffer{}
+	r := NewRegistry()
+	l := New(WithHandler(texth.New(b)), WithLevel(r.Leveler("db")))
+
+	l.Debug("visible")
+	if !strings.Contains(b.String(), "visible") {
+		t.Errorf("expected record to be written ; got %q", b.String())
+	}
+
+	r.SetLevel("db", level.Warn)
+	b.Reset()
+	l.Info("hidden")
+	if b.Len() != 0 {
+		t.Errorf("expected record to be filtered ; got %q", b.String())
+	}
+}
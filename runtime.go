@@ -0,0 +1,92 @@
+package logx
+
+import (
+	"context"
+	"runtime"
+	"runtime/metrics"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+)
+
+const (
+	runtimeMessage = "runtime stats"
+	cpuMetric      = "/cpu/classes/total:cpu-seconds"
+
+	defaultRuntimeInterval = time.Minute
+)
+
+// ReportRuntime logs the Go runtime's stats with the Logger `logger` (or the
+// standard Logger, if nil) and level `lv` (Info, if nil), every `interval`
+// (one minute, if not greater than zero), until the Context `ctx` is done.
+// It blocks, so it is meant to run in its own goroutine:
+//
+//	go logx.ReportRuntime(ctx, logger, 30*time.Second, level.Debug)
+//
+// Each record carries the number of goroutines, the heap and total memory
+// in use, the number of GC cycles and the duration of the last pause, and the
+// CPU time used since the previous record
+func ReportRuntime(ctx context.Context, logger Logger, interval time.Duration, lv level.Level) {
+	if logger == nil {
+		logger = std
+	}
+	if lv == nil {
+		lv = level.Info
+	}
+	if interval <= 0 {
+		interval = defaultRuntimeInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prevCPU := cpuSeconds()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !logger.Enabled(lv) {
+				continue
+			}
+
+			cpu := cpuSeconds()
+			logger.Log(lv, runtimeMessage, runtimeAttrs(cpu-prevCPU)...)
+			prevCPU = cpu
+		}
+	}
+}
+
+func runtimeAttrs(cpu float64) []attr.Attr {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	var lastPause time.Duration
+	if m.NumGC > 0 {
+		lastPause = time.Duration(m.PauseNs[(m.NumGC+255)%256])
+	}
+
+	return []attr.Attr{
+		attr.Int("goroutines", runtime.NumGoroutine()),
+		attr.Uint("heap_alloc", m.HeapAlloc),
+		attr.Uint("heap_objects", m.HeapObjects),
+		attr.Uint("sys", m.Sys),
+		attr.Uint("num_gc", m.NumGC),
+		attr.New("gc_pause", lastPause),
+		attr.New("gc_pause_total", time.Duration(m.PauseTotalNs)),
+		attr.Float("cpu_seconds", cpu),
+		attr.Int("gomaxprocs", runtime.GOMAXPROCS(0)),
+	}
+}
+
+// cpuSeconds returns the CPU time used by the process so far, as estimated
+// by the runtime
+func cpuSeconds() float64 {
+	sample := []metrics.Sample{{Name: cpuMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindFloat64 {
+		return 0
+	}
+	return sample[0].Value.Float64()
+}
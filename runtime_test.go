@@ -0,0 +1,65 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestReportRuntime(t *testing.T) {
+	t.Run("Reports", func(t *testing.T) {
+		var (
+			w           = make(chanWriter, 1)
+			ctx, cancel = context.WithCancel(context.Background())
+			done        = make(chan struct{})
+		)
+		go func() {
+			defer close(done)
+			ReportRuntime(ctx, New(WithHandler(jsonh.New(w))), time.Millisecond, level.Debug)
+		}()
+
+		out := <-w
+		cancel()
+		// drain any record written before cancellation
+		go func() {
+			for range w {
+			}
+		}()
+		<-done
+		close(w)
+
+		var e struct {
+			Message string         `json:"message"`
+			Level   string         `json:"level"`
+			Data    map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal(out, &e); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if e.Message != runtimeMessage || e.Level != "debug" {
+			t.Errorf("output mismatch error: wanted a debug runtime record ; got %v", e)
+		}
+		for _, key := range []string{"goroutines", "heap_alloc", "num_gc", "gc_pause", "cpu_seconds", "gomaxprocs"} {
+			if _, ok := e.Data[key]; !ok {
+				t.Errorf("output mismatch error: wanted a %s attribute ; got %v", key, e.Data)
+			}
+		}
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		ReportRuntime(ctx, New(WithHandler(jsonh.New(b)), WithLevel(level.Warn)), time.Millisecond, level.Info)
+
+		if b.Len() != 0 {
+			t.Errorf("output mismatch error: wanted no output ; got %s", b.String())
+		}
+	})
+}
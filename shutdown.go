@@ -0,0 +1,59 @@
+package logx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/zalgonoise/logx/handlers"
+)
+
+var shutdownTargets struct {
+	mu      sync.Mutex
+	targets []any
+}
+
+// RegisterShutdown adds the Handler, writer or io.Closer `v` to the set of
+// resources released by Shutdown, besides the standard Logger's Handler.
+//
+// Values implementing handlers.Shutdowner are shut down; otherwise, values
+// implementing io.Closer are closed
+func RegisterShutdown(v any) {
+	if v == nil {
+		return
+	}
+
+	shutdownTargets.mu.Lock()
+	defer shutdownTargets.mu.Unlock()
+
+	shutdownTargets.targets = append(shutdownTargets.targets, v)
+}
+
+// Shutdown flushes and releases the resources held by the standard Logger's
+// Handler tree and by the values registered with RegisterShutdown, like async
+// queues, write buffers and open files. It is meant to be called once, when
+// the application is exiting.
+//
+// Shutdown returns once all of them are released, or when the context `ctx` is
+// done, in which case its error is returned
+func Shutdown(ctx context.Context) error {
+	shutdownTargets.mu.Lock()
+	targets := append([]any{std.Handler()}, shutdownTargets.targets...)
+	shutdownTargets.mu.Unlock()
+
+	var errs []error
+	for _, t := range targets {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		switch v := t.(type) {
+		case handlers.Shutdowner:
+			errs = append(errs, v.Shutdown(ctx))
+		case io.Closer:
+			errs = append(errs, v.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,73 @@
+package logx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+)
+
+type testCloser struct {
+	closed bool
+}
+
+func (c *testCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestShutdown(t *testing.T) {
+	t.Run("HandlerTree", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.log")
+		w, err := handlers.Rotate(path, 1<<20, 0)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		prev := std
+		defer SetDefault(prev)
+
+		a := handlers.NewAsync(jsonh.New(handlers.Batch(w, 0, 0)), 16, handlers.Block, 0)
+		SetDefault(New(WithHandler(a)))
+
+		c := &testCloser{}
+		RegisterShutdown(c)
+		defer func() { shutdownTargets.targets = nil }()
+
+		Info("test message")
+
+		if err := Shutdown(context.Background()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(b) == 0 {
+			t.Errorf("expected the queued record to be written to the file")
+		}
+		if _, err := w.Write([]byte("late")); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("unexpected error: wanted %v ; got %v", os.ErrClosed, err)
+		}
+		if !c.closed {
+			t.Errorf("expected the registered closer to be closed")
+		}
+	})
+	t.Run("ContextDone", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+
+		if err := Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error: wanted %v ; got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package logx
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+)
+
+type slogAttrs struct {
+	depth int
+	attrs []attr.Attr
+}
+
+type slogHandler struct {
+	l      Logger
+	groups []string
+	attrs  []slogAttrs
+}
+
+// NewSlogHandler returns a slog.Handler that writes the records logged with a
+// slog.Logger to the Logger `logger` (or the standard Logger, if nil), so
+// that libraries using slog share the application's handlers.
+//
+// The slog levels map to the logx levels with the same value (Info is Info,
+// and `slog.LevelDebug-4` is Trace). Groups are converted into attribute
+// groups
+func NewSlogHandler(logger Logger) slog.Handler {
+	if logger == nil {
+		logger = std
+	}
+	return slogHandler{l: logger}
+}
+
+// slogLevel converts the slog.Level `lv` into a Level
+func slogLevel(lv slog.Level) level.Level {
+	return level.Info.Offset(int(lv))
+}
+
+// Enabled implements slog.Handler
+func (h slogHandler) Enabled(_ context.Context, lv slog.Level) bool {
+	return h.l.Enabled(slogLevel(lv))
+}
+
+// Handle implements slog.Handler, logging the slog.Record `r` with the Logger
+func (h slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]attr.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendSlogAttr(attrs, a)
+		return true
+	})
+
+	// the slog.Logger's call site is kept, if known
+	if l, ok := h.l.(*logger); ok && r.PC != 0 {
+		l.logPC(ctx, r.PC, slogLevel(r.Level), r.Message, h.nest(0, attrs))
+		return nil
+	}
+	h.l.LogContext(ctx, slogLevel(r.Level), r.Message, h.nest(0, attrs)...)
+	return nil
+}
+
+// nest returns the attributes set at the group depth `depth` and deeper,
+// ending with the record's attributes `attrs` in the innermost group
+func (h slogHandler) nest(depth int, attrs []attr.Attr) []attr.Attr {
+	var out []attr.Attr
+	for _, set := range h.attrs {
+		if set.depth == depth {
+			out = append(out, set.attrs...)
+		}
+	}
+
+	if depth == len(h.groups) {
+		return append(out, attrs...)
+	}
+	if inner := h.nest(depth+1, attrs); len(inner) > 0 {
+		out = append(out, attr.New(h.groups[depth], inner))
+	}
+	return out
+}
+
+// WithAttrs implements slog.Handler
+func (h slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	converted := make([]attr.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		converted = appendSlogAttr(converted, a)
+	}
+	if len(converted) == 0 {
+		return h
+	}
+
+	sets := make([]slogAttrs, 0, len(h.attrs)+1)
+	h.attrs = append(append(sets, h.attrs...), slogAttrs{depth: len(h.groups), attrs: converted})
+	return h
+}
+
+// WithGroup implements slog.Handler
+func (h slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	groups := make([]string, 0, len(h.groups)+1)
+	h.groups = append(append(groups, h.groups...), name)
+	return h
+}
+
+// appendSlogAttr appends the slog.Attr `a` to `dst`, converted into an
+// attribute. Empty attributes are skipped, and groups with no key are inlined,
+// as slog does
+func appendSlogAttr(dst []attr.Attr, a slog.Attr) []attr.Attr {
+	v := a.Value.Resolve()
+
+	if v.Kind() == slog.KindGroup {
+		var attrs []attr.Attr
+		for _, ga := range v.Group() {
+			attrs = appendSlogAttr(attrs, ga)
+		}
+		if len(attrs) == 0 {
+			return dst
+		}
+		if a.Key == "" {
+			return append(dst, attrs...)
+		}
+		return append(dst, attr.New(a.Key, attrs))
+	}
+
+	if a.Key == "" {
+		return dst
+	}
+
+	switch v.Kind() {
+	case slog.KindString:
+		return append(dst, attr.String(a.Key, v.String()))
+	case slog.KindInt64:
+		return append(dst, attr.Int(a.Key, v.Int64()))
+	case slog.KindUint64:
+		return append(dst, attr.Uint(a.Key, v.Uint64()))
+	case slog.KindFloat64:
+		return append(dst, attr.Float(a.Key, v.Float64()))
+	case slog.KindBool:
+		return append(dst, attr.New(a.Key, v.Bool()))
+	case slog.KindDuration:
+		return append(dst, attr.New(a.Key, v.Duration()))
+	case slog.KindTime:
+		return append(dst, attr.New(a.Key, v.Time()))
+	default:
+		return append(dst, attr.New(a.Key, v.Any()))
+	}
+}
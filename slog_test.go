@@ -0,0 +1,49 @@
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestSlogHandler(t *testing.T) {
+	b := &bytes.Buffer{}
+	logger := slog.New(NewSlogHandler(New(WithHandler(jsonh.New(b)), WithLevel(level.Info))))
+
+	logger.Debug("hidden")
+	logger.With("service", "api").WithGroup("req").With("id", 7).Warn("slow request",
+		"path", "/items",
+		slog.Group("", slog.Bool("cached", false)),
+		slog.Group("empty"),
+	)
+
+	var entry struct {
+		Message string         `json:"message"`
+		Level   string         `json:"level"`
+		Data    map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &entry); err != nil {
+		t.Errorf("unexpected error: %v ; output: %s", err, b.String())
+		return
+	}
+
+	if entry.Message != "slow request" || entry.Level != "warn" {
+		t.Errorf("output mismatch error: wanted %s at warn level ; got %s at %s level", "slow request", entry.Message, entry.Level)
+	}
+	wants := map[string]any{
+		"service": "api",
+		"req": map[string]any{
+			"id":     float64(7),
+			"path":   "/items",
+			"cached": false,
+		},
+	}
+	if !reflect.DeepEqual(entry.Data, wants) {
+		t.Errorf("output mismatch error: wanted %v ; got %v", wants, entry.Data)
+	}
+}
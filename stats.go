@@ -0,0 +1,171 @@
+package logx
+
+import (
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/internal/stats"
+	"github.com/zalgonoise/logx/level"
+)
+
+// Stats is a snapshot of the process-wide logx counters, as returned by
+// ReadStats
+type Stats struct {
+	// Records is the number of records handed to a Handler by the Loggers,
+	// per level, excluding the ones rejected by the Handler's Enabled method.
+	// Custom levels count as the closest built-in level below them
+	Records map[string]uint64 `json:"records"`
+	// Dropped is the number of records discarded by Async handlers due to a
+	// full queue, by open Breakers, or by Quotas
+	Dropped uint64 `json:"dropped"`
+	// Errors is the number of errors raised by the Loggers' Handlers
+	Errors uint64 `json:"errors"`
+	// QueueDepth is the number of records currently waiting in Async
+	// handlers' queues
+	QueueDepth int64 `json:"queue_depth"`
+	// Volume is the log volume over the last window, if tracked (see
+	// TrackVolume)
+	Volume *VolumeStats `json:"volume,omitempty"`
+}
+
+// Volume holds a number of records and their approximate size in bytes, as
+// the length of their message and of their attributes' keys and values (before
+// being encoded by a Handler)
+type Volume struct {
+	Records uint64 `json:"records"`
+	Bytes   uint64 `json:"bytes"`
+}
+
+// SizeCount is a bucket in a histogram of record sizes, holding the number of
+// records with up to UpTo bytes (and over the previous bucket's bound). The
+// last bucket has no bound
+type SizeCount struct {
+	UpTo    int    `json:"up_to,omitempty"`
+	Records uint64 `json:"records"`
+}
+
+// SiteVolume is the volume of the records logged with a message, identifying a
+// log site, with an exponential histogram of their sizes
+type SiteVolume struct {
+	Message string `json:"message"`
+	Level   string `json:"level"`
+	Volume
+	Sizes []SizeCount `json:"sizes"`
+}
+
+// VolumeStats is the log volume over the last window, per level and for the
+// noisiest log sites
+type VolumeStats struct {
+	// Window is the duration of the sliding window
+	Window time.Duration `json:"window"`
+	// Levels is the volume per level
+	Levels map[string]Volume `json:"levels"`
+	// Top is the volume of the messages with the most records, in descending
+	// order
+	Top []SiteVolume `json:"top"`
+}
+
+var statsLevels = [stats.NumLevels]level.Level{
+	level.Trace, level.Debug, level.Info, level.Warn, level.Error, level.Fatal,
+}
+
+// ReadStats returns a snapshot of the process-wide logx counters
+func ReadStats() Stats {
+	s := Stats{
+		Records:    make(map[string]uint64, len(statsLevels)),
+		Dropped:    stats.Dropped.Load(),
+		Errors:     stats.Errors.Load(),
+		QueueDepth: max(stats.Queued.Load(), 0),
+	}
+	for i, lv := range statsLevels {
+		s.Records[lv.String()] = stats.Records[i].Load()
+	}
+	if v := stats.Tracker.Load(); v != nil {
+		s.Volume = readVolume(v)
+	}
+	return s
+}
+
+// ResetStats zeroes the logx counters returned by ReadStats, except for the
+// queue depth
+func ResetStats() {
+	stats.Reset()
+	if v := stats.Tracker.Load(); v != nil {
+		stats.Tracker.CompareAndSwap(v, stats.NewVolume(v.Window(), v.Top()))
+	}
+}
+
+// TrackVolume starts tracking the number and approximate size of the records
+// logged in the process, per level and per message, over a sliding window of
+// duration `window`. ReadStats (and PublishStats) then report them, with the
+// `top` messages with the most records and a histogram of their sizes, to
+// find the noisiest log sites in production:
+//
+//	logx.TrackVolume(5*time.Minute, 10)
+//	logx.PublishStats("logx")
+//
+// Calling it again replaces the tracked volume; a zero window or top disables
+// it. Tracking takes a lock on each record, so it is disabled by default
+func TrackVolume(window time.Duration, top int) {
+	if window <= 0 || top <= 0 {
+		stats.Tracker.Store(nil)
+		return
+	}
+	stats.Tracker.Store(stats.NewVolume(window, top))
+}
+
+func readVolume(v *stats.Volume) *VolumeStats {
+	levels, top := v.Read()
+
+	vs := &VolumeStats{
+		Window: v.Window(),
+		Levels: make(map[string]Volume, len(statsLevels)),
+		Top:    make([]SiteVolume, 0, len(top)),
+	}
+	for i, lv := range statsLevels {
+		vs.Levels[lv.String()] = Volume(levels[i])
+	}
+	for _, site := range top {
+		sv := SiteVolume{
+			Message: site.Key,
+			Level:   level.Info.Offset(site.Level).String(),
+			Volume:  Volume(site.Counter),
+		}
+		for b, n := range site.Sizes {
+			if n > 0 {
+				sv.Sizes = append(sv.Sizes, SizeCount{UpTo: stats.SizeBound(b), Records: n})
+			}
+		}
+		vs.Top = append(vs.Top, sv)
+	}
+	return vs
+}
+
+// recordSize returns the approximate size of a record with message `msg` and
+// attributes `attrs` and `bound`, in bytes
+func recordSize(msg string, attrs, bound []attr.Attr) int {
+	return len(msg) + attrsSize(attrs) + attrsSize(bound)
+}
+
+func attrsSize(attrs []attr.Attr) int {
+	var n int
+	for _, a := range attrs {
+		if a == nil {
+			continue
+		}
+		n += len(a.Key())
+		switch v := a.Value().(type) {
+		case string:
+			n += len(v)
+		case []byte:
+			n += len(v)
+		case error:
+			n += len(v.Error())
+		case []attr.Attr:
+			n += attrsSize(v)
+		default:
+			n += 8
+		}
+	}
+	return n
+}
@@ -0,0 +1,15 @@
+//go:build !logx_tiny
+
+package logx
+
+import "expvar"
+
+// PublishStats exposes the logx counters (see ReadStats) as an expvar variable
+// with name `name`, served in the /debug/vars endpoint.
+//
+// Like expvar.Publish, it panics if the name is already in use
+func PublishStats(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return ReadStats()
+	}))
+}
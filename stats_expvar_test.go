@@ -0,0 +1,27 @@
+//go:build !logx_tiny
+
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/zalgonoise/logx/handlers/jsonh"
+)
+
+func TestPublishStats(t *testing.T) {
+	ResetStats()
+	PublishStats("logx_test")
+	New(WithHandler(jsonh.New(&bytes.Buffer{}))).Debug("message")
+
+	var s Stats
+	if err := json.Unmarshal([]byte(expvar.Get("logx_test").String()), &s); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if s.Records["debug"] != 1 {
+		t.Errorf("output mismatch error: wanted %v ; got %v", 1, s.Records["debug"])
+	}
+}
@@ -0,0 +1,123 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("Records", func(t *testing.T) {
+		ResetStats()
+		logger := New(WithHandler(jsonh.New(&bytes.Buffer{})))
+
+		logger.Info("one")
+		logger.Info("two")
+		logger.Warn("three")
+		logger.Log(level.Info.Offset(2), "four")
+
+		s := ReadStats()
+		if s.Records["info"] != 3 || s.Records["warn"] != 1 || s.Records["error"] != 0 {
+			t.Errorf("output mismatch error: wanted 3 info and 1 warn records ; got %v", s.Records)
+		}
+	})
+	t.Run("Filtered", func(t *testing.T) {
+		ResetStats()
+		logger := New(WithHandler(jsonh.New(&bytes.Buffer{}).WithLevel(level.Warn)))
+
+		logger.Info("one")
+		logger.InfoContext(context.Background(), "two")
+		logger.Warn("three")
+		logger.WarnContext(context.Background(), "four")
+
+		s := ReadStats()
+		if s.Records["info"] != 0 || s.Records["warn"] != 2 {
+			t.Errorf("output mismatch error: wanted 0 info and 2 warn records ; got %v", s.Records)
+		}
+	})
+	t.Run("Errors", func(t *testing.T) {
+		ResetStats()
+		New(WithHandler(jsonh.New(errWriter{}))).Error("failed")
+
+		if s := ReadStats(); s.Errors != 1 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 1, s.Errors)
+		}
+	})
+	t.Run("Dropped", func(t *testing.T) {
+		ResetStats()
+		block := make(chan struct{})
+		a := handlers.NewAsync(jsonh.New(blockingWriter(block)), 1, handlers.DropNewest, 0)
+		logger := New(WithHandler(a))
+
+		for i := 0; i < 5; i++ {
+			logger.Info("message")
+		}
+
+		s := ReadStats()
+		if s.Dropped == 0 || s.Dropped != a.Dropped() {
+			t.Errorf("output mismatch error: wanted %v dropped records ; got %v", a.Dropped(), s.Dropped)
+		}
+		if s.QueueDepth > 1 {
+			t.Errorf("output mismatch error: wanted a queue depth of at most 1 ; got %v", s.QueueDepth)
+		}
+
+		close(block)
+		_ = a.Close()
+
+		if s = ReadStats(); s.QueueDepth != 0 {
+			t.Errorf("output mismatch error: wanted %v ; got %v", 0, s.QueueDepth)
+		}
+	})
+}
+
+type blockingWriter chan struct{}
+
+func (w blockingWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w:
+	case <-time.After(time.Second):
+	}
+	return len(p), nil
+}
+
+func TestTrackVolume(t *testing.T) {
+	TrackVolume(time.Minute, 1)
+	defer TrackVolume(0, 0)
+
+	logger := New(WithHandler(jsonh.New(&bytes.Buffer{})))
+	logger.Info("cache miss", attr.String("key", "user:1"))
+	logger.Info("cache miss", attr.String("key", "user:22"))
+	logger.Warn("slow query", attr.Int("ms", 300))
+
+	s := ReadStats()
+	if s.Volume == nil {
+		t.Errorf("expected the volume to be tracked")
+		return
+	}
+	if info := s.Volume.Levels["info"]; info.Records != 2 || info.Bytes != 10+3+6+10+3+7 {
+		t.Errorf("output mismatch error: got %+v", info)
+	}
+	if len(s.Volume.Top) != 1 || s.Volume.Top[0].Message != "cache miss" || s.Volume.Top[0].Level != "info" {
+		t.Errorf("output mismatch error: wanted the cache miss site ; got %+v", s.Volume.Top)
+		return
+	}
+	if sizes := s.Volume.Top[0].Sizes; len(sizes) != 1 || sizes[0] != (SizeCount{UpTo: 32, Records: 2}) {
+		t.Errorf("output mismatch error: got %+v", sizes)
+	}
+
+	ResetStats()
+	if s = ReadStats(); s.Volume == nil || len(s.Volume.Top) != 0 {
+		t.Errorf("output mismatch error: wanted an empty volume ; got %+v", s.Volume)
+	}
+
+	TrackVolume(0, 0)
+	if s = ReadStats(); s.Volume != nil {
+		t.Errorf("expected the volume not to be tracked ; got %+v", s.Volume)
+	}
+}
@@ -0,0 +1,97 @@
+package logx
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+
+	"github.com/zalgonoise/logx/level"
+)
+
+// StdlogOption describes a setting applied to RedirectStdlog
+type StdlogOption func(*stdlogConfig)
+
+type stdlogConfig struct {
+	lv   level.Level
+	slog bool
+}
+
+// WithStdlogLevel sets the level that the lines written with the log package
+// are logged with, which defaults to level.Info
+func WithStdlogLevel(lv level.Level) StdlogOption {
+	return func(c *stdlogConfig) {
+		c.lv = lv
+	}
+}
+
+// WithSlog sets whether RedirectStdlog also sets the default slog.Logger to
+// one writing to the Logger (see NewSlogHandler)
+func WithSlog(enabled bool) StdlogOption {
+	return func(c *stdlogConfig) {
+		c.slog = enabled
+	}
+}
+
+// stdlogWriter logs each line written by the log package as a record
+type stdlogWriter struct {
+	l  Logger
+	lv level.Level
+}
+
+func (w stdlogWriter) Write(p []byte) (int, error) {
+	w.l.Log(w.lv, string(bytes.TrimSuffix(p, []byte("\n"))))
+	return len(p), nil
+}
+
+// RedirectStdlog sets the output of the log package's standard logger to the
+// Logger `logger` (or the standard Logger, if nil), so that the unstructured
+// lines that third-party libraries write with it become records, with the
+// line as their message. The log package's flags and prefix are cleared, as
+// the records carry their own timestamp.
+//
+// With the WithSlog option, the default slog.Logger is replaced as well.
+// The returned function restores the previous output, flags, prefix and
+// default slog.Logger:
+//
+//	restore := logx.RedirectStdlog(logger, logx.WithStdlogLevel(level.Debug))
+//	defer restore()
+func RedirectStdlog(logger Logger, opts ...StdlogOption) (restore func()) {
+	c := &stdlogConfig{lv: level.Info}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	if c.lv == nil {
+		c.lv = level.Info
+	}
+	if logger == nil {
+		logger = std
+	}
+
+	var (
+		prevOutput = log.Writer()
+		prevFlags  = log.Flags()
+		prevPrefix = log.Prefix()
+		prevSlog   = slog.Default()
+	)
+
+	// setting a default slog.Logger also redirects the log package to it, so
+	// the log package's output is set afterwards to keep its level
+	if c.slog {
+		slog.SetDefault(slog.New(NewSlogHandler(logger)))
+	}
+
+	log.SetOutput(stdlogWriter{l: logger, lv: c.lv})
+	log.SetFlags(0)
+	log.SetPrefix("")
+
+	return func() {
+		if c.slog {
+			slog.SetDefault(prevSlog)
+		}
+		log.SetOutput(prevOutput)
+		log.SetFlags(prevFlags)
+		log.SetPrefix(prevPrefix)
+	}
+}
@@ -0,0 +1,49 @@
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"testing"
+
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestRedirectStdlog(t *testing.T) {
+	b := &bytes.Buffer{}
+	prev := log.Writer()
+
+	restore := RedirectStdlog(New(WithHandler(jsonh.New(b))), WithStdlogLevel(level.Warn), WithSlog(true))
+	log.Printf("connection %d reset", 3)
+	slog.Error("query failed", "table", "users")
+	restore()
+
+	var entries []map[string]any
+	for dec := json.NewDecoder(b); dec.More(); {
+		var e map[string]any
+		if err := dec.Decode(&e); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 2 {
+		t.Errorf("output mismatch error: wanted %v records ; got %v", 2, len(entries))
+		return
+	}
+	if entries[0]["message"] != "connection 3 reset" || entries[0]["level"] != "warn" {
+		t.Errorf("output mismatch error: wanted the log line as a warning ; got %v", entries[0])
+	}
+	if data, _ := entries[1]["data"].(map[string]any); entries[1]["message"] != "query failed" || data["table"] != "users" {
+		t.Errorf("output mismatch error: wanted the slog record ; got %v", entries[1])
+	}
+
+	if log.Writer() != prev {
+		t.Errorf("expected the log package's output to be restored")
+	}
+	if _, ok := slog.Default().Handler().(slogHandler); ok {
+		t.Errorf("expected the default slog.Logger to be restored")
+	}
+}
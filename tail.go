@@ -0,0 +1,34 @@
+package logx
+
+import "github.com/zalgonoise/logx/handlers"
+
+// Tail returns a copy of the Logger `l` that holds its low-level records
+// in memory, as configured by the TailConfig `conf`, along with the
+// handlers.Tail holding them. Once the outcome of the unit of work that the
+// returned Logger is used for is known, the Tail is flushed (to keep the
+// records, like when a request fails) or discarded:
+//
+//	reqLogger, tail := logx.Tail(logger, handlers.TailConfig{})
+//	if err := process(logx.InContext(ctx, reqLogger)); err != nil {
+//		_ = tail.Flush()
+//		return err
+//	}
+//	tail.Discard()
+//
+// The returned Logger keeps the attributes and settings of `l`
+func Tail(l Logger, conf handlers.TailConfig) (Logger, *handlers.Tail) {
+	if l == nil {
+		l = Default()
+	}
+
+	t := handlers.NewTail(l.Handler(), conf)
+
+	base, ok := l.(*logger)
+	if !ok {
+		return New(WithHandler(t)), t
+	}
+
+	cp := *base
+	cp.h = t
+	return &cp, t
+}
@@ -0,0 +1,30 @@
+package logx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+)
+
+func TestTail(t *testing.T) {
+	b := &bytes.Buffer{}
+	logger, tail := Tail(New(WithHandler(jsonh.New(b))).With(attr.String("request_id", "abc")), handlers.TailConfig{})
+
+	logger.Debug("held")
+	logger.Error("failed")
+	if out := b.String(); strings.Contains(out, "held") || !strings.Contains(out, "failed") {
+		t.Errorf("output mismatch error: wanted only the error record ; got %s", out)
+	}
+
+	if err := tail.Flush(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if out := b.String(); !strings.Contains(out, `"message":"held"`) || strings.Count(out, `"request_id":"abc"`) != 2 {
+		t.Errorf("output mismatch error: wanted the held record with the logger's attributes ; got %s", out)
+	}
+}
@@ -0,0 +1,22 @@
+package logx
+
+import (
+	"time"
+
+	"github.com/zalgonoise/logx/records"
+)
+
+// TestTime is the timestamp of the records logged by a Logger created with
+// the TestMode option
+var TestTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// TestMode makes the Logger's output reproducible byte-for-byte, for golden
+// tests and examples: its records are timestamped with TestTime, and its
+// Handler writes them with sorted attributes, no source references and no
+// colors (see handlers.Deterministic)
+func TestMode() Option {
+	return func(c *config) {
+		c.clock = records.ClockFunc(func() time.Time { return TestTime })
+		c.deterministic = true
+	}
+}
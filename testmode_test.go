@@ -0,0 +1,24 @@
+package logx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers/texth"
+)
+
+func TestTestMode(t *testing.T) {
+	b := &bytes.Buffer{}
+	logger := New(WithHandler(texth.New(b)), WithSource(true), TestMode()).With(attr.String("service", "api"))
+
+	logger.Info("request served", attr.Int("status", 200), attr.String("path", "/"))
+	first := b.String()
+	b.Reset()
+	logger.Info("request served", attr.Int("status", 200), attr.String("path", "/"))
+
+	wants := "[2024-01-01T00:00:00Z] [info] request served [ path: / ; service: api ; status: 200 ]\n"
+	if first != wants || b.String() != wants {
+		t.Errorf("output mismatch error: wanted %q ; got %q and %q", wants, first, b.String())
+	}
+}
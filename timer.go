@@ -0,0 +1,76 @@
+package logx
+
+import (
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+)
+
+// DurationKey is the default key of the attribute holding the elapsed time, in
+// the records logged by a Timer
+const DurationKey = "duration"
+
+// TimerConfig configures the records logged by a Timer
+type TimerConfig struct {
+	// Level is the level of the record, defaulting to level.Info
+	Level level.Level
+	// Threshold is the elapsed time from which the record is logged with
+	// SlowLevel instead. Zero disables the escalation
+	Threshold time.Duration
+	// SlowLevel is the level of the record when the elapsed time is over the
+	// Threshold, defaulting to level.Warn
+	SlowLevel level.Level
+	// Key is the key of the attribute holding the elapsed time, defaulting to
+	// DurationKey
+	Key string
+}
+
+// Timer starts timing an operation, returning a function that logs the message
+// `msg` with the attributes `attrs` and the elapsed time when called, meant to
+// be deferred:
+//
+//	defer logx.Timer(logger, "query executed", attr.String("table", "users"))()
+//
+// The record is logged with level.Info; see TimerConfig for a level escalation
+// when the operation is slow
+func Timer(l Logger, msg string, attrs ...attr.Attr) func() {
+	return TimerConfig{}.Timer(l, msg, attrs...)
+}
+
+// Timer starts timing an operation like the Timer function, logging a record
+// as configured by the TimerConfig:
+//
+//	slow := logx.TimerConfig{Threshold: 500 * time.Millisecond}
+//
+//	defer slow.Timer(logger, "query executed")()
+func (c TimerConfig) Timer(l Logger, msg string, attrs ...attr.Attr) func() {
+	if l == nil {
+		l = Default()
+	}
+	if c.Level == nil {
+		c.Level = level.Info
+	}
+	if c.SlowLevel == nil {
+		c.SlowLevel = level.Warn
+	}
+	if c.Key == "" {
+		c.Key = DurationKey
+	}
+
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+
+		lv := c.Level
+		if c.Threshold > 0 && elapsed > c.Threshold {
+			lv = c.SlowLevel
+		}
+		if !l.Enabled(lv) {
+			return
+		}
+
+		out := make([]attr.Attr, 0, len(attrs)+1)
+		l.Log(lv, msg, append(append(out, attrs...), attr.New(c.Key, elapsed))...)
+	}
+}
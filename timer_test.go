@@ -0,0 +1,61 @@
+package logx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestTimer(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		conf  TimerConfig
+		key   string
+		wants level.Level
+	}{
+		{"Default", TimerConfig{}, DurationKey, level.Info},
+		{"UnderThreshold", TimerConfig{Threshold: time.Hour}, DurationKey, level.Info},
+		{"OverThreshold", TimerConfig{Threshold: time.Nanosecond}, DurationKey, level.Warn},
+		{"Custom", TimerConfig{Level: level.Debug, Threshold: time.Nanosecond, SlowLevel: level.Error, Key: "latency"}, "latency", level.Error},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s := handlers.NewStore(10)
+			logger := New(WithHandler(s))
+
+			done := test.conf.Timer(logger, "query executed", attr.String("table", "users"))
+			time.Sleep(time.Millisecond)
+			done()
+
+			rs := s.Records()
+			if len(rs) != 1 {
+				t.Errorf("output mismatch error: wanted %v records ; got %v", 1, len(rs))
+				return
+			}
+			if rs[0].Level() != test.wants || rs[0].Message() != "query executed" || !handlers.HasAttr("table", "users")(rs[0]) {
+				t.Errorf("output mismatch error: wanted a %v record ; got %v", test.wants, rs[0])
+			}
+
+			var elapsed time.Duration
+			for _, a := range rs[0].Attrs() {
+				if a.Key() == test.key {
+					elapsed, _ = a.Value().(time.Duration)
+				}
+			}
+			if elapsed < time.Millisecond {
+				t.Errorf("output mismatch error: wanted at least %v ; got %v", time.Millisecond, elapsed)
+			}
+		})
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		s := handlers.NewStore(10)
+		Timer(New(WithHandler(s), WithLevel(level.Warn)), "hidden")()
+
+		if s.Len() != 0 {
+			t.Errorf("output mismatch error: wanted no records ; got %v", s.Records())
+		}
+	})
+}
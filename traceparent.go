@@ -0,0 +1,82 @@
+package logx
+
+import (
+	"strings"
+
+	"github.com/zalgonoise/attr"
+)
+
+const (
+	traceParentLen = 55
+
+	traceIDKey = "trace_id"
+	spanIDKey  = "span_id"
+	sampledKey = "sampled"
+)
+
+// TraceParent parses the W3C Trace Context `traceparent` header value
+// `header`, returning its trace ID, parent span ID and sampled flag as the
+// `trace_id`, `span_id` and `sampled` attributes. This allows services that
+// propagate the trace context without an OpenTelemetry SDK to correlate their
+// records with it, e.g.:
+//
+//	logger.Info("request received", logx.TraceParent(r.Header.Get("traceparent"))...)
+//
+// Returns nil if the header is empty or malformed
+func TraceParent(header string) []attr.Attr {
+	header = strings.TrimSpace(header)
+	if len(header) < traceParentLen {
+		return nil
+	}
+
+	version := header[0:2]
+	if !isHex(version) || version == "ff" {
+		return nil
+	}
+	// version 00 has a fixed length; future versions may append fields
+	if version == "00" && len(header) != traceParentLen ||
+		len(header) > traceParentLen && header[traceParentLen] != '-' {
+		return nil
+	}
+	if header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return nil
+	}
+
+	var (
+		traceID = header[3:35]
+		spanID  = header[36:52]
+		flags   = header[53:55]
+	)
+	if !isHex(traceID) || !isHex(spanID) || !isHex(flags) ||
+		isZero(traceID) || isZero(spanID) {
+		return nil
+	}
+
+	return []attr.Attr{
+		attr.String(traceIDKey, traceID),
+		attr.String(spanIDKey, spanID),
+		attr.New(sampledKey, fromHex(flags[1])&1 == 1),
+	}
+}
+
+// isHex returns true if `s` only contains lowercase hexadecimal digits, as
+// required by the W3C Trace Context specification
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+func fromHex(c byte) byte {
+	if c >= 'a' {
+		return c - 'a' + 10
+	}
+	return c - '0'
+}
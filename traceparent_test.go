@@ -0,0 +1,64 @@
+package logx
+
+import (
+	"testing"
+
+	"github.com/zalgonoise/attr"
+)
+
+func TestTraceParent(t *testing.T) {
+	for _, testcase := range []struct {
+		name   string
+		header string
+		wants  []attr.Attr
+	}{
+		{
+			name:   "Sampled",
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wants: []attr.Attr{
+				attr.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"),
+				attr.String("span_id", "00f067aa0ba902b7"),
+				attr.New("sampled", true),
+			},
+		},
+		{
+			name:   "NotSampled",
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			wants: []attr.Attr{
+				attr.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"),
+				attr.String("span_id", "00f067aa0ba902b7"),
+				attr.New("sampled", false),
+			},
+		},
+		{
+			name:   "FutureVersion",
+			header: "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-03-extra",
+			wants: []attr.Attr{
+				attr.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"),
+				attr.String("span_id", "00f067aa0ba902b7"),
+				attr.New("sampled", true),
+			},
+		},
+		{name: "Empty", header: ""},
+		{name: "Short", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"},
+		{name: "Version00WithExtra", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"},
+		{name: "InvalidVersion", header: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "Uppercase", header: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+		{name: "ZeroTraceID", header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{name: "ZeroSpanID", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{name: "BadSeparator", header: "00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			attrs := TraceParent(testcase.header)
+			if len(attrs) != len(testcase.wants) {
+				t.Errorf("output mismatch error: wanted %v ; got %v", testcase.wants, attrs)
+				return
+			}
+			for i := range attrs {
+				if attrs[i].Key() != testcase.wants[i].Key() || attrs[i].Value() != testcase.wants[i].Value() {
+					t.Errorf("output mismatch error: wanted %v ; got %v", testcase.wants[i], attrs[i])
+				}
+			}
+		})
+	}
+}
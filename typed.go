@@ -0,0 +1,242 @@
+package logx
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/level"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+
+	// encoders caches the compiled encoders, per struct type
+	encoders sync.Map
+)
+
+// Typed is a Logger for the recurring events of type T, a struct whose fields
+// are logged as attributes, so that every call site logs the same shape:
+//
+//	type Login struct {
+//		UserID   string        `log:"user_id"`
+//		Attempts int           `log:"attempts,omitempty"`
+//		Elapsed  time.Duration `log:"elapsed"`
+//	}
+//
+//	logins := logx.NewTyped[Login](logger)
+//	logins.Log(ctx, level.Info, "user logged in", Login{UserID: id, Elapsed: d})
+//
+// The exported fields are logged with the key in their `log` tag, or with
+// their name if untagged. Fields tagged with `log:"-"` are left out, and the
+// ones with the `omitempty` option are left out when set to their zero value.
+// Nested structs are logged as attribute groups.
+//
+// The struct's layout is compiled into an encoder once per type, which reads
+// the fields' values directly, with no reflection for the string, boolean and
+// numeric kinds, time.Time and time.Duration. Fields of other types are read
+// with reflection
+type Typed[T any] struct {
+	l   Logger
+	enc *structEncoder
+}
+
+// NewTyped creates a Typed logger for the events of type T, writing them to the
+// Logger `l` (or the standard Logger, if nil). It panics if T is not a struct
+func NewTyped[T any](l Logger) *Typed[T] {
+	if l == nil {
+		l = Default()
+	}
+
+	return &Typed[T]{
+		l:   l,
+		enc: encoderFor(reflect.TypeOf((*T)(nil)).Elem()),
+	}
+}
+
+// Log prints a log message `msg` with the attributes of the event `event`, and
+// those extracted from `ctx` (if not nil), with `lv` log level
+func (t *Typed[T]) Log(ctx context.Context, lv level.Level, msg string, event T) {
+	if lv == nil {
+		lv = level.Info
+	}
+	if !t.l.Enabled(lv) {
+		return
+	}
+
+	attrs := t.Attrs(event)
+	if ctx == nil {
+		t.l.Log(lv, msg, attrs...)
+		return
+	}
+	t.l.LogContext(ctx, lv, msg, attrs...)
+}
+
+// Attrs returns the attributes of the event `event`
+func (t *Typed[T]) Attrs(event T) []attr.Attr {
+	return t.enc.attrs(unsafe.Pointer(&event))
+}
+
+// Logger returns the Logger that this Typed logger writes to
+func (t *Typed[T]) Logger() Logger {
+	return t.l
+}
+
+// structEncoder converts the values of a struct type into attributes
+type structEncoder struct {
+	fields []fieldEncoder
+}
+
+type fieldEncoder struct {
+	key       string
+	offset    uintptr
+	omitEmpty bool
+	encode    func(key string, p unsafe.Pointer) (a attr.Attr, empty bool)
+}
+
+// encoderFor returns the (cached) encoder for the struct type `t`
+func encoderFor(t reflect.Type) *structEncoder {
+	if enc, ok := encoders.Load(t); ok {
+		return enc.(*structEncoder)
+	}
+
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("logx: typed events must be structs, got %v", t))
+	}
+
+	enc, _ := encoders.LoadOrStore(t, compileStruct(t))
+	return enc.(*structEncoder)
+}
+
+// compileStruct builds the encoder for the struct type `t`, from the tags of
+// its exported fields
+func compileStruct(t reflect.Type) *structEncoder {
+	enc := &structEncoder{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		key, opts, _ := strings.Cut(f.Tag.Get("log"), ",")
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = f.Name
+		}
+
+		enc.fields = append(enc.fields, fieldEncoder{
+			key:       key,
+			offset:    f.Offset,
+			omitEmpty: opts == "omitempty",
+			encode:    compileField(f.Type),
+		})
+	}
+	return enc
+}
+
+// compileField returns the function converting a value of type `t`, pointed
+// to by `p`, into an attribute with the key `key`
+func compileField(t reflect.Type) func(key string, p unsafe.Pointer) (attr.Attr, bool) {
+	switch t {
+	case timeType:
+		return func(key string, p unsafe.Pointer) (attr.Attr, bool) {
+			v := *(*time.Time)(p)
+			return attr.New(key, v), v.IsZero()
+		}
+	case durationType:
+		return func(key string, p unsafe.Pointer) (attr.Attr, bool) {
+			v := *(*time.Duration)(p)
+			return attr.New(key, v), v == 0
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return func(key string, p unsafe.Pointer) (attr.Attr, bool) {
+			v := *(*string)(p)
+			return attr.String(key, v), v == ""
+		}
+	case reflect.Bool:
+		return func(key string, p unsafe.Pointer) (attr.Attr, bool) {
+			v := *(*bool)(p)
+			return attr.New(key, v), !v
+		}
+	case reflect.Int:
+		return intField[int]()
+	case reflect.Int8:
+		return intField[int8]()
+	case reflect.Int16:
+		return intField[int16]()
+	case reflect.Int32:
+		return intField[int32]()
+	case reflect.Int64:
+		return intField[int64]()
+	case reflect.Uint:
+		return uintField[uint]()
+	case reflect.Uint8:
+		return uintField[uint8]()
+	case reflect.Uint16:
+		return uintField[uint16]()
+	case reflect.Uint32:
+		return uintField[uint32]()
+	case reflect.Uint64:
+		return uintField[uint64]()
+	case reflect.Float32:
+		return floatField[float32]()
+	case reflect.Float64:
+		return floatField[float64]()
+	case reflect.Struct:
+		enc := compileStruct(t)
+		return func(key string, p unsafe.Pointer) (attr.Attr, bool) {
+			attrs := enc.attrs(p)
+			return attr.New(key, attrs), len(attrs) == 0
+		}
+	default:
+		return func(key string, p unsafe.Pointer) (attr.Attr, bool) {
+			v := reflect.NewAt(t, p).Elem()
+			return attr.New(key, v.Interface()), v.IsZero()
+		}
+	}
+}
+
+func intField[T int | int8 | int16 | int32 | int64]() func(string, unsafe.Pointer) (attr.Attr, bool) {
+	return func(key string, p unsafe.Pointer) (attr.Attr, bool) {
+		v := *(*T)(p)
+		return attr.Int(key, v), v == 0
+	}
+}
+
+func uintField[T uint | uint8 | uint16 | uint32 | uint64]() func(string, unsafe.Pointer) (attr.Attr, bool) {
+	return func(key string, p unsafe.Pointer) (attr.Attr, bool) {
+		v := *(*T)(p)
+		return attr.Uint(key, v), v == 0
+	}
+}
+
+func floatField[T float32 | float64]() func(string, unsafe.Pointer) (attr.Attr, bool) {
+	return func(key string, p unsafe.Pointer) (attr.Attr, bool) {
+		v := *(*T)(p)
+		return attr.Float(key, v), v == 0
+	}
+}
+
+// attrs returns the attributes of the struct pointed to by `p`
+func (e *structEncoder) attrs(p unsafe.Pointer) []attr.Attr {
+	attrs := make([]attr.Attr, 0, len(e.fields))
+	for _, f := range e.fields {
+		a, empty := f.encode(f.key, unsafe.Add(p, f.offset))
+		if empty && f.omitEmpty {
+			continue
+		}
+		attrs = append(attrs, a)
+	}
+	return attrs
+}
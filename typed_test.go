@@ -0,0 +1,103 @@
+package logx
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers"
+	"github.com/zalgonoise/logx/level"
+)
+
+type testStatus int
+
+type testLogin struct {
+	UserID   string        `log:"user_id"`
+	Attempts int           `log:"attempts,omitempty"`
+	Elapsed  time.Duration `log:"elapsed"`
+	Admin    bool
+	Password string     `log:"-"`
+	Status   testStatus `log:"status"`
+	Client   struct {
+		IP     string `log:"ip"`
+		Agent  string `log:"agent,omitempty"`
+		Labels []string
+	} `log:"client"`
+
+	internal string
+}
+
+func TestTyped(t *testing.T) {
+	event := testLogin{
+		UserID:   "u1",
+		Elapsed:  time.Second,
+		Password: "hunter2",
+		Status:   2,
+		internal: "x",
+	}
+	event.Client.IP = "10.0.0.2"
+	event.Client.Labels = []string{"a"}
+
+	t.Run("Attrs", func(t *testing.T) {
+		wants := []attr.Attr{
+			attr.String("user_id", "u1"),
+			attr.New("elapsed", time.Second),
+			attr.New("Admin", false),
+			attr.Int("status", 2),
+			attr.New("client", []attr.Attr{
+				attr.String("ip", "10.0.0.2"),
+				attr.New("Labels", []string{"a"}),
+			}),
+		}
+
+		attrs := NewTyped[testLogin](nil).Attrs(event)
+		if len(attrs) != len(wants) {
+			t.Errorf("output mismatch error: wanted %v ; got %v", wants, attrs)
+			return
+		}
+		for i := range wants {
+			if fmt.Sprint(attrs[i]) != fmt.Sprint(wants[i]) {
+				t.Errorf("output mismatch error: wanted %v ; got %v", wants[i], attrs[i])
+			}
+		}
+	})
+
+	t.Run("Log", func(t *testing.T) {
+		s := handlers.NewStore(10)
+		typed := NewTyped[testLogin](New(WithHandler(s), WithLevel(level.Info)))
+
+		typed.Log(context.Background(), level.Warn, "user logged in", event)
+		typed.Log(nil, level.Debug, "hidden", event)
+
+		rs := s.Records()
+		if len(rs) != 1 {
+			t.Errorf("output mismatch error: wanted %v records ; got %v", 1, len(rs))
+			return
+		}
+		if rs[0].Message() != "user logged in" || rs[0].Level() != level.Warn || !handlers.HasAttr("user_id", "u1")(rs[0]) {
+			t.Errorf("output mismatch error: wanted the typed event ; got %v", rs[0])
+		}
+	})
+
+	t.Run("NotStruct", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected NewTyped to panic for a non-struct type")
+			}
+		}()
+		NewTyped[string](nil)
+	})
+}
+
+func BenchmarkTyped(b *testing.B) {
+	typed := NewTyped[testLogin](nil)
+	event := testLogin{UserID: "u1", Attempts: 3, Elapsed: time.Second}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = typed.Attrs(event)
+	}
+}
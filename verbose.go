@@ -0,0 +1,29 @@
+package logx
+
+import "github.com/zalgonoise/logx/level"
+
+// Verbose returns a copy of the Logger `l` whose Handler accepts records from
+// the level `lv` upwards, regardless of the verbosity it was configured with
+// (including a module's level, for the Loggers from a Registry).
+//
+// This is meant for short-lived Loggers, like a request's, when more output is
+// needed for a single unit of work. The returned Logger keeps the attributes
+// and settings of `l`
+func Verbose(l Logger, lv level.Level) Logger {
+	if l == nil {
+		l = Default()
+	}
+
+	h := l.Handler().WithLevel(lv)
+
+	base, ok := l.(*logger)
+	if !ok {
+		return New(WithHandler(h))
+	}
+
+	cp := *base
+	cp.h = h
+	cp.module = nil
+	cp.registry = nil
+	return &cp
+}
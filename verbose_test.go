@@ -0,0 +1,27 @@
+package logx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zalgonoise/attr"
+	"github.com/zalgonoise/logx/handlers/jsonh"
+	"github.com/zalgonoise/logx/level"
+)
+
+func TestVerbose(t *testing.T) {
+	b := &bytes.Buffer{}
+	logger := New(WithHandler(jsonh.New(b)), WithLevel(level.Info)).With(attr.String("request_id", "abc"))
+
+	logger.Debug("hidden")
+	Verbose(logger, level.Debug).Debug("shown")
+
+	out := b.String()
+	if strings.Contains(out, "hidden") || !strings.Contains(out, "shown") {
+		t.Errorf("output mismatch error: wanted only the verbose record ; got %s", out)
+	}
+	if !strings.Contains(out, `"request_id":"abc"`) {
+		t.Errorf("output mismatch error: wanted the logger's attributes ; got %s", out)
+	}
+}